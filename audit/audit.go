@@ -0,0 +1,158 @@
+// Package audit records the operations on the control plane and on the key
+// material of a drand node in an append-only log, written to a file or to
+// syslog, one JSON object per line. Each event tells when the operation
+// happened, who ran it and how it ended.
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/drand/drand/log"
+)
+
+// The actions recorded in the audit log.
+const (
+	ActionDKG       = "dkg"
+	ActionReshare   = "reshare"
+	ActionKeyLoad   = "key.load"
+	ActionKeyExport = "key.export"
+	ActionBackup    = "backup"
+	ActionRestore   = "restore"
+	ActionPause     = "beacon.pause"
+	ActionResume    = "beacon.resume"
+	ActionDrain     = "node.drain"
+	ActionUndrain   = "node.undrain"
+	// ActionDenied is a command of the control port refused by its
+	// authentication.
+	ActionDenied = "control.denied"
+)
+
+// The outcomes of the actions.
+const (
+	OutcomeStarted = "started"
+	OutcomeOK      = "ok"
+	OutcomeFailed  = "failed"
+	OutcomeDenied  = "denied"
+)
+
+// DaemonActor is the actor of the operations the node runs by itself, such as
+// the loading of its keys or its scheduled backups.
+const DaemonActor = "daemon"
+
+// Event is an entry of the audit log.
+type Event struct {
+	Time    time.Time `json:"time"`
+	Action  string    `json:"action"`
+	Outcome string    `json:"outcome"`
+	// Actor identifies who ran the operation: the token of the control port,
+	// the user of the command line, or the daemon itself.
+	Actor string `json:"actor"`
+	// Remote is the address the command came from, if any.
+	Remote   string            `json:"remote,omitempty"`
+	BeaconID string            `json:"beacon_id,omitempty"`
+	Error    string            `json:"error,omitempty"`
+	Details  map[string]string `json:"details,omitempty"`
+}
+
+// Outcome returns the outcome of an action ending with the given error.
+func Outcome(err error) string {
+	if err != nil {
+		return OutcomeFailed
+	}
+	return OutcomeOK
+}
+
+type actorKey struct{}
+
+// WithActor returns a context carrying the actor of the operations run with
+// it.
+func WithActor(ctx context.Context, actor string) context.Context {
+	return context.WithValue(ctx, actorKey{}, actor)
+}
+
+// Actor returns the actor of the context, the daemon if none is set.
+func Actor(ctx context.Context) string {
+	if ctx != nil {
+		if actor, ok := ctx.Value(actorKey{}).(string); ok {
+			return actor
+		}
+	}
+	return DaemonActor
+}
+
+// Log is an audit log. A nil Log records nothing, so that the callers do not
+// need to check whether the audit log is enabled.
+type Log struct {
+	sync.Mutex
+	w io.Writer
+	l log.Logger
+}
+
+// New returns an audit log writing its events to w.
+func New(w io.Writer) *Log {
+	return &Log{w: w, l: log.DefaultLogger().With("audit", "log")}
+}
+
+// Open opens the audit log of the given target: "syslog" for the local
+// syslog, "syslog+udp://host:port" or "syslog+tcp://host:port" for a remote
+// one, and a file path otherwise, to which the events are appended.
+func Open(target string) (*Log, error) {
+	if target == "" {
+		return nil, errors.New("audit: no target given")
+	}
+	if target == "syslog" || strings.HasPrefix(target, "syslog+") {
+		w, err := openSyslog(target)
+		if err != nil {
+			return nil, fmt.Errorf("audit: %s", err)
+		}
+		return New(w), nil
+	}
+	f, err := os.OpenFile(target, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0600)
+	if err != nil {
+		return nil, fmt.Errorf("audit: %s", err)
+	}
+	return New(f), nil
+}
+
+// Record appends the event to the log, at the current time if its time is not
+// set. A failure to write the event is logged, and does not stop the
+// operation.
+func (a *Log) Record(e Event) {
+	if a == nil {
+		return
+	}
+	if e.Time.IsZero() {
+		e.Time = time.Now().UTC()
+	}
+	buff, err := json.Marshal(e)
+	if err != nil {
+		a.l.Error("audit", "encoding", "action", e.Action, "err", err)
+		return
+	}
+	a.Lock()
+	defer a.Unlock()
+	if _, err := a.w.Write(append(buff, '\n')); err != nil {
+		a.l.Error("audit", "writing", "action", e.Action, "err", err)
+	}
+}
+
+// Close closes the target of the log.
+func (a *Log) Close() error {
+	if a == nil {
+		return nil
+	}
+	a.Lock()
+	defer a.Unlock()
+	if c, ok := a.w.(io.Closer); ok {
+		return c.Close()
+	}
+	return nil
+}
@@ -0,0 +1,63 @@
+package audit
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestAuditLog(t *testing.T) {
+	var nilLog *Log
+	nilLog.Record(Event{Action: ActionPause})
+	require.NoError(t, nilLog.Close())
+
+	var buff bytes.Buffer
+	a := New(&buff)
+	ctx := WithActor(context.Background(), "token:ops")
+	require.Equal(t, DaemonActor, Actor(context.Background()))
+	require.Equal(t, "token:ops", Actor(ctx))
+	a.Record(Event{Action: ActionDKG, Outcome: OutcomeStarted, Actor: Actor(ctx)})
+	a.Record(Event{Action: ActionDKG, Outcome: Outcome(errors.New("timeout")), Actor: Actor(ctx), Error: "timeout"})
+
+	var events []Event
+	scanner := bufio.NewScanner(&buff)
+	for scanner.Scan() {
+		var e Event
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &e))
+		events = append(events, e)
+	}
+	require.Len(t, events, 2)
+	require.Equal(t, OutcomeStarted, events[0].Outcome)
+	require.Equal(t, OutcomeFailed, events[1].Outcome)
+	require.Equal(t, "token:ops", events[1].Actor)
+	require.False(t, events[0].Time.IsZero())
+}
+
+func TestAuditLogFile(t *testing.T) {
+	tmp, err := ioutil.TempDir("", "audit")
+	require.NoError(t, err)
+	defer os.RemoveAll(tmp)
+	file := path.Join(tmp, "audit.log")
+
+	// the events are appended to the events of the previous runs
+	for i := 0; i < 2; i++ {
+		a, err := Open(file)
+		require.NoError(t, err)
+		a.Record(Event{Action: ActionKeyLoad, Outcome: OutcomeOK, Actor: DaemonActor})
+		require.NoError(t, a.Close())
+	}
+	content, err := ioutil.ReadFile(file)
+	require.NoError(t, err)
+	require.Equal(t, 2, bytes.Count(content, []byte("\n")))
+
+	_, err = Open("")
+	require.Error(t, err)
+}
@@ -0,0 +1,26 @@
+//go:build !windows && !plan9 && !js
+// +build !windows,!plan9,!js
+
+package audit
+
+import (
+	"io"
+	"log/syslog"
+	"strings"
+)
+
+// syslogTag is the tag of the events of the audit log in syslog.
+const syslogTag = "drand-audit"
+
+func openSyslog(target string) (io.Writer, error) {
+	priority := syslog.LOG_AUTH | syslog.LOG_NOTICE
+	if target == "syslog" {
+		return syslog.New(priority, syslogTag)
+	}
+	// syslog+<network>://<address>
+	network, addr := strings.TrimPrefix(target, "syslog+"), ""
+	if i := strings.Index(network, "://"); i >= 0 {
+		network, addr = network[:i], network[i+3:]
+	}
+	return syslog.Dial(network, addr, priority, syslogTag)
+}
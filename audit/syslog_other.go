@@ -0,0 +1,13 @@
+//go:build windows || plan9 || js
+// +build windows plan9 js
+
+package audit
+
+import (
+	"errors"
+	"io"
+)
+
+func openSyslog(target string) (io.Writer, error) {
+	return nil, errors.New("syslog is not supported on this platform")
+}
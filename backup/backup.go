@@ -0,0 +1,591 @@
+// Package backup periodically backs up a drand node to an object storage,
+// such as S3 or GCS, and restores it. A backup holds a snapshot of the beacon
+// database and, encrypted under a passphrase, an archive of the key material
+// of the node: its key pair, its share and its group. A manifest records the
+// checksums of these objects, checked before a restore.
+package backup
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/drand/drand/audit"
+	"github.com/drand/drand/chain"
+	"github.com/drand/drand/fs"
+	"github.com/drand/drand/key"
+	"github.com/drand/drand/log"
+	"github.com/drand/drand/metrics"
+)
+
+// The objects of a backup, stored under <prefix>/<name>/ in the bucket, the
+// name of a backup being the UTC time it started at.
+const (
+	SnapshotObject = "chain.snapshot"
+	KeysObject     = "keys.tar.gz.enc"
+	ManifestObject = "manifest.json"
+	nameLayout     = "20060102T150405Z"
+)
+
+// DefaultInterval is the time between two backups when none is set.
+const DefaultInterval = 24 * time.Hour
+
+// maxKeysArchive bounds the size of the key material archive read back.
+const maxKeysArchive = 16 << 20
+
+// maxManifest bounds the size of the manifest read back.
+const maxManifest = 1 << 20
+
+// Config is the configuration of the backups of a node.
+type Config struct {
+	Bucket Bucket
+	// Prefix is the path of the backups in the bucket.
+	Prefix string
+	// Interval is the time between two backups.
+	Interval time.Duration
+	// Keep is the number of backups kept, 0 to keep them all.
+	Keep int
+	// MaxAge is the age after which backups are deleted, 0 to keep them
+	// regardless of their age. The last backup is never deleted.
+	MaxAge time.Duration
+	// Passphrase encrypts the key material. Without passphrase, only the
+	// beacon database is backed up.
+	Passphrase []byte
+	// Audit, if set, records each backup and who ran it.
+	Audit *audit.Log
+}
+
+// Backup describes a backup stored in the bucket.
+type Backup struct {
+	Name string    `json:"name"`
+	Time time.Time `json:"time"`
+	// Snapshot, Keys and Manifest tell which objects the backup holds. The
+	// backups made before the manifests were introduced have none.
+	Snapshot bool  `json:"snapshot"`
+	Keys     bool  `json:"keys"`
+	Manifest bool  `json:"manifest"`
+	Size     int64 `json:"size"`
+}
+
+// Manifest describes the objects of a backup, uploaded once they all are.
+type Manifest struct {
+	// ChainHash is the hash of the chain info of the snapshot, in hex.
+	ChainHash string `json:"chain_hash"`
+	Beacons   int    `json:"beacons"`
+	// Objects are the checksums of the objects of the backup by name.
+	Objects map[string]Checksum `json:"objects"`
+}
+
+// Checksum is the size and the SHA-256 hash, in hex, of an object.
+type Checksum struct {
+	Size   int64  `json:"size"`
+	SHA256 string `json:"sha256"`
+}
+
+// Status is the status of the backups of a node.
+type Status struct {
+	Running bool `json:"running"`
+	// Last is the last successful backup, if any.
+	Last    *Backup   `json:"last,omitempty"`
+	LastRun time.Time `json:"last_run,omitempty"`
+	// Beacons is the number of beacons of the last successful backup.
+	Beacons int `json:"beacons"`
+	// Deleted is the number of backups deleted by the retention rules.
+	Deleted int    `json:"deleted"`
+	Error   string `json:"error,omitempty"`
+}
+
+// Manager backs up a node at a regular interval.
+type Manager struct {
+	conf   *Config
+	folder string
+	store  chain.Store
+	info   func() *chain.Info
+	l      log.Logger
+
+	// run serializes the backups
+	run    sync.Mutex
+	mu     sync.Mutex
+	status Status
+}
+
+// NewManager returns a manager backing up the beacons of the store, of the
+// chain returned by info, and the key material of the configuration folder.
+func NewManager(c *Config, configFolder string, s chain.Store, info func() *chain.Info, l log.Logger) *Manager {
+	return &Manager{conf: c, folder: configFolder, store: s, info: info, l: l}
+}
+
+// Run backs up the node at each interval until the context is done.
+func (m *Manager) Run(ctx context.Context) {
+	interval := m.conf.Interval
+	if interval <= 0 {
+		interval = DefaultInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			if _, err := m.Backup(ctx); err != nil {
+				m.l.Error("backup", "failed", "err", err)
+			}
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// Status returns the status of the backups.
+func (m *Manager) Status() Status {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.status
+}
+
+func (m *Manager) setStatus(fn func(*Status)) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	fn(&m.status)
+}
+
+// Backup backs up the node right away, then deletes the backups the retention
+// rules do not keep.
+func (m *Manager) Backup(ctx context.Context) (*Backup, error) {
+	m.run.Lock()
+	defer m.run.Unlock()
+	now := time.Now().UTC()
+	m.setStatus(func(s *Status) {
+		s.Running = true
+		s.LastRun = now
+	})
+	b, n, err := m.backup(ctx, now)
+	var deleted int
+	if err == nil {
+		deleted, err = Prune(ctx, m.conf.Bucket, m.conf.Prefix, m.conf.Keep, m.conf.MaxAge, now)
+		if err != nil {
+			err = fmt.Errorf("applying retention rules: %w", err)
+		}
+	}
+	m.setStatus(func(s *Status) {
+		s.Running = false
+		s.Error = ""
+		if b != nil {
+			s.Last = b
+			s.Beacons = n
+		}
+		s.Deleted += deleted
+		if err != nil {
+			s.Error = err.Error()
+		}
+	})
+	event := audit.Event{
+		Action:  audit.ActionBackup,
+		Outcome: audit.Outcome(err),
+		Actor:   audit.Actor(ctx),
+		Details: map[string]string{"prefix": m.conf.Prefix},
+	}
+	if err != nil {
+		event.Error = err.Error()
+	}
+	if b != nil {
+		event.Details["name"] = b.Name
+		event.Details["keys"] = strconv.FormatBool(b.Keys)
+	}
+	m.conf.Audit.Record(event)
+	if b == nil {
+		metrics.BackupFailures.Inc()
+		return nil, err
+	}
+	metrics.BackupTimestamp.Set(float64(now.Unix()))
+	m.l.Info("backup", "done", "name", b.Name, "beacons", n, "keys", b.Keys, "deleted", deleted)
+	return b, err
+}
+
+func (m *Manager) backup(ctx context.Context, now time.Time) (*Backup, int, error) {
+	info := m.info()
+	if info == nil {
+		return nil, 0, errors.New("the node has no chain to back up yet")
+	}
+	return Create(ctx, m.conf.Bucket, m.conf.Prefix, m.folder, m.store, info, m.conf.Passphrase, now)
+}
+
+// Create backs up the beacons of the store, of the given chain, and, when a
+// passphrase is given, the key material of the configuration folder, as the
+// backup of the given time. The manifest of the backup is uploaded last, so a
+// backup interrupted midway has none. It returns the backup and the number of
+// beacons backed up.
+func Create(ctx context.Context, b Bucket, prefix, configFolder string, s chain.Store, info *chain.Info,
+	passphrase []byte, now time.Time) (*Backup, int, error) {
+	now = now.UTC()
+	bk := &Backup{Name: now.Format(nameLayout), Time: now}
+	dir := path.Join(prefix, bk.Name)
+	manifest := &Manifest{
+		ChainHash: hex.EncodeToString(info.Hash()),
+		Objects:   make(map[string]Checksum),
+	}
+	// the key material first, as it is small and most needed to recover
+	if len(passphrase) > 0 {
+		keys, err := archiveKeys(configFolder, passphrase)
+		if err != nil {
+			return nil, 0, fmt.Errorf("archiving the key material: %w", err)
+		}
+		if err := b.Put(ctx, path.Join(dir, KeysObject), bytes.NewReader(keys)); err != nil {
+			return nil, 0, fmt.Errorf("uploading the key material: %w", err)
+		}
+		sum := sha256.Sum256(keys)
+		manifest.Objects[KeysObject] = Checksum{Size: int64(len(keys)), SHA256: hex.EncodeToString(sum[:])}
+		bk.Keys = true
+		bk.Size += int64(len(keys))
+	}
+	pr, pw := io.Pipe()
+	counter := &countingReader{r: pr, h: sha256.New()}
+	var n int
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		var err error
+		n, err = chain.ExportSnapshot(pw, info, s, 0, 0)
+		pw.CloseWithError(err)
+	}()
+	err := b.Put(ctx, path.Join(dir, SnapshotObject), counter)
+	// unblock the export if the upload failed
+	pr.CloseWithError(errors.New("upload stopped"))
+	<-done
+	if err != nil {
+		return nil, 0, fmt.Errorf("uploading the snapshot: %w", err)
+	}
+	manifest.Objects[SnapshotObject] = Checksum{Size: counter.n, SHA256: hex.EncodeToString(counter.h.Sum(nil))}
+	manifest.Beacons = n
+	bk.Snapshot = true
+	bk.Size += counter.n
+
+	buff, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return nil, 0, err
+	}
+	if err := b.Put(ctx, path.Join(dir, ManifestObject), bytes.NewReader(buff)); err != nil {
+		return nil, 0, fmt.Errorf("uploading the manifest: %w", err)
+	}
+	bk.Manifest = true
+	bk.Size += int64(len(buff))
+	return bk, n, nil
+}
+
+// countingReader counts and hashes the bytes read.
+type countingReader struct {
+	r io.Reader
+	h hash.Hash
+	n int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += int64(n)
+	if c.h != nil {
+		c.h.Write(p[:n])
+	}
+	return n, err
+}
+
+// keyFolders are the folders of the key material, relative to the
+// configuration folder.
+var keyFolders = []string{key.KeyFolderName, key.GroupFolderName}
+
+// archiveKeys returns the encrypted archive of the key material of the
+// configuration folder.
+func archiveKeys(configFolder string, passphrase []byte) ([]byte, error) {
+	var buff bytes.Buffer
+	gz := gzip.NewWriter(&buff)
+	tw := tar.NewWriter(gz)
+	for _, folder := range keyFolders {
+		files, err := fs.Files(filepath.Join(configFolder, folder))
+		if err != nil && !os.IsNotExist(err) {
+			return nil, err
+		}
+		for _, file := range files {
+			content, err := ioutil.ReadFile(file)
+			if err != nil {
+				return nil, err
+			}
+			hdr := &tar.Header{
+				Name: path.Join(folder, filepath.Base(file)),
+				Mode: 0600,
+				Size: int64(len(content)),
+			}
+			if err := tw.WriteHeader(hdr); err != nil {
+				return nil, err
+			}
+			if _, err := tw.Write(content); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if err := tw.Close(); err != nil {
+		return nil, err
+	}
+	if err := gz.Close(); err != nil {
+		return nil, err
+	}
+	return Encrypt(passphrase, buff.Bytes())
+}
+
+// List returns the backups stored under the prefix, from the oldest to the
+// newest.
+func List(ctx context.Context, b Bucket, prefix string) ([]*Backup, error) {
+	objects, err := b.List(ctx, prefix)
+	if err != nil {
+		return nil, err
+	}
+	byName := make(map[string]*Backup)
+	for _, o := range objects {
+		rel := strings.TrimPrefix(strings.TrimPrefix(o.Name, prefix), "/")
+		parts := strings.Split(rel, "/")
+		if len(parts) != 2 {
+			continue
+		}
+		t, err := time.Parse(nameLayout, parts[0])
+		if err != nil {
+			continue
+		}
+		bk, ok := byName[parts[0]]
+		if !ok {
+			bk = &Backup{Name: parts[0], Time: t}
+			byName[parts[0]] = bk
+		}
+		switch parts[1] {
+		case SnapshotObject:
+			bk.Snapshot = true
+		case KeysObject:
+			bk.Keys = true
+		case ManifestObject:
+			bk.Manifest = true
+		default:
+			continue
+		}
+		bk.Size += o.Size
+	}
+	backups := make([]*Backup, 0, len(byName))
+	for _, bk := range byName {
+		backups = append(backups, bk)
+	}
+	sort.Slice(backups, func(i, j int) bool { return backups[i].Time.Before(backups[j].Time) })
+	return backups, nil
+}
+
+// Prune deletes the backups stored under the prefix beyond the `keep` newest
+// ones, and the ones older than maxAge, zero values disabling the rule. The
+// newest backup is always kept. It returns the number of backups deleted.
+func Prune(ctx context.Context, b Bucket, prefix string, keep int, maxAge time.Duration, now time.Time) (int, error) {
+	backups, err := List(ctx, b, prefix)
+	if err != nil {
+		return 0, err
+	}
+	if len(backups) == 0 {
+		return 0, nil
+	}
+	var deleted int
+	for i, bk := range backups[:len(backups)-1] {
+		tooMany := keep > 0 && len(backups)-i > keep
+		tooOld := maxAge > 0 && now.Sub(bk.Time) > maxAge
+		if !tooMany && !tooOld {
+			continue
+		}
+		for _, object := range objectsOf(bk) {
+			if err := b.Delete(ctx, path.Join(prefix, bk.Name, object)); err != nil {
+				return deleted, err
+			}
+		}
+		deleted++
+	}
+	return deleted, nil
+}
+
+func objectsOf(bk *Backup) []string {
+	var objects []string
+	if bk.Keys {
+		objects = append(objects, KeysObject)
+	}
+	if bk.Snapshot {
+		objects = append(objects, SnapshotObject)
+	}
+	if bk.Manifest {
+		objects = append(objects, ManifestObject)
+	}
+	return objects
+}
+
+// Find returns the backup of the given name stored under the prefix, or the
+// newest one if the name is empty.
+func Find(ctx context.Context, b Bucket, prefix, name string) (*Backup, error) {
+	backups, err := List(ctx, b, prefix)
+	if err != nil {
+		return nil, err
+	}
+	if len(backups) == 0 {
+		return nil, errors.New("no backup found")
+	}
+	if name == "" {
+		return backups[len(backups)-1], nil
+	}
+	for _, bk := range backups {
+		if bk.Name == name {
+			return bk, nil
+		}
+	}
+	return nil, fmt.Errorf("backup %s not found", name)
+}
+
+// Verify checks the objects of the backup against its manifest, and returns
+// the manifest. It errors when the backup has no manifest, as the backups
+// interrupted midway, or when an object is missing or was altered.
+func Verify(ctx context.Context, b Bucket, prefix string, bk *Backup) (*Manifest, error) {
+	if !bk.Manifest {
+		return nil, fmt.Errorf("backup %s has no manifest, it is incomplete or older than the manifests", bk.Name)
+	}
+	dir := path.Join(prefix, bk.Name)
+	r, err := b.Get(ctx, path.Join(dir, ManifestObject))
+	if err != nil {
+		return nil, err
+	}
+	buff, err := ioutil.ReadAll(io.LimitReader(r, maxManifest))
+	r.Close()
+	if err != nil {
+		return nil, err
+	}
+	manifest := new(Manifest)
+	if err := json.Unmarshal(buff, manifest); err != nil {
+		return nil, fmt.Errorf("invalid manifest: %w", err)
+	}
+	if _, ok := manifest.Objects[SnapshotObject]; !ok {
+		return nil, errors.New("the manifest has no snapshot")
+	}
+	for _, object := range []string{KeysObject, SnapshotObject} {
+		sum, ok := manifest.Objects[object]
+		if !ok {
+			if object == KeysObject && bk.Keys {
+				return nil, fmt.Errorf("%s is not in the manifest", object)
+			}
+			continue
+		}
+		r, err := b.Get(ctx, path.Join(dir, object))
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", object, err)
+		}
+		counter := &countingReader{r: r, h: sha256.New()}
+		_, err = io.Copy(ioutil.Discard, counter)
+		r.Close()
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", object, err)
+		}
+		if counter.n != sum.Size || hex.EncodeToString(counter.h.Sum(nil)) != sum.SHA256 {
+			return nil, fmt.Errorf("%s does not match the checksum of the manifest", object)
+		}
+	}
+	return manifest, nil
+}
+
+// RestoreKeys decrypts the key material of the backup into the configuration
+// folder, and returns the files written. It refuses to overwrite the key
+// material of the folder.
+func RestoreKeys(ctx context.Context, b Bucket, prefix string, bk *Backup, passphrase []byte, configFolder string) ([]string, error) {
+	if !bk.Keys {
+		return nil, fmt.Errorf("backup %s holds no key material", bk.Name)
+	}
+	r, err := b.Get(ctx, path.Join(prefix, bk.Name, KeysObject))
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	encrypted, err := ioutil.ReadAll(io.LimitReader(r, maxKeysArchive))
+	if err != nil {
+		return nil, err
+	}
+	archive, err := Decrypt(passphrase, encrypted)
+	if err != nil {
+		return nil, err
+	}
+	gz, err := gzip.NewReader(bytes.NewReader(archive))
+	if err != nil {
+		return nil, err
+	}
+	type file struct {
+		path    string
+		content []byte
+	}
+	var files []file
+	tr := tar.NewReader(gz)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, err
+		}
+		folder, name := path.Split(path.Clean(hdr.Name))
+		if !isKeyFolder(path.Clean(folder)) || name == "" {
+			return nil, fmt.Errorf("unexpected file %s in the key material", hdr.Name)
+		}
+		content, err := ioutil.ReadAll(tr)
+		if err != nil {
+			return nil, err
+		}
+		p := filepath.Join(configFolder, filepath.FromSlash(path.Clean(hdr.Name)))
+		if exists, _ := fs.Exists(p); exists {
+			return nil, fmt.Errorf("%s already exists", p)
+		}
+		files = append(files, file{path: p, content: content})
+	}
+	// only write once the whole archive is read and checked
+	var written []string
+	for _, f := range files {
+		if fs.CreateSecureFolder(filepath.Dir(f.path)) == "" {
+			return written, fmt.Errorf("can't create the folder of %s", f.path)
+		}
+		if err := ioutil.WriteFile(f.path, f.content, 0600); err != nil {
+			return written, err
+		}
+		written = append(written, f.path)
+	}
+	return written, nil
+}
+
+func isKeyFolder(folder string) bool {
+	for _, f := range keyFolders {
+		if folder == f {
+			return true
+		}
+	}
+	return false
+}
+
+// RestoreChain verifies the beacons of the snapshot of the backup and imports
+// them into the store, as chain.ImportSnapshot does.
+func RestoreChain(ctx context.Context, b Bucket, prefix string, bk *Backup, s chain.Store, trusted *chain.Info) (int, error) {
+	if !bk.Snapshot {
+		return 0, fmt.Errorf("backup %s holds no snapshot", bk.Name)
+	}
+	r, err := b.Get(ctx, path.Join(prefix, bk.Name, SnapshotObject))
+	if err != nil {
+		return 0, err
+	}
+	defer r.Close()
+	_, n, err := chain.ImportSnapshot(s, r, trusted)
+	return n, err
+}
@@ -0,0 +1,182 @@
+package backup
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/drand/drand/chain"
+	"github.com/drand/drand/chain/boltdb"
+	"github.com/drand/drand/key"
+	"github.com/drand/drand/log"
+	"github.com/drand/kyber/util/random"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEncrypt(t *testing.T) {
+	pass := []byte("passphrase")
+	ciphertext, err := Encrypt(pass, []byte("share"))
+	require.NoError(t, err)
+	plaintext, err := Decrypt(pass, ciphertext)
+	require.NoError(t, err)
+	require.Equal(t, []byte("share"), plaintext)
+
+	_, err = Decrypt([]byte("wrong"), ciphertext)
+	require.Error(t, err)
+	ciphertext[len(ciphertext)-1] ^= 0xff
+	_, err = Decrypt(pass, ciphertext)
+	require.Error(t, err)
+}
+
+func testChain(t *testing.T, folder string, rounds uint64) (*chain.Info, chain.Store) {
+	priv := key.KeyGroup.Scalar().Pick(random.New())
+	info := &chain.Info{
+		PublicKey:   key.KeyGroup.Point().Mul(priv, nil),
+		Period:      time.Second,
+		GenesisTime: 1000,
+		GroupHash:   []byte("group"),
+	}
+	require.NoError(t, os.MkdirAll(folder, 0700))
+	s, err := boltdb.NewBoltStore(folder, nil)
+	require.NoError(t, err)
+	prev := chain.GenesisBeacon(info)
+	require.NoError(t, s.Put(prev))
+	for round := uint64(1); round <= rounds; round++ {
+		sig, err := key.AuthScheme.Sign(priv, chain.Message(round, prev.Signature))
+		require.NoError(t, err)
+		prev = &chain.Beacon{Round: round, Signature: sig, PreviousSig: prev.Signature}
+		require.NoError(t, s.Put(prev))
+	}
+	return info, s
+}
+
+func TestBackupRestore(t *testing.T) {
+	tmp, err := ioutil.TempDir("", "backup")
+	require.NoError(t, err)
+	defer os.RemoveAll(tmp)
+
+	configFolder := filepath.Join(tmp, "node")
+	for _, f := range []string{"key/drand_id.private", "groups/dist_key.private"} {
+		p := filepath.Join(configFolder, f)
+		require.NoError(t, os.MkdirAll(filepath.Dir(p), 0700))
+		require.NoError(t, ioutil.WriteFile(p, []byte(f), 0600))
+	}
+	info, s := testChain(t, filepath.Join(tmp, "db"), 10)
+	defer s.Close()
+
+	ctx := context.Background()
+	bucket := NewDirBucket(filepath.Join(tmp, "bucket"))
+	conf := &Config{Bucket: bucket, Prefix: "node1", Passphrase: []byte("passphrase")}
+	m := NewManager(conf, configFolder, s, func() *chain.Info { return info }, log.DefaultLogger())
+	bk, err := m.Backup(ctx)
+	require.NoError(t, err)
+	require.True(t, bk.Keys)
+	require.True(t, bk.Snapshot)
+	require.True(t, bk.Manifest)
+	require.Equal(t, 11, m.Status().Beacons)
+
+	found, err := Find(ctx, bucket, "node1", "")
+	require.NoError(t, err)
+	require.Equal(t, bk.Name, found.Name)
+	manifest, err := Verify(ctx, bucket, "node1", found)
+	require.NoError(t, err)
+	require.Equal(t, 11, manifest.Beacons)
+	require.Len(t, manifest.Objects, 2)
+
+	restored := filepath.Join(tmp, "restored")
+	files, err := RestoreKeys(ctx, bucket, "node1", found, []byte("passphrase"), restored)
+	require.NoError(t, err)
+	require.Len(t, files, 2)
+	content, err := ioutil.ReadFile(filepath.Join(restored, "key/drand_id.private"))
+	require.NoError(t, err)
+	require.Equal(t, []byte("key/drand_id.private"), content)
+	// the restored key material is never overwritten
+	_, err = RestoreKeys(ctx, bucket, "node1", found, []byte("passphrase"), restored)
+	require.Error(t, err)
+	_, err = RestoreKeys(ctx, bucket, "node1", found, []byte("wrong"), filepath.Join(tmp, "other"))
+	require.Error(t, err)
+
+	require.NoError(t, os.MkdirAll(filepath.Join(tmp, "restored-db"), 0700))
+	dst, err := boltdb.NewBoltStore(filepath.Join(tmp, "restored-db"), nil)
+	require.NoError(t, err)
+	defer dst.Close()
+	n, err := RestoreChain(ctx, bucket, "node1", found, dst, info)
+	require.NoError(t, err)
+	require.Equal(t, 11, n)
+	require.Equal(t, 11, dst.Len())
+}
+
+func TestVerify(t *testing.T) {
+	tmp, err := ioutil.TempDir("", "backup")
+	require.NoError(t, err)
+	defer os.RemoveAll(tmp)
+	info, s := testChain(t, filepath.Join(tmp, "db"), 5)
+	defer s.Close()
+
+	ctx := context.Background()
+	bucket := NewDirBucket(filepath.Join(tmp, "bucket"))
+	now := time.Date(2020, 10, 1, 0, 0, 0, 0, time.UTC)
+	bk, n, err := Create(ctx, bucket, "p", filepath.Join(tmp, "node"), s, info, nil, now)
+	require.NoError(t, err)
+	require.Equal(t, 6, n)
+	require.False(t, bk.Keys)
+	_, err = Verify(ctx, bucket, "p", bk)
+	require.NoError(t, err)
+
+	// an altered snapshot does not match the manifest
+	snapshot := "p/" + bk.Name + "/" + SnapshotObject
+	r, err := bucket.Get(ctx, snapshot)
+	require.NoError(t, err)
+	content, err := ioutil.ReadAll(r)
+	r.Close()
+	require.NoError(t, err)
+	content[len(content)-1] ^= 0xff
+	require.NoError(t, bucket.Put(ctx, snapshot, bytes.NewReader(content)))
+	_, err = Verify(ctx, bucket, "p", bk)
+	require.Error(t, err)
+
+	// a backup without manifest is not verified
+	require.NoError(t, bucket.Delete(ctx, "p/"+bk.Name+"/"+ManifestObject))
+	found, err := Find(ctx, bucket, "p", "")
+	require.NoError(t, err)
+	require.False(t, found.Manifest)
+	_, err = Verify(ctx, bucket, "p", found)
+	require.Error(t, err)
+}
+
+func TestPrune(t *testing.T) {
+	tmp, err := ioutil.TempDir("", "backup")
+	require.NoError(t, err)
+	defer os.RemoveAll(tmp)
+	ctx := context.Background()
+	bucket := NewDirBucket(tmp)
+
+	now := time.Date(2020, 10, 1, 0, 0, 0, 0, time.UTC)
+	for i := 0; i < 5; i++ {
+		name := now.Add(-time.Duration(i) * 24 * time.Hour).Format(nameLayout)
+		require.NoError(t, bucket.Put(ctx, "p/"+name+"/"+SnapshotObject, strings.NewReader("")))
+	}
+	deleted, err := Prune(ctx, bucket, "p", 4, 0, now)
+	require.NoError(t, err)
+	require.Equal(t, 1, deleted)
+	deleted, err = Prune(ctx, bucket, "p", 0, 36*time.Hour, now)
+	require.NoError(t, err)
+	require.Equal(t, 2, deleted)
+	backups, err := List(ctx, bucket, "p")
+	require.NoError(t, err)
+	require.Len(t, backups, 2)
+	require.Equal(t, now.Format(nameLayout), backups[1].Name)
+
+	// the last backup is kept whatever its age
+	deleted, err = Prune(ctx, bucket, "p", 0, time.Hour, now.Add(48*time.Hour))
+	require.NoError(t, err)
+	require.Equal(t, 1, deleted)
+	backups, err = List(ctx, bucket, "p")
+	require.NoError(t, err)
+	require.Len(t, backups, 1)
+}
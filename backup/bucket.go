@@ -0,0 +1,150 @@
+package backup
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/drand/drand/fs"
+)
+
+// Object describes an object of a bucket.
+type Object struct {
+	Name     string
+	Size     int64
+	Modified time.Time
+}
+
+// Bucket is an object storage the backups are uploaded to. Names are slash
+// separated paths relative to the bucket.
+type Bucket interface {
+	// Put uploads the content of the reader under the given name, replacing
+	// the object of that name if any.
+	Put(ctx context.Context, name string, r io.Reader) error
+	// Get returns the content of the object of the given name.
+	Get(ctx context.Context, name string) (io.ReadCloser, error)
+	// List returns the objects whose name starts with the prefix, sorted by
+	// name.
+	List(ctx context.Context, prefix string) ([]Object, error)
+	// Delete deletes the object of the given name.
+	Delete(ctx context.Context, name string) error
+}
+
+// OpenBucket returns the bucket of the given URL, and the prefix of the backups
+// in the bucket. The URL is either:
+//   - s3://bucket/prefix for an AWS S3 bucket, or an S3 compatible storage
+//     when the endpoint of the options is set,
+//   - gs://bucket/prefix for a Google Cloud Storage bucket, through its S3
+//     compatible API with HMAC keys,
+//   - file:///folder/prefix for a local folder, such as a network mount.
+func OpenBucket(rawURL string, opts S3Options) (Bucket, string, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, "", fmt.Errorf("invalid backup url: %w", err)
+	}
+	prefix := strings.Trim(u.Path, "/")
+	switch u.Scheme {
+	case "s3":
+		opts.Bucket = u.Host
+		b, err := NewS3Bucket(opts)
+		return b, prefix, err
+	case "gs":
+		opts.Bucket = u.Host
+		if opts.Endpoint == "" {
+			opts.Endpoint = GCSEndpoint
+		}
+		b, err := NewS3Bucket(opts)
+		return b, prefix, err
+	case "file":
+		if u.Host != "" {
+			return nil, "", errors.New("a file backup url must be absolute, as in file:///folder")
+		}
+		return NewDirBucket(u.Path), "", nil
+	}
+	return nil, "", fmt.Errorf("unsupported backup url scheme %q, must be s3, gs or file", u.Scheme)
+}
+
+// dirBucket is a Bucket storing the objects as files of a local folder.
+type dirBucket struct {
+	folder string
+}
+
+// NewDirBucket returns a Bucket storing the objects in the given folder.
+func NewDirBucket(folder string) Bucket {
+	return &dirBucket{folder: folder}
+}
+
+func (d *dirBucket) path(name string) string {
+	return filepath.Join(d.folder, filepath.FromSlash(path.Clean("/"+name)))
+}
+
+func (d *dirBucket) Put(ctx context.Context, name string, r io.Reader) error {
+	p := d.path(name)
+	if fs.CreateSecureFolder(filepath.Dir(p)) == "" {
+		return fmt.Errorf("can't create the folder of %s", name)
+	}
+	// write to a temporary file first so that a failed upload does not leave
+	// a partial object behind
+	tmp, err := ioutil.TempFile(filepath.Dir(p), ".upload")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := io.Copy(tmp, r); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), p)
+}
+
+func (d *dirBucket) Get(ctx context.Context, name string) (io.ReadCloser, error) {
+	return os.Open(d.path(name))
+}
+
+func (d *dirBucket) List(ctx context.Context, prefix string) ([]Object, error) {
+	var objects []Object
+	err := filepath.Walk(d.folder, func(p string, info os.FileInfo, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) {
+				return nil
+			}
+			return err
+		}
+		if info.IsDir() || strings.HasPrefix(info.Name(), ".upload") {
+			return nil
+		}
+		rel, err := filepath.Rel(d.folder, p)
+		if err != nil {
+			return err
+		}
+		name := filepath.ToSlash(rel)
+		if strings.HasPrefix(name, prefix) {
+			objects = append(objects, Object{Name: name, Size: info.Size(), Modified: info.ModTime()})
+		}
+		return nil
+	})
+	sort.Slice(objects, func(i, j int) bool { return objects[i].Name < objects[j].Name })
+	return objects, err
+}
+
+func (d *dirBucket) Delete(ctx context.Context, name string) error {
+	p := d.path(name)
+	if err := os.Remove(p); err != nil {
+		return err
+	}
+	// remove the folder of the backup once empty
+	_ = os.Remove(filepath.Dir(p))
+	return nil
+}
@@ -0,0 +1,80 @@
+package backup
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"errors"
+	"fmt"
+
+	"golang.org/x/crypto/scrypt"
+)
+
+// The key material is encrypted with AES-256-GCM, under a key derived from the
+// passphrase with scrypt. An encrypted archive is the encryption version,
+// followed by the salt, the nonce and the ciphertext.
+const (
+	encryptionVersion = 1
+	saltSize          = 16
+	scryptN           = 1 << 15
+	scryptR           = 8
+	scryptP           = 1
+)
+
+func deriveKey(passphrase, salt []byte) (cipher.AEAD, error) {
+	key, err := scrypt.Key(passphrase, salt, scryptN, scryptR, scryptP, 32)
+	if err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// Encrypt encrypts the plaintext under the passphrase.
+func Encrypt(passphrase, plaintext []byte) ([]byte, error) {
+	if len(passphrase) == 0 {
+		return nil, errors.New("empty passphrase")
+	}
+	salt := make([]byte, saltSize)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+	aead, err := deriveKey(passphrase, salt)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	out := append([]byte{encryptionVersion}, salt...)
+	out = append(out, nonce...)
+	// the header is authenticated along with the ciphertext
+	return aead.Seal(out, nonce, plaintext, out), nil
+}
+
+// Decrypt decrypts a ciphertext of Encrypt under the passphrase.
+func Decrypt(passphrase, ciphertext []byte) ([]byte, error) {
+	if len(ciphertext) == 0 || ciphertext[0] != encryptionVersion {
+		return nil, errors.New("unsupported encryption version")
+	}
+	if len(ciphertext) < 1+saltSize {
+		return nil, errors.New("ciphertext too short")
+	}
+	aead, err := deriveKey(passphrase, ciphertext[1:1+saltSize])
+	if err != nil {
+		return nil, err
+	}
+	header := 1 + saltSize + aead.NonceSize()
+	if len(ciphertext) < header {
+		return nil, errors.New("ciphertext too short")
+	}
+	plaintext, err := aead.Open(nil, ciphertext[1+saltSize:header], ciphertext[header:], ciphertext[:header])
+	if err != nil {
+		return nil, fmt.Errorf("wrong passphrase or corrupted ciphertext: %w", err)
+	}
+	return plaintext, nil
+}
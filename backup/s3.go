@@ -0,0 +1,113 @@
+package backup
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3manager"
+)
+
+// GCSEndpoint is the endpoint of the S3 compatible API of Google Cloud
+// Storage, which authenticates with the HMAC keys of a service account given
+// as AWS credentials.
+const GCSEndpoint = "https://storage.googleapis.com"
+
+// S3Options are the options of an S3 bucket. The credentials are read from
+// the environment and the shared configuration files, as for the AWS CLI.
+type S3Options struct {
+	Bucket string
+	// Region of the bucket, optional.
+	Region string
+	// Endpoint is the URL of an S3 compatible storage, empty for AWS.
+	Endpoint string
+	// PathStyle addresses the bucket in the path of the URLs instead of the
+	// host name, as most self hosted storages require.
+	PathStyle bool
+}
+
+type s3Bucket struct {
+	bucket string
+	client *s3.S3
+	upr    *s3manager.Uploader
+}
+
+// NewS3Bucket returns a Bucket storing the objects in an S3 bucket.
+func NewS3Bucket(opts S3Options) (Bucket, error) {
+	if opts.Bucket == "" {
+		return nil, fmt.Errorf("no bucket name given")
+	}
+	conf := &aws.Config{S3ForcePathStyle: aws.Bool(opts.PathStyle)}
+	if opts.Region != "" {
+		conf.Region = aws.String(opts.Region)
+	} else if opts.Endpoint != "" {
+		// the region only matters to sign the requests of a compatible storage
+		conf.Region = aws.String("auto")
+	}
+	if opts.Endpoint != "" {
+		conf.Endpoint = aws.String(opts.Endpoint)
+	}
+	sess, err := session.NewSession(conf)
+	if err != nil {
+		return nil, fmt.Errorf("creating aws session: %w", err)
+	}
+	if _, err := sess.Config.Credentials.Get(); err != nil {
+		return nil, fmt.Errorf("checking credentials: %w", err)
+	}
+	return &s3Bucket{
+		bucket: opts.Bucket,
+		client: s3.New(sess),
+		upr:    s3manager.NewUploader(sess),
+	}, nil
+}
+
+func (b *s3Bucket) Put(ctx context.Context, name string, r io.Reader) error {
+	_, err := b.upr.UploadWithContext(ctx, &s3manager.UploadInput{
+		Bucket: aws.String(b.bucket),
+		Key:    aws.String(name),
+		Body:   r,
+	})
+	return err
+}
+
+func (b *s3Bucket) Get(ctx context.Context, name string) (io.ReadCloser, error) {
+	out, err := b.client.GetObjectWithContext(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(b.bucket),
+		Key:    aws.String(name),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return out.Body, nil
+}
+
+func (b *s3Bucket) List(ctx context.Context, prefix string) ([]Object, error) {
+	var objects []Object
+	err := b.client.ListObjectsV2PagesWithContext(ctx, &s3.ListObjectsV2Input{
+		Bucket: aws.String(b.bucket),
+		Prefix: aws.String(prefix),
+	}, func(page *s3.ListObjectsV2Output, last bool) bool {
+		for _, o := range page.Contents {
+			objects = append(objects, Object{
+				Name:     aws.StringValue(o.Key),
+				Size:     aws.Int64Value(o.Size),
+				Modified: aws.TimeValue(o.LastModified),
+			})
+		}
+		return true
+	})
+	sort.Slice(objects, func(i, j int) bool { return objects[i].Name < objects[j].Name })
+	return objects, err
+}
+
+func (b *s3Bucket) Delete(ctx context.Context, name string) error {
+	_, err := b.client.DeleteObjectWithContext(ctx, &s3.DeleteObjectInput{
+		Bucket: aws.String(b.bucket),
+		Key:    aws.String(name),
+	})
+	return err
+}
@@ -0,0 +1,193 @@
+package chain
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/drand/drand/key"
+)
+
+// BeaconIterator delivers beacons in increasing round order.
+type BeaconIterator interface {
+	// Next returns the next beacon, or nil once all beacons were delivered.
+	Next() (*Beacon, error)
+}
+
+// InconsistencyKind is the kind of inconsistency found in a range of beacons.
+type InconsistencyKind int
+
+const (
+	// InvalidSignature means the signature of the beacon does not verify
+	// against the chain.
+	InvalidSignature InconsistencyKind = iota
+	// BrokenLink means the previous signature of the beacon is not the
+	// signature of the previous round.
+	BrokenLink
+	// MissingRound means rounds are missing before the beacon.
+	MissingRound
+	// OutOfOrder means the round of the beacon is not greater than the round
+	// of the previous one.
+	OutOfOrder
+)
+
+func (k InconsistencyKind) String() string {
+	switch k {
+	case InvalidSignature:
+		return "invalid signature"
+	case BrokenLink:
+		return "broken link"
+	case MissingRound:
+		return "missing round"
+	case OutOfOrder:
+		return "out of order"
+	default:
+		return fmt.Sprintf("InconsistencyKind(%d)", int(k))
+	}
+}
+
+// Inconsistency describes the first inconsistency found in a range of
+// beacons.
+type Inconsistency struct {
+	Kind InconsistencyKind
+	// Round is the round of the inconsistent beacon.
+	Round uint64
+	// Previous is the round of the beacon delivered before it, if any.
+	Previous uint64
+	// Err is the verification error of an invalid signature.
+	Err error
+}
+
+func (i *Inconsistency) Error() string {
+	if i.Err != nil {
+		return fmt.Sprintf("round %d: %s: %s", i.Round, i.Kind, i.Err)
+	}
+	return fmt.Sprintf("round %d: %s after round %d", i.Round, i.Kind, i.Previous)
+}
+
+// ChainReport is the result of the audit of a range of beacons.
+type ChainReport struct {
+	// First and Last are the first and last rounds found valid.
+	First uint64
+	Last  uint64
+	// Verified is the number of beacons found valid.
+	Verified int
+	// Inconsistency is the first inconsistency found, nil when all the
+	// beacons are valid. The audit stops at the first inconsistency.
+	Inconsistency *Inconsistency
+}
+
+// VerifyChain audits a contiguous range of beacons of a chain: it checks the
+// signature of each beacon and, for rounds signing the previous signature,
+// that the previous signature of each beacon is the signature of the
+// previous one. The genesis beacon is checked against the group hash. An
+// error is only returned when the scheme of the chain is unknown or the
+// iterator fails, inconsistencies being reported in the returned report.
+func VerifyChain(info *Info, it BeaconIterator) (*ChainReport, error) {
+	vf, err := NewVerifier(info)
+	if err != nil {
+		return nil, err
+	}
+	report := new(ChainReport)
+	var prev *Beacon
+	for {
+		b, err := it.Next()
+		if err != nil {
+			return report, err
+		}
+		if b == nil {
+			return report, nil
+		}
+		if inc := checkBeacon(vf, prev, b); inc != nil {
+			report.Inconsistency = inc
+			return report, nil
+		}
+		if prev == nil {
+			report.First = b.Round
+		}
+		report.Last = b.Round
+		report.Verified++
+		prev = b
+	}
+}
+
+// checkBeacon returns the inconsistency of a beacon following `prev`, which
+// is nil for the first beacon of a range.
+func checkBeacon(vf *Verifier, prev, b *Beacon) *Inconsistency {
+	info := vf.Info()
+	inc := &Inconsistency{Round: b.Round}
+	if prev != nil {
+		inc.Previous = prev.Round
+		switch {
+		case b.Round <= prev.Round:
+			inc.Kind = OutOfOrder
+			return inc
+		case b.Round > prev.Round+1:
+			inc.Kind = MissingRound
+			return inc
+		}
+	}
+	if b.Round == 0 {
+		if !key.ConstantTimeEqual(b.Signature, info.GroupHash) {
+			inc.Kind = InvalidSignature
+			inc.Err = errors.New("genesis signature is not the group hash")
+			return inc
+		}
+		return nil
+	}
+	if prev != nil && chained(info, b.Round) && !key.ConstantTimeEqual(b.PreviousSig, prev.Signature) {
+		inc.Kind = BrokenLink
+		return inc
+	}
+	if err := vf.VerifyBeacon(b); err != nil {
+		inc.Kind = InvalidSignature
+		inc.Err = err
+		return inc
+	}
+	return nil
+}
+
+// chained reports whether a round of the chain signs the previous signature.
+func chained(info *Info, round uint64) bool {
+	s, err := SchemeFromID(info.Scheme)
+	if err != nil || !s.Chained() {
+		return false
+	}
+	return info.V2From == 0 || round < info.V2From
+}
+
+// CursorIterator iterates over the beacons of a store cursor, from the first
+// one. It is only valid within the function given to Store.Cursor.
+func CursorIterator(c Cursor) BeaconIterator {
+	return &cursorIterator{c: c}
+}
+
+type cursorIterator struct {
+	c       Cursor
+	started bool
+}
+
+func (i *cursorIterator) Next() (*Beacon, error) {
+	if !i.started {
+		i.started = true
+		return i.c.First(), nil
+	}
+	return i.c.Next(), nil
+}
+
+// SliceIterator iterates over a slice of beacons.
+func SliceIterator(beacons []*Beacon) BeaconIterator {
+	return &sliceIterator{beacons: beacons}
+}
+
+type sliceIterator struct {
+	beacons []*Beacon
+}
+
+func (i *sliceIterator) Next() (*Beacon, error) {
+	if len(i.beacons) == 0 {
+		return nil, nil
+	}
+	b := i.beacons[0]
+	i.beacons = i.beacons[1:]
+	return b, nil
+}
@@ -0,0 +1,72 @@
+package chain
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/drand/drand/key"
+	"github.com/drand/kyber/util/random"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVerifyChain(t *testing.T) {
+	priv := key.KeyGroup.Scalar().Pick(random.New())
+	info := &Info{
+		PublicKey:   key.KeyGroup.Point().Mul(priv, nil),
+		Period:      time.Second,
+		GenesisTime: 1000,
+		GroupHash:   []byte("group"),
+	}
+	beacons := []*Beacon{GenesisBeacon(info)}
+	for round := uint64(1); round <= 5; round++ {
+		prev := beacons[round-1].Signature
+		sig, err := key.AuthScheme.Sign(priv, Message(round, prev))
+		require.NoError(t, err)
+		beacons = append(beacons, &Beacon{Round: round, Signature: sig, PreviousSig: prev})
+	}
+
+	report, err := VerifyChain(info, SliceIterator(beacons))
+	require.NoError(t, err)
+	require.Nil(t, report.Inconsistency)
+	require.Equal(t, uint64(0), report.First)
+	require.Equal(t, uint64(5), report.Last)
+	require.Equal(t, 6, report.Verified)
+
+	forged := *beacons[3]
+	forged.Signature = beacons[2].Signature
+	tampered := append(append([]*Beacon{}, beacons[:3]...), &forged)
+	report, err = VerifyChain(info, SliceIterator(tampered))
+	require.NoError(t, err)
+	require.Equal(t, InvalidSignature, report.Inconsistency.Kind)
+	require.Equal(t, uint64(3), report.Inconsistency.Round)
+	require.Equal(t, uint64(2), report.Last)
+
+	unlinked := []*Beacon{beacons[1], beacons[2], beacons[4]}
+	report, err = VerifyChain(info, SliceIterator(unlinked))
+	require.NoError(t, err)
+	require.Equal(t, MissingRound, report.Inconsistency.Kind)
+	require.Equal(t, uint64(2), report.Inconsistency.Previous)
+
+	relinked := *beacons[3]
+	relinked.PreviousSig = beacons[1].Signature
+	report, err = VerifyChain(info, SliceIterator([]*Beacon{beacons[2], &relinked}))
+	require.NoError(t, err)
+	require.Equal(t, BrokenLink, report.Inconsistency.Kind)
+
+	report, err = VerifyChain(info, SliceIterator([]*Beacon{beacons[2], beacons[1]}))
+	require.NoError(t, err)
+	require.Equal(t, OutOfOrder, report.Inconsistency.Kind)
+
+	fail := errors.New("fail")
+	_, err = VerifyChain(info, failingIterator{fail})
+	require.Equal(t, fail, err)
+}
+
+type failingIterator struct {
+	err error
+}
+
+func (i failingIterator) Next() (*Beacon, error) {
+	return nil, i.err
+}
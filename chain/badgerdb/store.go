@@ -0,0 +1,222 @@
+package badgerdb
+
+import (
+	"encoding/binary"
+	"errors"
+	"path"
+
+	"github.com/dgraph-io/badger/v2"
+	"github.com/drand/drand/chain"
+	"github.com/drand/drand/log"
+)
+
+// badgerStore implements the Store interface using the badger key/value
+// storage, whose log structured design writes much less than boltdb for the
+// append-only workload of a chain. Beacons are stored under their round
+// encoded in big endian, so that the keys are sorted by round.
+type badgerStore struct {
+	db *badger.DB
+}
+
+// FolderName is the name of the folder badger writes to
+const FolderName = "badger"
+
+// ErrNoBeaconSaved is the error returned when no beacon have been saved in the
+// database yet.
+var ErrNoBeaconSaved = errors.New("beacon not found in database")
+
+// NewBadgerStore returns a Store implementation using the badger storage
+// engine.
+func NewBadgerStore(folder string) (chain.Store, error) {
+	db, err := badger.Open(badger.DefaultOptions(path.Join(folder, FolderName)))
+	if err != nil {
+		return nil, err
+	}
+	return &badgerStore{db: db}, nil
+}
+
+func (b *badgerStore) Len() int {
+	var length int
+	_ = b.db.View(func(txn *badger.Txn) error {
+		opts := badger.DefaultIteratorOptions
+		opts.PrefetchValues = false
+		it := txn.NewIterator(opts)
+		defer it.Close()
+		for it.Rewind(); it.Valid(); it.Next() {
+			length++
+		}
+		return nil
+	})
+	return length
+}
+
+func (b *badgerStore) Close() {
+	if err := b.db.Close(); err != nil {
+		log.DefaultLogger().Debug("badgerdb", "close", "err", err)
+	}
+}
+
+// Put implements the Store interface. WARNING: It does NOT verify that this
+// beacon is not already saved in the database or not.
+func (b *badgerStore) Put(beacon *chain.Beacon) error {
+	buff, err := beacon.Marshal()
+	if err != nil {
+		return err
+	}
+	return b.db.Update(func(txn *badger.Txn) error {
+		return txn.Set(chain.RoundToBytes(beacon.Round), buff)
+	})
+}
+
+// Last returns the last beacon signature saved into the db
+func (b *badgerStore) Last() (*chain.Beacon, error) {
+	var beacon *chain.Beacon
+	err := b.db.View(func(txn *badger.Txn) error {
+		opts := badger.DefaultIteratorOptions
+		opts.Reverse = true
+		it := txn.NewIterator(opts)
+		defer it.Close()
+		it.Rewind()
+		if !it.Valid() {
+			return ErrNoBeaconSaved
+		}
+		var err error
+		beacon, err = decode(it.Item())
+		return err
+	})
+	return beacon, err
+}
+
+// Get returns the beacon saved at this round
+func (b *badgerStore) Get(round uint64) (*chain.Beacon, error) {
+	var beacon *chain.Beacon
+	err := b.db.View(func(txn *badger.Txn) error {
+		item, err := txn.Get(chain.RoundToBytes(round))
+		if errors.Is(err, badger.ErrKeyNotFound) {
+			return ErrNoBeaconSaved
+		} else if err != nil {
+			return err
+		}
+		beacon, err = decode(item)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return beacon, nil
+}
+
+func (b *badgerStore) Del(round uint64) error {
+	return b.db.Update(func(txn *badger.Txn) error {
+		return txn.Delete(chain.RoundToBytes(round))
+	})
+}
+
+// DelRange implements the chain.RangeDeleter interface.
+func (b *badgerStore) DelRange(from, to uint64, keep func(round uint64) bool) (int, error) {
+	var keys [][]byte
+	err := b.db.View(func(txn *badger.Txn) error {
+		opts := badger.DefaultIteratorOptions
+		opts.PrefetchValues = false
+		it := txn.NewIterator(opts)
+		defer it.Close()
+		for it.Seek(chain.RoundToBytes(from)); it.Valid(); it.Next() {
+			round := binary.BigEndian.Uint64(it.Item().Key())
+			if round > to {
+				break
+			}
+			if !keep(round) {
+				keys = append(keys, it.Item().KeyCopy(nil))
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	// a write batch splits the deletions in as many transactions as needed
+	wb := b.db.NewWriteBatch()
+	defer wb.Cancel()
+	for _, k := range keys {
+		if err := wb.Delete(k); err != nil {
+			return 0, err
+		}
+	}
+	if err := wb.Flush(); err != nil {
+		return 0, err
+	}
+	return len(keys), nil
+}
+
+func (b *badgerStore) Cursor(fn func(chain.Cursor)) {
+	err := b.db.View(func(txn *badger.Txn) error {
+		c := &badgerCursor{txn: txn, it: txn.NewIterator(badger.DefaultIteratorOptions)}
+		defer c.it.Close()
+		fn(c)
+		return nil
+	})
+	if err != nil {
+		log.DefaultLogger().Warn("badgerdb", "error getting cursor", "err", err)
+	}
+}
+
+func decode(item *badger.Item) (*chain.Beacon, error) {
+	v, err := item.ValueCopy(nil)
+	if err != nil {
+		return nil, err
+	}
+	b := new(chain.Beacon)
+	if err := b.Unmarshal(v); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+type badgerCursor struct {
+	txn *badger.Txn
+	it  *badger.Iterator
+}
+
+func (c *badgerCursor) current() *chain.Beacon {
+	if !c.it.Valid() {
+		return nil
+	}
+	b, err := decode(c.it.Item())
+	if err != nil {
+		return nil
+	}
+	return b
+}
+
+func (c *badgerCursor) First() *chain.Beacon {
+	c.it.Rewind()
+	return c.current()
+}
+
+func (c *badgerCursor) Next() *chain.Beacon {
+	if !c.it.Valid() {
+		return nil
+	}
+	c.it.Next()
+	return c.current()
+}
+
+func (c *badgerCursor) Seek(round uint64) *chain.Beacon {
+	c.it.Seek(chain.RoundToBytes(round))
+	return c.current()
+}
+
+// Last moves the cursor to the last beacon, after which Next returns nil as
+// for the other stores.
+func (c *badgerCursor) Last() *chain.Beacon {
+	opts := badger.DefaultIteratorOptions
+	opts.Reverse = true
+	rev := c.txn.NewIterator(opts)
+	defer rev.Close()
+	rev.Rewind()
+	if !rev.Valid() {
+		return nil
+	}
+	c.it.Seek(rev.Item().KeyCopy(nil))
+	return c.current()
+}
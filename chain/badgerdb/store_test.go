@@ -0,0 +1,22 @@
+package badgerdb
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/drand/drand/chain"
+	"github.com/drand/drand/chain/storetest"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStoreBadgerConformance(t *testing.T) {
+	storetest.Run(t, func(t *testing.T) chain.Store {
+		tmp, err := ioutil.TempDir("", "badgertest*")
+		require.NoError(t, err)
+		t.Cleanup(func() { os.RemoveAll(tmp) })
+		store, err := NewBadgerStore(tmp)
+		require.NoError(t, err)
+		return store
+	})
+}
@@ -0,0 +1,87 @@
+package chain
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/drand/drand/key"
+	"github.com/drand/kyber"
+	"github.com/drand/kyber/util/random"
+)
+
+// hashablePoint is implemented by the points of the signature group, which
+// messages are hashed to.
+type hashablePoint interface {
+	Hash([]byte) kyber.Point
+}
+
+// VerifyBeacons verifies the chained signatures of many beacons at once. The
+// signatures are checked with a random linear combination of the beacons,
+// which takes two pairings whatever the number of beacons instead of two per
+// beacon, and the previous signature of each beacon following another one in
+// the slice must be the signature of that beacon. It returns -1 when all the
+// beacons are valid, and otherwise the index of the first invalid beacon along
+// with the reason it is invalid.
+func VerifyBeacons(pubkey kyber.Point, beacons []*Beacon) (int, error) {
+	sigs := make([]kyber.Point, 0, len(beacons))
+	hashes := make([]kyber.Point, 0, len(beacons))
+	// beacons are checked up to the first malformed or unchained one, so that
+	// an invalid signature before it is reported first.
+	var malformed error
+	for i, b := range beacons {
+		if i > 0 && b.Round == beacons[i-1].Round+1 && !key.ConstantTimeEqual(b.PreviousSig, beacons[i-1].Signature) {
+			malformed = fmt.Errorf("round %d does not follow round %d", b.Round, beacons[i-1].Round)
+			break
+		}
+		sig := key.SigGroup.Point()
+		if err := sig.UnmarshalBinary(b.Signature); err != nil {
+			malformed = fmt.Errorf("invalid signature of round %d: %w", b.Round, err)
+			break
+		}
+		hashable, ok := key.SigGroup.Point().(hashablePoint)
+		if !ok {
+			return i, errors.New("signature group points can not be hashed to")
+		}
+		sigs = append(sigs, sig)
+		hashes = append(hashes, hashable.Hash(Message(b.Round, b.PreviousSig)))
+	}
+	if i := firstInvalid(pubkey, sigs, hashes, 0, len(sigs)); i >= 0 {
+		return i, fmt.Errorf("invalid signature of round %d", beacons[i].Round)
+	}
+	if malformed != nil {
+		return len(sigs), malformed
+	}
+	return -1, nil
+}
+
+// firstInvalid returns the index of the first invalid signature between
+// `from` included and `to` excluded, or -1 when they are all valid. Failing
+// batches are split in halves to find the invalid signature.
+func firstInvalid(pubkey kyber.Point, sigs, hashes []kyber.Point, from, to int) int {
+	if from >= to || verifyBatch(pubkey, sigs[from:to], hashes[from:to]) {
+		return -1
+	}
+	if to-from == 1 {
+		return from
+	}
+	mid := from + (to-from)/2
+	if i := firstInvalid(pubkey, sigs, hashes, from, mid); i >= 0 {
+		return i
+	}
+	return firstInvalid(pubkey, sigs, hashes, mid, to)
+}
+
+// verifyBatch checks that e(g1, sum(r_i * sig_i)) = e(pubkey, sum(r_i * H(m_i)))
+// for random scalars r_i, so that invalid signatures can not cancel each
+// other out.
+func verifyBatch(pubkey kyber.Point, sigs, hashes []kyber.Point) bool {
+	aggSig := key.SigGroup.Point().Null()
+	aggHash := key.SigGroup.Point().Null()
+	stream := random.New()
+	for i := range sigs {
+		r := key.KeyGroup.Scalar().Pick(stream)
+		aggSig.Add(aggSig, key.SigGroup.Point().Mul(r, sigs[i]))
+		aggHash.Add(aggHash, key.SigGroup.Point().Mul(r, hashes[i]))
+	}
+	return key.Pairing.ValidatePairing(pubkey, aggHash, key.KeyGroup.Point().Base(), aggSig)
+}
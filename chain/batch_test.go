@@ -0,0 +1,58 @@
+package chain
+
+import (
+	"crypto/sha256"
+	"testing"
+
+	"github.com/drand/drand/key"
+	"github.com/drand/kyber/util/random"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVerifyBeacons(t *testing.T) {
+	priv := key.KeyGroup.Scalar().Pick(random.New())
+	pub := key.KeyGroup.Point().Mul(priv, nil)
+
+	prev := sha256.Sum256([]byte("genesis"))
+	prevSig := prev[:]
+	beacons := make([]*Beacon, 10)
+	for i := range beacons {
+		round := uint64(i + 1)
+		sig, err := key.AuthScheme.Sign(priv, Message(round, prevSig))
+		require.NoError(t, err)
+		beacons[i] = &Beacon{Round: round, Signature: sig, PreviousSig: prevSig}
+		prevSig = sig
+	}
+
+	i, err := VerifyBeacons(pub, beacons)
+	require.NoError(t, err)
+	require.Equal(t, -1, i)
+
+	i, err = VerifyBeacons(pub, nil)
+	require.NoError(t, err)
+	require.Equal(t, -1, i)
+
+	// a beacon signed by another key
+	other := key.KeyGroup.Scalar().Pick(random.New())
+	forged := *beacons[6]
+	forged.Signature, err = key.AuthScheme.Sign(other, Message(forged.Round, forged.PreviousSig))
+	require.NoError(t, err)
+	tampered := append([]*Beacon{}, beacons...)
+	tampered[6] = &forged
+	i, err = VerifyBeacons(pub, tampered)
+	require.Error(t, err)
+	require.Equal(t, 6, i)
+	i, err = VerifyBeacons(pub, tampered[:7])
+	require.Error(t, err)
+	require.Equal(t, 6, i)
+
+	// a beacon not chained to the previous one
+	i, err = VerifyBeacons(pub, []*Beacon{beacons[0], beacons[2]})
+	require.NoError(t, err)
+	require.Equal(t, -1, i)
+	unchained := *beacons[2]
+	unchained.Round = 2
+	i, err = VerifyBeacons(pub, []*Beacon{beacons[0], &unchained})
+	require.Error(t, err)
+	require.Equal(t, 1, i)
+}
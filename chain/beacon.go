@@ -1,7 +1,6 @@
 package chain
 
 import (
-	"bytes"
 	"crypto/sha256"
 	"encoding/hex"
 	"fmt"
@@ -26,10 +25,10 @@ type Beacon struct {
 
 // Equal indicates if two beacons are equal
 func (b *Beacon) Equal(b2 *Beacon) bool {
-	return bytes.Equal(b.PreviousSig, b2.PreviousSig) &&
+	return key.ConstantTimeEqual(b.PreviousSig, b2.PreviousSig) &&
 		b.Round == b2.Round &&
-		bytes.Equal(b.Signature, b2.Signature) &&
-		bytes.Equal(b.SignatureV2, b2.SignatureV2)
+		key.ConstantTimeEqual(b.Signature, b2.Signature) &&
+		key.ConstantTimeEqual(b.SignatureV2, b2.SignatureV2)
 }
 
 // Marshal provides a JSON encoding of a beacon
@@ -62,7 +61,8 @@ func (b *Beacon) GetRound() uint64 {
 	return b.Round
 }
 
-// RandomnessFromSignature derives the round randomness from its signature
+// RandomnessFromSignature derives the round randomness from its signature,
+// with the default derivation.
 func RandomnessFromSignature(sig []byte) []byte {
 	out := sha256.Sum256(sig)
 	return out[:]
@@ -5,9 +5,9 @@ import (
 	"encoding/binary"
 
 	"github.com/drand/drand/chain"
-	"github.com/drand/drand/key"
 	"github.com/drand/drand/log"
 	"github.com/drand/drand/protobuf/drand"
+	"github.com/drand/kyber/sign/tbls"
 )
 
 // partialCache is a cache that stores (or not) all the partials the node
@@ -29,6 +29,13 @@ func newPartialCache(l log.Logger) *partialCache {
 	}
 }
 
+// partialIndex returns the index of the share that made a partial signature,
+// whatever the group of the signature.
+func partialIndex(sig []byte) int {
+	idx, _ := tbls.SigShare(sig).Index()
+	return idx
+}
+
 func roundID(round uint64, previous []byte) string {
 	var buff bytes.Buffer
 	_ = binary.Write(&buff, binary.BigEndian, round)
@@ -39,7 +46,7 @@ func roundID(round uint64, previous []byte) string {
 // Append adds a partial signature to the cache.
 func (c *partialCache) Append(p *drand.PartialBeaconPacket) {
 	id := roundID(p.GetRound(), p.GetPreviousSig())
-	idx, _ := key.Scheme.IndexOf(p.GetPartialSig())
+	idx := partialIndex(p.GetPartialSig())
 	round := c.getCache(id, p)
 	if round == nil {
 		return
@@ -88,7 +95,7 @@ func (c *partialCache) getCache(id string, p *drand.PartialBeaconPacket) *roundC
 	if round, ok := c.rounds[id]; ok {
 		return round
 	}
-	idx, _ := key.Scheme.IndexOf(p.GetPartialSig())
+	idx := partialIndex(p.GetPartialSig())
 	if len(c.rcvd[idx]) >= MaxPartialsPerNode {
 		// this node has submitted too many partials - we take the last one off
 		toEvict := c.rcvd[idx][0]
@@ -130,7 +137,7 @@ func newRoundCache(id string, p *drand.PartialBeaconPacket) *roundCache {
 // append stores the partial and returns true if the partial is not stored . It
 // returns false if the cache is already caching this partial signature.
 func (r *roundCache) append(p *drand.PartialBeaconPacket) bool {
-	idx, _ := key.Scheme.IndexOf(p.GetPartialSig())
+	idx := partialIndex(p.GetPartialSig())
 	if _, seen := r.sigs[idx]; seen {
 		return false
 	}
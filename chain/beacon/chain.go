@@ -19,6 +19,13 @@ const (
 // chainStore implements CallbackStore, Syncer and deals with reconstructing the
 // beacons, and sync when needed. This struct is the gateway logic for beacons to
 // be inserted in the database and for replying to beacon requests.
+//
+// Every node aggregates the partial signatures it receives and stores the
+// beacon itself, as soon as it holds a threshold of them: no node is
+// responsible for the aggregation of a round, so there is no aggregator to
+// fail over from. Since the signatures are unique, all the nodes store the same
+// beacon. A stalled node only delays the rounds when less than a threshold of
+// the other nodes send their partial signatures in time.
 type chainStore struct {
 	CallbackStore
 	l           log.Logger
@@ -41,7 +48,7 @@ func newChainStore(l log.Logger, cf *Config, cl net.ProtocolClient, c *cryptoSto
 	// we make sure the chain is increasing monotically
 	as := newAppendStore(store)
 	// we write some stats about the timing when new beacon is saved
-	ds := newDiscrepancyStore(as, l, c.GetGroup())
+	ds := newDiscrepancyStore(as, l, c)
 	// we can register callbacks on it
 	cbs := NewCallbackStore(ds)
 	// we give the final append store to the syncer
@@ -132,13 +139,15 @@ func (c *chainStore) runAggregator() {
 				break
 			}
 
+			scheme := c.crypto.Scheme()
+			ts := scheme.Suite().ThresholdScheme
 			msg := roundCache.Msg()
-			finalSig, err := key.Scheme.Recover(c.crypto.GetPub(), msg, roundCache.Partials(), thr, n)
+			finalSig, err := ts.Recover(c.crypto.GetPub(), msg, roundCache.Partials(), thr, n)
 			if err != nil {
 				c.l.Debug("invalid_recovery", err, "round", pRound, "got", fmt.Sprintf("%d/%d", roundCache.Len(), n))
 				break
 			}
-			if err := key.Scheme.VerifyRecovered(c.crypto.GetPub().Commit(), msg, finalSig); err != nil {
+			if err := ts.VerifyRecovered(c.crypto.GetPub().Commit(), msg, finalSig); err != nil {
 				c.l.Error("invalid_sig", err, "round", pRound)
 				break
 			}
@@ -152,18 +161,24 @@ func (c *chainStore) runAggregator() {
 			// this allows a graceful transitions for nodes updating to v2
 			if roundCache.LenV2() >= thr {
 				roundMsg := chain.MessageV2(pRound)
-				finalSigV2, err := key.Scheme.Recover(c.crypto.GetPub(), roundMsg, roundCache.PartialsV2(), thr, n)
+				finalSigV2, err := ts.Recover(c.crypto.GetPub(), roundMsg, roundCache.PartialsV2(), thr, n)
 				if err != nil {
 					c.l.Debug("invalid_recovery_V2", err, "round", pRound, "got", fmt.Sprintf("%d/%d", roundCache.LenV2(), n))
 					// We don't never accept a beacon with invalid signature v2
 					// even if v1 is correct
 					break
 				}
-				if err := key.Scheme.VerifyRecovered(c.crypto.GetPub().Commit(), roundMsg, finalSigV2); err != nil {
+				if err := ts.VerifyRecovered(c.crypto.GetPub().Commit(), roundMsg, finalSigV2); err != nil {
 					c.l.Error("invalid_sig_V2", err, "round", pRound)
+					break
 				}
 				newBeacon.SignatureV2 = finalSigV2
 			}
+			// the schemes signing the round only wait for the partials over it
+			if !scheme.Chained() && newBeacon.SignatureV2 == nil {
+				c.l.Debug("store_partial", partial.addr, "round", pRound, "len_partials_V2", fmt.Sprintf("%d/%d", roundCache.LenV2(), thr))
+				break
+			}
 
 			cache.FlushRounds(partial.p.GetRound())
 			c.l.Info("aggregated_beacon", newBeacon.Round, "with_V2?", newBeacon.IsV2())
@@ -13,6 +13,11 @@ var MaxSyncWaitTime = 2 * time.Second
 // fast nodes and these are valid.
 const MaxPartialsPerNode = 100
 
+// MaxSeenPartials is the number of partial signature packets a node remembers
+// having verified, to drop the packets it receives again without verifying
+// them. It holds a few rounds of partials of a large group.
+const MaxSeenPartials = 10 * MaxPartialsPerNode
+
 // MaxCatchupBuffer is the maximum size of the channel that receives beacon from
 // a sync mechanism.
 const MaxCatchupBuffer = 1000
@@ -20,3 +25,27 @@ const MaxCatchupBuffer = 1000
 // CallbackWorkerQueue is the length of the channel that the callback worker
 // uses to dispatch beacons to its workers.
 const CallbackWorkerQueue = 100
+
+// SyncChunkSize is the number of rounds a node catching up fetches from a peer
+// in a single request.
+var SyncChunkSize uint64 = 1000
+
+// SyncParallelism is the maximum number of peers a node catching up fetches
+// rounds from at the same time.
+var SyncParallelism = 4
+
+// SyncChunkTimeout is how long a node catching up waits for a peer to send a
+// whole chunk of rounds before fetching it from another peer.
+var SyncChunkTimeout = time.Minute
+
+// SyncMaxPeerFailures is the number of chunks a peer can fail to serve before
+// a node catching up stops fetching rounds from it.
+const SyncMaxPeerFailures = 3
+
+// MinRetainedRounds is the number of last rounds a node keeps whatever its
+// retention policy, for the peers catching up.
+var MinRetainedRounds uint64 = 10000
+
+// PruneInterval is how often a node with a retention policy deletes the rounds
+// the policy does not keep anymore.
+var PruneInterval = time.Hour
@@ -10,8 +10,7 @@ import (
 
 // CryptoSafe holds the cryptographic information to generate a partial beacon
 type CryptoSafe interface {
-	// SignPartial returns the partial signature
-	SignPartial(msg []byte) ([]byte, error)
+	Signer
 }
 
 // cryptoStore stores the information necessary to validate partial beacon, full
@@ -19,23 +18,42 @@ type CryptoSafe interface {
 // cryptoStore is thread safe when using the methods.
 type cryptoStore struct {
 	sync.Mutex
-	// current share of the node
+	// current share of the node, nil when the signer holds it
 	share *key.Share
+	// signer produces the partial signatures
+	signer Signer
+	// index of the node in the group
+	index int
 	// public polynomial to verify a partial beacon
 	pub *share.PubPoly
 	// chian info to verify final random beacon
 	chain *chain.Info
+	// scheme of the chain, to sign and verify the beacons
+	scheme chain.Scheme
 	// to know the threshold, transition time etc
 	group *key.Group
 }
 
-func newCryptoStore(currentGroup *key.Group, ks *key.Share) *cryptoStore {
-	return &cryptoStore{
-		chain: chain.NewChainInfo(currentGroup),
-		share: ks,
-		pub:   currentGroup.PublicKey.PubPoly(),
-		group: currentGroup,
+func newCryptoStore(currentGroup *key.Group, ks *key.Share, s Signer, index int) (*cryptoStore, error) {
+	info := chain.NewChainInfo(currentGroup)
+	scheme, err := chain.SchemeFromID(info.Scheme)
+	if err != nil {
+		return nil, err
 	}
+	return &cryptoStore{
+		chain:  info,
+		scheme: scheme,
+		share:  ks,
+		signer: s,
+		index:  index,
+		pub:    currentGroup.PublicKey.PubPoly(),
+		group:  currentGroup,
+	}, nil
+}
+
+// Scheme returns the scheme of the chain, which a resharing does not change.
+func (c *cryptoStore) Scheme() chain.Scheme {
+	return c.scheme
 }
 
 // GetGroup returns the current group
@@ -52,21 +70,32 @@ func (c *cryptoStore) GetPub() *share.PubPoly {
 }
 
 // SignPartial implemements the CryptoSafe interface
-func (c *cryptoStore) SignPartial(msg []byte) ([]byte, error) {
+func (c *cryptoStore) SignPartial(round uint64, previousSig []byte) ([]byte, []byte, error) {
 	c.Lock()
-	defer c.Unlock()
-	return key.Scheme.Sign(c.share.PrivateShare(), msg)
+	s := c.signer
+	c.Unlock()
+	return s.SignPartial(round, previousSig)
 }
 
 // Index returns the index of the share
 func (c *cryptoStore) Index() int {
-	return c.share.Share.I
+	c.Lock()
+	defer c.Unlock()
+	return c.index
 }
 
 func (c *cryptoStore) SetInfo(newGroup *key.Group, ks *key.Share) {
 	c.Lock()
 	defer c.Unlock()
+	// the previous share is not used anymore once the new group is live.
+	if c.share != nil && c.share != ks {
+		c.share.Wipe()
+	}
 	c.share = ks
+	// the new share comes from a resharing run by the node, which is refused
+	// while a remote signer holds the share, so the node signs with it.
+	c.signer = NewShareSigner(ks)
+	c.index = ks.Share.I
 	c.group = newGroup
 	c.pub = newGroup.PublicKey.PubPoly()
 	// chain info is constant
@@ -7,10 +7,12 @@ import (
 	"fmt"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/drand/drand/chain"
 	"github.com/drand/drand/log"
+	"github.com/drand/drand/metrics"
 	proto "github.com/drand/drand/protobuf/drand"
 	clock "github.com/jonboulle/clockwork"
 
@@ -23,12 +25,22 @@ import (
 type Config struct {
 	// Public key of this node
 	Public *key.Node
-	// Share of this node in the network
+	// Share of this node in the network. It may only hold the public part of
+	// the share when Signer is set.
 	Share *key.Share
+	// Signer, when set, produces the partial signatures instead of Share, until
+	// the next resharing.
+	Signer Signer
 	// Group listing all nodes and public key of the network
 	Group *key.Group
 	// Clock to use - useful to testing
 	Clock clock.Clock
+	// Retention is the policy deciding which past rounds are pruned from the
+	// store.
+	Retention Retention
+	// BeaconID is the ID of the beacon in a daemon running several, empty for
+	// the default beacon. It labels the metrics of the beacon.
+	BeaconID string
 }
 
 // Handler holds the logic to initiate, and react to the TBLS protocol. Each time
@@ -43,20 +55,35 @@ type Handler struct {
 	// main logic that treats incoming packet / new beacons created
 	chain  *chainStore
 	ticker *ticker
+	// partials already verified
+	seen *seenPartials
+	// deletes the rounds the retention policy does not keep
+	pruner *pruner
+	// tracks the partial signatures of the members of the group
+	participation *participation
+	// lastStored is the unix time in nanoseconds at which the last beacon
+	// was stored, accessed atomically
+	lastStored int64
 
 	close   chan bool
 	addr    string
 	started bool
 	stopped bool
-	l       log.Logger
+	// pausedAt is the unix time at which the node stopped signing, zero when
+	// it signs.
+	pausedAt int64
+	l        log.Logger
 }
 
 // NewHandler returns a fresh handler ready to serve and create randomness
 // beacon
 func NewHandler(c net.ProtocolClient, s chain.Store, conf *Config, l log.Logger) (*Handler, error) {
-	if conf.Share == nil || conf.Group == nil {
+	if (conf.Share == nil && conf.Signer == nil) || conf.Group == nil {
 		return nil, errors.New("beacon: invalid configuration")
 	}
+	if err := conf.Retention.Check(); err != nil {
+		return nil, fmt.Errorf("beacon: %w", err)
+	}
 	// Checking we are in the group
 	node := conf.Group.Find(conf.Public.Identity)
 	if node == nil {
@@ -64,13 +91,20 @@ func NewHandler(c net.ProtocolClient, s chain.Store, conf *Config, l log.Logger)
 	}
 	addr := conf.Public.Address()
 	logger := l
-	crypto := newCryptoStore(conf.Group, conf.Share)
+	signer := conf.Signer
+	if signer == nil {
+		signer = NewShareSigner(conf.Share)
+	}
+	crypto, err := newCryptoStore(conf.Group, conf.Share, signer, int(node.Index))
+	if err != nil {
+		return nil, fmt.Errorf("beacon: %w", err)
+	}
 	// insert genesis beacon
 	if err := s.Put(chain.GenesisBeacon(crypto.chain)); err != nil {
 		return nil, err
 	}
 
-	ticker := newTicker(conf.Clock, conf.Group.Period, conf.Group.GenesisTime)
+	ticker := newTicker(conf.Clock, conf.Group.Schedule())
 	store := newChainStore(logger, conf, c, crypto, s, ticker)
 	handler := &Handler{
 		conf:   conf,
@@ -78,10 +112,18 @@ func NewHandler(c net.ProtocolClient, s chain.Store, conf *Config, l log.Logger)
 		crypto: crypto,
 		chain:  store,
 		ticker: ticker,
+		seen:   newSeenPartials(MaxSeenPartials),
+		pruner: newPruner(logger, s, conf.Retention),
 		addr:   addr,
 		close:  make(chan bool),
 		l:      logger,
+
+		participation: newParticipation(),
 	}
+	store.AddCallback("handler", func(b *chain.Beacon) {
+		atomic.StoreInt64(&handler.lastStored, conf.Clock.Now().UnixNano())
+		handler.participation.stored(b.Round, crypto.GetGroup())
+	})
 	return handler, nil
 }
 
@@ -93,7 +135,8 @@ func (h *Handler) ProcessPartialBeacon(c context.Context, p *proto.PartialBeacon
 	addr := net.RemoteAddress(c)
 	h.l.Debug("received", "request", "from", addr, "round", p.GetRound())
 
-	nextRound, _ := chain.NextRound(h.conf.Clock.Now().Unix(), h.conf.Group.Period, h.conf.Group.GenesisTime)
+	// the schedule of the ticker follows the period changes of the transitions
+	nextRound, _ := chain.EpochNextRound(h.conf.Clock.Now().Unix(), h.ticker.schedule())
 	currentRound := nextRound - 1
 
 	// we allow one round off in the future because of small clock drifts
@@ -104,27 +147,52 @@ func (h *Handler) ProcessPartialBeacon(c context.Context, p *proto.PartialBeacon
 		return nil, fmt.Errorf("invalid round: %d instead of %d", p.GetRound(), currentRound)
 	}
 
+	scheme := h.crypto.Scheme()
+	ts := scheme.Suite().ThresholdScheme
+	// drop the partials already received before verifying them again
+	idx, _ := ts.IndexOf(p.GetPartialSig())
+	id := newPartialID(idx, p)
+	if valid, seen := h.seen.get(id); seen {
+		metrics.PartialDuplicates.Inc()
+		h.l.Debug("process_partial", addr, "round", p.GetRound(), "index", idx, "duplicate", true, "valid", valid)
+		if !valid {
+			return nil, errors.New("invalid partial signature")
+		}
+		return new(proto.Empty), nil
+	}
+
 	msg := chain.Message(p.GetRound(), p.GetPreviousSig())
 	// XXX Remove that evaluation - find another way to show the current dist.
 	// key being used
 	shortPub := h.crypto.GetPub().Eval(1).V.String()[14:19]
 	// verify if request is valid
-	if err := key.Scheme.VerifyPartial(h.crypto.GetPub(), msg, p.GetPartialSig()); err != nil {
+	if err := ts.VerifyPartial(h.crypto.GetPub(), msg, p.GetPartialSig()); err != nil {
 		h.l.Error("process_partial", addr, "err", err,
 			"prev_sig", shortSigStr(p.GetPreviousSig()),
 			"curr_round", currentRound,
 			"msg_sign", shortSigStr(msg),
 			"short_pub", shortPub)
+		h.seen.add(id, false)
+		h.invalidPartial(idx)
 		return nil, err
 	}
 
-	// backward compatible: check new signature type v2 only if present
+	// backward compatible: check new signature type v2 only if present, while
+	// the schemes signing the round only need it
 	var withV2 bool
+	if len(p.GetPartialSigV2()) == 0 && !scheme.Chained() {
+		h.l.Error("process_partial_v2", addr, "curr_round", currentRound, "err", "missing partial signature")
+		h.seen.add(id, false)
+		h.invalidPartial(idx)
+		return nil, fmt.Errorf("no partial signature of round %d for the scheme %s", p.GetRound(), scheme.ID())
+	}
 	if len(p.GetPartialSigV2()) > 0 {
 		msgRound := chain.MessageV2(p.GetRound())
-		err := key.Scheme.VerifyPartial(h.crypto.GetPub(), msgRound, p.GetPartialSigV2())
+		err := ts.VerifyPartial(h.crypto.GetPub(), msgRound, p.GetPartialSigV2())
 		if err != nil {
 			h.l.Error("process_partial_v2", addr, "curr_round", currentRound, "err", err)
+			h.seen.add(id, false)
+			h.invalidPartial(idx)
 			return nil, err
 		}
 		withV2 = true
@@ -135,7 +203,7 @@ func (h *Handler) ProcessPartialBeacon(c context.Context, p *proto.PartialBeacon
 		shortSigStr(msg), "short_pub", shortPub,
 		"with_v2", withV2,
 		"status", "OK")
-	idx, _ := key.Scheme.IndexOf(p.GetPartialSig())
+	h.seen.add(id, true)
 	if idx == h.crypto.Index() {
 		h.l.Error("process_partial", addr,
 			"index_got", idx,
@@ -145,10 +213,64 @@ func (h *Handler) ProcessPartialBeacon(c context.Context, p *proto.PartialBeacon
 		// XXX error or not ?
 		return new(proto.Empty), nil
 	}
+	h.validPartial(idx, p.GetRound())
 	h.chain.NewValidPartial(addr, p)
 	return new(proto.Empty), nil
 }
 
+// validPartial records the arrival of a valid partial signature of the member
+// of the given index.
+func (h *Handler) validPartial(idx int, round uint64) {
+	node := h.crypto.GetGroup().Node(key.Index(idx))
+	if node == nil {
+		return
+	}
+	start := chain.EpochTimeOfRound(h.ticker.schedule(), round)
+	latency := h.conf.Clock.Now().Sub(time.Unix(start, 0))
+	h.participation.received(node, round, latency)
+}
+
+// Departed records that the member of the group at the given address stopped
+// after signing the given round, so that the node stops sending it partial
+// signatures until it receives one from it again. The announce must be
+// authenticated by the caller.
+func (h *Handler) Departed(addr string, round uint64) error {
+	for _, n := range h.crypto.GetGroup().Nodes {
+		if n.Address() != addr {
+			continue
+		}
+		if h.participation.departed(n, round) {
+			h.l.Info("departure", addr, "after_round", round)
+		}
+		return nil
+	}
+	return fmt.Errorf("beacon: %s is not a member of the group", addr)
+}
+
+// invalidPartial records a partial signature claiming to be from the member of
+// the given index which did not verify.
+func (h *Handler) invalidPartial(idx int) {
+	if node := h.crypto.GetGroup().Node(key.Index(idx)); node != nil {
+		h.participation.invalid(node)
+	}
+}
+
+// LastStoredTime returns the time at which the node stored its last beacon,
+// zero if it stored none since it started.
+func (h *Handler) LastStoredTime() time.Time {
+	at := atomic.LoadInt64(&h.lastStored)
+	if at == 0 {
+		return time.Time{}
+	}
+	return time.Unix(0, at)
+}
+
+// Participation returns the participation of the members of the group to the
+// beacons, as observed by the node.
+func (h *Handler) Participation() *ParticipationReport {
+	return h.participation.report(h.crypto.GetGroup())
+}
+
 // Store returns the store associated with this beacon handler
 func (h *Handler) Store() chain.Store {
 	return h.chain
@@ -166,7 +288,7 @@ func (h *Handler) Start() error {
 		h.l.Error("genesis_time", "past", "call", "catchup")
 		return errors.New("beacon: genesis time already passed. Call Catchup()")
 	}
-	_, tTime := chain.NextRound(h.conf.Clock.Now().Unix(), h.conf.Group.Period, h.conf.Group.GenesisTime)
+	_, tTime := chain.EpochNextRound(h.conf.Clock.Now().Unix(), h.conf.Group.Schedule())
 	h.l.Info("beacon", "start")
 	go h.run(tTime)
 	return nil
@@ -178,7 +300,7 @@ func (h *Handler) Start() error {
 // it sync its local chain with other nodes to be able to participate in the
 // next upcoming round.
 func (h *Handler) Catchup() {
-	nRound, tTime := chain.NextRound(h.conf.Clock.Now().Unix(), h.conf.Group.Period, h.conf.Group.GenesisTime)
+	nRound, tTime := chain.EpochNextRound(h.conf.Clock.Now().Unix(), h.conf.Group.Schedule())
 	go h.run(tTime)
 	h.chain.RunSync(context.Background(), nRound, nil)
 }
@@ -189,8 +311,8 @@ func (h *Handler) Catchup() {
 // given.
 func (h *Handler) Transition(prevGroup *key.Group) error {
 	targetTime := h.conf.Group.TransitionTime
-	tRound := chain.CurrentRound(targetTime, h.conf.Group.Period, h.conf.Group.GenesisTime)
-	tTime := chain.TimeOfRound(h.conf.Group.Period, h.conf.Group.GenesisTime, tRound)
+	tRound := chain.EpochCurrentRound(targetTime, h.conf.Group.Schedule())
+	tTime := chain.EpochTimeOfRound(h.conf.Group.Schedule(), tRound)
 	if tTime != targetTime {
 		h.l.Fatal("transition_time", "invalid_offset", "expected_time", tTime, "got_time", targetTime)
 		return nil
@@ -205,13 +327,18 @@ func (h *Handler) Transition(prevGroup *key.Group) error {
 // TransitionNewGroup prepares the node to transition to the new group
 func (h *Handler) TransitionNewGroup(newShare *key.Share, newGroup *key.Group) {
 	targetTime := newGroup.TransitionTime
-	tRound := chain.CurrentRound(targetTime, h.conf.Group.Period, h.conf.Group.GenesisTime)
-	tTime := chain.TimeOfRound(h.conf.Group.Period, h.conf.Group.GenesisTime, tRound)
+	tRound := chain.EpochCurrentRound(targetTime, h.conf.Group.Schedule())
+	tTime := chain.EpochTimeOfRound(h.conf.Group.Schedule(), tRound)
 	if tTime != targetTime {
 		h.l.Fatal("transition_time", "invalid_offset", "expected_time", tTime, "got_time", targetTime)
 		return
 	}
 	h.l.Debug("transition", "new_group", "at_round", tRound)
+	// the schedule of the new group only differs from the current one from
+	// the transition round on, when the resharing changes the period, so the
+	// ticker switches to it right away to tick at the new period from the
+	// transition round.
+	h.ticker.SetSchedule(newGroup.Schedule())
 	// register a callback such that when the round happening just before the
 	// transition is stored, then it switches the current share to the new one
 	targetRound := tRound - 1
@@ -220,6 +347,7 @@ func (h *Handler) TransitionNewGroup(newShare *key.Share, newGroup *key.Group) {
 			return
 		}
 		h.crypto.SetInfo(newGroup, newShare)
+		h.seen.reset()
 		h.chain.RemoveCallback("transition")
 	})
 }
@@ -228,6 +356,9 @@ func (h *Handler) TransitionNewGroup(newShare *key.Share, newGroup *key.Group) {
 func (h *Handler) run(startTime int64) {
 	chanTick := h.ticker.ChannelAt(startTime)
 	h.l.Debug("run_round", "wait", "until", startTime)
+	if h.conf.Retention.Enabled() {
+		go h.pruneLoop()
+	}
 	var current roundInfo
 	for {
 		select {
@@ -277,6 +408,10 @@ func (h *Handler) run(startTime int64) {
 }
 
 func (h *Handler) broadcastNextPartial(current roundInfo, upon *chain.Beacon) {
+	if h.Paused() {
+		h.l.Debug("beacon_round", current.round, "paused", "not signing")
+		return
+	}
 	ctx := context.Background()
 	previousSig := upon.Signature
 	round := upon.Round + 1
@@ -290,15 +425,9 @@ func (h *Handler) broadcastNextPartial(current roundInfo, upon *chain.Beacon) {
 		round = current.round
 	}
 	msg := chain.Message(round, previousSig)
-	currSig, err := h.crypto.SignPartial(msg)
+	currSig, sigV2, err := h.crypto.SignPartial(round, previousSig)
 	if err != nil {
-		h.l.Fatal("beacon_round", "err creating signature", "err", err, "round", round)
-		return
-	}
-	msgV2 := chain.MessageV2(round)
-	sigV2, err := h.crypto.SignPartial(msgV2)
-	if err != nil {
-		h.l.Fatal("beacon_round", "err creating sig V2", "err", err, "round", round)
+		h.l.Error("beacon_round", "err creating signature", "err", err, "round", round)
 		return
 	}
 	h.l.Debug("broadcast_partial", round, "from_prev_sig", shortSigStr(previousSig), "msg_sign", shortSigStr(msg), "sigV2", shortSigStr(sigV2))
@@ -308,11 +437,16 @@ func (h *Handler) broadcastNextPartial(current roundInfo, upon *chain.Beacon) {
 		PartialSig:   currSig,
 		PartialSigV2: sigV2,
 	}
+	h.validPartial(h.crypto.Index(), round)
 	h.chain.NewValidPartial(h.addr, packet)
 	for _, id := range h.crypto.GetGroup().Nodes {
 		if h.addr == id.Address() {
 			continue
 		}
+		if h.participation.isDeparted(id) {
+			h.l.Debug("beacon_round", round, "departed", id.Address())
+			continue
+		}
 		go func(i *key.Identity) {
 			h.l.Debug("beacon_round", round, "send_to", i.Address())
 			err := h.client.PartialBeacon(ctx, i, packet)
@@ -338,10 +472,59 @@ func (h *Handler) Stop() {
 	close(h.close)
 	h.chain.Stop()
 	h.ticker.Stop()
+	if h.pausedAt != 0 {
+		metrics.BeaconPaused.WithLabelValues(h.conf.BeaconID).Set(0)
+	}
 	h.stopped = true
 	h.l.Info("beacon", "stop")
 }
 
+// PauseStatus tells whether the node contributes partial signatures to the
+// beacons.
+type PauseStatus struct {
+	Paused bool `json:"paused"`
+	// Since is the unix time at which the node was paused.
+	Since int64 `json:"since,omitempty"`
+}
+
+// Pause stops the node from signing and sending its partial signatures, until
+// it is resumed. The node still aggregates the partial signatures of the
+// other nodes, and stores and syncs the beacons.
+func (h *Handler) Pause() *PauseStatus {
+	h.Lock()
+	defer h.Unlock()
+	if h.pausedAt == 0 {
+		h.pausedAt = h.conf.Clock.Now().Unix()
+		metrics.BeaconPaused.WithLabelValues(h.conf.BeaconID).Set(1)
+		h.l.Info("beacon", "paused")
+	}
+	return &PauseStatus{Paused: true, Since: h.pausedAt}
+}
+
+// Resume lets a paused node sign again, from the next round.
+func (h *Handler) Resume() *PauseStatus {
+	h.Lock()
+	defer h.Unlock()
+	if h.pausedAt != 0 {
+		h.pausedAt = 0
+		metrics.BeaconPaused.WithLabelValues(h.conf.BeaconID).Set(0)
+		h.l.Info("beacon", "resumed")
+	}
+	return &PauseStatus{}
+}
+
+// Paused returns true if the node does not sign the beacons.
+func (h *Handler) Paused() bool {
+	return h.PauseStatus().Paused
+}
+
+// PauseStatus returns whether the node signs the beacons.
+func (h *Handler) PauseStatus() *PauseStatus {
+	h.Lock()
+	defer h.Unlock()
+	return &PauseStatus{Paused: h.pausedAt != 0, Since: h.pausedAt}
+}
+
 // StopAt will stop the handler at the given time. It is useful when
 // transitionining for a resharing.
 func (h *Handler) StopAt(stopTime int64) error {
@@ -357,6 +540,32 @@ func (h *Handler) StopAt(stopTime int64) error {
 	return nil
 }
 
+// WaitCurrentRound waits until the beacon of the round in progress is stored,
+// or the context is done.
+func (h *Handler) WaitCurrentRound(ctx context.Context) error {
+	round := chain.EpochCurrentRound(h.conf.Clock.Now().Unix(), h.ticker.schedule())
+	stored := make(chan bool, 1)
+	id := fmt.Sprintf("wait-round-%d", round)
+	h.AddCallback(id, func(b *chain.Beacon) {
+		if b.Round >= round {
+			select {
+			case stored <- true:
+			default:
+			}
+		}
+	})
+	defer h.RemoveCallback(id)
+	if last, err := h.chain.Last(); err == nil && last.Round >= round {
+		return nil
+	}
+	select {
+	case <-stored:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
 // AddCallback is a proxy method to register a callback on the backend store
 func (h *Handler) AddCallback(id string, fn func(*chain.Beacon)) {
 	h.chain.AddCallback(id, fn)
@@ -369,9 +578,50 @@ func (h *Handler) RemoveCallback(id string) {
 
 // SyncChain is a proxy method to sync a chain
 func (h *Handler) SyncChain(req *proto.SyncRequest, stream proto.Protocol_SyncChainServer) error {
+	h.pruner.syncRequested(req.GetFromRound())
 	return h.chain.sync.SyncChain(req, stream)
 }
 
+// Prune deletes right away the rounds the retention policy of the node does
+// not keep, and returns the resulting pruning status.
+func (h *Handler) Prune() (*PruneStatus, error) {
+	if !h.conf.Retention.Enabled() {
+		return nil, errors.New("beacon: no retention policy set")
+	}
+	last, err := h.chain.Last()
+	if err != nil {
+		return nil, err
+	}
+	return h.pruner.prune(last.Round, h.conf.Clock.Now().Unix(), h.ticker.schedule())
+}
+
+// PruneStatus returns the state of the pruning of the store.
+func (h *Handler) PruneStatus() *PruneStatus {
+	return h.pruner.Status()
+}
+
+// pruneLoop prunes the store when the beacon starts, and then periodically.
+func (h *Handler) pruneLoop() {
+	ticker := h.conf.Clock.NewTicker(PruneInterval)
+	defer ticker.Stop()
+	for {
+		if _, err := h.Prune(); err != nil {
+			h.l.Error("beacon", "prune", "err", err)
+		}
+		select {
+		case <-ticker.Chan():
+		case <-h.close:
+			return
+		}
+	}
+}
+
+// SyncStatus returns the progress of the current or last sync of the chain
+// with the other nodes.
+func (h *Handler) SyncStatus() *SyncStatus {
+	return h.chain.sync.Status()
+}
+
 func shortSigStr(sig []byte) string {
 	max := 3
 	if len(sig) < max {
@@ -21,6 +21,7 @@ import (
 	testnet "github.com/drand/drand/test/net"
 	"github.com/drand/kyber"
 	"github.com/drand/kyber/share"
+	dkg "github.com/drand/kyber/share/dkg"
 	"github.com/drand/kyber/util/random"
 	clock "github.com/jonboulle/clockwork"
 	"github.com/stretchr/testify/require"
@@ -87,10 +88,10 @@ func dkgShares(n, t int) ([]*key.Share, []kyber.Point) {
 		if err != nil {
 			panic(err)
 		}
-		dkgShares[i] = &key.Share{
+		dkgShares[i] = &key.Share{DistKeyShare: dkg.DistKeyShare{
 			Share:   shares[i],
 			Commits: commits,
-		}
+		}}
 	}
 	sig, err := key.Scheme.Recover(pubPoly, msg, sigs, t, n)
 	if err != nil {
@@ -484,6 +485,63 @@ func TestBeaconSimple(t *testing.T) {
 	checkWait(counter)
 }
 
+func TestBeaconPause(t *testing.T) {
+	n := 3
+	thr := n/2 + 1
+	period := 2 * time.Second
+
+	offsetGenesis := 2 * time.Second
+	var genesisTime int64 = clock.NewFakeClock().Now().Add(offsetGenesis).Unix()
+
+	bt := NewBeaconTest(n, thr, period, genesisTime)
+	defer bt.CleanUp()
+
+	rounds := make(chan uint64, 100)
+	for i := 0; i < n; i++ {
+		bt.CallbackFor(i, func(b *chain.Beacon) {
+			rounds <- b.Round
+		})
+		bt.ServeBeacon(i)
+	}
+	bt.StartBeacons(n)
+	expectRound := func(round uint64, nodes int) {
+		for i := 0; i < nodes; i++ {
+			select {
+			case r := <-rounds:
+				require.Equal(t, round, r)
+			case <-time.After(20 * time.Second):
+				t.Fatalf("round %d not created", round)
+			}
+		}
+	}
+	bt.MoveTime(offsetGenesis)
+	expectRound(1, n)
+
+	// the paused node still stores the beacons of the others
+	paused := bt.nodes[bt.searchNode(0)].handler
+	status := paused.Pause()
+	require.True(t, status.Paused)
+	require.True(t, paused.Paused())
+	bt.MoveTime(period)
+	expectRound(2, n)
+	require.NoError(t, paused.WaitCurrentRound(context.Background()))
+
+	// below the threshold, no beacon is created
+	bt.nodes[bt.searchNode(1)].handler.Pause()
+	bt.MoveTime(period)
+	select {
+	case r := <-rounds:
+		t.Fatalf("round %d created by paused nodes", r)
+	case <-time.After(500 * time.Millisecond):
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	require.Equal(t, context.DeadlineExceeded, paused.WaitCurrentRound(ctx))
+
+	require.False(t, paused.Resume().Paused)
+	require.False(t, paused.PauseStatus().Paused)
+}
+
 func TestBeaconThreshold(t *testing.T) {
 	n := 3
 	thr := n/2 + 1
@@ -0,0 +1,191 @@
+package beacon
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/drand/drand/key"
+	"github.com/drand/drand/metrics"
+)
+
+// latencyWeight is the weight of the last partial signature of a member in
+// its average arrival latency.
+const latencyWeight = 0.2
+
+// PeerParticipation is what a node observed of the partial signatures a
+// member of its group sends.
+type PeerParticipation struct {
+	Index   int    `json:"index"`
+	Address string `json:"address"`
+	// Partials is the number of valid partial signatures received.
+	Partials uint64 `json:"partials"`
+	// Invalid is the number of partial signatures which did not verify.
+	Invalid uint64 `json:"invalid"`
+	// Missed is the number of rounds stored without a partial signature of
+	// the member.
+	Missed uint64 `json:"missed"`
+	// LastRound is the round of the last valid partial signature.
+	LastRound uint64 `json:"last_round"`
+	// LastLatency and AvgLatency are the arrival times of the partial
+	// signatures after the start of their round, in milliseconds. The average
+	// is a moving average favoring the last partials.
+	LastLatency int64 `json:"last_latency_ms"`
+	AvgLatency  int64 `json:"avg_latency_ms"`
+	// DepartedAfter is the last round the member signed before announcing it
+	// stopped, zero unless it is stopped.
+	DepartedAfter uint64 `json:"departed_after,omitempty"`
+}
+
+// ParticipationReport is the participation of each member of the group to
+// the beacons, as observed by the node since it started.
+type ParticipationReport struct {
+	// Round is the last round accounted for the missed rounds.
+	Round uint64               `json:"round"`
+	Peers []*PeerParticipation `json:"peers"`
+}
+
+// participation tracks, for each member of the group, when its partial
+// signatures arrive, the invalid ones and the rounds it misses. A round is
+// only accounted once the beacon of the next round is stored, so that the
+// partial signatures arriving after the threshold was reached still count,
+// and only when the node received partial signatures for it, so that the
+// rounds it synced are not held against its peers.
+type participation struct {
+	sync.Mutex
+	peers map[int]*PeerParticipation
+	// rounds holds the indexes of the members whose partial signature of the
+	// round was received, until the round is accounted.
+	rounds    map[uint64]map[int]bool
+	accounted uint64
+}
+
+func newParticipation() *participation {
+	return &participation{
+		peers:  make(map[int]*PeerParticipation),
+		rounds: make(map[uint64]map[int]bool),
+	}
+}
+
+func (p *participation) peer(n *key.Node) *PeerParticipation {
+	peer, ok := p.peers[int(n.Index)]
+	if !ok || peer.Address != n.Address() {
+		// the index may belong to another node after a resharing
+		peer = &PeerParticipation{Index: int(n.Index), Address: n.Address()}
+		p.peers[int(n.Index)] = peer
+	}
+	return peer
+}
+
+// received records a valid partial signature of the given member for the
+// round, arrived latency after the start of the round.
+func (p *participation) received(n *key.Node, round uint64, latency time.Duration) {
+	if latency < 0 {
+		latency = 0
+	}
+	ms := latency.Milliseconds()
+	metrics.PartialLatency.WithLabelValues(n.Address()).Observe(latency.Seconds())
+	p.Lock()
+	defer p.Unlock()
+	if round <= p.accounted {
+		// a late partial of a round already accounted as missed
+		return
+	}
+	peer := p.peer(n)
+	if peer.DepartedAfter != 0 && round > peer.DepartedAfter {
+		// the member is back: the rounds it was away for, not accounted yet,
+		// are not held against it
+		for r, received := range p.rounds {
+			if r > peer.DepartedAfter && r < round {
+				received[int(n.Index)] = true
+			}
+		}
+		peer.DepartedAfter = 0
+	}
+	if peer.Partials == 0 {
+		peer.AvgLatency = ms
+	} else {
+		peer.AvgLatency = int64(latencyWeight*float64(ms) + (1-latencyWeight)*float64(peer.AvgLatency))
+	}
+	peer.Partials++
+	peer.LastLatency = ms
+	if round > peer.LastRound {
+		peer.LastRound = round
+	}
+	if p.rounds[round] == nil {
+		p.rounds[round] = make(map[int]bool)
+	}
+	p.rounds[round][int(n.Index)] = true
+}
+
+// departed records that the given member stopped after signing the round.
+// The announces older than the last partial signature of the member are
+// ignored, the member having started again since.
+func (p *participation) departed(n *key.Node, round uint64) bool {
+	p.Lock()
+	defer p.Unlock()
+	peer := p.peer(n)
+	if round < peer.LastRound {
+		return false
+	}
+	peer.DepartedAfter = round
+	return true
+}
+
+// isDeparted returns true while the given member is stopped.
+func (p *participation) isDeparted(n *key.Node) bool {
+	p.Lock()
+	defer p.Unlock()
+	return p.peer(n).DepartedAfter != 0
+}
+
+// invalid records a partial signature of the given member which did not
+// verify.
+func (p *participation) invalid(n *key.Node) {
+	metrics.PartialInvalid.WithLabelValues(n.Address()).Inc()
+	p.Lock()
+	defer p.Unlock()
+	p.peer(n).Invalid++
+}
+
+// stored accounts the rounds before the round of the stored beacon, counting
+// a missed round for each member of the group which sent no partial signature
+// for it.
+func (p *participation) stored(round uint64, group *key.Group) {
+	p.Lock()
+	defer p.Unlock()
+	for r, received := range p.rounds {
+		if r >= round {
+			continue
+		}
+		for _, n := range group.Nodes {
+			if peer := p.peer(n); peer.DepartedAfter != 0 && r > peer.DepartedAfter {
+				// the member announced it stopped
+				continue
+			}
+			if !received[int(n.Index)] {
+				p.peer(n).Missed++
+				metrics.PartialMissedRounds.WithLabelValues(n.Address()).Inc()
+			}
+		}
+		delete(p.rounds, r)
+		if r > p.accounted {
+			p.accounted = r
+		}
+	}
+}
+
+// report returns the participation of the members of the group.
+func (p *participation) report(group *key.Group) *ParticipationReport {
+	p.Lock()
+	defer p.Unlock()
+	report := &ParticipationReport{Round: p.accounted, Peers: make([]*PeerParticipation, 0, len(group.Nodes))}
+	for _, n := range group.Nodes {
+		peer := *p.peer(n)
+		report.Peers = append(report.Peers, &peer)
+	}
+	sort.Slice(report.Peers, func(i, j int) bool {
+		return report.Peers[i].Index < report.Peers[j].Index
+	})
+	return report
+}
@@ -0,0 +1,83 @@
+package beacon
+
+import (
+	"testing"
+	"time"
+
+	"github.com/drand/drand/key"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParticipation(t *testing.T) {
+	group := &key.Group{Nodes: []*key.Node{
+		{Identity: &key.Identity{Addr: "127.0.0.1:1000"}, Index: 0},
+		{Identity: &key.Identity{Addr: "127.0.0.1:1001"}, Index: 1},
+		{Identity: &key.Identity{Addr: "127.0.0.1:1002"}, Index: 2},
+	}}
+	p := newParticipation()
+	p.received(group.Nodes[0], 10, 100*time.Millisecond)
+	p.received(group.Nodes[1], 10, 300*time.Millisecond)
+	p.invalid(group.Nodes[2])
+
+	// the round is only accounted once the next beacon is stored
+	p.stored(10, group)
+	report := p.report(group)
+	require.Equal(t, uint64(0), report.Round)
+	require.Equal(t, uint64(0), report.Peers[2].Missed)
+
+	// a partial arriving early, because of clock drifts, has no latency
+	p.received(group.Nodes[1], 11, 2*time.Second)
+	p.received(group.Nodes[1], 11, -time.Second)
+	p.stored(11, group)
+	report = p.report(group)
+	require.Equal(t, uint64(10), report.Round)
+	require.Len(t, report.Peers, 3)
+	require.Equal(t, uint64(1), report.Peers[0].Partials)
+	require.Equal(t, int64(100), report.Peers[0].LastLatency)
+	require.Equal(t, uint64(0), report.Peers[0].Missed)
+	require.Equal(t, uint64(3), report.Peers[1].Partials)
+	require.Equal(t, int64(0), report.Peers[1].LastLatency)
+	require.Equal(t, uint64(11), report.Peers[1].LastRound)
+	require.Equal(t, uint64(1), report.Peers[2].Invalid)
+	require.Equal(t, uint64(1), report.Peers[2].Missed)
+
+	// the rounds without any partial, such as the synced ones, are not held
+	// against the members
+	p.stored(20, group)
+	report = p.report(group)
+	require.Equal(t, uint64(11), report.Round)
+	require.Equal(t, uint64(1), report.Peers[0].Missed)
+	require.Equal(t, uint64(2), report.Peers[2].Missed)
+}
+
+func TestParticipationDeparture(t *testing.T) {
+	group := &key.Group{Nodes: []*key.Node{
+		{Identity: &key.Identity{Addr: "127.0.0.1:1000"}, Index: 0},
+		{Identity: &key.Identity{Addr: "127.0.0.1:1001"}, Index: 1},
+	}}
+	p := newParticipation()
+	p.received(group.Nodes[0], 10, 0)
+	p.received(group.Nodes[1], 10, 0)
+
+	// an announce older than the last partial of the member is ignored
+	require.False(t, p.departed(group.Nodes[1], 9))
+	require.False(t, p.isDeparted(group.Nodes[1]))
+
+	// the rounds after the departure are not held against the member
+	require.True(t, p.departed(group.Nodes[1], 10))
+	require.True(t, p.isDeparted(group.Nodes[1]))
+	p.received(group.Nodes[0], 11, 0)
+	p.received(group.Nodes[0], 12, 0)
+	p.stored(12, group)
+	report := p.report(group)
+	require.Equal(t, uint64(0), report.Peers[1].Missed)
+	require.Equal(t, uint64(10), report.Peers[1].DepartedAfter)
+
+	// the member is back once it signs a later round
+	p.received(group.Nodes[1], 13, 0)
+	require.False(t, p.isDeparted(group.Nodes[1]))
+	p.received(group.Nodes[0], 14, 0)
+	p.stored(15, group)
+	report = p.report(group)
+	require.Equal(t, uint64(1), report.Peers[1].Missed)
+}
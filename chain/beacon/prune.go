@@ -0,0 +1,174 @@
+package beacon
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/drand/drand/chain"
+	"github.com/drand/drand/key"
+	"github.com/drand/drand/log"
+)
+
+// Retention is the policy deciding which past rounds a node keeps in its
+// store. A round kept by either limit is kept, and the zero value keeps all the
+// rounds. Whatever the policy, a node keeps the last MinRetainedRounds rounds
+// for the peers catching up, and the checkpoints of the chain.
+type Retention struct {
+	// Rounds is the number of last rounds kept, 0 for no limit.
+	Rounds uint64
+	// Duration is how long rounds are kept, 0 for no limit.
+	Duration time.Duration
+}
+
+// Enabled returns true if the policy prunes rounds.
+func (r Retention) Enabled() bool {
+	return r.Rounds > 0 || r.Duration > 0
+}
+
+// Check returns an error if the policy keeps fewer rounds than the peers
+// catching up may need.
+func (r Retention) Check() error {
+	if r.Rounds > 0 && r.Rounds < MinRetainedRounds {
+		return fmt.Errorf("retention of %d rounds below the minimum of %d rounds", r.Rounds, MinRetainedRounds)
+	}
+	if r.Duration < 0 {
+		return errors.New("negative retention duration")
+	}
+	return nil
+}
+
+// horizon returns the first round the policy keeps, given the last stored
+// round.
+func (r Retention) horizon(last uint64, now int64, schedule []*key.Epoch) uint64 {
+	if !r.Enabled() || last < MinRetainedRounds {
+		return 1
+	}
+	// a round kept by either limit is kept, so the horizon is the lowest one
+	var horizon uint64 = math.MaxUint64
+	if r.Rounds > 0 {
+		horizon = 1
+		if last >= r.Rounds {
+			horizon = last + 1 - r.Rounds
+		}
+	}
+	if r.Duration > 0 {
+		byTime := chain.EpochCurrentRound(now-int64(r.Duration.Seconds()), schedule)
+		if byTime < horizon {
+			horizon = byTime
+		}
+	}
+	if limit := last + 1 - MinRetainedRounds; limit < horizon {
+		horizon = limit
+	}
+	if horizon < 1 {
+		horizon = 1
+	}
+	return horizon
+}
+
+// IsCheckpoint returns true for the rounds a node keeps whatever its
+// retention policy: the genesis, and the last round of each commitment span,
+// from which light clients can be given a checkpoint in any part of the chain.
+func IsCheckpoint(round uint64) bool {
+	return round == 0 || round%chain.CommitmentSpan == 0
+}
+
+// PruneStatus is the state of the pruning of the store of a node.
+type PruneStatus struct {
+	// KeepRounds and KeepFor are the retention policy of the node.
+	KeepRounds uint64 `json:"keep_rounds,omitempty"`
+	KeepFor    string `json:"keep_for,omitempty"`
+	// Horizon is the first round kept in the store, apart from the
+	// checkpoints.
+	Horizon uint64 `json:"horizon"`
+	// Pruned is the number of rounds deleted by the last pruning.
+	Pruned int `json:"pruned"`
+	// LastRun is the unix time at which the last pruning ended.
+	LastRun int64 `json:"last_run,omitempty"`
+	// Running is true while rounds are being deleted.
+	Running bool   `json:"running"`
+	Error   string `json:"error,omitempty"`
+}
+
+// pruner deletes the rounds of the store that the retention policy does not
+// keep.
+type pruner struct {
+	sync.Mutex
+	l         log.Logger
+	store     chain.Store
+	retention Retention
+	status    PruneStatus
+	// lowest round a peer asked to sync from since the last pruning
+	requested uint64
+}
+
+func newPruner(l log.Logger, store chain.Store, r Retention) *pruner {
+	p := &pruner{l: l, store: store, retention: r}
+	p.status.KeepRounds = r.Rounds
+	if r.Duration > 0 {
+		p.status.KeepFor = r.Duration.String()
+	}
+	p.status.Horizon = 1
+	return p
+}
+
+// syncRequested records that a peer asked to sync from the given round. The
+// rounds a peer asked for since the last pruning are not pruned, so that the
+// peer can ask them again to another node or after a failure.
+func (p *pruner) syncRequested(from uint64) {
+	p.Lock()
+	defer p.Unlock()
+	if p.requested == 0 || from < p.requested {
+		p.requested = from
+	}
+}
+
+func (p *pruner) Status() *PruneStatus {
+	p.Lock()
+	defer p.Unlock()
+	s := p.status
+	return &s
+}
+
+// prune deletes the rounds before the horizon of the retention policy, apart
+// from the checkpoints.
+func (p *pruner) prune(last uint64, now int64, schedule []*key.Epoch) (*PruneStatus, error) {
+	p.Lock()
+	if p.status.Running {
+		p.Unlock()
+		return nil, errors.New("pruning already running")
+	}
+	horizon := p.retention.horizon(last, now, schedule)
+	if p.requested != 0 && p.requested < horizon {
+		horizon = p.requested
+	}
+	p.requested = 0
+	p.status.Running = true
+	p.Unlock()
+
+	var pruned int
+	var err error
+	if horizon > 1 {
+		p.l.Debug("pruner", "start", "horizon", horizon)
+		pruned, err = chain.DelRange(p.store, 1, horizon-1, IsCheckpoint)
+	}
+
+	p.Lock()
+	defer p.Unlock()
+	p.status.Running = false
+	p.status.Pruned = pruned
+	p.status.LastRun = now
+	p.status.Error = ""
+	if err != nil {
+		p.l.Error("pruner", "failed", "err", err)
+		p.status.Error = err.Error()
+	} else if horizon > p.status.Horizon {
+		p.status.Horizon = horizon
+	}
+	p.l.Debug("pruner", "done", "horizon", horizon, "pruned", pruned)
+	s := p.status
+	return &s, err
+}
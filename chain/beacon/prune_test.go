@@ -0,0 +1,73 @@
+package beacon
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/drand/drand/chain"
+	"github.com/drand/drand/chain/boltdb"
+	"github.com/drand/drand/key"
+	"github.com/drand/drand/log"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRetentionHorizon(t *testing.T) {
+	var genesis int64 = 1000
+	schedule := []*key.Epoch{{Round: 1, Time: genesis, Period: 10 * time.Second}}
+	var last uint64 = 50000
+	now := chain.EpochTimeOfRound(schedule, last)
+
+	require.Equal(t, uint64(1), Retention{}.horizon(last, now, schedule))
+	require.Equal(t, uint64(1), Retention{Rounds: MinRetainedRounds}.horizon(MinRetainedRounds-1, now, schedule))
+	require.Equal(t, uint64(30001), Retention{Rounds: 20000}.horizon(last, now, schedule))
+	require.Equal(t, uint64(1), Retention{Rounds: 60000}.horizon(last, now, schedule))
+	// 10000 rounds of 10s
+	byTime := Retention{Duration: 100000 * time.Second}
+	require.Equal(t, uint64(40000), byTime.horizon(last, now, schedule))
+	// a round kept by either limit is kept
+	require.Equal(t, uint64(30001), Retention{Rounds: 20000, Duration: byTime.Duration}.horizon(last, now, schedule))
+	// the last rounds are always kept
+	require.Equal(t, last+1-MinRetainedRounds, Retention{Duration: time.Second}.horizon(last, now, schedule))
+
+	require.Error(t, Retention{Rounds: MinRetainedRounds - 1}.Check())
+	require.NoError(t, Retention{Rounds: MinRetainedRounds}.Check())
+}
+
+func TestPruner(t *testing.T) {
+	defer func(min uint64) { MinRetainedRounds = min }(MinRetainedRounds)
+	MinRetainedRounds = 10
+
+	dir, err := ioutil.TempDir("", "prune")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	store, err := boltdb.NewBoltStore(dir, nil)
+	require.NoError(t, err)
+	defer store.Close()
+	var last uint64 = 1100
+	for round := uint64(0); round <= last; round++ {
+		require.NoError(t, store.Put(&chain.Beacon{Round: round, Signature: chain.RoundToBytes(round)}))
+	}
+
+	p := newPruner(log.DefaultLogger(), store, Retention{Rounds: 50})
+	// a peer syncing from round 1040 keeps the rounds from 1040 on
+	p.syncRequested(1040)
+	status, err := p.prune(last, 0, nil)
+	require.NoError(t, err)
+	require.Equal(t, uint64(1040), status.Horizon)
+	require.Equal(t, 1038, status.Pruned)
+	for _, round := range []uint64{0, chain.CommitmentSpan, 1040} {
+		_, err := store.Get(round)
+		require.NoError(t, err)
+	}
+	_, err = store.Get(1039)
+	require.Error(t, err)
+
+	status, err = p.prune(last, 0, nil)
+	require.NoError(t, err)
+	require.Equal(t, uint64(1051), status.Horizon)
+	require.Equal(t, 11, status.Pruned)
+	require.Equal(t, status, p.Status())
+	require.Equal(t, 52, store.Len())
+}
@@ -0,0 +1,86 @@
+package beacon
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"sync"
+
+	proto "github.com/drand/drand/protobuf/drand"
+)
+
+// partialID identifies a partial signature packet: the round, the index of
+// the signer and a digest of the signed previous signature and the partial
+// signatures of the packet.
+type partialID struct {
+	round  uint64
+	index  int
+	digest [sha256.Size]byte
+}
+
+func newPartialID(index int, p *proto.PartialBeaconPacket) partialID {
+	h := sha256.New()
+	for _, b := range [][]byte{p.GetPreviousSig(), p.GetPartialSig(), p.GetPartialSigV2()} {
+		// the length prefix keeps the fields from being shifted into each other
+		_ = binary.Write(h, binary.BigEndian, uint32(len(b)))
+		_, _ = h.Write(b)
+	}
+	id := partialID{round: p.GetRound(), index: index}
+	copy(id.digest[:], h.Sum(nil))
+	return id
+}
+
+// seenPartials remembers whether the last partial signature packets received
+// were valid, so that the packets received again, which happens a lot when
+// nodes keep reconnecting to each other, are not verified again. It holds at
+// most a fixed number of packets and forgets the oldest ones first.
+type seenPartials struct {
+	sync.Mutex
+	valid map[partialID]bool
+	order []partialID
+	next  int
+	size  int
+}
+
+func newSeenPartials(size int) *seenPartials {
+	return &seenPartials{
+		valid: make(map[partialID]bool),
+		order: make([]partialID, 0, size),
+		size:  size,
+	}
+}
+
+// get returns whether the packet is valid, and false if it was not seen.
+func (s *seenPartials) get(id partialID) (valid bool, seen bool) {
+	s.Lock()
+	defer s.Unlock()
+	valid, seen = s.valid[id]
+	return
+}
+
+// add records whether the packet is valid.
+func (s *seenPartials) add(id partialID, valid bool) {
+	s.Lock()
+	defer s.Unlock()
+	if _, seen := s.valid[id]; seen {
+		s.valid[id] = valid
+		return
+	}
+	if len(s.order) < s.size {
+		s.order = append(s.order, id)
+	} else {
+		delete(s.valid, s.order[s.next])
+		s.order[s.next] = id
+		s.next = (s.next + 1) % s.size
+	}
+	s.valid[id] = valid
+}
+
+// reset forgets all the packets, which are verified against the public
+// polynomial of the group.
+func (s *seenPartials) reset() {
+	s.Lock()
+	defer s.Unlock()
+	s.valid = make(map[partialID]bool)
+	s.order = s.order[:0]
+	s.next = 0
+}
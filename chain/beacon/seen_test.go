@@ -0,0 +1,42 @@
+package beacon
+
+import (
+	"testing"
+
+	proto "github.com/drand/drand/protobuf/drand"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSeenPartials(t *testing.T) {
+	p := &proto.PartialBeaconPacket{Round: 10, PreviousSig: []byte("prev"), PartialSig: []byte("sig")}
+	id := newPartialID(1, p)
+	require.Equal(t, id, newPartialID(1, p))
+	// the same packet from another index or with another signature
+	require.NotEqual(t, id, newPartialID(2, p))
+	other := &proto.PartialBeaconPacket{Round: 10, PreviousSig: []byte("pre"), PartialSig: []byte("vsig")}
+	require.NotEqual(t, id, newPartialID(1, other))
+
+	s := newSeenPartials(2)
+	_, seen := s.get(id)
+	require.False(t, seen)
+	s.add(id, true)
+	valid, seen := s.get(id)
+	require.True(t, seen)
+	require.True(t, valid)
+
+	s.add(newPartialID(1, other), false)
+	valid, seen = s.get(newPartialID(1, other))
+	require.True(t, seen)
+	require.False(t, valid)
+
+	// the oldest packet is forgotten first
+	s.add(newPartialID(2, p), true)
+	_, seen = s.get(id)
+	require.False(t, seen)
+	_, seen = s.get(newPartialID(2, p))
+	require.True(t, seen)
+
+	s.reset()
+	_, seen = s.get(newPartialID(2, p))
+	require.False(t, seen)
+}
@@ -0,0 +1,46 @@
+package beacon
+
+import (
+	"errors"
+
+	"github.com/drand/drand/chain"
+	"github.com/drand/drand/key"
+)
+
+// Signer produces the partial signatures of the node. It may keep the private
+// share outside of the node, for instance in a remote signing daemon (see the
+// signer package) in front of an HSM, so that the node never holds it.
+type Signer interface {
+	// SignPartial returns the partial signatures of a round, over the round
+	// and previous signature and over the round only.
+	SignPartial(round uint64, previousSig []byte) (sig, sigV2 []byte, err error)
+}
+
+// shareSigner signs with a private share held in memory.
+type shareSigner struct {
+	share *key.Share
+}
+
+// NewShareSigner returns a signer using a private share held in memory.
+func NewShareSigner(s *key.Share) Signer {
+	return &shareSigner{share: s}
+}
+
+func (s *shareSigner) SignPartial(round uint64, previousSig []byte) ([]byte, []byte, error) {
+	if s.share == nil || s.share.Share == nil {
+		return nil, nil, errors.New("beacon: no private share")
+	}
+	suite, err := s.share.Suite()
+	if err != nil {
+		return nil, nil, err
+	}
+	sig, err := suite.ThresholdScheme.Sign(s.share.PrivateShare(), chain.Message(round, previousSig))
+	if err != nil {
+		return nil, nil, err
+	}
+	sigV2, err := suite.ThresholdScheme.Sign(s.share.PrivateShare(), chain.MessageV2(round))
+	if err != nil {
+		return nil, nil, err
+	}
+	return sig, sigV2, nil
+}
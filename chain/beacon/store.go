@@ -8,7 +8,6 @@ import (
 	"time"
 
 	"github.com/drand/drand/chain"
-	"github.com/drand/drand/key"
 	"github.com/drand/drand/log"
 	"github.com/drand/drand/metrics"
 )
@@ -56,15 +55,17 @@ func (a *appendStore) Put(b *chain.Beacon) error {
 // discrepancyStore is used to log timing information about the rounds
 type discrepancyStore struct {
 	chain.Store
-	l     log.Logger
-	group *key.Group
+	l log.Logger
+	// crypto gives the current group, whose schedule sets the expected time
+	// of the rounds
+	crypto *cryptoStore
 }
 
-func newDiscrepancyStore(s chain.Store, l log.Logger, group *key.Group) chain.Store {
+func newDiscrepancyStore(s chain.Store, l log.Logger, c *cryptoStore) chain.Store {
 	return &discrepancyStore{
-		Store: s,
-		l:     l,
-		group: group,
+		Store:  s,
+		l:      l,
+		crypto: c,
 	}
 }
 
@@ -73,7 +74,7 @@ func (d *discrepancyStore) Put(b *chain.Beacon) error {
 		return err
 	}
 	actual := time.Now().UnixNano()
-	expected := chain.TimeOfRound(d.group.Period, d.group.GenesisTime, b.Round) * 1e9
+	expected := chain.EpochTimeOfRound(d.crypto.GetGroup().Schedule(), b.Round) * 1e9
 	discrepancy := float64(actual-expected) / float64(time.Millisecond)
 	metrics.BeaconDiscrepancyLatency.Set(float64(actual-expected) / float64(time.Millisecond))
 	metrics.LastBeaconRound.Set(float64(b.GetRound()))
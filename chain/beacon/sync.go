@@ -10,6 +10,7 @@ import (
 
 	"github.com/drand/drand/chain"
 	"github.com/drand/drand/log"
+	"github.com/drand/drand/metrics"
 	"github.com/drand/drand/net"
 	proto "github.com/drand/drand/protobuf/drand"
 )
@@ -26,6 +27,8 @@ type Syncer interface {
 	Syncing() bool
 	// SyncChain imeplements the server side of the syncing process
 	SyncChain(req *proto.SyncRequest, p proto.Protocol_SyncChainServer) error
+	// Status returns the progress of the current or last sync
+	Status() *SyncStatus
 }
 
 // syncer implements the Syncer interface
@@ -35,6 +38,7 @@ type syncer struct {
 	info      *chain.Info
 	client    net.ProtocolClient
 	following bool
+	status    SyncStatus
 	sync.Mutex
 }
 
@@ -54,6 +58,30 @@ func (s *syncer) Syncing() bool {
 	return s.following
 }
 
+func (s *syncer) Status() *SyncStatus {
+	s.Lock()
+	defer s.Unlock()
+	return s.status.copy()
+}
+
+// progress records the last round stored by the sync
+func (s *syncer) progress(round uint64) {
+	s.Lock()
+	defer s.Unlock()
+	s.status.Current = round
+}
+
+func (s *syncer) finish(err error) {
+	metrics.SyncTargetRound.Set(0)
+	s.Lock()
+	defer s.Unlock()
+	s.following = false
+	s.status.Syncing = false
+	if err != nil {
+		s.status.Error = err.Error()
+	}
+}
+
 func (s *syncer) Follow(c context.Context, upTo uint64, nodes []net.Peer) error {
 	s.Lock()
 	if s.following {
@@ -61,23 +89,37 @@ func (s *syncer) Follow(c context.Context, upTo uint64, nodes []net.Peer) error
 		return errors.New("already following chain")
 	}
 	s.following = true
+	var from uint64
+	if last, err := s.store.Last(); err == nil {
+		from = last.Round + 1
+	}
+	s.status = SyncStatus{Syncing: true, From: from, Target: upTo, Current: from - 1}
 	s.Unlock()
-	defer func() {
-		s.Lock()
-		s.following = false
-		s.Unlock()
-	}()
+	metrics.SyncTargetRound.Set(float64(upTo))
+	var err error
+	defer func() { s.finish(err) }()
 
 	s.l.Debug("syncer", "starting", "up_to", upTo, "nodes", peersToString(nodes))
 
+	// catch up from several nodes at once when far behind, and fetch what is
+	// left one node at a time
+	if upTo > 0 && from > 0 && upTo >= from+SyncChunkSize && len(nodes) > 1 {
+		if err = s.parallelSync(c, from, upTo, nodes); err == nil {
+			return nil
+		}
+		s.l.Debug("syncer", "parallel_sync_failed", "err", err)
+	}
+
 	// shuffle through the nodes
 	for _, n := range rand.Perm(len(nodes)) {
 		node := nodes[n]
 		if s.tryNode(c, upTo, node) {
+			err = nil
 			return nil
 		}
 	}
-	return errors.New("sync store tried to follow all nodes")
+	err = errors.New("sync store tried to follow all nodes")
+	return err
 }
 
 func (s *syncer) tryNode(global context.Context, upTo uint64, n net.Peer) bool {
@@ -102,7 +144,7 @@ func (s *syncer) tryNode(global context.Context, upTo uint64, n net.Peer) bool {
 		beacon := protoToBeacon(beaconPacket)
 
 		// verify the signature validity
-		if err := chain.VerifyBeacon(s.info.PublicKey, beacon); err != nil {
+		if err := s.info.VerifyBeacon(beacon); err != nil {
 			s.l.Debug("syncer", "invalid_beacon", "with_peer", n.Address(), "round", beacon.Round, "err", err, fmt.Sprintf("%+v", beacon))
 			return false
 		}
@@ -112,6 +154,7 @@ func (s *syncer) tryNode(global context.Context, upTo uint64, n net.Peer) bool {
 			return false
 		}
 		last = beacon
+		s.progress(last.Round)
 		if last.Round == upTo {
 			s.l.Debug("syncer", "syncing finished to", "round", upTo)
 			return true
@@ -132,14 +175,18 @@ func (s *syncer) tryNode(global context.Context, upTo uint64, n net.Peer) bool {
 
 func (s *syncer) SyncChain(req *proto.SyncRequest, stream proto.Protocol_SyncChainServer) error {
 	fromRound := req.GetFromRound()
+	upTo := net.SyncUpToFromContext(stream.Context())
 	addr := net.RemoteAddress(stream.Context())
-	s.l.Debug("syncer", "sync_request", "from", addr, "from_round", fromRound)
+	s.l.Debug("syncer", "sync_request", "from", addr, "from_round", fromRound, "up_to", upTo)
 
 	last, err := s.store.Last()
 	if err != nil {
 		return err
 	}
-	if last.Round < fromRound {
+	// a peer following the chain from the next round waits for it, unless
+	// this node is catching up itself: two nodes missing the same round would
+	// otherwise wait for each other.
+	if last.Round < fromRound && (last.Round+1 < fromRound || s.Syncing()) {
 		return fmt.Errorf("no beacon stored above requested round %d < %d", last.Round, fromRound)
 	}
 
@@ -147,7 +194,14 @@ func (s *syncer) SyncChain(req *proto.SyncRequest, stream proto.Protocol_SyncCha
 		// first sync up from the store itself
 		var err error
 		s.store.Cursor(func(c chain.Cursor) {
-			for bb := c.Seek(fromRound); bb != nil; bb = c.Next() {
+			expected := fromRound
+			for bb := c.Seek(fromRound); bb != nil && (upTo == 0 || bb.Round <= upTo); bb = c.Next() {
+				// the rounds before the retention horizon are pruned
+				if bb.Round != expected {
+					err = fmt.Errorf("round %d is not stored anymore", expected)
+					return
+				}
+				expected++
 				if err = stream.Send(beaconToProto(bb)); err != nil {
 					s.l.Debug("syncer", "streaming_send", "err", err)
 					return
@@ -157,14 +211,25 @@ func (s *syncer) SyncChain(req *proto.SyncRequest, stream proto.Protocol_SyncCha
 		if err != nil {
 			return err
 		}
+		if upTo != 0 && upTo <= last.Round {
+			return nil
+		}
 	}
 	var done = make(chan error, 1)
 	// then register a callback to process new incoming beacons
 	s.store.AddCallback(addr, func(b *chain.Beacon) {
+		if upTo != 0 && b.Round > upTo {
+			return
+		}
 		err := stream.Send(beaconToProto(b))
 		if err != nil {
 			s.l.Debug("syncer", "streaming_send", "err", err)
-			done <- nil
+		}
+		if err != nil || b.Round == upTo {
+			select {
+			case done <- nil:
+			default:
+			}
 		}
 	})
 	defer s.store.RemoveCallback(addr)
@@ -0,0 +1,327 @@
+package beacon
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"sort"
+	"sync"
+
+	"github.com/drand/drand/chain"
+	"github.com/drand/drand/metrics"
+	"github.com/drand/drand/net"
+	proto "github.com/drand/drand/protobuf/drand"
+)
+
+// SyncStatus is the progress of the sync of a node with the other nodes.
+type SyncStatus struct {
+	// Syncing is true while the node fetches rounds from other nodes.
+	Syncing bool `json:"syncing"`
+	// From is the first round of the last sync.
+	From uint64 `json:"from"`
+	// Target is the last round of the last sync, zero when following the
+	// chain.
+	Target uint64 `json:"target"`
+	// Current is the last round stored by the last sync.
+	Current uint64 `json:"current"`
+	// Peers holds the rounds fetched from each peer, ordered by address.
+	Peers []*SyncPeerStatus `json:"peers,omitempty"`
+	// Error is the reason the last sync failed.
+	Error string `json:"error,omitempty"`
+}
+
+// SyncPeerStatus is what a node fetched from a peer during a sync.
+type SyncPeerStatus struct {
+	Address string `json:"address"`
+	// Rounds is the number of valid rounds fetched from the peer.
+	Rounds uint64 `json:"rounds"`
+	// Failures is the number of ranges of rounds the peer failed to serve.
+	Failures  int    `json:"failures"`
+	LastError string `json:"last_error,omitempty"`
+}
+
+func (s *SyncStatus) peer(addr string) *SyncPeerStatus {
+	for _, p := range s.Peers {
+		if p.Address == addr {
+			return p
+		}
+	}
+	p := &SyncPeerStatus{Address: addr}
+	s.Peers = append(s.Peers, p)
+	sort.Slice(s.Peers, func(i, j int) bool { return s.Peers[i].Address < s.Peers[j].Address })
+	return p
+}
+
+func (s *SyncStatus) copy() *SyncStatus {
+	c := *s
+	c.Peers = make([]*SyncPeerStatus, len(s.Peers))
+	for i, p := range s.Peers {
+		pc := *p
+		c.Peers[i] = &pc
+	}
+	return &c
+}
+
+// syncRange is a range of rounds cut in chunks of SyncChunkSize rounds.
+type syncRange struct {
+	from, upTo uint64
+}
+
+func (r syncRange) chunks() int {
+	return int((r.upTo - r.from + SyncChunkSize) / SyncChunkSize)
+}
+
+// chunk returns the first and last rounds of the i-th chunk.
+func (r syncRange) chunk(i int) (uint64, uint64) {
+	from := r.from + uint64(i)*SyncChunkSize
+	to := from + SyncChunkSize - 1
+	if to > r.upTo {
+		to = r.upTo
+	}
+	return from, to
+}
+
+// chunkQueue hands out the chunks to fetch, lowest first. Only the chunks
+// close to the next one to store are handed out, so that the chunks fetched
+// but waiting for a previous one to be stored stay bounded.
+type chunkQueue struct {
+	sync.Mutex
+	cond    *sync.Cond
+	pending []int
+	next    int
+	window  int
+	closed  bool
+}
+
+func newChunkQueue(chunks, window int) *chunkQueue {
+	q := &chunkQueue{window: window}
+	q.cond = sync.NewCond(q)
+	for i := 0; i < chunks; i++ {
+		q.pending = append(q.pending, i)
+	}
+	return q
+}
+
+// get blocks until a chunk can be fetched and returns it, or returns false
+// when the queue is closed.
+func (q *chunkQueue) get() (int, bool) {
+	q.Lock()
+	defer q.Unlock()
+	for {
+		if q.closed {
+			return 0, false
+		}
+		if len(q.pending) > 0 && q.pending[0] < q.next+q.window {
+			i := q.pending[0]
+			q.pending = q.pending[1:]
+			return i, true
+		}
+		q.cond.Wait()
+	}
+}
+
+// put hands out the chunk again, after it failed to be fetched.
+func (q *chunkQueue) put(i int) {
+	q.Lock()
+	defer q.Unlock()
+	idx := sort.SearchInts(q.pending, i)
+	q.pending = append(q.pending, 0)
+	copy(q.pending[idx+1:], q.pending[idx:])
+	q.pending[idx] = i
+	q.cond.Broadcast()
+}
+
+// stored records that the chunks before next are stored.
+func (q *chunkQueue) stored(next int) {
+	q.Lock()
+	defer q.Unlock()
+	q.next = next
+	q.cond.Broadcast()
+}
+
+func (q *chunkQueue) close() {
+	q.Lock()
+	defer q.Unlock()
+	q.closed = true
+	q.cond.Broadcast()
+}
+
+type fetchedChunk struct {
+	index   int
+	beacons []*chain.Beacon
+}
+
+// parallelSync fetches the rounds from `from` up to `upTo` from several peers
+// at once, each peer sending chunks of SyncChunkSize rounds which are verified
+// with a single batch verification. The chunks a peer fails to send in time or
+// sends invalid are fetched from another peer, and peers failing too many
+// chunks are not used anymore. The chunks are stored in order.
+func (s *syncer) parallelSync(ctx context.Context, from, upTo uint64, peers []net.Peer) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	r := syncRange{from: from, upTo: upTo}
+	workers := SyncParallelism
+	if len(peers) < workers {
+		workers = len(peers)
+	}
+	q := newChunkQueue(r.chunks(), 2*workers)
+	defer q.close()
+
+	pool := make(chan net.Peer, len(peers))
+	for _, i := range rand.Perm(len(peers)) {
+		pool <- peers[i]
+	}
+	results := make(chan *fetchedChunk, workers)
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			s.syncWorker(ctx, r, q, pool, results)
+		}()
+	}
+	go func() {
+		wg.Wait()
+		close(results)
+	}()
+
+	s.l.Debug("syncer", "parallel_sync", "from", from, "up_to", upTo, "chunks", r.chunks(), "workers", workers)
+	fetched := make(map[int][]*chain.Beacon)
+	for next := 0; next < r.chunks(); {
+		select {
+		case res, ok := <-results:
+			if !ok {
+				return errors.New("no peer left to sync from")
+			}
+			fetched[res.index] = res.beacons
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+		for beacons, ok := fetched[next]; ok; beacons, ok = fetched[next] {
+			delete(fetched, next)
+			for _, b := range beacons {
+				if err := s.store.Put(b); err != nil {
+					return fmt.Errorf("unable to save round %d: %w", b.Round, err)
+				}
+			}
+			next++
+			q.stored(next)
+			s.progress(beacons[len(beacons)-1].Round)
+		}
+	}
+	return nil
+}
+
+// syncWorker fetches chunks from a peer of the pool until the queue is closed,
+// and switches to another peer when the peer fails too many chunks.
+func (s *syncer) syncWorker(ctx context.Context, r syncRange, q *chunkQueue, pool chan net.Peer, results chan<- *fetchedChunk) {
+	for {
+		var peer net.Peer
+		select {
+		case peer = <-pool:
+		default:
+			return
+		}
+		for failures := 0; failures < SyncMaxPeerFailures; {
+			i, ok := q.get()
+			if !ok {
+				return
+			}
+			from, to := r.chunk(i)
+			beacons, err := s.fetchChunk(ctx, peer, from, to)
+			if err != nil {
+				q.put(i)
+				if ctx.Err() != nil {
+					return
+				}
+				failures++
+				s.peerFailed(peer, err)
+				continue
+			}
+			s.peerServed(peer, len(beacons))
+			select {
+			case results <- &fetchedChunk{index: i, beacons: beacons}:
+			case <-ctx.Done():
+				return
+			}
+		}
+		s.l.Debug("syncer", "dropping_peer", "peer", peer.Address(), "failures", SyncMaxPeerFailures)
+	}
+}
+
+// fetchChunk fetches and verifies the rounds from `from` to `to` from the peer.
+func (s *syncer) fetchChunk(ctx context.Context, peer net.Peer, from, to uint64) ([]*chain.Beacon, error) {
+	ctx, cancel := context.WithTimeout(net.WithSyncUpTo(ctx, to), SyncChunkTimeout)
+	defer cancel()
+	beaconCh, err := s.client.SyncChain(ctx, peer, &proto.SyncRequest{FromRound: from})
+	if err != nil {
+		return nil, err
+	}
+	// the stream is canceled once the chunk is read, but it may still be
+	// sending beacons that nobody reads anymore
+	defer func() {
+		go func() {
+			for range beaconCh {
+			}
+		}()
+	}()
+
+	beacons := make([]*chain.Beacon, 0, to-from+1)
+	for packet := range beaconCh {
+		b := protoToBeacon(packet)
+		if expected := from + uint64(len(beacons)); b.Round != expected {
+			return nil, fmt.Errorf("received round %d instead of round %d", b.Round, expected)
+		}
+		beacons = append(beacons, b)
+		if b.Round == to {
+			break
+		}
+	}
+	if len(beacons) == 0 || beacons[len(beacons)-1].Round != to {
+		if ctx.Err() != nil {
+			return nil, fmt.Errorf("chunk %d-%d: %w", from, to, ctx.Err())
+		}
+		return nil, fmt.Errorf("chunk %d-%d: stream ended after %d rounds", from, to, len(beacons))
+	}
+	if err := s.verifyChunk(beacons); err != nil {
+		return nil, err
+	}
+	return beacons, nil
+}
+
+// verifyChunk verifies the beacons of a chunk, all at once when the chain is
+// made of chained signatures of the default scheme.
+func (s *syncer) verifyChunk(beacons []*chain.Beacon) error {
+	last := beacons[len(beacons)-1]
+	if s.info.SchemeID() == chain.DefaultSchemeID && (s.info.V2From == 0 || last.Round < s.info.V2From) {
+		if _, err := chain.VerifyBeacons(s.info.PublicKey, beacons); err != nil {
+			return err
+		}
+		return nil
+	}
+	for _, b := range beacons {
+		if err := s.info.VerifyBeacon(b); err != nil {
+			return fmt.Errorf("invalid round %d: %w", b.Round, err)
+		}
+	}
+	return nil
+}
+
+func (s *syncer) peerServed(peer net.Peer, rounds int) {
+	metrics.SyncPeerRounds.WithLabelValues(peer.Address()).Add(float64(rounds))
+	s.Lock()
+	defer s.Unlock()
+	s.status.peer(peer.Address()).Rounds += uint64(rounds)
+}
+
+func (s *syncer) peerFailed(peer net.Peer, err error) {
+	s.l.Debug("syncer", "chunk_failed", "peer", peer.Address(), "err", err)
+	metrics.SyncPeerFailures.WithLabelValues(peer.Address()).Inc()
+	s.Lock()
+	defer s.Unlock()
+	p := s.status.peer(peer.Address())
+	p.Failures++
+	p.LastError = err.Error()
+}
@@ -0,0 +1,170 @@
+package beacon
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"os"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/drand/drand/chain"
+	"github.com/drand/drand/chain/boltdb"
+	"github.com/drand/drand/key"
+	"github.com/drand/drand/log"
+	"github.com/drand/drand/net"
+	proto "github.com/drand/drand/protobuf/drand"
+	"github.com/drand/kyber/util/random"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/metadata"
+)
+
+// rangeClient serves the rounds of a chain to the syncer, with some peers
+// down and some peers sending rounds signed with another key.
+type rangeClient struct {
+	net.ProtocolClient
+	beacons []*chain.Beacon
+	forged  []*chain.Beacon
+	down    map[string]bool
+	forgers map[string]bool
+}
+
+func (r *rangeClient) SyncChain(ctx context.Context, p net.Peer, in *proto.SyncRequest, opts ...net.CallOption) (chan *proto.BeaconPacket, error) {
+	if r.down[p.Address()] {
+		return nil, errors.New("peer is down")
+	}
+	upTo := uint64(len(r.beacons) - 1)
+	md, _ := metadata.FromOutgoingContext(ctx)
+	if vals := md.Get(net.SyncUpToKey); len(vals) > 0 {
+		upTo, _ = strconv.ParseUint(vals[0], 10, 64)
+	}
+	beacons := r.beacons
+	if r.forgers[p.Address()] {
+		beacons = r.forged
+	}
+	ch := make(chan *proto.BeaconPacket)
+	go func() {
+		defer close(ch)
+		for round := in.GetFromRound(); round <= upTo; round++ {
+			select {
+			case ch <- beaconToProto(beacons[round]):
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return ch, nil
+}
+
+func makeChain(t *testing.T, rounds int) ([]*chain.Beacon, *chain.Info) {
+	priv := key.KeyGroup.Scalar().Pick(random.New())
+	info := &chain.Info{PublicKey: key.KeyGroup.Point().Mul(priv, nil), GroupHash: []byte("seed")}
+	beacons := []*chain.Beacon{{Round: 0, Signature: info.GroupHash}}
+	for round := uint64(1); round <= uint64(rounds); round++ {
+		prevSig := beacons[round-1].Signature
+		sig, err := key.AuthScheme.Sign(priv, chain.Message(round, prevSig))
+		require.NoError(t, err)
+		beacons = append(beacons, &chain.Beacon{Round: round, Signature: sig, PreviousSig: prevSig})
+	}
+	return beacons, info
+}
+
+func newSyncTestStore(t *testing.T, genesis *chain.Beacon) (CallbackStore, func()) {
+	dir, err := ioutil.TempDir("", "sync")
+	require.NoError(t, err)
+	bbstore, err := boltdb.NewBoltStore(dir, nil)
+	require.NoError(t, err)
+	require.NoError(t, bbstore.Put(genesis))
+	return NewCallbackStore(newAppendStore(bbstore)), func() {
+		bbstore.Close()
+		os.RemoveAll(dir)
+	}
+}
+
+func TestChunkQueue(t *testing.T) {
+	q := newChunkQueue(5, 2)
+	i, ok := q.get()
+	require.True(t, ok)
+	require.Equal(t, 0, i)
+	i, _ = q.get()
+	require.Equal(t, 1, i)
+
+	// chunk 2 is out of the window until chunk 0 is stored
+	got := make(chan int, 1)
+	go func() {
+		i, _ := q.get()
+		got <- i
+	}()
+	select {
+	case <-got:
+		t.Fatal("chunk handed out beyond the window")
+	case <-time.After(50 * time.Millisecond):
+	}
+	q.stored(1)
+	require.Equal(t, 2, <-got)
+
+	// a failed chunk is handed out first again
+	q.put(1)
+	i, _ = q.get()
+	require.Equal(t, 1, i)
+
+	q.close()
+	_, ok = q.get()
+	require.False(t, ok)
+}
+
+func TestParallelSync(t *testing.T) {
+	defer func(size uint64) { SyncChunkSize = size }(SyncChunkSize)
+	SyncChunkSize = 7
+
+	rounds := 50
+	beacons, info := makeChain(t, rounds)
+	forged, _ := makeChain(t, rounds)
+	peers := []net.Peer{
+		net.CreatePeer("good1:1234", false),
+		net.CreatePeer("good2:1234", false),
+		net.CreatePeer("down:1234", false),
+		net.CreatePeer("forger:1234", false),
+	}
+	client := &rangeClient{
+		beacons: beacons,
+		forged:  forged,
+		down:    map[string]bool{"down:1234": true},
+		forgers: map[string]bool{"forger:1234": true},
+	}
+	store, cleanup := newSyncTestStore(t, beacons[0])
+	defer cleanup()
+
+	s := NewSyncer(log.DefaultLogger(), store, info, client)
+	require.NoError(t, s.Follow(context.Background(), uint64(rounds), peers))
+	last, err := store.Last()
+	require.NoError(t, err)
+	require.True(t, beacons[rounds].Equal(last))
+
+	status := s.Status()
+	require.False(t, status.Syncing)
+	require.Equal(t, uint64(1), status.From)
+	require.Equal(t, uint64(rounds), status.Current)
+	var fetched uint64
+	for _, p := range status.Peers {
+		switch p.Address {
+		case "down:1234", "forger:1234":
+			require.Zero(t, p.Rounds)
+			require.NotZero(t, p.Failures)
+		default:
+			fetched += p.Rounds
+		}
+	}
+	require.Equal(t, uint64(rounds), fetched)
+
+	// with only bad peers, the sync fails without storing anything
+	store, cleanup = newSyncTestStore(t, beacons[0])
+	defer cleanup()
+	s = NewSyncer(log.DefaultLogger(), store, info, client)
+	require.Error(t, s.Follow(context.Background(), uint64(rounds), peers[2:]))
+	last, err = store.Last()
+	require.NoError(t, err)
+	require.Equal(t, uint64(0), last.Round)
+	require.NotEmpty(t, s.Status().Error)
+}
@@ -1,34 +1,51 @@
 package beacon
 
 import (
+	"sync"
 	"time"
 
 	"github.com/drand/drand/chain"
+	"github.com/drand/drand/key"
 	clock "github.com/jonboulle/clockwork"
 )
 
 const tickerChanBacklog = 5
 
 type ticker struct {
-	clock   clock.Clock
-	period  time.Duration
-	genesis int64
-	newCh   chan channelInfo
-	stop    chan bool
+	sync.Mutex
+	clock clock.Clock
+	// epochs of the chain, which set the time of each round
+	epochs []*key.Epoch
+	newCh  chan channelInfo
+	stop   chan bool
 }
 
-func newTicker(c clock.Clock, period time.Duration, genesis int64) *ticker {
+func newTicker(c clock.Clock, epochs []*key.Epoch) *ticker {
 	t := &ticker{
-		clock:   c,
-		period:  period,
-		genesis: genesis,
-		newCh:   make(chan channelInfo, tickerChanBacklog),
-		stop:    make(chan bool, 1),
+		clock:  c,
+		epochs: epochs,
+		newCh:  make(chan channelInfo, tickerChanBacklog),
+		stop:   make(chan bool, 1),
 	}
 	go t.Start()
 	return t
 }
 
+// SetSchedule changes the epochs of the chain, when a resharing changes the
+// period. The new epochs must agree with the current ones up to the epoch
+// they add, so that the ticks before the change are unaffected.
+func (t *ticker) SetSchedule(epochs []*key.Epoch) {
+	t.Lock()
+	defer t.Unlock()
+	t.epochs = epochs
+}
+
+func (t *ticker) schedule() []*key.Epoch {
+	t.Lock()
+	defer t.Unlock()
+	return t.epochs
+}
+
 func (t *ticker) Channel() chan roundInfo {
 	newCh := make(chan roundInfo, 1)
 	t.newCh <- channelInfo{
@@ -51,7 +68,7 @@ func (t *ticker) Stop() {
 }
 
 func (t *ticker) CurrentRound() uint64 {
-	return chain.CurrentRound(t.clock.Now().Unix(), t.period, t.genesis)
+	return chain.EpochCurrentRound(t.clock.Now().Unix(), t.schedule())
 }
 
 // Start will sleep until the next upcoming round and start sending out the
@@ -59,22 +76,19 @@ func (t *ticker) CurrentRound() uint64 {
 func (t *ticker) Start() {
 	chanTime := make(chan time.Time, 1)
 	// whole reason of this function is to accept new incoming channels while
-	// still sleeping until the next time
+	// still sleeping until the next time. The time of the next round is
+	// computed at each tick, so that the ticks follow the period changes.
 	go func() {
-		now := t.clock.Now().Unix()
-		_, ttime := chain.NextRound(now, t.period, t.genesis)
-		if ttime > now {
-			t.clock.Sleep(time.Duration(ttime-now) * time.Second)
-		}
-		// first tick happens at specified time
-		chanTime <- t.clock.Now()
-		ticker := t.clock.NewTicker(t.period)
-		defer ticker.Stop()
-		tickChan := ticker.Chan()
 		for {
+			now := t.clock.Now().Unix()
+			_, ttime := chain.EpochNextRound(now, t.schedule())
+			select {
+			case <-t.clock.After(time.Duration(ttime-now) * time.Second):
+			case <-t.stop:
+				return
+			}
 			select {
-			case nt := <-tickChan:
-				chanTime <- nt
+			case chanTime <- t.clock.Now():
 			case <-t.stop:
 				return
 			}
@@ -104,7 +118,7 @@ func (t *ticker) Start() {
 		}
 		select {
 		case nt := <-chanTime:
-			tround = chain.CurrentRound(nt.Unix(), t.period, t.genesis)
+			tround = chain.EpochCurrentRound(nt.Unix(), t.schedule())
 			ttime = nt.Unix()
 			sendTicks = true
 		case newChan := <-t.newCh:
@@ -1,6 +1,7 @@
 package boltdb
 
 import (
+	"encoding/binary"
 	"errors"
 	"path"
 	"sync"
@@ -44,6 +45,29 @@ func NewBoltStore(folder string, opts *bolt.Options) (chain.Store, error) {
 	}, err
 }
 
+// OpenArchive opens the beacon database file at the given path in read-only
+// mode, for instance a copy of the database of a drand node. Any attempt to
+// modify the returned store fails.
+func OpenArchive(dbPath string) (chain.Store, error) {
+	db, err := bolt.Open(dbPath, 0440, &bolt.Options{ReadOnly: true})
+	if err != nil {
+		return nil, err
+	}
+	err = db.View(func(tx *bolt.Tx) error {
+		if tx.Bucket(beaconBucket) == nil {
+			return errors.New("no beacon found in archive")
+		}
+		return nil
+	})
+	if err != nil {
+		_ = db.Close()
+		return nil, err
+	}
+	return &boltStore{
+		db: db,
+	}, nil
+}
+
 func (b *boltStore) Len() int {
 	var length = 0
 	err := b.db.View(func(tx *bolt.Tx) error {
@@ -134,6 +158,47 @@ func (b *boltStore) Del(round uint64) error {
 	})
 }
 
+// delBatch is the number of rounds DelRange deletes per transaction, so that
+// pruning a large store does not build a huge transaction.
+const delBatch = 10000
+
+// DelRange implements the chain.RangeDeleter interface.
+func (b *boltStore) DelRange(from, to uint64, keep func(round uint64) bool) (int, error) {
+	var deleted int
+	for from <= to {
+		var keys [][]byte
+		err := b.db.Update(func(tx *bolt.Tx) error {
+			bucket := tx.Bucket(beaconBucket)
+			c := bucket.Cursor()
+			// keys are collected first as deleting moves the cursor
+			for k, _ := c.Seek(chain.RoundToBytes(from)); k != nil && len(keys) < delBatch; k, _ = c.Next() {
+				round := binary.BigEndian.Uint64(k)
+				if round > to {
+					break
+				}
+				from = round + 1
+				if !keep(round) {
+					keys = append(keys, append([]byte{}, k...))
+				}
+			}
+			for _, k := range keys {
+				if err := bucket.Delete(k); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return deleted, err
+		}
+		if len(keys) == 0 {
+			break
+		}
+		deleted += len(keys)
+	}
+	return deleted, nil
+}
+
 func (b *boltStore) Cursor(fn func(chain.Cursor)) {
 	err := b.db.View(func(tx *bolt.Tx) error {
 		bucket := tx.Bucket(beaconBucket)
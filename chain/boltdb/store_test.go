@@ -3,10 +3,12 @@ package boltdb
 import (
 	"io/ioutil"
 	"os"
+	"path"
 	"testing"
 	"time"
 
 	"github.com/drand/drand/chain"
+	"github.com/drand/drand/chain/storetest"
 	"github.com/stretchr/testify/require"
 )
 
@@ -124,3 +126,41 @@ func TestStoreBolt(t *testing.T) {
 	require.Nil(t, unknown)
 	require.Equal(t, ErrNoBeaconSaved, err)
 }
+
+func TestStoreBoltArchive(t *testing.T) {
+	tmp, err := ioutil.TempDir("", "drandtest*")
+	require.NoError(t, err)
+	defer os.RemoveAll(tmp)
+
+	_, err = OpenArchive(path.Join(tmp, BoltFileName))
+	require.Error(t, err)
+
+	store, err := NewBoltStore(tmp, nil)
+	require.NoError(t, err)
+	b1 := &chain.Beacon{
+		PreviousSig: []byte("a magnificent signature"),
+		Round:       145,
+		Signature:   []byte("one signature to"),
+	}
+	require.NoError(t, store.Put(b1))
+	store.Close()
+
+	archive, err := OpenArchive(path.Join(tmp, BoltFileName))
+	require.NoError(t, err)
+	defer archive.Close()
+	eb1, err := archive.Get(145)
+	require.NoError(t, err)
+	require.Equal(t, b1, eb1)
+	require.Error(t, archive.Put(b1))
+}
+
+func TestStoreBoltConformance(t *testing.T) {
+	storetest.Run(t, func(t *testing.T) chain.Store {
+		tmp, err := ioutil.TempDir("", "bolttest*")
+		require.NoError(t, err)
+		t.Cleanup(func() { os.RemoveAll(tmp) })
+		store, err := NewBoltStore(tmp, nil)
+		require.NoError(t, err)
+		return store
+	})
+}
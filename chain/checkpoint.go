@@ -0,0 +1,99 @@
+package chain
+
+import (
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/drand/drand/key"
+	json "github.com/nikkolasg/hexjson"
+)
+
+// Checkpoint is a beacon of a chain, attested by the signature of the group,
+// that light clients can trust as a starting point instead of walking the
+// chain from its first round. Since the group only signs the genuine rounds
+// of its chain, a checkpoint verifying against the chain public key commits
+// to the genuine signature of its round. Checkpoints carry the signature of
+// the chained scheme, over the round and the previous signature.
+type Checkpoint struct {
+	// ChainHash is the hash of the chain the checkpoint belongs to.
+	ChainHash []byte `json:"chain_hash"`
+	// Round is the round of the beacon.
+	Round uint64 `json:"round"`
+	// Signature is the signature of the beacon.
+	Signature []byte `json:"signature"`
+	// PreviousSignature is the signature of the previous round, signed along
+	// with the round.
+	PreviousSignature []byte `json:"previous_signature"`
+}
+
+// NewCheckpoint creates a checkpoint from a beacon of the chain, after having
+// verified it.
+func NewCheckpoint(info *Info, b *Beacon) (*Checkpoint, error) {
+	cp := &Checkpoint{
+		ChainHash:         info.Hash(),
+		Round:             b.Round,
+		Signature:         b.Signature,
+		PreviousSignature: b.PreviousSig,
+	}
+	if err := cp.Verify(info); err != nil {
+		return nil, err
+	}
+	return cp, nil
+}
+
+// CheckpointFromStore creates a checkpoint from the beacon of the given round
+// held by the store of a synced node, 0 designating the last stored beacon.
+func CheckpointFromStore(info *Info, s Store, round uint64) (*Checkpoint, error) {
+	var b *Beacon
+	var err error
+	if round == 0 {
+		b, err = s.Last()
+	} else {
+		b, err = s.Get(round)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("loading beacon: %w", err)
+	}
+	return NewCheckpoint(info, b)
+}
+
+// Verify returns an error unless the checkpoint belongs to the chain and its
+// signature is valid.
+func (cp *Checkpoint) Verify(info *Info) error {
+	if !key.ConstantTimeEqual(cp.ChainHash, info.Hash()) {
+		return errors.New("checkpoint belongs to another chain")
+	}
+	if cp.Round == 0 {
+		return errors.New("checkpoint of the genesis round")
+	}
+	s, err := SchemeFromID(info.Scheme)
+	if err != nil {
+		return err
+	}
+	msg := Message(cp.Round, cp.PreviousSignature)
+	return s.Suite().ThresholdScheme.VerifyRecovered(info.PublicKey.Clone(), msg, cp.Signature)
+}
+
+// Beacon returns the beacon of the checkpoint.
+func (cp *Checkpoint) Beacon() *Beacon {
+	return &Beacon{
+		Round:       cp.Round,
+		Signature:   cp.Signature,
+		PreviousSig: cp.PreviousSignature,
+	}
+}
+
+// CheckpointFromJSON reads a checkpoint from its JSON description.
+func CheckpointFromJSON(r io.Reader) (*Checkpoint, error) {
+	cp := new(Checkpoint)
+	if err := json.NewDecoder(r).Decode(cp); err != nil {
+		return nil, fmt.Errorf("reading checkpoint: %w", err)
+	}
+	return cp, nil
+}
+
+// ToJSON writes the JSON description of the checkpoint.
+func (cp *Checkpoint) ToJSON(w io.Writer) error {
+	return json.NewEncoder(w).Encode(cp)
+}
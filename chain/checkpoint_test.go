@@ -0,0 +1,45 @@
+package chain
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"testing"
+	"time"
+
+	"github.com/drand/drand/key"
+	"github.com/drand/kyber/util/random"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckpoint(t *testing.T) {
+	priv := key.KeyGroup.Scalar().Pick(random.New())
+	info := &Info{
+		PublicKey:   key.KeyGroup.Point().Mul(priv, nil),
+		Period:      time.Second,
+		GenesisTime: 1000,
+		GroupHash:   []byte("group"),
+	}
+	prev := sha256.Sum256([]byte("previous"))
+	sig, err := key.AuthScheme.Sign(priv, Message(42, prev[:]))
+	require.NoError(t, err)
+	b := &Beacon{Round: 42, Signature: sig, PreviousSig: prev[:]}
+
+	cp, err := NewCheckpoint(info, b)
+	require.NoError(t, err)
+	require.True(t, cp.Beacon().Equal(b))
+
+	var buff bytes.Buffer
+	require.NoError(t, cp.ToJSON(&buff))
+	read, err := CheckpointFromJSON(&buff)
+	require.NoError(t, err)
+	require.Equal(t, cp, read)
+	require.NoError(t, read.Verify(info))
+
+	forged := *cp
+	forged.Round++
+	require.Error(t, forged.Verify(info))
+
+	other := *info
+	other.GenesisTime++
+	require.Error(t, cp.Verify(&other))
+}
@@ -1,6 +1,7 @@
 package chain
 
 import (
+	"errors"
 	"fmt"
 	"io"
 	"time"
@@ -13,46 +14,106 @@ import (
 
 // InfoFromProto returns a Info from the protocol description
 func InfoFromProto(p *drand.ChainInfoPacket) (*Info, error) {
-	public := key.KeyGroup.Point()
+	s, err := SchemeFromID(p.Scheme)
+	if err != nil {
+		return nil, err
+	}
+	public := s.KeyGroup().Point()
 	if err := public.UnmarshalBinary(p.PublicKey); err != nil {
 		return nil, err
 	}
+	epochs, err := epochsFromProto(p)
+	if err != nil {
+		return nil, err
+	}
 
 	return &Info{
 		PublicKey:   public,
 		GenesisTime: p.GenesisTime,
 		Period:      time.Duration(p.Period) * time.Second,
 		GroupHash:   p.GroupHash,
+		Scheme:      p.Scheme,
+		Epochs:      epochs,
+		Derivation:  p.Derivation,
+		BeaconID:    p.BeaconID,
+		V2From:      p.V2From,
 	}, nil
 }
 
+// epochsFromProto returns the epochs of a chain info, checking the first one
+// is the genesis of the chain.
+func epochsFromProto(p *drand.ChainInfoPacket) ([]*key.Epoch, error) {
+	if len(p.Epochs) == 0 {
+		return nil, nil
+	}
+	epochs := make([]*key.Epoch, len(p.Epochs))
+	for i, e := range p.Epochs {
+		epochs[i] = &key.Epoch{Round: e.Round, Time: e.Time, Period: time.Duration(e.Period) * time.Second}
+	}
+	if err := key.CheckEpochs(epochs); err != nil {
+		return nil, err
+	}
+	if epochs[0].Time != p.GenesisTime || epochs[0].Period != time.Duration(p.Period)*time.Second {
+		return nil, errors.New("first epoch is not the genesis")
+	}
+	return epochs, nil
+}
+
 // ToProto returns the protobuf description of the chain info
 func (c *Info) ToProto() *drand.ChainInfoPacket {
 	buff, _ := c.PublicKey.MarshalBinary()
+	var epochs []*drand.Epoch
+	for _, e := range c.Epochs {
+		epochs = append(epochs, &drand.Epoch{Round: e.Round, Time: e.Time, Period: uint32(e.Period.Seconds())})
+	}
 	return &drand.ChainInfoPacket{
 		PublicKey:   buff,
 		GenesisTime: c.GenesisTime,
 		Period:      uint32(c.Period.Seconds()),
 		Hash:        c.Hash(),
 		GroupHash:   c.GroupHash,
+		Scheme:      c.Scheme,
+		Epochs:      epochs,
+		Derivation:  c.Derivation,
+		BeaconID:    c.BeaconID,
+		V2From:      c.V2From,
 	}
 }
 
+// infoJSON is the JSON description of a chain info: its protobuf description,
+// which carries the fields of version 2, along with the version and the
+// handover. Descriptions without version are of version 1.
+type infoJSON struct {
+	*drand.ChainInfoPacket
+	Version  int       `json:"version,omitempty"`
+	Handover *Handover `json:"handover,omitempty"`
+}
+
 // InfoFromJSON returns a Info from JSON description in the given reader
 func InfoFromJSON(buff io.Reader) (*Info, error) {
-	chainProto := new(drand.ChainInfoPacket)
-	if err := json.NewDecoder(buff).Decode(chainProto); err != nil {
+	chainJSON := infoJSON{ChainInfoPacket: new(drand.ChainInfoPacket)}
+	if err := json.NewDecoder(buff).Decode(&chainJSON); err != nil {
 		return nil, fmt.Errorf("reading group file (%v)", err)
 	}
-	chainInfo, err := InfoFromProto(chainProto)
+	if chainJSON.Version > InfoVersion {
+		return nil, fmt.Errorf("unsupported chain info version %d", chainJSON.Version)
+	}
+	chainInfo, err := InfoFromProto(chainJSON.ChainInfoPacket)
 	if err != nil {
 		return nil, fmt.Errorf("invalid chain info: %s", err)
 	}
+	chainInfo.Handover = chainJSON.Handover
 	return chainInfo, nil
 }
 
 // ToJSON provides a json serialization of an info packet
 func (c *Info) ToJSON(w io.Writer) error {
-	info := c.ToProto()
-	return json.NewEncoder(w).Encode(info)
+	info := infoJSON{
+		ChainInfoPacket: c.ToProto(),
+		Handover:        c.Handover,
+	}
+	if v := c.Version(); v > 1 {
+		info.Version = v
+	}
+	return json.NewEncoder(w).Encode(&info)
 }
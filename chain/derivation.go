@@ -0,0 +1,114 @@
+package chain
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash"
+	"sort"
+	"sync"
+
+	"golang.org/x/crypto/sha3"
+	"lukechampine.com/blake3"
+)
+
+// IDs of the built-in randomness derivations.
+const (
+	// DefaultDerivationID identifies the derivation of chains whose info does
+	// not name one: the randomness is the SHA-256 hash of the signature.
+	DefaultDerivationID = "sha256"
+	// SHA3DerivationID derives the randomness with SHA3-256.
+	SHA3DerivationID = "sha3-256"
+	// BLAKE3DerivationID derives the randomness with BLAKE3, with a 256-bit
+	// output.
+	BLAKE3DerivationID = "blake3-256"
+)
+
+// consumerDomain separates the randomness derived for consumers from the
+// randomness of the round.
+var consumerDomain = []byte("drand-randomness-for")
+
+// Derivation derives the randomness of a round from its signature with a
+// hash function. Other derivations can be registered with RegisterDerivation.
+type Derivation struct {
+	// ID identifies the derivation in chain info.
+	ID string
+	// New returns a hash computing the randomness.
+	New func() hash.Hash
+}
+
+// Randomness returns the randomness of a round with the given signature.
+func (d *Derivation) Randomness(sig []byte) []byte {
+	h := d.New()
+	_, _ = h.Write(sig)
+	return h.Sum(nil)
+}
+
+// RandomnessFor returns randomness reserved to a consumer, so that protocols
+// using the same rounds do not share their randomness. It is the hash of a
+// domain tag, the length prefixed consumer and the signature.
+func (d *Derivation) RandomnessFor(consumer string, sig []byte) []byte {
+	h := d.New()
+	_, _ = h.Write(consumerDomain)
+	_ = binary.Write(h, binary.BigEndian, uint32(len(consumer)))
+	_, _ = h.Write([]byte(consumer))
+	_, _ = h.Write(sig)
+	return h.Sum(nil)
+}
+
+var (
+	derivationsLk sync.RWMutex
+	derivations   = map[string]*Derivation{
+		DefaultDerivationID: {ID: DefaultDerivationID, New: sha256.New},
+		SHA3DerivationID:    {ID: SHA3DerivationID, New: sha3.New256},
+		BLAKE3DerivationID:  {ID: BLAKE3DerivationID, New: newBLAKE3256},
+	}
+)
+
+func newBLAKE3256() hash.Hash {
+	return blake3.New(32, nil)
+}
+
+// RegisterDerivation makes a derivation available to the chains naming it in
+// their info. It returns an error if a derivation with the same ID is
+// registered.
+func RegisterDerivation(d *Derivation) error {
+	if d.ID == "" || d.New == nil {
+		return errors.New("derivation without ID or hash")
+	}
+	derivationsLk.Lock()
+	defer derivationsLk.Unlock()
+	if _, ok := derivations[d.ID]; ok {
+		return fmt.Errorf("derivation %s already registered", d.ID)
+	}
+	derivations[d.ID] = d
+	return nil
+}
+
+// DerivationFromID returns the registered derivation with the given ID, the
+// empty ID designating the default derivation.
+func DerivationFromID(id string) (*Derivation, error) {
+	if id == "" {
+		id = DefaultDerivationID
+	}
+	derivationsLk.RLock()
+	defer derivationsLk.RUnlock()
+	d, ok := derivations[id]
+	if !ok {
+		return nil, fmt.Errorf("unknown randomness derivation %q", id)
+	}
+	return d, nil
+}
+
+// DerivationIDs returns the IDs of the registered derivations, sorted.
+func DerivationIDs() []string {
+	derivationsLk.RLock()
+	defer derivationsLk.RUnlock()
+	ids := make([]string, 0, len(derivations))
+	for id := range derivations {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
@@ -0,0 +1,70 @@
+package chain
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"testing"
+	"time"
+
+	"github.com/drand/drand/key"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDerivations(t *testing.T) {
+	sig := []byte("signature")
+
+	d, err := DerivationFromID("")
+	require.NoError(t, err)
+	require.Equal(t, RandomnessFromSignature(sig), d.Randomness(sig))
+
+	seen := make(map[string]bool)
+	for _, id := range []string{DefaultDerivationID, SHA3DerivationID, BLAKE3DerivationID} {
+		d, err := DerivationFromID(id)
+		require.NoError(t, err)
+		r := d.Randomness(sig)
+		require.Len(t, r, 32)
+		require.False(t, seen[string(r)])
+		seen[string(r)] = true
+
+		// consumers get their own randomness.
+		a := d.RandomnessFor("a", sig)
+		require.NotEqual(t, r, a)
+		require.NotEqual(t, a, d.RandomnessFor("b", sig))
+		require.Equal(t, a, d.RandomnessFor("a", sig))
+	}
+
+	// the BLAKE3 hash of the empty input, from the BLAKE3 test vectors
+	d, err = DerivationFromID(BLAKE3DerivationID)
+	require.NoError(t, err)
+	require.Equal(t, "af1349b9f5f9a1a6a0404dea36dcc9499bcb25c9adc112b7cc9a93cae41f3262", hex.EncodeToString(d.Randomness(nil)))
+
+	_, err = DerivationFromID("unknown")
+	require.Error(t, err)
+	require.Subset(t, DerivationIDs(), []string{BLAKE3DerivationID, DefaultDerivationID, SHA3DerivationID})
+	require.Error(t, RegisterDerivation(&Derivation{ID: DefaultDerivationID, New: sha256.New}))
+}
+
+func TestInfoDerivation(t *testing.T) {
+	info := &Info{
+		PublicKey:   key.KeyGroup.Point().Base(),
+		Period:      time.Second,
+		GenesisTime: 1000,
+	}
+	sig := []byte("signature")
+	r, err := info.Randomness(sig)
+	require.NoError(t, err)
+	require.Equal(t, RandomnessFromSignature(sig), r)
+
+	sha3 := *info
+	sha3.Derivation = SHA3DerivationID
+	require.NotEqual(t, info.Hash(), sha3.Hash())
+	require.False(t, info.Equal(&sha3))
+	r3, err := sha3.Randomness(sig)
+	require.NoError(t, err)
+	require.NotEqual(t, r, r3)
+
+	unknown := *info
+	unknown.Derivation = "unknown"
+	_, err = unknown.Randomness(sig)
+	require.Error(t, err)
+}
@@ -0,0 +1,148 @@
+// Package derive derives randomness for applications from the randomness of
+// drand rounds, so that applications do not need to roll their own key
+// derivation.
+//
+// A Source derived for a label and salt is an HKDF-SHA256 stream keyed by the
+// randomness of a round: applications using different labels or salts get
+// independent randomness from the same round, and any party knowing the round
+// can derive the same values.
+//
+//	src, err := derive.FromBeacon(info, beacon, "my-lottery", []byte("draw 12"))
+//	winner, err := src.Uint64n(uint64(len(tickets)))
+//
+// The beacon must have been verified beforehand.
+package derive
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"io"
+	"math"
+	"math/bits"
+
+	"golang.org/x/crypto/hkdf"
+
+	"github.com/drand/drand/chain"
+)
+
+// labelDomain separates the derivations of this package from other uses of
+// HKDF with the randomness of a round.
+var labelDomain = []byte("drand-derive-v1")
+
+// Source is a deterministic stream of randomness derived from the randomness
+// of a round. It delivers at most 8160 bytes.
+type Source struct {
+	r io.Reader
+}
+
+// New returns the source of randomness derived from the randomness of a
+// round for the given label and salt.
+func New(randomness []byte, label string, salt []byte) (*Source, error) {
+	return newSource(randomness, label, nil, salt)
+}
+
+// FromBeacon returns the source of randomness derived from a beacon of the
+// chain described by info for the given label and salt. The round is bound to
+// the label, so that the same label and salt give independent randomness at
+// each round.
+func FromBeacon(info *chain.Info, b *chain.Beacon, label string, salt []byte) (*Source, error) {
+	sig := b.Signature
+	if b.IsV2() {
+		sig = b.SignatureV2
+	}
+	randomness, err := info.Randomness(sig)
+	if err != nil {
+		return nil, err
+	}
+	var round [8]byte
+	binary.BigEndian.PutUint64(round[:], b.Round)
+	return newSource(randomness, label, round[:], salt)
+}
+
+// newSource returns the source derived with the HKDF info made of the domain,
+// the length prefixed label and the round, if any.
+func newSource(randomness []byte, label string, round, salt []byte) (*Source, error) {
+	if len(randomness) == 0 {
+		return nil, errors.New("no randomness to derive from")
+	}
+	if label == "" {
+		return nil, errors.New("empty label")
+	}
+	info := make([]byte, 0, len(labelDomain)+4+len(label)+len(round))
+	info = append(info, labelDomain...)
+	var l [4]byte
+	binary.BigEndian.PutUint32(l[:], uint32(len(label)))
+	info = append(info, l[:]...)
+	info = append(info, label...)
+	info = append(info, round...)
+	return &Source{r: hkdf.New(sha256.New, randomness, salt, info)}, nil
+}
+
+// Read fills p with derived randomness. It fails once the source is
+// exhausted.
+func (s *Source) Read(p []byte) (int, error) {
+	n, err := io.ReadFull(s.r, p)
+	if err != nil {
+		return n, errors.New("derived randomness exhausted")
+	}
+	return n, nil
+}
+
+// Bytes returns n bytes of derived randomness.
+func (s *Source) Bytes(n int) ([]byte, error) {
+	b := make([]byte, n)
+	if _, err := s.Read(b); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+// Uint64 returns a uniformly distributed uint64.
+func (s *Source) Uint64() (uint64, error) {
+	var b [8]byte
+	if _, err := s.Read(b[:]); err != nil {
+		return 0, err
+	}
+	return binary.BigEndian.Uint64(b[:]), nil
+}
+
+// Uint64n returns a uniformly distributed integer in [0, n), without modulo
+// bias: values from the incomplete last interval are rejected and drawn
+// again.
+func (s *Source) Uint64n(n uint64) (uint64, error) {
+	if n == 0 {
+		return 0, errors.New("empty range")
+	}
+	if bits.OnesCount64(n) == 1 {
+		v, err := s.Uint64()
+		return v & (n - 1), err
+	}
+	max := math.MaxUint64 - math.MaxUint64%n
+	for {
+		v, err := s.Uint64()
+		if err != nil {
+			return 0, err
+		}
+		if v < max {
+			return v % n, nil
+		}
+	}
+}
+
+// Shuffle shuffles n elements with the Fisher-Yates algorithm, calling swap
+// to exchange the elements of indexes i and j. Each permutation is equally
+// likely.
+func (s *Source) Shuffle(n int, swap func(i, j int)) error {
+	if n < 0 {
+		return errors.New("negative number of elements")
+	}
+	for i := n - 1; i > 0; i-- {
+		j, err := s.Uint64n(uint64(i + 1))
+		if err != nil {
+			return err
+		}
+		swap(i, int(j))
+	}
+	return nil
+}
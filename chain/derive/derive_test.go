@@ -0,0 +1,83 @@
+package derive
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/drand/drand/chain"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDerivation(t *testing.T) {
+	randomness := bytes.Repeat([]byte{1}, 32)
+	a, err := New(randomness, "app", []byte("salt"))
+	require.NoError(t, err)
+	a2, err := New(randomness, "app", []byte("salt"))
+	require.NoError(t, err)
+	b, err := New(randomness, "other", []byte("salt"))
+	require.NoError(t, err)
+
+	ra, err := a.Bytes(32)
+	require.NoError(t, err)
+	ra2, err := a2.Bytes(32)
+	require.NoError(t, err)
+	rb, err := b.Bytes(32)
+	require.NoError(t, err)
+	require.Equal(t, ra, ra2)
+	require.NotEqual(t, ra, rb)
+
+	_, err = New(randomness, "", nil)
+	require.Error(t, err)
+	_, err = New(nil, "app", nil)
+	require.Error(t, err)
+
+	// the source is exhausted after 255 hashes.
+	_, err = a.Bytes(255 * 32)
+	require.Error(t, err)
+}
+
+func TestFromBeacon(t *testing.T) {
+	info := &chain.Info{}
+	b1 := &chain.Beacon{Round: 1, Signature: []byte("signature")}
+	b2 := &chain.Beacon{Round: 2, Signature: []byte("signature")}
+	s1, err := FromBeacon(info, b1, "app", nil)
+	require.NoError(t, err)
+	s2, err := FromBeacon(info, b2, "app", nil)
+	require.NoError(t, err)
+	v1, err := s1.Uint64()
+	require.NoError(t, err)
+	v2, err := s2.Uint64()
+	require.NoError(t, err)
+	require.NotEqual(t, v1, v2)
+
+	// the randomness of the round follows the derivation of the chain
+	s3, err := FromBeacon(&chain.Info{Derivation: chain.SHA3DerivationID}, b1, "app", nil)
+	require.NoError(t, err)
+	v3, err := s3.Uint64()
+	require.NoError(t, err)
+	require.NotEqual(t, v1, v3)
+	_, err = FromBeacon(&chain.Info{Derivation: "unknown"}, b1, "app", nil)
+	require.Error(t, err)
+}
+
+func TestUint64nAndShuffle(t *testing.T) {
+	src, err := New(bytes.Repeat([]byte{2}, 32), "app", nil)
+	require.NoError(t, err)
+	for _, n := range []uint64{1, 2, 3, 10, 1 << 40, 1<<63 + 1} {
+		v, err := src.Uint64n(n)
+		require.NoError(t, err)
+		require.Less(t, v, n)
+	}
+	_, err = src.Uint64n(0)
+	require.Error(t, err)
+
+	items := []int{0, 1, 2, 3, 4, 5, 6, 7}
+	require.NoError(t, src.Shuffle(len(items), func(i, j int) {
+		items[i], items[j] = items[j], items[i]
+	}))
+	seen := make(map[int]bool)
+	for _, it := range items {
+		seen[it] = true
+	}
+	require.Len(t, seen, 8)
+}
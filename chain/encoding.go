@@ -0,0 +1,140 @@
+package chain
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+)
+
+// canonicalVersion is the version of the canonical encoding of beacons,
+// written first so that the encoding can evolve.
+const canonicalVersion = 1
+
+// digestDomain separates beacon digests from other hashes of the encoding.
+var digestDomain = []byte("drand-beacon-digest")
+
+// EncodeBeacon returns the canonical binary encoding of a beacon of a chain
+// using the given scheme, the empty scheme designating the default one. The
+// encoding is a version byte, the length prefixed scheme ID, the round on 8
+// bytes, then the signature, previous signature and v2 signature, each
+// prefixed by its length on 2 bytes, all in big endian. Two consumers encoding
+// the same beacon always get the same bytes.
+func EncodeBeacon(scheme string, b *Beacon) ([]byte, error) {
+	if scheme == "" {
+		scheme = DefaultSchemeID
+	}
+	if len(scheme) > math.MaxUint8 {
+		return nil, errors.New("scheme ID too long")
+	}
+	fields := [][]byte{b.Signature, b.PreviousSig, b.SignatureV2}
+	size := 1 + 1 + len(scheme) + 8
+	for _, f := range fields {
+		if len(f) > math.MaxUint16 {
+			return nil, errors.New("signature too long")
+		}
+		size += 2 + len(f)
+	}
+
+	buff := make([]byte, 0, size)
+	buff = append(buff, canonicalVersion, byte(len(scheme)))
+	buff = append(buff, scheme...)
+	buff = append(buff, RoundToBytes(b.Round)...)
+	for _, f := range fields {
+		var l [2]byte
+		binary.BigEndian.PutUint16(l[:], uint16(len(f)))
+		buff = append(buff, l[:]...)
+		buff = append(buff, f...)
+	}
+	return buff, nil
+}
+
+// DecodeBeacon decodes the canonical binary encoding of a beacon, returning
+// the beacon and the ID of its scheme. It rejects truncated encodings,
+// trailing bytes and unsupported versions.
+func DecodeBeacon(data []byte) (*Beacon, string, error) {
+	r := canonicalReader{data: data}
+	if v := r.byte(); v != canonicalVersion && r.err == nil {
+		return nil, "", fmt.Errorf("unsupported beacon encoding version %d", v)
+	}
+	scheme := string(r.next(int(r.byte())))
+	b := &Beacon{Round: binary.BigEndian.Uint64(pad(r.next(8)))}
+	b.Signature = r.field()
+	b.PreviousSig = r.field()
+	b.SignatureV2 = r.field()
+	if r.err != nil {
+		return nil, "", r.err
+	}
+	if len(r.data) > 0 {
+		return nil, "", fmt.Errorf("%d trailing bytes after beacon", len(r.data))
+	}
+	if scheme == "" {
+		return nil, "", errors.New("beacon without scheme")
+	}
+	return b, scheme, nil
+}
+
+// Digest returns a hash of the canonical encoding of a beacon of a chain
+// using the given scheme, stable across consumers.
+func (b *Beacon) Digest(scheme string) ([]byte, error) {
+	enc, err := EncodeBeacon(scheme, b)
+	if err != nil {
+		return nil, err
+	}
+	h := sha256.New()
+	_, _ = h.Write(digestDomain)
+	_, _ = h.Write(enc)
+	return h.Sum(nil), nil
+}
+
+// canonicalReader reads the fields of a canonical encoding, remembering the
+// first error.
+type canonicalReader struct {
+	data []byte
+	err  error
+}
+
+// next returns the next n bytes.
+func (r *canonicalReader) next(n int) []byte {
+	if r.err != nil {
+		return nil
+	}
+	if len(r.data) < n {
+		r.err = errors.New("truncated beacon encoding")
+		return nil
+	}
+	out := r.data[:n:n]
+	r.data = r.data[n:]
+	return out
+}
+
+func (r *canonicalReader) byte() byte {
+	if b := r.next(1); b != nil {
+		return b[0]
+	}
+	return 0
+}
+
+// field returns the next length prefixed field, nil when empty as when
+// encoding.
+func (r *canonicalReader) field() []byte {
+	l := r.next(2)
+	if l == nil {
+		return nil
+	}
+	f := r.next(int(binary.BigEndian.Uint16(l)))
+	if len(f) == 0 {
+		return nil
+	}
+	return append([]byte(nil), f...)
+}
+
+// pad returns 8 zero bytes for a missing round, so that decoding fails on the
+// recorded error rather than panicking.
+func pad(b []byte) []byte {
+	if len(b) < 8 {
+		return make([]byte, 8)
+	}
+	return b
+}
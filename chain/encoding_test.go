@@ -0,0 +1,54 @@
+package chain
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCanonicalEncoding(t *testing.T) {
+	b := &Beacon{
+		Round:       42,
+		Signature:   []byte("signature"),
+		PreviousSig: []byte("previous"),
+		SignatureV2: []byte("v2"),
+	}
+	enc, err := EncodeBeacon("", b)
+	require.NoError(t, err)
+	dec, scheme, err := DecodeBeacon(enc)
+	require.NoError(t, err)
+	require.Equal(t, DefaultSchemeID, scheme)
+	require.True(t, b.Equal(dec))
+
+	// the default scheme is encoded explicitly.
+	named, err := EncodeBeacon(DefaultSchemeID, b)
+	require.NoError(t, err)
+	require.Equal(t, enc, named)
+
+	d1, err := b.Digest("")
+	require.NoError(t, err)
+	d2, err := dec.Digest(scheme)
+	require.NoError(t, err)
+	require.Equal(t, d1, d2)
+	d3, err := b.Digest(UnchainedSchemeID)
+	require.NoError(t, err)
+	require.NotEqual(t, d1, d3)
+
+	unsigned := &Beacon{Round: 1}
+	enc2, err := EncodeBeacon("", unsigned)
+	require.NoError(t, err)
+	dec2, _, err := DecodeBeacon(enc2)
+	require.NoError(t, err)
+	require.Equal(t, unsigned, dec2)
+
+	_, _, err = DecodeBeacon(append(enc, 0))
+	require.Error(t, err)
+	for i := range enc {
+		_, _, err = DecodeBeacon(enc[:i])
+		require.Error(t, err)
+	}
+	bad := append([]byte{}, enc...)
+	bad[0] = 2
+	_, _, err = DecodeBeacon(bad)
+	require.Error(t, err)
+}
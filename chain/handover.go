@@ -0,0 +1,104 @@
+package chain
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+
+	json "github.com/nikkolasg/hexjson"
+
+	"github.com/drand/drand/key"
+	"github.com/drand/drand/protobuf/drand"
+)
+
+// Handover announces that a chain stops after a given round and that another
+// chain, for instance with a new genesis or scheme, takes over. It is signed
+// by the group of the chain being handed over, so that clients trusting this
+// chain can follow the successor without being reconfigured.
+type Handover struct {
+	// Round is the last round of the chain being handed over.
+	Round uint64
+	// Successor is the information of the chain taking over.
+	Successor *Info
+	// Signature is the signature of the group of the chain being handed over
+	// on the handover message.
+	Signature []byte
+}
+
+// handoverSignatureDomain separates signatures over handovers from other
+// signatures made with the same group key.
+var handoverSignatureDomain = []byte("drand-chain-handover")
+
+// SignatureMessage returns the message the group of the chain described by
+// `info` signs to hand it over. It commits to the hash of the chain, the last
+// round and the hash of the successor.
+func (h *Handover) SignatureMessage(info *Info) []byte {
+	hash := sha256.New()
+	_, _ = hash.Write(handoverSignatureDomain)
+	_, _ = hash.Write(info.Hash())
+	_ = binary.Write(hash, binary.BigEndian, h.Round)
+	_, _ = hash.Write(h.Successor.Hash())
+	return hash.Sum(nil)
+}
+
+// Verify returns an error unless the handover is signed by the group of the
+// chain described by `info`, and the successor starts after the last round.
+func (h *Handover) Verify(info *Info) error {
+	if h.Round == 0 {
+		return errors.New("handover at the genesis round")
+	}
+	if h.Successor == nil || h.Successor.PublicKey == nil {
+		return errors.New("handover without successor")
+	}
+	if key.ConstantTimeEqual(h.Successor.Hash(), info.Hash()) {
+		return errors.New("handover to the same chain")
+	}
+	if h.Successor.GenesisTime <= info.TimeOfRound(h.Round).Unix() {
+		return errors.New("successor starts before the last round")
+	}
+	s, err := SchemeFromID(info.Scheme)
+	if err != nil {
+		return err
+	}
+	return s.Suite().ThresholdScheme.VerifyRecovered(info.PublicKey, h.SignatureMessage(info), h.Signature)
+}
+
+// handoverJSON is the JSON description of a handover, with the successor
+// described as in the /info endpoint of relays.
+type handoverJSON struct {
+	Round     uint64                 `json:"round"`
+	Successor *drand.ChainInfoPacket `json:"successor"`
+	Signature []byte                 `json:"signature"`
+}
+
+// MarshalJSON implements json.Marshaler.
+func (h *Handover) MarshalJSON() ([]byte, error) {
+	if h.Successor == nil {
+		return nil, errors.New("handover without successor")
+	}
+	return json.Marshal(&handoverJSON{
+		Round:     h.Round,
+		Successor: h.Successor.ToProto(),
+		Signature: h.Signature,
+	})
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (h *Handover) UnmarshalJSON(data []byte) error {
+	var hj handoverJSON
+	if err := json.Unmarshal(data, &hj); err != nil {
+		return err
+	}
+	if hj.Successor == nil {
+		return errors.New("handover without successor")
+	}
+	successor, err := InfoFromProto(hj.Successor)
+	if err != nil {
+		return fmt.Errorf("invalid successor: %w", err)
+	}
+	h.Round = hj.Round
+	h.Successor = successor
+	h.Signature = hj.Signature
+	return nil
+}
@@ -0,0 +1,58 @@
+package chain
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/drand/drand/key"
+	"github.com/drand/kyber/util/random"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandover(t *testing.T) {
+	priv := key.KeyGroup.Scalar().Pick(random.New())
+	info := &Info{
+		PublicKey:   key.KeyGroup.Point().Mul(priv, nil),
+		Period:      time.Second,
+		GenesisTime: 1000,
+		GroupHash:   []byte("group"),
+	}
+	successor := &Info{
+		PublicKey:   key.KeyGroup.Point().Pick(random.New()),
+		Period:      3 * time.Second,
+		GenesisTime: 2000,
+		GroupHash:   []byte("successor"),
+	}
+	h := &Handover{Round: 900, Successor: successor}
+	sig, err := key.AuthScheme.Sign(priv, h.SignatureMessage(info))
+	require.NoError(t, err)
+	h.Signature = sig
+	require.NoError(t, h.Verify(info))
+
+	// the handover is carried along with the chain info, without changing
+	// its hash.
+	hash := info.Hash()
+	info.Handover = h
+	require.Equal(t, hash, info.Hash())
+	var buff bytes.Buffer
+	require.NoError(t, info.ToJSON(&buff))
+	read, err := InfoFromJSON(&buff)
+	require.NoError(t, err)
+	require.NotNil(t, read.Handover)
+	require.Equal(t, h.Round, read.Handover.Round)
+	require.True(t, successor.Equal(read.Handover.Successor))
+	require.NoError(t, read.Handover.Verify(info))
+
+	late := *h
+	late.Round = 1001
+	require.Error(t, late.Verify(info))
+
+	forged := *h
+	forged.Round--
+	require.Error(t, forged.Verify(info))
+
+	self := *h
+	self.Successor = info
+	require.Error(t, self.Verify(info))
+}
@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"crypto/sha256"
 	"encoding/binary"
+	"io"
 	"time"
 
 	"github.com/drand/drand/key"
@@ -11,28 +12,58 @@ import (
 	"github.com/drand/kyber"
 )
 
+// InfoVersion is the latest version of the chain info format. Version 1 only
+// holds the public key, period, genesis time and group hash, while version 2
+// also describes the scheme, beacon ID, signature transition, randomness
+// derivation and period changes of the chain.
+const InfoVersion = 2
+
 // Info represents the public information that is necessary for a client to
 // very any beacon present in a randomness chain.
 type Info struct {
-	PublicKey   kyber.Point   `json:"public_key"`
+	PublicKey kyber.Point `json:"public_key"`
+	// Period is the period of the chain at its genesis, see Epochs.
 	Period      time.Duration `json:"period"`
 	GenesisTime int64         `json:"genesis_time"`
 	GroupHash   []byte        `json:"group_hash"`
+	// Scheme is the ID of the scheme of the chain, empty for the default
+	// scheme. It is part of the hash of chains using another scheme.
+	Scheme string `json:"scheme,omitempty"`
+	// BeaconID identifies the chain among the chains run by the same network,
+	// empty for the default chain. It is part of the hash when set.
+	BeaconID string `json:"beaconID,omitempty"`
+	// V2From is the first round verified with the signature over the round
+	// only, 0 when unknown. It is part of the hash when set.
+	V2From uint64 `json:"v2From,omitempty"`
+	// Derivation is the ID of the derivation of the randomness of the chain,
+	// empty for the default derivation. It is part of the hash when set.
+	Derivation string `json:"derivation,omitempty"`
+	// Handover, when set, announces the chain taking over this one. It is not
+	// part of the hash of the chain.
+	Handover *Handover `json:"handover,omitempty"`
+	// Epochs, when set, are the periods of the chain since its genesis, whose
+	// period changed with a resharing. They are part of the hash when set, so
+	// that a pinned chain hash authenticates the schedule. See Schedule.
+	Epochs []*key.Epoch `json:"epochs,omitempty"`
 }
 
 // NewChainInfo makes a chain Info from a group
 func NewChainInfo(g *key.Group) *Info {
 	return &Info{
-		Period:      g.Period,
+		Period:      g.GenesisPeriod(),
 		PublicKey:   g.PublicKey.Key(),
 		GenesisTime: g.GenesisTime,
 		GroupHash:   g.GetGenesisSeed(),
+		Scheme:      g.Scheme,
+		Epochs:      g.Epochs,
+		Derivation:  g.Derivation,
 	}
 }
 
 // Hash returns the canonical hash representing the chain information. A hash is
 // consistent throughout the entirety of a chain, regardless of the network
-// composition, the actual nodes, generating the randomness.
+// composition, the actual nodes, generating the randomness. Only a change of
+// period changes it, since the new schedule must be authenticated as well.
 func (c *Info) Hash() []byte {
 	h := sha256.New()
 	_ = binary.Write(h, binary.BigEndian, uint32(c.Period.Seconds()))
@@ -43,13 +74,101 @@ func (c *Info) Hash() []byte {
 	}
 	_, _ = h.Write(buff)
 	_, _ = h.Write(c.GroupHash)
+	// the fields of version 2 are only hashed when they are set, so that the
+	// hash of existing chains does not change.
+	if c.Scheme != "" && c.Scheme != DefaultSchemeID {
+		hashField(h, 's', []byte(c.Scheme))
+	}
+	if c.BeaconID != "" {
+		hashField(h, 'b', []byte(c.BeaconID))
+	}
+	if c.V2From != 0 {
+		hashField(h, 'v', RoundToBytes(c.V2From))
+	}
+	if c.Derivation != "" && c.Derivation != DefaultDerivationID {
+		hashField(h, 'd', []byte(c.Derivation))
+	}
+	if len(c.Epochs) > 0 {
+		hashField(h, 'e', epochsBytes(c.Epochs))
+	}
 	return h.Sum(nil)
 }
 
+// epochsBytes returns the canonical encoding of epochs in the hash.
+func epochsBytes(epochs []*key.Epoch) []byte {
+	var buff bytes.Buffer
+	for _, e := range epochs {
+		_ = binary.Write(&buff, binary.BigEndian, e.Round)
+		_ = binary.Write(&buff, binary.BigEndian, e.Time)
+		_ = binary.Write(&buff, binary.BigEndian, uint32(e.Period.Seconds()))
+	}
+	return buff.Bytes()
+}
+
+// hashField writes a tagged and length prefixed field to a hash.
+func hashField(h io.Writer, tag byte, value []byte) {
+	_, _ = h.Write([]byte{tag})
+	_ = binary.Write(h, binary.BigEndian, uint32(len(value)))
+	_, _ = h.Write(value)
+}
+
+// Version returns the version of the format needed to describe the info.
+func (c *Info) Version() int {
+	if c.Scheme != "" || c.BeaconID != "" || c.V2From != 0 || c.Derivation != "" || len(c.Epochs) > 0 {
+		return InfoVersion
+	}
+	return 1
+}
+
 // Equal indicates if two Chain Info objects are equivalent
 func (c *Info) Equal(c2 *Info) bool {
 	return c.GenesisTime == c2.GenesisTime &&
 		c.Period == c2.Period &&
 		c.PublicKey.Equal(c2.PublicKey) &&
-		bytes.Equal(c.GroupHash, c2.GroupHash)
+		key.ConstantTimeEqual(c.GroupHash, c2.GroupHash) &&
+		c.SchemeID() == c2.SchemeID() &&
+		c.BeaconID == c2.BeaconID &&
+		c.V2From == c2.V2From &&
+		c.derivationID() == c2.derivationID() &&
+		key.EqualEpochs(c.Epochs, c2.Epochs)
+}
+
+// derivationID returns the ID of the derivation of the chain.
+func (c *Info) derivationID() string {
+	if c.Derivation == "" {
+		return DefaultDerivationID
+	}
+	return c.Derivation
+}
+
+// Randomness returns the randomness of the round with the given signature,
+// derived as specified by the chain.
+func (c *Info) Randomness(sig []byte) ([]byte, error) {
+	d, err := DerivationFromID(c.Derivation)
+	if err != nil {
+		return nil, err
+	}
+	return d.Randomness(sig), nil
+}
+
+// SchemeID returns the ID of the scheme of the chain.
+func (c *Info) SchemeID() string {
+	if c.Scheme == "" {
+		return DefaultSchemeID
+	}
+	return c.Scheme
+}
+
+// VerifyBeacon returns an error unless the beacon is valid according to the
+// scheme of the chain. Rounds of the default scheme from V2From on are
+// verified with their signature over the round only.
+func (c *Info) VerifyBeacon(b *Beacon) error {
+	s, err := SchemeFromID(c.Scheme)
+	if err != nil {
+		return err
+	}
+	if s.ID() == DefaultSchemeID && c.V2From != 0 && b.Round >= c.V2From {
+		return VerifyBeaconV2(c.PublicKey, b)
+	}
+	return s.Verify(c.PublicKey, b)
 }
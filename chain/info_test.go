@@ -3,10 +3,14 @@ package chain
 import (
 	"bytes"
 	"testing"
+	"time"
 
 	"github.com/drand/drand/key"
 	"github.com/drand/drand/test"
+	"github.com/drand/kyber/util/random"
 	"github.com/stretchr/testify/require"
+
+	json "github.com/nikkolasg/hexjson"
 )
 
 func TestChainInfo(t *testing.T) {
@@ -54,3 +58,132 @@ func TestChainInfo(t *testing.T) {
 	require.NotNil(t, c13)
 	require.Equal(t, c1, c13)
 }
+
+func TestChainInfoV2(t *testing.T) {
+	priv := key.KeyGroup.Scalar().Pick(random.New())
+	v1 := &Info{
+		PublicKey:   key.KeyGroup.Point().Mul(priv, nil),
+		Period:      time.Second,
+		GenesisTime: 1000,
+		GroupHash:   []byte("group"),
+	}
+	require.Equal(t, 1, v1.Version())
+
+	// version 1 descriptions are still read, and written as before.
+	var v1Buff bytes.Buffer
+	require.NoError(t, json.NewEncoder(&v1Buff).Encode(v1.ToProto()))
+	var buff bytes.Buffer
+	require.NoError(t, v1.ToJSON(&buff))
+	require.Equal(t, v1Buff.Bytes(), buff.Bytes())
+	read, err := InfoFromJSON(&v1Buff)
+	require.NoError(t, err)
+	require.Equal(t, v1, read)
+
+	v2 := *v1
+	v2.BeaconID = "fast"
+	v2.V2From = 100
+	require.Equal(t, InfoVersion, v2.Version())
+	require.NotEqual(t, v1.Hash(), v2.Hash())
+	require.False(t, v1.Equal(&v2))
+
+	buff.Reset()
+	require.NoError(t, v2.ToJSON(&buff))
+	read, err = InfoFromJSON(&buff)
+	require.NoError(t, err)
+	require.Equal(t, &v2, read)
+
+	// rounds from V2From on are verified with their v2 signature.
+	sigV2, err := key.AuthScheme.Sign(priv, MessageV2(100))
+	require.NoError(t, err)
+	require.NoError(t, v2.VerifyBeacon(&Beacon{Round: 100, SignatureV2: sigV2}))
+	require.Error(t, v1.VerifyBeacon(&Beacon{Round: 100, SignatureV2: sigV2}))
+
+	_, err = InfoFromJSON(bytes.NewBufferString(`{"version": 3}`))
+	require.Error(t, err)
+}
+
+func TestChainInfoProtoRoundTrip(t *testing.T) {
+	priv := key.KeyGroup.Scalar().Pick(random.New())
+	info := &Info{
+		PublicKey:   key.KeyGroup.Point().Mul(priv, nil),
+		Period:      10 * time.Second,
+		GenesisTime: 1000,
+		GroupHash:   []byte("group"),
+		BeaconID:    "fast",
+		V2From:      100,
+		Derivation:  SHA3DerivationID,
+		Epochs: []*key.Epoch{
+			{Round: 1, Time: 1000, Period: 10 * time.Second},
+			{Round: 11, Time: 1100, Period: 3 * time.Second},
+		},
+	}
+	// the info fetched over gRPC hashes like the info served over HTTP
+	read, err := InfoFromProto(info.ToProto())
+	require.NoError(t, err)
+	require.Equal(t, info.Hash(), read.Hash())
+	require.Equal(t, info.ToProto().GetHash(), read.Hash())
+	require.True(t, info.Equal(read))
+
+	// the JSON description uses the camelCase names of the wire format
+	var buff bytes.Buffer
+	require.NoError(t, info.ToJSON(&buff))
+	require.Contains(t, buff.String(), `"beaconID":"fast"`)
+	require.Contains(t, buff.String(), `"v2From":100`)
+	read, err = InfoFromJSON(&buff)
+	require.NoError(t, err)
+	require.Equal(t, info.Hash(), read.Hash())
+}
+
+func TestChainInfoEpochs(t *testing.T) {
+	info := &Info{
+		PublicKey:   key.KeyGroup.Point().Pick(random.New()),
+		Period:      10 * time.Second,
+		GenesisTime: 1000,
+		GroupHash:   []byte("group"),
+	}
+	hash := info.Hash()
+	require.Equal(t, time.Unix(1090, 0), info.TimeOfRound(10))
+
+	// the period changes at round 11, which changes the hash of the chain so
+	// that the schedule can't be forged under a pinned hash
+	before := *info
+	info.Epochs = []*key.Epoch{
+		{Round: 1, Time: 1000, Period: 10 * time.Second},
+		{Round: 11, Time: 1100, Period: 3 * time.Second},
+	}
+	require.NotEqual(t, hash, info.Hash())
+	require.False(t, info.Equal(&before))
+	forged := *info
+	forged.Epochs = []*key.Epoch{
+		{Round: 1, Time: 1000, Period: 10 * time.Second},
+		{Round: 11, Time: 1100, Period: time.Second},
+	}
+	require.NotEqual(t, info.Hash(), forged.Hash())
+	require.False(t, info.Equal(&forged))
+	require.Equal(t, InfoVersion, info.Version())
+	require.Equal(t, time.Unix(1103, 0), info.TimeOfRound(12))
+	require.Equal(t, uint64(12), info.RoundAt(time.Unix(1104, 0)))
+	require.Equal(t, 3*time.Second, info.PeriodAt(12))
+	require.Equal(t, 10*time.Second, info.PeriodAt(10))
+
+	var buff bytes.Buffer
+	require.NoError(t, info.ToJSON(&buff))
+	read, err := InfoFromJSON(&buff)
+	require.NoError(t, err)
+	require.Equal(t, info.Epochs, read.Epochs)
+	require.Equal(t, info.TimeOfRound(12), read.TimeOfRound(12))
+
+	read, err = InfoFromProto(info.ToProto())
+	require.NoError(t, err)
+	require.Equal(t, info.Epochs, read.Epochs)
+	require.Equal(t, info.RoundAt(time.Unix(1104, 0)), read.RoundAt(time.Unix(1104, 0)))
+
+	// the first epoch must be the genesis of the chain
+	info.Epochs[0].Period = 5 * time.Second
+	buff.Reset()
+	require.NoError(t, info.ToJSON(&buff))
+	_, err = InfoFromJSON(&buff)
+	require.Error(t, err)
+	_, err = InfoFromProto(info.ToProto())
+	require.Error(t, err)
+}
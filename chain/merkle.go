@@ -0,0 +1,206 @@
+package chain
+
+import (
+	"crypto/sha256"
+	"errors"
+	"fmt"
+
+	"github.com/drand/drand/key"
+)
+
+// CommitmentSpan is the number of rounds covered by a commitment. Commitments
+// cover rounds 1 to CommitmentSpan, then CommitmentSpan+1 to 2*CommitmentSpan
+// and so on.
+const CommitmentSpan = 1024
+
+// Prefixes separating the hashes of leaves, inner nodes and roots, so that an
+// inner node can not be passed off as a beacon.
+const (
+	leafPrefix  = 0x00
+	innerPrefix = 0x01
+	rootPrefix  = 0x02
+)
+
+// Commitment is the root of a Merkle tree over the beacons of a range of
+// rounds, letting light consumers verify that an old round belongs to the
+// history of the chain with an inclusion proof, without walking the chain.
+type Commitment struct {
+	// From and To are the first and last rounds covered, included.
+	From uint64 `json:"from"`
+	To   uint64 `json:"to"`
+	// Root is the root of the Merkle tree over the beacons of the range.
+	Root []byte `json:"root"`
+}
+
+// InclusionProof proves that a beacon is part of the range of a commitment.
+type InclusionProof struct {
+	// Round is the round of the beacon.
+	Round uint64 `json:"round"`
+	// From and To are the rounds covered by the commitment.
+	From uint64 `json:"from"`
+	To   uint64 `json:"to"`
+	// Path holds the hashes of the siblings of the nodes on the way from the
+	// beacon to the root, starting at the beacon.
+	Path [][]byte `json:"path"`
+}
+
+// CommitmentRange returns the rounds covered by the commitment including
+// the given round.
+func CommitmentRange(round uint64) (from, to uint64) {
+	if round == 0 {
+		return 0, 0
+	}
+	from = (round-1)/CommitmentSpan*CommitmentSpan + 1
+	return from, from + CommitmentSpan - 1
+}
+
+// leafHash returns the hash of a beacon in a Merkle tree.
+func leafHash(b *Beacon) []byte {
+	h := sha256.New()
+	_, _ = h.Write([]byte{leafPrefix})
+	_, _ = h.Write(RoundToBytes(b.Round))
+	_, _ = h.Write(b.Signature)
+	return h.Sum(nil)
+}
+
+// innerHash returns the hash of an inner node of a Merkle tree.
+func innerHash(left, right []byte) []byte {
+	h := sha256.New()
+	_, _ = h.Write([]byte{innerPrefix})
+	_, _ = h.Write(left)
+	_, _ = h.Write(right)
+	return h.Sum(nil)
+}
+
+// rootHash returns the root of a commitment, binding the top of the tree to
+// the range of rounds it covers.
+func rootHash(from, to uint64, top []byte) []byte {
+	h := sha256.New()
+	_, _ = h.Write([]byte{rootPrefix})
+	_, _ = h.Write(RoundToBytes(from))
+	_, _ = h.Write(RoundToBytes(to))
+	_, _ = h.Write(top)
+	return h.Sum(nil)
+}
+
+// MerkleTree is the Merkle tree over the beacons of a range of consecutive
+// rounds. The last node of a level with an odd number of nodes is promoted to
+// the next level as is.
+type MerkleTree struct {
+	from uint64
+	// levels holds the hashes of each level, from the leaves to the root.
+	levels [][][]byte
+}
+
+// NewMerkleTree builds the Merkle tree over beacons of consecutive rounds.
+func NewMerkleTree(beacons []*Beacon) (*MerkleTree, error) {
+	if len(beacons) == 0 {
+		return nil, errors.New("no beacon to commit to")
+	}
+	leaves := make([][]byte, len(beacons))
+	for i, b := range beacons {
+		if b.Round != beacons[0].Round+uint64(i) {
+			return nil, fmt.Errorf("round %d is not consecutive to round %d", b.Round, beacons[0].Round)
+		}
+		leaves[i] = leafHash(b)
+	}
+	t := &MerkleTree{from: beacons[0].Round, levels: [][][]byte{leaves}}
+	for level := leaves; len(level) > 1; {
+		next := make([][]byte, 0, (len(level)+1)/2)
+		for i := 0; i < len(level); i += 2 {
+			if i+1 == len(level) {
+				next = append(next, level[i])
+				continue
+			}
+			next = append(next, innerHash(level[i], level[i+1]))
+		}
+		t.levels = append(t.levels, next)
+		level = next
+	}
+	return t, nil
+}
+
+// Commitment returns the commitment to the beacons of the tree.
+func (t *MerkleTree) Commitment() *Commitment {
+	to := t.from + uint64(len(t.levels[0])) - 1
+	return &Commitment{
+		From: t.from,
+		To:   to,
+		Root: rootHash(t.from, to, t.levels[len(t.levels)-1][0]),
+	}
+}
+
+// Prove returns the proof that the beacon of the given round is part of the
+// tree.
+func (t *MerkleTree) Prove(round uint64) (*InclusionProof, error) {
+	c := t.Commitment()
+	if round < c.From || round > c.To {
+		return nil, fmt.Errorf("round %d is not between %d and %d", round, c.From, c.To)
+	}
+	proof := &InclusionProof{Round: round, From: c.From, To: c.To}
+	idx := int(round - c.From)
+	for _, level := range t.levels[:len(t.levels)-1] {
+		sibling := idx ^ 1
+		if sibling < len(level) {
+			proof.Path = append(proof.Path, level[sibling])
+		}
+		idx /= 2
+	}
+	return proof, nil
+}
+
+// MerkleTreeFromStore builds the Merkle tree over the beacons of the store
+// between rounds `from` and `to` included, for nodes to commit to their
+// history.
+func MerkleTreeFromStore(s Store, from, to uint64) (*MerkleTree, error) {
+	if to < from {
+		return nil, errors.New("empty range")
+	}
+	beacons := make([]*Beacon, 0, to-from+1)
+	for round := from; round <= to; round++ {
+		b, err := s.Get(round)
+		if err != nil {
+			return nil, fmt.Errorf("loading round %d: %w", round, err)
+		}
+		beacons = append(beacons, b)
+	}
+	return NewMerkleTree(beacons)
+}
+
+// VerifyInclusion returns an error unless the proof shows that the beacon is
+// part of the beacons committed to by `root`. The beacon itself should be
+// verified against the chain, or the root be trusted, for the proof to mean
+// the beacon is part of the history of the chain.
+func VerifyInclusion(root []byte, proof *InclusionProof, b *Beacon) error {
+	if b.Round != proof.Round {
+		return fmt.Errorf("proof of round %d for round %d", proof.Round, b.Round)
+	}
+	if proof.Round < proof.From || proof.Round > proof.To {
+		return fmt.Errorf("round %d is not between %d and %d", proof.Round, proof.From, proof.To)
+	}
+	hash := leafHash(b)
+	idx := proof.Round - proof.From
+	size := proof.To - proof.From + 1
+	path := proof.Path
+	for ; size > 1; size = (size + 1) / 2 {
+		switch {
+		case idx^1 >= size:
+			// the last node of a level with an odd number of nodes is
+			// promoted as is.
+		case len(path) == 0:
+			return errors.New("proof too short")
+		case idx%2 == 0:
+			hash, path = innerHash(hash, path[0]), path[1:]
+		default:
+			hash, path = innerHash(path[0], hash), path[1:]
+		}
+		idx /= 2
+	}
+	if len(path) != 0 {
+		return errors.New("proof too long")
+	}
+	if !key.ConstantTimeEqual(rootHash(proof.From, proof.To, hash), root) {
+		return errors.New("beacon not included in the commitment")
+	}
+	return nil
+}
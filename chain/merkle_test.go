@@ -0,0 +1,62 @@
+package chain
+
+import (
+	"crypto/sha256"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func fakeBeacons(from, to uint64) []*Beacon {
+	beacons := make([]*Beacon, 0, to-from+1)
+	for round := from; round <= to; round++ {
+		sig := sha256.Sum256(RoundToBytes(round))
+		beacons = append(beacons, &Beacon{Round: round, Signature: sig[:]})
+	}
+	return beacons
+}
+
+func TestMerkleInclusion(t *testing.T) {
+	// odd sizes exercise the promotion of the last node of a level.
+	for _, size := range []uint64{1, 2, 7, 64, 100} {
+		beacons := fakeBeacons(11, 10+size)
+		tree, err := NewMerkleTree(beacons)
+		require.NoError(t, err)
+		c := tree.Commitment()
+		require.Equal(t, uint64(11), c.From)
+		require.Equal(t, 10+size, c.To)
+
+		for _, b := range beacons {
+			proof, err := tree.Prove(b.Round)
+			require.NoError(t, err)
+			require.NoError(t, VerifyInclusion(c.Root, proof, b))
+
+			forged := *b
+			forged.Signature = []byte("forged")
+			require.Error(t, VerifyInclusion(c.Root, proof, &forged))
+
+			if len(proof.Path) > 0 {
+				short := *proof
+				short.Path = short.Path[1:]
+				require.Error(t, VerifyInclusion(c.Root, &short, b))
+			}
+			shifted := *proof
+			shifted.From--
+			require.Error(t, VerifyInclusion(c.Root, &shifted, b))
+		}
+		_, err = tree.Prove(c.To + 1)
+		require.Error(t, err)
+	}
+
+	_, err := NewMerkleTree(append(fakeBeacons(1, 3), fakeBeacons(5, 5)...))
+	require.Error(t, err)
+}
+
+func TestCommitmentRange(t *testing.T) {
+	from, to := CommitmentRange(1)
+	require.Equal(t, uint64(1), from)
+	require.Equal(t, uint64(CommitmentSpan), to)
+	from, to = CommitmentRange(CommitmentSpan + 1)
+	require.Equal(t, uint64(CommitmentSpan+1), from)
+	require.Equal(t, uint64(2*CommitmentSpan), to)
+}
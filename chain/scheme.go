@@ -0,0 +1,167 @@
+package chain
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+
+	bls "github.com/drand/kyber-bls12381"
+
+	"github.com/drand/drand/key"
+	"github.com/drand/kyber"
+)
+
+const (
+	// DefaultSchemeID identifies the scheme of chains whose info does not
+	// name one: each round signs the signature of the previous round.
+	DefaultSchemeID = "pedersen-bls-chained"
+	// UnchainedSchemeID identifies the scheme where each round only signs its
+	// round number, in the second signature of beacons.
+	UnchainedSchemeID = "pedersen-bls-unchained"
+	// UnchainedOnG1SchemeID identifies the unchained scheme with the groups
+	// swapped: the keys are on G2 and the shorter signatures on G1. Messages
+	// are hashed to G1 with the tag of the G2 ciphersuite, so the signatures
+	// do not verify with the standard G1 ciphersuite of BLS.
+	UnchainedOnG1SchemeID = "bls-unchained-on-g1"
+	// BN254UnchainedOnG1SchemeID identifies the unchained scheme on BN254,
+	// with the keys on G2 and the signatures on G1. Messages are hashed to G1
+	// by try-and-increment, without tag, so the signatures only verify with
+	// kyber.
+	BN254UnchainedOnG1SchemeID = "bls-bn254-unchained-on-g1"
+)
+
+// Scheme describes how the beacons of a chain are signed, so that beacons can
+// be verified without knowing the curve or the flavor of the chain.
+type Scheme interface {
+	// ID identifies the scheme in chain info.
+	ID() string
+	// DomainSeparationTag is the tag used to hash messages to the signature
+	// group, nil when the hash has none.
+	DomainSeparationTag() []byte
+	// KeyGroup is the group of the public key of the chain.
+	KeyGroup() kyber.Group
+	// SigGroup is the group of the signatures of the beacons.
+	SigGroup() kyber.Group
+	// Suite is the suite of the keys and signatures of the nodes running the
+	// chain.
+	Suite() *key.Suite
+	// Chained indicates that each round signs the previous signature.
+	Chained() bool
+	// Digest returns the message signed at the given round.
+	Digest(round uint64, previousSig []byte) []byte
+	// Verify returns an error unless the beacon is signed by the public key.
+	Verify(pubkey kyber.Point, b *Beacon) error
+}
+
+var (
+	schemesLk sync.RWMutex
+	schemes   = map[string]Scheme{}
+)
+
+func init() {
+	for _, s := range []Scheme{
+		&blsScheme{id: DefaultSchemeID, suite: key.DefaultSuite, dst: bls.Domain, chained: true},
+		&blsScheme{id: UnchainedSchemeID, suite: key.DefaultSuite, dst: bls.Domain},
+		// kyber-bls12381 hashes to G1 with the tag of G2, not with
+		// BLS_SIG_BLS12381G1_XMD:SHA-256_SSWU_RO_NUL_
+		&blsScheme{id: UnchainedOnG1SchemeID, suite: key.G1SigSuite, dst: bls.Domain},
+		// kyber hashes to BN254 by try-and-increment, without tag
+		&blsScheme{id: BN254UnchainedOnG1SchemeID, suite: key.BN254Suite},
+	} {
+		if err := RegisterScheme(s); err != nil {
+			panic(err)
+		}
+	}
+}
+
+// RegisterScheme makes a scheme available to the chains naming it in their
+// info, and to the keys generated for it. It returns an error if a scheme with
+// the same ID is registered.
+func RegisterScheme(s Scheme) error {
+	schemesLk.Lock()
+	defer schemesLk.Unlock()
+	if s.ID() == "" {
+		return errors.New("scheme without ID")
+	}
+	if _, ok := schemes[s.ID()]; ok {
+		return fmt.Errorf("scheme %s already registered", s.ID())
+	}
+	schemes[s.ID()] = s
+	key.RegisterSuite(s.ID(), s.Suite())
+	return nil
+}
+
+// SchemeFromID returns the registered scheme with the given ID, the empty ID
+// designating the default scheme.
+func SchemeFromID(id string) (Scheme, error) {
+	if id == "" {
+		id = DefaultSchemeID
+	}
+	schemesLk.RLock()
+	defer schemesLk.RUnlock()
+	s, ok := schemes[id]
+	if !ok {
+		return nil, fmt.Errorf("unknown scheme %q", id)
+	}
+	return s, nil
+}
+
+// SchemeIDs returns the IDs of the registered schemes, sorted.
+func SchemeIDs() []string {
+	schemesLk.RLock()
+	defer schemesLk.RUnlock()
+	ids := make([]string, 0, len(schemes))
+	for id := range schemes {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// blsScheme is a BLS scheme of drand, signing the beacons with the threshold
+// scheme of its suite.
+type blsScheme struct {
+	id      string
+	suite   *key.Suite
+	dst     []byte
+	chained bool
+}
+
+func (s *blsScheme) ID() string {
+	return s.id
+}
+
+func (s *blsScheme) DomainSeparationTag() []byte {
+	return s.dst
+}
+
+func (s *blsScheme) KeyGroup() kyber.Group {
+	return s.suite.KeyGroup
+}
+
+func (s *blsScheme) SigGroup() kyber.Group {
+	return s.suite.SigGroup
+}
+
+func (s *blsScheme) Suite() *key.Suite {
+	return s.suite
+}
+
+func (s *blsScheme) Chained() bool {
+	return s.chained
+}
+
+func (s *blsScheme) Digest(round uint64, previousSig []byte) []byte {
+	if s.chained {
+		return Message(round, previousSig)
+	}
+	return MessageV2(round)
+}
+
+func (s *blsScheme) Verify(pubkey kyber.Point, b *Beacon) error {
+	if s.chained {
+		return s.suite.ThresholdScheme.VerifyRecovered(pubkey, Message(b.Round, b.PreviousSig), b.Signature)
+	}
+	return s.suite.ThresholdScheme.VerifyRecovered(pubkey, MessageV2(b.Round), b.SignatureV2)
+}
@@ -0,0 +1,121 @@
+package chain
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"testing"
+	"time"
+
+	"github.com/drand/drand/key"
+	"github.com/drand/kyber/share"
+	"github.com/drand/kyber/util/random"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSchemeRegistry(t *testing.T) {
+	s, err := SchemeFromID("")
+	require.NoError(t, err)
+	require.Equal(t, DefaultSchemeID, s.ID())
+	require.True(t, s.Chained())
+
+	_, err = SchemeFromID("unknown")
+	require.Error(t, err)
+
+	require.Error(t, RegisterScheme(s))
+	require.Contains(t, SchemeIDs(), UnchainedSchemeID)
+}
+
+func TestInfoScheme(t *testing.T) {
+	priv := key.KeyGroup.Scalar().Pick(random.New())
+	info := &Info{
+		PublicKey:   key.KeyGroup.Point().Mul(priv, nil),
+		Period:      time.Second,
+		GenesisTime: 1000,
+		GroupHash:   []byte("group"),
+	}
+	prev := sha256.Sum256([]byte("previous"))
+	sig, err := key.AuthScheme.Sign(priv, Message(7, prev[:]))
+	require.NoError(t, err)
+	sigV2, err := key.AuthScheme.Sign(priv, MessageV2(7))
+	require.NoError(t, err)
+	b := &Beacon{Round: 7, Signature: sig, PreviousSig: prev[:], SignatureV2: sigV2}
+	require.NoError(t, info.VerifyBeacon(b))
+
+	// naming the default scheme does not change the chain.
+	hash := info.Hash()
+	named := *info
+	named.Scheme = DefaultSchemeID
+	require.Equal(t, hash, named.Hash())
+	require.True(t, info.Equal(&named))
+
+	unchained := *info
+	unchained.Scheme = UnchainedSchemeID
+	require.NotEqual(t, hash, unchained.Hash())
+	require.False(t, info.Equal(&unchained))
+	require.NoError(t, unchained.VerifyBeacon(&Beacon{Round: 7, SignatureV2: sigV2}))
+	require.Error(t, unchained.VerifyBeacon(&Beacon{Round: 8, SignatureV2: sigV2}))
+
+	var buff bytes.Buffer
+	require.NoError(t, unchained.ToJSON(&buff))
+	require.Contains(t, buff.String(), `"scheme":"`+UnchainedSchemeID+`"`)
+	read, err := InfoFromJSON(&buff)
+	require.NoError(t, err)
+	require.Equal(t, UnchainedSchemeID, read.Scheme)
+	require.Equal(t, unchained.Hash(), read.Hash())
+
+	fromProto, err := InfoFromProto(unchained.ToProto())
+	require.NoError(t, err)
+	require.Equal(t, UnchainedSchemeID, fromProto.Scheme)
+	require.Equal(t, unchained.Hash(), fromProto.Hash())
+
+	unknown := *info
+	unknown.Scheme = "unknown"
+	require.Error(t, unknown.VerifyBeacon(b))
+}
+
+func TestSchemesSignAndVerify(t *testing.T) {
+	for _, id := range SchemeIDs() {
+		s, err := SchemeFromID(id)
+		require.NoError(t, err)
+		suite := s.Suite()
+		n, thr := 3, 2
+		poly := share.NewPriPoly(suite.KeyGroup, thr, nil, random.New())
+		pub := poly.Commit(suite.KeyGroup.Point().Base())
+		info := &Info{
+			PublicKey:   pub.Commit(),
+			Period:      time.Second,
+			GenesisTime: 1000,
+			GroupHash:   []byte("group"),
+			Scheme:      id,
+		}
+
+		prev := sha256.Sum256([]byte("previous"))
+		sign := func(msg []byte) []byte {
+			var partials [][]byte
+			for _, sh := range poly.Shares(n) {
+				p, err := suite.ThresholdScheme.Sign(sh, msg)
+				require.NoError(t, err)
+				partials = append(partials, p)
+			}
+			sig, err := suite.ThresholdScheme.Recover(pub, msg, partials, thr, n)
+			require.NoError(t, err)
+			return sig
+		}
+		b := &Beacon{
+			Round:       7,
+			PreviousSig: prev[:],
+			Signature:   sign(Message(7, prev[:])),
+			SignatureV2: sign(MessageV2(7)),
+		}
+		require.NoError(t, info.VerifyBeacon(b), id)
+		require.Error(t, info.VerifyBeacon(&Beacon{Round: 8, PreviousSig: prev[:], Signature: b.Signature, SignatureV2: b.SignatureV2}), id)
+
+		cp, err := NewCheckpoint(info, b)
+		require.NoError(t, err, id)
+		require.NoError(t, cp.Verify(info), id)
+
+		read, err := InfoFromProto(info.ToProto())
+		require.NoError(t, err, id)
+		require.NoError(t, read.VerifyBeacon(b), id)
+	}
+}
@@ -0,0 +1,250 @@
+package chain
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"time"
+
+	"github.com/drand/drand/key"
+	json "github.com/nikkolasg/hexjson"
+)
+
+// A snapshot is a portable copy of a range of beacons of a chain, letting a
+// new node, relay or verifier bootstrap without syncing the chain from the
+// network. It is made of:
+//   - the snapshotMagic bytes,
+//   - the SnapshotHeader in JSON, prefixed by its length,
+//   - the beacons in increasing round order, each prefixed by its length,
+//   - a zero length, followed by the number of beacons,
+//   - the SHA-256 checksum of all of the above.
+// Lengths are 32 bits and the number of beacons 64 bits, in big endian.
+
+// SnapshotVersion is the version of the snapshots written by ExportSnapshot.
+const SnapshotVersion = 1
+
+var snapshotMagic = []byte("drand-snapshot\n")
+
+const (
+	maxSnapshotHeader = 1 << 20
+	maxSnapshotBeacon = 1 << 16
+)
+
+// SnapshotHeader describes the content of a snapshot.
+type SnapshotHeader struct {
+	Version int `json:"version"`
+	// Created is the unix time at which the snapshot was exported.
+	Created int64 `json:"created"`
+	// Info is the chain information, as served by the nodes.
+	Info json.RawMessage `json:"info"`
+}
+
+// ExportSnapshot writes the beacons of the store from round `from` up to round
+// `to` included, 0 meaning the last stored round, as a snapshot of the chain.
+// It returns the number of beacons written.
+func ExportSnapshot(w io.Writer, info *Info, s Store, from, to uint64) (int, error) {
+	var infoJSON bytes.Buffer
+	if err := info.ToJSON(&infoJSON); err != nil {
+		return 0, err
+	}
+	header, err := json.Marshal(&SnapshotHeader{
+		Version: SnapshotVersion,
+		Created: time.Now().Unix(),
+		Info:    infoJSON.Bytes(),
+	})
+	if err != nil {
+		return 0, err
+	}
+	bw := bufio.NewWriter(w)
+	h := sha256.New()
+	hw := io.MultiWriter(bw, h)
+	if _, err := hw.Write(snapshotMagic); err != nil {
+		return 0, err
+	}
+	if err := writeRecord(hw, header); err != nil {
+		return 0, err
+	}
+	var count int
+	s.Cursor(func(c Cursor) {
+		for b := c.Seek(from); b != nil && (to == 0 || b.Round <= to); b = c.Next() {
+			var buff []byte
+			if buff, err = b.Marshal(); err != nil {
+				return
+			}
+			if err = writeRecord(hw, buff); err != nil {
+				return
+			}
+			count++
+		}
+	})
+	if err != nil {
+		return count, err
+	}
+	// the end marker and the count
+	if err := writeRecord(hw, nil); err != nil {
+		return count, err
+	}
+	if err := binary.Write(hw, binary.BigEndian, uint64(count)); err != nil {
+		return count, err
+	}
+	if _, err := bw.Write(h.Sum(nil)); err != nil {
+		return count, err
+	}
+	return count, bw.Flush()
+}
+
+func writeRecord(w io.Writer, buff []byte) error {
+	if err := binary.Write(w, binary.BigEndian, uint32(len(buff))); err != nil {
+		return err
+	}
+	_, err := w.Write(buff)
+	return err
+}
+
+// SnapshotReader reads the beacons of a snapshot. It implements
+// BeaconIterator, and only reports the end of the beacons once the checksum
+// of the snapshot is verified: the beacons read before an error must be
+// discarded.
+type SnapshotReader struct {
+	// Header is the header of the snapshot and Info its chain information.
+	Header *SnapshotHeader
+	Info   *Info
+
+	r     *bufio.Reader
+	h     hash.Hash
+	hr    io.Reader
+	count uint64
+	done  bool
+}
+
+// NewSnapshotReader reads the header of the snapshot.
+func NewSnapshotReader(r io.Reader) (*SnapshotReader, error) {
+	br := bufio.NewReader(r)
+	h := sha256.New()
+	s := &SnapshotReader{r: br, h: h, hr: io.TeeReader(br, h)}
+	magic := make([]byte, len(snapshotMagic))
+	if _, err := io.ReadFull(s.hr, magic); err != nil || !bytes.Equal(magic, snapshotMagic) {
+		return nil, errors.New("snapshot: not a drand snapshot")
+	}
+	buff, err := s.readRecord(maxSnapshotHeader)
+	if err != nil {
+		return nil, fmt.Errorf("snapshot: reading header: %w", err)
+	}
+	s.Header = new(SnapshotHeader)
+	if err := json.Unmarshal(buff, s.Header); err != nil {
+		return nil, fmt.Errorf("snapshot: invalid header: %w", err)
+	}
+	if s.Header.Version > SnapshotVersion {
+		return nil, fmt.Errorf("snapshot: unsupported version %d", s.Header.Version)
+	}
+	if s.Info, err = InfoFromJSON(bytes.NewReader(s.Header.Info)); err != nil {
+		return nil, fmt.Errorf("snapshot: %w", err)
+	}
+	return s, nil
+}
+
+func (s *SnapshotReader) readRecord(max uint32) ([]byte, error) {
+	var length uint32
+	if err := binary.Read(s.hr, binary.BigEndian, &length); err != nil {
+		return nil, err
+	}
+	if length > max {
+		return nil, fmt.Errorf("record of %d bytes is too large", length)
+	}
+	buff := make([]byte, length)
+	if _, err := io.ReadFull(s.hr, buff); err != nil {
+		return nil, err
+	}
+	return buff, nil
+}
+
+// Next returns the next beacon of the snapshot, or nil once all the beacons
+// were read and the checksum of the snapshot verified.
+func (s *SnapshotReader) Next() (*Beacon, error) {
+	if s.done {
+		return nil, nil
+	}
+	buff, err := s.readRecord(maxSnapshotBeacon)
+	if err != nil {
+		return nil, fmt.Errorf("snapshot: reading beacon: %w", err)
+	}
+	if len(buff) == 0 {
+		return nil, s.end()
+	}
+	b := new(Beacon)
+	if err := b.Unmarshal(buff); err != nil {
+		return nil, fmt.Errorf("snapshot: invalid beacon: %w", err)
+	}
+	s.count++
+	return b, nil
+}
+
+func (s *SnapshotReader) end() error {
+	var count uint64
+	if err := binary.Read(s.hr, binary.BigEndian, &count); err != nil {
+		return fmt.Errorf("snapshot: reading count: %w", err)
+	}
+	expected := s.h.Sum(nil)
+	checksum := make([]byte, sha256.Size)
+	if _, err := io.ReadFull(s.r, checksum); err != nil {
+		return fmt.Errorf("snapshot: reading checksum: %w", err)
+	}
+	if !key.ConstantTimeEqual(checksum, expected) {
+		return errors.New("snapshot: checksum mismatch")
+	}
+	if count != s.count {
+		return fmt.Errorf("snapshot: holds %d beacons instead of %d", s.count, count)
+	}
+	s.done = true
+	return nil
+}
+
+// ImportSnapshot verifies the beacons of the snapshot and stores them. The
+// first beacon of the snapshot must follow or be the last beacon of the store,
+// if any. When trusted is set, the snapshot must be a snapshot of its chain, and
+// otherwise the chain information of the snapshot is trusted. It returns the
+// chain information of the snapshot and the number of beacons imported. The
+// beacons verified before an invalid one are stored, so the store should be
+// discarded when the import fails.
+func ImportSnapshot(s Store, r io.Reader, trusted *Info) (*Info, int, error) {
+	sr, err := NewSnapshotReader(r)
+	if err != nil {
+		return nil, 0, err
+	}
+	if trusted != nil && !key.ConstantTimeEqual(trusted.Hash(), sr.Info.Hash()) {
+		return nil, 0, errors.New("snapshot: snapshot of another chain")
+	}
+	vf, err := NewVerifier(sr.Info)
+	if err != nil {
+		return nil, 0, err
+	}
+	prev, _ := s.Last()
+	var imported int
+	for {
+		b, err := sr.Next()
+		if err != nil {
+			return sr.Info, imported, err
+		}
+		if b == nil {
+			return sr.Info, imported, nil
+		}
+		if prev != nil && b.Round == prev.Round && b.Equal(prev) {
+			// the beacon already stored, such as the genesis beacon of a
+			// fresh node
+			continue
+		}
+		if inc := checkBeacon(vf, prev, b); inc != nil {
+			return sr.Info, imported, fmt.Errorf("snapshot: %w", inc)
+		}
+		if err := s.Put(b); err != nil {
+			return sr.Info, imported, err
+		}
+		imported++
+		prev = b
+	}
+}
@@ -0,0 +1,169 @@
+package chain
+
+import (
+	"bytes"
+	"errors"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/drand/drand/key"
+	"github.com/drand/kyber/util/random"
+	"github.com/stretchr/testify/require"
+)
+
+// memStore is a Store keeping the beacons in memory, sorted by round.
+type memStore struct {
+	beacons []*Beacon
+}
+
+func (m *memStore) Len() int { return len(m.beacons) }
+func (m *memStore) Close()   {}
+
+func (m *memStore) index(round uint64) int {
+	return sort.Search(len(m.beacons), func(i int) bool { return m.beacons[i].Round >= round })
+}
+
+func (m *memStore) Put(b *Beacon) error {
+	i := m.index(b.Round)
+	if i < len(m.beacons) && m.beacons[i].Round == b.Round {
+		m.beacons[i] = b
+		return nil
+	}
+	m.beacons = append(m.beacons[:i], append([]*Beacon{b}, m.beacons[i:]...)...)
+	return nil
+}
+
+func (m *memStore) Last() (*Beacon, error) {
+	if len(m.beacons) == 0 {
+		return nil, errors.New("empty store")
+	}
+	return m.beacons[len(m.beacons)-1], nil
+}
+
+func (m *memStore) Get(round uint64) (*Beacon, error) {
+	i := m.index(round)
+	if i == len(m.beacons) || m.beacons[i].Round != round {
+		return nil, errors.New("not found")
+	}
+	return m.beacons[i], nil
+}
+
+func (m *memStore) Del(round uint64) error {
+	if i := m.index(round); i < len(m.beacons) && m.beacons[i].Round == round {
+		m.beacons = append(m.beacons[:i], m.beacons[i+1:]...)
+	}
+	return nil
+}
+
+func (m *memStore) Cursor(fn func(Cursor)) { fn(&memCursor{m: m}) }
+
+type memCursor struct {
+	m *memStore
+	i int
+}
+
+func (c *memCursor) at(i int) *Beacon {
+	c.i = i
+	if i >= len(c.m.beacons) {
+		return nil
+	}
+	return c.m.beacons[i]
+}
+
+func (c *memCursor) First() *Beacon            { return c.at(0) }
+func (c *memCursor) Next() *Beacon             { return c.at(c.i + 1) }
+func (c *memCursor) Seek(round uint64) *Beacon { return c.at(c.m.index(round)) }
+func (c *memCursor) Last() *Beacon {
+	if len(c.m.beacons) == 0 {
+		return nil
+	}
+	return c.at(len(c.m.beacons) - 1)
+}
+
+func snapshotChain(t *testing.T, rounds uint64) (*Info, *memStore) {
+	priv := key.KeyGroup.Scalar().Pick(random.New())
+	info := &Info{
+		PublicKey:   key.KeyGroup.Point().Mul(priv, nil),
+		Period:      time.Second,
+		GenesisTime: 1000,
+		GroupHash:   []byte("group"),
+	}
+	s := &memStore{beacons: []*Beacon{GenesisBeacon(info)}}
+	for round := uint64(1); round <= rounds; round++ {
+		prev := s.beacons[round-1].Signature
+		sig, err := key.AuthScheme.Sign(priv, Message(round, prev))
+		require.NoError(t, err)
+		require.NoError(t, s.Put(&Beacon{Round: round, Signature: sig, PreviousSig: prev}))
+	}
+	return info, s
+}
+
+func TestSnapshot(t *testing.T) {
+	info, src := snapshotChain(t, 10)
+	var buff bytes.Buffer
+	n, err := ExportSnapshot(&buff, info, src, 0, 0)
+	require.NoError(t, err)
+	require.Equal(t, 11, n)
+	snapshot := buff.Bytes()
+
+	// a fresh node already stores the genesis beacon
+	dst := &memStore{beacons: []*Beacon{GenesisBeacon(info)}}
+	got, n, err := ImportSnapshot(dst, bytes.NewReader(snapshot), info)
+	require.NoError(t, err)
+	require.Equal(t, 10, n)
+	require.True(t, got.Equal(info))
+	require.Equal(t, 11, dst.Len())
+	last, err := dst.Last()
+	require.NoError(t, err)
+	require.True(t, last.Equal(src.beacons[10]))
+
+	// the snapshot can be audited offline
+	sr, err := NewSnapshotReader(bytes.NewReader(snapshot))
+	require.NoError(t, err)
+	report, err := VerifyChain(sr.Info, sr)
+	require.NoError(t, err)
+	require.Nil(t, report.Inconsistency)
+	require.Equal(t, 11, report.Verified)
+
+	// a range of the chain
+	buff.Reset()
+	n, err = ExportSnapshot(&buff, info, src, 3, 6)
+	require.NoError(t, err)
+	require.Equal(t, 4, n)
+	dst = &memStore{beacons: src.beacons[:3:3]}
+	_, n, err = ImportSnapshot(dst, &buff, nil)
+	require.NoError(t, err)
+	require.Equal(t, 4, n)
+	require.Equal(t, 7, dst.Len())
+}
+
+func TestSnapshotInvalid(t *testing.T) {
+	info, src := snapshotChain(t, 5)
+	var buff bytes.Buffer
+	_, err := ExportSnapshot(&buff, info, src, 0, 0)
+	require.NoError(t, err)
+	snapshot := buff.Bytes()
+
+	// the checksum covers the whole snapshot
+	tampered := append([]byte{}, snapshot...)
+	tampered[len(tampered)-1] ^= 0xff
+	_, _, err = ImportSnapshot(&memStore{}, bytes.NewReader(tampered), info)
+	require.Error(t, err)
+
+	// a truncated snapshot is rejected
+	_, _, err = ImportSnapshot(&memStore{}, bytes.NewReader(snapshot[:len(snapshot)-40]), info)
+	require.Error(t, err)
+
+	_, _, err = ImportSnapshot(&memStore{}, bytes.NewReader([]byte("not a snapshot")), nil)
+	require.Error(t, err)
+
+	// a snapshot of another chain
+	other, otherStore := snapshotChain(t, 1)
+	_, _, err = ImportSnapshot(&memStore{}, bytes.NewReader(snapshot), other)
+	require.Error(t, err)
+
+	// beacons not following the stored ones, of a chain with the same genesis
+	_, _, err = ImportSnapshot(otherStore, bytes.NewReader(snapshot), nil)
+	require.Error(t, err)
+}
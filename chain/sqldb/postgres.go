@@ -0,0 +1,7 @@
+//go:build postgres
+// +build postgres
+
+package sqldb
+
+// The Postgres driver is only linked in builds with the postgres tag.
+import _ "github.com/lib/pq"
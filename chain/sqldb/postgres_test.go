@@ -0,0 +1,31 @@
+//go:build postgres
+// +build postgres
+
+package sqldb
+
+import (
+	"os"
+	"testing"
+
+	"github.com/drand/drand/chain"
+	"github.com/drand/drand/chain/storetest"
+	"github.com/stretchr/testify/require"
+)
+
+// The Postgres tests need the data source of an empty database in
+// DRAND_TEST_POSTGRES.
+
+func TestStorePostgresConformance(t *testing.T) {
+	dsn := os.Getenv("DRAND_TEST_POSTGRES")
+	if dsn == "" {
+		t.Skip("DRAND_TEST_POSTGRES not set")
+	}
+	storetest.Run(t, func(t *testing.T) chain.Store {
+		store, err := NewSQLStore("postgres", dsn)
+		require.NoError(t, err)
+		// the database is shared by the tests
+		_, err = store.(*sqlStore).db.Exec("DELETE FROM beacons")
+		require.NoError(t, err)
+		return store
+	})
+}
@@ -0,0 +1,8 @@
+//go:build sqlite
+// +build sqlite
+
+package sqldb
+
+// The SQLite driver needs cgo, so it is only linked in builds with the sqlite
+// tag.
+import _ "github.com/mattn/go-sqlite3"
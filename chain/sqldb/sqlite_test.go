@@ -0,0 +1,26 @@
+//go:build sqlite
+// +build sqlite
+
+package sqldb
+
+import (
+	"io/ioutil"
+	"os"
+	"path"
+	"testing"
+
+	"github.com/drand/drand/chain"
+	"github.com/drand/drand/chain/storetest"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStoreSQLiteConformance(t *testing.T) {
+	storetest.Run(t, func(t *testing.T) chain.Store {
+		tmp, err := ioutil.TempDir("", "sqlitetest*")
+		require.NoError(t, err)
+		t.Cleanup(func() { os.RemoveAll(tmp) })
+		store, err := NewSQLStore("sqlite3", path.Join(tmp, "drand.sqlite"))
+		require.NoError(t, err)
+		return store
+	})
+}
@@ -0,0 +1,273 @@
+// Package sqldb stores the beacons of a chain in a SQL database, SQLite or
+// Postgres, through the database/sql package. The driver of each database is
+// only linked in builds with its tag, "sqlite" or "postgres", as the SQLite one
+// needs cgo.
+package sqldb
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/drand/drand/chain"
+	"github.com/drand/drand/log"
+)
+
+// ErrNoBeaconSaved is the error returned when no beacon have been saved in the
+// database yet.
+var ErrNoBeaconSaved = errors.New("beacon not found in database")
+
+// dialect holds what differs between the supported databases.
+type dialect struct {
+	// blob is the type of the binary columns.
+	blob string
+	// placeholder returns the placeholder of the i-th parameter of a query,
+	// starting at 1.
+	placeholder func(i int) string
+	// tag is the build tag linking the driver of the database.
+	tag string
+}
+
+var dialects = map[string]*dialect{
+	"sqlite3": {
+		blob:        "BLOB",
+		placeholder: func(int) string { return "?" },
+		tag:         "sqlite",
+	},
+	"postgres": {
+		blob:        "BYTEA",
+		placeholder: func(i int) string { return fmt.Sprintf("$%d", i) },
+		tag:         "postgres",
+	},
+}
+
+// linked tells whether the driver of the given name is linked in the binary.
+func linked(driver string) bool {
+	for _, d := range sql.Drivers() {
+		if d == driver {
+			return true
+		}
+	}
+	return false
+}
+
+// Dialects returns the names of the databases a SQL store can use, which are
+// the names their drivers register under.
+func Dialects() []string {
+	return []string{"sqlite3", "postgres"}
+}
+
+// sqlStore implements the Store interface with a table holding a row per
+// round, so that the chain can be inspected and backed up with the tools of
+// the database.
+type sqlStore struct {
+	db      *sql.DB
+	dialect *dialect
+}
+
+// NewSQLStore returns a Store implementation storing the beacons in the
+// database of the given driver, "sqlite3" or "postgres", at the given data
+// source. It creates the table of the beacons if needed. It fails if the
+// binary is built without the tag of the driver.
+func NewSQLStore(driver, dsn string) (chain.Store, error) {
+	d, ok := dialects[driver]
+	if !ok {
+		return nil, fmt.Errorf("unsupported sql database %q, must be one of %s", driver, strings.Join(Dialects(), ", "))
+	}
+	if !linked(driver) {
+		return nil, fmt.Errorf("the %s driver is not linked, build with the %q tag", driver, d.tag)
+	}
+	db, err := sql.Open(driver, dsn)
+	if err != nil {
+		return nil, err
+	}
+	create := fmt.Sprintf(`CREATE TABLE IF NOT EXISTS beacons (
+		round BIGINT PRIMARY KEY,
+		previous_sig %[1]s,
+		signature %[1]s NOT NULL,
+		signature_v2 %[1]s
+	)`, d.blob)
+	if _, err := db.Exec(create); err != nil {
+		_ = db.Close()
+		return nil, fmt.Errorf("creating the beacons table: %w", err)
+	}
+	return &sqlStore{db: db, dialect: d}, nil
+}
+
+// query replaces the question marks of the query by the placeholders of the
+// dialect.
+func (s *sqlStore) query(q string) string {
+	var b strings.Builder
+	i := 0
+	for _, r := range q {
+		if r == '?' {
+			i++
+			b.WriteString(s.dialect.placeholder(i))
+			continue
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+const columns = "round, previous_sig, signature, signature_v2"
+
+func (s *sqlStore) Len() int {
+	var length int
+	if err := s.db.QueryRow("SELECT COUNT(*) FROM beacons").Scan(&length); err != nil {
+		log.DefaultLogger().Warn("sqldb", "error getting length", "err", err)
+	}
+	return length
+}
+
+func (s *sqlStore) Close() {
+	if err := s.db.Close(); err != nil {
+		log.DefaultLogger().Debug("sqldb", "close", "err", err)
+	}
+}
+
+// Put implements the Store interface, replacing the beacon of the same round
+// if any.
+func (s *sqlStore) Put(b *chain.Beacon) error {
+	_, err := s.db.Exec(s.query(`INSERT INTO beacons (`+columns+`) VALUES (?, ?, ?, ?)
+		ON CONFLICT (round) DO UPDATE SET previous_sig = excluded.previous_sig,
+		signature = excluded.signature, signature_v2 = excluded.signature_v2`),
+		int64(b.Round), b.PreviousSig, b.Signature, b.SignatureV2)
+	return err
+}
+
+type scanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanBeacon(row scanner) (*chain.Beacon, error) {
+	var round int64
+	b := new(chain.Beacon)
+	if err := row.Scan(&round, &b.PreviousSig, &b.Signature, &b.SignatureV2); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNoBeaconSaved
+		}
+		return nil, err
+	}
+	b.Round = uint64(round)
+	return b, nil
+}
+
+// Last returns the last beacon signature saved into the db
+func (s *sqlStore) Last() (*chain.Beacon, error) {
+	return scanBeacon(s.db.QueryRow("SELECT " + columns + " FROM beacons ORDER BY round DESC LIMIT 1"))
+}
+
+// Get returns the beacon saved at this round
+func (s *sqlStore) Get(round uint64) (*chain.Beacon, error) {
+	return scanBeacon(s.db.QueryRow(s.query("SELECT "+columns+" FROM beacons WHERE round = ?"), int64(round)))
+}
+
+func (s *sqlStore) Del(round uint64) error {
+	_, err := s.db.Exec(s.query("DELETE FROM beacons WHERE round = ?"), int64(round))
+	return err
+}
+
+// DelRange implements the chain.RangeDeleter interface.
+func (s *sqlStore) DelRange(from, to uint64, keep func(round uint64) bool) (int, error) {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer func() { _ = tx.Rollback() }()
+	rows, err := tx.Query(s.query("SELECT round FROM beacons WHERE round >= ? AND round <= ?"), int64(from), int64(to))
+	if err != nil {
+		return 0, err
+	}
+	var rounds []int64
+	for rows.Next() {
+		var round int64
+		if err := rows.Scan(&round); err != nil {
+			rows.Close()
+			return 0, err
+		}
+		if !keep(uint64(round)) {
+			rounds = append(rounds, round)
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+	del, err := tx.Prepare(s.query("DELETE FROM beacons WHERE round = ?"))
+	if err != nil {
+		return 0, err
+	}
+	defer del.Close()
+	for _, round := range rounds {
+		if _, err := del.Exec(round); err != nil {
+			return 0, err
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
+	return len(rounds), nil
+}
+
+func (s *sqlStore) Cursor(fn func(chain.Cursor)) {
+	c := &sqlCursor{s: s}
+	defer c.close()
+	fn(c)
+	if c.err != nil {
+		log.DefaultLogger().Warn("sqldb", "cursor", "err", c.err)
+	}
+}
+
+// sqlCursor iterates over the rows of a query ordered by round, which each
+// move of the cursor other than Next replaces.
+type sqlCursor struct {
+	s    *sqlStore
+	rows *sql.Rows
+	err  error
+}
+
+func (c *sqlCursor) close() {
+	if c.rows != nil {
+		c.rows.Close()
+		c.rows = nil
+	}
+}
+
+func (c *sqlCursor) seek(q string, args ...interface{}) *chain.Beacon {
+	c.close()
+	c.rows, c.err = c.s.db.Query(c.s.query(q), args...)
+	if c.err != nil {
+		return nil
+	}
+	return c.Next()
+}
+
+func (c *sqlCursor) First() *chain.Beacon {
+	return c.Seek(0)
+}
+
+func (c *sqlCursor) Next() *chain.Beacon {
+	if c.rows == nil || !c.rows.Next() {
+		c.close()
+		return nil
+	}
+	b, err := scanBeacon(c.rows)
+	if err != nil {
+		c.err = err
+		c.close()
+		return nil
+	}
+	return b
+}
+
+func (c *sqlCursor) Seek(round uint64) *chain.Beacon {
+	return c.seek("SELECT "+columns+" FROM beacons WHERE round >= ? ORDER BY round", int64(round))
+}
+
+// Last moves the cursor to the last beacon, after which Next returns nil as
+// for the other stores.
+func (c *sqlCursor) Last() *chain.Beacon {
+	return c.seek("SELECT " + columns + " FROM beacons ORDER BY round DESC LIMIT 1")
+}
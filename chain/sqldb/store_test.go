@@ -0,0 +1,24 @@
+package sqldb
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewSQLStore(t *testing.T) {
+	_, err := NewSQLStore("mysql", "")
+	require.Error(t, err)
+	if !linked("sqlite3") {
+		_, err = NewSQLStore("sqlite3", "")
+		require.Error(t, err)
+		require.Contains(t, err.Error(), `"sqlite" tag`)
+	}
+}
+
+func TestQueryPlaceholders(t *testing.T) {
+	s := &sqlStore{dialect: dialects["postgres"]}
+	require.Equal(t, "SELECT a FROM b WHERE c = $1 AND d <= $2", s.query("SELECT a FROM b WHERE c = ? AND d <= ?"))
+	s = &sqlStore{dialect: dialects["sqlite3"]}
+	require.Equal(t, "WHERE c = ?", s.query("WHERE c = ?"))
+}
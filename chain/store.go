@@ -3,6 +3,7 @@ package chain
 import (
 	"bytes"
 	"encoding/binary"
+	"fmt"
 )
 
 // store contains all the definitions and implementation of the logic that
@@ -21,6 +22,53 @@ type Store interface {
 	Del(round uint64) error
 }
 
+// RangeDeleter is implemented by the stores able to delete many rounds at
+// once, much faster than deleting them one by one with Del.
+type RangeDeleter interface {
+	// DelRange deletes the stored rounds from `from` to `to` included for
+	// which keep returns false, and returns the number of rounds deleted.
+	DelRange(from, to uint64, keep func(round uint64) bool) (int, error)
+}
+
+// DelRange deletes the stored rounds from `from` to `to` included for which
+// keep returns false, at once when the store is a RangeDeleter.
+func DelRange(s Store, from, to uint64, keep func(round uint64) bool) (int, error) {
+	if rd, ok := s.(RangeDeleter); ok {
+		return rd.DelRange(from, to, keep)
+	}
+	var rounds []uint64
+	s.Cursor(func(c Cursor) {
+		for b := c.Seek(from); b != nil && b.Round <= to; b = c.Next() {
+			if !keep(b.Round) {
+				rounds = append(rounds, b.Round)
+			}
+		}
+	})
+	for i, r := range rounds {
+		if err := s.Del(r); err != nil {
+			return i, err
+		}
+	}
+	return len(rounds), nil
+}
+
+// CopyStore copies all the beacons of src into dst in order, and returns the
+// number of beacons copied. It migrates a chain between storage backends.
+func CopyStore(dst, src Store) (int, error) {
+	var n int
+	var err error
+	src.Cursor(func(c Cursor) {
+		for b := c.First(); b != nil; b = c.Next() {
+			if err = dst.Put(b); err != nil {
+				err = fmt.Errorf("copying round %d: %w", b.Round, err)
+				return
+			}
+			n++
+		}
+	})
+	return n, err
+}
+
 // Cursor iterates over items in sorted key order. This starts from the
 // first key/value pair and updates the k/v variables to the
 // next key/value on each iteration.
@@ -0,0 +1,134 @@
+// Package storetest holds the conformance tests that every implementation of
+// chain.Store must pass.
+package storetest
+
+import (
+	"testing"
+
+	"github.com/drand/drand/chain"
+	"github.com/stretchr/testify/require"
+)
+
+func beacon(round uint64) *chain.Beacon {
+	return &chain.Beacon{
+		Round:       round,
+		PreviousSig: chain.RoundToBytes(round - 1),
+		Signature:   chain.RoundToBytes(round),
+	}
+}
+
+// Run runs the conformance tests against the stores returned by open, which
+// must be empty.
+func Run(t *testing.T, open func(t *testing.T) chain.Store) {
+	tests := map[string]func(*testing.T, chain.Store){
+		"PutGet":   testPutGet,
+		"Cursor":   testCursor,
+		"Del":      testDel,
+		"DelRange": testDelRange,
+		"Copy":     func(t *testing.T, s chain.Store) { testCopy(t, s, open(t)) },
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			s := open(t)
+			defer s.Close()
+			test(t, s)
+		})
+	}
+}
+
+func testPutGet(t *testing.T, s chain.Store) {
+	require.Equal(t, 0, s.Len())
+	_, err := s.Last()
+	require.Error(t, err)
+	_, err = s.Get(1)
+	require.Error(t, err)
+
+	b1 := beacon(145)
+	b1.SignatureV2 = []byte("v2")
+	b2 := beacon(146)
+	require.NoError(t, s.Put(b1))
+	require.NoError(t, s.Put(b2))
+	require.Equal(t, 2, s.Len())
+	last, err := s.Last()
+	require.NoError(t, err)
+	require.True(t, b2.Equal(last))
+	got, err := s.Get(145)
+	require.NoError(t, err)
+	require.True(t, b1.Equal(got))
+	require.Equal(t, b1.SignatureV2, got.SignatureV2)
+
+	// putting a round again replaces it
+	b1.Signature = []byte("another signature")
+	require.NoError(t, s.Put(b1))
+	require.Equal(t, 2, s.Len())
+	got, err = s.Get(145)
+	require.NoError(t, err)
+	require.Equal(t, b1.Signature, got.Signature)
+}
+
+func testCursor(t *testing.T, s chain.Store) {
+	s.Cursor(func(c chain.Cursor) {
+		require.Nil(t, c.First())
+		require.Nil(t, c.Last())
+	})
+	// rounds are iterated in order whatever the insertion order
+	for _, round := range []uint64{300, 0, 1, 256, 2} {
+		require.NoError(t, s.Put(beacon(round)))
+	}
+	var rounds []uint64
+	s.Cursor(func(c chain.Cursor) {
+		for b := c.First(); b != nil; b = c.Next() {
+			rounds = append(rounds, b.Round)
+		}
+		require.Equal(t, uint64(256), c.Seek(3).Round)
+		require.Equal(t, uint64(300), c.Next().Round)
+		require.Nil(t, c.Next())
+		require.Equal(t, uint64(300), c.Last().Round)
+		require.Nil(t, c.Next())
+		require.Nil(t, c.Seek(301))
+	})
+	require.Equal(t, []uint64{0, 1, 2, 256, 300}, rounds)
+}
+
+func testDel(t *testing.T, s chain.Store) {
+	require.NoError(t, s.Put(beacon(1)))
+	require.NoError(t, s.Put(beacon(2)))
+	require.NoError(t, s.Del(2))
+	_, err := s.Get(2)
+	require.Error(t, err)
+	last, err := s.Last()
+	require.NoError(t, err)
+	require.Equal(t, uint64(1), last.Round)
+	require.Equal(t, 1, s.Len())
+}
+
+func testDelRange(t *testing.T, s chain.Store) {
+	for round := uint64(0); round <= 30; round++ {
+		require.NoError(t, s.Put(beacon(round)))
+	}
+	keep := func(round uint64) bool { return round%10 == 0 }
+	n, err := chain.DelRange(s, 1, 25, keep)
+	require.NoError(t, err)
+	require.Equal(t, 23, n)
+	require.Equal(t, 8, s.Len())
+	for _, round := range []uint64{0, 10, 20, 26, 30} {
+		_, err := s.Get(round)
+		require.NoError(t, err)
+	}
+	_, err = s.Get(15)
+	require.Error(t, err)
+}
+
+func testCopy(t *testing.T, src, dst chain.Store) {
+	defer dst.Close()
+	for round := uint64(0); round <= 20; round++ {
+		require.NoError(t, src.Put(beacon(round)))
+	}
+	n, err := chain.CopyStore(dst, src)
+	require.NoError(t, err)
+	require.Equal(t, 21, n)
+	require.Equal(t, 21, dst.Len())
+	last, err := dst.Last()
+	require.NoError(t, err)
+	require.True(t, beacon(20).Equal(last))
+}
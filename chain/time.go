@@ -3,6 +3,8 @@ package chain
 import (
 	"math"
 	"time"
+
+	"github.com/drand/drand/key"
 )
 
 // time.Unix will add `time.unixToInternal` to a unix timestamp in int64 space.
@@ -61,3 +63,86 @@ func NextRound(now int64, period time.Duration, genesis int64) (nextRound uint64
 	nextTime = genesis + int64(nextRound*uint64(period.Seconds()))
 	return nextRound + 1, nextTime
 }
+
+// EpochTimeOfRound returns the time of the round of a chain whose period
+// changes at each of the given epochs, see TimeOfRound.
+func EpochTimeOfRound(epochs []*key.Epoch, round uint64) int64 {
+	if round == 0 {
+		return epochs[0].Time
+	}
+	e := epochs[0]
+	for _, next := range epochs[1:] {
+		if round < next.Round {
+			break
+		}
+		e = next
+	}
+	return TimeOfRound(e.Period, e.Time, round-e.Round+1)
+}
+
+// EpochNextRound returns the next upcoming round and its time of a chain
+// whose period changes at each of the given epochs, see NextRound.
+func EpochNextRound(now int64, epochs []*key.Epoch) (nextRound uint64, nextTime int64) {
+	i := 0
+	for i+1 < len(epochs) && now >= epochs[i+1].Time {
+		i++
+	}
+	e := epochs[i]
+	nextRound, nextTime = NextRound(now, e.Period, e.Time)
+	nextRound += e.Round - 1
+	// the schedule of the next epoch takes over at its first round
+	if i+1 < len(epochs) && nextTime >= epochs[i+1].Time {
+		return epochs[i+1].Round, epochs[i+1].Time
+	}
+	return nextRound, nextTime
+}
+
+// EpochCurrentRound returns the active round at now of a chain whose period
+// changes at each of the given epochs, see CurrentRound.
+func EpochCurrentRound(now int64, epochs []*key.Epoch) uint64 {
+	nextRound, _ := EpochNextRound(now, epochs)
+	if nextRound <= 1 {
+		return nextRound
+	}
+	return nextRound - 1
+}
+
+// Schedule returns the epochs of the chain, a single one starting at the
+// genesis if its period never changed.
+func (c *Info) Schedule() []*key.Epoch {
+	if len(c.Epochs) > 0 {
+		return c.Epochs
+	}
+	return []*key.Epoch{{Round: 1, Time: c.GenesisTime, Period: c.Period}}
+}
+
+// PeriodAt returns the period of the chain at the given round.
+func (c *Info) PeriodAt(round uint64) time.Duration {
+	s := c.Schedule()
+	p := s[0].Period
+	for _, e := range s[1:] {
+		if round < e.Round {
+			break
+		}
+		p = e.Period
+	}
+	return p
+}
+
+// TimeOfRound returns the time at which the given round of the chain is
+// produced.
+func (c *Info) TimeOfRound(round uint64) time.Time {
+	return time.Unix(EpochTimeOfRound(c.Schedule(), round), 0)
+}
+
+// RoundAt returns the latest round of the chain produced at the given time.
+func (c *Info) RoundAt(t time.Time) uint64 {
+	return EpochCurrentRound(t.Unix(), c.Schedule())
+}
+
+// NextRound returns the next round of the chain after the given time, and the
+// time it is produced.
+func (c *Info) NextRound(t time.Time) (uint64, time.Time) {
+	round, next := EpochNextRound(t.Unix(), c.Schedule())
+	return round, time.Unix(next, 0)
+}
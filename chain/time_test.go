@@ -5,6 +5,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/drand/drand/key"
 	clock "github.com/jonboulle/clockwork"
 	"github.com/stretchr/testify/require"
 )
@@ -68,3 +69,44 @@ func TestChainNextRound(t *testing.T) {
 	time2 := TimeOfRound(period, genesis, 3)
 	require.Equal(t, expTime2, time2)
 }
+
+func TestInfoTimeOfRound(t *testing.T) {
+	info := &Info{Period: 30 * time.Second, GenesisTime: 1000}
+	require.Equal(t, time.Unix(1000, 0), info.TimeOfRound(1))
+	require.Equal(t, time.Unix(1060, 0), info.TimeOfRound(3))
+	require.Equal(t, uint64(3), info.RoundAt(time.Unix(1075, 0)))
+	require.Equal(t, uint64(3), info.RoundAt(info.TimeOfRound(3)))
+}
+
+func TestEpochSchedule(t *testing.T) {
+	epochs := []*key.Epoch{
+		{Round: 1, Time: 1000, Period: 10 * time.Second},
+		{Round: 11, Time: 1100, Period: 3 * time.Second},
+	}
+	require.Equal(t, int64(1000), EpochTimeOfRound(epochs, 1))
+	require.Equal(t, int64(1090), EpochTimeOfRound(epochs, 10))
+	require.Equal(t, int64(1100), EpochTimeOfRound(epochs, 11))
+	require.Equal(t, int64(1103), EpochTimeOfRound(epochs, 12))
+
+	round, next := EpochNextRound(900, epochs)
+	require.Equal(t, uint64(1), round)
+	require.Equal(t, int64(1000), next)
+	// the last round of the first epoch is followed by the first round of
+	// the next one
+	round, next = EpochNextRound(1095, epochs)
+	require.Equal(t, uint64(11), round)
+	require.Equal(t, int64(1100), next)
+	round, next = EpochNextRound(1100, epochs)
+	require.Equal(t, uint64(12), round)
+	require.Equal(t, int64(1103), next)
+
+	require.Equal(t, uint64(10), EpochCurrentRound(1099, epochs))
+	require.Equal(t, uint64(11), EpochCurrentRound(1102, epochs))
+	require.Equal(t, uint64(12), EpochCurrentRound(1103, epochs))
+
+	// a single epoch is the schedule of a chain whose period never changed
+	single := epochs[:1]
+	for _, now := range []int64{999, 1000, 1095, 1234} {
+		require.Equal(t, CurrentRound(now, 10*time.Second, 1000), EpochCurrentRound(now, single))
+	}
+}
@@ -0,0 +1,54 @@
+package chain
+
+import (
+	"github.com/drand/kyber"
+)
+
+// Verifier verifies the beacons of a chain. It resolves the scheme and copies
+// the public key of the chain once, so that chain walks verifying many
+// beacons do not process them again for each beacon. Verifications do not
+// modify the public key, so a Verifier is safe for concurrent use.
+type Verifier struct {
+	info   *Info
+	pubkey kyber.Point
+	scheme Scheme
+}
+
+// NewVerifier returns the verifier of the beacons of a chain.
+func NewVerifier(info *Info) (*Verifier, error) {
+	s, err := SchemeFromID(info.Scheme)
+	if err != nil {
+		return nil, err
+	}
+	return &Verifier{
+		info:   info,
+		pubkey: info.PublicKey.Clone(),
+		scheme: s,
+	}, nil
+}
+
+// Info returns the chain info the verifier was created with.
+func (v *Verifier) Info() *Info {
+	return v.info
+}
+
+// VerifyBeacon returns an error unless the beacon is valid according to the
+// scheme of the chain, as Info.VerifyBeacon.
+func (v *Verifier) VerifyBeacon(b *Beacon) error {
+	if v.scheme.ID() == DefaultSchemeID && v.info.V2From != 0 && b.Round >= v.info.V2From {
+		return v.VerifyV2(b)
+	}
+	return v.scheme.Verify(v.pubkey, b)
+}
+
+// VerifyV1 returns an error unless the signature of the beacon over its round
+// and previous signature is valid.
+func (v *Verifier) VerifyV1(b *Beacon) error {
+	return v.scheme.Suite().ThresholdScheme.VerifyRecovered(v.pubkey, Message(b.Round, b.PreviousSig), b.Signature)
+}
+
+// VerifyV2 returns an error unless the signature of the beacon over its round
+// only is valid.
+func (v *Verifier) VerifyV2(b *Beacon) error {
+	return v.scheme.Suite().ThresholdScheme.VerifyRecovered(v.pubkey, MessageV2(b.Round), b.SignatureV2)
+}
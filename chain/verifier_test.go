@@ -0,0 +1,66 @@
+package chain
+
+import (
+	"testing"
+	"time"
+
+	"github.com/drand/drand/key"
+	"github.com/drand/kyber/util/random"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVerifier(t *testing.T) {
+	priv := key.KeyGroup.Scalar().Pick(random.New())
+	info := &Info{
+		PublicKey:   key.KeyGroup.Point().Mul(priv, nil),
+		Period:      time.Second,
+		GenesisTime: 1000,
+		GroupHash:   []byte("group"),
+		V2From:      10,
+	}
+	vf, err := NewVerifier(info)
+	require.NoError(t, err)
+	require.Equal(t, info, vf.Info())
+
+	prev := []byte("previous")
+	sig, err := key.AuthScheme.Sign(priv, Message(5, prev))
+	require.NoError(t, err)
+	sigV2, err := key.AuthScheme.Sign(priv, MessageV2(5))
+	require.NoError(t, err)
+	b := &Beacon{Round: 5, Signature: sig, PreviousSig: prev, SignatureV2: sigV2}
+	require.NoError(t, vf.VerifyV1(b))
+	require.NoError(t, vf.VerifyV2(b))
+	require.NoError(t, vf.VerifyBeacon(b))
+
+	// past V2From, only the v2 signature is checked.
+	sigV2, err = key.AuthScheme.Sign(priv, MessageV2(12))
+	require.NoError(t, err)
+	b = &Beacon{Round: 12, Signature: []byte("invalid"), SignatureV2: sigV2}
+	require.Error(t, vf.VerifyV1(b))
+	require.NoError(t, vf.VerifyBeacon(b))
+	b.SignatureV2 = sig
+	require.Error(t, vf.VerifyBeacon(b))
+
+	_, err = NewVerifier(&Info{PublicKey: info.PublicKey, Scheme: "unknown"})
+	require.Error(t, err)
+}
+
+func BenchmarkVerifier(b *testing.B) {
+	secret := key.KeyGroup.Scalar().Pick(random.New())
+	vf, err := NewVerifier(&Info{PublicKey: key.KeyGroup.Point().Mul(secret, nil)})
+	if err != nil {
+		panic(err)
+	}
+	var round uint64 = 16
+	sig, _ := key.AuthScheme.Sign(secret, MessageV2(round))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		err := vf.VerifyV2(&Beacon{
+			Round:       round,
+			SignatureV2: sig,
+		})
+		if err != nil {
+			panic(err)
+		}
+	}
+}
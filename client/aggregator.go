@@ -4,13 +4,16 @@ import (
 	"context"
 	"fmt"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/drand/drand/log"
 )
 
 const (
-	aggregatorWatchBuffer = 5
+	// defaultWatchBuffer is the default capacity of the channels returned by
+	// `Watch`.
+	defaultWatchBuffer = 5
 	// defaultAutoWatchRetry is the time after which the watch channel
 	// created by the autoWatch is re-opened when no context error occurred.
 	defaultAutoWatchRetry = time.Second * 30
@@ -32,28 +35,69 @@ func newWatchAggregator(c, wc Client, autoWatch bool, autoWatchRetry time.Durati
 		passiveClient:  wc,
 		autoWatch:      autoWatch,
 		autoWatchRetry: autoWatchRetry,
+		watchBuffer:    defaultWatchBuffer,
+		dropPolicy:     DropNewest,
 		log:            log.DefaultLogger(),
 		subscribers:    make([]subscriber, 0),
 	}
 	return aggregator
 }
 
+// DropPolicy decides what happens to a round that can not be delivered
+// because the channel returned by `Watch` is full.
+type DropPolicy int
+
+const (
+	// DropNewest discards the incoming round, keeping the buffered ones.
+	DropNewest DropPolicy = iota
+	// DropOldest discards the oldest buffered round to make room for the
+	// incoming one.
+	DropOldest
+	// Block waits for the consumer to read the channel. A slow consumer then
+	// delays the delivery of rounds to all the other watchers of the client.
+	Block
+)
+
+func (p DropPolicy) String() string {
+	switch p {
+	case DropNewest:
+		return "drop-newest"
+	case DropOldest:
+		return "drop-oldest"
+	case Block:
+		return "block"
+	default:
+		return fmt.Sprintf("DropPolicy(%d)", int(p))
+	}
+}
+
 type subscriber struct {
 	ctx context.Context
 	c   chan Result
 }
 
 type watchAggregator struct {
+	// dropped is accessed atomically, and kept first for 64-bit alignment.
+	dropped uint64
+
 	Client
 	passiveClient   Client
 	autoWatch       bool
 	autoWatchRetry  time.Duration
+	watchBuffer     int
+	dropPolicy      DropPolicy
 	log             log.Logger
 	cancelAutoWatch context.CancelFunc
+	// stats, when set, gathers the counters of the client.
+	stats *statsCollector
+	// events, when set, is told when the auto watch is restarted.
+	events *eventBus
 
-	subscriberLock sync.Mutex
-	subscribers    []subscriber
-	cancelPassive  context.CancelFunc
+	subscriberLock   sync.Mutex
+	subscribers      []subscriber
+	cancelPassive    context.CancelFunc
+	cancelDistribute context.CancelFunc
+	closed           bool
 }
 
 // Start initiates auto watching if configured to do so.
@@ -109,6 +153,7 @@ func (c *watchAggregator) startAutoWatch(full bool) {
 				t.Stop()
 			}
 			c.log.Info("watch_aggregator", "retrying auto watch")
+			c.events.emit(Event{Type: EventWatchReconnected})
 		}
 	}()
 }
@@ -140,7 +185,11 @@ func (c *watchAggregator) Watch(ctx context.Context) <-chan Result {
 	c.subscriberLock.Lock()
 	defer c.subscriberLock.Unlock()
 
-	sub := subscriber{ctx, make(chan Result, aggregatorWatchBuffer)}
+	sub := subscriber{ctx, make(chan Result, c.watchBuffer)}
+	if c.closed {
+		close(sub.c)
+		return sub.c
+	}
 	c.subscribers = append(c.subscribers, sub)
 
 	if len(c.subscribers) == 1 {
@@ -149,6 +198,7 @@ func (c *watchAggregator) Watch(ctx context.Context) <-chan Result {
 			c.cancelPassive = nil
 		}
 		ctx, cancel := context.WithCancel(context.Background())
+		c.cancelDistribute = cancel
 		go c.distribute(c.Client.Watch(ctx), cancel)
 	}
 	return sub.c
@@ -188,29 +238,101 @@ func (c *watchAggregator) distribute(in <-chan Result, cancel context.CancelFunc
 		for _, s := range curr {
 			if ok && s.ctx.Err() == nil {
 				c.subscribers = append(c.subscribers, s)
-				if m != nil {
-					select {
-					case s.c <- m:
-					default:
-						c.log.Warn("watch_aggregator", "dropped watch message to subscriber. full channel")
-					}
-				}
 			} else {
 				close(s.c)
 			}
 		}
+		// deliver outside of the lock, so that a blocking delivery does not
+		// prevent new watchers from subscribing.
+		active := append([]subscriber(nil), c.subscribers...)
 		c.subscriberLock.Unlock()
 
 		if !ok {
 			return
 		}
+		if m != nil {
+			for _, s := range active {
+				c.deliver(s, m)
+			}
+		}
+	}
+}
+
+// deliver sends a round to a subscriber according to the drop policy.
+func (c *watchAggregator) deliver(s subscriber, m Result) {
+	switch c.dropPolicy {
+	case Block:
+		select {
+		case s.c <- m:
+		case <-s.ctx.Done():
+		}
+	case DropOldest:
+		for {
+			select {
+			case s.c <- m:
+				return
+			default:
+			}
+			select {
+			case old := <-s.c:
+				c.drop(old)
+			default:
+			}
+		}
+	default:
+		select {
+		case s.c <- m:
+		default:
+			c.drop(m)
+		}
 	}
 }
 
+func (c *watchAggregator) drop(m Result) {
+	atomic.AddUint64(&c.dropped, 1)
+	c.log.Warn("watch_aggregator", "dropped watch message to subscriber. full channel", "round", m.Round(), "policy", c.dropPolicy)
+}
+
+// DroppedRounds returns the number of rounds dropped from the channels
+// returned by `Watch` because they were full.
+func (c *watchAggregator) DroppedRounds() uint64 {
+	return atomic.LoadUint64(&c.dropped)
+}
+
+// Stats returns a snapshot of the runtime counters of the client.
+func (c *watchAggregator) Stats() Stats {
+	if c.stats == nil {
+		return Stats{}
+	}
+	return c.stats.snapshot()
+}
+
+// DroppedRounds returns the number of rounds a client created with `New`
+// dropped from the channels returned by its `Watch` because they were full.
+func DroppedRounds(c Client) uint64 {
+	if dc, ok := c.(interface{ DroppedRounds() uint64 }); ok {
+		return dc.DroppedRounds()
+	}
+	return 0
+}
+
+// Close ends the watches of the client, whose channels get closed, stops
+// the auto watch and closes the wrapped client.
 func (c *watchAggregator) Close() error {
-	err := c.Client.Close()
+	c.subscriberLock.Lock()
+	c.closed = true
+	if c.cancelPassive != nil {
+		c.cancelPassive()
+		c.cancelPassive = nil
+	}
+	if c.cancelDistribute != nil {
+		c.cancelDistribute()
+		c.cancelDistribute = nil
+	}
+	c.subscriberLock.Unlock()
+
 	if c.cancelAutoWatch != nil {
 		c.cancelAutoWatch()
 	}
-	return err
+	return c.Client.Close()
 }
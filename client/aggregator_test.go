@@ -1,6 +1,7 @@
 package client
 
 import (
+	"context"
 	"sync"
 	"testing"
 	"time"
@@ -30,6 +31,36 @@ func TestAggregatorClose(t *testing.T) {
 	wg.Wait() // wait for underlying client to close
 }
 
+func TestAggregatorCloseEndsWatches(t *testing.T) {
+	c := &MockClient{
+		WatchF: func(ctx context.Context) <-chan Result {
+			ch := make(chan Result)
+			go func() {
+				<-ctx.Done()
+				close(ch)
+			}()
+			return ch
+		},
+	}
+	ac := newWatchAggregator(c, nil, false, 0)
+	results := ac.Watch(context.Background())
+
+	if err := ac.Close(); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case _, ok := <-results:
+		if ok {
+			t.Fatal("unexpected result")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("watch should end when the client is closed")
+	}
+	if _, ok := <-ac.Watch(context.Background()); ok {
+		t.Fatal("watch of a closed client should end immediately")
+	}
+}
+
 func TestAggregatorPassive(t *testing.T) {
 	wg := sync.WaitGroup{}
 	wg.Add(1)
@@ -79,3 +110,68 @@ func TestAggregatorPassive(t *testing.T) {
 
 	wg.Wait()
 }
+
+func TestAggregatorDropPolicy(t *testing.T) {
+	tests := []struct {
+		policy   DropPolicy
+		expected []uint64
+		dropped  uint64
+	}{
+		{DropNewest, []uint64{1, 2}, 2},
+		{DropOldest, []uint64{3, 4}, 2},
+	}
+	for _, test := range tests {
+		t.Run(test.policy.String(), func(t *testing.T) {
+			c := &MockClient{WatchCh: make(chan Result)}
+			ac := newWatchAggregator(c, nil, false, 0)
+			ac.watchBuffer = 2
+			ac.dropPolicy = test.policy
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			ch := ac.Watch(ctx)
+			for i := uint64(1); i <= 4; i++ {
+				c.WatchCh <- &mock.Result{Rnd: i}
+			}
+			// wait for the last round to be delivered
+			time.Sleep(50 * time.Millisecond)
+
+			for _, expected := range test.expected {
+				if r := nextResult(t, ch); r.Round() != expected {
+					t.Fatalf("expected round %d, got %d", expected, r.Round())
+				}
+			}
+			if d := DroppedRounds(ac); d != test.dropped {
+				t.Fatalf("expected %d dropped rounds, got %d", test.dropped, d)
+			}
+		})
+	}
+}
+
+func TestAggregatorBlockPolicy(t *testing.T) {
+	c := &MockClient{WatchCh: make(chan Result)}
+	ac := newWatchAggregator(c, nil, false, 0)
+	ac.watchBuffer = 1
+	ac.dropPolicy = Block
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	ch := ac.Watch(ctx)
+
+	sent := make(chan struct{})
+	go func() {
+		defer close(sent)
+		for i := uint64(1); i <= 3; i++ {
+			c.WatchCh <- &mock.Result{Rnd: i}
+		}
+	}()
+	for i := uint64(1); i <= 3; i++ {
+		if r := nextResult(t, ch); r.Round() != i {
+			t.Fatalf("expected round %d, got %d", i, r.Round())
+		}
+	}
+	<-sent
+	if d := DroppedRounds(ac); d != 0 {
+		t.Fatalf("expected no dropped round, got %d", d)
+	}
+}
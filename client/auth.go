@@ -0,0 +1,15 @@
+package client
+
+import "context"
+
+// TokenProvider returns the token authenticating the requests made to a
+// source, such as a relay behind an authentication gateway. It is called
+// before every request, so that tokens can be refreshed as they expire.
+type TokenProvider func(ctx context.Context) (string, error)
+
+// StaticToken returns a TokenProvider always returning `token`.
+func StaticToken(token string) TokenProvider {
+	return func(context.Context) (string, error) {
+		return token, nil
+	}
+}
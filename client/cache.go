@@ -3,13 +3,17 @@ package client
 import (
 	"context"
 	"fmt"
+	"io"
+	"sync/atomic"
 
 	"github.com/drand/drand/log"
 
 	lru "github.com/hashicorp/golang-lru"
 )
 
-// Cache provides a mechanism to check for rounds in the cache.
+// Cache provides a mechanism to check for rounds in the cache. Caches also
+// implementing io.Closer, such as persistent caches, are closed along with the
+// client using them so that they can flush their content.
 type Cache interface {
 	// TryGet provides a round beacon or nil if it is not cached.
 	TryGet(round uint64) Result
@@ -70,10 +74,18 @@ func NewCachingClient(client Client, cache Cache) (Client, error) {
 }
 
 type cachingClient struct {
+	// hits and misses are accessed atomically, and kept first for 64-bit
+	// alignment.
+	hits   uint64
+	misses uint64
+
 	Client
 
 	cache Cache
 	log   log.Logger
+	// flights coalesces the concurrent requests for rounds missing from the
+	// cache.
+	flights flightGroup
 }
 
 // SetLog configures the client log output
@@ -86,19 +98,26 @@ func (c *cachingClient) String() string {
 	if arc, ok := c.cache.(*typedCache); ok {
 		return fmt.Sprintf("%s.(+%d el cache)", c.Client, arc.ARCCache.Len())
 	}
-	return fmt.Sprintf("%s.(+nil cache)", c.Client)
+	if _, ok := c.cache.(*nilCache); ok {
+		return fmt.Sprintf("%s.(+nil cache)", c.Client)
+	}
+	return fmt.Sprintf("%s.(+cache)", c.Client)
 }
 
 // Get returns the randomness at `round` or an error.
 func (c *cachingClient) Get(ctx context.Context, round uint64) (res Result, err error) {
 	if val := c.cache.TryGet(round); val != nil {
+		atomic.AddUint64(&c.hits, 1)
 		return val, nil
 	}
-	val, err := c.Client.Get(ctx, round)
-	if err == nil && val != nil {
-		c.cache.Add(val.Round(), val)
-	}
-	return val, err
+	atomic.AddUint64(&c.misses, 1)
+	return c.flights.get(ctx, round, func(ctx context.Context) (Result, error) {
+		val, err := c.Client.Get(ctx, round)
+		if err == nil && val != nil {
+			c.cache.Add(val.Round(), val)
+		}
+		return val, err
+	})
 }
 
 func (c *cachingClient) Watch(ctx context.Context) <-chan Result {
@@ -114,6 +133,13 @@ func (c *cachingClient) Watch(ctx context.Context) <-chan Result {
 	return out
 }
 
+// Close closes the wrapped client, then the cache if it can be closed.
 func (c *cachingClient) Close() error {
-	return c.Client.Close()
+	err := c.Client.Close()
+	if cc, ok := c.cache.(io.Closer); ok {
+		if cerr := cc.Close(); err == nil {
+			err = cerr
+		}
+	}
+	return err
 }
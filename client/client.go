@@ -4,13 +4,11 @@ import (
 	"bytes"
 	"context"
 	"errors"
+	"fmt"
 	"time"
 
 	"github.com/drand/drand/chain"
 	"github.com/drand/drand/log"
-	"github.com/drand/drand/metrics"
-
-	"github.com/prometheus/client_golang/prometheus"
 )
 
 const clientStartupTimeoutDefault = time.Second * 5
@@ -43,18 +41,81 @@ func trySetLog(c Client, l log.Logger) {
 
 // makeClient creates a client from a configuration.
 func makeClient(cfg *clientConfig) (Client, error) {
+	if cfg.pinStore != nil {
+		if err := cfg.pinChainInfo(); err != nil {
+			return nil, err
+		}
+	}
 	if !cfg.insecure && cfg.chainHash == nil && cfg.chainInfo == nil {
 		return nil, errors.New("no root of trust specified")
 	}
-	if len(cfg.clients) == 0 && cfg.watcher == nil {
+	if len(cfg.clients) == 0 && cfg.watcher == nil && cfg.discovery == nil {
 		return nil, errors.New("no points of contact specified")
 	}
 
+	// the signature transition of the chain applies unless overridden.
+	if cfg.v2from == 0 && cfg.chainInfo != nil {
+		cfg.v2from = cfg.chainInfo.V2From
+	}
 	if cfg.fullVerify && cfg.v2from == 0 {
 		return nil, errors.New("fullVerify is deprecated for v2 only chain")
 	}
 
 	var err error
+	cfg.stats = new(statsCollector)
+
+	if cfg.prefetchPage >= 0 {
+		cfg.prefetcher = &prefetcher{page: cfg.prefetchPage}
+		if cfg.prefetcher.page == 0 {
+			cfg.prefetcher.page = defaultPrefetchPage
+		}
+		for _, c := range cfg.clients {
+			if rc, ok := c.(RangeClient); ok {
+				if cfg.rateLimit > 0 {
+					rc = &rateLimitedRangeClient{RangeClient: rc, bucket: cfg.rateBucket(c)}
+				}
+				cfg.prefetcher.sources = append(cfg.prefetcher.sources, rc)
+			}
+		}
+	}
+
+	var handoverSources []HandoverSource
+	if cfg.handoverConnect != nil {
+		for _, c := range cfg.clients {
+			if hs, ok := c.(HandoverSource); ok {
+				handoverSources = append(handoverSources, hs)
+			}
+		}
+	}
+
+	if cfg.newSharedCache != nil {
+		if err := cfg.makeSharedCache(); err != nil {
+			return nil, err
+		}
+	}
+
+	sources := make([]Client, 0, len(cfg.clients))
+	for _, c := range cfg.clients {
+		sources = append(sources, cfg.wrapSource(c))
+	}
+	cfg.clients = sources
+
+	if cfg.discovery != nil {
+		cfg.sources, err = discoverSources(cfg, cfg.wrapSource)
+		if err != nil {
+			return nil, err
+		}
+		cfg.clients = append(cfg.clients, cfg.sources.list()...)
+	}
+	if len(cfg.clients) == 0 && cfg.watcher == nil {
+		return nil, errors.New("no points of contact specified")
+	}
+
+	if cfg.checkpoint != nil {
+		if err := cfg.trustCheckpoint(); err != nil {
+			return nil, err
+		}
+	}
 
 	// provision cache
 	cache, err := makeCache(cfg.cacheSize)
@@ -80,11 +141,14 @@ func makeClient(cfg *clientConfig) (Client, error) {
 
 	verifiers := make([]Client, 0, len(cfg.clients))
 	for _, source := range cfg.clients {
-		nv := newVerifyingClient(source, cfg.previousResult, cfg.fullVerify, cfg.v2from)
+		nv := cfg.newVerifier(source)
 		verifiers = append(verifiers, nv)
 		if source == wc {
 			wc = nv
 		}
+		if cfg.sources != nil {
+			cfg.sources.track(source, nv)
+		}
 	}
 
 	c, err = makeOptimizingClient(cfg, verifiers, wc, cache)
@@ -92,7 +156,32 @@ func makeClient(cfg *clientConfig) (Client, error) {
 		return nil, err
 	}
 
+	if cfg.watchdogPeriods > 0 {
+		wd := newWatchdogClient(c, cfg.watchdogPeriods, cfg.onWatchGap)
+		wd.events = cfg.events
+		c = wd
+		trySetLog(c, cfg.log)
+	}
+	if cfg.continuityCheck {
+		c = newContinuityClient(c, cfg.v2from, cfg.onContinuityViolation)
+		trySetLog(c, cfg.log)
+	}
+	if cfg.clockDriftCheck {
+		c = newClockDriftClient(c, cfg.clockDriftThreshold, cfg.onClockDrift)
+		trySetLog(c, cfg.log)
+	}
+	if cfg.handoverConnect != nil {
+		c = newHandoverClient(c, cfg, handoverSources)
+		trySetLog(c, cfg.log)
+	}
+
 	wa := newWatchAggregator(c, wc, cfg.autoWatch, cfg.autoWatchRetry)
+	if cfg.watchBuffer > 0 {
+		wa.watchBuffer = cfg.watchBuffer
+	}
+	wa.dropPolicy = cfg.dropPolicy
+	wa.stats = cfg.stats
+	wa.events = cfg.events
 	c = wa
 	trySetLog(c, cfg.log)
 
@@ -106,6 +195,9 @@ func makeOptimizingClient(cfg *clientConfig, verifiers []Client, watcher Client,
 	if err != nil {
 		return nil, err
 	}
+	oc.hedgeDelay = cfg.hedgeDelay
+	cfg.stats.oc = oc
+	oc.events = cfg.events
 	if watcher != nil {
 		oc.MarkPassive(watcher)
 	}
@@ -117,17 +209,94 @@ func makeOptimizingClient(cfg *clientConfig, verifiers []Client, watcher Client,
 		if err != nil {
 			return nil, err
 		}
+		cfg.stats.cache = c.(*cachingClient)
 		trySetLog(c, cfg.log)
 	}
+	// chain info is shared by the verifiers and the wrappers above.
+	if cfg.infoTTL >= 0 {
+		ttl := cfg.infoTTL
+		if ttl == 0 {
+			ttl = defaultInfoTTL
+		}
+		ic := newInfoCachingClient(c, newInfoCache(ttl))
+		for _, v := range verifiers {
+			v.(*verifyingClient).infoCache = ic.cache
+		}
+		c = ic
+	}
+	var sharedInfo *infoCache
+	if ic, ok := c.(*infoCachingClient); ok {
+		sharedInfo = ic.cache
+	}
 	for _, v := range verifiers {
 		trySetLog(v, cfg.log)
 		v.(*verifyingClient).indirectClient = c
 	}
 
 	oc.Start()
+	if cfg.sources != nil {
+		go cfg.sources.run(oc, func(source Client) Client {
+			nv := cfg.newVerifier(source)
+			nv.indirectClient = c
+			nv.infoCache = sharedInfo
+			trySetLog(nv, cfg.log)
+			return nv
+		})
+	}
 	return c, nil
 }
 
+// newVerifier wraps a source with the verification of its results.
+func (c *clientConfig) newVerifier(source Client) *verifyingClient {
+	v := newVerifyingClient(source, c.previousResult, c.fullVerify, c.v2from).(*verifyingClient)
+	v.prefetcher = c.prefetcher
+	v.progress = c.progress
+	v.verifyCache = c.verifyCache
+	v.stats = c.stats
+	v.events = c.events
+	return v
+}
+
+// wrapSource wraps a source of randomness with the checks and limits applying
+// to all sources.
+func (c *clientConfig) wrapSource(source Client) Client {
+	// the rate limit only applies to the requests reaching the source, not
+	// to the rounds or the info served by the caches.
+	if c.rateLimit > 0 {
+		source = newRateLimitedClient(source, c.rateBucket(source))
+	}
+	if c.sharedCache != nil {
+		source = &cachingClient{Client: source, cache: c.sharedCache, log: c.log}
+	}
+	if c.chainHash != nil || c.chainInfo != nil {
+		tc := newTrustedInfoClient(source, c.chainHash, c.chainInfo)
+		tc.pinned = c.pinned
+		if c.verifiedInfo == nil {
+			c.verifiedInfo = tc.verified
+		}
+		tc.verified = c.verifiedInfo
+		source = tc
+	}
+	if c.stats != nil {
+		source = c.stats.count(source)
+	}
+	trySetLog(source, c.log)
+	return source
+}
+
+// rateBucket returns the token bucket limiting the requests sent to a source.
+func (c *clientConfig) rateBucket(source Client) *tokenBucket {
+	if c.rateBuckets == nil {
+		c.rateBuckets = make(map[Client]*tokenBucket)
+	}
+	b, ok := c.rateBuckets[source]
+	if !ok {
+		b = newTokenBucket(c.rateLimit, c.rateBurst)
+		c.rateBuckets[source] = b
+	}
+	return b
+}
+
 func makeWatcherClient(cfg *clientConfig, cache Cache) (Client, error) {
 	if err := cfg.tryPopulateInfo(cfg.clients...); err != nil {
 		return nil, err
@@ -140,19 +309,6 @@ func makeWatcherClient(cfg *clientConfig, cache Cache) (Client, error) {
 	return &watcherClient{ec, w}, nil
 }
 
-func attachMetrics(cfg *clientConfig, c Client) (Client, error) {
-	if cfg.prometheus != nil {
-		if err := metrics.RegisterClientMetrics(cfg.prometheus); err != nil {
-			return nil, err
-		}
-		if err := cfg.tryPopulateInfo(c); err != nil {
-			return nil, err
-		}
-		return newWatchLatencyMetricClient(c, cfg.chainInfo), nil
-	}
-	return c, nil
-}
-
 type clientConfig struct {
 	// clients is the set of options for fetching randomness
 	clients []Client
@@ -184,7 +340,78 @@ type clientConfig struct {
 	// created by the autoWatch is re-opened when no context error occurred.
 	autoWatchRetry time.Duration
 	// prometheus is an interface to a Prometheus system
-	prometheus prometheus.Registerer
+	prometheus metricsRegisterer
+	// pinStore persists the chain info trusted on first use.
+	pinStore PinStore
+	// pinned indicates chainInfo was loaded from or saved to pinStore.
+	pinned bool
+	// verifiedInfo is the chain information of the root of trust, shared by
+	// the sources.
+	verifiedInfo *verifiedInfo
+	// newSharedCache makes the cache of the rounds of the sources shared with
+	// other clients, kept in sharedCache.
+	newSharedCache func(chainHash []byte) (Cache, error)
+	sharedCache    Cache
+	// rateLimit is the number of requests per second allowed to each
+	// source, 0 meaning unlimited.
+	rateLimit float64
+	// rateBurst is the number of requests a source may receive at once.
+	rateBurst int
+	// rateBuckets holds the token bucket of each rate limited source.
+	rateBuckets map[Client]*tokenBucket
+	// hedgeDelay is the time after which a pending Get is also sent to the
+	// next fastest source, 0 disabling hedged requests.
+	hedgeDelay time.Duration
+	// watchBuffer is the capacity of the channels returned by Watch, 0
+	// meaning the default.
+	watchBuffer int
+	// dropPolicy decides what happens to rounds when a Watch channel is full.
+	dropPolicy DropPolicy
+	// discovery provides sources in addition to clients.
+	discovery Discovery
+	// discoveryInterval is how often the discovery is refreshed.
+	discoveryInterval time.Duration
+	// sources tracks the sources created from discovery.
+	sources *discoveredSources
+	// prefetchPage is the number of rounds fetched at once ahead of chain
+	// walks, 0 meaning the default and a negative value disabling prefetch.
+	prefetchPage int
+	// prefetcher is shared by the verifiers.
+	prefetcher *prefetcher
+	// progress is told about the progress of chain walks.
+	progress func(current, target uint64)
+	// verifyCache is shared by the verifiers, and possibly other clients.
+	verifyCache *VerificationCache
+	// watchdogPeriods is the number of periods without a round after which a
+	// watch is restarted, 0 disabling the watchdog.
+	watchdogPeriods int
+	// onWatchGap is told about the rounds missed by a restarted watch.
+	onWatchGap func(WatchGap)
+	// continuityCheck checks the continuity of the rounds delivered by Watch.
+	continuityCheck bool
+	// onContinuityViolation is told about discontinuities of Watch.
+	onContinuityViolation func(*ContinuityError)
+	// clockDriftCheck checks the delivery times of the rounds of Watch.
+	clockDriftCheck bool
+	// clockDriftThreshold is the drift of the rounds warned about, a period
+	// when 0.
+	clockDriftThreshold time.Duration
+	// onClockDrift is told about the rounds of Watch delivered off schedule.
+	onClockDrift func(*ClockDrift)
+	// events dispatches the events of the client to its handlers.
+	events *eventBus
+	// stats gathers the counters of the clients composing the client.
+	stats *statsCollector
+	// handoverConnect creates the sources of the chain taking over, nil
+	// disabling handovers.
+	handoverConnect func(*chain.Info) ([]Client, error)
+	// handoverInterval is how often sources are asked for a handover.
+	handoverInterval time.Duration
+	// checkpoint is a signed beacon the verification starts from.
+	checkpoint *chain.Checkpoint
+	// infoTTL is how long the chain info is cached, 0 meaning the default
+	// and a negative value disabling the cache.
+	infoTTL time.Duration
 }
 
 func (c *clientConfig) tryPopulateInfo(clients ...Client) (err error) {
@@ -204,6 +431,59 @@ func (c *clientConfig) tryPopulateInfo(clients ...Client) (err error) {
 	return
 }
 
+// makeSharedCache makes the shared cache for the chain of the root of trust.
+// An insecure client gets the chain info of its sources, and is then bound to
+// their chain.
+func (c *clientConfig) makeSharedCache() error {
+	hash := c.chainHash
+	if c.chainInfo != nil {
+		hash = c.chainInfo.Hash()
+	}
+	if hash == nil {
+		if err := c.tryPopulateInfo(c.clients...); err != nil {
+			return fmt.Errorf("could not get chain info to key the shared cache: %w", err)
+		}
+		if c.chainInfo == nil {
+			return errors.New("no chain info to key the shared cache")
+		}
+		hash = c.chainInfo.Hash()
+	}
+	cache, err := c.newSharedCache(hash)
+	if err != nil {
+		return err
+	}
+	c.sharedCache = cache
+	return nil
+}
+
+// trustCheckpoint verifies the checkpoint against the chain info and makes
+// it the verified result chain walks start from.
+func (c *clientConfig) trustCheckpoint() error {
+	if err := c.tryPopulateInfo(c.clients...); err != nil {
+		return fmt.Errorf("could not get chain info to verify checkpoint: %w", err)
+	}
+	if c.chainInfo == nil {
+		return errors.New("no chain info to verify checkpoint")
+	}
+	if err := c.checkpoint.Verify(c.chainInfo); err != nil {
+		return fmt.Errorf("invalid checkpoint: %w", err)
+	}
+	if c.previousResult != nil && c.previousResult.Round() >= c.checkpoint.Round {
+		return nil
+	}
+	random, err := c.chainInfo.Randomness(c.checkpoint.Signature)
+	if err != nil {
+		return fmt.Errorf("invalid checkpoint: %w", err)
+	}
+	c.previousResult = &RandomData{
+		Rnd:               c.checkpoint.Round,
+		Random:            random,
+		Sig:               c.checkpoint.Signature,
+		PreviousSignature: c.checkpoint.PreviousSignature,
+	}
+	return nil
+}
+
 // Option is an option configuring a client.
 type Option func(cfg *clientConfig) error
 
@@ -233,6 +513,17 @@ func WithCacheSize(size int) Option {
 	}
 }
 
+// WithSharedCache caches the rounds of the sources in a cache shared with other
+// clients, such as the replicas of a relay, made by newCache for the hash of
+// the chain of the client. The cache sits below the verification of the
+// rounds: the rounds it serves are verified like the ones of the sources.
+func WithSharedCache(newCache func(chainHash []byte) (Cache, error)) Option {
+	return func(cfg *clientConfig) error {
+		cfg.newSharedCache = newCache
+		return nil
+	}
+}
+
 // WithLogger overrides the logging options for the client,
 // allowing specification of additional tags, or redirection / configuration
 // of logging level and output.
@@ -267,6 +558,49 @@ func WithChainInfo(chainInfo *chain.Info) Option {
 	}
 }
 
+// WithTrustOnFirstUse pins the chain information served the first time the
+// client is created into the given store. Subsequent clients using the same
+// store root their trust in the pinned information and refuse any source
+// serving different parameters with a PinMismatchError.
+func WithTrustOnFirstUse(store PinStore) Option {
+	return func(cfg *clientConfig) error {
+		cfg.pinStore = store
+		return nil
+	}
+}
+
+// WithRateLimit limits the number of requests sent to each source of
+// randomness to `limit` per second, allowing bursts of up to `burst` requests.
+// The calls to `Get`, `Info` and `Watch`, including the watches reconnecting,
+// and the pages of rounds prefetched all count against the limit of their
+// source. Requests exceeding the limit wait until they are allowed, or until
+// their context is done.
+func WithRateLimit(limit float64, burst int) Option {
+	return func(cfg *clientConfig) error {
+		if limit <= 0 || burst < 1 {
+			return errors.New("rate limit and burst must be positive")
+		}
+		cfg.rateLimit = limit
+		cfg.rateBurst = burst
+		return nil
+	}
+}
+
+// WithHedgedRequests makes `Get` query the fastest source first, and send the
+// same request to the next fastest source whenever the pending requests got
+// no answer after `delay`, or failed. The first valid answer wins and the other
+// requests are canceled. This bounds tail latency while keeping the load on
+// sources low.
+func WithHedgedRequests(delay time.Duration) Option {
+	return func(cfg *clientConfig) error {
+		if delay <= 0 {
+			return errors.New("hedge delay must be positive")
+		}
+		cfg.hedgeDelay = delay
+		return nil
+	}
+}
+
 // WithVerifiedResult provides a checkpoint of randomness verified at a given round.
 // Used in combination with `VerifyFullChain`, this allows for catching up only on
 // previously not-yet-verified results.
@@ -280,6 +614,21 @@ func WithVerifiedResult(result Result) Option {
 	}
 }
 
+// WithCheckpoint makes the client trust a signed checkpoint, typically
+// produced by a synced node with `chain.CheckpointFromStore`, as the starting
+// point of its chain walks. The checkpoint is verified against the chain info
+// when the client is created, so that light clients using full chain
+// verification only need to sync the rounds following it.
+func WithCheckpoint(cp *chain.Checkpoint) Option {
+	return func(cfg *clientConfig) error {
+		if cp == nil {
+			return errors.New("nil checkpoint")
+		}
+		cfg.checkpoint = cp
+		return nil
+	}
+}
+
 // WithFullChainVerification validates random beacons not just as being generated correctly
 // from the group signature, but ensures that the full chain is deterministic by making sure
 // each round is derived correctly from the previous one. In cases of compromise where
@@ -330,10 +679,65 @@ func WithAutoWatchRetry(interval time.Duration) Option {
 	}
 }
 
-// WithPrometheus specifies a registry into which to report metrics
-func WithPrometheus(r prometheus.Registerer) Option {
+// WithWatchBuffer sets the capacity of the channels returned by `Watch`, and
+// the policy applied when a consumer does not keep up and its channel is full.
+// By default channels hold 5 rounds and the newest rounds are dropped. The
+// number of dropped rounds is reported by `DroppedRounds`.
+func WithWatchBuffer(size int, policy DropPolicy) Option {
+	return func(cfg *clientConfig) error {
+		if size <= 0 {
+			return errors.New("watch buffer size must be positive")
+		}
+		switch policy {
+		case DropNewest, DropOldest, Block:
+		default:
+			return fmt.Errorf("unknown drop policy %v", policy)
+		}
+		cfg.watchBuffer = size
+		cfg.dropPolicy = policy
+		return nil
+	}
+}
+
+// WithVerificationProgress sets a function told about the progress of the
+// chain walks of strict verification: it is called with the last verified
+// round and the round the walk is heading to, at most once per second and
+// once the walk completes. It must return quickly, as it blocks the walk.
+func WithVerificationProgress(progress func(current, target uint64)) Option {
+	return func(cfg *clientConfig) error {
+		cfg.progress = progress
+		return nil
+	}
+}
+
+// WithVerificationCache makes the client skip the verification of rounds
+// already verified by any client sharing the same cache.
+func WithVerificationCache(cache *VerificationCache) Option {
+	return func(cfg *clientConfig) error {
+		cfg.verifyCache = cache
+		return nil
+	}
+}
+
+// WithPrefetch sets the number of historical rounds fetched at once ahead of
+// the verifier when it walks the chain in strict mode, 100 by default. Pages
+// are fetched in a single request from the sources implementing RangeClient,
+// and with concurrent requests otherwise. A negative page size disables
+// prefetching, so that rounds are fetched one after the other.
+func WithPrefetch(page int) Option {
+	return func(cfg *clientConfig) error {
+		cfg.prefetchPage = page
+		return nil
+	}
+}
+
+// WithInfoTTL sets how long the chain info fetched from the sources is reused
+// before being fetched again, one hour by default. The cached info is also
+// refreshed whenever a round fails verification. A negative TTL disables the
+// cache, so that the info is fetched for every request.
+func WithInfoTTL(ttl time.Duration) Option {
 	return func(cfg *clientConfig) error {
-		cfg.prometheus = r
+		cfg.infoTTL = ttl
 		return nil
 	}
 }
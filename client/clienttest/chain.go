@@ -0,0 +1,72 @@
+package clienttest
+
+import (
+	"crypto/sha256"
+	"time"
+
+	"github.com/drand/drand/chain"
+	"github.com/drand/drand/client"
+	"github.com/drand/drand/key"
+	"github.com/drand/kyber"
+	"github.com/drand/kyber/share"
+	"github.com/drand/kyber/sign/tbls"
+)
+
+// Chain is a fake beacon chain whose key and rounds are derived from a seed.
+// Rounds are signed independently of each other, as in a v2 chain, so any
+// round can be produced without computing the ones before it.
+type Chain struct {
+	secret kyber.Scalar
+	info   *chain.Info
+}
+
+// NewChain creates the chain derived from `seed`, producing a round every
+// `period` from `genesis` on. The period is rounded down to the second.
+func NewChain(seed []byte, period time.Duration, genesis time.Time) *Chain {
+	secret := key.KeyGroup.Scalar().SetBytes(hash(seed, []byte("secret")))
+	return &Chain{
+		secret: secret,
+		info: &chain.Info{
+			PublicKey:   key.KeyGroup.Point().Mul(secret, nil),
+			Period:      period.Truncate(time.Second),
+			GenesisTime: genesis.Unix(),
+			GroupHash:   hash(seed, []byte("group")),
+		},
+	}
+}
+
+// Info returns the information of the chain.
+func (c *Chain) Info() *chain.Info {
+	return c.info
+}
+
+// Result returns the given round of the chain.
+func (c *Chain) Result(round uint64) *client.RandomData {
+	sig, err := key.Scheme.Sign(&share.PriShare{I: 0, V: c.secret}, chain.MessageV2(round))
+	if err != nil {
+		panic(err)
+	}
+	share := tbls.SigShare(sig)
+	sigV2 := share.Value()
+	return &client.RandomData{
+		Rnd:    round,
+		Random: chain.RandomnessFromSignature(sigV2),
+		SigV2:  sigV2,
+	}
+}
+
+// CorruptResult returns the given round of the chain with a signature that
+// does not verify.
+func (c *Chain) CorruptResult(round uint64) *client.RandomData {
+	r := c.Result(round)
+	r.SigV2 = c.Result(round + 1).SigV2
+	return r
+}
+
+func hash(parts ...[]byte) []byte {
+	h := sha256.New()
+	for _, p := range parts {
+		_, _ = h.Write(p)
+	}
+	return h.Sum(nil)
+}
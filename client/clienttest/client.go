@@ -0,0 +1,209 @@
+package clienttest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/drand/drand/chain"
+	"github.com/drand/drand/client"
+
+	"github.com/jonboulle/clockwork"
+)
+
+// ErrClosed is returned by the calls made on a closed client.
+var ErrClosed = errors.New("client closed")
+
+// ErrNotProduced is returned when requesting a round the chain did not
+// produce yet at the time of the clock of the client.
+var ErrNotProduced = errors.New("round not produced yet")
+
+// Client serves the rounds of a Chain as they are produced according to its
+// clock. It implements the client.Client interface.
+type Client struct {
+	chain *Chain
+	clock clockwork.Clock
+
+	lk       sync.Mutex
+	rounds   map[uint64]error
+	corrupt  map[uint64]bool
+	next     []error
+	infoErr  error
+	closed   bool
+	done     chan struct{}
+	getCalls int
+}
+
+// NewClient creates a client serving the rounds of the chain, using the given
+// clock to decide which rounds are produced. A nil clock uses the real time.
+func NewClient(c *Chain, clock clockwork.Clock) *Client {
+	if clock == nil {
+		clock = clockwork.NewRealClock()
+	}
+	return &Client{
+		chain:   c,
+		clock:   clock,
+		rounds:  make(map[uint64]error),
+		corrupt: make(map[uint64]bool),
+		done:    make(chan struct{}),
+	}
+}
+
+// FailRound makes every request for `round` fail with `err`. The round is
+// also skipped by Watch. A nil error makes the round available again.
+func (c *Client) FailRound(round uint64, err error) {
+	c.lk.Lock()
+	defer c.lk.Unlock()
+	if err == nil {
+		delete(c.rounds, round)
+		return
+	}
+	c.rounds[round] = err
+}
+
+// FailNext makes the next calls to Get fail with the given errors, in order.
+func (c *Client) FailNext(errs ...error) {
+	c.lk.Lock()
+	defer c.lk.Unlock()
+	c.next = append(c.next, errs...)
+}
+
+// FailInfo makes the calls to Info fail with `err`, nil restoring them.
+func (c *Client) FailInfo(err error) {
+	c.lk.Lock()
+	defer c.lk.Unlock()
+	c.infoErr = err
+}
+
+// CorruptRound makes the client serve `round` with an invalid signature, both
+// from Get and Watch.
+func (c *Client) CorruptRound(round uint64) {
+	c.lk.Lock()
+	defer c.lk.Unlock()
+	c.corrupt[round] = true
+}
+
+// GetCalls returns the number of calls made to Get.
+func (c *Client) GetCalls() int {
+	c.lk.Lock()
+	defer c.lk.Unlock()
+	return c.getCalls
+}
+
+// result returns the given round as served by the client.
+func (c *Client) result(round uint64) (client.Result, error) {
+	c.lk.Lock()
+	defer c.lk.Unlock()
+	if c.closed {
+		return nil, ErrClosed
+	}
+	if err, ok := c.rounds[round]; ok {
+		return nil, err
+	}
+	if c.corrupt[round] {
+		return c.chain.CorruptResult(round), nil
+	}
+	return c.chain.Result(round), nil
+}
+
+// Get returns the randomness at `round`, 0 being the latest produced round.
+func (c *Client) Get(ctx context.Context, round uint64) (client.Result, error) {
+	c.lk.Lock()
+	c.getCalls++
+	if len(c.next) > 0 {
+		err := c.next[0]
+		c.next = c.next[1:]
+		c.lk.Unlock()
+		return nil, err
+	}
+	c.lk.Unlock()
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	latest := c.RoundAt(c.clock.Now())
+	if round == 0 {
+		round = latest
+	}
+	if round > latest {
+		return nil, fmt.Errorf("%w: round %d, latest %d", ErrNotProduced, round, latest)
+	}
+	return c.result(round)
+}
+
+// Watch returns the rounds as they are produced, starting with the next one.
+// Failed rounds are skipped, and the channel is closed when the context is
+// done or the client is closed.
+func (c *Client) Watch(ctx context.Context) <-chan client.Result {
+	out := make(chan client.Result)
+	go func() {
+		defer close(out)
+		info := c.chain.Info()
+		round := c.RoundAt(c.clock.Now()) + 1
+		for {
+			wait := info.TimeOfRound(round).Sub(c.clock.Now())
+			if wait > 0 {
+				select {
+				case <-c.clock.After(wait):
+				case <-ctx.Done():
+					return
+				case <-c.done:
+					return
+				}
+			}
+			r, err := c.result(round)
+			round++
+			if errors.Is(err, ErrClosed) {
+				return
+			}
+			if err != nil {
+				continue
+			}
+			select {
+			case out <- r:
+			case <-ctx.Done():
+				return
+			case <-c.done:
+				return
+			}
+		}
+	}()
+	return out
+}
+
+// Info returns the information of the chain.
+func (c *Client) Info(ctx context.Context) (*chain.Info, error) {
+	c.lk.Lock()
+	defer c.lk.Unlock()
+	if c.closed {
+		return nil, ErrClosed
+	}
+	if c.infoErr != nil {
+		return nil, c.infoErr
+	}
+	return c.chain.Info(), nil
+}
+
+// RoundAt returns the round of the chain produced at `t`.
+func (c *Client) RoundAt(t time.Time) uint64 {
+	info := c.chain.Info()
+	return info.RoundAt(t)
+}
+
+// Close stops the watches of the client, and makes its calls fail.
+func (c *Client) Close() error {
+	c.lk.Lock()
+	defer c.lk.Unlock()
+	if !c.closed {
+		c.closed = true
+		close(c.done)
+	}
+	return nil
+}
+
+// String returns the name of this client.
+func (c *Client) String() string {
+	return fmt.Sprintf("clienttest.Client(%x)", c.chain.Info().Hash()[:4])
+}
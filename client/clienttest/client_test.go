@@ -0,0 +1,126 @@
+package clienttest
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/drand/drand/client"
+
+	"github.com/jonboulle/clockwork"
+)
+
+func TestChainDeterministic(t *testing.T) {
+	genesis := time.Unix(1600000000, 0)
+	c1 := NewChain([]byte("seed"), 3*time.Second, genesis)
+	c2 := NewChain([]byte("seed"), 3*time.Second, genesis)
+	c3 := NewChain([]byte("other seed"), 3*time.Second, genesis)
+
+	if !c1.Info().Equal(c2.Info()) {
+		t.Fatal("same seed should give the same chain")
+	}
+	if c1.Info().Equal(c3.Info()) {
+		t.Fatal("different seeds should give different chains")
+	}
+	if !bytes.Equal(c1.Result(5).Randomness(), c2.Result(5).Randomness()) {
+		t.Fatal("same seed should give the same randomness")
+	}
+	if bytes.Equal(c1.Result(5).Randomness(), c1.Result(6).Randomness()) {
+		t.Fatal("rounds should have different randomness")
+	}
+}
+
+func TestClientVerifies(t *testing.T) {
+	clock := clockwork.NewFakeClock()
+	ch := NewChain([]byte("seed"), 3*time.Second, clock.Now())
+	clock.Advance(30 * time.Second)
+	fake := NewClient(ch, clock)
+	fake.CorruptRound(4)
+
+	c, err := client.New(client.From(fake), client.WithChainInfo(ch.Info()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	ctx := context.Background()
+	r, err := c.Get(ctx, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(r.Randomness(), ch.Result(3).Randomness()) {
+		t.Fatal("unexpected randomness")
+	}
+	if _, err := c.Get(ctx, 4); err == nil {
+		t.Fatal("corrupted round should not verify")
+	}
+	if _, err := c.Get(ctx, 12); err == nil {
+		t.Fatal("future round should not be served")
+	}
+}
+
+func TestClientScriptedFailures(t *testing.T) {
+	clock := clockwork.NewFakeClock()
+	ch := NewChain([]byte("seed"), 3*time.Second, clock.Now())
+	fake := NewClient(ch, clock)
+	ctx := context.Background()
+
+	errBoom := errors.New("boom")
+	fake.FailNext(errBoom)
+	fake.FailRound(1, ErrNotProduced)
+	if _, err := fake.Get(ctx, 1); !errors.Is(err, errBoom) {
+		t.Fatal("expected the scripted error, got", err)
+	}
+	if _, err := fake.Get(ctx, 1); !errors.Is(err, ErrNotProduced) {
+		t.Fatal("expected the round error, got", err)
+	}
+	fake.FailRound(1, nil)
+	if r, err := fake.Get(ctx, 0); err != nil || r.Round() != 1 {
+		t.Fatal("expected round 1", r, err)
+	}
+	if fake.GetCalls() != 3 {
+		t.Fatal("expected 3 calls, got", fake.GetCalls())
+	}
+
+	fake.FailInfo(errBoom)
+	if _, err := fake.Info(ctx); !errors.Is(err, errBoom) {
+		t.Fatal("expected the info error, got", err)
+	}
+}
+
+func TestClientWatch(t *testing.T) {
+	clock := clockwork.NewFakeClock()
+	ch := NewChain([]byte("seed"), 3*time.Second, clock.Now())
+	fake := NewClient(ch, clock)
+	fake.FailRound(3, ErrNotProduced)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	results := fake.Watch(ctx)
+
+	for _, expected := range []uint64{2, 4} {
+		for {
+			clock.BlockUntil(1)
+			clock.Advance(3 * time.Second)
+			select {
+			case r := <-results:
+				if r.Round() != expected {
+					t.Fatal("expected round", expected, "got", r.Round())
+				}
+			case <-time.After(100 * time.Millisecond):
+				// round 3 is skipped
+				continue
+			}
+			break
+		}
+	}
+
+	if err := fake.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := <-results; ok {
+		t.Fatal("watch should end when the client is closed")
+	}
+}
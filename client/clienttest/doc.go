@@ -0,0 +1,42 @@
+/*
+Package clienttest provides a deterministic, in-memory drand client for unit
+testing code that consumes drand randomness.
+
+A Chain derives every round from a seed: the same seed always produces the
+same chain information and the same signatures, and rounds are validly signed,
+so they pass the verification of the drand client. A Client serves the rounds
+of a Chain according to a clock the test controls, and failures can be
+scripted per round or per call.
+
+Example:
+
+	package consumer_test
+
+	import (
+		"context"
+		"testing"
+		"time"
+
+		"github.com/drand/drand/client"
+		"github.com/drand/drand/client/clienttest"
+		"github.com/jonboulle/clockwork"
+	)
+
+	func TestConsumer(t *testing.T) {
+		clock := clockwork.NewFakeClock()
+		ch := clienttest.NewChain([]byte("seed"), 3*time.Second, clock.Now())
+		fake := clienttest.NewClient(ch, clock)
+
+		c, err := client.New(client.From(fake), client.WithChainInfo(ch.Info()))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		results := c.Watch(context.Background())
+		clock.BlockUntil(1)
+		clock.Advance(3 * time.Second)
+		r := <-results
+		...
+	}
+*/
+package clienttest
@@ -0,0 +1,379 @@
+package config
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	nhttp "net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/drand/drand/chain"
+	"github.com/drand/drand/client"
+	"github.com/drand/drand/client/grpc"
+	"github.com/drand/drand/client/http"
+
+	"gopkg.in/yaml.v2"
+)
+
+// defaultWatchBuffer is the capacity of the watch channels when only a drop
+// policy is configured, matching the default of the client.
+const defaultWatchBuffer = 5
+
+// Config describes a drand client.
+type Config struct {
+	// Endpoints are the sources of randomness, as http://, https:// or
+	// grpc:// URLs.
+	Endpoints []string `json:"endpoints" yaml:"endpoints"`
+	// ChainHash is the hex encoded hash of the chain, serving as root of
+	// trust.
+	ChainHash string `json:"chain_hash,omitempty" yaml:"chain_hash,omitempty"`
+	// ChainInfo is the path to the JSON chain info, serving as root of trust
+	// instead of the chain hash.
+	ChainInfo string `json:"chain_info,omitempty" yaml:"chain_info,omitempty"`
+	// Insecure allows the client to work without root of trust.
+	Insecure bool `json:"insecure,omitempty" yaml:"insecure,omitempty"`
+	// CacheSize is the number of rounds cached, 32 when unset and 0
+	// disabling the cache.
+	CacheSize *int `json:"cache_size,omitempty" yaml:"cache_size,omitempty"`
+	// FullVerify enables the verification of the whole chain, up to the
+	// round V1Until.
+	FullVerify bool `json:"full_verify,omitempty" yaml:"full_verify,omitempty"`
+	// V1Until is the last round signed with the chained scheme.
+	V1Until uint64 `json:"v1_until,omitempty" yaml:"v1_until,omitempty"`
+	// RequestTimeout bounds the requests made to HTTP endpoints.
+	RequestTimeout Duration `json:"request_timeout,omitempty" yaml:"request_timeout,omitempty"`
+	// HedgeDelay enables hedged requests with the given delay.
+	HedgeDelay Duration `json:"hedge_delay,omitempty" yaml:"hedge_delay,omitempty"`
+	// RateLimit is the number of requests per second allowed to each
+	// endpoint, with bursts of RateBurst requests.
+	RateLimit float64 `json:"rate_limit,omitempty" yaml:"rate_limit,omitempty"`
+	RateBurst int     `json:"rate_burst,omitempty" yaml:"rate_burst,omitempty"`
+	// Proxy is the URL of the proxy the endpoints are reached through.
+	Proxy string `json:"proxy,omitempty" yaml:"proxy,omitempty"`
+	// GRPCCert is the path to the certificate of gRPC endpoints, the system
+	// roots being used when empty.
+	GRPCCert string `json:"grpc_cert,omitempty" yaml:"grpc_cert,omitempty"`
+	// Watch configures the watches of the client.
+	Watch WatchConfig `json:"watch,omitempty" yaml:"watch,omitempty"`
+}
+
+// WatchConfig configures the watches of a client.
+type WatchConfig struct {
+	// Buffer is the capacity of the channels returned by Watch.
+	Buffer int `json:"buffer,omitempty" yaml:"buffer,omitempty"`
+	// DropPolicy is one of drop-newest, drop-oldest or block.
+	DropPolicy string `json:"drop_policy,omitempty" yaml:"drop_policy,omitempty"`
+	// AutoWatch makes the client watch in the background.
+	AutoWatch bool `json:"auto_watch,omitempty" yaml:"auto_watch,omitempty"`
+	// AutoWatchRetry is the delay before restarting an ended auto watch.
+	AutoWatchRetry Duration `json:"auto_watch_retry,omitempty" yaml:"auto_watch_retry,omitempty"`
+	// WatchdogPeriods restarts watches stalled for that many periods.
+	WatchdogPeriods int `json:"watchdog_periods,omitempty" yaml:"watchdog_periods,omitempty"`
+}
+
+// Duration is a time.Duration written as a string such as "1m30s".
+type Duration time.Duration
+
+// UnmarshalJSON parses a duration string.
+func (d *Duration) UnmarshalJSON(b []byte) error {
+	var s string
+	if err := json.Unmarshal(b, &s); err != nil {
+		return fmt.Errorf("duration must be a string: %w", err)
+	}
+	return d.parse(s)
+}
+
+// MarshalJSON writes the duration as a string.
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(time.Duration(d).String())
+}
+
+// UnmarshalYAML parses a duration string.
+func (d *Duration) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var s string
+	if err := unmarshal(&s); err != nil {
+		return fmt.Errorf("duration must be a string: %w", err)
+	}
+	return d.parse(s)
+}
+
+// MarshalYAML writes the duration as a string.
+func (d Duration) MarshalYAML() (interface{}, error) {
+	return time.Duration(d).String(), nil
+}
+
+func (d *Duration) parse(s string) error {
+	v, err := time.ParseDuration(s)
+	if err != nil {
+		return err
+	}
+	*d = Duration(v)
+	return nil
+}
+
+// FieldError is a validation error of a field of the configuration.
+type FieldError struct {
+	// Field is the path of the field, such as "watch.drop_policy".
+	Field string
+	Err   error
+}
+
+func (e *FieldError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Field, e.Err)
+}
+
+func (e *FieldError) Unwrap() error {
+	return e.Err
+}
+
+func fieldErr(field, format string, args ...interface{}) error {
+	return &FieldError{Field: field, Err: fmt.Errorf(format, args...)}
+}
+
+// Parse reads a configuration, as JSON when it is a JSON object and as YAML
+// otherwise. Unknown fields are rejected.
+func Parse(data []byte) (*Config, error) {
+	c := new(Config)
+	if trimmed := bytes.TrimSpace(data); len(trimmed) > 0 && trimmed[0] == '{' {
+		dec := json.NewDecoder(bytes.NewReader(trimmed))
+		dec.DisallowUnknownFields()
+		if err := dec.Decode(c); err != nil {
+			return nil, fmt.Errorf("parsing JSON config: %w", err)
+		}
+		return c, nil
+	}
+	if err := yaml.UnmarshalStrict(data, c); err != nil {
+		return nil, fmt.Errorf("parsing YAML config: %w", err)
+	}
+	return c, nil
+}
+
+// Load reads the configuration in the file at path.
+func Load(path string) (*Config, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return Parse(data)
+}
+
+// FromFile creates the client described by the configuration file at path.
+func FromFile(path string) (client.Client, error) {
+	c, err := Load(path)
+	if err != nil {
+		return nil, err
+	}
+	return New(c)
+}
+
+// Validate checks the configuration, returning a *FieldError for the first
+// invalid field.
+func (c *Config) Validate() error {
+	if len(c.Endpoints) == 0 {
+		return fieldErr("endpoints", "at least one endpoint is required")
+	}
+	for i, ep := range c.Endpoints {
+		if _, _, err := splitEndpoint(ep); err != nil {
+			return &FieldError{Field: fmt.Sprintf("endpoints[%d]", i), Err: err}
+		}
+	}
+	if c.ChainHash != "" {
+		if h, err := hex.DecodeString(c.ChainHash); err != nil || len(h) != 32 {
+			return fieldErr("chain_hash", "must be 32 hex encoded bytes")
+		}
+	}
+	if c.ChainHash == "" && c.ChainInfo == "" && !c.Insecure {
+		return fieldErr("chain_hash", "a chain hash or chain info is required unless insecure is set")
+	}
+	if c.CacheSize != nil && *c.CacheSize < 0 {
+		return fieldErr("cache_size", "must not be negative")
+	}
+	if c.FullVerify && c.V1Until == 0 {
+		return fieldErr("v1_until", "is required with full_verify")
+	}
+	if c.RequestTimeout < 0 {
+		return fieldErr("request_timeout", "must not be negative")
+	}
+	if c.HedgeDelay < 0 {
+		return fieldErr("hedge_delay", "must not be negative")
+	}
+	if c.RateLimit < 0 {
+		return fieldErr("rate_limit", "must not be negative")
+	}
+	if c.RateBurst < 0 || (c.RateBurst > 0 && c.RateLimit == 0) {
+		return fieldErr("rate_burst", "must be positive, with a rate_limit")
+	}
+	if c.Proxy != "" {
+		if _, err := url.Parse(c.Proxy); err != nil {
+			return &FieldError{Field: "proxy", Err: err}
+		}
+	}
+	if c.Watch.Buffer < 0 {
+		return fieldErr("watch.buffer", "must not be negative")
+	}
+	if _, err := dropPolicy(c.Watch.DropPolicy); err != nil {
+		return &FieldError{Field: "watch.drop_policy", Err: err}
+	}
+	if c.Watch.WatchdogPeriods < 0 {
+		return fieldErr("watch.watchdog_periods", "must not be negative")
+	}
+	return nil
+}
+
+// New creates the client described by the configuration.
+func New(c *Config) (client.Client, error) {
+	if err := c.Validate(); err != nil {
+		return nil, err
+	}
+
+	var opts []client.Option
+	var hash []byte
+	var info *chain.Info
+	if c.ChainHash != "" {
+		hash, _ = hex.DecodeString(c.ChainHash)
+		opts = append(opts, client.WithChainHash(hash))
+	}
+	if c.ChainInfo != "" {
+		f, err := os.Open(c.ChainInfo)
+		if err != nil {
+			return nil, &FieldError{Field: "chain_info", Err: err}
+		}
+		info, err = chain.InfoFromJSON(f)
+		f.Close()
+		if err != nil {
+			return nil, &FieldError{Field: "chain_info", Err: err}
+		}
+		opts = append(opts, client.WithChainInfo(info))
+	}
+	if c.Insecure {
+		opts = append(opts, client.Insecurely())
+	}
+	if c.CacheSize != nil {
+		opts = append(opts, client.WithCacheSize(*c.CacheSize))
+	}
+	if c.V1Until > 0 {
+		opts = append(opts, client.WithV1VerificationUntil(c.V1Until))
+	}
+	if c.FullVerify {
+		opts = append(opts, client.WithFullChainVerification())
+	}
+	if c.HedgeDelay > 0 {
+		opts = append(opts, client.WithHedgedRequests(time.Duration(c.HedgeDelay)))
+	}
+	if c.RateLimit > 0 {
+		opts = append(opts, client.WithRateLimit(c.RateLimit, c.RateBurst))
+	}
+	if c.Watch.Buffer > 0 || c.Watch.DropPolicy != "" {
+		size := c.Watch.Buffer
+		if size == 0 {
+			size = defaultWatchBuffer
+		}
+		policy, _ := dropPolicy(c.Watch.DropPolicy)
+		opts = append(opts, client.WithWatchBuffer(size, policy))
+	}
+	if c.Watch.AutoWatch {
+		opts = append(opts, client.WithAutoWatch())
+	}
+	if c.Watch.AutoWatchRetry != 0 {
+		opts = append(opts, client.WithAutoWatchRetry(time.Duration(c.Watch.AutoWatchRetry)))
+	}
+	if c.Watch.WatchdogPeriods > 0 {
+		opts = append(opts, client.WithWatchdog(c.Watch.WatchdogPeriods, nil))
+	}
+
+	sources, err := c.connect(hash, info)
+	if err != nil {
+		return nil, err
+	}
+	cl, err := client.Wrap(sources, opts...)
+	if err != nil {
+		for _, s := range sources {
+			_ = s.Close()
+		}
+		return nil, err
+	}
+	return cl, nil
+}
+
+// connect creates the clients of the endpoints. Endpoints that can not be
+// reached are skipped, as long as one of them can be.
+func (c *Config) connect(hash []byte, info *chain.Info) ([]client.Client, error) {
+	var proxyURL *url.URL
+	if c.Proxy != "" {
+		proxyURL, _ = url.Parse(c.Proxy)
+	}
+	var transport nhttp.RoundTripper
+	if proxyURL != nil {
+		transport = http.NewTransport(http.TransportOptions{Proxy: proxyURL})
+	}
+	var httpOpts []http.Option
+	if c.RequestTimeout > 0 {
+		httpOpts = append(httpOpts, http.WithRequestTimeout(time.Duration(c.RequestTimeout)))
+	}
+	var grpcOpts []grpc.Option
+	if proxyURL != nil {
+		grpcOpts = append(grpcOpts, grpc.WithProxy(proxyURL))
+	}
+
+	var sources []client.Client
+	var errs []string
+	for i, ep := range c.Endpoints {
+		scheme, addr, _ := splitEndpoint(ep)
+		var s client.Client
+		var err error
+		switch {
+		case scheme == "grpc":
+			s, err = grpc.New(addr, c.GRPCCert, false, grpcOpts...)
+		case info != nil:
+			s, err = http.NewWithInfo(ep, info, transport, httpOpts...)
+		default:
+			s, err = http.New(ep, hash, transport, httpOpts...)
+		}
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("endpoints[%d]: %s", i, err))
+			continue
+		}
+		sources = append(sources, s)
+	}
+	if len(sources) == 0 {
+		return nil, errors.New("no endpoint could be reached: " + strings.Join(errs, "; "))
+	}
+	return sources, nil
+}
+
+// splitEndpoint returns the scheme and the address of an endpoint.
+func splitEndpoint(endpoint string) (scheme, addr string, err error) {
+	i := strings.Index(endpoint, "://")
+	if i <= 0 {
+		return "", "", fmt.Errorf("invalid endpoint %q", endpoint)
+	}
+	scheme, addr = endpoint[:i], endpoint[i+3:]
+	switch scheme {
+	case "http", "https", "grpc":
+	default:
+		return "", "", fmt.Errorf("unsupported scheme in endpoint %q", endpoint)
+	}
+	if addr == "" {
+		return "", "", fmt.Errorf("invalid endpoint %q", endpoint)
+	}
+	return scheme, addr, nil
+}
+
+// dropPolicy parses the name of a drop policy, empty meaning the default.
+func dropPolicy(name string) (client.DropPolicy, error) {
+	for _, p := range []client.DropPolicy{client.DropNewest, client.DropOldest, client.Block} {
+		if name == p.String() {
+			return p, nil
+		}
+	}
+	if name == "" {
+		return client.DropNewest, nil
+	}
+	return 0, fmt.Errorf("unknown drop policy %q", name)
+}
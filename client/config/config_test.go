@@ -0,0 +1,91 @@
+package config
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/drand/drand/client/test/http/mock"
+)
+
+func TestParse(t *testing.T) {
+	yamlConf := `
+endpoints:
+  - https://api.drand.sh/
+  - grpc://127.0.0.1:4444
+chain_hash: 8990e7a9aaed2ffed73dbd7092123d6f289930540d7651336225dc172e51b2ce
+cache_size: 0
+hedge_delay: 500ms
+watch:
+  buffer: 10
+  drop_policy: drop-oldest
+`
+	jsonConf := `{
+	"endpoints": ["https://api.drand.sh/", "grpc://127.0.0.1:4444"],
+	"chain_hash": "8990e7a9aaed2ffed73dbd7092123d6f289930540d7651336225dc172e51b2ce",
+	"cache_size": 0,
+	"hedge_delay": "500ms",
+	"watch": {"buffer": 10, "drop_policy": "drop-oldest"}
+}`
+	for _, data := range []string{yamlConf, jsonConf} {
+		c, err := Parse([]byte(data))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := c.Validate(); err != nil {
+			t.Fatal(err)
+		}
+		if len(c.Endpoints) != 2 || c.CacheSize == nil || *c.CacheSize != 0 {
+			t.Fatal("unexpected config", c)
+		}
+		if time.Duration(c.HedgeDelay) != 500*time.Millisecond || c.Watch.Buffer != 10 {
+			t.Fatal("unexpected config", c)
+		}
+	}
+
+	if _, err := Parse([]byte("endpoint: https://api.drand.sh/")); err == nil {
+		t.Fatal("unknown fields should be rejected")
+	}
+}
+
+func TestValidate(t *testing.T) {
+	hash := "8990e7a9aaed2ffed73dbd7092123d6f289930540d7651336225dc172e51b2ce"
+	for field, conf := range map[string]string{
+		"endpoints":         `chain_hash: ` + hash,
+		"endpoints[1]":      "endpoints: [http://a/, ftp://b/]\nchain_hash: " + hash,
+		"chain_hash":        `endpoints: [http://a/]`,
+		"v1_until":          "endpoints: [http://a/]\nfull_verify: true\nchain_hash: " + hash,
+		"watch.drop_policy": "endpoints: [http://a/]\nwatch: {drop_policy: newest}\nchain_hash: " + hash,
+	} {
+		c, err := Parse([]byte(conf))
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = c.Validate()
+		var fe *FieldError
+		if !errors.As(err, &fe) || fe.Field != field {
+			t.Fatalf("expected an error on %s, got %v", field, err)
+		}
+	}
+}
+
+func TestNew(t *testing.T) {
+	addr, info, cancel, _ := mock.NewMockHTTPPublicServer(t, false)
+	defer cancel()
+
+	c, err := Parse([]byte(fmt.Sprintf(`{"endpoints": ["http://%s/"], "chain_hash": "%s"}`, addr, hex.EncodeToString(info.Hash()))))
+	if err != nil {
+		t.Fatal(err)
+	}
+	cl, err := New(c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cl.Close()
+	if _, err := cl.Get(context.Background(), 0); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,31 @@
+/*
+Package config builds drand clients from a declarative configuration, given
+as YAML or JSON, instead of assembling client options in code.
+
+Example configuration:
+
+	endpoints:
+	  - https://api.drand.sh/
+	  - https://drand.cloudflare.com/
+	  - grpc://pl-us.testnet.drand.sh:443
+	chain_hash: 8990e7a9aaed2ffed73dbd7092123d6f289930540d7651336225dc172e51b2ce
+	cache_size: 64
+	full_verify: false
+	request_timeout: 5s
+	hedge_delay: 500ms
+	watch:
+	  buffer: 10
+	  drop_policy: drop-oldest
+	  auto_watch: true
+	  auto_watch_retry: 30s
+
+The configuration is validated before any connection is made, and validation
+errors name the offending field:
+
+	c, err := config.FromFile("drand.yaml")
+	if err != nil {
+		// e.g. "watch.drop_policy: unknown drop policy "newest""
+	}
+	defer c.Close()
+*/
+package config
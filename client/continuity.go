@@ -0,0 +1,154 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+
+	"github.com/drand/drand/log"
+)
+
+// ContinuityViolation is the kind of discontinuity observed between two
+// consecutive results of a `Watch`.
+type ContinuityViolation int
+
+const (
+	// ViolationGap means rounds are missing between the two results.
+	ViolationGap ContinuityViolation = iota
+	// ViolationOutOfOrder means the round of the result is not greater than
+	// the round of the previous one.
+	ViolationOutOfOrder
+	// ViolationFork means the result does not chain to the previous round:
+	// its previous signature is not the signature delivered for that round,
+	// which may reveal a fork or an equivocation of the group.
+	ViolationFork
+)
+
+func (v ContinuityViolation) String() string {
+	switch v {
+	case ViolationGap:
+		return "gap"
+	case ViolationOutOfOrder:
+		return "out-of-order"
+	case ViolationFork:
+		return "fork"
+	default:
+		return fmt.Sprintf("ContinuityViolation(%d)", int(v))
+	}
+}
+
+// ContinuityError describes a discontinuity between two consecutive results
+// of a `Watch`.
+type ContinuityError struct {
+	Violation ContinuityViolation
+	// Previous is the last result delivered before Current.
+	Previous Result
+	Current  Result
+}
+
+func (e *ContinuityError) Error() string {
+	return fmt.Sprintf("watch %s: round %d after round %d", e.Violation, e.Current.Round(), e.Previous.Round())
+}
+
+// WithContinuityCheck checks that the rounds delivered by `Watch` are
+// increasing and, for chained signatures, that each round follows the
+// signature of the previous one. Discontinuities are reported to
+// `onViolation`, which may be nil. Gaps are reported and the round is still
+// delivered, while out of order rounds and rounds chaining to another
+// signature are not delivered.
+func WithContinuityCheck(onViolation func(*ContinuityError)) Option {
+	return func(cfg *clientConfig) error {
+		cfg.continuityCheck = true
+		cfg.onContinuityViolation = onViolation
+		return nil
+	}
+}
+
+// newContinuityClient wraps a client to check the continuity of its watches.
+func newContinuityClient(c Client, v2from uint64, onViolation func(*ContinuityError)) *continuityClient {
+	return &continuityClient{
+		Client:      c,
+		v2from:      v2from,
+		onViolation: onViolation,
+		log:         log.DefaultLogger(),
+	}
+}
+
+type continuityClient struct {
+	Client
+	// v2from is the first round signed without the previous signature.
+	v2from      uint64
+	onViolation func(*ContinuityError)
+	log         log.Logger
+}
+
+// SetLog configures the client log output.
+func (c *continuityClient) SetLog(l log.Logger) {
+	c.log = l
+	trySetLog(c.Client, l)
+}
+
+// String returns the name of this client.
+func (c *continuityClient) String() string {
+	return fmt.Sprintf("%s.(+continuity)", c.Client)
+}
+
+// Watch returns new randomness as it becomes available, checking it follows
+// the previous result.
+func (c *continuityClient) Watch(ctx context.Context) <-chan Result {
+	in := c.Client.Watch(ctx)
+	out := make(chan Result)
+	go func() {
+		defer close(out)
+		var last Result
+		for r := range in {
+			if last != nil {
+				if err := c.check(last, r); err != nil {
+					c.log.Warn("continuity", "discontinuous watch", "round", r.Round(), "previous", last.Round(), "violation", err.Violation)
+					if c.onViolation != nil {
+						c.onViolation(err)
+					}
+					if err.Violation != ViolationGap {
+						continue
+					}
+				}
+			}
+			last = r
+			select {
+			case out <- r:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return out
+}
+
+// check returns the violation between two consecutive results, if any.
+func (c *continuityClient) check(last, r Result) *ContinuityError {
+	switch {
+	case r.Round() <= last.Round():
+		return &ContinuityError{Violation: ViolationOutOfOrder, Previous: last, Current: r}
+	case r.Round() > last.Round()+1:
+		return &ContinuityError{Violation: ViolationGap, Previous: last, Current: r}
+	case r.Round() >= c.v2from:
+		return nil
+	}
+	prev := previousSignature(r)
+	if prev != nil && !bytes.Equal(prev, last.Signature()) {
+		return &ContinuityError{Violation: ViolationFork, Previous: last, Current: r}
+	}
+	return nil
+}
+
+// previousSignature returns the previous signature carried by a result, or
+// nil when it does not carry one.
+func previousSignature(r Result) []byte {
+	switch rd := r.(type) {
+	case *RandomData:
+		return rd.PreviousSignature
+	case resultWithPreviousSignature:
+		return rd.PreviousSignature()
+	}
+	return nil
+}
@@ -0,0 +1,49 @@
+package client
+
+import (
+	"context"
+	"testing"
+
+	"github.com/drand/drand/client/test/result/mock"
+)
+
+func TestContinuityCheck(t *testing.T) {
+	_, results := mock.VerifiableResults(6, 1000000000)
+	forked := results[2]
+	forked.PSig = []byte("another signature")
+
+	ch := make(chan Result, 6)
+	for _, r := range []mock.Result{results[0], results[1], results[1], forked, results[4], results[5]} {
+		r := r
+		ch <- &r
+	}
+	close(ch)
+
+	var violations []*ContinuityError
+	c := newContinuityClient(&MockClient{WatchCh: ch}, 1000000000, func(err *ContinuityError) {
+		violations = append(violations, err)
+	})
+	var rounds []uint64
+	for r := range c.Watch(context.Background()) {
+		rounds = append(rounds, r.Round())
+	}
+
+	expected := []uint64{1, 2, 5, 6}
+	if len(rounds) != len(expected) {
+		t.Fatal("unexpected rounds", rounds)
+	}
+	for i := range expected {
+		if rounds[i] != expected[i] {
+			t.Fatal("unexpected rounds", rounds)
+		}
+	}
+	kinds := []ContinuityViolation{ViolationOutOfOrder, ViolationFork, ViolationGap}
+	if len(violations) != len(kinds) {
+		t.Fatal("unexpected violations", violations)
+	}
+	for i, k := range kinds {
+		if violations[i].Violation != k {
+			t.Fatal("expected", k, "got", violations[i].Violation)
+		}
+	}
+}
@@ -0,0 +1,185 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/drand/drand/log"
+)
+
+// defaultDiscoveryInterval is how often the endpoints of a Discovery are
+// refreshed by default.
+const defaultDiscoveryInterval = 10 * time.Minute
+
+// Discovery provides the endpoints currently serving randomness, for instance
+// from DNS records, so that sources can be added and removed without
+// rebuilding the client.
+type Discovery interface {
+	// Endpoints returns the identifiers of the endpoints, such as their URL.
+	Endpoints(ctx context.Context) ([]string, error)
+	// Connect creates a client for one of the endpoints.
+	Connect(endpoint string) (Client, error)
+}
+
+// WithDiscovery adds the sources found by `d` to the client. The endpoints
+// are refreshed every `interval`, 10 minutes by default: new endpoints are
+// used by the failover logic as soon as they are discovered, and the sources
+// of endpoints that disappeared are closed. A refresh failing or finding no
+// endpoint keeps the current sources.
+func WithDiscovery(d Discovery, interval time.Duration) Option {
+	return func(cfg *clientConfig) error {
+		if interval < 0 {
+			return errors.New("discovery interval must not be negative")
+		}
+		if interval == 0 {
+			interval = defaultDiscoveryInterval
+		}
+		cfg.discovery = d
+		cfg.discoveryInterval = interval
+		return nil
+	}
+}
+
+// discoveredSources keeps track of the sources created from the endpoints of
+// a Discovery.
+type discoveredSources struct {
+	discovery Discovery
+	interval  time.Duration
+	wrap      func(Client) Client
+	log       log.Logger
+
+	lk sync.Mutex
+	// sources are the wrapped sources, by endpoint.
+	sources map[string]Client
+	// verifiers are the clients used by the optimizing client, by endpoint.
+	verifiers map[string]Client
+}
+
+// discoverSources connects to the endpoints currently provided by the
+// discovery of the configuration, wrapping them with `wrap`.
+func discoverSources(cfg *clientConfig, wrap func(Client) Client) (*discoveredSources, error) {
+	ds := &discoveredSources{
+		discovery: cfg.discovery,
+		interval:  cfg.discoveryInterval,
+		wrap:      wrap,
+		log:       cfg.log,
+		sources:   make(map[string]Client),
+		verifiers: make(map[string]Client),
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), clientStartupTimeoutDefault)
+	defer cancel()
+	endpoints, err := ds.discovery.Endpoints(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("discovering endpoints: %w", err)
+	}
+	for _, ep := range endpoints {
+		if s := ds.connect(ep); s != nil {
+			ds.sources[ep] = s
+		}
+	}
+	return ds, nil
+}
+
+// connect creates the wrapped source of an endpoint, or nil on failure.
+func (ds *discoveredSources) connect(endpoint string) Client {
+	c, err := ds.discovery.Connect(endpoint)
+	if err != nil {
+		ds.log.Warn("discovery", "could not connect to endpoint", "endpoint", endpoint, "err", err)
+		return nil
+	}
+	return ds.wrap(c)
+}
+
+// list returns the current wrapped sources.
+func (ds *discoveredSources) list() []Client {
+	ds.lk.Lock()
+	defer ds.lk.Unlock()
+	sources := make([]Client, 0, len(ds.sources))
+	for _, s := range ds.sources {
+		sources = append(sources, s)
+	}
+	return sources
+}
+
+// track records the client used by the optimizing client for a source.
+func (ds *discoveredSources) track(source, verifier Client) {
+	ds.lk.Lock()
+	defer ds.lk.Unlock()
+	for ep, s := range ds.sources {
+		if s == source {
+			ds.verifiers[ep] = verifier
+			return
+		}
+	}
+}
+
+// run refreshes the endpoints periodically until the optimizing client is
+// closed, building the clients it uses with `newVerifier`.
+func (ds *discoveredSources) run(oc *optimizingClient, newVerifier func(Client) Client) {
+	ticker := time.NewTicker(ds.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			ctx, cancel := context.WithTimeout(context.Background(), oc.requestTimeout)
+			ds.refresh(ctx, oc, newVerifier)
+			cancel()
+		case <-oc.done:
+			return
+		}
+	}
+}
+
+func (ds *discoveredSources) refresh(ctx context.Context, oc *optimizingClient, newVerifier func(Client) Client) {
+	endpoints, err := ds.discovery.Endpoints(ctx)
+	if err != nil {
+		ds.log.Warn("discovery", "could not refresh endpoints", "err", err)
+		return
+	}
+	if len(endpoints) == 0 {
+		ds.log.Warn("discovery", "no endpoint found, keeping the current ones")
+		return
+	}
+
+	ds.lk.Lock()
+	defer ds.lk.Unlock()
+
+	current := make(map[string]bool, len(endpoints))
+	var added []Client
+	for _, ep := range endpoints {
+		current[ep] = true
+		if _, ok := ds.sources[ep]; ok {
+			continue
+		}
+		s := ds.connect(ep)
+		if s == nil {
+			continue
+		}
+		v := newVerifier(s)
+		ds.sources[ep] = s
+		ds.verifiers[ep] = v
+		added = append(added, v)
+		ds.log.Info("discovery", "new endpoint", "endpoint", ep)
+	}
+	if len(added) > 0 {
+		oc.addClients(added...)
+	}
+
+	for ep, v := range ds.verifiers {
+		if current[ep] {
+			continue
+		}
+		if err := oc.removeClients(v); errors.Is(err, errLastClient) {
+			ds.log.Warn("discovery", "not removing the last endpoint", "endpoint", ep)
+			continue
+		} else if err != nil {
+			ds.log.Warn("discovery", "error closing removed endpoint", "endpoint", ep, "err", err)
+		}
+		delete(ds.sources, ep)
+		delete(ds.verifiers, ep)
+		ds.log.Info("discovery", "endpoint removed", "endpoint", ep)
+	}
+}
@@ -0,0 +1,153 @@
+package discovery
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/drand/drand/client"
+	"github.com/drand/drand/client/grpc"
+	"github.com/drand/drand/client/http"
+)
+
+// txtPrefix marks the TXT records holding a drand endpoint.
+const txtPrefix = "url="
+
+// srvServices maps the SRV services looked up to the scheme of the endpoints
+// they advertise.
+var srvServices = []struct {
+	service string
+	scheme  string
+}{
+	{"drand-https", "https"},
+	{"drand-http", "http"},
+	{"drand-grpc", "grpc"},
+}
+
+// Resolver looks up DNS records. It is implemented by *net.Resolver.
+type Resolver interface {
+	LookupSRV(ctx context.Context, service, proto, name string) (string, []*net.SRV, error)
+	LookupTXT(ctx context.Context, name string) ([]string, error)
+}
+
+// DNS discovers the drand endpoints published in the DNS records of a domain.
+// It implements the client.Discovery interface.
+type DNS struct {
+	domain    string
+	chainHash []byte
+	// Resolver is used for lookups, net.DefaultResolver by default.
+	Resolver Resolver
+	// HTTPOptions configure the HTTP clients created for HTTP endpoints.
+	HTTPOptions []http.Option
+	// GRPCCertPath is the certificate used to connect to gRPC endpoints, the
+	// system roots being used when empty.
+	GRPCCertPath string
+}
+
+// NewDNS creates the discovery of the endpoints of the chain with the given
+// hash published under `domain`.
+func NewDNS(domain string, chainHash []byte) *DNS {
+	return &DNS{
+		domain:    strings.TrimSuffix(domain, "."),
+		chainHash: chainHash,
+		Resolver:  net.DefaultResolver,
+	}
+}
+
+// Endpoints returns the endpoints currently published in DNS, sorted. A
+// failing lookup is only reported when no endpoint could be found at all.
+func (d *DNS) Endpoints(ctx context.Context) ([]string, error) {
+	found := make(map[string]bool)
+	var errs []string
+
+	for _, s := range srvServices {
+		_, srvs, err := d.Resolver.LookupSRV(ctx, s.service, "tcp", d.domain)
+		if err != nil {
+			if !isNotFound(err) {
+				errs = append(errs, err.Error())
+			}
+			continue
+		}
+		for _, srv := range srvs {
+			host := strings.TrimSuffix(srv.Target, ".")
+			if host == "" {
+				continue
+			}
+			found[formatEndpoint(s.scheme, host, srv.Port)] = true
+		}
+	}
+
+	txts, err := d.Resolver.LookupTXT(ctx, "_drand."+d.domain)
+	if err != nil && !isNotFound(err) {
+		errs = append(errs, err.Error())
+	}
+	for _, txt := range txts {
+		if !strings.HasPrefix(txt, txtPrefix) {
+			continue
+		}
+		ep := strings.TrimSpace(strings.TrimPrefix(txt, txtPrefix))
+		if _, _, err := splitEndpoint(ep); err != nil {
+			continue
+		}
+		found[ep] = true
+	}
+
+	if len(found) == 0 && len(errs) > 0 {
+		return nil, fmt.Errorf("looking up %s: %s", d.domain, strings.Join(errs, "; "))
+	}
+	endpoints := make([]string, 0, len(found))
+	for ep := range found {
+		endpoints = append(endpoints, ep)
+	}
+	sort.Strings(endpoints)
+	return endpoints, nil
+}
+
+// Connect creates a client for an endpoint returned by Endpoints.
+func (d *DNS) Connect(endpoint string) (client.Client, error) {
+	scheme, addr, err := splitEndpoint(endpoint)
+	if err != nil {
+		return nil, err
+	}
+	switch scheme {
+	case "grpc":
+		return grpc.New(addr, d.GRPCCertPath, false)
+	default:
+		return http.New(endpoint, d.chainHash, nil, d.HTTPOptions...)
+	}
+}
+
+func formatEndpoint(scheme, host string, port uint16) string {
+	addr := net.JoinHostPort(host, strconv.Itoa(int(port)))
+	if scheme == "grpc" {
+		return "grpc://" + addr
+	}
+	return scheme + "://" + addr + "/"
+}
+
+// splitEndpoint returns the scheme and the address of an endpoint.
+func splitEndpoint(endpoint string) (scheme, addr string, err error) {
+	i := strings.Index(endpoint, "://")
+	if i <= 0 {
+		return "", "", fmt.Errorf("invalid endpoint %q", endpoint)
+	}
+	scheme, addr = endpoint[:i], endpoint[i+3:]
+	switch scheme {
+	case "http", "https", "grpc":
+	default:
+		return "", "", fmt.Errorf("unsupported scheme in endpoint %q", endpoint)
+	}
+	if addr == "" {
+		return "", "", fmt.Errorf("invalid endpoint %q", endpoint)
+	}
+	return scheme, addr, nil
+}
+
+func isNotFound(err error) bool {
+	var dnsErr *net.DNSError
+	return errors.As(err, &dnsErr) && dnsErr.IsNotFound
+}
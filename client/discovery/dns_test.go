@@ -0,0 +1,82 @@
+package discovery
+
+import (
+	"context"
+	"net"
+	"reflect"
+	"testing"
+)
+
+type fakeResolver struct {
+	srv map[string][]*net.SRV
+	txt map[string][]string
+}
+
+func (r *fakeResolver) LookupSRV(ctx context.Context, service, proto, name string) (string, []*net.SRV, error) {
+	key := "_" + service + "._" + proto + "." + name
+	srvs, ok := r.srv[key]
+	if !ok {
+		return "", nil, &net.DNSError{Err: "no such host", Name: key, IsNotFound: true}
+	}
+	return key, srvs, nil
+}
+
+func (r *fakeResolver) LookupTXT(ctx context.Context, name string) ([]string, error) {
+	txts, ok := r.txt[name]
+	if !ok {
+		return nil, &net.DNSError{Err: "no such host", Name: name, IsNotFound: true}
+	}
+	return txts, nil
+}
+
+func TestDNSEndpoints(t *testing.T) {
+	d := NewDNS("drand.example.org.", nil)
+	d.Resolver = &fakeResolver{
+		srv: map[string][]*net.SRV{
+			"_drand-https._tcp.drand.example.org": {{Target: "api1.example.org.", Port: 443}},
+			"_drand-grpc._tcp.drand.example.org":  {{Target: "grpc.example.org.", Port: 4444}},
+		},
+		txt: map[string][]string{
+			"_drand.drand.example.org": {
+				"url=https://api2.example.org/",
+				"v=spf1 -all",
+				"url=ftp://invalid.example.org",
+			},
+		},
+	}
+
+	endpoints, err := d.Endpoints(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []string{
+		"grpc://grpc.example.org:4444",
+		"https://api1.example.org:443/",
+		"https://api2.example.org/",
+	}
+	if !reflect.DeepEqual(endpoints, expected) {
+		t.Fatal("unexpected endpoints", endpoints)
+	}
+}
+
+func TestDNSNoRecords(t *testing.T) {
+	d := NewDNS("drand.example.org", nil)
+	d.Resolver = &fakeResolver{}
+	endpoints, err := d.Endpoints(context.Background())
+	if err != nil {
+		t.Fatal("missing records should not be an error", err)
+	}
+	if len(endpoints) != 0 {
+		t.Fatal("expected no endpoint", endpoints)
+	}
+}
+
+func TestSplitEndpoint(t *testing.T) {
+	scheme, addr, err := splitEndpoint("grpc://grpc.example.org:4444")
+	if err != nil || scheme != "grpc" || addr != "grpc.example.org:4444" {
+		t.Fatal("unexpected split", scheme, addr, err)
+	}
+	if _, _, err := splitEndpoint("example.org"); err == nil {
+		t.Fatal("expected an error for an endpoint without scheme")
+	}
+}
@@ -0,0 +1,39 @@
+/*
+Package discovery provides the discovery of drand relays from DNS records, so
+that operators can publish their endpoints in DNS instead of having them baked
+into client binaries.
+
+For a domain, the following records are looked up:
+
+	_drand-https._tcp.<domain>  SRV  HTTPS relays, as https://<target>:<port>/
+	_drand-http._tcp.<domain>   SRV  HTTP relays, as http://<target>:<port>/
+	_drand-grpc._tcp.<domain>   SRV  gRPC endpoints, as grpc://<target>:<port>
+	_drand.<domain>             TXT  endpoints given as "url=<endpoint>"
+
+Endpoints found this way feed the failover logic of the drand client, and are
+refreshed periodically.
+
+Example:
+
+	package main
+
+	import (
+		"encoding/hex"
+		"time"
+
+		"github.com/drand/drand/client"
+		"github.com/drand/drand/client/discovery"
+	)
+
+	var chainHash, _ = hex.DecodeString("8990e7a9aaed2ffed73dbd7092123d6f289930540d7651336225dc172e51b2ce")
+
+	func main() {
+		d := discovery.NewDNS("drand.example.org", chainHash)
+
+		c, err := client.New(
+			client.WithDiscovery(d, 10*time.Minute),
+			client.WithChainHash(chainHash),
+		)
+	}
+*/
+package discovery
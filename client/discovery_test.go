@@ -0,0 +1,95 @@
+package client
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/drand/drand/log"
+)
+
+// staticDiscovery serves a settable list of endpoints backed by mock clients.
+type staticDiscovery struct {
+	sync.Mutex
+	endpoints []string
+	clients   map[string]Client
+}
+
+func (d *staticDiscovery) set(endpoints ...string) {
+	d.Lock()
+	defer d.Unlock()
+	d.endpoints = endpoints
+}
+
+func (d *staticDiscovery) Endpoints(ctx context.Context) ([]string, error) {
+	d.Lock()
+	defer d.Unlock()
+	return d.endpoints, nil
+}
+
+func (d *staticDiscovery) Connect(endpoint string) (Client, error) {
+	return d.clients[endpoint], nil
+}
+
+func TestDiscoveryRefresh(t *testing.T) {
+	closed := make(map[string]bool)
+	d := &staticDiscovery{clients: make(map[string]Client)}
+	for _, ep := range []string{"a", "b", "c"} {
+		ep := ep
+		mc := MockClientWithResults(0, 5)
+		mc.CloseF = func() error {
+			closed[ep] = true
+			return nil
+		}
+		d.clients[ep] = mc
+	}
+	d.set("a", "b")
+
+	identity := func(c Client) Client { return c }
+	cfg := &clientConfig{discovery: d, discoveryInterval: defaultDiscoveryInterval, log: log.DefaultLogger()}
+	ds, err := discoverSources(cfg, identity)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sources := ds.list()
+	if len(sources) != 2 {
+		t.Fatal("expected 2 sources, got", len(sources))
+	}
+	for _, s := range sources {
+		ds.track(s, s)
+	}
+	oc, err := newOptimizingClient(sources, 0, 0, 0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expectClients := func(endpoints ...string) {
+		t.Helper()
+		active := oc.activeClients()
+		if len(active) != len(endpoints) {
+			t.Fatalf("expected %d clients, got %d", len(endpoints), len(active))
+		}
+	LOOP:
+		for _, ep := range endpoints {
+			for _, c := range active {
+				if c == d.clients[ep] {
+					continue LOOP
+				}
+			}
+			t.Fatal("missing client for endpoint", ep)
+		}
+	}
+
+	ctx := context.Background()
+	d.set("b", "c")
+	ds.refresh(ctx, oc, identity)
+	expectClients("b", "c")
+	if !closed["a"] {
+		t.Fatal("removed endpoint should be closed")
+	}
+
+	// an empty discovery keeps the current endpoints
+	d.set()
+	ds.refresh(ctx, oc, identity)
+	expectClients("b", "c")
+}
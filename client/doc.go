@@ -40,7 +40,9 @@ calls to "Watch" to reduce requests.
 WARNING: When using the client you should use the "WithChainHash" or
 "WithChainInfo" option in order for your client to validate the randomness it
 receives is from the correct chain. You may use the "Insecurely" option to
-bypass this validation but it is not recommended.
+bypass this validation but it is not recommended. Every source is checked
+against the configured root of trust and sources advertising different chain
+information are refused with "ErrUntrustedInfo".
 
 In an application that uses the drand client, the following options are likely
 to be needed/customized:
@@ -53,6 +55,11 @@ to be needed/customized:
 		both should be set for increased security if you have
 		persistent state and expect to be following the chain.
 
+	WithTrustOnFirstUse()
+		pins the chain information seen on first use to a store,
+		such as "NewFilePinStore", and fails with a "PinMismatchError"
+		if a source later serves different parameters.
+
 	WithAutoWatch()
 		will pre-load new results as they become available adding them
 		to the cache for speedy retreival when you need them.
@@ -0,0 +1,287 @@
+package client
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/drand/drand/protobuf/drand"
+
+	"google.golang.org/protobuf/proto"
+)
+
+// Versions of the encoded random data, telling which signature the
+// randomness derives from. The responses of the public API carry no version.
+const (
+	// RandomDataV1 is the random data of the rounds signed with Sig.
+	RandomDataV1 = 1
+	// RandomDataV2 is the random data of the rounds signed with SigV2.
+	RandomDataV2 = 2
+)
+
+// encodingVersion returns the version the random data is encoded with: V2 when
+// the randomness derives from SigV2, V1 when it derives from Sig, and none
+// without signature.
+func (r *RandomData) encodingVersion() uint64 {
+	switch {
+	case r.version == RandomDataV2 || (len(r.Sig) == 0 && len(r.SigV2) > 0):
+		return RandomDataV2
+	case len(r.Sig) > 0:
+		return RandomDataV1
+	default:
+		return 0
+	}
+}
+
+// setVersion sets the version of decoded random data, checking that it holds
+// the signature of its version. Random data without version, as served by the
+// public API, is left as is.
+func (r *RandomData) setVersion(v uint64) error {
+	switch v {
+	case 0:
+	case RandomDataV1:
+		if len(r.Sig) == 0 {
+			return errors.New("random data v1 without signature")
+		}
+	case RandomDataV2:
+		if len(r.SigV2) == 0 {
+			return errors.New("random data v2 without signature v2")
+		}
+	default:
+		return fmt.Errorf("unsupported random data version %d", v)
+	}
+	r.version = byte(v)
+	return nil
+}
+
+// ToProto returns the protobuf representation of the random data, as served
+// by the drand public API, with its version.
+func (r *RandomData) ToProto() *drand.PublicRandResponse {
+	return &drand.PublicRandResponse{
+		Round:             r.Rnd,
+		Signature:         r.Sig,
+		PreviousSignature: r.PreviousSignature,
+		Randomness:        r.Random,
+		SignatureV2:       r.SigV2,
+		Version:           uint32(r.encodingVersion()),
+	}
+}
+
+// RandomDataFromProto returns the random data held in a public API response.
+// The version of the response is ignored when invalid.
+func RandomDataFromProto(p *drand.PublicRandResponse) *RandomData {
+	r := &RandomData{
+		Rnd:               p.GetRound(),
+		Random:            p.GetRandomness(),
+		Sig:               p.GetSignature(),
+		PreviousSignature: p.GetPreviousSignature(),
+		SigV2:             p.GetSignatureV2(),
+	}
+	_ = r.setVersion(uint64(p.GetVersion()))
+	return r
+}
+
+// MarshalProto encodes the random data as a protobuf message.
+func (r *RandomData) MarshalProto() ([]byte, error) {
+	return proto.Marshal(r.ToProto())
+}
+
+// UnmarshalProto decodes random data from a protobuf message.
+func (r *RandomData) UnmarshalProto(buff []byte) error {
+	p := new(drand.PublicRandResponse)
+	if err := proto.Unmarshal(buff, p); err != nil {
+		return err
+	}
+	out := RandomDataFromProto(p)
+	if err := out.setVersion(uint64(p.GetVersion())); err != nil {
+		return err
+	}
+	*r = *out
+	return nil
+}
+
+// CBOR major types used to encode random data.
+const (
+	cborUint  byte = 0
+	cborBytes byte = 2
+	cborText  byte = 3
+	cborMap   byte = 5
+)
+
+// MarshalCBOR encodes the random data as a CBOR map, keyed by the same field
+// names as the JSON encoding, and its version. Empty fields, and the version of
+// random data without signature, are omitted.
+func (r *RandomData) MarshalCBOR() ([]byte, error) {
+	fields := []struct {
+		key   string
+		value []byte
+	}{
+		{"randomness", r.Random},
+		{"signature", r.Sig},
+		{"previous_signature", r.PreviousSignature},
+		{"signaturev2", r.SigV2},
+	}
+	n := 1
+	version := r.encodingVersion()
+	if version != 0 {
+		n++
+	}
+	for _, f := range fields {
+		if len(f.value) > 0 {
+			n++
+		}
+	}
+
+	var buff bytes.Buffer
+	writeCBORHead(&buff, cborMap, uint64(n))
+	writeCBORText(&buff, "round")
+	writeCBORHead(&buff, cborUint, r.Rnd)
+	if version != 0 {
+		writeCBORText(&buff, "version")
+		writeCBORHead(&buff, cborUint, version)
+	}
+	for _, f := range fields {
+		if len(f.value) == 0 {
+			continue
+		}
+		writeCBORText(&buff, f.key)
+		writeCBORHead(&buff, cborBytes, uint64(len(f.value)))
+		buff.Write(f.value)
+	}
+	return buff.Bytes(), nil
+}
+
+// UnmarshalCBOR decodes random data from a CBOR map produced by MarshalCBOR.
+// Unknown keys are ignored as long as their values are integers, byte or text
+// strings.
+func (r *RandomData) UnmarshalCBOR(buff []byte) error {
+	rd := bytes.NewReader(buff)
+	major, n, err := readCBORHead(rd)
+	if err != nil {
+		return err
+	}
+	if major != cborMap {
+		return fmt.Errorf("cbor: expected a map, got major type %d", major)
+	}
+	var out RandomData
+	var version uint64
+	for i := uint64(0); i < n; i++ {
+		major, l, err := readCBORHead(rd)
+		if err != nil {
+			return err
+		}
+		if major != cborText {
+			return fmt.Errorf("cbor: expected a text key, got major type %d", major)
+		}
+		key, err := readCBORBytes(rd, l)
+		if err != nil {
+			return err
+		}
+		major, v, err := readCBORHead(rd)
+		if err != nil {
+			return err
+		}
+		var value []byte
+		switch major {
+		case cborUint:
+		case cborBytes, cborText:
+			if value, err = readCBORBytes(rd, v); err != nil {
+				return err
+			}
+		default:
+			return fmt.Errorf("cbor: unsupported major type %d for %q", major, key)
+		}
+		switch string(key) {
+		case "round":
+			if major != cborUint {
+				return errors.New("cbor: round is not an integer")
+			}
+			out.Rnd = v
+		case "version":
+			if major != cborUint {
+				return errors.New("cbor: version is not an integer")
+			}
+			version = v
+		case "randomness":
+			out.Random = value
+		case "signature":
+			out.Sig = value
+		case "previous_signature":
+			out.PreviousSignature = value
+		case "signaturev2":
+			out.SigV2 = value
+		}
+	}
+	if rd.Len() != 0 {
+		return errors.New("cbor: trailing data")
+	}
+	if err := out.setVersion(version); err != nil {
+		return fmt.Errorf("cbor: %w", err)
+	}
+	*r = out
+	return nil
+}
+
+func writeCBORHead(w *bytes.Buffer, major byte, n uint64) {
+	m := major << 5
+	switch {
+	case n < 24:
+		w.WriteByte(m | byte(n))
+	case n <= 0xff:
+		w.WriteByte(m | 24)
+		w.WriteByte(byte(n))
+	case n <= 0xffff:
+		w.WriteByte(m | 25)
+		_ = binary.Write(w, binary.BigEndian, uint16(n))
+	case n <= 0xffffffff:
+		w.WriteByte(m | 26)
+		_ = binary.Write(w, binary.BigEndian, uint32(n))
+	default:
+		w.WriteByte(m | 27)
+		_ = binary.Write(w, binary.BigEndian, n)
+	}
+}
+
+func writeCBORText(w *bytes.Buffer, s string) {
+	writeCBORHead(w, cborText, uint64(len(s)))
+	w.WriteString(s)
+}
+
+func readCBORHead(r *bytes.Reader) (major byte, n uint64, err error) {
+	b, err := r.ReadByte()
+	if err != nil {
+		return 0, 0, fmt.Errorf("cbor: %w", err)
+	}
+	major, info := b>>5, b&0x1f
+	switch {
+	case info < 24:
+		return major, uint64(info), nil
+	case info == 24:
+		v, err := r.ReadByte()
+		return major, uint64(v), err
+	case info == 25:
+		var v uint16
+		err = binary.Read(r, binary.BigEndian, &v)
+		return major, uint64(v), err
+	case info == 26:
+		var v uint32
+		err = binary.Read(r, binary.BigEndian, &v)
+		return major, uint64(v), err
+	case info == 27:
+		err = binary.Read(r, binary.BigEndian, &n)
+		return major, n, err
+	default:
+		return 0, 0, fmt.Errorf("cbor: unsupported additional information %d", info)
+	}
+}
+
+func readCBORBytes(r *bytes.Reader, n uint64) ([]byte, error) {
+	if n > uint64(r.Len()) {
+		return nil, fmt.Errorf("cbor: %w", io.ErrUnexpectedEOF)
+	}
+	b := make([]byte, n)
+	_, err := io.ReadFull(r, b)
+	return b, err
+}
@@ -0,0 +1,127 @@
+package client
+
+import (
+	"bytes"
+	"encoding/hex"
+	"reflect"
+	"testing"
+
+	"github.com/drand/drand/protobuf/drand"
+	"google.golang.org/protobuf/proto"
+)
+
+func testRandomData() *RandomData {
+	return &RandomData{
+		Rnd:               1969,
+		Random:            []byte("randomness"),
+		Sig:               bytes.Repeat([]byte{0x01}, 96),
+		PreviousSignature: bytes.Repeat([]byte{0x02}, 300),
+		SigV2:             []byte{0x03},
+		version:           RandomDataV1,
+	}
+}
+
+func TestRandomDataProto(t *testing.T) {
+	r := testRandomData()
+	buff, err := r.MarshalProto()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var r2 RandomData
+	if err := r2.UnmarshalProto(buff); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(r, &r2) {
+		t.Fatal("proto round trip mismatch", r, r2)
+	}
+}
+
+func TestRandomDataCBOR(t *testing.T) {
+	r := testRandomData()
+	buff, err := r.MarshalCBOR()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var r2 RandomData
+	if err := r2.UnmarshalCBOR(buff); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(r, &r2) {
+		t.Fatal("cbor round trip mismatch", r, r2)
+	}
+
+	// {"round": 1}
+	buff, err = (&RandomData{Rnd: 1}).MarshalCBOR()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if hex.EncodeToString(buff) != "a165726f756e6401" {
+		t.Fatalf("unexpected encoding %x", buff)
+	}
+
+	if err := r2.UnmarshalCBOR(buff[:len(buff)-1]); err == nil {
+		t.Fatal("truncated input should fail")
+	}
+	if err := r2.UnmarshalCBOR(append(buff, 0x00)); err == nil {
+		t.Fatal("trailing data should fail")
+	}
+}
+
+func TestRandomDataVersion(t *testing.T) {
+	v2 := &RandomData{Rnd: 2, Random: []byte("randomness"), SigV2: []byte{0x03}}
+	decoders := map[string]struct {
+		marshal   func(*RandomData) ([]byte, error)
+		unmarshal func(*RandomData, []byte) error
+	}{
+		"proto": {(*RandomData).MarshalProto, (*RandomData).UnmarshalProto},
+		"cbor":  {(*RandomData).MarshalCBOR, (*RandomData).UnmarshalCBOR},
+	}
+	for name, d := range decoders {
+		buff, err := d.marshal(v2)
+		if err != nil {
+			t.Fatal(name, err)
+		}
+		var r RandomData
+		if err := d.unmarshal(&r, buff); err != nil {
+			t.Fatal(name, err)
+		}
+		if r.version != RandomDataV2 || !bytes.Equal(r.Signature(), v2.SigV2) {
+			t.Fatal(name, "v2 random data should be signed with SigV2", r)
+		}
+
+		// both signatures, the randomness deriving from SigV2
+		both := testRandomData()
+		both.version = RandomDataV2
+		if buff, err = d.marshal(both); err != nil {
+			t.Fatal(name, err)
+		}
+		if err := d.unmarshal(&r, buff); err != nil {
+			t.Fatal(name, err)
+		}
+		if !reflect.DeepEqual(both, &r) {
+			t.Fatal(name, "round trip mismatch", both, r)
+		}
+	}
+
+	// a version without its signature, or unknown, is refused
+	for _, p := range []*drand.PublicRandResponse{
+		{Round: 1, Signature: []byte{0x01}, Version: RandomDataV2},
+		{Round: 1, SignatureV2: []byte{0x01}, Version: RandomDataV1},
+		{Round: 1, Signature: []byte{0x01}, Version: 3},
+	} {
+		buff, err := proto.Marshal(p)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var r RandomData
+		if err := r.UnmarshalProto(buff); err == nil {
+			t.Fatal("invalid version should fail", p)
+		}
+	}
+
+	// the responses of the public API have no version
+	r := RandomDataFromProto(&drand.PublicRandResponse{Round: 1, Signature: []byte{0x01}})
+	if r.version != 0 || !bytes.Equal(r.Signature(), []byte{0x01}) {
+		t.Fatal("unversioned random data should be signed with Sig", r)
+	}
+}
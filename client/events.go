@@ -0,0 +1,107 @@
+package client
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// EventType identifies the kind of an Event.
+type EventType int
+
+const (
+	// EventBackendDemoted is emitted when the source tried first by the
+	// client is replaced by another one, because it became slower or failed.
+	EventBackendDemoted EventType = iota
+	// EventVerificationFailed is emitted when a round served by a source
+	// fails verification.
+	EventVerificationFailed
+	// EventWatchReconnected is emitted when a watch of the client is
+	// restarted.
+	EventWatchReconnected
+	// EventChainInfoRefreshed is emitted when the chain info is fetched again
+	// and differs from the one used so far.
+	EventChainInfoRefreshed
+	// EventChainHandover is emitted when the client switches to the chain
+	// taking over the current one. Round is the last round of the current
+	// chain.
+	EventChainHandover
+)
+
+func (t EventType) String() string {
+	switch t {
+	case EventBackendDemoted:
+		return "backend-demoted"
+	case EventVerificationFailed:
+		return "verification-failed"
+	case EventWatchReconnected:
+		return "watch-reconnected"
+	case EventChainInfoRefreshed:
+		return "chain-info-refreshed"
+	case EventChainHandover:
+		return "chain-handover"
+	default:
+		return fmt.Sprintf("EventType(%d)", int(t))
+	}
+}
+
+// Event describes something noteworthy that happened in a client, so that
+// applications can alert on anomalies.
+type Event struct {
+	Type EventType
+	Time time.Time
+	// Source is the name of the source involved, if any. For
+	// EventBackendDemoted, it is the demoted source.
+	Source string
+	// Round is the round involved, if any.
+	Round uint64
+	// Err is the error that caused the event, if any.
+	Err error
+}
+
+func (e Event) String() string {
+	s := e.Type.String()
+	if e.Source != "" {
+		s += fmt.Sprintf(" source=%s", e.Source)
+	}
+	if e.Round != 0 {
+		s += fmt.Sprintf(" round=%d", e.Round)
+	}
+	if e.Err != nil {
+		s += fmt.Sprintf(" err=%q", e.Err)
+	}
+	return s
+}
+
+// WithEventHandler registers a function called with the events of the
+// client. It may be given several times to register several handlers.
+// Handlers are called synchronously from the goroutines of the client, and
+// should hand events over rather than block.
+func WithEventHandler(h func(Event)) Option {
+	return func(cfg *clientConfig) error {
+		if h == nil {
+			return errors.New("nil event handler")
+		}
+		if cfg.events == nil {
+			cfg.events = new(eventBus)
+		}
+		cfg.events.handlers = append(cfg.events.handlers, h)
+		return nil
+	}
+}
+
+// eventBus dispatches the events of a client to its handlers. A nil bus
+// discards events.
+type eventBus struct {
+	handlers []func(Event)
+}
+
+func (b *eventBus) emit(e Event) {
+	if b == nil {
+		return
+	}
+	e.Time = time.Now()
+	for _, h := range b.handlers {
+		h(e)
+	}
+}
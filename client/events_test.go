@@ -0,0 +1,59 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/drand/drand/client/test/result/mock"
+)
+
+func TestEventVerificationFailed(t *testing.T) {
+	info, results := mock.VerifiableResults(5, 1)
+	results[2].SigV2 = results[1].SigV2
+	source := &infoMockClient{&MockClient{Results: results, StrictRounds: true}, info}
+
+	var lk sync.Mutex
+	var events []Event
+	c, err := New(From(source), WithChainInfo(info), WithEventHandler(func(e Event) {
+		lk.Lock()
+		events = append(events, e)
+		lk.Unlock()
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	if _, err := c.Get(context.Background(), 3); err == nil {
+		t.Fatal("round 3 should not verify")
+	}
+	lk.Lock()
+	defer lk.Unlock()
+	for _, e := range events {
+		if e.Type == EventVerificationFailed && e.Round == 3 && e.Err != nil && !e.Time.IsZero() {
+			return
+		}
+	}
+	t.Fatal("missing verification failure event", events)
+}
+
+func TestEventBackendDemoted(t *testing.T) {
+	c0 := MockClientWithResults(0, 5)
+	c1 := MockClientWithResults(0, 5)
+	oc, err := newOptimizingClient([]Client{c0, c1}, 0, 0, 0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	demoted := make(chan Event, 1)
+	oc.events = &eventBus{handlers: []func(Event){func(e Event) { demoted <- e }}}
+
+	first := oc.fastestClients()[0]
+	oc.updateStats([]*requestStat{{client: first, rtt: time.Hour, startTime: time.Now()}})
+	e := <-demoted
+	if e.Type != EventBackendDemoted || e.Source != fmt.Sprint(first) {
+		t.Fatal("unexpected event", e)
+	}
+}
@@ -0,0 +1,71 @@
+package client
+
+import (
+	"context"
+	"time"
+)
+
+// maxFilterSearch bounds the number of rounds examined to find the next round
+// selected by a filter.
+const maxFilterSearch = 1 << 20
+
+// RoundFilter selects rounds by their number.
+type RoundFilter func(round uint64) bool
+
+// EveryNth selects every nth round, the rounds multiple of n.
+func EveryNth(n uint64) RoundFilter {
+	if n == 0 {
+		n = 1
+	}
+	return func(round uint64) bool {
+		return round%n == 0
+	}
+}
+
+// WatchFiltered returns the rounds selected by the filter as they are
+// produced. Unlike filtering the results of `Watch`, only the selected rounds
+// are fetched and verified: the client waits for the time of the next
+// selected round, then gets it. Rounds that can not be fetched are skipped.
+// The channel is closed when the context is done, when the chain info can not
+// be fetched, or when no round is selected among the next ones.
+func WatchFiltered(ctx context.Context, c Client, filter RoundFilter) <-chan Result {
+	out := make(chan Result)
+	go func() {
+		defer close(out)
+		info, err := c.Info(ctx)
+		if err != nil {
+			return
+		}
+		next := info.RoundAt(time.Now()) + 1
+		for {
+			round, ok := nextSelected(next, filter)
+			if !ok {
+				return
+			}
+			r, err := WaitForRound(ctx, c, round)
+			if ctx.Err() != nil {
+				return
+			}
+			next = round + 1
+			if err != nil {
+				continue
+			}
+			select {
+			case out <- r:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return out
+}
+
+// nextSelected returns the first round from `from` selected by the filter.
+func nextSelected(from uint64, filter RoundFilter) (uint64, bool) {
+	for r := from; r < from+maxFilterSearch; r++ {
+		if filter(r) {
+			return r, true
+		}
+	}
+	return 0, false
+}
@@ -0,0 +1,59 @@
+package client
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/drand/drand/chain"
+)
+
+func TestWatchFiltered(t *testing.T) {
+	info := fakeChainInfo()
+	info.GenesisTime = time.Now().Unix() - 10
+	current := chain.CurrentRound(time.Now().Unix(), info.Period, info.GenesisTime)
+
+	mc := MockClientWithResults(current, current+10)
+	mc.StrictRounds = true
+	source := &countingGetClient{Client: &infoMockClient{mc, info}}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	results := WatchFiltered(ctx, source, EveryNth(3))
+	for i := 0; i < 2; i++ {
+		r := nextResultWithin(t, results, 5*time.Second)
+		if r.Round()%3 != 0 || r.Round() <= current {
+			t.Fatal("unexpected round", r.Round())
+		}
+	}
+	if calls := atomic.LoadInt32(&source.calls); calls != 2 {
+		t.Fatal("only the selected rounds should be fetched, got calls:", calls)
+	}
+	cancel()
+	for range results {
+	}
+}
+
+func TestNextSelected(t *testing.T) {
+	if r, ok := nextSelected(7, EveryNth(5)); !ok || r != 10 {
+		t.Fatal("unexpected round", r)
+	}
+	if _, ok := nextSelected(1, func(uint64) bool { return false }); ok {
+		t.Fatal("no round should be selected")
+	}
+}
+
+func nextResultWithin(t *testing.T, ch <-chan Result, d time.Duration) Result {
+	t.Helper()
+	select {
+	case r, ok := <-ch:
+		if !ok {
+			t.Fatal("channel closed")
+		}
+		return r
+	case <-time.After(d):
+		t.Fatal("timed out waiting for result")
+	}
+	return nil
+}
@@ -0,0 +1,69 @@
+package grpc
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/drand/drand/client"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+)
+
+// WithHeaders adds static metadata, such as API keys, to every request of the
+// client.
+func WithHeaders(headers map[string]string) Option {
+	return func(o *clientOptions) {
+		if o.headers == nil {
+			o.headers = make(map[string]string)
+		}
+		for k, v := range headers {
+			o.headers[k] = v
+		}
+	}
+}
+
+// WithTokenProvider authenticates every request of the client with a bearer
+// token returned by `tp`, sent as the "authorization" metadata.
+func WithTokenProvider(tp client.TokenProvider) Option {
+	return func(o *clientOptions) {
+		o.token = tp
+	}
+}
+
+// dialOptions returns the dial options applying the options.
+func (o *clientOptions) dialOptions(secure bool) []grpc.DialOption {
+	if len(o.headers) == 0 && o.token == nil {
+		return nil
+	}
+	return []grpc.DialOption{grpc.WithPerRPCCredentials(&rpcAuth{clientOptions: o, secure: secure})}
+}
+
+// rpcAuth adds the authentication metadata to every request.
+type rpcAuth struct {
+	*clientOptions
+	secure bool
+}
+
+var _ credentials.PerRPCCredentials = (*rpcAuth)(nil)
+
+func (a *rpcAuth) GetRequestMetadata(ctx context.Context, _ ...string) (map[string]string, error) {
+	md := make(map[string]string, len(a.headers)+1)
+	for k, v := range a.headers {
+		md[k] = v
+	}
+	if a.token != nil {
+		token, err := a.token(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("getting token: %w", err)
+		}
+		md["authorization"] = "Bearer " + token
+	}
+	return md, nil
+}
+
+// RequireTransportSecurity allows credentials to be sent over insecure
+// connections only when the client was explicitly created as insecure.
+func (a *rpcAuth) RequireTransportSecurity() bool {
+	return a.secure
+}
@@ -27,7 +27,11 @@ type grpcClient struct {
 }
 
 // New creates a drand client backed by a GRPC connection.
-func New(address, certPath string, insecure bool) (client.Client, error) {
+func New(address, certPath string, insecure bool, options ...Option) (client.Client, error) {
+	o := new(clientOptions)
+	for _, opt := range options {
+		opt(o)
+	}
 	opts := []grpc.DialOption{}
 	if certPath != "" {
 		creds, err := credentials.NewClientTLSFromFile(certPath, "")
@@ -40,6 +44,12 @@ func New(address, certPath string, insecure bool) (client.Client, error) {
 	} else {
 		opts = append(opts, grpc.WithTransportCredentials(credentials.NewTLS(&tls.Config{})))
 	}
+	opts = append(opts, o.dialOptions(certPath != "" || !insecure)...)
+	proxyOpts, err := o.proxyDialOptions()
+	if err != nil {
+		return nil, err
+	}
+	opts = append(opts, proxyOpts...)
 	opts = append(opts,
 		grpc.WithUnaryInterceptor(grpc_prometheus.UnaryClientInterceptor),
 		grpc.WithStreamInterceptor(grpc_prometheus.StreamClientInterceptor),
@@ -119,11 +129,11 @@ func (g *grpcClient) translate(stream drand.Public_PublicRandStreamClient, out c
 func (g *grpcClient) RoundAt(t time.Time) uint64 {
 	ctx, cancel := context.WithTimeout(context.Background(), grpcDefaultTimeout)
 	defer cancel()
-	info, err := g.client.ChainInfo(ctx, &drand.ChainInfoRequest{})
+	info, err := g.Info(ctx)
 	if err != nil {
 		return 0
 	}
-	return chain.CurrentRound(t.Unix(), time.Second*time.Duration(info.Period), info.GenesisTime)
+	return info.RoundAt(t)
 }
 
 // SetLog configures the client log output
@@ -7,6 +7,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/drand/drand/client"
 	"github.com/drand/drand/test/mock"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
@@ -97,3 +98,62 @@ func TestClientClose(t *testing.T) {
 
 	wg.Wait() // wait for the watch to close
 }
+
+func TestReconnectingClientWatch(t *testing.T) {
+	l, server := mock.NewMockGRPCPublicServer("localhost:0", false)
+	addr := l.Addr()
+	go l.Start()
+	defer l.Stop(context.Background())
+
+	c, err := NewReconnecting(addr, "", true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+	emit := server.(mock.MockService).EmitRand
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	res := c.Watch(ctx)
+
+	time.Sleep(100 * time.Millisecond)
+	emit(false)
+	r1, ok := <-res
+	if !ok {
+		t.Fatal("watch should work")
+	}
+
+	// break the stream: the client should reconnect and keep delivering.
+	emit(true)
+	time.Sleep(time.Second)
+	emit(false)
+	r2, ok := <-res
+	if !ok {
+		t.Fatal("watch should survive a broken stream")
+	}
+	if r2.Round() != r1.Round()+1 {
+		t.Fatal("unexpected round after reconnection", r1.Round(), r2.Round())
+	}
+}
+
+func TestClientAuth(t *testing.T) {
+	o := new(clientOptions)
+	WithHeaders(map[string]string{"x-api-key": "key"})(o)
+	WithTokenProvider(client.StaticToken("secret"))(o)
+	dial := o.dialOptions(true)
+	if len(dial) != 1 {
+		t.Fatal("expected per-RPC credentials")
+	}
+
+	auth := &rpcAuth{clientOptions: o, secure: true}
+	md, err := auth.GetRequestMetadata(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if md["x-api-key"] != "key" || md["authorization"] != "Bearer secret" {
+		t.Fatal("unexpected metadata", md)
+	}
+	if len(new(clientOptions).dialOptions(true)) != 0 {
+		t.Fatal("no credentials expected without options")
+	}
+}
@@ -35,5 +35,9 @@ Example:
 A path to a file that holds TLS credentials for the drand server is required
 to validate server connections. Alternatively set the final parameter to
 `true` to enable _insecure_ connections (not recommended).
+
+The stream returned by "Watch" ends when the connection to the server breaks.
+Use the "NewReconnecting" constructor for a client that re-establishes the
+stream with backoff and backfills the rounds it missed in the meantime.
 */
 package grpc
@@ -0,0 +1,16 @@
+package grpc
+
+import (
+	"net/url"
+
+	"github.com/drand/drand/client"
+)
+
+// Option configures a gRPC client.
+type Option func(o *clientOptions)
+
+type clientOptions struct {
+	headers map[string]string
+	token   client.TokenProvider
+	proxy   *url.URL
+}
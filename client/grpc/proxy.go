@@ -0,0 +1,33 @@
+package grpc
+
+import (
+	"context"
+	"net"
+	"net/url"
+
+	"github.com/drand/drand/client/proxy"
+
+	"google.golang.org/grpc"
+)
+
+// WithProxy makes the client connect through the proxy at the given URL,
+// whose scheme is one of socks5, socks5h or http.
+func WithProxy(u *url.URL) Option {
+	return func(o *clientOptions) {
+		o.proxy = u
+	}
+}
+
+// proxyDialOptions returns the dial options connecting through the proxy.
+func (o *clientOptions) proxyDialOptions() ([]grpc.DialOption, error) {
+	if o.proxy == nil {
+		return nil, nil
+	}
+	d, err := proxy.New(o.proxy)
+	if err != nil {
+		return nil, err
+	}
+	return []grpc.DialOption{grpc.WithContextDialer(func(ctx context.Context, addr string) (net.Conn, error) {
+		return d.DialContext(ctx, "tcp", addr)
+	})}, nil
+}
@@ -0,0 +1,93 @@
+package grpc
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/drand/drand/client"
+	"github.com/drand/drand/protobuf/drand"
+)
+
+// reconnectingClient is a gRPC client whose Watch survives connection loss.
+type reconnectingClient struct {
+	*grpcClient
+
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+// NewReconnecting creates a drand client backed by a GRPC connection whose
+// `Watch` stream is re-established with backoff whenever it breaks. Rounds
+// produced while the stream was down are fetched with `Get` once streaming
+// resumes, and rounds are never delivered twice.
+func NewReconnecting(address, certPath string, insecure bool, options ...Option) (client.Client, error) {
+	c, err := New(address, certPath, insecure, options...)
+	if err != nil {
+		return nil, err
+	}
+	return &reconnectingClient{
+		grpcClient: c.(*grpcClient),
+		done:       make(chan struct{}),
+	}, nil
+}
+
+// String returns the name of this client.
+func (r *reconnectingClient) String() string {
+	return fmt.Sprintf("GRPC(%q).(+reconnect)", r.address)
+}
+
+// Watch returns new randomness as it becomes available, reconnecting the
+// underlying stream as needed until the context is done or the client closed.
+func (r *reconnectingClient) Watch(ctx context.Context) <-chan client.Result {
+	return client.WatchReconnecting(ctx, r.done, r.stream, func(last uint64, backoff time.Duration, err error) {
+		r.l.Warn("grpc_client", "public rand stream interrupted", "last", last, "retry", backoff, "err", err)
+	})
+}
+
+// stream forwards rounds after `last` to out until the stream breaks. Gaps
+// between the last delivered round and the streamed ones are filled with
+// unary requests. It returns the last round delivered.
+func (r *reconnectingClient) stream(ctx context.Context, last uint64, out chan<- client.Result) (uint64, error) {
+	stream, err := r.client.PublicRandStream(ctx, &drand.PublicRandRequest{Round: 0})
+	if err != nil {
+		return last, err
+	}
+	send := func(res client.Result) bool {
+		select {
+		case out <- res:
+			last = res.Round()
+			return true
+		case <-ctx.Done():
+			return false
+		}
+	}
+	for {
+		next, err := stream.Recv()
+		if err != nil {
+			return last, err
+		}
+		if next.Round <= last {
+			continue
+		}
+		for missed := last + 1; last > 0 && missed < next.Round; missed++ {
+			res, err := r.Get(ctx, missed)
+			if err != nil {
+				return last, fmt.Errorf("backfilling round %d: %w", missed, err)
+			}
+			if !send(res) {
+				return last, ctx.Err()
+			}
+		}
+		if !send(asRD(next)) {
+			return last, ctx.Err()
+		}
+	}
+}
+
+// Close stops all streams and tears down the gRPC connection.
+func (r *reconnectingClient) Close() error {
+	r.closeOnce.Do(func() { close(r.done) })
+	return r.grpcClient.Close()
+}
@@ -0,0 +1,340 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/drand/drand/chain"
+	"github.com/drand/drand/log"
+)
+
+// defaultHandoverInterval is how often sources are asked for a handover of
+// the chain by default.
+const defaultHandoverInterval = 10 * time.Minute
+
+// HandoverSource is implemented by sources able to tell whether the chain
+// they serve is handed over to another chain.
+type HandoverSource interface {
+	// Handover returns the handover announced for the chain, or nil when
+	// none is announced.
+	Handover(ctx context.Context) (*chain.Handover, error)
+}
+
+// WithHandover follows the handovers of the chain to its successors, for
+// instance when the network migrates to a new genesis or scheme. While
+// watching, the sources implementing HandoverSource are asked every
+// `interval`, 10 minutes by default, whether the chain is handed over, and a
+// handover announced in the trusted chain info is used directly. Handovers are
+// only followed when signed by the group of the current chain.
+//
+// Once the last round of the current chain is delivered, or the successor
+// chain started, `connect` is called with the information of the successor
+// chain to create its sources, which are verified against it. `Watch` then
+// continues with the rounds of the successor chain, while `Get`, `Info` and
+// `RoundAt` serve the successor chain once it started. With a pin store, the
+// handover is pinned along with the chain information, so that restarted
+// clients follow it without asking sources again.
+func WithHandover(connect func(successor *chain.Info) ([]Client, error), interval time.Duration) Option {
+	return func(cfg *clientConfig) error {
+		if connect == nil {
+			return errors.New("nil handover connect function")
+		}
+		if interval < 0 {
+			return errors.New("handover interval must not be negative")
+		}
+		if interval == 0 {
+			interval = defaultHandoverInterval
+		}
+		cfg.handoverConnect = connect
+		cfg.handoverInterval = interval
+		return nil
+	}
+}
+
+// newSuccessor creates the client of the chain taking over, configured like
+// the current one.
+func (c *clientConfig) newSuccessor(info *chain.Info) (Client, error) {
+	sources, err := c.handoverConnect(info)
+	if err != nil {
+		return nil, err
+	}
+	opts := []Option{
+		From(sources...),
+		WithChainInfo(info),
+		WithCacheSize(c.cacheSize),
+		WithLogger(c.log),
+		WithHandover(c.handoverConnect, c.handoverInterval),
+	}
+	if c.events != nil {
+		for _, h := range c.events.handlers {
+			opts = append(opts, WithEventHandler(h))
+		}
+	}
+	return New(opts...)
+}
+
+// newHandoverClient wraps a client to follow the handovers of its chain.
+func newHandoverClient(c Client, cfg *clientConfig, sources []HandoverSource) *handoverClient {
+	hc := &handoverClient{
+		Client:       c,
+		sources:      sources,
+		newSuccessor: cfg.newSuccessor,
+		pin:          cfg.pinStore,
+		interval:     cfg.handoverInterval,
+		log:          cfg.log,
+		events:       cfg.events,
+	}
+	if cfg.chainInfo != nil && cfg.chainInfo.Handover != nil {
+		if err := cfg.chainInfo.Handover.Verify(cfg.chainInfo); err != nil {
+			hc.log.Warn("handover", "ignoring invalid handover of the chain info", "err", err)
+		} else {
+			hc.handover = cfg.chainInfo.Handover
+		}
+	}
+	return hc
+}
+
+type handoverClient struct {
+	Client
+	sources      []HandoverSource
+	newSuccessor func(*chain.Info) (Client, error)
+	pin          PinStore
+	interval     time.Duration
+	log          log.Logger
+	events       *eventBus
+
+	lk        sync.Mutex
+	handover  *chain.Handover
+	successor Client
+	// followLk serializes the creation of the successor client.
+	followLk sync.Mutex
+}
+
+// SetLog configures the client log output.
+func (c *handoverClient) SetLog(l log.Logger) {
+	c.log = l
+	trySetLog(c.Client, l)
+}
+
+// String returns the name of this client.
+func (c *handoverClient) String() string {
+	return fmt.Sprintf("%s.(+handover)", c.Client)
+}
+
+// Get returns the randomness at `round` of the successor chain once it
+// started, and of the current chain before.
+func (c *handoverClient) Get(ctx context.Context, round uint64) (Result, error) {
+	return c.current().Get(ctx, round)
+}
+
+// Info returns the information of the successor chain once it started, and
+// of the current chain before.
+func (c *handoverClient) Info(ctx context.Context) (*chain.Info, error) {
+	return c.current().Info(ctx)
+}
+
+// RoundAt returns the round of the successor chain at the given time once
+// it started, and of the current chain before.
+func (c *handoverClient) RoundAt(t time.Time) uint64 {
+	return c.current().RoundAt(t)
+}
+
+// Close stops the client, and the client of the successor chain if any.
+func (c *handoverClient) Close() error {
+	c.lk.Lock()
+	successor := c.successor
+	c.lk.Unlock()
+	err := c.Client.Close()
+	if successor != nil {
+		if serr := successor.Close(); err == nil {
+			err = serr
+		}
+	}
+	return err
+}
+
+// current returns the client serving the chain running now.
+func (c *handoverClient) current() Client {
+	c.lk.Lock()
+	h := c.handover
+	c.lk.Unlock()
+	if h == nil || time.Now().Unix() < h.Successor.GenesisTime {
+		return c.Client
+	}
+	s, err := c.follow()
+	if err != nil {
+		return c.Client
+	}
+	return s
+}
+
+// Watch returns new randomness as it becomes available, continuing with the
+// rounds of the successor chain once the current chain is handed over.
+func (c *handoverClient) Watch(ctx context.Context) <-chan Result {
+	out := make(chan Result)
+	go func() {
+		defer close(out)
+		if !c.watchCurrent(ctx, out) {
+			return
+		}
+		successor := c.waitSuccessor(ctx)
+		if successor == nil {
+			return
+		}
+		for r := range successor.Watch(ctx) {
+			select {
+			case out <- r:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return out
+}
+
+// watchCurrent delivers the rounds of the current chain until its last
+// round, returning whether the chain was handed over.
+func (c *handoverClient) watchCurrent(ctx context.Context, out chan<- Result) bool {
+	c.lk.Lock()
+	switched := c.successor != nil
+	c.lk.Unlock()
+	if switched {
+		return true
+	}
+
+	wctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	in := c.Client.Watch(wctx)
+
+	ticker := time.NewTicker(c.interval)
+	defer ticker.Stop()
+	var started <-chan time.Time
+	h := c.detect(ctx)
+	if h != nil {
+		started = c.startTimer(h)
+	}
+	for {
+		select {
+		case r, ok := <-in:
+			if !ok {
+				return false
+			}
+			if h != nil && r.Round() > h.Round {
+				continue
+			}
+			select {
+			case out <- r:
+			case <-ctx.Done():
+				return false
+			}
+			if h != nil && r.Round() == h.Round {
+				return true
+			}
+		case <-ticker.C:
+			if h == nil {
+				if h = c.detect(ctx); h != nil {
+					started = c.startTimer(h)
+				}
+			}
+		case <-started:
+			return true
+		case <-ctx.Done():
+			return false
+		}
+	}
+}
+
+// startTimer returns a channel receiving when the successor chain of a
+// handover starts.
+func (c *handoverClient) startTimer(h *chain.Handover) <-chan time.Time {
+	return time.After(time.Until(time.Unix(h.Successor.GenesisTime, 0)))
+}
+
+// waitSuccessor returns the client of the successor chain, retrying to
+// create it every period of the successor chain until `ctx` is done.
+func (c *handoverClient) waitSuccessor(ctx context.Context) Client {
+	for {
+		s, err := c.follow()
+		if err == nil {
+			return s
+		}
+		c.lk.Lock()
+		period := c.handover.Successor.Period
+		c.lk.Unlock()
+		select {
+		case <-time.After(period):
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}
+
+// detect returns the handover of the chain, asking the sources for it when
+// it is not known yet.
+func (c *handoverClient) detect(ctx context.Context) *chain.Handover {
+	c.lk.Lock()
+	h := c.handover
+	c.lk.Unlock()
+	if h != nil || len(c.sources) == 0 {
+		return h
+	}
+
+	info, err := c.Client.Info(ctx)
+	if err != nil {
+		c.log.Warn("handover", "could not get chain info", "err", err)
+		return nil
+	}
+	for _, s := range c.sources {
+		h, err := s.Handover(ctx)
+		if err != nil {
+			c.log.Debug("handover", "could not get handover", "source", s, "err", err)
+			continue
+		}
+		if h == nil {
+			continue
+		}
+		if err := h.Verify(info); err != nil {
+			c.log.Warn("handover", "invalid handover", "source", s, "err", err)
+			c.events.emit(Event{Type: EventVerificationFailed, Source: fmt.Sprint(s), Round: h.Round, Err: err})
+			continue
+		}
+		c.log.Info("handover", "chain handed over", "last_round", h.Round, "successor", fmt.Sprintf("%x", h.Successor.Hash()))
+		c.lk.Lock()
+		c.handover = h
+		c.lk.Unlock()
+		if c.pin != nil {
+			pinned := *info
+			pinned.Handover = h
+			if err := c.pin.Store(&pinned); err != nil {
+				c.log.Warn("handover", "could not pin handover", "err", err)
+			}
+		}
+		return h
+	}
+	return nil
+}
+
+// follow returns the client of the successor chain, creating it on first use.
+func (c *handoverClient) follow() (Client, error) {
+	c.followLk.Lock()
+	defer c.followLk.Unlock()
+	c.lk.Lock()
+	s, h := c.successor, c.handover
+	c.lk.Unlock()
+	if s != nil {
+		return s, nil
+	}
+	s, err := c.newSuccessor(h.Successor)
+	if err != nil {
+		c.log.Warn("handover", "could not connect to successor chain", "err", err)
+		return nil, err
+	}
+	c.lk.Lock()
+	c.successor = s
+	c.lk.Unlock()
+	c.events.emit(Event{Type: EventChainHandover, Source: fmt.Sprint(s), Round: h.Round})
+	return s, nil
+}
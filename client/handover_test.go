@@ -0,0 +1,135 @@
+package client
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/drand/drand/chain"
+	"github.com/drand/drand/client/test/result/mock"
+	"github.com/drand/drand/key"
+	"github.com/drand/drand/log"
+	"github.com/drand/kyber/util/random"
+)
+
+type handoverMockClient struct {
+	*infoMockClient
+	handover *chain.Handover
+}
+
+func (m *handoverMockClient) Handover(ctx context.Context) (*chain.Handover, error) {
+	return m.handover, nil
+}
+
+// signedHandover returns a chain info and a handover of that chain at round
+// `last`, signed by its group.
+func signedHandover(t *testing.T, last uint64, successor *chain.Info) (*chain.Info, *chain.Handover) {
+	priv := key.KeyGroup.Scalar().Pick(random.New())
+	info := &chain.Info{
+		PublicKey:   key.KeyGroup.Point().Mul(priv, nil),
+		Period:      time.Second,
+		GenesisTime: time.Now().Unix() - 100,
+	}
+	h := &chain.Handover{Round: last, Successor: successor}
+	sig, err := key.AuthScheme.Sign(priv, h.SignatureMessage(info))
+	if err != nil {
+		t.Fatal(err)
+	}
+	h.Signature = sig
+	return info, h
+}
+
+func TestHandoverWatch(t *testing.T) {
+	successorInfo := fakeChainInfo()
+	successorInfo.GenesisTime = time.Now().Unix() + 3600
+	info, h := signedHandover(t, 3, successorInfo)
+
+	current := &MockClient{WatchCh: make(chan Result, 3)}
+	for _, round := range []uint64{2, 3, 4} {
+		r := mock.NewMockResult(round)
+		current.WatchCh <- &r
+	}
+	source := &handoverMockClient{&infoMockClient{current, info}, h}
+
+	successor := &MockClient{WatchCh: make(chan Result, 2)}
+	for _, round := range []uint64{1, 2} {
+		r := mock.NewMockResult(round)
+		successor.WatchCh <- &r
+	}
+
+	events := make(chan Event, 1)
+	cfg := &clientConfig{
+		log:              log.DefaultLogger(),
+		handoverInterval: time.Hour,
+		events:           &eventBus{handlers: []func(Event){func(e Event) { events <- e }}},
+	}
+	hc := newHandoverClient(source, cfg, []HandoverSource{source})
+	var connected *chain.Info
+	hc.newSuccessor = func(info *chain.Info) (Client, error) {
+		connected = info
+		return successor, nil
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	results := hc.Watch(ctx)
+	expectRound(t, nextResult(t, results), 2)
+	expectRound(t, nextResult(t, results), 3)
+	// round 4 of the handed over chain is not delivered
+	expectRound(t, nextResult(t, results), 1)
+	expectRound(t, nextResult(t, results), 2)
+
+	if connected == nil || !connected.Equal(successorInfo) {
+		t.Fatal("successor chain not connected with its info")
+	}
+	select {
+	case e := <-events:
+		if e.Type != EventChainHandover || e.Round != 3 {
+			t.Fatal("unexpected event", e)
+		}
+	default:
+		t.Fatal("no handover event")
+	}
+}
+
+func TestHandoverIgnoresForgedHandover(t *testing.T) {
+	successorInfo := fakeChainInfo()
+	successorInfo.GenesisTime = time.Now().Unix() + 3600
+	_, h := signedHandover(t, 3, successorInfo)
+	// the handover is signed by another group
+	info, _ := signedHandover(t, 3, successorInfo)
+
+	cfg := &clientConfig{log: log.DefaultLogger(), handoverInterval: time.Hour}
+	source := &handoverMockClient{&infoMockClient{new(MockClient), info}, h}
+	hc := newHandoverClient(source, cfg, []HandoverSource{source})
+	if hc.detect(context.Background()) != nil {
+		t.Fatal("forged handover followed")
+	}
+}
+
+func TestHandoverFromChainInfo(t *testing.T) {
+	successorInfo := fakeChainInfo()
+	successorInfo.GenesisTime = time.Now().Unix() - 10
+	info, h := signedHandover(t, 3, successorInfo)
+	info.Handover = h
+
+	successor := MockClientWithResults(7, 8)
+	cfg := &clientConfig{log: log.DefaultLogger(), handoverInterval: time.Hour, chainInfo: info}
+	hc := newHandoverClient(&infoMockClient{MockClientWithResults(100, 101), info}, cfg, nil)
+	hc.newSuccessor = func(info *chain.Info) (Client, error) {
+		return successor, nil
+	}
+
+	// the successor chain started, so it serves the requests.
+	r, err := hc.Get(context.Background(), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectRound(t, r, 7)
+}
+
+func TestHandoverOption(t *testing.T) {
+	if _, err := New(From(MockClientWithResults(1, 5)), Insecurely(), WithHandover(nil, 0)); err == nil {
+		t.Fatal("handover connect function is required")
+	}
+}
@@ -0,0 +1,77 @@
+package http
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	nhttp "net/http"
+	"strings"
+
+	"github.com/drand/drand/client"
+
+	json "github.com/nikkolasg/hexjson"
+)
+
+// NewForChain creates a client for one of the chains served by a multi-chain
+// relay. Requests are routed to the endpoints under `<url>/<chain hash>/` and
+// the relay must advertise chain information matching the given hash.
+func NewForChain(url string, chainHash []byte, transport nhttp.RoundTripper, opts ...Option) (client.Client, error) {
+	if len(chainHash) == 0 {
+		return nil, errors.New("a chain hash is required to select a chain")
+	}
+	return New(chainURL(url, chainHash), chainHash, transport, opts...)
+}
+
+// ForURLsAndChain provides a shortcut for creating a set of HTTP clients for
+// the given chain on a set of multi-chain relays.
+func ForURLsAndChain(urls []string, chainHash []byte, opts ...Option) []client.Client {
+	chainURLs := make([]string, 0, len(urls))
+	for _, u := range urls {
+		chainURLs = append(chainURLs, chainURL(u, chainHash))
+	}
+	return ForURLs(chainURLs, chainHash, opts...)
+}
+
+// Chains returns the hashes of the chains served by the relay at url.
+func Chains(ctx context.Context, url string, transport nhttp.RoundTripper) ([][]byte, error) {
+	if transport == nil {
+		transport = nhttp.DefaultTransport
+	}
+	if !strings.HasSuffix(url, "/") {
+		url += "/"
+	}
+	req, err := nhttp.NewRequestWithContext(ctx, "GET", url+"chains", nil)
+	if err != nil {
+		return nil, fmt.Errorf("creating request: %w", err)
+	}
+	resp, err := (&nhttp.Client{Transport: transport}).Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("doing request: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != nhttp.StatusOK {
+		return nil, fmt.Errorf("%s does not list chains: %s", url, resp.Status)
+	}
+
+	var hashes []string
+	if err := json.NewDecoder(resp.Body).Decode(&hashes); err != nil {
+		return nil, fmt.Errorf("decoding response: %w", err)
+	}
+	chains := make([][]byte, 0, len(hashes))
+	for _, h := range hashes {
+		hash, err := hex.DecodeString(h)
+		if err != nil {
+			return nil, fmt.Errorf("invalid chain hash %q: %w", h, err)
+		}
+		chains = append(chains, hash)
+	}
+	return chains, nil
+}
+
+func chainURL(url string, chainHash []byte) string {
+	if !strings.HasSuffix(url, "/") {
+		url += "/"
+	}
+	return url + hex.EncodeToString(chainHash) + "/"
+}
@@ -0,0 +1,81 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"net/http/httputil"
+	"net/url"
+	"testing"
+
+	"github.com/drand/drand/client/test/http/mock"
+
+	json "github.com/nikkolasg/hexjson"
+)
+
+// newMultiChainRelay serves the mock chain under its hash prefix, next to a
+// listing of the served chains.
+func newMultiChainRelay(t *testing.T) (*httptest.Server, []byte, func()) {
+	addr, chainInfo, cancel, _ := mock.NewMockHTTPPublicServer(t, false)
+	target, err := url.Parse("http://" + addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	hash := hex.EncodeToString(chainInfo.Hash())
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/chains", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode([]string{hash})
+	})
+	mux.Handle("/"+hash+"/", http.StripPrefix("/"+hash, httputil.NewSingleHostReverseProxy(target)))
+	srv := httptest.NewServer(mux)
+	return srv, chainInfo.Hash(), func() {
+		srv.Close()
+		cancel()
+	}
+}
+
+func TestHTTPChains(t *testing.T) {
+	srv, hash, cancel := newMultiChainRelay(t)
+	defer cancel()
+
+	chains, err := Chains(context.Background(), srv.URL, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(chains) != 1 || !bytes.Equal(chains[0], hash) {
+		t.Fatal("unexpected chains", chains)
+	}
+}
+
+func TestHTTPNewForChain(t *testing.T) {
+	srv, hash, cancel := newMultiChainRelay(t)
+	defer cancel()
+
+	c, err := NewForChain(srv.URL, hash, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+	info, err := c.Info(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(info.Hash(), hash) {
+		t.Fatal("unexpected chain info")
+	}
+	if _, err := c.Get(context.Background(), 0); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := NewForChain(srv.URL, nil, nil); err == nil {
+		t.Fatal("a chain hash should be required")
+	}
+	other := append([]byte{}, hash...)
+	other[0]++
+	if _, err := NewForChain(srv.URL, other, nil); err == nil {
+		t.Fatal("unknown chains should be refused")
+	}
+}
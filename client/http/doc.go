@@ -35,6 +35,16 @@ The "ForURLs" helper creates multiple HTTP clients from a list of
 URLs. Alternatively you can use the "New" or "NewWithInfo" constructor to
 create clients.
 
+Options such as "WithRequestTimeout", "WithUserAgent" and "WithTransport" can
+be passed to the constructors. "NewTransport" creates a transport with tuned
+connection pooling and HTTP/2 behavior, and "NewHTTP2Transport" one which only
+speaks HTTP/2, in cleartext for http URLs. Relays behind an authentication
+gateway can be reached with "WithHeaders" and "WithTokenProvider".
+
+Relays serving several chains expose each of them under its chain hash. Use
+"NewForChain" or "ForURLsAndChain" to talk to a given chain on such relays,
+and "Chains" to list the chain hashes a relay serves.
+
 Tip: Provide multiple URLs to enable failover and speed optimized URL
 selection.
 */
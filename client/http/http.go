@@ -9,14 +9,12 @@ import (
 	"os"
 	"path"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/drand/drand/chain"
 	"github.com/drand/drand/client"
 	"github.com/drand/drand/log"
-	"github.com/drand/drand/metrics"
-	"github.com/prometheus/client_golang/prometheus"
-	"github.com/prometheus/client_golang/prometheus/promhttp"
 
 	json "github.com/nikkolasg/hexjson"
 )
@@ -25,8 +23,10 @@ var errClientClosed = fmt.Errorf("client closed")
 
 const defaultClientExec = "unknown"
 
-// New creates a new client pointing to an HTTP endpoint
-func New(url string, chainHash []byte, transport nhttp.RoundTripper) (client.Client, error) {
+// New creates a new client pointing to an HTTP endpoint. The transport may be
+// nil to use the default one, or created with NewTransport to tune connection
+// handling.
+func New(url string, chainHash []byte, transport nhttp.RoundTripper, opts ...Option) (client.Client, error) {
 	if transport == nil {
 		transport = nhttp.DefaultTransport
 	}
@@ -45,6 +45,9 @@ func New(url string, chainHash []byte, transport nhttp.RoundTripper) (client.Cli
 		Agent:  agent,
 		done:   make(chan struct{}),
 	}
+	for _, opt := range opts {
+		opt(c)
+	}
 	chainInfo, err := c.FetchChainInfo(chainHash)
 	if err != nil {
 		return nil, err
@@ -55,7 +58,7 @@ func New(url string, chainHash []byte, transport nhttp.RoundTripper) (client.Cli
 }
 
 // NewWithInfo constructs an http client when the group parameters are already known.
-func NewWithInfo(url string, info *chain.Info, transport nhttp.RoundTripper) (client.Client, error) {
+func NewWithInfo(url string, info *chain.Info, transport nhttp.RoundTripper, opts ...Option) (client.Client, error) {
 	if transport == nil {
 		transport = nhttp.DefaultTransport
 	}
@@ -76,17 +79,20 @@ func NewWithInfo(url string, info *chain.Info, transport nhttp.RoundTripper) (cl
 		Agent:     agent,
 		done:      make(chan struct{}),
 	}
+	for _, opt := range opts {
+		opt(c)
+	}
 	return c, nil
 }
 
 // ForURLs provides a shortcut for creating a set of HTTP clients for a set of URLs.
-func ForURLs(urls []string, chainHash []byte) []client.Client {
+func ForURLs(urls []string, chainHash []byte, opts ...Option) []client.Client {
 	clients := make([]client.Client, 0)
 	var info *chain.Info
 	skipped := []string{}
 	for _, u := range urls {
 		if info == nil {
-			if c, err := New(u, chainHash, nil); err == nil {
+			if c, err := New(u, chainHash, nil, opts...); err == nil {
 				// Note: this wrapper assumes the current behavior that if `New` succeeds,
 				// Info will have been fetched.
 				info, _ = c.Info(context.Background())
@@ -95,14 +101,14 @@ func ForURLs(urls []string, chainHash []byte) []client.Client {
 				skipped = append(skipped, u)
 			}
 		} else {
-			if c, err := NewWithInfo(u, info, nil); err == nil {
+			if c, err := NewWithInfo(u, info, nil, opts...); err == nil {
 				clients = append(clients, c)
 			}
 		}
 	}
 	if info != nil {
 		for _, u := range skipped {
-			if c, err := NewWithInfo(u, info, nil); err == nil {
+			if c, err := NewWithInfo(u, info, nil, opts...); err == nil {
 				clients = append(clients, c)
 			}
 		}
@@ -110,40 +116,6 @@ func ForURLs(urls []string, chainHash []byte) []client.Client {
 	return clients
 }
 
-// Instruments an HTTP client around a transport
-func instrumentClient(url string, transport nhttp.RoundTripper) *nhttp.Client {
-	hc := nhttp.Client{}
-	hc.Timeout = nhttp.DefaultClient.Timeout
-	hc.Jar = nhttp.DefaultClient.Jar
-	hc.CheckRedirect = nhttp.DefaultClient.CheckRedirect
-	urlLabel := prometheus.Labels{"url": url}
-
-	trace := &promhttp.InstrumentTrace{
-		DNSStart: func(t float64) {
-			metrics.ClientDNSLatencyVec.MustCurryWith(urlLabel).WithLabelValues("dns_start").Observe(t)
-		},
-		DNSDone: func(t float64) {
-			metrics.ClientDNSLatencyVec.MustCurryWith(urlLabel).WithLabelValues("dns_done").Observe(t)
-		},
-		TLSHandshakeStart: func(t float64) {
-			metrics.ClientTLSLatencyVec.MustCurryWith(urlLabel).WithLabelValues("tls_handshake_start").Observe(t)
-		},
-		TLSHandshakeDone: func(t float64) {
-			metrics.ClientTLSLatencyVec.MustCurryWith(urlLabel).WithLabelValues("tls_handshake_done").Observe(t)
-		},
-	}
-
-	transport = promhttp.InstrumentRoundTripperInFlight(metrics.ClientInFlight.With(urlLabel),
-		promhttp.InstrumentRoundTripperCounter(metrics.ClientRequests.MustCurryWith(urlLabel),
-			promhttp.InstrumentRoundTripperTrace(trace,
-				promhttp.InstrumentRoundTripperDuration(metrics.ClientLatencyVec.MustCurryWith(urlLabel),
-					transport))))
-
-	hc.Transport = transport
-
-	return &hc
-}
-
 // httpClient implements Client through http requests to a Drand relay.
 type httpClient struct {
 	root      string
@@ -152,6 +124,11 @@ type httpClient struct {
 	chainInfo *chain.Info
 	l         log.Logger
 	done      chan struct{}
+	closeOnce sync.Once
+	// headers are added to every request.
+	headers nhttp.Header
+	// token authenticates every request when set.
+	token client.TokenProvider
 }
 
 // SetLog configures the client log output
@@ -192,7 +169,10 @@ func (h *httpClient) FetchChainInfo(chainHash []byte) (*chain.Info, error) {
 			resC <- httpInfoResponse{nil, fmt.Errorf("creating request: %w", err)}
 			return
 		}
-		req.Header.Set("User-Agent", h.Agent)
+		if err := h.prepare(ctx, req); err != nil {
+			resC <- httpInfoResponse{nil, err}
+			return
+		}
 
 		infoBody, err := h.client.Do(req)
 		if err != nil {
@@ -234,6 +214,22 @@ func (h *httpClient) FetchChainInfo(chainHash []byte) (*chain.Info, error) {
 	}
 }
 
+// prepare sets the headers of a request made by the client.
+func (h *httpClient) prepare(ctx context.Context, req *nhttp.Request) error {
+	req.Header.Set("User-Agent", h.Agent)
+	for k, v := range h.headers {
+		req.Header[k] = v
+	}
+	if h.token != nil {
+		token, err := h.token(ctx)
+		if err != nil {
+			return fmt.Errorf("getting token: %w", err)
+		}
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	return nil
+}
+
 // Implement textMarshaller
 func (h *httpClient) MarshalText() ([]byte, error) {
 	return json.Marshal(h)
@@ -263,7 +259,10 @@ func (h *httpClient) Get(ctx context.Context, round uint64) (client.Result, erro
 			resC <- httpGetResponse{nil, fmt.Errorf("creating request: %w", err)}
 			return
 		}
-		req.Header.Set("User-Agent", h.Agent)
+		if err := h.prepare(ctx, req); err != nil {
+			resC <- httpGetResponse{nil, err}
+			return
+		}
 
 		randResponse, err := h.client.Do(req)
 		if err != nil {
@@ -296,6 +295,45 @@ func (h *httpClient) Get(ctx context.Context, round uint64) (client.Result, erro
 	}
 }
 
+type httpRangeResponse struct {
+	Rounds []*client.RandomData `json:"rounds"`
+}
+
+// GetRange returns rounds `from` to `to` included, from the range endpoint of
+// the relay. A single page is fetched, so fewer rounds than requested may be
+// returned.
+func (h *httpClient) GetRange(ctx context.Context, from, to uint64) ([]client.Result, error) {
+	url := fmt.Sprintf("%spublic/rounds?start=%d&end=%d", h.root, from, to)
+	req, err := nhttp.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("creating request: %w", err)
+	}
+	if err := h.prepare(ctx, req); err != nil {
+		return nil, err
+	}
+	resp, err := h.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("doing request: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != nhttp.StatusOK {
+		return nil, fmt.Errorf("range request failed: %s", resp.Status)
+	}
+
+	var page httpRangeResponse
+	if err := json.NewDecoder(resp.Body).Decode(&page); err != nil {
+		return nil, fmt.Errorf("decoding response: %w", err)
+	}
+	results := make([]client.Result, 0, len(page.Rounds))
+	for _, r := range page.Rounds {
+		if r == nil || len(r.Sig) == 0 || len(r.PreviousSignature) == 0 {
+			return nil, fmt.Errorf("insufficient response")
+		}
+		results = append(results, r)
+	}
+	return results, nil
+}
+
 // Watch returns new randomness as it becomes available.
 func (h *httpClient) Watch(ctx context.Context) <-chan client.Result {
 	out := make(chan client.Result)
@@ -325,14 +363,44 @@ func (h *httpClient) Info(ctx context.Context) (*chain.Info, error) {
 	return h.chainInfo, nil
 }
 
+// Handover returns the handover announced in the chain info currently served
+// by the endpoint, or nil when none is announced.
+func (h *httpClient) Handover(ctx context.Context) (*chain.Handover, error) {
+	req, err := nhttp.NewRequestWithContext(ctx, "GET", fmt.Sprintf("%sinfo", h.root), nil)
+	if err != nil {
+		return nil, fmt.Errorf("creating request: %w", err)
+	}
+	if err := h.prepare(ctx, req); err != nil {
+		return nil, err
+	}
+	infoBody, err := h.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("doing request: %w", err)
+	}
+	defer infoBody.Body.Close()
+
+	info, err := chain.InfoFromJSON(infoBody.Body)
+	if err != nil {
+		return nil, fmt.Errorf("decoding response: %w", err)
+	}
+	if h.chainInfo != nil && !bytes.Equal(info.Hash(), h.chainInfo.Hash()) {
+		return nil, fmt.Errorf("%s does not advertise the expected drand group (%x vs %x)", h.root, info.Hash(), h.chainInfo.Hash())
+	}
+	return info.Handover, nil
+}
+
 // RoundAt will return the most recent round of randomness that will be available
 // at time for the current client.
 func (h *httpClient) RoundAt(t time.Time) uint64 {
-	return chain.CurrentRound(t.Unix(), h.chainInfo.Period, h.chainInfo.GenesisTime)
+	return h.chainInfo.RoundAt(t)
 }
 
+// Close stops the watches of the client and closes its idle connections. It
+// can safely be called more than once.
 func (h *httpClient) Close() error {
-	close(h.done)
-	h.client.CloseIdleConnections()
+	h.closeOnce.Do(func() {
+		close(h.done)
+		h.client.CloseIdleConnections()
+	})
 	return nil
 }
@@ -0,0 +1,47 @@
+//go:build !js
+// +build !js
+
+package http
+
+import (
+	nhttp "net/http"
+
+	"github.com/drand/drand/metrics"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// Instruments an HTTP client around a transport
+func instrumentClient(url string, transport nhttp.RoundTripper) *nhttp.Client {
+	hc := nhttp.Client{}
+	hc.Timeout = nhttp.DefaultClient.Timeout
+	hc.Jar = nhttp.DefaultClient.Jar
+	hc.CheckRedirect = nhttp.DefaultClient.CheckRedirect
+	urlLabel := prometheus.Labels{"url": url}
+
+	trace := &promhttp.InstrumentTrace{
+		DNSStart: func(t float64) {
+			metrics.ClientDNSLatencyVec.MustCurryWith(urlLabel).WithLabelValues("dns_start").Observe(t)
+		},
+		DNSDone: func(t float64) {
+			metrics.ClientDNSLatencyVec.MustCurryWith(urlLabel).WithLabelValues("dns_done").Observe(t)
+		},
+		TLSHandshakeStart: func(t float64) {
+			metrics.ClientTLSLatencyVec.MustCurryWith(urlLabel).WithLabelValues("tls_handshake_start").Observe(t)
+		},
+		TLSHandshakeDone: func(t float64) {
+			metrics.ClientTLSLatencyVec.MustCurryWith(urlLabel).WithLabelValues("tls_handshake_done").Observe(t)
+		},
+	}
+
+	transport = promhttp.InstrumentRoundTripperInFlight(metrics.ClientInFlight.With(urlLabel),
+		promhttp.InstrumentRoundTripperCounter(metrics.ClientRequests.MustCurryWith(urlLabel),
+			promhttp.InstrumentRoundTripperTrace(trace,
+				promhttp.InstrumentRoundTripperDuration(metrics.ClientLatencyVec.MustCurryWith(urlLabel),
+					transport))))
+
+	hc.Transport = transport
+
+	return &hc
+}
@@ -0,0 +1,14 @@
+//go:build js
+// +build js
+
+package http
+
+import (
+	nhttp "net/http"
+)
+
+// instrumentClient creates a plain HTTP client around a transport: in a
+// browser, requests go through the fetch API and are not instrumented.
+func instrumentClient(url string, transport nhttp.RoundTripper) *nhttp.Client {
+	return &nhttp.Client{Transport: transport}
+}
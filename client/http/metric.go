@@ -1,10 +1,12 @@
+//go:build !js
+// +build !js
+
 package http
 
 import (
 	"context"
 	"time"
 
-	"github.com/drand/drand/chain"
 	"github.com/drand/drand/client"
 	"github.com/drand/drand/metrics"
 	"github.com/prometheus/client_golang/prometheus"
@@ -56,7 +58,7 @@ func (c *HealthMetrics) startObserve(ctx context.Context) {
 		}
 		// compute the latency metric
 		actual := time.Now().UnixNano()
-		expected := chain.TimeOfRound(httpClient.chainInfo.Period, httpClient.chainInfo.GenesisTime, result.Round()) * 1e9
+		expected := httpClient.chainInfo.TimeOfRound(result.Round()).Unix() * 1e9
 		// the labels of the gauge vec must already be set at the registerer level
 		metrics.ClientHTTPHeartbeatLatency.With(prometheus.Labels{"http_address": httpClient.root}).
 			Set(float64(actual-expected) / float64(time.Millisecond))
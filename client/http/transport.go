@@ -0,0 +1,152 @@
+package http
+
+import (
+	"crypto/tls"
+	"net"
+	nhttp "net/http"
+	"net/url"
+	"time"
+
+	"github.com/drand/drand/client"
+	"golang.org/x/net/http2"
+)
+
+// TransportOptions tunes the connection handling of a transport created with
+// NewTransport. Zero values keep the defaults of `net/http`.
+type TransportOptions struct {
+	// MaxIdleConns bounds the number of idle connections kept across hosts.
+	MaxIdleConns int
+	// MaxIdleConnsPerHost bounds the number of idle connections kept per host.
+	MaxIdleConnsPerHost int
+	// MaxConnsPerHost bounds the number of connections per host, including
+	// the ones in use.
+	MaxConnsPerHost int
+	// IdleConnTimeout is how long an idle connection is kept open.
+	IdleConnTimeout time.Duration
+	// DisableHTTP2 prevents connections from being upgraded to HTTP/2.
+	DisableHTTP2 bool
+	// Proxy is the URL of the proxy requests are sent through, with the
+	// socks5, socks5h, http or https scheme. The proxy environment variables
+	// are ignored when it is set.
+	Proxy *url.URL
+}
+
+// NewTransport creates a transport for HTTP clients based on the default
+// `net/http` transport, tuned with the given options.
+func NewTransport(opts TransportOptions) *nhttp.Transport {
+	t := nhttp.DefaultTransport.(*nhttp.Transport).Clone()
+	if opts.MaxIdleConns > 0 {
+		t.MaxIdleConns = opts.MaxIdleConns
+	}
+	if opts.MaxIdleConnsPerHost > 0 {
+		t.MaxIdleConnsPerHost = opts.MaxIdleConnsPerHost
+	}
+	if opts.MaxConnsPerHost > 0 {
+		t.MaxConnsPerHost = opts.MaxConnsPerHost
+	}
+	if opts.IdleConnTimeout > 0 {
+		t.IdleConnTimeout = opts.IdleConnTimeout
+	}
+	if opts.Proxy != nil {
+		u := *opts.Proxy
+		if u.Scheme == "socks5h" {
+			// net/http always lets SOCKS5 proxies resolve host names.
+			u.Scheme = "socks5"
+		}
+		t.Proxy = nhttp.ProxyURL(&u)
+	}
+	t.ForceAttemptHTTP2 = !opts.DisableHTTP2
+	if opts.DisableHTTP2 {
+		// a non-nil, empty map disables the HTTP/2 upgrade.
+		t.TLSNextProto = make(map[string]func(string, *tls.Conn) nhttp.RoundTripper)
+	}
+	return t
+}
+
+// http2Transport sends every request over HTTP/2, over TLS for https URLs and
+// in cleartext for http ones.
+type http2Transport struct {
+	tls *http2.Transport
+	h2c *http2.Transport
+}
+
+// NewHTTP2Transport creates a transport for HTTP clients which forces HTTP/2,
+// never falling back to HTTP/1.1: requests to https URLs are sent over TLS,
+// and requests to http URLs in cleartext (h2c). HTTP/2 multiplexes the
+// requests to a host over one connection, so the pool sizes of
+// TransportOptions have no equivalent, and proxies are not supported.
+func NewHTTP2Transport() nhttp.RoundTripper {
+	return &http2Transport{
+		tls: &http2.Transport{},
+		h2c: &http2.Transport{
+			AllowHTTP: true,
+			DialTLS: func(network, addr string, _ *tls.Config) (net.Conn, error) {
+				return net.Dial(network, addr)
+			},
+		},
+	}
+}
+
+func (t *http2Transport) RoundTrip(r *nhttp.Request) (*nhttp.Response, error) {
+	if r.URL.Scheme == "http" {
+		return t.h2c.RoundTrip(r)
+	}
+	return t.tls.RoundTrip(r)
+}
+
+// CloseIdleConnections closes the idle connections of the transport.
+func (t *http2Transport) CloseIdleConnections() {
+	t.tls.CloseIdleConnections()
+	t.h2c.CloseIdleConnections()
+}
+
+// Option configures an HTTP client.
+type Option func(h *httpClient)
+
+// WithRequestTimeout bounds the time taken by every request of the client,
+// including reading the response body. No timeout applies by default, beyond
+// the deadline of the context of each call.
+func WithRequestTimeout(timeout time.Duration) Option {
+	return func(h *httpClient) {
+		h.client.Timeout = timeout
+	}
+}
+
+// WithUserAgent sets the user agent sent with every request of the client.
+func WithUserAgent(ua string) Option {
+	return func(h *httpClient) {
+		h.Agent = ua
+	}
+}
+
+// WithHeaders adds static headers, such as API keys, to every request of the
+// client.
+func WithHeaders(headers nhttp.Header) Option {
+	return func(h *httpClient) {
+		if h.headers == nil {
+			h.headers = make(nhttp.Header)
+		}
+		for k, v := range headers {
+			h.headers[k] = append(h.headers[k], v...)
+		}
+	}
+}
+
+// WithTokenProvider authenticates every request of the client with a bearer
+// token returned by `tp`.
+func WithTokenProvider(tp client.TokenProvider) Option {
+	return func(h *httpClient) {
+		h.token = tp
+	}
+}
+
+// WithTransport sets the round tripper used by the client, overriding the one
+// given to the constructor. It allows custom transports to be used with
+// helpers such as ForURLs.
+func WithTransport(transport nhttp.RoundTripper) Option {
+	return func(h *httpClient) {
+		timeout := h.client.Timeout
+		h.client = instrumentClient(h.root, transport)
+		h.client.Timeout = timeout
+	}
+}
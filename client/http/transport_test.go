@@ -0,0 +1,162 @@
+package http
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/drand/drand/chain"
+	"github.com/drand/drand/client"
+	"github.com/drand/drand/test"
+	"golang.org/x/net/http2"
+	"golang.org/x/net/http2/h2c"
+)
+
+func TestNewTransport(t *testing.T) {
+	tr := NewTransport(TransportOptions{
+		MaxIdleConns:        3,
+		MaxIdleConnsPerHost: 2,
+		MaxConnsPerHost:     4,
+		IdleConnTimeout:     time.Second,
+	})
+	if tr.MaxIdleConns != 3 || tr.MaxIdleConnsPerHost != 2 || tr.MaxConnsPerHost != 4 {
+		t.Fatal("pool sizes not applied")
+	}
+	if tr.IdleConnTimeout != time.Second {
+		t.Fatal("idle timeout not applied")
+	}
+	if !tr.ForceAttemptHTTP2 {
+		t.Fatal("HTTP/2 should be attempted by default")
+	}
+
+	tr = NewTransport(TransportOptions{DisableHTTP2: true})
+	if tr.ForceAttemptHTTP2 || tr.TLSNextProto == nil {
+		t.Fatal("HTTP/2 should be disabled")
+	}
+}
+
+func TestHTTP2Transport(t *testing.T) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+
+	// cleartext HTTP/2 for http URLs
+	h2c := httptest.NewServer(h2c.NewHandler(handler, &http2.Server{}))
+	defer h2c.Close()
+	tr := NewHTTP2Transport()
+	hc := &http.Client{Transport: tr}
+	resp, err := hc.Get(h2c.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.ProtoMajor != 2 {
+		t.Fatalf("%s instead of HTTP/2", resp.Proto)
+	}
+
+	// no fallback to HTTP/1.1
+	h1 := httptest.NewServer(handler)
+	defer h1.Close()
+	if resp, err := hc.Get(h1.URL); err == nil {
+		resp.Body.Close()
+		t.Fatal("HTTP/1.1 servers should be refused")
+	}
+
+	// HTTP/2 over TLS for https URLs
+	tlsSrv := httptest.NewUnstartedServer(handler)
+	tlsSrv.EnableHTTP2 = true
+	tlsSrv.StartTLS()
+	defer tlsSrv.Close()
+	pool := x509.NewCertPool()
+	pool.AddCert(tlsSrv.Certificate())
+	tr.(*http2Transport).tls.TLSClientConfig = &tls.Config{RootCAs: pool}
+	resp, err = hc.Get(tlsSrv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.ProtoMajor != 2 {
+		t.Fatalf("%s instead of HTTP/2", resp.Proto)
+	}
+}
+
+type countingTransport struct {
+	calls int32
+}
+
+func (c *countingTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	atomic.AddInt32(&c.calls, 1)
+	return http.DefaultTransport.RoundTrip(r)
+}
+
+func TestHTTPClientOptions(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(200 * time.Millisecond)
+	}))
+	defer srv.Close()
+
+	info := &chain.Info{
+		Period:      time.Second,
+		GenesisTime: time.Now().Unix(),
+		PublicKey:   test.GenerateIDs(1)[0].Public.Key,
+	}
+	rt := &countingTransport{}
+	c, err := NewWithInfo(srv.URL, info, nil,
+		WithRequestTimeout(50*time.Millisecond),
+		WithTransport(rt),
+		WithUserAgent("test-agent"),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+	if c.(*httpClient).Agent != "test-agent" {
+		t.Fatal("user agent not applied")
+	}
+
+	start := time.Now()
+	if _, err := c.Get(context.Background(), 1); err == nil {
+		t.Fatal("expected request to time out")
+	}
+	if time.Since(start) >= 200*time.Millisecond {
+		t.Fatal("request timeout not applied")
+	}
+	if atomic.LoadInt32(&rt.calls) != 1 {
+		t.Fatal("custom transport not used")
+	}
+}
+
+func TestHTTPClientAuth(t *testing.T) {
+	headers := make(chan http.Header, 1)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		headers <- r.Header
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer srv.Close()
+
+	info := &chain.Info{
+		Period:      time.Second,
+		GenesisTime: time.Now().Unix(),
+		PublicKey:   test.GenerateIDs(1)[0].Public.Key,
+	}
+	c, err := NewWithInfo(srv.URL, info, nil,
+		WithHeaders(http.Header{"X-Api-Key": []string{"key"}}),
+		WithTokenProvider(client.StaticToken("secret")),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	_, _ = c.Get(context.Background(), 1)
+	h := <-headers
+	if h.Get("X-Api-Key") != "key" {
+		t.Fatal("static header not sent")
+	}
+	if h.Get("Authorization") != "Bearer secret" {
+		t.Fatal("token not sent, got", h.Get("Authorization"))
+	}
+}
@@ -0,0 +1,77 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/drand/drand/chain"
+)
+
+// defaultInfoTTL is how long the chain info fetched from the sources is
+// reused by default.
+const defaultInfoTTL = time.Hour
+
+// infoCache keeps the chain info fetched from a client for a limited time, so
+// that the wrappers needing it on every request do not hit the sources.
+type infoCache struct {
+	ttl time.Duration
+
+	lk      sync.Mutex
+	info    *chain.Info
+	fetched time.Time
+}
+
+func newInfoCache(ttl time.Duration) *infoCache {
+	return &infoCache{ttl: ttl}
+}
+
+// get returns the cached chain info, fetching it from `c` when it is missing
+// or expired. Errors are not cached.
+func (ic *infoCache) get(ctx context.Context, c Client) (*chain.Info, error) {
+	ic.lk.Lock()
+	if ic.info != nil && time.Since(ic.fetched) < ic.ttl {
+		info := ic.info
+		ic.lk.Unlock()
+		return info, nil
+	}
+	ic.lk.Unlock()
+
+	info, err := c.Info(ctx)
+	if err != nil {
+		return nil, err
+	}
+	ic.lk.Lock()
+	ic.info = info
+	ic.fetched = time.Now()
+	ic.lk.Unlock()
+	return info, nil
+}
+
+// invalidate forces the next call to `get` to fetch the chain info again.
+func (ic *infoCache) invalidate() {
+	ic.lk.Lock()
+	ic.info = nil
+	ic.lk.Unlock()
+}
+
+// newInfoCachingClient wraps a client to serve its chain info from a cache.
+func newInfoCachingClient(c Client, cache *infoCache) *infoCachingClient {
+	return &infoCachingClient{Client: c, cache: cache}
+}
+
+type infoCachingClient struct {
+	Client
+	cache *infoCache
+}
+
+// Info returns the cached parameters of the chain, fetching them when needed.
+func (c *infoCachingClient) Info(ctx context.Context) (*chain.Info, error) {
+	return c.cache.get(ctx, c.Client)
+}
+
+// String returns the name of this client.
+func (c *infoCachingClient) String() string {
+	return fmt.Sprintf("%s.(+infocache)", c.Client)
+}
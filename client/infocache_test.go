@@ -0,0 +1,80 @@
+package client
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/drand/drand/chain"
+	"github.com/drand/drand/client/test/result/mock"
+	"github.com/drand/drand/log"
+)
+
+// countingInfoClient counts the calls made to Info.
+type countingInfoClient struct {
+	Client
+	calls int32
+}
+
+func (c *countingInfoClient) Info(ctx context.Context) (*chain.Info, error) {
+	atomic.AddInt32(&c.calls, 1)
+	return c.Client.Info(ctx)
+}
+
+func TestInfoCacheTTL(t *testing.T) {
+	source := &countingInfoClient{Client: MockClientWithInfo(fakeChainInfo())}
+	c := newInfoCachingClient(source, newInfoCache(50*time.Millisecond))
+	ctx := context.Background()
+
+	for i := 0; i < 3; i++ {
+		if _, err := c.Info(ctx); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if calls := atomic.LoadInt32(&source.calls); calls != 1 {
+		t.Fatal("expected the info to be fetched once, got", calls)
+	}
+
+	c.cache.invalidate()
+	if _, err := c.Info(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if calls := atomic.LoadInt32(&source.calls); calls != 2 {
+		t.Fatal("expected the info to be fetched after invalidation, got", calls)
+	}
+
+	time.Sleep(100 * time.Millisecond)
+	if _, err := c.Info(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if calls := atomic.LoadInt32(&source.calls); calls != 3 {
+		t.Fatal("expected the info to be fetched after expiry, got", calls)
+	}
+}
+
+func TestVerifyingClientRefreshesInfo(t *testing.T) {
+	info, results := mock.VerifiableResults(3, 0)
+	stale, _ := mock.VerifiableResults(1, 0)
+
+	source := &infoMockClient{&MockClient{Results: results, StrictRounds: true}, info}
+	cache := newInfoCache(time.Hour)
+	cache.info = stale
+	cache.fetched = time.Now()
+
+	v := newVerifyingClient(source, nil, false, 0).(*verifyingClient)
+	v.indirectClient = newInfoCachingClient(source, cache)
+	v.infoCache = cache
+	v.SetLog(log.DefaultLogger())
+
+	r, err := v.Get(context.Background(), 2)
+	if err != nil {
+		t.Fatal("round should verify with the refreshed info", err)
+	}
+	if r.Round() != 2 {
+		t.Fatal("unexpected round", r.Round())
+	}
+	if !cache.info.Equal(info) {
+		t.Fatal("cache should hold the refreshed info")
+	}
+}
@@ -0,0 +1,33 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrStaleResult is returned when the latest randomness a client could get is
+// older than the freshness bound required by the caller.
+var ErrStaleResult = errors.New("latest randomness is stale")
+
+// GetLatestVerified returns the latest randomness available from the client,
+// making sure it was produced no longer than maxAge ago according to the chain
+// parameters. The client is expected to verify results, as clients created
+// with `New` or `Wrap` do. A stale result is returned alongside an error
+// wrapping ErrStaleResult, so that callers may still decide to use it.
+func GetLatestVerified(ctx context.Context, c Client, maxAge time.Duration) (Result, error) {
+	info, err := c.Info(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("could not get chain info: %w", err)
+	}
+	r, err := c.Get(ctx, 0)
+	if err != nil {
+		return nil, err
+	}
+	produced := info.TimeOfRound(r.Round())
+	if age := time.Since(produced); age > maxAge {
+		return r, fmt.Errorf("%w: round %d is %s old (max %s)", ErrStaleResult, r.Round(), age.Truncate(time.Second), maxAge)
+	}
+	return r, nil
+}
@@ -0,0 +1,44 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/drand/drand/chain"
+)
+
+// infoMockClient is a mock client which also serves chain info.
+type infoMockClient struct {
+	*MockClient
+	info *chain.Info
+}
+
+func (m *infoMockClient) Info(ctx context.Context) (*chain.Info, error) {
+	return m.info, nil
+}
+
+func TestGetLatestVerified(t *testing.T) {
+	info := fakeChainInfo()
+	info.GenesisTime = time.Now().Unix() - 100
+	current := chain.CurrentRound(time.Now().Unix(), info.Period, info.GenesisTime)
+
+	c := &infoMockClient{MockClientWithResults(current, current+1), info}
+	r, err := GetLatestVerified(context.Background(), c, 5*time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.Round() != current {
+		t.Fatal("unexpected round", r.Round())
+	}
+
+	c = &infoMockClient{MockClientWithResults(current-30, current), info}
+	r, err = GetLatestVerified(context.Background(), c, 5*time.Second)
+	if !errors.Is(err, ErrStaleResult) {
+		t.Fatal("expected stale result error", err)
+	}
+	if r == nil || r.Round() != current-30 {
+		t.Fatal("stale result should still be returned", r)
+	}
+}
@@ -1,3 +1,6 @@
+//go:build !js
+// +build !js
+
 package client
 
 import (
@@ -6,8 +9,34 @@ import (
 
 	"github.com/drand/drand/chain"
 	"github.com/drand/drand/metrics"
+
+	"github.com/prometheus/client_golang/prometheus"
 )
 
+// metricsRegisterer is the registry client metrics are reported to.
+type metricsRegisterer = prometheus.Registerer
+
+// WithPrometheus specifies a registry into which to report metrics
+func WithPrometheus(r prometheus.Registerer) Option {
+	return func(cfg *clientConfig) error {
+		cfg.prometheus = r
+		return nil
+	}
+}
+
+func attachMetrics(cfg *clientConfig, c Client) (Client, error) {
+	if cfg.prometheus != nil {
+		if err := metrics.RegisterClientMetrics(cfg.prometheus); err != nil {
+			return nil, err
+		}
+		if err := cfg.tryPopulateInfo(c); err != nil {
+			return nil, err
+		}
+		return newWatchLatencyMetricClient(c, cfg.chainInfo), nil
+	}
+	return c, nil
+}
+
 func newWatchLatencyMetricClient(base Client, info *chain.Info) Client {
 	ctx, cancel := context.WithCancel(context.Background())
 	c := &watchLatencyMetricClient{
@@ -35,7 +64,7 @@ func (c *watchLatencyMetricClient) startObserve(ctx context.Context) {
 			}
 			// compute the latency metric
 			actual := time.Now().UnixNano()
-			expected := chain.TimeOfRound(c.chainInfo.Period, c.chainInfo.GenesisTime, result.Round()) * 1e9
+			expected := c.chainInfo.TimeOfRound(result.Round()).Unix() * 1e9
 			// the labels of the gauge vec must already be set at the registerer level
 			metrics.ClientWatchLatency.Set(float64(actual-expected) / float64(time.Millisecond))
 		case <-ctx.Done():
@@ -44,6 +73,17 @@ func (c *watchLatencyMetricClient) startObserve(ctx context.Context) {
 	}
 }
 
+// Stats returns the stats of the wrapped client.
+func (c *watchLatencyMetricClient) Stats() Stats {
+	s, _ := StatsOf(c.Client)
+	return s
+}
+
+// DroppedRounds returns the number of rounds dropped by the wrapped client.
+func (c *watchLatencyMetricClient) DroppedRounds() uint64 {
+	return DroppedRounds(c.Client)
+}
+
 func (c *watchLatencyMetricClient) Close() error {
 	err := c.Client.Close()
 	c.cancel()
@@ -0,0 +1,11 @@
+//go:build js
+// +build js
+
+package client
+
+// metricsRegisterer is a placeholder, metrics are not reported in a browser.
+type metricsRegisterer interface{}
+
+func attachMetrics(cfg *clientConfig, c Client) (Client, error) {
+	return c, nil
+}
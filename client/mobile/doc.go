@@ -0,0 +1,18 @@
+/*
+Package mobile provides a drand client with an API that gomobile can bind, so
+that iOS and Android applications can fetch and verify drand randomness
+natively.
+
+The API only uses types supported by gomobile: rounds are int64, randomness
+and signatures are byte slices, relay URLs are given as a single comma
+separated string, and Watch calls back an implementation of WatchCallback
+instead of returning a channel.
+
+Generate the bindings with:
+
+	gomobile bind -target=android github.com/drand/drand/client/mobile
+	gomobile bind -target=ios github.com/drand/drand/client/mobile
+
+Every result is verified against the chain before being returned.
+*/
+package mobile
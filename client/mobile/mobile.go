@@ -0,0 +1,207 @@
+package mobile
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/drand/drand/chain"
+	"github.com/drand/drand/client"
+	"github.com/drand/drand/client/http"
+)
+
+// defaultTimeout bounds the calls made by a Client.
+const defaultTimeout = 10 * time.Second
+
+// Beacon is a verified round of randomness.
+type Beacon struct {
+	Round             int64
+	Randomness        []byte
+	Signature         []byte
+	PreviousSignature []byte
+}
+
+// ChainInfo holds the parameters of a chain.
+type ChainInfo struct {
+	// Hash identifies the chain.
+	Hash []byte
+	// PublicKey is the distributed public key of the chain.
+	PublicKey []byte
+	// Period is the time between rounds, in seconds.
+	Period int64
+	// GenesisTime is the unix time of the first round.
+	GenesisTime int64
+	// GroupHash is the hash of the group producing the chain.
+	GroupHash []byte
+	// Derivation is the ID of the derivation of the randomness from the
+	// signatures of the chain.
+	Derivation string
+}
+
+// WatchCallback receives the rounds of a watch.
+type WatchCallback interface {
+	// OnBeacon is called with every new verified round.
+	OnBeacon(b *Beacon)
+	// OnEnd is called once the watch ended, either because it was canceled
+	// or because the client was closed.
+	OnEnd()
+}
+
+// Subscription is a running watch.
+type Subscription struct {
+	cancel context.CancelFunc
+}
+
+// Cancel stops the watch.
+func (s *Subscription) Cancel() {
+	s.cancel()
+}
+
+// Client fetches verified randomness from drand HTTP relays.
+type Client struct {
+	c client.Client
+
+	lk      sync.Mutex
+	timeout time.Duration
+}
+
+// NewClient creates a client for the chain with the given hex encoded hash,
+// served by the HTTP relays at `urls`, separated by commas.
+func NewClient(urls, chainHash string) (*Client, error) {
+	hash, err := hex.DecodeString(chainHash)
+	if err != nil {
+		return nil, fmt.Errorf("invalid chain hash: %w", err)
+	}
+	var relays []string
+	for _, u := range strings.Split(urls, ",") {
+		if u = strings.TrimSpace(u); u != "" {
+			relays = append(relays, u)
+		}
+	}
+	if len(relays) == 0 {
+		return nil, errors.New("no relay url given")
+	}
+	c, err := client.New(
+		client.From(http.ForURLs(relays, hash)...),
+		client.WithChainHash(hash),
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &Client{c: c, timeout: defaultTimeout}, nil
+}
+
+// SetTimeout sets the maximum duration of the calls to Get and ChainInfo, in
+// milliseconds.
+func (c *Client) SetTimeout(millis int64) {
+	c.lk.Lock()
+	defer c.lk.Unlock()
+	c.timeout = time.Duration(millis) * time.Millisecond
+}
+
+func (c *Client) context() (context.Context, context.CancelFunc) {
+	c.lk.Lock()
+	defer c.lk.Unlock()
+	return context.WithTimeout(context.Background(), c.timeout)
+}
+
+// Get returns the given round, 0 being the latest one.
+func (c *Client) Get(round int64) (*Beacon, error) {
+	if round < 0 {
+		return nil, errors.New("round must not be negative")
+	}
+	ctx, cancel := c.context()
+	defer cancel()
+	info, err := c.c.Info(ctx)
+	if err != nil {
+		return nil, err
+	}
+	r, err := c.c.Get(ctx, uint64(round))
+	if err != nil {
+		return nil, err
+	}
+	return toBeacon(info, r)
+}
+
+// Latest returns the latest round.
+func (c *Client) Latest() (*Beacon, error) {
+	return c.Get(0)
+}
+
+// Watch calls back `cb` with every new round until the subscription is
+// canceled or the client closed.
+func (c *Client) Watch(cb WatchCallback) *Subscription {
+	ctx, cancel := context.WithCancel(context.Background())
+	results := c.c.Watch(ctx)
+	go func() {
+		defer cb.OnEnd()
+		info, err := c.c.Info(ctx)
+		if err != nil {
+			cancel()
+			return
+		}
+		for r := range results {
+			b, err := toBeacon(info, r)
+			if err != nil {
+				cancel()
+				return
+			}
+			cb.OnBeacon(b)
+		}
+	}()
+	return &Subscription{cancel: cancel}
+}
+
+// ChainInfo returns the parameters of the chain.
+func (c *Client) ChainInfo() (*ChainInfo, error) {
+	ctx, cancel := c.context()
+	defer cancel()
+	info, err := c.c.Info(ctx)
+	if err != nil {
+		return nil, err
+	}
+	pub, err := info.PublicKey.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+	return &ChainInfo{
+		Hash:        info.Hash(),
+		PublicKey:   pub,
+		Period:      int64(info.Period / time.Second),
+		GenesisTime: info.GenesisTime,
+		GroupHash:   info.GroupHash,
+		Derivation:  info.Derivation,
+	}, nil
+}
+
+// RoundAt returns the latest round produced at the given unix time.
+func (c *Client) RoundAt(unixTime int64) int64 {
+	return int64(c.c.RoundAt(time.Unix(unixTime, 0)))
+}
+
+// Close stops the watches and releases the resources of the client.
+func (c *Client) Close() error {
+	return c.c.Close()
+}
+
+// toBeacon returns a round of the chain, whose randomness is derived from its
+// signature as specified by the chain info.
+func toBeacon(info *chain.Info, r client.Result) (*Beacon, error) {
+	random, err := info.Randomness(r.Signature())
+	if err != nil {
+		return nil, err
+	}
+	b := &Beacon{
+		Round:      int64(r.Round()),
+		Randomness: random,
+		Signature:  r.Signature(),
+	}
+	if rd, ok := r.(*client.RandomData); ok {
+		b.PreviousSignature = rd.PreviousSignature
+	}
+	return b, nil
+}
@@ -0,0 +1,77 @@
+package mobile
+
+import (
+	"encoding/hex"
+	"testing"
+	"time"
+
+	"github.com/drand/drand/client/test/http/mock"
+)
+
+type testCallback struct {
+	beacons chan *Beacon
+	ended   chan struct{}
+}
+
+func (c *testCallback) OnBeacon(b *Beacon) {
+	c.beacons <- b
+}
+
+func (c *testCallback) OnEnd() {
+	close(c.ended)
+}
+
+func TestMobileClient(t *testing.T) {
+	addr, chainInfo, cancel, emit := mock.NewMockHTTPPublicServer(t, false)
+	defer cancel()
+
+	c, err := NewClient(" http://"+addr+", ", hex.EncodeToString(chainInfo.Hash()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	b, err := c.Latest()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b.Round <= 0 || len(b.Randomness) == 0 {
+		t.Fatal("expected a valid beacon", b)
+	}
+
+	info, err := c.ChainInfo()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if hex.EncodeToString(info.Hash) != hex.EncodeToString(chainInfo.Hash()) {
+		t.Fatal("unexpected chain hash")
+	}
+
+	cb := &testCallback{beacons: make(chan *Beacon, 5), ended: make(chan struct{})}
+	sub := c.Watch(cb)
+	time.Sleep(100 * time.Millisecond)
+	emit(false)
+	select {
+	case b := <-cb.beacons:
+		if len(b.Signature) == 0 {
+			t.Fatal("expected a signed beacon")
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for a beacon")
+	}
+	sub.Cancel()
+	select {
+	case <-cb.ended:
+	case <-time.After(5 * time.Second):
+		t.Fatal("watch did not end")
+	}
+}
+
+func TestMobileClientInvalidArgs(t *testing.T) {
+	if _, err := NewClient("", "00"); err == nil {
+		t.Fatal("expected an error without urls")
+	}
+	if _, err := NewClient("http://localhost", "zz"); err == nil {
+		t.Fatal("expected an error for an invalid hash")
+	}
+}
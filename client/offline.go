@@ -0,0 +1,184 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/drand/drand/chain"
+
+	json "github.com/nikkolasg/hexjson"
+)
+
+// NewOfflineClient creates a client serving randomness from a local beacon
+// store, such as an archive opened with `boltdb.OpenArchive`, without any
+// network access. Only the rounds present in the store are available, and
+// `Watch` yields no new round. Results should still be verified by wrapping
+// the client, e.g. with `Wrap` and the chain info as root of trust.
+func NewOfflineClient(info *chain.Info, store chain.Store) Client {
+	return &offlineClient{info: info, archive: &storeArchive{store: store, info: info}}
+}
+
+// NewOfflineClientFromJSONLines creates a client serving randomness from an
+// archive in the JSON lines format, one round per line as served by the HTTP
+// API, such as the output of the watch command of the client CLI. The archive
+// is read in memory. As with NewOfflineClient, `Watch` yields no new round and
+// results should be verified by wrapping the client.
+func NewOfflineClientFromJSONLines(info *chain.Info, r io.Reader) (Client, error) {
+	archive, err := readJSONLinesArchive(info, r)
+	if err != nil {
+		return nil, err
+	}
+	return &offlineClient{info: info, archive: archive}, nil
+}
+
+// archive holds the rounds served by an offline client.
+type archive interface {
+	// get returns the given round, or the last one when round is 0.
+	get(round uint64) (*RandomData, error)
+	close()
+}
+
+type offlineClient struct {
+	info    *chain.Info
+	archive archive
+}
+
+// String returns the name of this client.
+func (o *offlineClient) String() string {
+	return "Offline"
+}
+
+// Get returns the randomness stored for `round`, or the most recent stored
+// round when `round` is 0.
+func (o *offlineClient) Get(ctx context.Context, round uint64) (Result, error) {
+	r, err := o.archive.get(round)
+	if err != nil {
+		return nil, fmt.Errorf("round %d not in archive: %w", round, err)
+	}
+	return r, nil
+}
+
+// beaconToResult exposes a beacon of the chain as a result carrying everything
+// needed to verify it.
+func beaconToResult(info *chain.Info, b *chain.Beacon) (*RandomData, error) {
+	random, err := info.Randomness(b.Signature)
+	if err != nil {
+		return nil, err
+	}
+	return &RandomData{
+		Rnd:               b.Round,
+		Random:            random,
+		Sig:               b.Signature,
+		PreviousSignature: b.PreviousSig,
+		SigV2:             b.SignatureV2,
+	}, nil
+}
+
+// Watch returns a closed channel: no new randomness is available offline.
+func (o *offlineClient) Watch(ctx context.Context) <-chan Result {
+	ch := make(chan Result)
+	close(ch)
+	return ch
+}
+
+// Info returns the chain information the store belongs to.
+func (o *offlineClient) Info(ctx context.Context) (*chain.Info, error) {
+	return o.info, nil
+}
+
+// RoundAt will return the most recent round of randomness that will be
+// available at time for the chain.
+func (o *offlineClient) RoundAt(t time.Time) uint64 {
+	return o.info.RoundAt(t)
+}
+
+// Close closes the underlying archive.
+func (o *offlineClient) Close() error {
+	o.archive.close()
+	return nil
+}
+
+// storeArchive is an archive backed by a beacon store.
+type storeArchive struct {
+	store chain.Store
+	info  *chain.Info
+}
+
+func (s *storeArchive) get(round uint64) (*RandomData, error) {
+	var b *chain.Beacon
+	var err error
+	if round == 0 {
+		b, err = s.store.Last()
+	} else {
+		b, err = s.store.Get(round)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return beaconToResult(s.info, b)
+}
+
+func (s *storeArchive) close() {
+	s.store.Close()
+}
+
+// jsonLinesArchive is an archive read from JSON lines.
+type jsonLinesArchive struct {
+	rounds map[uint64]*RandomData
+	last   uint64
+}
+
+var errNotArchived = errors.New("not archived")
+
+// readJSONLinesArchive reads rounds of the chain in JSON, one after the other.
+// The randomness of the rounds without it is derived from their signature.
+func readJSONLinesArchive(info *chain.Info, r io.Reader) (*jsonLinesArchive, error) {
+	a := &jsonLinesArchive{rounds: make(map[uint64]*RandomData)}
+	dec := json.NewDecoder(r)
+	for n := 1; ; n++ {
+		rd := new(RandomData)
+		err := dec.Decode(rd)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("archive entry %d: %w", n, err)
+		}
+		if rd.Rnd == 0 {
+			return nil, fmt.Errorf("archive entry %d: no round", n)
+		}
+		if len(rd.Random) == 0 {
+			sig := rd.Sig
+			if len(sig) == 0 {
+				sig = rd.SigV2
+			}
+			if rd.Random, err = info.Randomness(sig); err != nil {
+				return nil, fmt.Errorf("archive entry %d: %w", n, err)
+			}
+		}
+		a.rounds[rd.Rnd] = rd
+		if rd.Rnd > a.last {
+			a.last = rd.Rnd
+		}
+	}
+	if len(a.rounds) == 0 {
+		return nil, errors.New("no round found in archive")
+	}
+	return a, nil
+}
+
+func (a *jsonLinesArchive) get(round uint64) (*RandomData, error) {
+	if round == 0 {
+		round = a.last
+	}
+	rd, ok := a.rounds[round]
+	if !ok {
+		return nil, errNotArchived
+	}
+	return rd, nil
+}
+
+func (a *jsonLinesArchive) close() {}
@@ -0,0 +1,129 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"os"
+	"path"
+	"strings"
+	"testing"
+
+	"github.com/drand/drand/chain"
+	"github.com/drand/drand/chain/boltdb"
+	"github.com/drand/drand/client/test/result/mock"
+
+	json "github.com/nikkolasg/hexjson"
+)
+
+func TestOfflineClient(t *testing.T) {
+	dir, err := ioutil.TempDir("", "drand-offline")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	info, results := mock.VerifiableResults(3, 1000)
+	store, err := boltdb.NewBoltStore(dir, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := range results {
+		r := &results[i]
+		if err := store.Put(&chain.Beacon{
+			Round:       r.Round(),
+			Signature:   r.Sig,
+			PreviousSig: r.PreviousSignature(),
+		}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	store.Close()
+
+	archive, err := boltdb.OpenArchive(path.Join(dir, boltdb.BoltFileName))
+	if err != nil {
+		t.Fatal(err)
+	}
+	c, err := Wrap([]Client{NewOfflineClient(info, archive)},
+		WithChainInfo(info),
+		WithV1VerificationUntil(1000),
+		WithCacheSize(0),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	r, err := c.Get(context.Background(), results[1].Round())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(r.Randomness(), results[1].Randomness()) {
+		t.Fatal("unexpected randomness")
+	}
+	latest, err := c.Get(context.Background(), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if latest.Round() != results[2].Round() {
+		t.Fatal("expected the last archived round", latest.Round())
+	}
+	if _, err := c.Get(context.Background(), results[2].Round()+1); err == nil {
+		t.Fatal("rounds missing from the archive should fail")
+	}
+}
+
+func TestOfflineClientJSONLines(t *testing.T) {
+	info, results := mock.VerifiableResults(3, 1000)
+	var buff bytes.Buffer
+	enc := json.NewEncoder(&buff)
+	for i := range results {
+		r := &results[i]
+		// the randomness is derived from the signature when missing
+		if err := enc.Encode(&RandomData{
+			Rnd:               r.Round(),
+			Sig:               r.Sig,
+			PreviousSignature: r.PreviousSignature(),
+		}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	offline, err := NewOfflineClientFromJSONLines(info, &buff)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c, err := Wrap([]Client{offline},
+		WithChainInfo(info),
+		WithV1VerificationUntil(1000),
+		WithCacheSize(0),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	r, err := c.Get(context.Background(), results[1].Round())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(r.Randomness(), results[1].Randomness()) {
+		t.Fatal("unexpected randomness")
+	}
+	latest, err := c.Get(context.Background(), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if latest.Round() != results[2].Round() {
+		t.Fatal("expected the last archived round", latest.Round())
+	}
+	if _, err := c.Get(context.Background(), results[2].Round()+1); err == nil {
+		t.Fatal("rounds missing from the archive should fail")
+	}
+
+	for _, archive := range []string{"", "{\"round\":1}\n{\"round\":", "{\"signature\":\"01\"}\n"} {
+		if _, err := NewOfflineClientFromJSONLines(info, strings.NewReader(archive)); err == nil {
+			t.Fatalf("archive %q should be refused", archive)
+		}
+	}
+}
@@ -31,6 +31,10 @@ const (
 	maxUnixTime = 1<<63 - 62135596801
 )
 
+// errLastClient is returned when removing all the active clients of an
+// optimizing client.
+var errLastClient = errors.New("can not remove all active clients")
+
 // newOptimizingClient creates a drand client that measures the speed of clients
 // and uses the fastest ones.
 //
@@ -124,12 +128,22 @@ type optimizingClient struct {
 	requestConcurrency int
 	speedTestInterval  time.Duration
 	watchRetryInterval time.Duration
-	log                log.Logger
-	done               chan struct{}
+	// hedgeDelay, when positive, makes `Get` query clients one at a time,
+	// sending a hedged request to the next client whenever the pending ones
+	// take longer than this delay.
+	hedgeDelay time.Duration
+	log        log.Logger
+	// events, when set, is told when the fastest client changes.
+	events    *eventBus
+	done      chan struct{}
+	closeOnce sync.Once
+	closeErr  error
 }
 
 // String returns the name of this client.
 func (oc *optimizingClient) String() string {
+	oc.RLock()
+	defer oc.RUnlock()
 	names := make([]string, len(oc.clients))
 	for i, c := range oc.clients {
 		names[i] = fmt.Sprint(c)
@@ -167,15 +181,86 @@ func (oc *optimizingClient) markedPassive(c Client) bool {
 	return false
 }
 
-func (oc *optimizingClient) testSpeed() {
+// activeClients returns the clients not marked as passive.
+func (oc *optimizingClient) activeClients() []Client {
+	oc.RLock()
+	defer oc.RUnlock()
 	clients := make([]Client, 0, len(oc.clients))
 	for _, c := range oc.clients {
 		if !oc.markedPassive(c) {
 			clients = append(clients, c)
 		}
 	}
+	return clients
+}
+
+// addClients adds clients to the ones used by the optimizing client. Like the
+// initial clients, they are tried first until their speed is measured.
+func (oc *optimizingClient) addClients(clients ...Client) {
+	oc.Lock()
+	defer oc.Unlock()
+	now := time.Now()
+	for _, c := range clients {
+		oc.clients = append(oc.clients, c)
+		oc.stats = append(oc.stats, &requestStat{client: c, rtt: 0, startTime: now})
+	}
+	sort.SliceStable(oc.stats, func(i, j int) bool {
+		return oc.stats[i].rtt < oc.stats[j].rtt
+	})
+}
+
+// removeClients stops using the given clients, and closes them. Passive
+// clients can not be removed, nor the last active client.
+func (oc *optimizingClient) removeClients(clients ...Client) error {
+	oc.Lock()
+	remove := func(c Client) bool {
+		for _, r := range clients {
+			if r == c && !oc.markedPassive(c) {
+				return true
+			}
+		}
+		return false
+	}
+	active := 0
+	for _, c := range oc.clients {
+		if !remove(c) && !oc.markedPassive(c) {
+			active++
+		}
+	}
+	if active == 0 {
+		oc.Unlock()
+		return errLastClient
+	}
+
+	kept := make([]Client, 0, len(oc.clients))
+	var removed []Client
+	for _, c := range oc.clients {
+		if remove(c) {
+			removed = append(removed, c)
+		} else {
+			kept = append(kept, c)
+		}
+	}
+	stats := make([]*requestStat, 0, len(kept))
+	for _, s := range oc.stats {
+		if !remove(s.client) {
+			stats = append(stats, s)
+		}
+	}
+	oc.clients = kept
+	oc.stats = stats
+	oc.Unlock()
+
+	var errs *multierror.Error
+	for _, c := range removed {
+		errs = multierror.Append(errs, c.Close())
+	}
+	return errs.ErrorOrNil()
+}
 
+func (oc *optimizingClient) testSpeed() {
 	for {
+		clients := oc.activeClients()
 		stats := []*requestStat{}
 		ctx, cancel := context.WithCancel(context.Background())
 		ch := parallelGet(ctx, clients, 1, oc.requestTimeout, oc.requestConcurrency)
@@ -231,7 +316,14 @@ func (oc *optimizingClient) fastestClients() []Client {
 func (oc *optimizingClient) Get(ctx context.Context, round uint64) (res Result, err error) {
 	clients := oc.fastestClients()
 	stats := []*requestStat{}
-	ch := raceGet(ctx, clients, round, oc.requestTimeout, oc.requestConcurrency)
+	var ch <-chan *requestResult
+	if oc.hedgeDelay > 0 {
+		ch = raceGetWith(ctx, len(clients), func(ctx context.Context) <-chan *requestResult {
+			return hedgedGet(ctx, clients, round, oc.requestTimeout, oc.hedgeDelay)
+		})
+	} else {
+		ch = raceGet(ctx, clients, round, oc.requestTimeout, oc.requestConcurrency)
+	}
 	err = errors.New("no valid clients")
 
 LOOP:
@@ -283,13 +375,21 @@ func get(ctx context.Context, client Client, round uint64) *requestResult {
 }
 
 func raceGet(ctx context.Context, clients []Client, round uint64, timeout time.Duration, concurrency int) <-chan *requestResult {
-	results := make(chan *requestResult, len(clients))
+	return raceGetWith(ctx, len(clients), func(ctx context.Context) <-chan *requestResult {
+		return parallelGet(ctx, clients, round, timeout, concurrency)
+	})
+}
+
+// raceGetWith forwards the results of the requests to `n` clients started by
+// `start` until one of them succeeds, at which point the others are canceled.
+func raceGetWith(ctx context.Context, n int, start func(context.Context) <-chan *requestResult) <-chan *requestResult {
+	results := make(chan *requestResult, n)
 
 	go func() {
 		rctx, cancel := context.WithCancel(ctx)
 		defer cancel()
 		defer close(results)
-		ch := parallelGet(rctx, clients, round, timeout, concurrency)
+		ch := start(rctx)
 
 		for {
 			select {
@@ -310,6 +410,50 @@ func raceGet(ctx context.Context, clients []Client, round uint64, timeout time.D
 	return results
 }
 
+// hedgedGet calls Get on the clients one after the other, starting the request
+// to the next client as soon as a pending one fails, or after `delay` passed
+// without any answer.
+func hedgedGet(ctx context.Context, clients []Client, round uint64, timeout, delay time.Duration) <-chan *requestResult {
+	results := make(chan *requestResult, len(clients))
+	failed := make(chan struct{}, len(clients))
+
+	go func() {
+		wg := sync.WaitGroup{}
+	LOOP:
+		for i, c := range clients {
+			wg.Add(1)
+			go func(c Client) {
+				defer wg.Done()
+				gctx, cancel := context.WithTimeout(ctx, timeout)
+				rr := get(gctx, c, round)
+				cancel()
+				if rr != nil {
+					results <- rr
+					if rr.err != nil {
+						failed <- struct{}{}
+					}
+				}
+			}(c)
+			if i == len(clients)-1 {
+				break
+			}
+			t := time.NewTimer(delay)
+			select {
+			case <-t.C:
+			case <-failed:
+				t.Stop()
+			case <-ctx.Done():
+				t.Stop()
+				break LOOP
+			}
+		}
+		wg.Wait()
+		close(results)
+	}()
+
+	return results
+}
+
 func parallelGet(ctx context.Context, clients []Client, round uint64, timeout time.Duration, concurrency int) <-chan *requestResult {
 	results := make(chan *requestResult, len(clients))
 	token := make(chan struct{}, concurrency)
@@ -348,7 +492,10 @@ func parallelGet(ctx context.Context, clients []Client, round uint64, timeout ti
 
 func (oc *optimizingClient) updateStats(stats []*requestStat) {
 	oc.Lock()
-	defer oc.Unlock()
+	var first Client
+	if len(oc.stats) > 0 {
+		first = oc.stats[0].client
+	}
 
 	// update the round trip times with new samples
 	for _, next := range stats {
@@ -367,6 +514,12 @@ func (oc *optimizingClient) updateStats(stats []*requestStat) {
 	sort.Slice(oc.stats, func(i, j int) bool {
 		return oc.stats[i].rtt < oc.stats[j].rtt
 	})
+	demoted := first != nil && len(oc.stats) > 0 && oc.stats[0].client != first
+	oc.Unlock()
+
+	if demoted {
+		oc.events.emit(Event{Type: EventBackendDemoted, Source: fmt.Sprint(first)})
+	}
 }
 
 type watchResult struct {
@@ -380,7 +533,7 @@ func (oc *optimizingClient) trackWatchResults(info *chain.Info, in chan watchRes
 	latest := uint64(0)
 	for r := range in {
 		round := r.Result.Round()
-		timeOfRound := time.Unix(chain.TimeOfRound(info.Period, info.GenesisTime, round), 0)
+		timeOfRound := info.TimeOfRound(round)
 		stat := requestStat{
 			client:    r.Client,
 			rtt:       time.Since(timeOfRound),
@@ -618,16 +771,26 @@ func (oc *optimizingClient) Info(ctx context.Context) (chainInfo *chain.Info, er
 // RoundAt will return the most recent round of randomness that will be available
 // at time for the current client.
 func (oc *optimizingClient) RoundAt(t time.Time) uint64 {
-	return oc.clients[0].RoundAt(t)
+	oc.RLock()
+	c := oc.clients[0]
+	oc.RUnlock()
+	return c.RoundAt(t)
 }
 
 // Close stops the background speed tests and closes the client and it's
-// underlying clients for further use.
+// underlying clients for further use. Closing the client again returns the
+// result of the first call.
 func (oc *optimizingClient) Close() error {
-	var errs *multierror.Error
-	for _, c := range oc.clients {
-		errs = multierror.Append(errs, c.Close())
-	}
-	close(oc.done)
-	return errs.ErrorOrNil()
+	oc.closeOnce.Do(func() {
+		oc.RLock()
+		clients := oc.clients
+		oc.RUnlock()
+		var errs *multierror.Error
+		for _, c := range clients {
+			errs = multierror.Append(errs, c.Close())
+		}
+		close(oc.done)
+		oc.closeErr = errs.ErrorOrNil()
+	})
+	return oc.closeErr
 }
@@ -86,6 +86,24 @@ func TestOptimizingGet(t *testing.T) {
 	expectRound(t, latestResult(t, oc), 4) // round 4 from c0
 }
 
+func TestOptimizingCloseTwice(t *testing.T) {
+	closes := 0
+	c := &MockClient{CloseF: func() error {
+		closes++
+		return nil
+	}}
+	oc, err := newOptimizingClient([]Client{c}, time.Second, 1, time.Minute, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	oc.Start()
+	closeClient(t, oc)
+	closeClient(t, oc)
+	if closes != 1 {
+		t.Fatal("expected the source to be closed once, got", closes)
+	}
+}
+
 func TestOptimizingWatch(t *testing.T) {
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
@@ -301,3 +319,45 @@ func TestOptimizingClose(t *testing.T) {
 
 	wg.Wait() // wait for underlying clients to close
 }
+
+func TestOptimizingHedgedGet(t *testing.T) {
+	c0 := MockClientWithResults(0, 5)
+	c1 := MockClientWithResults(5, 8)
+
+	c0.Delay = time.Second * 2
+	c1.Delay = time.Millisecond
+
+	oc, err := newOptimizingClient([]Client{c0, c1}, time.Second*5, 2, time.Minute*5, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	oc.hedgeDelay = time.Millisecond * 50
+	defer closeClient(t, oc)
+
+	// c0 is slow, so the hedged request to c1 answers first.
+	start := time.Now()
+	expectRound(t, latestResult(t, oc), 5)
+	if time.Since(start) >= c0.Delay {
+		t.Fatal("hedged request did not cut the latency of the slow client")
+	}
+
+	// c2 answers before the hedge delay, so c3 is left alone.
+	c2 := MockClientWithResults(0, 5)
+	c3 := MockClientWithResults(5, 8)
+	c2.Delay = time.Millisecond
+
+	oc, err = newOptimizingClient([]Client{c2, c3}, time.Second*5, 2, time.Minute*5, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	oc.hedgeDelay = time.Second
+	defer closeClient(t, oc)
+
+	expectRound(t, latestResult(t, oc), 0)
+	c3.Lock()
+	left := len(c3.Results)
+	c3.Unlock()
+	if left != 3 {
+		t.Fatal("expected no hedged request, results left:", left)
+	}
+}
@@ -0,0 +1,114 @@
+package client
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"sync"
+
+	"github.com/drand/drand/chain"
+	"github.com/drand/drand/fs"
+)
+
+// PinStore persists the chain information a client pinned the first time it
+// connected to a chain.
+type PinStore interface {
+	// Load returns the pinned chain information, or nil if nothing is pinned yet.
+	Load() (*chain.Info, error)
+	// Store pins the given chain information.
+	Store(*chain.Info) error
+}
+
+// PinMismatchError is returned when a source serves chain information that
+// differs from the information pinned on first use.
+type PinMismatchError struct {
+	Pinned *chain.Info
+	Served *chain.Info
+}
+
+func (e *PinMismatchError) Error() string {
+	if e.Served == nil {
+		return fmt.Sprintf("chain info does not match pinned chain %x", e.Pinned.Hash())
+	}
+	return fmt.Sprintf("chain info %x does not match pinned chain %x", e.Served.Hash(), e.Pinned.Hash())
+}
+
+// Unwrap allows a pin mismatch to be matched as an `ErrUntrustedInfo`.
+func (e *PinMismatchError) Unwrap() error {
+	return ErrUntrustedInfo
+}
+
+// NewFilePinStore returns a PinStore keeping the pinned chain information as
+// JSON in the file at path.
+func NewFilePinStore(path string) PinStore {
+	return &filePinStore{path: path}
+}
+
+type filePinStore struct {
+	sync.Mutex
+	path string
+}
+
+// Load reads the pinned chain information from disk.
+func (f *filePinStore) Load() (*chain.Info, error) {
+	f.Lock()
+	defer f.Unlock()
+	fd, err := os.Open(f.path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer fd.Close()
+	return chain.InfoFromJSON(fd)
+}
+
+// Store writes the chain information to disk, readable by the user only.
+func (f *filePinStore) Store(info *chain.Info) error {
+	f.Lock()
+	defer f.Unlock()
+	fd, err := fs.CreateSecureFile(f.path)
+	if err != nil {
+		return err
+	}
+	if fd == nil {
+		return fmt.Errorf("could not secure pin file %s", f.path)
+	}
+	defer fd.Close()
+	return info.ToJSON(fd)
+}
+
+// pinChainInfo loads the pinned chain information, or pins the chain
+// information served by the configured clients when none is pinned yet.
+func (c *clientConfig) pinChainInfo() error {
+	pinned, err := c.pinStore.Load()
+	if err != nil {
+		return fmt.Errorf("loading pinned chain info: %w", err)
+	}
+	if pinned == nil {
+		if err := c.tryPopulateInfo(c.clients...); err != nil {
+			return err
+		}
+		if c.chainInfo == nil {
+			return fmt.Errorf("no chain info to pin")
+		}
+		if c.chainHash != nil && !bytes.Equal(c.chainHash, c.chainInfo.Hash()) {
+			return fmt.Errorf("%w: %x vs %x", ErrUntrustedInfo, c.chainInfo.Hash(), c.chainHash)
+		}
+		if err := c.pinStore.Store(c.chainInfo); err != nil {
+			return fmt.Errorf("pinning chain info: %w", err)
+		}
+		c.pinned = true
+		return nil
+	}
+	if c.chainInfo != nil && !c.chainInfo.Equal(pinned) {
+		return &PinMismatchError{Pinned: pinned, Served: c.chainInfo}
+	}
+	if c.chainHash != nil && !bytes.Equal(c.chainHash, pinned.Hash()) {
+		return &PinMismatchError{Pinned: pinned}
+	}
+	c.chainInfo = pinned
+	c.pinned = true
+	return nil
+}
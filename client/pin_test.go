@@ -0,0 +1,84 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path"
+	"testing"
+)
+
+func TestFilePinStore(t *testing.T) {
+	dir, err := ioutil.TempDir("", "drand-pin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	store := NewFilePinStore(path.Join(dir, "pin.json"))
+	pinned, err := store.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pinned != nil {
+		t.Fatal("expected nothing pinned", pinned)
+	}
+
+	info := fakeChainInfo()
+	if err := store.Store(info); err != nil {
+		t.Fatal(err)
+	}
+	pinned, err = store.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !pinned.Equal(info) {
+		t.Fatal("unexpected pinned info", pinned)
+	}
+}
+
+func TestClientTrustOnFirstUse(t *testing.T) {
+	dir, err := ioutil.TempDir("", "drand-pin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	store := NewFilePinStore(path.Join(dir, "pin.json"))
+
+	info := fakeChainInfo()
+	c, err := New(From(MockClientWithInfo(info)), WithTrustOnFirstUse(store))
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = c.Close()
+
+	pinned, err := store.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !pinned.Equal(info) {
+		t.Fatal("first use should pin the served chain info")
+	}
+
+	// a later client served different parameters must fail hard.
+	other := MockClientWithInfo(fakeChainInfo())
+	c, err = New(From(other), WithTrustOnFirstUse(store))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+	_, err = c.Info(context.Background())
+	var mismatch *PinMismatchError
+	if !errors.As(err, &mismatch) {
+		t.Fatal("expected pin mismatch error", err)
+	}
+	if !errors.Is(err, ErrUntrustedInfo) {
+		t.Fatal("pin mismatch should be an untrusted info error", err)
+	}
+
+	_, err = New(From(other), WithTrustOnFirstUse(store), WithChainInfo(fakeChainInfo()))
+	if !errors.As(err, &mismatch) {
+		t.Fatal("expected pin mismatch error", err)
+	}
+}
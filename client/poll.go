@@ -24,37 +24,23 @@ func PollingWatcher(ctx context.Context, c Client, chainInfo *chain.Info, l log.
 	go func() {
 		defer close(ch)
 
-		// Initially, wait to synchronize to the round boundary.
-		_, nextTime := chain.NextRound(time.Now().Unix(), chainInfo.Period, chainInfo.GenesisTime)
-		select {
-		case <-ctx.Done():
-			return
-		case <-time.After(time.Duration(nextTime-time.Now().Unix()) * time.Second):
-		}
-
-		r, err := c.Get(ctx, c.RoundAt(time.Now()))
-		if err == nil {
-			ch <- r
-		} else {
-			l.Error("polling_client", "failed to watch", "err", err)
-		}
-
-		// Then tick each period.
-		t := time.NewTicker(chainInfo.Period)
-		defer t.Stop()
+		// Wait for the time of each round, which follows the period changes
+		// of the chain.
 		for {
+			_, nextTime := chainInfo.NextRound(time.Now())
 			select {
-			case <-t.C:
-				r, err := c.Get(ctx, c.RoundAt(time.Now()))
-				if err == nil {
-					ch <- r
-				} else {
-					l.Error("polling_client", "failed to watch", "err", err)
-				}
-				// TODO: keep trying on errors?
 			case <-ctx.Done():
 				return
+			case <-time.After(time.Until(nextTime)):
+			}
+
+			r, err := c.Get(ctx, c.RoundAt(time.Now()))
+			if err == nil {
+				ch <- r
+			} else {
+				l.Error("polling_client", "failed to watch", "err", err)
 			}
+			// TODO: keep trying on errors?
 		}
 	}()
 
@@ -0,0 +1,136 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+const (
+	// defaultPrefetchPage is the number of historical rounds fetched at once
+	// ahead of a verification chain walk.
+	defaultPrefetchPage = 100
+	// prefetchConcurrency is the number of concurrent requests used to fetch
+	// a page of rounds from sources that can not serve ranges.
+	prefetchConcurrency = 8
+)
+
+// RangeClient is implemented by sources able to serve consecutive rounds in
+// a single request.
+type RangeClient interface {
+	// GetRange returns rounds `from` to `to` included, in order. Fewer rounds
+	// than requested may be returned, as long as they start at `from`.
+	GetRange(ctx context.Context, from, to uint64) ([]Result, error)
+}
+
+// prefetcher downloads the historical rounds needed by a verification chain
+// walk ahead of the verifier, a page at a time.
+type prefetcher struct {
+	// sources are tried first to fetch a page in a single request.
+	sources []RangeClient
+	page    int
+}
+
+type prefetched struct {
+	result Result
+	err    error
+}
+
+// prefetch delivers rounds `from` to `to` in order, fetching them from the
+// range sources, or from `c` with concurrent requests. It stops at the first
+// error, which is delivered last.
+func (p *prefetcher) prefetch(ctx context.Context, c Client, from, to uint64) <-chan prefetched {
+	out := make(chan prefetched, p.page)
+	go func() {
+		defer close(out)
+		for next := from; next <= to; {
+			end := next + uint64(p.page) - 1
+			if end > to {
+				end = to
+			}
+			results, err := p.fetchPage(ctx, c, next, end)
+			if err != nil {
+				select {
+				case out <- prefetched{err: err}:
+				case <-ctx.Done():
+				}
+				return
+			}
+			for _, r := range results {
+				select {
+				case out <- prefetched{result: r}:
+				case <-ctx.Done():
+					return
+				}
+			}
+			next += uint64(len(results))
+		}
+	}()
+	return out
+}
+
+// fetchPage returns consecutive rounds starting at `from`, and at most up to
+// `to`.
+func (p *prefetcher) fetchPage(ctx context.Context, c Client, from, to uint64) ([]Result, error) {
+	for _, s := range p.sources {
+		results, err := s.GetRange(ctx, from, to)
+		if err != nil {
+			continue
+		}
+		if results = consecutive(results, from, to); len(results) > 0 {
+			return results, nil
+		}
+	}
+	return getRounds(ctx, c, from, to, prefetchConcurrency)
+}
+
+// consecutive returns the longest prefix of results holding consecutive
+// rounds starting at `from`, up to `to`.
+func consecutive(results []Result, from, to uint64) []Result {
+	for i, r := range results {
+		if r == nil || r.Round() != from+uint64(i) || r.Round() > to {
+			return results[:i]
+		}
+	}
+	return results
+}
+
+// getRounds gets rounds `from` to `to` from `c` with up to `concurrency`
+// concurrent requests. Rounds fetched before the first failing one are
+// returned without error.
+func getRounds(ctx context.Context, c Client, from, to uint64, concurrency int) ([]Result, error) {
+	if to < from {
+		return nil, errors.New("empty range")
+	}
+	results := make([]Result, to-from+1)
+	errs := make([]error, len(results))
+	tokens := make(chan struct{}, concurrency)
+	wg := sync.WaitGroup{}
+	for i := range results {
+		tokens <- struct{}{}
+		wg.Add(1)
+		go func(i int) {
+			defer func() {
+				<-tokens
+				wg.Done()
+			}()
+			round := from + uint64(i)
+			results[i], errs[i] = c.Get(ctx, round)
+			if errs[i] == nil && results[i].Round() != round {
+				errs[i] = fmt.Errorf("got round %d instead", results[i].Round())
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	for i, err := range errs {
+		if err != nil {
+			if i == 0 {
+				return nil, fmt.Errorf("could not get round %d: %w", from, err)
+			}
+			return results[:i], nil
+		}
+	}
+	return results, nil
+}
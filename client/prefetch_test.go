@@ -0,0 +1,90 @@
+package client
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+
+	"github.com/drand/drand/client/test/result/mock"
+)
+
+// rangeMockClient serves ranges of the results of a mock client.
+type rangeMockClient struct {
+	*MockClient
+	rangeCalls int32
+}
+
+func (m *rangeMockClient) GetRange(ctx context.Context, from, to uint64) ([]Result, error) {
+	atomic.AddInt32(&m.rangeCalls, 1)
+	var out []Result
+	for i := range m.Results {
+		r := &m.Results[i]
+		if r.Round() >= from && r.Round() <= to {
+			out = append(out, r)
+		}
+	}
+	return out, nil
+}
+
+func TestPrefetchInOrder(t *testing.T) {
+	c := MockClientWithResults(1, 20)
+	c.StrictRounds = true
+	p := &prefetcher{page: 4}
+
+	expected := uint64(3)
+	for r := range p.prefetch(context.Background(), c, 3, 17) {
+		if r.err != nil {
+			t.Fatal(r.err)
+		}
+		if r.result.Round() != expected {
+			t.Fatalf("expected round %d, got %d", expected, r.result.Round())
+		}
+		expected++
+	}
+	if expected != 18 {
+		t.Fatal("prefetch ended early at round", expected)
+	}
+}
+
+func TestPrefetchError(t *testing.T) {
+	c := MockClientWithResults(1, 5)
+	c.StrictRounds = true
+	p := &prefetcher{page: 10}
+
+	var last prefetched
+	for r := range p.prefetch(context.Background(), c, 1, 8) {
+		last = r
+	}
+	if last.err == nil {
+		t.Fatal("expected the missing rounds to fail")
+	}
+}
+
+func TestVerifyWithRangePrefetch(t *testing.T) {
+	info, results := mock.VerifiableResults(12, 1000000000)
+	rc := &rangeMockClient{MockClient: &MockClient{Results: results, StrictRounds: true}}
+	c, err := Wrap(
+		[]Client{MockClientWithInfo(info), rc},
+		WithChainInfo(info),
+		WithVerifiedResult(&results[0]),
+		WithFullChainVerification(),
+		WithV1VerificationUntil(1000000000),
+		WithPrefetch(4),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	r, err := c.Get(context.Background(), results[11].Round())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.Round() != results[11].Round() {
+		t.Fatal("unexpected round", r.Round())
+	}
+	// rounds 2 to 11 are walked, in pages of 4 rounds.
+	if calls := atomic.LoadInt32(&rc.rangeCalls); calls != 3 {
+		t.Fatal("expected 3 range requests, got", calls)
+	}
+}
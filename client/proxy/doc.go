@@ -0,0 +1,19 @@
+/*
+Package proxy provides the dialers used by drand clients to reach their
+sources through a proxy, so that relays do not learn the IP address of the
+consumers of randomness.
+
+Proxies are given as URLs:
+
+	socks5://[user:password@]host:port   SOCKS5 proxy, such as Tor
+	socks5h://[user:password@]host:port  same as socks5
+	http://[user:password@]host:port     HTTP proxy supporting CONNECT
+
+Host names are always resolved by the proxy, which allows reaching .onion
+endpoints through Tor.
+
+The HTTP client accepts a proxy in its "TransportOptions", and the gRPC client
+with its "WithProxy" option. Connections of libp2p hosts are not proxied:
+gossip relays connect to their peers directly.
+*/
+package proxy
@@ -0,0 +1,131 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/drand/drand/chain"
+	"github.com/drand/drand/log"
+)
+
+// newRateLimitedClient wraps a client so that the requests sent to it, calls
+// to Get, Info and Watch, take a token from the bucket first.
+func newRateLimitedClient(c Client, bucket *tokenBucket) Client {
+	return &rateLimitedClient{
+		Client: c,
+		bucket: bucket,
+	}
+}
+
+type rateLimitedClient struct {
+	Client
+
+	bucket *tokenBucket
+}
+
+// Get waits for the rate limit to allow a request before returning the
+// randomness at `round`.
+func (r *rateLimitedClient) Get(ctx context.Context, round uint64) (Result, error) {
+	if err := r.bucket.wait(ctx); err != nil {
+		return nil, err
+	}
+	return r.Client.Get(ctx, round)
+}
+
+// Info waits for the rate limit to allow a request before returning the chain
+// information.
+func (r *rateLimitedClient) Info(ctx context.Context) (*chain.Info, error) {
+	if err := r.bucket.wait(ctx); err != nil {
+		return nil, err
+	}
+	return r.Client.Info(ctx)
+}
+
+// Watch waits for the rate limit to allow a request before subscribing, so
+// that watches reconnecting in a loop are limited as well. The channel is
+// closed without results when the context is done first.
+func (r *rateLimitedClient) Watch(ctx context.Context) <-chan Result {
+	if err := r.bucket.wait(ctx); err != nil {
+		ch := make(chan Result)
+		close(ch)
+		return ch
+	}
+	return r.Client.Watch(ctx)
+}
+
+// SetLog configures the log output of the wrapped client.
+func (r *rateLimitedClient) SetLog(l log.Logger) {
+	trySetLog(r.Client, l)
+}
+
+// String returns the name of this client.
+func (r *rateLimitedClient) String() string {
+	return fmt.Sprintf("%s.(+%g req/s)", r.Client, r.bucket.rate)
+}
+
+// rateLimitedRangeClient limits the ranges requested to a source with the
+// bucket limiting its other requests.
+type rateLimitedRangeClient struct {
+	RangeClient
+
+	bucket *tokenBucket
+}
+
+// GetRange waits for the rate limit to allow a request before returning the
+// rounds `from` to `to`.
+func (r *rateLimitedRangeClient) GetRange(ctx context.Context, from, to uint64) ([]Result, error) {
+	if err := r.bucket.wait(ctx); err != nil {
+		return nil, err
+	}
+	return r.RangeClient.GetRange(ctx, from, to)
+}
+
+// newTokenBucket returns a full bucket of `burst` tokens, refilled with
+// `rate` tokens per second.
+func newTokenBucket(rate float64, burst int) *tokenBucket {
+	return &tokenBucket{
+		rate:   rate,
+		burst:  float64(burst),
+		tokens: float64(burst),
+		last:   time.Now(),
+	}
+}
+
+// tokenBucket is a token bucket rate limiter.
+type tokenBucket struct {
+	sync.Mutex
+	// rate is the number of tokens added per second.
+	rate float64
+	// burst is the capacity of the bucket.
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+// wait blocks until a token is available or the context is done.
+func (b *tokenBucket) wait(ctx context.Context) error {
+	for {
+		b.Lock()
+		now := time.Now()
+		b.tokens = math.Min(b.burst, b.tokens+now.Sub(b.last).Seconds()*b.rate)
+		b.last = now
+		if b.tokens >= 1 {
+			b.tokens--
+			b.Unlock()
+			return nil
+		}
+		delay := time.Duration((1 - b.tokens) / b.rate * float64(time.Second))
+		b.Unlock()
+
+		t := time.NewTimer(delay)
+		select {
+		case <-t.C:
+		case <-ctx.Done():
+			t.Stop()
+			return ctx.Err()
+		}
+	}
+}
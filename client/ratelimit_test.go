@@ -0,0 +1,71 @@
+package client
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestRateLimitedClient(t *testing.T) {
+	c := newRateLimitedClient(MockClientWithResults(0, 10), newTokenBucket(10, 2))
+
+	start := time.Now()
+	for i := 0; i < 4; i++ {
+		if _, err := c.Get(context.Background(), 0); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// two requests fit in the burst, the next two wait 100ms each.
+	if elapsed := time.Since(start); elapsed < 150*time.Millisecond {
+		t.Fatal("requests were not rate limited", elapsed)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := c.Get(ctx, 0); err == nil {
+		t.Fatal("expected canceled request to fail")
+	}
+	if _, err := c.Info(ctx); err == nil {
+		t.Fatal("expected canceled info request to fail")
+	}
+	if _, ok := <-c.Watch(ctx); ok {
+		t.Fatal("expected canceled watch to deliver nothing")
+	}
+}
+
+func TestRateLimitSharedBucket(t *testing.T) {
+	// the info, watch and range requests take their tokens from the bucket of
+	// the source, with the rounds requested
+	bucket := newTokenBucket(10, 3)
+	source := MockClientWithResults(0, 10)
+	c := newRateLimitedClient(&infoMockClient{source, fakeChainInfo()}, bucket)
+	rc := &rateLimitedRangeClient{RangeClient: &rangeMockClient{MockClient: source}, bucket: bucket}
+
+	start := time.Now()
+	if _, err := c.Info(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	for range c.Watch(context.Background()) {
+	}
+	if _, err := rc.GetRange(context.Background(), 0, 1); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.Get(context.Background(), 0); err != nil {
+		t.Fatal(err)
+	}
+	// three requests fit in the burst, the fourth waits 100ms.
+	if elapsed := time.Since(start); elapsed < 75*time.Millisecond {
+		t.Fatal("requests were not rate limited", elapsed)
+	}
+}
+
+func TestRateLimitOption(t *testing.T) {
+	if _, err := New(From(MockClientWithResults(0, 5)), Insecurely(), WithRateLimit(0, 1)); err == nil {
+		t.Fatal("expected invalid rate limit to be refused")
+	}
+	c, err := New(From(MockClientWithResults(0, 5)), Insecurely(), WithRateLimit(100, 1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = c.Close()
+}
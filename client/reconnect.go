@@ -0,0 +1,64 @@
+package client
+
+import (
+	"context"
+	"time"
+)
+
+const (
+	minReconnectBackoff = 250 * time.Millisecond
+	maxReconnectBackoff = 30 * time.Second
+)
+
+// StreamFunc follows a stream of rounds and forwards the rounds after `last`
+// to out until the stream breaks or the context is done. It returns the last
+// round delivered.
+type StreamFunc func(ctx context.Context, last uint64, out chan<- Result) (uint64, error)
+
+// WatchReconnecting runs stream until the context is done or done is closed,
+// re-establishing it with an exponential backoff whenever it breaks. The stream
+// resumes after the last round delivered, and the backoff is reset once it
+// delivers a round. onRetry, when set, is called with the error of the stream
+// before waiting the backoff.
+func WatchReconnecting(ctx context.Context, done <-chan struct{}, stream StreamFunc,
+	onRetry func(last uint64, backoff time.Duration, err error)) <-chan Result {
+	out := make(chan Result)
+	go func() {
+		defer close(out)
+		ctx, cancel := context.WithCancel(ctx)
+		defer cancel()
+		go func() {
+			select {
+			case <-done:
+				cancel()
+			case <-ctx.Done():
+			}
+		}()
+
+		var last uint64
+		backoff := minReconnectBackoff
+		for {
+			delivered, err := stream(ctx, last, out)
+			if delivered > last {
+				last = delivered
+				backoff = minReconnectBackoff
+			}
+			if ctx.Err() != nil {
+				return
+			}
+			if onRetry != nil {
+				onRetry(last, backoff, err)
+			}
+			select {
+			case <-time.After(backoff):
+			case <-ctx.Done():
+				return
+			}
+			backoff *= 2
+			if backoff > maxReconnectBackoff {
+				backoff = maxReconnectBackoff
+			}
+		}
+	}()
+	return out
+}
@@ -0,0 +1,43 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestWatchReconnecting(t *testing.T) {
+	stream := func(ctx context.Context, last uint64, out chan<- Result) (uint64, error) {
+		if last >= 2 {
+			<-ctx.Done()
+			return last, ctx.Err()
+		}
+		// deliver the round after the last one, then break
+		select {
+		case out <- &RandomData{Rnd: last + 1}:
+		case <-ctx.Done():
+			return last, ctx.Err()
+		}
+		return last + 1, errors.New("broken")
+	}
+	done := make(chan struct{})
+	ch := WatchReconnecting(context.Background(), done, stream, func(last uint64, backoff time.Duration, err error) {
+		if backoff != minReconnectBackoff {
+			t.Errorf("backoff %s not reset after a delivered round", backoff)
+		}
+	})
+	// the stream resumes after the last round delivered
+	for want := uint64(1); want <= 2; want++ {
+		r := <-ch
+		if r.Round() != want {
+			t.Fatalf("round %d instead of %d", r.Round(), want)
+		}
+	}
+
+	// closing done stops the watch
+	close(done)
+	if _, ok := <-ch; ok {
+		t.Fatal("watch should be closed")
+	}
+}
@@ -0,0 +1,49 @@
+package sharedcache
+
+import (
+	"context"
+	"time"
+
+	"github.com/bradfitz/gomemcache/memcache"
+)
+
+// maxMemcachedTTL is the longest expiration memcached takes as a duration,
+// the longer ones being read as unix times.
+const maxMemcachedTTL = 30 * 24 * time.Hour
+
+// memcachedStore is a Store in memcached. The memcached client takes no
+// context: its requests are bounded by requestTimeout instead.
+type memcachedStore struct {
+	c *memcache.Client
+}
+
+// NewMemcached returns the store of the memcached server at addr.
+func NewMemcached(addr string) Store {
+	c := memcache.New(addr)
+	c.Timeout = requestTimeout
+	c.MaxIdleConns = poolSize
+	return &memcachedStore{c: c}
+}
+
+func (m *memcachedStore) Get(ctx context.Context, key string) ([]byte, error) {
+	item, err := m.c.Get(key)
+	if err == memcache.ErrCacheMiss {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return item.Value, nil
+}
+
+func (m *memcachedStore) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	if ttl > maxMemcachedTTL {
+		ttl = maxMemcachedTTL
+	}
+	return m.c.Set(&memcache.Item{Key: key, Value: value, Expiration: int32(ttl / time.Second)})
+}
+
+// Close does nothing: the memcached client cannot close its idle connections.
+func (m *memcachedStore) Close() error {
+	return nil
+}
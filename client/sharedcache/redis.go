@@ -0,0 +1,40 @@
+package sharedcache
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-redis/redis/v7"
+)
+
+// redisStore is a Store in Redis.
+type redisStore struct {
+	c *redis.Client
+}
+
+// NewRedis returns the store of the Redis server at addr, authenticating with
+// the password if not empty and using the given database.
+func NewRedis(addr, password string, db int) Store {
+	return newRedis(&redis.Options{Addr: addr, Password: password, DB: db})
+}
+
+func newRedis(opts *redis.Options) Store {
+	opts.PoolSize = poolSize
+	return &redisStore{c: redis.NewClient(opts)}
+}
+
+func (r *redisStore) Get(ctx context.Context, key string) ([]byte, error) {
+	value, err := r.c.WithContext(ctx).Get(key).Bytes()
+	if err == redis.Nil {
+		return nil, nil
+	}
+	return value, err
+}
+
+func (r *redisStore) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	return r.c.WithContext(ctx).Set(key, value, ttl).Err()
+}
+
+func (r *redisStore) Close() error {
+	return r.c.Close()
+}
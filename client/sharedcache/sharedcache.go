@@ -0,0 +1,162 @@
+// Package sharedcache caches the rounds served by a fleet of relays in a store
+// they share, Redis or memcached, so that the fleet fetches each round once
+// from the upstream nodes however many replicas it runs.
+//
+// The cache is given to the client with client.WithSharedCache, which puts it
+// below the verification of the rounds: the rounds read from the store are
+// verified like the rounds of the upstream nodes.
+package sharedcache
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/drand/drand/client"
+	"github.com/drand/drand/log"
+	"github.com/go-redis/redis/v7"
+
+	json "github.com/nikkolasg/hexjson"
+)
+
+const (
+	// DefaultTTL is how long the rounds are kept in the store by default.
+	DefaultTTL = 24 * time.Hour
+	// requestTimeout bounds the requests to the store, which must not slow
+	// down the relay much more than the upstream would.
+	requestTimeout = 500 * time.Millisecond
+	// poolSize is the number of connections kept to the store.
+	poolSize = 16
+)
+
+// Store is a key-value store shared by the relays. Get returns nil without
+// error for a missing key.
+type Store interface {
+	Get(ctx context.Context, key string) ([]byte, error)
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+	Close() error
+}
+
+// Open connects to the store of the given URL: redis://[:password@]host:port[/db]
+// or memcached://host:port.
+func Open(storeURL string) (Store, error) {
+	u, err := url.Parse(storeURL)
+	if err != nil {
+		return nil, fmt.Errorf("shared cache: %w", err)
+	}
+	switch u.Scheme {
+	case "redis":
+		opts, err := redis.ParseURL(storeURL)
+		if err != nil {
+			return nil, fmt.Errorf("shared cache: %w", err)
+		}
+		return newRedis(opts), nil
+	case "memcached":
+		return NewMemcached(u.Host), nil
+	default:
+		return nil, fmt.Errorf("shared cache: unsupported store %q", u.Scheme)
+	}
+}
+
+// Cache is a client.Cache kept in the shared store, for the rounds of one
+// chain. The failures of the store are logged, and served as cache misses.
+type Cache struct {
+	store  Store
+	prefix string
+	ttl    time.Duration
+	log    log.Logger
+	closed sync.Once
+}
+
+// NewCache returns the cache of the rounds of the chain of the given hash in
+// the store, kept for ttl. The keys of the store start with the hash of the
+// chain, which tells apart the chains sharing the store. The store is closed
+// along with the cache.
+func NewCache(store Store, chainHash []byte, ttl time.Duration) (*Cache, error) {
+	if len(chainHash) == 0 {
+		return nil, errors.New("shared cache: no chain hash")
+	}
+	return &Cache{
+		store:  store,
+		prefix: "drand:" + hex.EncodeToString(chainHash),
+		ttl:    ttl,
+		log:    log.DefaultLogger(),
+	}, nil
+}
+
+// Option returns the client option caching the rounds of the sources of the
+// client in the store of the given URL, for the chain of the client.
+func Option(storeURL string, ttl time.Duration) client.Option {
+	return client.WithSharedCache(func(chainHash []byte) (client.Cache, error) {
+		store, err := Open(storeURL)
+		if err != nil {
+			return nil, err
+		}
+		cache, err := NewCache(store, chainHash, ttl)
+		if err != nil {
+			store.Close()
+			return nil, err
+		}
+		return cache, nil
+	})
+}
+
+func (c *Cache) roundKey(round uint64) string {
+	return c.prefix + ":round:" + strconv.FormatUint(round, 10)
+}
+
+// TryGet returns the round from the store, or nil.
+func (c *Cache) TryGet(round uint64) client.Result {
+	ctx, cancel := context.WithTimeout(context.Background(), requestTimeout)
+	defer cancel()
+	b, err := c.store.Get(ctx, c.roundKey(round))
+	if err != nil {
+		c.log.Warn("shared_cache", "get", "round", round, "err", err)
+		return nil
+	}
+	if b == nil {
+		return nil
+	}
+	var r client.RandomData
+	if err := json.Unmarshal(b, &r); err != nil || r.Rnd != round {
+		c.log.Warn("shared_cache", "invalid round", "round", round, "err", err)
+		return nil
+	}
+	return &r
+}
+
+// Add stores the round. The randomness stored is the one of the source of the
+// round, which the verification, above the cache, derives again from the
+// signature as specified by the chain.
+func (c *Cache) Add(round uint64, r client.Result) {
+	rd, ok := r.(*client.RandomData)
+	if !ok {
+		rd = &client.RandomData{Rnd: r.Round(), Random: r.Randomness(), Sig: r.Signature()}
+		if p, ok := r.(interface{ PreviousSignature() []byte }); ok {
+			rd.PreviousSignature = p.PreviousSignature()
+		}
+	}
+	b, err := json.Marshal(rd)
+	if err != nil {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), requestTimeout)
+	defer cancel()
+	if err := c.store.Set(ctx, c.roundKey(round), b, c.ttl); err != nil {
+		c.log.Warn("shared_cache", "set", "round", round, "err", err)
+	}
+}
+
+// Close closes the store, once however many sources share the cache.
+func (c *Cache) Close() error {
+	var err error
+	c.closed.Do(func() {
+		err = c.store.Close()
+	})
+	return err
+}
@@ -0,0 +1,199 @@
+package sharedcache
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/drand/drand/chain"
+	"github.com/drand/drand/client"
+	"github.com/drand/drand/client/test/result/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeServer serves an in-memory map with the Redis or the memcached
+// protocol, enough for the tests.
+type fakeServer struct {
+	sync.Mutex
+	l      net.Listener
+	values map[string][]byte
+}
+
+func newFakeServer(t *testing.T, serve func(*fakeServer, *bufio.ReadWriter) error) *fakeServer {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	s := &fakeServer{l: l, values: make(map[string][]byte)}
+	go func() {
+		for {
+			c, err := l.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer c.Close()
+				rw := bufio.NewReadWriter(bufio.NewReader(c), bufio.NewWriter(c))
+				for serve(s, rw) == nil {
+					_ = rw.Flush()
+				}
+			}()
+		}
+	}()
+	return s
+}
+
+func readLine(rw *bufio.ReadWriter) (string, error) {
+	line, err := rw.ReadString('\n')
+	return strings.TrimSuffix(line, "\r\n"), err
+}
+
+func serveRedis(s *fakeServer, rw *bufio.ReadWriter) error {
+	line, err := readLine(rw)
+	if err != nil {
+		return err
+	}
+	n, _ := strconv.Atoi(line[1:])
+	args := make([]string, n)
+	for i := range args {
+		line, _ := readLine(rw)
+		size, _ := strconv.Atoi(line[1:])
+		buff := make([]byte, size+2)
+		if _, err := io.ReadFull(rw, buff); err != nil {
+			return err
+		}
+		args[i] = string(buff[:size])
+	}
+	s.Lock()
+	defer s.Unlock()
+	switch strings.ToUpper(args[0]) {
+	case "GET":
+		v, ok := s.values[args[1]]
+		if !ok {
+			_, err = rw.WriteString("$-1\r\n")
+			return err
+		}
+		_, err = fmt.Fprintf(rw, "$%d\r\n%s\r\n", len(v), v)
+	case "SET":
+		s.values[args[1]] = []byte(args[2])
+		_, err = rw.WriteString("+OK\r\n")
+	default:
+		_, err = rw.WriteString("-ERR unknown command\r\n")
+	}
+	return err
+}
+
+func serveMemcached(s *fakeServer, rw *bufio.ReadWriter) error {
+	line, err := readLine(rw)
+	if err != nil {
+		return err
+	}
+	fields := strings.Fields(line)
+	s.Lock()
+	defer s.Unlock()
+	switch fields[0] {
+	case "get", "gets":
+		if v, ok := s.values[fields[1]]; ok {
+			fmt.Fprintf(rw, "VALUE %s 0 %d 1\r\n%s\r\n", fields[1], len(v), v)
+		}
+		_, err = rw.WriteString("END\r\n")
+	case "set":
+		size, _ := strconv.Atoi(fields[4])
+		buff := make([]byte, size+2)
+		if _, err := io.ReadFull(rw, buff); err != nil {
+			return err
+		}
+		s.values[fields[1]] = buff[:size]
+		_, err = rw.WriteString("STORED\r\n")
+	}
+	return err
+}
+
+// countingClient serves verifiable rounds, and counts the requests reaching
+// the upstream.
+type countingClient struct {
+	client.Client
+	info    *chain.Info
+	results []mock.Result
+
+	sync.Mutex
+	gets map[uint64]int
+}
+
+func (c *countingClient) Get(ctx context.Context, round uint64) (client.Result, error) {
+	c.Lock()
+	c.gets[round]++
+	c.Unlock()
+	if round == 0 || round > uint64(len(c.results)) {
+		return nil, errors.New("no such round")
+	}
+	r := c.results[round-1]
+	return &client.RandomData{Rnd: r.Rnd, Random: r.Rand, Sig: r.Sig, SigV2: r.SigV2, PreviousSignature: r.PSig}, nil
+}
+
+func (c *countingClient) Info(ctx context.Context) (*chain.Info, error) {
+	return c.info, nil
+}
+
+func TestSharedCache(t *testing.T) {
+	info, results := mock.VerifiableResults(5, 1)
+	for name, serve := range map[string]func(*fakeServer, *bufio.ReadWriter) error{
+		"redis":     serveRedis,
+		"memcached": serveMemcached,
+	} {
+		t.Run(name, func(t *testing.T) {
+			server := newFakeServer(t, serve)
+			defer server.l.Close()
+			url := fmt.Sprintf("%s://%s", name, server.l.Addr())
+
+			// two relays of a fleet share the rounds
+			upstream := &countingClient{Client: client.EmptyClientWithInfo(info), info: info, results: results,
+				gets: make(map[uint64]int)}
+			var relays []client.Client
+			for i := 0; i < 2; i++ {
+				c, err := client.Wrap([]client.Client{upstream},
+					client.WithChainHash(info.Hash()),
+					client.WithCacheSize(0),
+					Option(url, DefaultTTL))
+				require.NoError(t, err)
+				relays = append(relays, c)
+			}
+			ctx := context.Background()
+			for _, r := range relays {
+				res, err := r.Get(ctx, 3)
+				require.NoError(t, err)
+				require.Equal(t, uint64(3), res.Round())
+			}
+			upstream.Lock()
+			require.Equal(t, 1, upstream.gets[3])
+			upstream.Unlock()
+
+			// the rounds of the store are verified
+			server.Lock()
+			for key := range server.values {
+				server.values[key] = []byte(`{"round":3,"signaturev2":"0011"}`)
+			}
+			server.Unlock()
+			_, err := relays[0].Get(ctx, 3)
+			require.Error(t, err)
+			for _, r := range relays {
+				require.NoError(t, r.Close())
+			}
+		})
+	}
+
+	_, err := Open("mongodb://localhost")
+	require.Error(t, err)
+}
+
+func TestNewCacheNeedsChainHash(t *testing.T) {
+	store, err := Open("memcached://127.0.0.1:0")
+	require.NoError(t, err)
+	_, err = NewCache(store, nil, DefaultTTL)
+	require.Error(t, err)
+}
@@ -0,0 +1,62 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+// flight is a Get call in progress, shared by the callers asking for the
+// same round.
+type flight struct {
+	done   chan struct{}
+	result Result
+	err    error
+}
+
+// flightGroup coalesces concurrent Get calls for the same round, so that they
+// share a single upstream fetch and verification.
+type flightGroup struct {
+	lk      sync.Mutex
+	flights map[uint64]*flight
+}
+
+// get returns the result of `fetch` for `round`, sharing the call with the
+// concurrent callers asking for the same round. A caller whose context is
+// still valid retries when the shared call failed because the context of the
+// caller who started it was done.
+func (g *flightGroup) get(ctx context.Context, round uint64, fetch func(context.Context) (Result, error)) (Result, error) {
+	for {
+		g.lk.Lock()
+		if g.flights == nil {
+			g.flights = make(map[uint64]*flight)
+		}
+		if f, ok := g.flights[round]; ok {
+			g.lk.Unlock()
+			select {
+			case <-f.done:
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			}
+			if isContextErr(f.err) && ctx.Err() == nil {
+				continue
+			}
+			return f.result, f.err
+		}
+		f := &flight{done: make(chan struct{})}
+		g.flights[round] = f
+		g.lk.Unlock()
+
+		f.result, f.err = fetch(ctx)
+
+		g.lk.Lock()
+		delete(g.flights, round)
+		g.lk.Unlock()
+		close(f.done)
+		return f.result, f.err
+	}
+}
+
+func isContextErr(err error) bool {
+	return errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded)
+}
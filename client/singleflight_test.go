@@ -0,0 +1,81 @@
+package client
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// countingGetClient counts the calls to Get of the wrapped client.
+type countingGetClient struct {
+	Client
+	calls int32
+}
+
+func (c *countingGetClient) Get(ctx context.Context, round uint64) (Result, error) {
+	atomic.AddInt32(&c.calls, 1)
+	return c.Client.Get(ctx, round)
+}
+
+func TestCachingClientCoalescesGets(t *testing.T) {
+	mc := MockClientWithResults(1, 5)
+	mc.StrictRounds = true
+	mc.Delay = 100 * time.Millisecond
+	source := &countingGetClient{Client: mc}
+	cache, err := makeCache(10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c, err := NewCachingClient(source, cache)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	wg := sync.WaitGroup{}
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			r, err := c.Get(context.Background(), 2)
+			if err != nil || r.Round() != 2 {
+				t.Error("unexpected result", r, err)
+			}
+		}()
+	}
+	wg.Wait()
+	if calls := atomic.LoadInt32(&source.calls); calls != 1 {
+		t.Fatal("expected a single upstream call, got", calls)
+	}
+}
+
+func TestFlightGroupRetriesCanceledLeader(t *testing.T) {
+	var g flightGroup
+	started := make(chan struct{})
+	leaderCtx, cancel := context.WithCancel(context.Background())
+	go func() {
+		_, _ = g.get(leaderCtx, 1, func(ctx context.Context) (Result, error) {
+			close(started)
+			<-ctx.Done()
+			return nil, ctx.Err()
+		})
+	}()
+	<-started
+
+	done := make(chan Result)
+	go func() {
+		r, err := g.get(context.Background(), 1, func(ctx context.Context) (Result, error) {
+			return &RandomData{Rnd: 1}, nil
+		})
+		if err != nil {
+			t.Error(err)
+		}
+		done <- r
+	}()
+	time.Sleep(10 * time.Millisecond)
+	cancel()
+	if r := <-done; r == nil || r.Round() != 1 {
+		t.Fatal("follower should have retried", r)
+	}
+}
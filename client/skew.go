@@ -0,0 +1,151 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/drand/drand/log"
+)
+
+// DetectClockSkew estimates how far the local clock is from the clock of the
+// randomness chain, by comparing the time at which the latest round available
+// from the client was produced with the local time. A positive value means
+// the local clock is behind, a negative one that it is ahead. Skews smaller
+// than the chain period cannot be detected and are reported as 0.
+//
+// Note that a source lagging behind the chain looks like a local clock being
+// ahead.
+func DetectClockSkew(ctx context.Context, c Client) (time.Duration, error) {
+	info, err := c.Info(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("could not get chain info: %w", err)
+	}
+	r, err := c.Get(ctx, 0)
+	if err != nil {
+		return 0, err
+	}
+	now := time.Now()
+	produced := info.TimeOfRound(r.Round())
+	switch {
+	case produced.After(now):
+		// the round can't exist yet according to our clock.
+		return produced.Sub(now), nil
+	case now.Sub(produced) > 2*info.Period:
+		// the next round should have been produced already; allow one period
+		// for the network to deliver it.
+		return -(now.Sub(produced) - 2*info.Period), nil
+	default:
+		return 0, nil
+	}
+}
+
+// ClockDrift describes a round delivered by `Watch` away from the time the
+// chain schedules it at, as seen by the local clock.
+type ClockDrift struct {
+	Round uint64
+	// Scheduled is the time the round is produced at according to the chain
+	// info.
+	Scheduled time.Time
+	// Received is the local time the round was delivered at.
+	Received time.Time
+}
+
+// Drift is how late the round was delivered, negative when it was delivered
+// before its time.
+func (d *ClockDrift) Drift() time.Duration {
+	return d.Received.Sub(d.Scheduled)
+}
+
+// WithClockDriftCheck compares the time the rounds of `Watch` are delivered at
+// with the time the chain schedules them at, and warns when they are more than
+// `threshold` apart, a period of the chain when it is 0. Rounds delivered
+// early or late reveal a local clock off the time of the chain, or a chain
+// info with the wrong genesis time or period. The drifting rounds are reported
+// to `onDrift`, which may be nil, while the log only warns when the drift
+// starts and when it stops.
+func WithClockDriftCheck(threshold time.Duration, onDrift func(*ClockDrift)) Option {
+	return func(cfg *clientConfig) error {
+		if threshold < 0 {
+			return fmt.Errorf("invalid clock drift threshold %s", threshold)
+		}
+		cfg.clockDriftCheck = true
+		cfg.clockDriftThreshold = threshold
+		cfg.onClockDrift = onDrift
+		return nil
+	}
+}
+
+// newClockDriftClient wraps a client to check the delivery times of its
+// watches.
+func newClockDriftClient(c Client, threshold time.Duration, onDrift func(*ClockDrift)) *clockDriftClient {
+	return &clockDriftClient{
+		Client:    c,
+		threshold: threshold,
+		onDrift:   onDrift,
+		now:       time.Now,
+		log:       log.DefaultLogger(),
+	}
+}
+
+type clockDriftClient struct {
+	Client
+	threshold time.Duration
+	onDrift   func(*ClockDrift)
+	now       func() time.Time
+	log       log.Logger
+}
+
+// SetLog configures the client log output.
+func (c *clockDriftClient) SetLog(l log.Logger) {
+	c.log = l
+	trySetLog(c.Client, l)
+}
+
+// String returns the name of this client.
+func (c *clockDriftClient) String() string {
+	return fmt.Sprintf("%s.(+clockdrift)", c.Client)
+}
+
+// Watch returns new randomness as it becomes available, checking it arrives
+// on schedule.
+func (c *clockDriftClient) Watch(ctx context.Context) <-chan Result {
+	info, err := c.Info(ctx)
+	if err != nil {
+		c.log.Warn("clock_drift", "could not get chain info, watching without drift check", "err", err)
+		return c.Client.Watch(ctx)
+	}
+	threshold := c.threshold
+	if threshold == 0 {
+		threshold = info.Period
+	}
+
+	in := c.Client.Watch(ctx)
+	out := make(chan Result)
+	go func() {
+		defer close(out)
+		drifting := false
+		for r := range in {
+			d := &ClockDrift{Round: r.Round(), Scheduled: info.TimeOfRound(r.Round()), Received: c.now()}
+			if drift := d.Drift(); drift > threshold || drift < -threshold {
+				if !drifting {
+					c.log.Warn("clock_drift", "rounds delivered off schedule, check the local clock and the chain info",
+						"round", d.Round, "drift", drift)
+					drifting = true
+				}
+				if c.onDrift != nil {
+					c.onDrift(d)
+				}
+			} else if drifting {
+				c.log.Info("clock_drift", "rounds delivered on schedule again", "round", d.Round)
+				drifting = false
+			}
+			select {
+			case out <- r:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return out
+}
@@ -0,0 +1,62 @@
+package client
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestDetectClockSkew(t *testing.T) {
+	info := fakeChainInfo()
+	info.GenesisTime = time.Now().Unix() - 100
+	current := info.RoundAt(time.Now())
+
+	for _, tc := range []struct {
+		round    uint64
+		behind   bool
+		ahead    bool
+		expected string
+	}{
+		{round: current, expected: "no skew"},
+		{round: current + 10, behind: true, expected: "local clock behind"},
+		{round: current - 10, ahead: true, expected: "local clock ahead"},
+	} {
+		c := &infoMockClient{MockClientWithResults(tc.round, tc.round+1), info}
+		skew, err := DetectClockSkew(context.Background(), c)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if (skew > 0) != tc.behind || (skew < 0) != tc.ahead {
+			t.Fatal("expected", tc.expected, "got skew", skew)
+		}
+	}
+}
+
+func TestClockDriftCheck(t *testing.T) {
+	info := fakeChainInfo()
+	info.GenesisTime = time.Now().Unix() - 100
+	current := info.RoundAt(time.Now())
+
+	ch := make(chan Result, 3)
+	ch <- &RandomData{Rnd: current}
+	// a round 10 periods ahead of the local clock
+	ch <- &RandomData{Rnd: current + 10}
+	ch <- &RandomData{Rnd: current + 1}
+	close(ch)
+
+	var drifts []*ClockDrift
+	c := newClockDriftClient(&infoMockClient{&MockClient{WatchCh: ch}, info}, 0, func(d *ClockDrift) {
+		drifts = append(drifts, d)
+	})
+	c.now = func() time.Time { return info.TimeOfRound(current) }
+	var rounds []uint64
+	for r := range c.Watch(context.Background()) {
+		rounds = append(rounds, r.Round())
+	}
+	if len(rounds) != 3 {
+		t.Fatal("expected all the rounds delivered, got", rounds)
+	}
+	if len(drifts) != 1 || drifts[0].Round != current+10 || drifts[0].Drift() != -10*info.Period {
+		t.Fatal("expected the drift of the round ahead only, got", drifts)
+	}
+}
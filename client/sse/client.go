@@ -0,0 +1,140 @@
+package sse
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	nhttp "net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/drand/drand/client"
+	"github.com/drand/drand/client/http"
+	"github.com/drand/drand/log"
+
+	json "github.com/nikkolasg/hexjson"
+)
+
+type sseClient struct {
+	// Client is the HTTP client of the relay, used for everything but Watch.
+	client.Client
+
+	url       string
+	client    *nhttp.Client
+	l         log.Logger
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+// New creates a drand client for the relay at `url`, following new rounds as
+// Server-Sent Events. The chain hash, if given, is checked against the chain
+// information advertised by the relay.
+func New(url string, chainHash []byte, transport nhttp.RoundTripper) (client.Client, error) {
+	if transport == nil {
+		transport = nhttp.DefaultTransport
+	}
+	if !strings.HasSuffix(url, "/") {
+		url += "/"
+	}
+	hc, err := http.New(url, chainHash, transport)
+	if err != nil {
+		return nil, err
+	}
+	return &sseClient{
+		Client: hc,
+		url:    url + "events",
+		client: &nhttp.Client{Transport: transport},
+		l:      log.DefaultLogger(),
+		done:   make(chan struct{}),
+	}, nil
+}
+
+// String returns the name of this client.
+func (s *sseClient) String() string {
+	return fmt.Sprintf("SSE(%q)", s.url)
+}
+
+// SetLog configures the client log output.
+func (s *sseClient) SetLog(l log.Logger) {
+	s.l = l
+	if lc, ok := s.Client.(client.LoggingClient); ok {
+		lc.SetLog(l)
+	}
+}
+
+// Watch returns new randomness as it becomes available. The event stream is
+// re-established whenever the connection is lost, resuming after the last
+// delivered round.
+func (s *sseClient) Watch(ctx context.Context) <-chan client.Result {
+	return client.WatchReconnecting(ctx, s.done, s.stream, func(last uint64, backoff time.Duration, err error) {
+		s.l.Warn("sse_client", "stream interrupted", "url", s.url, "last", last, "retry", backoff, "err", err)
+	})
+}
+
+// stream follows the event stream and forwards rounds after `last` to out
+// until the connection fails. It returns the last round delivered.
+func (s *sseClient) stream(ctx context.Context, last uint64, out chan<- client.Result) (uint64, error) {
+	req, err := nhttp.NewRequestWithContext(ctx, "GET", s.url, nil)
+	if err != nil {
+		return last, fmt.Errorf("creating request: %w", err)
+	}
+	req.Header.Set("Accept", "text/event-stream")
+	if last > 0 {
+		req.Header.Set("Last-Event-ID", strconv.FormatUint(last, 10))
+	}
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return last, fmt.Errorf("doing request: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != nhttp.StatusOK {
+		return last, fmt.Errorf("unexpected status: %s", resp.Status)
+	}
+
+	var data strings.Builder
+	scanner := bufio.NewScanner(resp.Body)
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case line == "":
+			// a blank line dispatches the event.
+			if data.Len() == 0 {
+				continue
+			}
+			rd := new(client.RandomData)
+			err := json.Unmarshal([]byte(data.String()), rd)
+			data.Reset()
+			if err != nil {
+				return last, fmt.Errorf("decoding round: %w", err)
+			}
+			if rd.Round() <= last {
+				continue
+			}
+			select {
+			case out <- rd:
+				last = rd.Round()
+			case <-ctx.Done():
+				return last, ctx.Err()
+			}
+		case strings.HasPrefix(line, "data:"):
+			if data.Len() > 0 {
+				data.WriteByte('\n')
+			}
+			data.WriteString(strings.TrimPrefix(strings.TrimPrefix(line, "data:"), " "))
+		}
+		// ids, event names and comments carry nothing we need: the round
+		// number is part of the data.
+	}
+	if err := scanner.Err(); err != nil {
+		return last, err
+	}
+	return last, fmt.Errorf("stream closed")
+}
+
+// Close stops all streams and the underlying HTTP client.
+func (s *sseClient) Close() error {
+	s.closeOnce.Do(func() { close(s.done) })
+	return s.Client.Close()
+}
@@ -0,0 +1,88 @@
+package sse
+
+import (
+	"context"
+	"fmt"
+	nhttp "net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/drand/drand/client/test/http/mock"
+	"github.com/drand/drand/log"
+)
+
+func TestSSEWatch(t *testing.T) {
+	addr, chainInfo, cancel, emit := mock.NewMockHTTPPublicServer(t, false)
+	defer cancel()
+
+	c, err := New("http://"+addr, chainInfo.Hash(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	ctx, cancelWatch := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancelWatch()
+	results := c.Watch(ctx)
+
+	var last uint64
+	for i := 0; i < 3; i++ {
+		// give the stream time to be subscribed before emitting.
+		time.Sleep(200 * time.Millisecond)
+		emit(false)
+		select {
+		case r, ok := <-results:
+			if !ok {
+				t.Fatal("watch closed early")
+			}
+			if r.Round() <= last {
+				t.Fatal("expected increasing rounds", last, r.Round())
+			}
+			last = r.Round()
+		case <-ctx.Done():
+			t.Fatal("timed out waiting for round")
+		}
+	}
+}
+
+func TestSSEResume(t *testing.T) {
+	lastEventIDs := make(chan string, 2)
+	srv := httptest.NewServer(nhttp.HandlerFunc(func(w nhttp.ResponseWriter, r *nhttp.Request) {
+		id := r.Header.Get("Last-Event-ID")
+		select {
+		case lastEventIDs <- id:
+		default:
+		}
+		w.Header().Set("Content-Type", "text/event-stream")
+		// round 5 is sent on every connection but must only be delivered once.
+		fmt.Fprint(w, "id: 5\nevent: round\ndata: {\"round\":5}\n\n")
+		if id != "" {
+			fmt.Fprint(w, "id: 6\nevent: round\ndata: {\"round\":6}\n\n")
+		}
+	}))
+	defer srv.Close()
+
+	c := &sseClient{
+		url:    srv.URL,
+		client: srv.Client(),
+		l:      log.DefaultLogger(),
+		done:   make(chan struct{}),
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	results := c.Watch(ctx)
+
+	for _, expected := range []uint64{5, 6} {
+		r := <-results
+		if r == nil || r.Round() != expected {
+			t.Fatal("unexpected round", r, expected)
+		}
+	}
+	if id := <-lastEventIDs; id != "" {
+		t.Fatal("first connection should not resume", id)
+	}
+	if id := <-lastEventIDs; id != "5" {
+		t.Fatal("reconnection should resume after the last round", id)
+	}
+}
@@ -0,0 +1,36 @@
+/*
+Package sse provides a drand client implementation that streams new rounds of
+randomness from a drand HTTP relay as Server-Sent Events.
+
+Chain information and individual rounds are fetched with the relay's HTTP API,
+while "Watch" follows the relay's "/events" stream. Every event is identified
+by its round number: when the connection drops, the client reconnects with an
+increasing backoff and sends the last round it delivered in the
+"Last-Event-ID" header, so the relay resumes the stream where it left off.
+
+Example:
+
+	package main
+
+	import (
+		"encoding/hex"
+
+		"github.com/drand/drand/client"
+		"github.com/drand/drand/client/sse"
+	)
+
+	var chainHash, _ = hex.DecodeString("8990e7a9aaed2ffed73dbd7092123d6f289930540d7651336225dc172e51b2ce")
+
+	func main() {
+		sc, err := sse.New("https://api.drand.sh", chainHash, nil)
+
+		c, err := client.New(
+			client.From(sc),
+			client.WithChainHash(chainHash),
+		)
+	}
+
+Server-Sent Events only require plain HTTP, which makes this client suitable
+for environments where neither gRPC nor libp2p are available.
+*/
+package sse
@@ -0,0 +1,149 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+
+	"github.com/drand/drand/log"
+)
+
+// Stats is a snapshot of the runtime counters of a client created with `New`,
+// for applications to introspect the health of their client without
+// Prometheus.
+type Stats struct {
+	// Backends holds the counters of each source, in the order they were
+	// added to the client.
+	Backends []BackendStats
+	// CacheHits and CacheMisses count the Get calls served from, and missing,
+	// the cache of the client.
+	CacheHits   uint64
+	CacheMisses uint64
+	// LastVerifiedRound is the highest round verified by the client.
+	LastVerifiedRound uint64
+	// FailoverTarget is the source currently tried first.
+	FailoverTarget string
+}
+
+// BackendStats holds the counters of a source of a client.
+type BackendStats struct {
+	// Name identifies the source.
+	Name string
+	// Requests counts the Get calls made to the source, including the ones
+	// made to verify other rounds.
+	Requests uint64
+	// Failures counts the Get calls to the source that failed.
+	Failures uint64
+}
+
+// CacheHitRate returns the ratio of Get calls served from the cache, 0 when
+// no call was made.
+func (s Stats) CacheHitRate() float64 {
+	total := s.CacheHits + s.CacheMisses
+	if total == 0 {
+		return 0
+	}
+	return float64(s.CacheHits) / float64(total)
+}
+
+// StatsOf returns the stats of a client created with `New`, and false for
+// other clients.
+func StatsOf(c Client) (Stats, bool) {
+	if sc, ok := c.(interface{ Stats() Stats }); ok {
+		return sc.Stats(), true
+	}
+	return Stats{}, false
+}
+
+// statsCollector gathers the counters of the clients composing a client.
+type statsCollector struct {
+	// lastVerified is accessed atomically, and kept first for 64-bit
+	// alignment.
+	lastVerified uint64
+
+	lk       sync.Mutex
+	backends []*countingClient
+	cache    *cachingClient
+	oc       *optimizingClient
+}
+
+// count wraps a source to count its requests.
+func (s *statsCollector) count(c Client) Client {
+	cc := &countingClient{Client: c}
+	s.lk.Lock()
+	s.backends = append(s.backends, cc)
+	s.lk.Unlock()
+	return cc
+}
+
+// verified records that a round was verified.
+func (s *statsCollector) verified(round uint64) {
+	if s == nil {
+		return
+	}
+	for {
+		last := atomic.LoadUint64(&s.lastVerified)
+		if round <= last || atomic.CompareAndSwapUint64(&s.lastVerified, last, round) {
+			return
+		}
+	}
+}
+
+// snapshot returns the current counters.
+func (s *statsCollector) snapshot() Stats {
+	s.lk.Lock()
+	defer s.lk.Unlock()
+	st := Stats{
+		Backends:          make([]BackendStats, 0, len(s.backends)),
+		LastVerifiedRound: atomic.LoadUint64(&s.lastVerified),
+	}
+	for _, b := range s.backends {
+		st.Backends = append(st.Backends, BackendStats{
+			Name:     fmt.Sprint(b.Client),
+			Requests: atomic.LoadUint64(&b.requests),
+			Failures: atomic.LoadUint64(&b.failures),
+		})
+	}
+	if s.cache != nil {
+		st.CacheHits = atomic.LoadUint64(&s.cache.hits)
+		st.CacheMisses = atomic.LoadUint64(&s.cache.misses)
+	}
+	if s.oc != nil {
+		if clients := s.oc.fastestClients(); len(clients) > 0 {
+			st.FailoverTarget = fmt.Sprint(clients[0])
+		}
+	}
+	return st
+}
+
+// countingClient counts the requests made to a source.
+type countingClient struct {
+	// requests and failures are accessed atomically, and kept first for
+	// 64-bit alignment.
+	requests uint64
+	failures uint64
+
+	Client
+}
+
+// SetLog configures the log output of the wrapped client.
+func (c *countingClient) SetLog(l log.Logger) {
+	trySetLog(c.Client, l)
+}
+
+// String returns the name of the wrapped client, so that sources are
+// reported under their own name.
+func (c *countingClient) String() string {
+	return fmt.Sprint(c.Client)
+}
+
+// Get returns the randomness at `round` or an error.
+func (c *countingClient) Get(ctx context.Context, round uint64) (Result, error) {
+	atomic.AddUint64(&c.requests, 1)
+	r, err := c.Client.Get(ctx, round)
+	if err != nil && ctx.Err() == nil {
+		atomic.AddUint64(&c.failures, 1)
+	}
+	return r, err
+}
@@ -0,0 +1,45 @@
+package client
+
+import (
+	"context"
+	"testing"
+
+	"github.com/drand/drand/client/test/result/mock"
+)
+
+func TestStats(t *testing.T) {
+	info, results := mock.VerifiableResults(5, 1)
+	source := &infoMockClient{&MockClient{Results: results, StrictRounds: true}, info}
+	c, err := New(From(source), WithChainInfo(info))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	for i := 0; i < 2; i++ {
+		if _, err := c.Get(context.Background(), 3); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	s, ok := StatsOf(c)
+	if !ok {
+		t.Fatal("client should expose stats")
+	}
+	if len(s.Backends) != 1 || s.Backends[0].Requests == 0 || s.Backends[0].Failures != 0 {
+		t.Fatal("unexpected backend stats", s.Backends)
+	}
+	if s.CacheHits == 0 || s.CacheHitRate() == 0 {
+		t.Fatal("second Get should hit the cache", s)
+	}
+	if s.LastVerifiedRound < 3 {
+		t.Fatal("unexpected last verified round", s.LastVerifiedRound)
+	}
+	if s.FailoverTarget == "" {
+		t.Fatal("missing failover target")
+	}
+
+	if _, ok := StatsOf(source); ok {
+		t.Fatal("mock client has no stats")
+	}
+}
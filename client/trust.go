@@ -0,0 +1,162 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/drand/drand/chain"
+	"github.com/drand/drand/log"
+)
+
+// ErrUntrustedInfo is returned when a backend advertises chain information
+// that does not match the root of trust the client was configured with.
+var ErrUntrustedInfo = errors.New("chain info does not match root of trust")
+
+// newTrustedInfoClient wraps a client so that the chain information it
+// advertises is checked against the configured root of trust. Results are
+// only passed through once the source has proven it serves the expected chain.
+func newTrustedInfoClient(c Client, chainHash []byte, chainInfo *chain.Info) *trustedInfoClient {
+	if chainInfo != nil {
+		chainHash = chainInfo.Hash()
+	}
+	return &trustedInfoClient{
+		Client:    c,
+		chainHash: chainHash,
+		chainInfo: chainInfo,
+		verified:  &verifiedInfo{info: chainInfo},
+	}
+}
+
+// verifiedInfo is the chain information matching the root of trust, once
+// known. It is shared by the sources of a client.
+type verifiedInfo struct {
+	sync.Mutex
+	info *chain.Info
+}
+
+func (v *verifiedInfo) get() *chain.Info {
+	v.Lock()
+	defer v.Unlock()
+	return v.info
+}
+
+func (v *verifiedInfo) set(info *chain.Info) {
+	v.Lock()
+	defer v.Unlock()
+	if v.info == nil {
+		v.info = info
+	}
+}
+
+type trustedInfoClient struct {
+	Client
+
+	chainHash []byte
+	chainInfo *chain.Info
+	// pinned indicates chainInfo was pinned on first use, mismatches are
+	// then reported as a PinMismatchError.
+	pinned bool
+
+	verified *verifiedInfo
+	// checked is set once the wrapped client served the trusted chain
+	// information, which is not checked again afterwards.
+	lk      sync.Mutex
+	checked bool
+}
+
+// checkInfo returns an error if info does not match the root of trust.
+func (t *trustedInfoClient) checkInfo(info *chain.Info) error {
+	if info == nil {
+		return fmt.Errorf("%s: %w: no chain info", t.Client, ErrUntrustedInfo)
+	}
+	if t.chainInfo != nil && !t.chainInfo.Equal(info) {
+		if t.pinned {
+			return fmt.Errorf("%s: %w", t.Client, &PinMismatchError{Pinned: t.chainInfo, Served: info})
+		}
+		return fmt.Errorf("%s: %w", t.Client, ErrUntrustedInfo)
+	}
+	if h := info.Hash(); !bytes.Equal(h, t.chainHash) {
+		return fmt.Errorf("%s: %w (%x vs %x)", t.Client, ErrUntrustedInfo, h, t.chainHash)
+	}
+	return nil
+}
+
+// check fails unless the wrapped client serves the trusted chain, which it
+// checks until it succeeds once. A client which cannot report its chain
+// information passes once the trusted chain information is known, as its
+// results are verified against it, and is checked again on the next call.
+func (t *trustedInfoClient) check(ctx context.Context) error {
+	t.lk.Lock()
+	checked := t.checked
+	t.lk.Unlock()
+	if checked {
+		return nil
+	}
+	info, err := t.Client.Info(ctx)
+	if err != nil {
+		if t.verified.get() == nil {
+			return fmt.Errorf("%s: no trusted chain info to verify its results: %w", t.Client, err)
+		}
+		return nil
+	}
+	if err := t.checkInfo(info); err != nil {
+		return err
+	}
+	t.verified.set(info)
+	t.lk.Lock()
+	t.checked = true
+	t.lk.Unlock()
+	return nil
+}
+
+// Info returns the trusted chain information, once the wrapped client serves
+// it.
+func (t *trustedInfoClient) Info(ctx context.Context) (*chain.Info, error) {
+	if err := t.check(ctx); err != nil {
+		return nil, err
+	}
+	return t.verified.get(), nil
+}
+
+// Get returns the randomness at `round` unless the wrapped client serves a
+// chain other than the trusted one.
+func (t *trustedInfoClient) Get(ctx context.Context, round uint64) (Result, error) {
+	if err := t.check(ctx); err != nil {
+		return nil, err
+	}
+	return t.Client.Get(ctx, round)
+}
+
+// Watch returns new randomness from the wrapped client unless it serves a
+// chain other than the trusted one, in which case the returned channel is
+// closed immediately.
+func (t *trustedInfoClient) Watch(ctx context.Context) <-chan Result {
+	if err := t.check(ctx); err != nil {
+		ch := make(chan Result)
+		close(ch)
+		return ch
+	}
+	return t.Client.Watch(ctx)
+}
+
+// RoundAt uses the trusted chain parameters when they are known.
+func (t *trustedInfoClient) RoundAt(tm time.Time) uint64 {
+	if t.chainInfo != nil {
+		return t.chainInfo.RoundAt(tm)
+	}
+	return t.Client.RoundAt(tm)
+}
+
+// SetLog configures the log output of the wrapped client.
+func (t *trustedInfoClient) SetLog(l log.Logger) {
+	trySetLog(t.Client, l)
+}
+
+// String returns the name of this client.
+func (t *trustedInfoClient) String() string {
+	return fmt.Sprintf("%s.(+trusted info)", t.Client)
+}
@@ -0,0 +1,115 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/drand/drand/chain"
+)
+
+func TestTrustedInfoClientRefusesMismatch(t *testing.T) {
+	trusted := fakeChainInfo()
+	other := fakeChainInfo()
+
+	c := newTrustedInfoClient(MockClientWithInfo(other), trusted.Hash(), nil)
+	if _, err := c.Info(context.Background()); !errors.Is(err, ErrUntrustedInfo) {
+		t.Fatal("expected untrusted info error", err)
+	}
+	if _, err := c.Get(context.Background(), 1); !errors.Is(err, ErrUntrustedInfo) {
+		t.Fatal("expected untrusted info error", err)
+	}
+	if _, ok := <-c.Watch(context.Background()); ok {
+		t.Fatal("expected closed watch channel")
+	}
+
+	c = newTrustedInfoClient(MockClientWithInfo(trusted), nil, trusted)
+	info, err := c.Info(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !info.Equal(trusted) {
+		t.Fatal("unexpected chain info", info)
+	}
+}
+
+func TestTrustedInfoClientRefusesInfolessSources(t *testing.T) {
+	trusted := fakeChainInfo()
+	c := newTrustedInfoClient(MockClientWithResults(1, 2), trusted.Hash(), nil)
+	if _, err := c.Get(context.Background(), 1); err == nil {
+		t.Fatal("expected an error without chain info")
+	}
+	if _, err := c.Info(context.Background()); err == nil {
+		t.Fatal("expected an error without chain info")
+	}
+}
+
+func TestTrustedInfoClientChecksInfoOnce(t *testing.T) {
+	trusted := fakeChainInfo()
+	source := &countingInfoClient{Client: &infoMockClient{MockClientWithResults(1, 4), trusted}}
+	c := newTrustedInfoClient(source, trusted.Hash(), nil)
+	for i := uint64(1); i < 4; i++ {
+		if _, err := c.Get(context.Background(), i); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := c.Info(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if source.calls != 1 {
+		t.Fatal("chain info should be fetched once, got", source.calls)
+	}
+}
+
+// flakyInfoClient fails to report its chain information while err is set.
+type flakyInfoClient struct {
+	Client
+	info *chain.Info
+	err  error
+}
+
+func (c *flakyInfoClient) Info(ctx context.Context) (*chain.Info, error) {
+	if c.err != nil {
+		return nil, c.err
+	}
+	return c.info, nil
+}
+
+func TestTrustedInfoClientRechecksFailingSource(t *testing.T) {
+	trusted := fakeChainInfo()
+	source := &flakyInfoClient{Client: MockClientWithResults(1, 4), err: errors.New("unavailable")}
+	c := newTrustedInfoClient(source, nil, trusted)
+	// the results of the source are verified against the trusted info
+	if _, err := c.Get(context.Background(), 1); err != nil {
+		t.Fatal(err)
+	}
+	// once it reports its chain information again, the source is checked
+	source.err = nil
+	source.info = fakeChainInfo()
+	if _, err := c.Get(context.Background(), 2); !errors.Is(err, ErrUntrustedInfo) {
+		t.Fatal("expected untrusted info error", err)
+	}
+	source.info = trusted
+	if _, err := c.Get(context.Background(), 3); err != nil {
+		t.Fatal(err)
+	}
+	// and it is not checked again after it served the trusted info
+	source.info = fakeChainInfo()
+	if _, err := c.Info(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestClientRefusesMismatchingSource(t *testing.T) {
+	trusted := fakeChainInfo()
+	_, err := New(
+		From(MockClientWithInfo(fakeChainInfo())),
+		WithChainHash(trusted.Hash()),
+		WithWatcher(func(_ *chain.Info, _ Cache) (Watcher, error) {
+			return &MockClient{}, nil
+		}),
+	)
+	if !errors.Is(err, ErrUntrustedInfo) {
+		t.Fatal("expected untrusted info error", err)
+	}
+}
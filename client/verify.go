@@ -2,13 +2,19 @@ package client
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"sync"
+	"time"
 
 	"github.com/drand/drand/chain"
 	"github.com/drand/drand/log"
 )
 
+// verificationProgressInterval is the minimum time between two reports of
+// the progress of a chain walk.
+const verificationProgressInterval = time.Second
+
 // newVerifyingClient wraps a client to perform `chain.Verify` on emitted results.
 // v2from indicates from which round to verify the v2 signature only. Before
 // that round, the client only verifies the v1.
@@ -29,6 +35,26 @@ type verifyingClient struct {
 	// indirectClient is used to fetch other rounds of randomness needed for verification.
 	// it is separated so that it can provide a cache or shared pool that the direct client may not.
 	indirectClient Client
+	// infoCache, when set, is the cache serving the chain info of
+	// indirectClient. It is refreshed when a round fails verification.
+	infoCache *infoCache
+	// prefetcher, when set, fetches the rounds of chain walks ahead of time.
+	prefetcher *prefetcher
+	// progress, when set, is told about the progress of chain walks.
+	progress func(current, target uint64)
+	// verifyCache, when set, holds the signatures already verified, possibly
+	// by other clients.
+	verifyCache *VerificationCache
+	// stats, when set, records the verified rounds.
+	stats *statsCollector
+	// events, when set, is told about verification failures and chain info
+	// changes.
+	events *eventBus
+
+	// verifier verifies the beacons of the chain, created from its latest
+	// info.
+	verifier   *chain.Verifier
+	verifierLk sync.Mutex
 
 	pointOfTrust Result
 	potLk        sync.Mutex
@@ -54,9 +80,11 @@ func (v *verifyingClient) Get(ctx context.Context, round uint64) (Result, error)
 		return nil, err
 	}
 	rd := v.asRandomData(r)
-	if err := v.verify(ctx, info, rd); err != nil {
+	if _, err := v.verifyWithRefresh(ctx, info, rd); err != nil {
+		v.events.emit(Event{Type: EventVerificationFailed, Source: fmt.Sprint(v.Client), Round: rd.Round(), Err: err})
 		return nil, err
 	}
+	v.stats.verified(rd.Round())
 	return rd, nil
 }
 
@@ -75,10 +103,13 @@ func (v *verifyingClient) Watch(ctx context.Context) <-chan Result {
 	go func() {
 		defer close(outCh)
 		for r := range inCh {
-			if err := v.verify(ctx, info, v.asRandomData(r)); err != nil {
+			var err error
+			if info, err = v.verifyWithRefresh(ctx, info, v.asRandomData(r)); err != nil {
 				v.log.Warn("verifying_client", "skipping invalid watch round", "round", r.Round(), "err", err)
+				v.events.emit(Event{Type: EventVerificationFailed, Source: fmt.Sprint(v.Client), Round: r.Round(), Err: err})
 				continue
 			}
+			v.stats.verified(r.Round())
 			outCh <- r
 		}
 	}()
@@ -142,11 +173,30 @@ func (v *verifyingClient) getTrustedPreviousSignature(ctx context.Context, round
 	}
 	initialTrustRound := trustRound
 
+	var ahead <-chan prefetched
+	if v.prefetcher != nil && trustRound+1 < round-1 {
+		pctx, cancel := context.WithCancel(ctx)
+		defer cancel()
+		ahead = v.prefetcher.prefetch(pctx, v.indirectClient, trustRound+1, round-1)
+	}
+
+	lastProgress := time.Now()
 	var next Result
 	for trustRound < round-1 {
 		trustRound++
 		v.log.Debug("verifying_client", "loading round to verify", "round", trustRound)
-		next, err = v.indirectClient.Get(ctx, trustRound)
+		if ahead != nil {
+			p, ok := <-ahead
+			if !ok {
+				p.err = ctx.Err()
+				if p.err == nil {
+					p.err = errors.New("prefetch ended early")
+				}
+			}
+			next, err = p.result, p.err
+		} else {
+			next, err = v.indirectClient.Get(ctx, trustRound)
+		}
 		if err != nil {
 			return []byte{}, fmt.Errorf("could not get round %d: %w", trustRound, err)
 		}
@@ -154,12 +204,17 @@ func (v *verifyingClient) getTrustedPreviousSignature(ctx context.Context, round
 		b.Round = trustRound
 		b.Signature = next.Signature()
 
-		ipk := info.PublicKey.Clone()
-		if err := chain.VerifyBeacon(ipk, &b); err != nil {
-			v.log.Warn("verifying_client", "failed to verify value", "b", b, "err", err)
-			return []byte{}, fmt.Errorf("verifying beacon: %w", err)
+		if !v.knownValid(info, trustRound, false, b.Signature) {
+			if err := v.verifyBeacon(info, &b, false); err != nil {
+				v.log.Warn("verifying_client", "failed to verify value", "b", b, "err", err)
+				return []byte{}, fmt.Errorf("verifying beacon: %w", err)
+			}
 		}
 		trustPrevSig = next.Signature()
+		if v.progress != nil && (trustRound == round-1 || time.Since(lastProgress) >= verificationProgressInterval) {
+			v.progress(trustRound, round-1)
+			lastProgress = time.Now()
+		}
 	}
 	if trustRound == round-1 && trustRound > initialTrustRound {
 		v.potLk.Lock()
@@ -173,41 +228,132 @@ func (v *verifyingClient) getTrustedPreviousSignature(ctx context.Context, round
 	return trustPrevSig, nil
 }
 
+// verifyWithRefresh verifies a round and, if it fails, verifies it again with
+// freshly fetched chain info in case the cached one is outdated. It returns
+// the chain info to use from now on.
+func (v *verifyingClient) verifyWithRefresh(ctx context.Context, info *chain.Info, r *RandomData) (*chain.Info, error) {
+	err := v.verify(ctx, info, r)
+	if err == nil || v.infoCache == nil {
+		return info, err
+	}
+	v.infoCache.invalidate()
+	fresh, ierr := v.indirectClient.Info(ctx)
+	if ierr != nil || fresh.Equal(info) {
+		return info, err
+	}
+	v.log.Warn("verifying_client", "chain info changed, verifying again", "round", r.Round())
+	v.events.emit(Event{Type: EventChainInfoRefreshed, Source: fmt.Sprint(v.Client), Round: r.Round()})
+	return fresh, v.verify(ctx, fresh, r)
+}
+
 func (v *verifyingClient) verify(ctx context.Context, info *chain.Info, r *RandomData) (err error) {
+	v2 := r.Round() >= v.v2from
+	sig := r.Sig
+	if v2 {
+		sig = r.SigV2
+	}
+	// a signature known to be valid needs neither a chain walk nor a check.
+	if v.knownValid(info, r.Round(), v2, sig) {
+		r.Random, err = info.Randomness(sig)
+		return err
+	}
+
 	ps := r.PreviousSignature
-	if r.Round() < v.v2from && (v.strict || r.PreviousSignature == nil) {
+	if !v2 && (v.strict || r.PreviousSignature == nil) {
 		ps, err = v.getTrustedPreviousSignature(ctx, r.Round())
 		if err != nil {
 			return
 		}
 	}
 
-	ipk := info.PublicKey.Clone()
-	if r.Round() >= v.v2from {
+	if v2 {
 		b := chain.Beacon{
 			PreviousSig: ps,
 			Round:       r.Round(),
 			SignatureV2: r.SigV2,
 		}
 
-		if err := chain.VerifyBeaconV2(ipk, &b); err != nil {
+		if err := v.verifyBeacon(info, &b, true); err != nil {
 			return fmt.Errorf("verification v2 of %s failed: %w", b.String(), err)
 		}
-		r.Random = chain.RandomnessFromSignature(r.SigV2)
+		r.Random, err = info.Randomness(r.SigV2)
 	} else {
 		b := chain.Beacon{
 			PreviousSig: ps,
 			Round:       r.Round(),
 			Signature:   r.Signature(),
 		}
-		if err = chain.VerifyBeacon(ipk, &b); err != nil {
+		if err = v.verifyBeacon(info, &b, false); err != nil {
 			return fmt.Errorf("verification v1 of %s failed: %w", b.String(), err)
 		}
-		r.Random = chain.RandomnessFromSignature(r.Sig)
+		r.Random, err = info.Randomness(r.Sig)
+	}
+	return err
+}
+
+// knownValid reports whether the shared verification cache knows the
+// signature of the round is valid.
+func (v *verifyingClient) knownValid(info *chain.Info, round uint64, v2 bool, sig []byte) bool {
+	return v.verifyCache != nil && v.verifyCache.contains(info, round, v2, sig)
+}
+
+// verifyBeacon checks the v1 or v2 signature of a beacon, and records it in
+// the shared verification cache when valid.
+func (v *verifyingClient) verifyBeacon(info *chain.Info, b *chain.Beacon, v2 bool) error {
+	sig := b.Signature
+	if v2 {
+		sig = b.SignatureV2
+	}
+	vf, err := v.verifierFor(info)
+	if err != nil {
+		return err
+	}
+	if v2 {
+		err = vf.VerifyV2(b)
+	} else {
+		err = vf.VerifyV1(b)
+	}
+	if err != nil {
+		return &VerificationError{Round: b.Round, Err: err}
+	}
+	if v.verifyCache != nil {
+		v.verifyCache.add(info, b.Round, v2, sig)
 	}
 	return nil
 }
 
+// VerificationError is the error of a round whose signature does not verify
+// against the chain info, as opposed to the errors getting the round.
+type VerificationError struct {
+	Round uint64
+	Err   error
+}
+
+func (e *VerificationError) Error() string {
+	return fmt.Sprintf("invalid round %d: %v", e.Round, e.Err)
+}
+
+// Unwrap returns the error of the verification.
+func (e *VerificationError) Unwrap() error {
+	return e.Err
+}
+
+// verifierFor returns the verifier of the chain, created once per chain info
+// rather than for each beacon.
+func (v *verifyingClient) verifierFor(info *chain.Info) (*chain.Verifier, error) {
+	v.verifierLk.Lock()
+	defer v.verifierLk.Unlock()
+	if v.verifier != nil && (v.verifier.Info() == info || v.verifier.Info().Equal(info)) {
+		return v.verifier, nil
+	}
+	vf, err := chain.NewVerifier(info)
+	if err != nil {
+		return nil, err
+	}
+	v.verifier = vf
+	return vf, nil
+}
+
 // String returns the name of this client.
 func (v *verifyingClient) String() string {
 	return fmt.Sprintf("%s.(+verifier)", v.Client)
@@ -2,9 +2,11 @@ package client_test
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"testing"
 
+	"github.com/drand/drand/chain"
 	"github.com/drand/drand/client"
 	"github.com/drand/drand/client/test/result/mock"
 	"github.com/stretchr/testify/require"
@@ -78,3 +80,74 @@ func TestVerifyWithOldVerifiedResult(t *testing.T) {
 		t.Fatal("expected to get result.", results[4].Round(), res.Round(), fmt.Sprintf("%v", c))
 	}
 }
+
+func TestVerifyProgress(t *testing.T) {
+	info, results := mock.VerifiableResults(6, 1000000000)
+	mc := client.MockClient{Results: results, StrictRounds: true}
+	var reports [][2]uint64
+	c, err := client.Wrap(
+		[]client.Client{client.MockClientWithInfo(info), &mc},
+		client.WithChainInfo(info),
+		client.WithVerifiedResult(&results[0]),
+		client.WithFullChainVerification(),
+		client.WithV1VerificationUntil(1000000000),
+		client.WithVerificationProgress(func(current, target uint64) {
+			reports = append(reports, [2]uint64{current, target})
+		}),
+	)
+	require.NoError(t, err)
+	_, err = c.Get(context.Background(), results[5].Round())
+	require.NoError(t, err)
+	require.NotEmpty(t, reports)
+	last := reports[len(reports)-1]
+	require.Equal(t, [2]uint64{results[4].Round(), results[4].Round()}, last)
+}
+
+func TestVerifyFromCheckpoint(t *testing.T) {
+	info, results := mock.VerifiableResults(6, 1000000000)
+	cp, err := chain.NewCheckpoint(info, &chain.Beacon{
+		Round:       results[2].Rnd,
+		Signature:   results[2].Sig,
+		PreviousSig: results[2].PSig,
+	})
+	require.NoError(t, err)
+
+	// the rounds before the checkpoint are not available
+	mc := client.MockClient{Results: results[3:], StrictRounds: true}
+	c, err := client.Wrap(
+		[]client.Client{client.MockClientWithInfo(info), &mc},
+		client.WithChainInfo(info),
+		client.WithCheckpoint(cp),
+		client.WithFullChainVerification(),
+		client.WithV1VerificationUntil(1000000000),
+	)
+	require.NoError(t, err)
+	r, err := c.Get(context.Background(), results[5].Round())
+	require.NoError(t, err)
+	require.Equal(t, results[5].Round(), r.Round())
+
+	forged := *cp
+	forged.Round++
+	_, err = client.Wrap(
+		[]client.Client{client.MockClientWithInfo(info), &mc},
+		client.WithChainInfo(info),
+		client.WithCheckpoint(&forged),
+	)
+	require.Error(t, err)
+}
+
+func TestVerifyInvalidRound(t *testing.T) {
+	info, results := mock.VerifiableResults(3, 1000000000)
+	results[1].Sig = results[2].Sig
+	mc := client.MockClient{Results: results, StrictRounds: true}
+	c, err := client.Wrap(
+		[]client.Client{client.MockClientWithInfo(info), &mc},
+		client.WithChainInfo(info),
+		client.WithV1VerificationUntil(1000000000),
+	)
+	require.NoError(t, err)
+	_, err = c.Get(context.Background(), results[1].Round())
+	var verr *client.VerificationError
+	require.True(t, errors.As(err, &verr), "expected a verification error, got %v", err)
+	require.Equal(t, results[1].Round(), verr.Round)
+}
@@ -0,0 +1,73 @@
+package client
+
+import (
+	"bytes"
+	"errors"
+	"sync/atomic"
+
+	"github.com/drand/drand/chain"
+
+	lru "github.com/hashicorp/golang-lru"
+)
+
+// VerificationCache remembers the signatures of the rounds already verified,
+// so that clients of the same chain can share verification work. It is safe
+// for concurrent use by several clients, which may follow different chains.
+type VerificationCache struct {
+	// hits and misses are accessed atomically, and kept first for 64-bit
+	// alignment.
+	hits   uint64
+	misses uint64
+
+	cache *lru.Cache
+}
+
+type verificationKey struct {
+	chain string
+	round uint64
+	v2    bool
+}
+
+// NewVerificationCache creates a cache remembering the last `size` verified
+// rounds.
+func NewVerificationCache(size int) (*VerificationCache, error) {
+	if size <= 0 {
+		return nil, errors.New("verification cache size must be positive")
+	}
+	c, err := lru.New(size)
+	if err != nil {
+		return nil, err
+	}
+	return &VerificationCache{cache: c}, nil
+}
+
+// contains reports whether the signature of the round is known to be valid.
+func (vc *VerificationCache) contains(info *chain.Info, round uint64, v2 bool, sig []byte) bool {
+	v, ok := vc.cache.Get(verificationKey{string(info.Hash()), round, v2})
+	if ok && bytes.Equal(v.([]byte), sig) {
+		atomic.AddUint64(&vc.hits, 1)
+		return true
+	}
+	atomic.AddUint64(&vc.misses, 1)
+	return false
+}
+
+// add records the signature of the round as valid.
+func (vc *VerificationCache) add(info *chain.Info, round uint64, v2 bool, sig []byte) {
+	vc.cache.Add(verificationKey{string(info.Hash()), round, v2}, sig)
+}
+
+// Hits returns the number of verifications skipped thanks to the cache.
+func (vc *VerificationCache) Hits() uint64 {
+	return atomic.LoadUint64(&vc.hits)
+}
+
+// Misses returns the number of verifications the cache could not skip.
+func (vc *VerificationCache) Misses() uint64 {
+	return atomic.LoadUint64(&vc.misses)
+}
+
+// Len returns the number of rounds in the cache.
+func (vc *VerificationCache) Len() int {
+	return vc.cache.Len()
+}
@@ -0,0 +1,64 @@
+package client
+
+import (
+	"context"
+	"testing"
+
+	"github.com/drand/drand/client/test/result/mock"
+	"github.com/drand/drand/log"
+)
+
+func TestVerificationCacheShared(t *testing.T) {
+	info, results := mock.VerifiableResults(3, 0)
+	vc, err := NewVerificationCache(10)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	newClient := func() Client {
+		source := &infoMockClient{&MockClient{Results: results, StrictRounds: true}, info}
+		v := newVerifyingClient(source, nil, false, 0).(*verifyingClient)
+		v.verifyCache = vc
+		v.SetLog(log.DefaultLogger())
+		return v
+	}
+	c1, c2 := newClient(), newClient()
+
+	if _, err := c1.Get(context.Background(), 2); err != nil {
+		t.Fatal(err)
+	}
+	if vc.Hits() != 0 || vc.Len() != 1 {
+		t.Fatal("expected the first verification to fill the cache", vc.Hits(), vc.Len())
+	}
+	r, err := c2.Get(context.Background(), 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if vc.Hits() != 1 {
+		t.Fatal("expected the second client to hit the cache", vc.Hits())
+	}
+	if r.Round() != 2 {
+		t.Fatal("unexpected round", r.Round())
+	}
+}
+
+func TestVerificationCacheSignatureMismatch(t *testing.T) {
+	info, results := mock.VerifiableResults(2, 0)
+	vc, err := NewVerificationCache(10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	vc.add(info, 1, true, results[0].SigV2)
+	if !vc.contains(info, 1, true, results[0].SigV2) {
+		t.Fatal("expected the signature to be known")
+	}
+	if vc.contains(info, 1, true, results[1].SigV2) {
+		t.Fatal("another signature must not be considered verified")
+	}
+	if vc.contains(info, 1, false, results[0].SigV2) {
+		t.Fatal("v1 and v2 signatures must not be mixed")
+	}
+	if _, err := NewVerificationCache(0); err == nil {
+		t.Fatal("expected an error for an empty cache")
+	}
+}
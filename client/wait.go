@@ -0,0 +1,67 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+const (
+	// waitRetryMin is the first delay between the attempts to get a round
+	// that should have been produced already.
+	waitRetryMin = 100 * time.Millisecond
+	// waitRetryMax bounds the delay between these attempts.
+	waitRetryMax = 5 * time.Second
+)
+
+// ErrRoundAfterDeadline is returned when the round waited for is produced
+// after the deadline of the context.
+var ErrRoundAfterDeadline = errors.New("round is produced after the deadline")
+
+// WaitForRound blocks until `round` is produced according to the chain
+// parameters, then returns it from the client, retrying while the sources do
+// not serve it yet. The client is expected to verify results, as clients
+// created with `New` or `Wrap` do. It fails early with an error wrapping
+// ErrRoundAfterDeadline when the context deadline is before the round.
+func WaitForRound(ctx context.Context, c Client, round uint64) (Result, error) {
+	if round == 0 {
+		return nil, errors.New("round 0 is not produced")
+	}
+	info, err := c.Info(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("could not get chain info: %w", err)
+	}
+	produced := info.TimeOfRound(round)
+	if deadline, ok := ctx.Deadline(); ok && deadline.Before(produced) {
+		return nil, fmt.Errorf("%w: round %d at %s, deadline %s", ErrRoundAfterDeadline, round, produced, deadline)
+	}
+
+	if wait := time.Until(produced); wait > 0 {
+		t := time.NewTimer(wait)
+		select {
+		case <-t.C:
+		case <-ctx.Done():
+			t.Stop()
+			return nil, ctx.Err()
+		}
+	}
+
+	retry := waitRetryMin
+	for {
+		r, err := c.Get(ctx, round)
+		if err == nil {
+			return r, nil
+		}
+		t := time.NewTimer(retry)
+		select {
+		case <-t.C:
+		case <-ctx.Done():
+			t.Stop()
+			return nil, fmt.Errorf("waiting for round %d: %w (last error: %s)", round, ctx.Err(), err)
+		}
+		if retry *= 2; retry > waitRetryMax {
+			retry = waitRetryMax
+		}
+	}
+}
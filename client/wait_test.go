@@ -0,0 +1,52 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/drand/drand/chain"
+	"github.com/drand/drand/client/test/result/mock"
+)
+
+func TestWaitForRound(t *testing.T) {
+	info := fakeChainInfo()
+	info.GenesisTime = time.Now().Unix() - 10
+	next := chain.CurrentRound(time.Now().Unix(), info.Period, info.GenesisTime) + 1
+
+	mc := &MockClient{Results: []mock.Result{mock.NewMockResult(next)}, StrictRounds: true}
+	c := &infoMockClient{mc, info}
+	failing := &failingGetClient{infoMockClient: c, failures: 2}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	r, err := WaitForRound(ctx, failing, next)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectRound(t, r, next)
+	if failing.failures != 0 {
+		t.Fatal("expected retries")
+	}
+
+	ctx, cancel = context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if _, err := WaitForRound(ctx, c, next+100); !errors.Is(err, ErrRoundAfterDeadline) {
+		t.Fatal("expected the round to be after the deadline, got", err)
+	}
+}
+
+// failingGetClient fails the first calls to Get.
+type failingGetClient struct {
+	*infoMockClient
+	failures int
+}
+
+func (f *failingGetClient) Get(ctx context.Context, round uint64) (Result, error) {
+	if f.failures > 0 {
+		f.failures--
+		return nil, errors.New("not yet")
+	}
+	return f.infoMockClient.Get(ctx, round)
+}
@@ -0,0 +1,31 @@
+//go:build js && wasm
+// +build js,wasm
+
+/*
+Command wasm exposes the drand client to JavaScript, so that drand beacons can
+be fetched and verified in the browser.
+
+Build it with:
+
+	GOOS=js GOARCH=wasm go build -o drand.wasm ./client/wasm
+	cp "$(go env GOROOT)/misc/wasm/wasm_exec.js" .
+
+Once loaded with `wasm_exec.js`, it registers a global `drand` object:
+
+	drand.newClient(urls, chainHash)      Promise of a client for the HTTP relays
+	                                      at `urls`, serving the chain whose hex
+	                                      encoded hash is `chainHash`
+	client.get(round)                     Promise of the verified round, 0 being
+	                                      the latest
+	client.watch(callback)                calls `callback(beacon)` with every new
+	                                      verified round, and returns a function
+	                                      stopping the watch
+	client.info()                         Promise of the chain info
+	client.close()                        closes the client
+	drand.verify(chainInfo, beacon)       Promise resolved when the beacon, as
+	                                      served by a relay, verifies against the
+	                                      chain info, as served by a relay
+
+Requests go through the fetch API of the browser.
+*/
+package main
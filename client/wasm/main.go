@@ -0,0 +1,177 @@
+//go:build js && wasm
+// +build js,wasm
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"syscall/js"
+
+	"github.com/drand/drand/chain"
+	"github.com/drand/drand/client"
+	"github.com/drand/drand/client/http"
+
+	json "github.com/nikkolasg/hexjson"
+)
+
+func main() {
+	js.Global().Set("drand", js.ValueOf(map[string]interface{}{
+		"newClient": js.FuncOf(newClient),
+		"verify":    js.FuncOf(verify),
+	}))
+	// keep the functions available to JavaScript.
+	select {}
+}
+
+// promise runs `f` in its own goroutine, as blocking calls from a JavaScript
+// callback would deadlock, and returns a promise of its result.
+func promise(f func() (interface{}, error)) js.Value {
+	var executor js.Func
+	executor = js.FuncOf(func(this js.Value, args []js.Value) interface{} {
+		resolve, reject := args[0], args[1]
+		go func() {
+			defer executor.Release()
+			v, err := f()
+			if err != nil {
+				reject.Invoke(js.Global().Get("Error").New(err.Error()))
+				return
+			}
+			resolve.Invoke(v)
+		}()
+		return nil
+	})
+	return js.Global().Get("Promise").New(executor)
+}
+
+func newClient(this js.Value, args []js.Value) interface{} {
+	return promise(func() (interface{}, error) {
+		if len(args) != 2 {
+			return nil, errors.New("newClient expects the relay urls and the chain hash")
+		}
+		urls := make([]string, args[0].Length())
+		for i := range urls {
+			urls[i] = args[0].Index(i).String()
+		}
+		chainHash, err := hex.DecodeString(args[1].String())
+		if err != nil {
+			return nil, fmt.Errorf("invalid chain hash: %w", err)
+		}
+		c, err := client.New(
+			client.From(http.ForURLs(urls, chainHash)...),
+			client.WithChainHash(chainHash),
+		)
+		if err != nil {
+			return nil, err
+		}
+		return wrapClient(c), nil
+	})
+}
+
+// wrapClient returns the JavaScript object exposing a client.
+func wrapClient(c client.Client) js.Value {
+	return js.ValueOf(map[string]interface{}{
+		"get": js.FuncOf(func(this js.Value, args []js.Value) interface{} {
+			var round uint64
+			if len(args) > 0 {
+				round = uint64(args[0].Int())
+			}
+			return promise(func() (interface{}, error) {
+				r, err := c.Get(context.Background(), round)
+				if err != nil {
+					return nil, err
+				}
+				return resultToJS(r), nil
+			})
+		}),
+		"watch": js.FuncOf(func(this js.Value, args []js.Value) interface{} {
+			if len(args) != 1 || args[0].Type() != js.TypeFunction {
+				panic("watch expects a callback")
+			}
+			callback := args[0]
+			ctx, cancel := context.WithCancel(context.Background())
+			go func() {
+				for r := range c.Watch(ctx) {
+					callback.Invoke(resultToJS(r))
+				}
+			}()
+			var stop js.Func
+			stop = js.FuncOf(func(this js.Value, args []js.Value) interface{} {
+				cancel()
+				stop.Release()
+				return nil
+			})
+			return stop
+		}),
+		"info": js.FuncOf(func(this js.Value, args []js.Value) interface{} {
+			return promise(func() (interface{}, error) {
+				info, err := c.Info(context.Background())
+				if err != nil {
+					return nil, err
+				}
+				return toJSObject(info.ToJSON)
+			})
+		}),
+		"close": js.FuncOf(func(this js.Value, args []js.Value) interface{} {
+			if err := c.Close(); err != nil {
+				return js.Global().Get("Error").New(err.Error())
+			}
+			return nil
+		}),
+	})
+}
+
+// verify checks a beacon against chain info, both given as the JSON objects
+// served by a relay.
+func verify(this js.Value, args []js.Value) interface{} {
+	return promise(func() (interface{}, error) {
+		if len(args) != 2 {
+			return nil, errors.New("verify expects the chain info and the beacon")
+		}
+		stringify := js.Global().Get("JSON").Get("stringify")
+		info, err := chain.InfoFromJSON(strings.NewReader(stringify.Invoke(args[0]).String()))
+		if err != nil {
+			return nil, fmt.Errorf("invalid chain info: %w", err)
+		}
+		var rd client.RandomData
+		if err := json.Unmarshal([]byte(stringify.Invoke(args[1]).String()), &rd); err != nil {
+			return nil, fmt.Errorf("invalid beacon: %w", err)
+		}
+		b := chain.Beacon{
+			Round:       rd.Rnd,
+			Signature:   rd.Sig,
+			PreviousSig: rd.PreviousSignature,
+			SignatureV2: rd.SigV2,
+		}
+		if err := info.VerifyBeacon(&b); err != nil {
+			return nil, err
+		}
+		return true, nil
+	})
+}
+
+func resultToJS(r client.Result) js.Value {
+	obj := map[string]interface{}{
+		"round":      r.Round(),
+		"randomness": hex.EncodeToString(r.Randomness()),
+		"signature":  hex.EncodeToString(r.Signature()),
+	}
+	if rd, ok := r.(*client.RandomData); ok && len(rd.PreviousSignature) > 0 {
+		obj["previous_signature"] = hex.EncodeToString(rd.PreviousSignature)
+	}
+	return js.ValueOf(obj)
+}
+
+// toJSObject parses the JSON written by `write` into a JavaScript object.
+func toJSObject(write func(w io.Writer) error) (interface{}, error) {
+	var buff bytes.Buffer
+	if err := write(&buff); err != nil {
+		return nil, err
+	}
+	return js.Global().Get("JSON").Call("parse", buff.String()), nil
+}
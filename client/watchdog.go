@@ -0,0 +1,127 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/drand/drand/log"
+)
+
+// WatchGap describes rounds a `Watch` did not deliver because its upstream
+// subscription stalled, so that they can be backfilled with `Get`.
+type WatchGap struct {
+	// From is the first missed round.
+	From uint64
+	// To is the last missed round.
+	To uint64
+}
+
+// WithWatchdog restarts the upstream subscription of `Watch` when no round
+// is received for `periods` periods of the chain. The rounds skipped while the
+// subscription was stalled are reported to `onGap`, which may be nil, once it
+// delivers rounds again.
+func WithWatchdog(periods int, onGap func(WatchGap)) Option {
+	return func(cfg *clientConfig) error {
+		if periods <= 0 {
+			return errors.New("watchdog periods must be positive")
+		}
+		cfg.watchdogPeriods = periods
+		cfg.onWatchGap = onGap
+		return nil
+	}
+}
+
+// newWatchdogClient wraps a client to restart its watches when they stall.
+func newWatchdogClient(c Client, periods int, onGap func(WatchGap)) *watchdogClient {
+	return &watchdogClient{
+		Client:  c,
+		periods: periods,
+		onGap:   onGap,
+		log:     log.DefaultLogger(),
+	}
+}
+
+type watchdogClient struct {
+	Client
+	periods int
+	onGap   func(WatchGap)
+	log     log.Logger
+	// events, when set, is told when a watch is restarted.
+	events *eventBus
+}
+
+// SetLog configures the client log output.
+func (c *watchdogClient) SetLog(l log.Logger) {
+	c.log = l
+	trySetLog(c.Client, l)
+}
+
+// String returns the name of this client.
+func (c *watchdogClient) String() string {
+	return fmt.Sprintf("%s.(+watchdog)", c.Client)
+}
+
+// Watch returns new randomness as it becomes available, re-subscribing to
+// the wrapped client when it stays silent for too long.
+func (c *watchdogClient) Watch(ctx context.Context) <-chan Result {
+	info, err := c.Info(ctx)
+	if err != nil {
+		c.log.Warn("watchdog", "could not get chain info, watching without watchdog", "err", err)
+		return c.Client.Watch(ctx)
+	}
+	timeout := time.Duration(c.periods) * info.Period
+
+	out := make(chan Result)
+	go func() {
+		defer close(out)
+		var last uint64
+		stalled := false
+		for {
+			wctx, cancel := context.WithCancel(ctx)
+			in := c.Client.Watch(wctx)
+			t := time.NewTimer(timeout)
+		LOOP:
+			for {
+				select {
+				case r, ok := <-in:
+					if !ok {
+						t.Stop()
+						cancel()
+						return
+					}
+					if !t.Stop() {
+						<-t.C
+					}
+					t.Reset(timeout)
+					if stalled && r.Round() > last+1 && last > 0 && c.onGap != nil {
+						c.onGap(WatchGap{From: last + 1, To: r.Round() - 1})
+					}
+					stalled = false
+					if r.Round() > last {
+						last = r.Round()
+					}
+					select {
+					case out <- r:
+					case <-ctx.Done():
+						t.Stop()
+						cancel()
+						return
+					}
+				case <-t.C:
+					c.log.Warn("watchdog", "no round received, restarting watch", "last_round", last, "timeout", timeout)
+					c.events.emit(Event{Type: EventWatchReconnected, Round: last, Err: errors.New("watch stalled")})
+					stalled = true
+					break LOOP
+				case <-ctx.Done():
+					t.Stop()
+					cancel()
+					return
+				}
+			}
+			cancel()
+		}
+	}()
+	return out
+}
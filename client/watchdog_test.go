@@ -0,0 +1,61 @@
+package client
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/drand/drand/client/test/result/mock"
+)
+
+func TestWatchdogRestartsStalledWatch(t *testing.T) {
+	info := fakeChainInfo()
+	info.Period = 50 * time.Millisecond
+
+	var subscriptions int32
+	mc := new(MockClient)
+	mc.WatchF = func(ctx context.Context) <-chan Result {
+		ch := make(chan Result, 1)
+		switch atomic.AddInt32(&subscriptions, 1) {
+		case 1:
+			// delivers a round, then stalls
+			r := mock.NewMockResult(1)
+			ch <- &r
+		case 2:
+			r := mock.NewMockResult(4)
+			ch <- &r
+		default:
+			close(ch)
+		}
+		return ch
+	}
+
+	gaps := make(chan WatchGap, 1)
+	c := newWatchdogClient(&infoMockClient{mc, info}, 2, func(g WatchGap) {
+		gaps <- g
+	})
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	results := c.Watch(ctx)
+
+	expectRound(t, nextResult(t, results), 1)
+	expectRound(t, nextResult(t, results), 4)
+	select {
+	case g := <-gaps:
+		if g.From != 2 || g.To != 3 {
+			t.Fatal("unexpected gap", g)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("no gap reported")
+	}
+	if n := atomic.LoadInt32(&subscriptions); n != 2 {
+		t.Fatal("expected 2 subscriptions, got", n)
+	}
+}
+
+func TestWatchdogOption(t *testing.T) {
+	if _, err := New(From(MockClientWithResults(1, 5)), Insecurely(), WithWatchdog(0, nil)); err == nil {
+		t.Fatal("watchdog periods must be positive")
+	}
+}
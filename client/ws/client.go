@@ -0,0 +1,149 @@
+package ws
+
+import (
+	"context"
+	"fmt"
+	nhttp "net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/drand/drand/client"
+	"github.com/drand/drand/client/http"
+	"github.com/drand/drand/log"
+	"github.com/gorilla/websocket"
+
+	json "github.com/nikkolasg/hexjson"
+)
+
+type wsClient struct {
+	// Client is the HTTP client of the relay, used for everything but Watch.
+	client.Client
+
+	url       string
+	dialer    *websocket.Dialer
+	l         log.Logger
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+// New creates a drand client for the relay at `url`, streaming new rounds
+// over a WebSocket. The chain hash, if given, is checked against the chain
+// information advertised by the relay.
+func New(url string, chainHash []byte, transport nhttp.RoundTripper) (client.Client, error) {
+	wsURL, err := streamURL(url)
+	if err != nil {
+		return nil, err
+	}
+	hc, err := http.New(url, chainHash, transport)
+	if err != nil {
+		return nil, err
+	}
+	return &wsClient{
+		Client: hc,
+		url:    wsURL,
+		dialer: websocket.DefaultDialer,
+		l:      log.DefaultLogger(),
+		done:   make(chan struct{}),
+	}, nil
+}
+
+// streamURL derives the websocket endpoint from the base URL of a relay.
+func streamURL(base string) (string, error) {
+	u, err := url.Parse(base)
+	if err != nil {
+		return "", err
+	}
+	switch u.Scheme {
+	case "http", "ws":
+		u.Scheme = "ws"
+	case "https", "wss":
+		u.Scheme = "wss"
+	default:
+		return "", fmt.Errorf("unsupported scheme %q", u.Scheme)
+	}
+	if !strings.HasSuffix(u.Path, "/") {
+		u.Path += "/"
+	}
+	u.Path += "ws"
+	return u.String(), nil
+}
+
+// String returns the name of this client.
+func (w *wsClient) String() string {
+	return fmt.Sprintf("WS(%q)", w.url)
+}
+
+// SetLog configures the client log output.
+func (w *wsClient) SetLog(l log.Logger) {
+	w.l = l
+	if lc, ok := w.Client.(client.LoggingClient); ok {
+		lc.SetLog(l)
+	}
+}
+
+// Watch returns new randomness as it becomes available. The stream is
+// re-established whenever the connection is lost, resuming after the last
+// delivered round.
+func (w *wsClient) Watch(ctx context.Context) <-chan client.Result {
+	return client.WatchReconnecting(ctx, w.done, w.stream, func(last uint64, backoff time.Duration, err error) {
+		w.l.Warn("ws_client", "stream interrupted", "url", w.url, "last", last, "retry", backoff, "err", err)
+	})
+}
+
+// stream connects to the relay and forwards rounds after `last` to out until
+// the connection fails. It returns the last round delivered.
+func (w *wsClient) stream(ctx context.Context, last uint64, out chan<- client.Result) (uint64, error) {
+	u := w.url
+	if last > 0 {
+		u += "?from=" + strconv.FormatUint(last+1, 10)
+	}
+	conn, _, err := w.dialer.DialContext(ctx, u, nil)
+	if err != nil {
+		return last, err
+	}
+	defer conn.Close()
+
+	// unblock reads once the watch is no longer wanted.
+	stop := make(chan struct{})
+	defer close(stop)
+	go func() {
+		select {
+		case <-ctx.Done():
+		case <-w.done:
+		case <-stop:
+			return
+		}
+		_ = conn.Close()
+	}()
+
+	for {
+		_, msg, err := conn.ReadMessage()
+		if err != nil {
+			return last, err
+		}
+		rd := new(client.RandomData)
+		if err := json.Unmarshal(msg, rd); err != nil {
+			return last, fmt.Errorf("decoding round: %w", err)
+		}
+		if rd.Round() <= last {
+			continue
+		}
+		select {
+		case out <- rd:
+			last = rd.Round()
+		case <-ctx.Done():
+			return last, ctx.Err()
+		case <-w.done:
+			return last, nil
+		}
+	}
+}
+
+// Close stops all streams and the underlying HTTP client.
+func (w *wsClient) Close() error {
+	w.closeOnce.Do(func() { close(w.done) })
+	return w.Client.Close()
+}
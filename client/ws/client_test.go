@@ -0,0 +1,65 @@
+package ws
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/drand/drand/client/test/http/mock"
+)
+
+func TestStreamURL(t *testing.T) {
+	for in, expected := range map[string]string{
+		"http://example.com":         "ws://example.com/ws",
+		"https://example.com/relay/": "wss://example.com/relay/ws",
+	} {
+		u, err := streamURL(in)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if u != expected {
+			t.Fatalf("expected %s, got %s", expected, u)
+		}
+	}
+	if _, err := streamURL("ftp://example.com"); err == nil {
+		t.Fatal("unsupported scheme should fail")
+	}
+}
+
+func TestWSWatch(t *testing.T) {
+	addr, chainInfo, cancel, emit := mock.NewMockHTTPPublicServer(t, false)
+	defer cancel()
+
+	c, err := New("http://"+addr, chainInfo.Hash(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	ctx, cancelWatch := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancelWatch()
+	results := c.Watch(ctx)
+
+	var last uint64
+	for i := 0; i < 3; i++ {
+		// give the stream time to be subscribed before emitting.
+		time.Sleep(200 * time.Millisecond)
+		emit(false)
+		select {
+		case r, ok := <-results:
+			if !ok {
+				t.Fatal("watch closed early")
+			}
+			if r.Round() <= last {
+				t.Fatal("expected increasing rounds", last, r.Round())
+			}
+			last = r.Round()
+		case <-ctx.Done():
+			t.Fatal("timed out waiting for round")
+		}
+	}
+
+	_ = c.Close()
+	for range results {
+	}
+}
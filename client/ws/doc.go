@@ -0,0 +1,36 @@
+/*
+Package ws provides a drand client implementation that streams new rounds of
+randomness from a drand HTTP relay over a WebSocket.
+
+Chain information and individual rounds are fetched with the relay's HTTP API,
+while "Watch" keeps a WebSocket open to the relay's "/ws" endpoint. When the
+connection drops, the client reconnects with an increasing backoff and resumes
+the stream from the round after the last one it delivered, so no round is
+missed or emitted twice.
+
+Example:
+
+	package main
+
+	import (
+		"encoding/hex"
+
+		"github.com/drand/drand/client"
+		"github.com/drand/drand/client/ws"
+	)
+
+	var chainHash, _ = hex.DecodeString("8990e7a9aaed2ffed73dbd7092123d6f289930540d7651336225dc172e51b2ce")
+
+	func main() {
+		wc, err := ws.New("https://api.drand.sh", chainHash, nil)
+
+		c, err := client.New(
+			client.From(wc),
+			client.WithChainHash(chainHash),
+		)
+	}
+
+The WebSocket client is useful where gRPC is not available and polling the
+HTTP API on each round is undesirable.
+*/
+package ws
@@ -0,0 +1,179 @@
+package main
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/drand/drand/chain"
+	"github.com/drand/drand/client"
+	"github.com/drand/drand/cmd/client/lib"
+	json "github.com/nikkolasg/hexjson"
+	"github.com/urfave/cli/v2"
+)
+
+// The exit codes of the commands getting rounds, besides 0 on success.
+const (
+	// exitError is the exit code of the errors getting a round.
+	exitError = 1
+	// exitNotAvailable is the exit code of a round not produced yet.
+	exitNotAvailable = 2
+	// exitInvalid is the exit code of a round failing its verification.
+	exitInvalid = 3
+)
+
+// errNotAvailable is the error of a round not produced yet.
+var errNotAvailable = errors.New("round not available")
+
+var atFlag = &cli.StringFlag{
+	Name:  "at",
+	Usage: "get the round of the chain at the given time, as RFC 3339 or UNIX seconds",
+}
+
+var formatFlag = &cli.StringFlag{
+	Name:  "format",
+	Usage: "output format of the round: json, or its randomness as hex, raw or base64",
+	Value: "json",
+}
+
+var getCmd = &cli.Command{
+	Name: "get",
+	Usage: "get a round, the round at a time or the latest round, verified against the chain info. " +
+		"Exits with 2 when the round is not produced yet and with 3 when it fails its verification.",
+	Flags:  append(lib.ClientFlags, roundFlag, atFlag, formatFlag, verboseFlag),
+	Action: getRound,
+}
+
+func getRound(c *cli.Context) error {
+	setLogger(c)
+	if c.IsSet(roundFlag.Name) && c.IsSet(atFlag.Name) {
+		return cli.Exit("only one of --round and --at can be given", exitError)
+	}
+	format := c.String(formatFlag.Name)
+	if err := checkFormat(format); err != nil {
+		return cli.Exit(err, exitError)
+	}
+
+	apiClient, err := lib.Create(c, false)
+	if err != nil {
+		return cli.Exit(err, exitError)
+	}
+	defer apiClient.Close()
+	info, err := apiClient.Info(c.Context)
+	if err != nil {
+		return cli.Exit(fmt.Errorf("getting chain info: %w", err), exitError)
+	}
+
+	round, err := selectRound(c, info, time.Now())
+	if errors.Is(err, errNotAvailable) {
+		return cli.Exit(err, exitNotAvailable)
+	}
+	if err != nil {
+		return cli.Exit(err, exitError)
+	}
+	rand, err := apiClient.Get(c.Context, round)
+	var verr *client.VerificationError
+	if errors.As(err, &verr) {
+		return cli.Exit(err, exitInvalid)
+	}
+	if err != nil {
+		return cli.Exit(err, exitError)
+	}
+	if _, err := formatResult(os.Stdout, format, info, rand); err != nil {
+		return cli.Exit(err, exitError)
+	}
+	return nil
+}
+
+// selectRound returns the round given on the command line, or the round of
+// the chain at the time given, or 0 for the latest round. It errors when the
+// round is not produced by now.
+func selectRound(c *cli.Context, info *chain.Info, now time.Time) (uint64, error) {
+	var round uint64
+	switch {
+	case c.IsSet(roundFlag.Name):
+		round = uint64(c.Int(roundFlag.Name))
+	case c.IsSet(atFlag.Name):
+		at, err := parseTime(c.String(atFlag.Name))
+		if err != nil {
+			return 0, err
+		}
+		if at.Unix() < info.GenesisTime {
+			return 0, fmt.Errorf("%s is before the genesis of the chain at %s",
+				at.Format(time.RFC3339), time.Unix(info.GenesisTime, 0).Format(time.RFC3339))
+		}
+		round = info.RoundAt(at)
+	default:
+		return 0, nil
+	}
+	if current := info.RoundAt(now); round > current {
+		return 0, fmt.Errorf("%w: round %d is produced at %s", errNotAvailable, round,
+			info.TimeOfRound(round).Format(time.RFC3339))
+	}
+	return round, nil
+}
+
+// parseTime parses a time given as RFC 3339 or as UNIX seconds.
+func parseTime(s string) (time.Time, error) {
+	if secs, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return time.Unix(secs, 0), nil
+	}
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid time %q, expected RFC 3339 or UNIX seconds", s)
+	}
+	return t, nil
+}
+
+// checkFormat checks the output format is one formatResult knows.
+func checkFormat(format string) error {
+	switch format {
+	case "json", "hex", "raw", "base64":
+		return nil
+	default:
+		return fmt.Errorf("unknown format %q, expected json, hex, raw or base64", format)
+	}
+}
+
+// formatResult writes the result of the chain in the given format: the round
+// as JSON, or its randomness as hex, raw bytes or base64. The randomness is
+// derived from the signature as specified by the chain info.
+func formatResult(w io.Writer, format string, info *chain.Info, r client.Result) (int, error) {
+	if err := checkFormat(format); err != nil {
+		return 0, err
+	}
+	random, err := info.Randomness(r.Signature())
+	if err != nil {
+		return 0, err
+	}
+	switch format {
+	case "json":
+		rd := *randomData(r)
+		rd.Random = random
+		b, err := json.Marshal(&rd)
+		if err != nil {
+			return 0, err
+		}
+		return fmt.Fprintf(w, "%s\n", b)
+	case "hex":
+		return fmt.Fprintln(w, hex.EncodeToString(random))
+	case "raw":
+		return w.Write(random)
+	default:
+		return fmt.Fprintln(w, base64.StdEncoding.EncodeToString(random))
+	}
+}
+
+// randomData returns the result as random data, with the fields the result
+// has only when it is not.
+func randomData(r client.Result) *client.RandomData {
+	if rd, ok := r.(*client.RandomData); ok {
+		return rd
+	}
+	return &client.RandomData{Rnd: r.Round(), Random: r.Randomness(), Sig: r.Signature()}
+}
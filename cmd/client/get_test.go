@@ -0,0 +1,83 @@
+package main
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"flag"
+	"testing"
+	"time"
+
+	"github.com/drand/drand/chain"
+	"github.com/drand/drand/client"
+	"github.com/urfave/cli/v2"
+	"golang.org/x/crypto/sha3"
+)
+
+func getContext(t *testing.T, args ...string) *cli.Context {
+	set := flag.NewFlagSet("get", flag.ContinueOnError)
+	for _, f := range getCmd.Flags {
+		if err := f.Apply(set); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := set.Parse(args); err != nil {
+		t.Fatal(err)
+	}
+	return cli.NewContext(nil, set, nil)
+}
+
+func TestSelectRound(t *testing.T) {
+	now := time.Now()
+	info := &chain.Info{Period: 30 * time.Second, GenesisTime: now.Unix() - 300}
+
+	for args, expected := range map[string]uint64{
+		"":          0,
+		"--round=5": 5,
+		"--at=" + now.Add(-100*time.Second).UTC().Format(time.RFC3339): 7,
+	} {
+		var flags []string
+		if args != "" {
+			flags = []string{args}
+		}
+		round, err := selectRound(getContext(t, flags...), info, now)
+		if err != nil {
+			t.Fatal(args, err)
+		}
+		if round != expected {
+			t.Fatalf("%s: expected round %d, got %d", args, expected, round)
+		}
+	}
+
+	if _, err := selectRound(getContext(t, "--round=100"), info, now); !errors.Is(err, errNotAvailable) {
+		t.Fatal("expected round 100 not to be available", err)
+	}
+	if _, err := selectRound(getContext(t, "--at=1"), info, now); err == nil || errors.Is(err, errNotAvailable) {
+		t.Fatal("expected an error for a time before the genesis", err)
+	}
+}
+
+func TestFormatResult(t *testing.T) {
+	// the randomness follows the derivation of the chain
+	info := &chain.Info{Derivation: chain.SHA3DerivationID}
+	r := &client.RandomData{Rnd: 1, Random: []byte{0xde, 0xad}, Sig: []byte{0x01}}
+	random := sha3.Sum256(r.Sig)
+	for format, expected := range map[string]string{
+		"json":   `{"round":1,"randomness":"` + hex.EncodeToString(random[:]) + `","signature":"01"}` + "\n",
+		"hex":    hex.EncodeToString(random[:]) + "\n",
+		"raw":    string(random[:]),
+		"base64": base64.StdEncoding.EncodeToString(random[:]) + "\n",
+	} {
+		var b bytes.Buffer
+		if _, err := formatResult(&b, format, info, r); err != nil {
+			t.Fatal(format, err)
+		}
+		if b.String() != expected {
+			t.Fatalf("%s: expected %q, got %q", format, expected, b.String())
+		}
+	}
+	if _, err := formatResult(&bytes.Buffer{}, "yaml", info, r); err == nil {
+		t.Fatal("expected an error for an unknown format")
+	}
+}
@@ -8,6 +8,7 @@ import (
 	"io/ioutil"
 	"net"
 	nhttp "net/http"
+	"net/url"
 	"os"
 	"path"
 	"strings"
@@ -78,6 +79,24 @@ var (
 		Name:  "port",
 		Usage: "Local (host:)port for constructed libp2p host to listen on",
 	}
+	// NATPortMapFlag is the CLI flag mapping a port of the router to the
+	// libp2p host with UPnP or NAT-PMP.
+	NATPortMapFlag = &cli.BoolFlag{
+		Name:  "nat-portmap",
+		Usage: "Map a port of the router to the libp2p host with UPnP or NAT-PMP",
+	}
+	// CircuitRelayFlag is the CLI flag for the circuit relay multiaddr(s) the
+	// libp2p host announces when it is behind a NAT.
+	CircuitRelayFlag = &cli.StringSliceFlag{
+		Name:  "circuit-relay",
+		Usage: "circuit relay multiaddr(s) through which the peers reach the libp2p host when it is behind a NAT",
+	}
+	// ProxyFlag is the CLI flag for the URL of the proxy HTTP and gRPC
+	// sources are reached through.
+	ProxyFlag = &cli.StringFlag{
+		Name:  "proxy",
+		Usage: "URL of a socks5:// or http:// proxy to reach HTTP and gRPC sources through",
+	}
 )
 
 // ClientFlags is a list of common flags for client creation
@@ -90,6 +109,9 @@ var ClientFlags = []cli.Flag{
 	InsecureFlag,
 	RelayFlag,
 	PortFlag,
+	NATPortMapFlag,
+	CircuitRelayFlag,
+	ProxyFlag,
 }
 
 // Create builds a client, and can be invoked from a cli action supplied
@@ -110,7 +132,12 @@ func Create(c *cli.Context, withInstrumentation bool, opts ...client.Option) (cl
 		opts = append(opts, client.WithChainInfo(info))
 	}
 
-	gc, err := buildGrpcClient(c, &info)
+	proxyURL, err := proxyFromFlag(c)
+	if err != nil {
+		return nil, err
+	}
+
+	gc, err := buildGrpcClient(c, &info, proxyURL)
 	if err != nil {
 		return nil, err
 	}
@@ -135,7 +162,7 @@ func Create(c *cli.Context, withInstrumentation bool, opts ...client.Option) (cl
 		opts = append(opts, client.Insecurely())
 	}
 
-	clients = append(clients, buildHTTPClients(c, &info, hash, withInstrumentation)...)
+	clients = append(clients, buildHTTPClients(c, &info, hash, proxyURL, withInstrumentation)...)
 
 	gopt, err := buildGossipClient(c)
 	if err != nil {
@@ -146,9 +173,25 @@ func Create(c *cli.Context, withInstrumentation bool, opts ...client.Option) (cl
 	return client.Wrap(clients, opts...)
 }
 
-func buildGrpcClient(c *cli.Context, info **chain.Info) ([]client.Client, error) {
+// proxyFromFlag parses the proxy URL given on the command line, if any.
+func proxyFromFlag(c *cli.Context) (*url.URL, error) {
+	if !c.IsSet(ProxyFlag.Name) {
+		return nil, nil
+	}
+	u, err := url.Parse(c.String(ProxyFlag.Name))
+	if err != nil {
+		return nil, fmt.Errorf("invalid proxy URL: %w", err)
+	}
+	return u, nil
+}
+
+func buildGrpcClient(c *cli.Context, info **chain.Info, proxyURL *url.URL) ([]client.Client, error) {
 	if c.IsSet(GRPCConnectFlag.Name) {
-		gc, err := grpc.New(c.String(GRPCConnectFlag.Name), c.String(CertFlag.Name), c.Bool(InsecureFlag.Name))
+		var opts []grpc.Option
+		if proxyURL != nil {
+			opts = append(opts, grpc.WithProxy(proxyURL))
+		}
+		gc, err := grpc.New(c.String(GRPCConnectFlag.Name), c.String(CertFlag.Name), c.Bool(InsecureFlag.Name), opts...)
 		if err != nil {
 			return nil, err
 		}
@@ -163,20 +206,24 @@ func buildGrpcClient(c *cli.Context, info **chain.Info) ([]client.Client, error)
 	return []client.Client{}, nil
 }
 
-func buildHTTPClients(c *cli.Context, info **chain.Info, hash []byte, withInstrumentation bool) []client.Client {
+func buildHTTPClients(c *cli.Context, info **chain.Info, hash []byte, proxyURL *url.URL, withInstrumentation bool) []client.Client {
 	clients := make([]client.Client, 0)
+	transport := nhttp.DefaultTransport
+	if proxyURL != nil {
+		transport = http.NewTransport(http.TransportOptions{Proxy: proxyURL})
+	}
 	var err error
 	skipped := []string{}
 	var hc client.Client
 	for _, url := range c.StringSlice(URLFlag.Name) {
 		if *info != nil {
-			hc, err = http.NewWithInfo(url, *info, nhttp.DefaultTransport)
+			hc, err = http.NewWithInfo(url, *info, transport)
 			if err != nil {
 				log.DefaultLogger().Warn("client", "failed to load URL", "url", url, "err", err)
 				continue
 			}
 		} else {
-			hc, err = http.New(url, hash, nhttp.DefaultTransport)
+			hc, err = http.New(url, hash, transport)
 			if err != nil {
 				log.DefaultLogger().Warn("client", "failed to load URL", "url", url, "err", err)
 				skipped = append(skipped, url)
@@ -192,7 +239,7 @@ func buildHTTPClients(c *cli.Context, info **chain.Info, hash []byte, withInstru
 	}
 	if *info != nil {
 		for _, url := range skipped {
-			hc, err = http.NewWithInfo(url, *info, nhttp.DefaultTransport)
+			hc, err = http.NewWithInfo(url, *info, transport)
 			if err != nil {
 				log.DefaultLogger().Warn("client", "failed to load URL", "url", url, "err", err)
 				continue
@@ -208,6 +255,50 @@ func buildHTTPClients(c *cli.Context, info **chain.Info, hash []byte, withInstru
 	return clients
 }
 
+// ChainClients creates the clients of the chains given as <hash>=<url>, each
+// following its chain from all the URLs given for its hash, with the given
+// options.
+func ChainClients(chains []string, opts ...client.Option) ([]client.Client, error) {
+	var hashes []string
+	urls := make(map[string][]string)
+	for _, chain := range chains {
+		parts := strings.SplitN(chain, "=", 2)
+		if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+			return nil, fmt.Errorf("invalid chain %q, expected <hash>=<url>", chain)
+		}
+		if _, ok := urls[parts[0]]; !ok {
+			hashes = append(hashes, parts[0])
+		}
+		urls[parts[0]] = append(urls[parts[0]], parts[1])
+	}
+
+	clients := make([]client.Client, 0, len(hashes))
+	for _, h := range hashes {
+		hash, err := hex.DecodeString(h)
+		if err != nil {
+			return nil, fmt.Errorf("invalid chain hash %q: %w", h, err)
+		}
+		var sources []client.Client
+		for _, u := range urls[h] {
+			hc, err := http.New(u, hash, nhttp.DefaultTransport)
+			if err != nil {
+				log.DefaultLogger().Warn("client", "failed to load URL", "url", u, "chain", h, "err", err)
+				continue
+			}
+			sources = append(sources, hc)
+		}
+		if len(sources) == 0 {
+			return nil, fmt.Errorf("no URL of chain %s could be loaded", h)
+		}
+		cl, err := client.Wrap(sources, append([]client.Option{client.WithChainHash(hash)}, opts...)...)
+		if err != nil {
+			return nil, fmt.Errorf("chain %s: %w", h, err)
+		}
+		clients = append(clients, cl)
+	}
+	return clients, nil
+}
+
 func buildGossipClient(c *cli.Context) ([]client.Option, error) {
 	if c.IsSet(RelayFlag.Name) {
 		addrs := c.StringSlice(RelayFlag.Name)
@@ -220,17 +311,36 @@ func buildGossipClient(c *cli.Context) ([]client.Option, error) {
 			if c.IsSet(PortFlag.Name) {
 				listen = c.String(PortFlag.Name)
 			}
-			ps, err := buildClientHost(listen, relayPeers)
+			nat, err := NATConfig(c)
 			if err != nil {
 				return nil, err
 			}
-			return []client.Option{gclient.WithPubsub(ps)}, nil
+			scores := gclient.NewPeerScores()
+			ps, err := buildClientHost(listen, relayPeers, nat, scores.PubsubOptions()...)
+			if err != nil {
+				return nil, err
+			}
+			return []client.Option{gclient.WithPubsub(ps, gclient.WithPeerScores(scores))}, nil
 		}
 	}
 	return []client.Option{}, nil
 }
 
-func buildClientHost(clientListenAddr string, relayMultiaddr []ma.Multiaddr) (*pubsub.PubSub, error) {
+// NATConfig returns the traversal of the NATs configured by the NAT flags of
+// ClientFlags.
+func NATConfig(c *cli.Context) (*lp2p.NATConfig, error) {
+	relays, err := lp2p.ParseMultiaddrSlice(c.StringSlice(CircuitRelayFlag.Name))
+	if err != nil {
+		return nil, err
+	}
+	return &lp2p.NATConfig{
+		PortMap: c.Bool(NATPortMapFlag.Name),
+		Relays:  relays,
+	}, nil
+}
+
+func buildClientHost(clientListenAddr string, relayMultiaddr []ma.Multiaddr, nat *lp2p.NATConfig,
+	psOpts ...pubsub.Option) (*pubsub.PubSub, error) {
 	clientID := uuid.New().String()
 	ds, err := bds.NewDatastore(path.Join(os.TempDir(), "drand-"+clientID+"-datastore"), nil)
 	if err != nil {
@@ -260,7 +370,9 @@ func buildClientHost(clientListenAddr string, relayMultiaddr []ma.Multiaddr) (*p
 		priv,
 		listen,
 		relayMultiaddr,
+		nat,
 		log.DefaultLogger(),
+		psOpts...,
 	)
 	if err != nil {
 		return nil, err
@@ -75,6 +75,7 @@ func main() {
 		clientMetricsAddressFlag, clientMetricsGatewayFlag, clientMetricsIDFlag,
 		clientMetricsPushIntervalFlag, verboseFlag)
 	app.Action = Client
+	app.Commands = []*cli.Command{getCmd, verifyCmd, watchCmd}
 	cli.VersionPrinter = func(c *cli.Context) {
 		fmt.Printf("drand client %v (date %v, commit %v)\n", version, buildDate, gitCommit)
 	}
@@ -87,13 +88,7 @@ func main() {
 
 // Client loads randomness from a server
 func Client(c *cli.Context) error {
-	// configure logging
-	_ = log.DefaultLogger()
-	if c.Bool(verboseFlag.Name) {
-		log.SetDefaultLogger(log.LoggerTo(os.Stderr), log.LogDebug)
-	} else {
-		log.SetDefaultLogger(log.LoggerTo(os.Stderr), log.LogInfo)
-	}
+	setLogger(c)
 
 	opts := []client.Option{}
 
@@ -133,6 +128,17 @@ func Client(c *cli.Context) error {
 	return nil
 }
 
+// setLogger configures the logging on stderr, at the debug level when
+// verbose.
+func setLogger(c *cli.Context) {
+	_ = log.DefaultLogger()
+	if c.Bool(verboseFlag.Name) {
+		log.SetDefaultLogger(log.LoggerTo(os.Stderr), log.LogDebug)
+	} else {
+		log.SetDefaultLogger(log.LoggerTo(os.Stderr), log.LogInfo)
+	}
+}
+
 // Watch streams randomness from a client
 func Watch(inst client.Watcher) error {
 	results := inst.Watch(context.Background())
@@ -0,0 +1,235 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	nhttp "net/http"
+	"os"
+	"strings"
+
+	"github.com/drand/drand/chain"
+	"github.com/drand/drand/client"
+	"github.com/drand/drand/client/http"
+	"github.com/drand/drand/cmd/client/lib"
+	json "github.com/nikkolasg/hexjson"
+	"github.com/urfave/cli/v2"
+)
+
+var chainInfoFlag = &cli.StringFlag{
+	Name:     "chain-info",
+	Usage:    "path or URL of the chain info (JSON encoded) the rounds are verified against",
+	Required: true,
+}
+
+var fromFlag = &cli.IntFlag{
+	Name:  "from",
+	Usage: "first round to fetch from the --url relay",
+}
+
+var toFlag = &cli.IntFlag{
+	Name:  "to",
+	Usage: "last round to fetch from the --url relay, the first one by default",
+}
+
+var verifyCmd = &cli.Command{
+	Name: "verify",
+	Usage: "verify rounds against the chain info, offline. The rounds are read as JSON from the files given, " +
+		"stdin (-) by default, or fetched from a relay. Exits with 3 when a round is invalid.",
+	ArgsUsage: "[FILE|-]...",
+	Flags:     []cli.Flag{chainInfoFlag, lib.URLFlag, fromFlag, toFlag, verboseFlag},
+	Action:    verifyRounds,
+}
+
+func verifyRounds(c *cli.Context) error {
+	setLogger(c)
+	info, err := loadChainInfo(c.Context, c.String(chainInfoFlag.Name))
+	if err != nil {
+		return cli.Exit(err, exitError)
+	}
+
+	var results []*client.RandomData
+	if c.IsSet(lib.URLFlag.Name) {
+		from := uint64(c.Int(fromFlag.Name))
+		to := from
+		if c.IsSet(toFlag.Name) {
+			to = uint64(c.Int(toFlag.Name))
+		}
+		results, err = fetchRounds(c.Context, c.StringSlice(lib.URLFlag.Name)[0], info, from, to)
+		if err != nil {
+			return cli.Exit(err, exitError)
+		}
+	}
+	files := c.Args().Slice()
+	if len(files) == 0 && !c.IsSet(lib.URLFlag.Name) {
+		files = []string{"-"}
+	}
+	for _, f := range files {
+		rs, err := readRoundsFile(f)
+		if err != nil {
+			return cli.Exit(fmt.Errorf("reading %s: %w", f, err), exitError)
+		}
+		results = append(results, rs...)
+	}
+	if len(results) == 0 {
+		return cli.Exit("no round to verify", exitError)
+	}
+
+	invalid := 0
+	for _, r := range results {
+		if err := verifyResult(info, r); err != nil {
+			invalid++
+			fmt.Fprintf(c.App.Writer, "%d\tinvalid\t%s\n", r.Round(), err)
+			continue
+		}
+		fmt.Fprintf(c.App.Writer, "%d\tok\n", r.Round())
+	}
+	if invalid > 0 {
+		return cli.Exit(fmt.Sprintf("%d of %d rounds are invalid", invalid, len(results)), exitInvalid)
+	}
+	fmt.Fprintf(errWriter(c), "all %d rounds are valid\n", len(results))
+	return nil
+}
+
+// errWriter returns the writer of the messages of the command, besides its
+// output.
+func errWriter(c *cli.Context) io.Writer {
+	if c.App.ErrWriter != nil {
+		return c.App.ErrWriter
+	}
+	return cli.ErrWriter
+}
+
+// loadChainInfo reads the chain info from a file, or from a URL serving it.
+func loadChainInfo(ctx context.Context, src string) (*chain.Info, error) {
+	if !strings.HasPrefix(src, "http://") && !strings.HasPrefix(src, "https://") {
+		b, err := ioutil.ReadFile(src)
+		if err != nil {
+			return nil, err
+		}
+		return chain.InfoFromJSON(bytes.NewReader(b))
+	}
+	req, err := nhttp.NewRequestWithContext(ctx, "GET", src, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := nhttp.DefaultClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("getting chain info: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != nhttp.StatusOK {
+		return nil, fmt.Errorf("getting chain info: %s", resp.Status)
+	}
+	return chain.InfoFromJSON(resp.Body)
+}
+
+// fetchRounds gets the rounds `from` to `to` from a relay, without verifying
+// them, by pages from its range endpoint or one by one when it has none.
+func fetchRounds(ctx context.Context, url string, info *chain.Info, from, to uint64) ([]*client.RandomData, error) {
+	if from == 0 || to < from {
+		return nil, errors.New("the rounds to fetch must be given with --from, and --to from the first one")
+	}
+	hc, err := http.NewWithInfo(url, info, nhttp.DefaultTransport)
+	if err != nil {
+		return nil, err
+	}
+	defer hc.Close()
+
+	var results []*client.RandomData
+	ranged := true
+	for next := from; next <= to; {
+		if rc, ok := hc.(client.RangeClient); ok && ranged {
+			page, err := rc.GetRange(ctx, next, to)
+			if err == nil && len(page) > 0 {
+				for _, r := range page {
+					results = append(results, randomData(r))
+				}
+				next = page[len(page)-1].Round() + 1
+				continue
+			}
+			ranged = false
+		}
+		r, err := hc.Get(ctx, next)
+		if err != nil {
+			return nil, fmt.Errorf("getting round %d: %w", next, err)
+		}
+		results = append(results, randomData(r))
+		next++
+	}
+	return results, nil
+}
+
+// readRoundsFile reads the rounds of a file, or of stdin for "-".
+func readRoundsFile(name string) ([]*client.RandomData, error) {
+	if name == "-" {
+		return readRounds(os.Stdin)
+	}
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return readRounds(f)
+}
+
+// readRounds reads rounds as JSON, given as an array or one after the other
+// as written by the watch command.
+func readRounds(r io.Reader) ([]*client.RandomData, error) {
+	b, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	b = bytes.TrimSpace(b)
+	var results []*client.RandomData
+	if bytes.HasPrefix(b, []byte("[")) {
+		if err := json.Unmarshal(b, &results); err != nil {
+			return nil, err
+		}
+		return results, nil
+	}
+	dec := json.NewDecoder(bytes.NewReader(b))
+	for {
+		var rd client.RandomData
+		err := dec.Decode(&rd)
+		if err == io.EOF {
+			return results, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, &rd)
+	}
+}
+
+// verifyResult verifies the signature of a round against the chain info, and
+// that its randomness, when given, derives from its signature.
+func verifyResult(info *chain.Info, r *client.RandomData) error {
+	b := chain.Beacon{
+		Round:       r.Rnd,
+		Signature:   r.Sig,
+		SignatureV2: r.SigV2,
+		PreviousSig: r.PreviousSignature,
+	}
+	if err := info.VerifyBeacon(&b); err != nil {
+		return err
+	}
+	if len(r.Random) == 0 {
+		return nil
+	}
+	sig := b.Signature
+	if info.V2From != 0 && b.Round >= info.V2From {
+		sig = b.SignatureV2
+	}
+	randomness, err := info.Randomness(sig)
+	if err != nil {
+		return err
+	}
+	if !bytes.Equal(randomness, r.Random) {
+		return errors.New("randomness does not derive from the signature")
+	}
+	return nil
+}
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/drand/drand/client"
+	"github.com/drand/drand/client/test/result/mock"
+	json "github.com/nikkolasg/hexjson"
+	"github.com/urfave/cli/v2"
+)
+
+func TestVerifyResult(t *testing.T) {
+	info, results := mock.VerifiableResults(3, 0)
+	for _, r := range results {
+		rd := &client.RandomData{Rnd: r.Rnd, Random: r.Rand, Sig: r.Sig, PreviousSignature: r.PSig}
+		if err := verifyResult(info, rd); err != nil {
+			t.Fatal("expected round", r.Rnd, "to verify", err)
+		}
+	}
+
+	spoofed := &client.RandomData{Rnd: 2, Sig: results[2].Sig, PreviousSignature: results[1].PSig}
+	if err := verifyResult(info, spoofed); err == nil {
+		t.Fatal("expected a spoofed signature not to verify")
+	}
+	mismatched := &client.RandomData{Rnd: 1, Random: results[1].Rand, Sig: results[0].Sig, PreviousSignature: results[0].PSig}
+	if err := verifyResult(info, mismatched); err == nil {
+		t.Fatal("expected a mismatched randomness not to verify")
+	}
+}
+
+func TestReadRounds(t *testing.T) {
+	for name, input := range map[string]string{
+		"array":   `[{"round":1,"signature":"01"},{"round":2,"signature":"02"}]`,
+		"ndjson":  "{\"round\":1,\"signature\":\"01\"}\n{\"round\":2,\"signature\":\"02\"}\n",
+		"objects": `{"round":1,"signature":"01"} {"round":2,"signature":"02"}`,
+	} {
+		rounds, err := readRounds(strings.NewReader(input))
+		if err != nil {
+			t.Fatal(name, err)
+		}
+		if len(rounds) != 2 || rounds[0].Rnd != 1 || rounds[1].Rnd != 2 || rounds[1].Sig[0] != 0x02 {
+			t.Fatal(name, "unexpected rounds", rounds)
+		}
+	}
+	if _, err := readRounds(strings.NewReader("not json")); err == nil {
+		t.Fatal("expected an error for malformed rounds")
+	}
+}
+
+func TestVerifyRoundsOutput(t *testing.T) {
+	tmp, err := ioutil.TempDir("", "drand-verify")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmp)
+
+	info, results := mock.VerifiableResults(2, 0)
+	infoFile := filepath.Join(tmp, "info.json")
+	f, err := os.Create(infoFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := info.ToJSON(f); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+	var rounds []*client.RandomData
+	for _, r := range results {
+		rounds = append(rounds, &client.RandomData{Rnd: r.Rnd, Random: r.Rand, Sig: r.Sig, PreviousSignature: r.PSig})
+	}
+	buff, err := json.Marshal(rounds)
+	if err != nil {
+		t.Fatal(err)
+	}
+	roundsFile := filepath.Join(tmp, "rounds.json")
+	if err := ioutil.WriteFile(roundsFile, buff, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	var out, errOut bytes.Buffer
+	app := cli.NewApp()
+	app.Writer = &out
+	app.ErrWriter = &errOut
+	app.Commands = []*cli.Command{verifyCmd}
+	if err := app.Run([]string{"drand-client", "verify", "--chain-info", infoFile, roundsFile}); err != nil {
+		t.Fatal(err)
+	}
+	expected := fmt.Sprintf("%d\tok\n%d\tok\n", results[0].Rnd, results[1].Rnd)
+	if out.String() != expected {
+		t.Fatalf("unexpected output %q", out.String())
+	}
+	if !strings.Contains(errOut.String(), "all 2 rounds are valid") {
+		t.Fatalf("unexpected messages %q", errOut.String())
+	}
+}
@@ -0,0 +1,172 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strconv"
+	"time"
+
+	"github.com/drand/drand/client"
+	"github.com/drand/drand/cmd/client/lib"
+	"github.com/drand/drand/log"
+	json "github.com/nikkolasg/hexjson"
+	"github.com/urfave/cli/v2"
+)
+
+// reconnectDelay is the time the watch command waits before watching the
+// chain again once its watch ended.
+const reconnectDelay = time.Second
+
+var backfillFlag = &cli.IntFlag{
+	Name:  "from",
+	Usage: "backfill the rounds from the given one before the new rounds",
+}
+
+var execFlag = &cli.StringFlag{
+	Name: "exec",
+	Usage: "run the given shell command for each round instead of writing it to stdout, with the round as JSON " +
+		"on its stdin and in the DRAND_ROUND, DRAND_RANDOMNESS and DRAND_SIGNATURE environment variables",
+}
+
+var watchCmd = &cli.Command{
+	Name: "watch",
+	Usage: "write each verified round of the chain as a line of JSON to stdout, or run a command for it, " +
+		"watching the chain again whenever its watch ends",
+	Flags:  append(lib.ClientFlags, backfillFlag, execFlag, verboseFlag),
+	Action: watchRounds,
+}
+
+func watchRounds(c *cli.Context) error {
+	setLogger(c)
+	apiClient, err := lib.Create(c, false)
+	if err != nil {
+		return err
+	}
+	defer apiClient.Close()
+
+	w := &roundWatcher{
+		c:     apiClient,
+		emit:  writeRound(os.Stdout),
+		retry: reconnectDelay,
+		l:     log.DefaultLogger(),
+	}
+	if c.IsSet(execFlag.Name) {
+		w.emit = execRound(c.String(execFlag.Name))
+	}
+	if c.IsSet(backfillFlag.Name) {
+		from := uint64(c.Int(backfillFlag.Name))
+		if from == 0 {
+			return fmt.Errorf("invalid round to backfill from: %d", from)
+		}
+		w.last = from - 1
+		if err := w.backfill(c.Context, apiClient.RoundAt(time.Now())); err != nil {
+			return err
+		}
+	}
+	return w.run(c.Context)
+}
+
+// roundWatcher emits the rounds of a chain in order and without gaps: the
+// rounds missed while the watch was down are fetched before the new ones.
+type roundWatcher struct {
+	c     client.Client
+	emit  func(client.Result) error
+	retry time.Duration
+	l     log.Logger
+	// last is the last round emitted, 0 for none.
+	last uint64
+}
+
+// run watches the chain until the context is done, watching it again after
+// the retry delay whenever its watch ends or a round cannot be delivered.
+func (w *roundWatcher) run(ctx context.Context) error {
+	for {
+		watchCtx, cancel := context.WithCancel(ctx)
+		for r := range w.c.Watch(watchCtx) {
+			if err := w.deliver(ctx, r); err != nil {
+				w.l.Warn("client", "could not deliver round", "round", r.Round(), "err", err)
+				break
+			}
+		}
+		cancel()
+		if ctx.Err() != nil {
+			return nil
+		}
+		w.l.Warn("client", "watch ended, watching again", "last", w.last, "in", w.retry)
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(w.retry):
+		}
+	}
+}
+
+// deliver emits a new round, after the rounds missed since the last one.
+func (w *roundWatcher) deliver(ctx context.Context, r client.Result) error {
+	if r.Round() <= w.last {
+		return nil
+	}
+	if w.last > 0 {
+		if err := w.backfill(ctx, r.Round()-1); err != nil {
+			return err
+		}
+	}
+	w.last = r.Round()
+	return w.emit(r)
+}
+
+// backfill emits the rounds following the last one up to the given round.
+func (w *roundWatcher) backfill(ctx context.Context, to uint64) error {
+	for round := w.last + 1; round <= to; round++ {
+		r, err := w.c.Get(ctx, round)
+		if err != nil {
+			return fmt.Errorf("backfilling round %d: %w", round, err)
+		}
+		w.last = round
+		if err := w.emit(r); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeRound writes each round as a line of JSON.
+func writeRound(out io.Writer) func(client.Result) error {
+	return func(r client.Result) error {
+		b, err := json.Marshal(randomData(r))
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintf(out, "%s\n", b)
+		return err
+	}
+}
+
+// execRound runs the shell command for each round. A failing command is
+// logged, and does not stop the watch.
+func execRound(command string) func(client.Result) error {
+	return func(r client.Result) error {
+		b, err := json.Marshal(randomData(r))
+		if err != nil {
+			return err
+		}
+		cmd := exec.Command("sh", "-c", command)
+		cmd.Stdin = bytes.NewReader(b)
+		cmd.Stdout = os.Stdout
+		cmd.Stderr = os.Stderr
+		cmd.Env = append(os.Environ(),
+			"DRAND_ROUND="+strconv.FormatUint(r.Round(), 10),
+			"DRAND_RANDOMNESS="+hex.EncodeToString(r.Randomness()),
+			"DRAND_SIGNATURE="+hex.EncodeToString(r.Signature()),
+		)
+		if err := cmd.Run(); err != nil {
+			log.DefaultLogger().Warn("client", "round command failed", "round", r.Round(), "err", err)
+		}
+		return nil
+	}
+}
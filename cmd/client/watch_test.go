@@ -0,0 +1,89 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/drand/drand/client"
+	"github.com/drand/drand/log"
+)
+
+// watchedClient serves the rounds up to a round, and watches each list of
+// rounds in turn, as if the watch ended in between.
+type watchedClient struct {
+	client.Client
+	upTo    uint64
+	watches [][]uint64
+}
+
+func (c *watchedClient) Get(ctx context.Context, round uint64) (client.Result, error) {
+	if round == 0 || round > c.upTo {
+		return nil, errors.New("round not available")
+	}
+	return &client.RandomData{Rnd: round, Random: []byte{byte(round)}}, nil
+}
+
+func (c *watchedClient) Watch(ctx context.Context) <-chan client.Result {
+	ch := make(chan client.Result, 10)
+	if len(c.watches) > 0 {
+		for _, round := range c.watches[0] {
+			ch <- &client.RandomData{Rnd: round, Random: []byte{byte(round)}}
+		}
+		c.watches = c.watches[1:]
+	}
+	close(ch)
+	return ch
+}
+
+func TestRoundWatcher(t *testing.T) {
+	c := &watchedClient{upTo: 10, watches: [][]uint64{{4, 5}, {5, 8}, {10}}}
+	var rounds []uint64
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	w := &roundWatcher{
+		c: c,
+		emit: func(r client.Result) error {
+			rounds = append(rounds, r.Round())
+			if r.Round() == 10 {
+				cancel()
+			}
+			return nil
+		},
+		l:    log.DefaultLogger(),
+		last: 1,
+	}
+	if err := w.backfill(ctx, 3); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.run(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	// the rounds are emitted once, in order, and the missed ones backfilled
+	expected := []uint64{2, 3, 4, 5, 6, 7, 8, 9, 10}
+	if len(rounds) != len(expected) {
+		t.Fatal("unexpected rounds", rounds)
+	}
+	for i := range expected {
+		if rounds[i] != expected[i] {
+			t.Fatal("unexpected rounds", rounds)
+		}
+	}
+}
+
+func TestWriteRound(t *testing.T) {
+	var b bytes.Buffer
+	emit := writeRound(&b)
+	for round := uint64(1); round <= 2; round++ {
+		if err := emit(&client.RandomData{Rnd: round, Random: []byte{0xff}}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	lines := strings.Split(strings.TrimSpace(b.String()), "\n")
+	if len(lines) != 2 || lines[1] != `{"round":2,"randomness":"ff"}` {
+		t.Fatal("expected a line of JSON per round", lines)
+	}
+}
@@ -0,0 +1,247 @@
+package drand
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/drand/drand/chain"
+	"github.com/drand/drand/client"
+	json "github.com/nikkolasg/hexjson"
+	"github.com/urfave/cli/v2"
+)
+
+// maxEndpointLag is the number of rounds the latest round of an endpoint may
+// lag behind the current round, as rounds reach the endpoints a little after
+// their time.
+const maxEndpointLag = 1
+
+// checkReport is the report of the check command.
+type checkReport struct {
+	Pass      bool              `json:"pass"`
+	ChainHash string            `json:"chain_hash,omitempty"`
+	Endpoints []*endpointReport `json:"endpoints"`
+}
+
+// endpointReport is the result of the checks of an endpoint.
+type endpointReport struct {
+	URL       string        `json:"url"`
+	ChainHash string        `json:"chain_hash,omitempty"`
+	Pass      bool          `json:"pass"`
+	Checks    []checkResult `json:"checks"`
+	info      *chain.Info
+}
+
+type checkResult struct {
+	Name      string `json:"name"`
+	Pass      bool   `json:"pass"`
+	LatencyMS int64  `json:"latency_ms,omitempty"`
+	Detail    string `json:"detail,omitempty"`
+}
+
+func (e *endpointReport) add(name string, latency time.Duration, err error, detail string) {
+	r := checkResult{Name: name, Pass: err == nil, LatencyMS: latency.Milliseconds(), Detail: detail}
+	if err != nil {
+		r.Detail = err.Error()
+	}
+	e.Checks = append(e.Checks, r)
+}
+
+func checkEndpointsCmd(c *cli.Context) error {
+	if !c.Args().Present() {
+		return errors.New("check takes the URLs of the endpoints to check as arguments")
+	}
+	var expected []byte
+	if c.IsSet(expectHashFlag.Name) {
+		var err error
+		expected, err = hex.DecodeString(c.String(expectHashFlag.Name))
+		if err != nil {
+			return fmt.Errorf("invalid chain hash: %s", err)
+		}
+	}
+	round := c.Int(checkRoundFlag.Name)
+	if round <= 0 {
+		return fmt.Errorf("invalid round %d", round)
+	}
+	hc := &http.Client{Timeout: c.Duration(checkTimeoutFlag.Name)}
+	report := checkEndpoints(c.Context, hc, c.Args().Slice(), expected, uint64(round), time.Now)
+
+	if c.Bool(jsonFlag.Name) {
+		if err := printJSON(report); err != nil {
+			return err
+		}
+	} else {
+		printCheckReport(report)
+	}
+	if !report.Pass {
+		failed := 0
+		for _, e := range report.Endpoints {
+			if !e.Pass {
+				failed++
+			}
+		}
+		return fmt.Errorf("%d of %d endpoints fail the checks", failed, len(report.Endpoints))
+	}
+	return nil
+}
+
+// checkEndpoints checks each endpoint, then that they all serve the chain of
+// the expected hash, or else the chain of the first endpoint answering.
+func checkEndpoints(ctx context.Context, hc *http.Client, urls []string, expected []byte, round uint64,
+	now func() time.Time) *checkReport {
+	report := &checkReport{Pass: true}
+	for _, url := range urls {
+		report.Endpoints = append(report.Endpoints, checkEndpoint(ctx, hc, url, round, now))
+	}
+
+	reference, from := expected, "the expected chain"
+	for _, e := range report.Endpoints {
+		if reference != nil {
+			break
+		}
+		if e.info != nil {
+			reference, from = e.info.Hash(), "the chain of "+e.URL
+		}
+	}
+	if reference != nil {
+		report.ChainHash = hex.EncodeToString(reference)
+	}
+	for _, e := range report.Endpoints {
+		if e.info != nil {
+			var err error
+			if !bytes.Equal(e.info.Hash(), reference) {
+				err = fmt.Errorf("chain %s instead of %s", e.ChainHash, report.ChainHash)
+			}
+			e.add("chain-hash", 0, err, "same as "+from)
+		}
+		e.Pass = true
+		for _, r := range e.Checks {
+			e.Pass = e.Pass && r.Pass
+		}
+		report.Pass = report.Pass && e.Pass
+	}
+	return report
+}
+
+// checkEndpoint fetches the chain info, the latest round and the given past
+// round of the endpoint, and verifies the rounds.
+func checkEndpoint(ctx context.Context, hc *http.Client, url string, round uint64, now func() time.Time) *endpointReport {
+	e := &endpointReport{URL: url}
+	root := strings.TrimSuffix(url, "/")
+
+	start := time.Now()
+	info, err := fetchChainInfo(ctx, hc, root+"/info")
+	if err != nil {
+		e.add("info", time.Since(start), err, "")
+		return e
+	}
+	e.info = info
+	e.ChainHash = hex.EncodeToString(info.Hash())
+	e.add("info", time.Since(start), nil, "chain "+e.ChainHash)
+
+	start = time.Now()
+	latest, err := fetchRound(ctx, hc, root+"/public/latest", info)
+	latency := time.Since(start)
+	if err != nil {
+		e.add("latest", latency, err, "")
+	} else {
+		var lag uint64
+		if current := info.RoundAt(now()); current > latest.Rnd {
+			lag = current - latest.Rnd
+		}
+		if lag > maxEndpointLag {
+			err = fmt.Errorf("round %d is %d rounds behind", latest.Rnd, lag)
+		}
+		e.add("latest", latency, err, fmt.Sprintf("round %d, %d rounds behind", latest.Rnd, lag))
+	}
+
+	start = time.Now()
+	past, err := fetchRound(ctx, hc, fmt.Sprintf("%s/public/%d", root, round), info)
+	latency = time.Since(start)
+	if err == nil && past.Rnd != round {
+		err = fmt.Errorf("round %d instead of %d", past.Rnd, round)
+	}
+	e.add("past", latency, err, fmt.Sprintf("round %d", round))
+	return e
+}
+
+func fetchChainInfo(ctx context.Context, hc *http.Client, url string) (*chain.Info, error) {
+	resp, err := httpGet(ctx, hc, url)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	return chain.InfoFromJSON(resp.Body)
+}
+
+// fetchRound fetches a round and verifies it against the chain info.
+func fetchRound(ctx context.Context, hc *http.Client, url string, info *chain.Info) (*client.RandomData, error) {
+	resp, err := httpGet(ctx, hc, url)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	r := new(client.RandomData)
+	if err := json.NewDecoder(resp.Body).Decode(r); err != nil {
+		return nil, fmt.Errorf("invalid round: %s", err)
+	}
+	b := &chain.Beacon{Round: r.Rnd, Signature: r.Sig, SignatureV2: r.SigV2, PreviousSig: r.PreviousSignature}
+	if err := info.VerifyBeacon(b); err != nil {
+		return nil, fmt.Errorf("round %d is invalid: %s", r.Rnd, err)
+	}
+	sig := b.Signature
+	if info.V2From != 0 && b.Round >= info.V2From {
+		sig = b.SignatureV2
+	}
+	randomness, err := info.Randomness(sig)
+	if err != nil {
+		return nil, err
+	}
+	if !bytes.Equal(randomness, r.Random) {
+		return nil, fmt.Errorf("the randomness of round %d does not derive from its signature", r.Rnd)
+	}
+	return r, nil
+}
+
+func httpGet(ctx context.Context, hc *http.Client, url string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := hc.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, fmt.Errorf("%s answered %s", url, resp.Status)
+	}
+	return resp, nil
+}
+
+func printCheckReport(report *checkReport) {
+	status := func(pass bool) string {
+		if pass {
+			return "PASS"
+		}
+		return "FAIL"
+	}
+	for _, e := range report.Endpoints {
+		fmt.Fprintf(output, "%s %s\n", status(e.Pass), e.URL)
+		for _, r := range e.Checks {
+			latency := ""
+			if r.LatencyMS > 0 {
+				latency = fmt.Sprintf("%dms", r.LatencyMS)
+			}
+			fmt.Fprintf(output, "  %s  %-10s %7s  %s\n", status(r.Pass), r.Name, latency, r.Detail)
+		}
+	}
+	if report.Pass {
+		fmt.Fprintf(output, "All %d endpoints serve the chain %s.\n", len(report.Endpoints), report.ChainHash)
+	}
+}
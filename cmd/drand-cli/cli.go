@@ -6,21 +6,28 @@ import (
 	"bufio"
 	"bytes"
 	"context"
+	"encoding/hex"
 	"errors"
 	"fmt"
 	"io"
+	"io/ioutil"
 	"os"
+	"os/user"
 	"path"
 	"path/filepath"
 	"regexp"
 	"runtime"
 	"strconv"
 	"strings"
+	"time"
 
 	gonet "net"
 
 	"github.com/BurntSushi/toml"
-	"github.com/drand/drand/chain/boltdb"
+	"github.com/drand/drand/audit"
+	"github.com/drand/drand/backup"
+	"github.com/drand/drand/chain"
+	"github.com/drand/drand/chain/beacon"
 	"github.com/drand/drand/core"
 	"github.com/drand/drand/fs"
 	"github.com/drand/drand/key"
@@ -28,6 +35,7 @@ import (
 	"github.com/drand/drand/net"
 	"github.com/drand/drand/protobuf/drand"
 	"github.com/urfave/cli/v2"
+	bolt "go.etcd.io/bbolt"
 )
 
 // default output of the drand operational commands
@@ -84,6 +92,38 @@ var controlFlag = &cli.StringFlag{
 	Usage: "Set the port you want to listen to for control port commands. If not specified, we will use the default port 8888.",
 }
 
+var beaconIDFlag = &cli.StringFlag{
+	Name:  "id",
+	Usage: "Set the ID of the beacon the command is for, when the daemon hosts several beacons. If not specified, the command is for the default beacon.",
+}
+
+var remoteSignerFlag = &cli.StringFlag{
+	Name: "remote-signer",
+	Usage: "Set the address of a remote signing daemon holding the private share, started with 'drand signer'. " +
+		"The link to the daemon is authenticated by mutual TLS and requires the --signer-tls-* flags, even with --tls-disable.",
+}
+
+var signerTLSCertFlag = &cli.StringFlag{
+	Name:  "signer-tls-cert",
+	Usage: "Set the TLS certificate (in PEM format) presented on the link between the node and its signing daemon.",
+}
+
+var signerTLSKeyFlag = &cli.StringFlag{
+	Name:  "signer-tls-key",
+	Usage: "Set the TLS private key (in PEM format) of the certificate given by --signer-tls-cert.",
+}
+
+var signerTLSCAFlag = &cli.StringFlag{
+	Name: "signer-tls-ca",
+	Usage: "Set the CA certificate (in PEM format) signing the certificate of the other end of the link " +
+		"between the node and its signing daemon.",
+}
+
+var signerListenFlag = &cli.StringFlag{
+	Name:  "listen",
+	Usage: "Set the listening (binding) address of the signing daemon.",
+}
+
 var metricsFlag = &cli.StringFlag{
 	Name:  "metrics",
 	Usage: "Launch a metrics server at the specified (host:)port.",
@@ -122,7 +162,14 @@ var outFlag = &cli.StringFlag{
 
 var periodFlag = &cli.StringFlag{
 	Name:  "period",
-	Usage: "period to set when doing a setup",
+	Usage: "period to set when doing a setup, or the period a resharing switches the chain to at the transition",
+}
+
+var derivationFlag = &cli.StringFlag{
+	Name: "derivation",
+	Usage: "ID of the derivation of the randomness from the signatures of the chain to set when doing a setup, one of " +
+		strings.Join(chain.DerivationIDs(), ", ") + ". A resharing keeps the derivation of the chain.",
+	Value: chain.DefaultDerivationID,
 }
 
 var catchupPeriodFlag = &cli.StringFlag{
@@ -133,7 +180,7 @@ var catchupPeriodFlag = &cli.StringFlag{
 
 var thresholdFlag = &cli.IntFlag{
 	Name:  "threshold",
-	Usage: "threshold to use for the DKG",
+	Usage: "threshold to use for the DKG. A resharing keeps the threshold of the old group by default",
 }
 
 var shareNodeFlag = &cli.IntFlag{
@@ -196,6 +243,242 @@ var timeoutFlag = &cli.StringFlag{
 	Usage: fmt.Sprintf("Timeout to use during the DKG, in string format. Default is %s", core.DefaultDKGTimeout),
 }
 
+var dealTimeoutFlag = &cli.StringFlag{
+	Name:  "deal-timeout",
+	Usage: "Leader uses this flag to set the duration of the deal phase of the DKG, in string format. Default is the timeout flag.",
+}
+
+var responseTimeoutFlag = &cli.StringFlag{
+	Name:  "response-timeout",
+	Usage: "Leader uses this flag to set the duration of the response phase of the DKG, in string format. Default is the timeout flag.",
+}
+
+var justificationTimeoutFlag = &cli.StringFlag{
+	Name:  "justification-timeout",
+	Usage: "Leader uses this flag to set the duration of the justification phase of the DKG, in string format. Default is the timeout flag.",
+}
+
+var phaseRetriesFlag = &cli.IntFlag{
+	Name: "phase-retries",
+	Usage: "Leader uses this flag to set the number of times the nodes extend the deal and then the response " +
+		"phase of the DKG while some participants did not complete it. Default is 0.",
+}
+
+var dkgRetriesFlag = &cli.IntFlag{
+	Name:  "dkg-retries",
+	Usage: fmt.Sprintf("Number of times to retry delivering a DKG packet to a participant. Default is %d", core.DefaultDKGRetries),
+}
+
+var keepRoundsFlag = &cli.Uint64Flag{
+	Name: "keep-rounds",
+	Usage: fmt.Sprintf("Prune the rounds older than the given number of last rounds from the database. "+
+		"The last %d rounds and the checkpoints of the chain are always kept.", beacon.MinRetainedRounds),
+}
+
+var keepForFlag = &cli.DurationFlag{
+	Name: "keep-for",
+	Usage: "Prune the rounds older than the given duration from the database. A round kept by --keep-rounds " +
+		"is kept as well.",
+}
+
+var backupURLFlag = &cli.StringFlag{
+	Name: "backup-url",
+	Usage: "Periodically back up the node to the given object storage: s3://bucket/prefix, gs://bucket/prefix " +
+		"or file:///folder. The credentials of S3 and GCS are read from the AWS environment variables and " +
+		"configuration files, with the HMAC keys of a service account for GCS.",
+}
+
+var backupEndpointFlag = &cli.StringFlag{
+	Name:  "backup-endpoint",
+	Usage: "URL of the S3 compatible storage of an s3:// backup url, instead of AWS.",
+}
+
+var backupRegionFlag = &cli.StringFlag{
+	Name:  "backup-region",
+	Usage: "Region of the bucket of the backups.",
+}
+
+var backupPathStyleFlag = &cli.BoolFlag{
+	Name:  "backup-path-style",
+	Usage: "Address the bucket of the backups in the path of the URLs, as most self hosted S3 storages require.",
+}
+
+var backupIntervalFlag = &cli.DurationFlag{
+	Name:  "backup-interval",
+	Usage: "Time between two backups.",
+	Value: backup.DefaultInterval,
+}
+
+var backupKeepFlag = &cli.IntFlag{
+	Name:  "backup-keep",
+	Usage: "Number of backups to keep, 0 to keep them all.",
+	Value: 7,
+}
+
+var backupMaxAgeFlag = &cli.DurationFlag{
+	Name:  "backup-max-age",
+	Usage: "Delete the backups older than the given duration. The last backup is always kept.",
+}
+
+var backupPassphraseFlag = &cli.StringFlag{
+	Name: "backup-passphrase-file",
+	Usage: "File holding the passphrase encrypting the key material in the backups. Without it, " +
+		"only the beacon database is backed up.",
+}
+
+var backupStatusFlag = &cli.BoolFlag{
+	Name:  "status",
+	Usage: "Only print the backup status of the daemon, without backing up.",
+}
+
+var chainOnlyFlag = &cli.BoolFlag{
+	Name:  "chain-only",
+	Usage: "Only restore the beacons of the backup, and not its key material, as for a node which still has its keys.",
+}
+
+var skipManifestFlag = &cli.BoolFlag{
+	Name: "skip-manifest",
+	Usage: "Restore a backup without manifest, made by an older version, relying on the checksum of its " +
+		"snapshot and the authentication of its key material only.",
+}
+
+var dbFlag = &cli.StringFlag{
+	Name:  "db",
+	Value: core.DefaultDBEngine,
+	Usage: "Storage engine of the beacon database, one of " + strings.Join(core.DBEngines(), ", ") +
+		". The SQL engines need a binary built with their tag, sqlite (which needs cgo) or postgres.",
+}
+
+var dbURLFlag = &cli.StringFlag{
+	Name: "db-url",
+	Usage: "Data source of the SQL database of the beacons: the path of the SQLite file, " +
+		"by default in the database folder, or the connection string of the Postgres database.",
+}
+
+var toDBFlag = &cli.StringFlag{
+	Name:     "to-db",
+	Usage:    "Storage engine to migrate the beacon database to, one of " + strings.Join(core.DBEngines(), ", ") + ".",
+	Required: true,
+}
+
+var toDBURLFlag = &cli.StringFlag{
+	Name:  "to-db-url",
+	Usage: "Data source of the SQL database to migrate the beacon database to, see --db-url.",
+}
+
+var snapshotFromFlag = &cli.Uint64Flag{
+	Name:  "from",
+	Usage: "First round to export in the snapshot.",
+}
+
+var snapshotToFlag = &cli.Uint64Flag{
+	Name:  "to",
+	Usage: "Last round to export in the snapshot, by default the last round of the database.",
+}
+
+var snapshotHashFlag = &cli.StringFlag{
+	Name: "chain-hash",
+	Usage: "The hash of the chain info the snapshot must be a snapshot of. By default, the chain of the group " +
+		"of the node, if any.",
+}
+
+var pruneStatusFlag = &cli.BoolFlag{
+	Name:  "status",
+	Usage: "Only print the pruning status of the daemon, without pruning.",
+}
+
+var pauseStatusFlag = &cli.BoolFlag{
+	Name:  "status",
+	Usage: "Only print whether the daemon is paused, without pausing it.",
+}
+
+var drainStatusFlag = &cli.BoolFlag{
+	Name:  "status",
+	Usage: "Only print the state of the drain of the daemon, without draining it.",
+}
+
+var drainExitFlag = &cli.BoolFlag{
+	Name:  "exit",
+	Usage: "Stop draining the daemon, which serves all its traffic again.",
+}
+
+var mtlsCAFlag = &cli.StringFlag{
+	Name: "mtls-ca",
+	Usage: "Set the CA certificate (in PEM format) signing the certificates of the nodes, to enable mutual TLS " +
+		"between them: the node presents its --tls-cert, reloaded when its files change, and only serves the " +
+		"protocol to the peers presenting a certificate of the CA.",
+}
+
+var mtlsReloadFlag = &cli.DurationFlag{
+	Name:  "mtls-reload-interval",
+	Usage: "How often the files of the certificate of the node are checked for a rotated certificate.",
+	Value: net.DefaultMTLSReloadInterval,
+}
+
+var controlTokensFlag = &cli.StringFlag{
+	Name: "control-tokens",
+	Usage: "Require the control commands to present one of the tokens of this TOML file, in the " +
+		"DRAND_CONTROL_TOKEN environment variable. Each [[tokens]] table holds a name, a token, and a role: " +
+		"'read' for the status commands only, or 'admin' for all the commands.",
+}
+
+var proxyFlag = &cli.StringFlag{
+	Name: "proxy",
+	Usage: "Connect to the other nodes, for the beacons, the DKG and the sync, through the proxy of this URL: " +
+		"socks5://host:port for a SOCKS5 proxy, or http(s)://host:port for a HTTP proxy supporting CONNECT. " +
+		"The URL may hold the credentials of the proxy. Without it, the SOCKS5 proxy of the ALL_PROXY " +
+		"environment variable is used, if set.",
+}
+
+var acmeDomainFlag = &cli.StringSliceFlag{
+	Name: "acme-domain",
+	Usage: "Serve the public endpoint with the certificates of an ACME certificate authority, Let's Encrypt " +
+		"by default, issued and renewed automatically for these domains. The certificates are stapled with " +
+		"their OCSP responses. The private endpoint keeps the certificate of --tls-cert.",
+}
+
+var acmeEmailFlag = &cli.StringFlag{
+	Name:  "acme-email",
+	Usage: "Contact email of the ACME account, notified of the problems with the certificates.",
+}
+
+var acmeHTTPFlag = &cli.StringFlag{
+	Name: "acme-http",
+	Usage: "Answer the HTTP-01 challenges of the ACME certificate authority on this address, e.g. :80. " +
+		"Without it, only the TLS-ALPN-01 challenges are answered, on the public endpoint.",
+}
+
+var acmeDirectoryFlag = &cli.StringFlag{
+	Name:  "acme-directory",
+	Usage: "Directory URL of the ACME certificate authority, e.g. the staging one of Let's Encrypt for tests.",
+}
+
+var auditLogFlag = &cli.StringFlag{
+	Name: "audit-log",
+	Usage: "Append the operations on the control plane and on the key material - the DKGs and resharings, " +
+		"the loading and export of the keys, the backups and restores, the pauses and resumes and the " +
+		"denied control commands - to this audit log, one JSON object per line. It is a file path, or " +
+		"'syslog' for the local syslog, or syslog+udp://host:port or syslog+tcp://host:port for a remote one.",
+}
+
+var drainTimeoutFlag = &cli.DurationFlag{
+	Name: "drain-timeout",
+	Usage: "On SIGTERM, how long the daemon waits for the round in progress and lets the calls in progress " +
+		"finish before stopping.",
+	Value: 30 * time.Second,
+}
+
+var configFileFlag = &cli.StringFlag{
+	Name: "config",
+	Usage: "Read the settings of the daemon from the given TOML file, which the flags override. " +
+		"The log level and the metrics address are reloaded from the file on SIGHUP or with 'drand util reload'.",
+}
+
+var followFlag = &cli.BoolFlag{
+	Name:  "follow",
+	Usage: "Keep printing the status at each change, until the DKG ends.",
+}
+
 var pushFlag = &cli.BoolFlag{
 	Name: "push",
 	Usage: "Push mode forces the daemon to start making beacon requests to the other node, " +
@@ -228,6 +511,54 @@ var hashOnly = &cli.BoolFlag{
 	Usage: "Only print the hash of the group file",
 }
 
+var chainInfoFormatFlag = &cli.StringFlag{
+	Name: "format",
+	Usage: "Output format of the chain info: json, toml, or pin for a pin string HASH@URL of the hash of the " +
+		"chain and of the --url serving it, to distribute the chain to trust.",
+	Value: "json",
+}
+
+var pinURLFlag = &cli.StringFlag{
+	Name:  "url",
+	Usage: "The URL of the HTTP endpoint serving the chain, in the pin string.",
+}
+
+var expectHashFlag = &cli.StringFlag{
+	Name: "expect-hash",
+	Usage: "Fail unless the chain info has the given hash, in hex. For the check command, by default, the " +
+		"endpoints must serve the chain of the first endpoint.",
+}
+
+var schemeFlag = &cli.StringFlag{
+	Name: "scheme",
+	Usage: "The ID of the scheme of the chains the keypair is for, one of " + strings.Join(chain.SchemeIDs(), ", ") +
+		". It decides the curve and groups of the key, and the groups led by the node are of this scheme. " +
+		"The node refuses to join the groups of another scheme.",
+	Value: chain.DefaultSchemeID,
+}
+
+var chainInfoFlag = &cli.StringFlag{
+	Name:  "chain-info",
+	Usage: "Path or URL of the chain info (JSON encoded) to use instead of the one of the daemon",
+}
+
+var checkRoundFlag = &cli.IntFlag{
+	Name:  "round",
+	Usage: "The past round fetched and verified from each endpoint, besides the latest one.",
+	Value: 1,
+}
+
+var checkTimeoutFlag = &cli.DurationFlag{
+	Name:  "timeout",
+	Usage: "Timeout of each request to the endpoints.",
+	Value: 10 * time.Second,
+}
+
+var jsonFlag = &cli.BoolFlag{
+	Name:  "json",
+	Usage: "Print the result in JSON.",
+}
+
 var hashInfoFlag = &cli.StringFlag{
 	Name:     "chain-hash",
 	Usage:    "The hash of the chain info",
@@ -254,16 +585,44 @@ var appCommands = []*cli.Command{
 		Usage: "Start the drand daemon.",
 		Flags: toArray(folderFlag, tlsCertFlag, tlsKeyFlag,
 			insecureFlag, controlFlag, privListenFlag, pubListenFlag, metricsFlag,
-			certsDirFlag, pushFlag, verboseFlag, enablePrivateRand, oldGroupFlag, skipValidationFlag),
+			certsDirFlag, pushFlag, verboseFlag, enablePrivateRand, oldGroupFlag, skipValidationFlag,
+			remoteSignerFlag, signerTLSCertFlag, signerTLSKeyFlag, signerTLSCAFlag, dkgRetriesFlag, keepRoundsFlag, keepForFlag,
+			dbFlag, dbURLFlag, backupURLFlag, backupEndpointFlag, backupRegionFlag, backupPathStyleFlag,
+			backupIntervalFlag, backupKeepFlag, backupMaxAgeFlag, backupPassphraseFlag, configFileFlag,
+			drainTimeoutFlag, mtlsCAFlag, mtlsReloadFlag, controlTokensFlag, proxyFlag, auditLogFlag,
+			acmeDomainFlag, acmeEmailFlag, acmeHTTPFlag, acmeDirectoryFlag),
 		Action: func(c *cli.Context) error {
 			banner()
 			return startCmd(c)
 		},
 	},
+	{
+		Name: "signer",
+		Usage: "Start a signing daemon producing the partial signatures of a node with the private share " +
+			"of the given folder, so that the node does not hold it. The daemon only signs the rounds whose time has come.\n",
+		Flags: toArray(folderFlag, signerListenFlag, signerTLSCertFlag, signerTLSKeyFlag, signerTLSCAFlag),
+		Action: func(c *cli.Context) error {
+			banner()
+			return signerCmd(c)
+		},
+	},
+	{
+		Name: "observe",
+		Usage: "Start the daemon as an observer, which follows the chain of the given hash from the given nodes " +
+			"and serves it, without any key, DKG or participation in the generation of the beacons.",
+		Flags: toArray(folderFlag, tlsCertFlag, tlsKeyFlag, insecureFlag, controlFlag, privListenFlag,
+			pubListenFlag, metricsFlag, certsDirFlag, verboseFlag, dbFlag, dbURLFlag, hashInfoFlag, syncNodeFlag,
+			mtlsCAFlag, mtlsReloadFlag, controlTokensFlag, proxyFlag, acmeDomainFlag, acmeEmailFlag, acmeHTTPFlag,
+			acmeDirectoryFlag),
+		Action: func(c *cli.Context) error {
+			banner()
+			return observeCmd(c)
+		},
+	},
 	{
 		Name:  "stop",
 		Usage: "Stop the drand daemon.\n",
-		Flags: toArray(controlFlag),
+		Flags: toArray(controlFlag, beaconIDFlag),
 		Action: func(c *cli.Context) error {
 			banner()
 			return stopDaemon(c)
@@ -272,10 +631,11 @@ var appCommands = []*cli.Command{
 	{
 		Name:  "share",
 		Usage: "Launch a sharing protocol.",
-		Flags: toArray(insecureFlag, controlFlag, oldGroupFlag,
-			timeoutFlag, sourceFlag, userEntropyOnlyFlag, secretFlag,
+		Flags: toArray(insecureFlag, controlFlag, beaconIDFlag, oldGroupFlag,
+			timeoutFlag, dealTimeoutFlag, responseTimeoutFlag, justificationTimeoutFlag, phaseRetriesFlag,
+			sourceFlag, userEntropyOnlyFlag, secretFlag,
 			periodFlag, shareNodeFlag, thresholdFlag, connectFlag, outFlag,
-			leaderFlag, beaconOffset, transitionFlag, forceFlag, catchupPeriodFlag),
+			leaderFlag, beaconOffset, transitionFlag, forceFlag, catchupPeriodFlag, derivationFlag),
 		Action: func(c *cli.Context) error {
 			banner()
 			return shareCmd(c)
@@ -284,16 +644,57 @@ var appCommands = []*cli.Command{
 	{
 		Name:  "follow",
 		Usage: "follow and store a randomness chain",
-		Flags: toArray(folderFlag, controlFlag, hashInfoFlag, syncNodeFlag,
+		Flags: toArray(folderFlag, controlFlag, beaconIDFlag, hashInfoFlag, syncNodeFlag,
 			tlsCertFlag, insecureFlag, upToFlag),
 		Action: followCmd,
 	},
+	{
+		Name: "backup",
+		Usage: "Backs up the node, which must be stopped, to the given --backup-url: a snapshot of its beacon " +
+			"database and, encrypted under the --backup-passphrase-file if given, its key material, with a " +
+			"manifest of their checksums. Use 'util backup' to back up a running daemon.",
+		Flags: toArray(folderFlag, dbFlag, dbURLFlag, backupURLFlag, backupEndpointFlag, backupRegionFlag,
+			backupPathStyleFlag, backupPassphraseFlag, beaconIDFlag, auditLogFlag),
+		Action: backupNodeCmd,
+	},
+	{
+		Name: "restore",
+		Usage: "Restores the backup `NAME`, by default the newest one, stored at the given --backup-url, once " +
+			"its objects match the checksums of its manifest: decrypts the key material into the configuration " +
+			"folder, which must not hold any, and imports the verified beacons into the database. The daemon " +
+			"must be stopped.",
+		Flags: toArray(folderFlag, dbFlag, dbURLFlag, backupURLFlag, backupEndpointFlag, backupRegionFlag,
+			backupPathStyleFlag, backupPassphraseFlag, beaconIDFlag, auditLogFlag, chainOnlyFlag, skipManifestFlag),
+		Action: restoreBackupCmd,
+	},
+	{
+		Name: "check",
+		Usage: "Checks the HTTP endpoints, nodes or relays, at the given `URL`s: fetches their chain info, " +
+			"their latest round and a past round, verifies the rounds, measures the latency of the requests, " +
+			"and compares the chains the endpoints serve. Prints a report, and fails if a check fails.",
+		Flags:  toArray(expectHashFlag, checkRoundFlag, checkTimeoutFlag, jsonFlag),
+		Action: checkEndpointsCmd,
+	},
+	{
+		Name:  "dkg",
+		Usage: "Commands following the DKG or resharing of the daemon.",
+		Subcommands: []*cli.Command{
+			{
+				Name: "status",
+				Usage: "Prints the status of the last DKG or resharing of the daemon: its current phase and the " +
+					"time left before it times out, the timeouts of the phases, and which participants sent " +
+					"their deals, responses and justifications, in JSON with --json.",
+				Flags:  toArray(controlFlag, beaconIDFlag, followFlag, jsonFlag),
+				Action: dkgStatusReportCmd,
+			},
+		},
+	},
 	{
 		Name: "generate-keypair",
 		Usage: "Generate the longterm keypair (drand.private, drand.public)" +
 			"for this node.\n",
 		ArgsUsage: "<address> is the address other nodes will be able to contact this node on (specified as 'private-listen' to the daemon)",
-		Flags:     toArray(folderFlag, insecureFlag),
+		Flags:     toArray(folderFlag, insecureFlag, schemeFlag, beaconIDFlag),
 		Action: func(c *cli.Context) error {
 			banner()
 			return keygenCmd(c)
@@ -335,8 +736,9 @@ var appCommands = []*cli.Command{
 				Name:      "chain-info",
 				Usage:     "Get the binding chain information that this nodes participates to",
 				ArgsUsage: "`ADDRESS1` `ADDRESS2` ... provides the addresses of the node to try to contact to.",
-				Flags:     toArray(tlsCertFlag, insecureFlag, hashOnly),
-				Action:    getChainInfo,
+				Flags: toArray(tlsCertFlag, insecureFlag, hashOnly, chainInfoFormatFlag, pinURLFlag,
+					expectHashFlag),
+				Action: getChainInfo,
 			},
 		},
 	},
@@ -356,22 +758,169 @@ var appCommands = []*cli.Command{
 			{
 				Name:   "ping",
 				Usage:  "pings the daemon checking its state\n",
-				Flags:  toArray(controlFlag),
+				Flags:  toArray(controlFlag, beaconIDFlag),
 				Action: pingpongCmd,
 			},
 			{
 				Name:   "reset",
 				Usage:  "Resets the local distributed information (share, group file and random beacons). It KEEPS the private/public key pair.",
-				Flags:  toArray(folderFlag, controlFlag),
+				Flags:  toArray(folderFlag, controlFlag, beaconIDFlag),
 				Action: resetCmd,
 			},
 			{
 				Name: "del-beacon",
 				Usage: "Delete all beacons from the given `ROUND` number until the head of the chain. " +
 					" You MUST restart the daemon after that command.",
-				Flags:  toArray(folderFlag),
+				Flags:  toArray(folderFlag, dbFlag, dbURLFlag),
 				Action: deleteBeaconCmd,
 			},
+			{
+				Name: "migrate-db",
+				Usage: "Copies the beacon database of the node from the storage engine given by --db to the one " +
+					"given by --to-db. The daemon must be stopped, and restarted with the new engine afterwards.",
+				Flags:  toArray(folderFlag, dbFlag, dbURLFlag, toDBFlag, toDBURLFlag),
+				Action: migrateDBCmd,
+			},
+			{
+				Name: "export-snapshot",
+				Usage: "Exports the beacons of the database of the node to the snapshot `FILE`, with the chain info, " +
+					"so that a new node, relay or verifier can import it. The daemon should be stopped.",
+				Flags:  toArray(folderFlag, dbFlag, dbURLFlag, snapshotFromFlag, snapshotToFlag),
+				Action: exportSnapshotCmd,
+			},
+			{
+				Name: "import-snapshot",
+				Usage: "Verifies the beacons of the snapshot `FILE` and imports them into the database of the node, " +
+					"which must be stopped. The snapshot must follow the last beacon of the database.",
+				Flags:  toArray(folderFlag, dbFlag, dbURLFlag, snapshotHashFlag),
+				Action: importSnapshotCmd,
+			},
+			{
+				Name:   "verify-snapshot",
+				Usage:  "Verifies the checksum and all the beacons of the snapshot `FILE`, and prints its content.",
+				Flags:  toArray(snapshotHashFlag),
+				Action: verifySnapshotCmd,
+			},
+			{
+				Name: "backup",
+				Usage: "Backs up the daemon right away to the object storage it is configured with, and prints, " +
+					"in JSON, the resulting backup status. With --status, only prints the status.",
+				Flags:  toArray(controlFlag, beaconIDFlag, backupStatusFlag),
+				Action: backupCmd,
+			},
+			{
+				Name:  "list-backups",
+				Usage: "Prints, in JSON, the backups stored at the given --backup-url, from the oldest to the newest.",
+				Flags: toArray(backupURLFlag, backupEndpointFlag, backupRegionFlag, backupPathStyleFlag,
+					beaconIDFlag),
+				Action: listBackupsCmd,
+			},
+			{
+				Name: "restore-backup",
+				Usage: "Restores the backup `NAME`, by default the newest one, stored at the given --backup-url. " +
+					"Same as the restore command.",
+				Flags: toArray(folderFlag, dbFlag, dbURLFlag, backupURLFlag, backupEndpointFlag, backupRegionFlag,
+					backupPathStyleFlag, backupPassphraseFlag, beaconIDFlag, auditLogFlag, chainOnlyFlag,
+					skipManifestFlag),
+				Action: restoreBackupCmd,
+			},
+			{
+				Name: "dkg-status",
+				Usage: "Prints, in JSON, the status of the last DKG or resharing of the daemon: the current phase, " +
+					"and which packets it received from each participant. Same as dkg status --json.",
+				Flags:  toArray(controlFlag, beaconIDFlag, followFlag),
+				Action: dkgStatusCmd,
+			},
+			{
+				Name: "sync-status",
+				Usage: "Prints, in JSON, the progress of the current or last sync of the daemon with the other nodes, " +
+					"and the rounds fetched from each of them.",
+				Flags:  toArray(controlFlag, beaconIDFlag),
+				Action: syncStatusCmd,
+			},
+			{
+				Name: "node-status",
+				Usage: "Prints, in JSON, the health of the daemon: its lag behind the expected round, the time of " +
+					"its last beacon, the state of its DKG, of its storage and of the other members, and the " +
+					"problems making it unhealthy, in which case the command fails.",
+				Flags:  toArray(controlFlag, beaconIDFlag),
+				Action: nodeStatusCmd,
+			},
+			{
+				Name: "prune",
+				Usage: "Deletes right away the rounds the retention policy of the daemon does not keep, " +
+					"and prints, in JSON, the resulting pruning status. With --status, only prints the status.",
+				Flags:  toArray(controlFlag, beaconIDFlag, pruneStatusFlag),
+				Action: pruneCmd,
+			},
+			{
+				Name: "reload",
+				Usage: "Reloads the configuration file of the daemon, and prints, in JSON, the settings applied " +
+					"and the changed settings which only apply after a restart.",
+				Flags:  toArray(controlFlag, beaconIDFlag),
+				Action: reloadCmd,
+			},
+			{
+				Name: "pause",
+				Usage: "Stops the daemon from signing the beacons, until it is resumed, and prints, in JSON, " +
+					"the resulting status. The daemon still stores and syncs the beacons. " +
+					"With --status, only prints the status.",
+				Flags:  toArray(controlFlag, beaconIDFlag, pauseStatusFlag),
+				Action: pauseCmd,
+			},
+			{
+				Name:   "resume",
+				Usage:  "Lets a paused daemon sign the beacons again, from the next round.",
+				Flags:  toArray(controlFlag, beaconIDFlag),
+				Action: resumeCmd,
+			},
+			{
+				Name: "drain",
+				Usage: "Drains the daemon before a maintenance, and prints, in JSON, the resulting state: the " +
+					"daemon refuses the public traffic and the new syncs, and its peers finish syncing from it. " +
+					"It keeps signing the beacons until it is stopped, once 'drained'. With --exit, it serves " +
+					"all its traffic again. With --status, only prints the state.",
+				Flags:  toArray(controlFlag, beaconIDFlag, drainStatusFlag, drainExitFlag),
+				Action: drainCmd,
+			},
+			{
+				Name: "participation",
+				Usage: "Prints, in JSON, for each member of the group, the arrival latency of its partial " +
+					"signatures after the start of their round, its invalid partial signatures and the " +
+					"rounds it missed, as observed by the daemon since it started.",
+				Flags:  toArray(controlFlag, beaconIDFlag),
+				Action: participationCmd,
+			},
+			{
+				Name: "dkg-state",
+				Usage: "Shows the state of the DKG or resharing in progress persisted by the node, " +
+					"which the node rejoins when it restarts.",
+				Flags:  toArray(folderFlag, beaconIDFlag),
+				Action: showDKGStateCmd,
+			},
+			{
+				Name: "abort-dkg",
+				Usage: "Deletes the state of the DKG or resharing in progress persisted by the node, " +
+					"so that it does not rejoin the ceremony when it restarts. Stop the daemon before.",
+				Flags:  toArray(folderFlag, beaconIDFlag),
+				Action: abortDKGCmd,
+			},
+			{
+				Name: "round-at",
+				Usage: "Prints the latest round of the chain produced at the given `TIME`, as RFC 3339 or UNIX " +
+					"seconds, using the chain info of the daemon or of the chain-info flag.",
+				ArgsUsage: "<TIME>",
+				Flags:     toArray(controlFlag, beaconIDFlag, chainInfoFlag),
+				Action:    roundAtCmd,
+			},
+			{
+				Name: "time-of",
+				Usage: "Prints the time, as RFC 3339, at which the given `ROUND` of the chain is produced, " +
+					"using the chain info of the daemon or of the chain-info flag.",
+				ArgsUsage: "<ROUND>",
+				Flags:     toArray(controlFlag, beaconIDFlag, chainInfoFlag),
+				Action:    timeOfCmd,
+			},
 			{
 				Name:   "self-sign",
 				Usage:  "Signs the public identity of this node. Needed for backward compatibility with previous versions.",
@@ -388,12 +937,12 @@ var appCommands = []*cli.Command{
 			"long-term private key (drand.private), the long-term public key " +
 			"(drand.public), or the private key share (drand.share), " +
 			"respectively.\n",
-		Flags: toArray(folderFlag, controlFlag),
+		Flags: toArray(folderFlag, controlFlag, beaconIDFlag),
 		Subcommands: []*cli.Command{
 			{
 				Name:   "share",
 				Usage:  "shows the private share\n",
-				Flags:  toArray(controlFlag),
+				Flags:  toArray(controlFlag, beaconIDFlag),
 				Action: showShareCmd,
 			},
 			{
@@ -401,25 +950,26 @@ var appCommands = []*cli.Command{
 				Usage: "shows the current group.toml used. The group.toml " +
 					"may contain the distributed public key if the DKG has been " +
 					"ran already.\n",
-				Flags:  toArray(outFlag, controlFlag, hashOnly),
+				Flags:  toArray(outFlag, controlFlag, beaconIDFlag, hashOnly),
 				Action: showGroupCmd,
 			},
 			{
-				Name:   "chain-info",
-				Usage:  "shows the chain information this node is participating to",
-				Flags:  toArray(controlFlag, hashOnly),
+				Name:  "chain-info",
+				Usage: "shows the chain information this node is participating to",
+				Flags: toArray(controlFlag, beaconIDFlag, hashOnly, chainInfoFormatFlag, pinURLFlag,
+					expectHashFlag),
 				Action: showChainInfo,
 			},
 			{
 				Name:   "private",
 				Usage:  "shows the long-term private key of a node.\n",
-				Flags:  toArray(controlFlag),
+				Flags:  toArray(controlFlag, beaconIDFlag),
 				Action: showPrivateCmd,
 			},
 			{
 				Name:   "public",
 				Usage:  "shows the long-term public key of a node.\n",
-				Flags:  toArray(controlFlag),
+				Flags:  toArray(controlFlag, beaconIDFlag),
 				Action: showPublicCmd,
 			},
 		},
@@ -461,12 +1011,12 @@ func resetCmd(c *cli.Context) error {
 		fmt.Fprintf(output, "drand: not reseting the state.")
 		return nil
 	}
-	store := key.NewFileStore(conf.ConfigFolder())
+	store := key.NewFileStore(beaconFolder(c, conf))
 	if err := store.Reset(); err != nil {
 		fmt.Fprintf(output, "drand: err reseting key store: %v\n", err)
 		os.Exit(1)
 	}
-	if err := os.RemoveAll(conf.DBFolder()); err != nil {
+	if err := os.RemoveAll(beaconDBFolder(c, conf)); err != nil {
 		fmt.Fprintf(output, "drand: err reseting beacons database: %v\n", err)
 		os.Exit(1)
 	}
@@ -506,31 +1056,38 @@ func keygenCmd(c *cli.Context) error {
 		return errors.New("missing drand address in argument. Abort")
 	}
 	addr := args.First()
+	scheme, err := keyScheme(c.String(schemeFlag.Name))
+	if err != nil {
+		return err
+	}
 	var validID = regexp.MustCompile(`:\d+$`)
 	if !validID.MatchString(addr) {
 		fmt.Println("Invalid port.")
 		addr = addr + ":" + askPort()
 	}
-	var priv *key.Pair
+	priv, err := key.NewSchemeKeyPair(addr, scheme)
+	if err != nil {
+		return err
+	}
 	if c.Bool(insecureFlag.Name) {
 		fmt.Println("Generating private / public key pair without TLS.")
-		priv = key.NewKeyPair(addr)
 	} else {
 		fmt.Println("Generating private / public key pair with TLS indication")
-		priv = key.NewTLSKeyPair(addr)
+		priv.Public.TLS = true
+		priv.SelfSign()
 	}
 
-	config := contextToConfig(c)
-	fileStore := key.NewFileStore(config.ConfigFolder())
+	folder := beaconFolder(c, contextToConfig(c))
+	fileStore := key.NewFileStore(folder)
 
 	if _, err := fileStore.LoadKeyPair(); err == nil {
-		fmt.Fprintf(output, "Keypair already present in `%s`.\nRemove them before generating new one\n", config.ConfigFolder())
+		fmt.Fprintf(output, "Keypair already present in `%s`.\nRemove them before generating new one\n", folder)
 		return nil
 	}
 	if err := fileStore.SaveKeyPair(priv); err != nil {
 		return fmt.Errorf("could not save key: %s", err)
 	}
-	fullpath := path.Join(config.ConfigFolder(), key.KeyFolderName)
+	fullpath := path.Join(folder, key.KeyFolderName)
 	absPath, err := filepath.Abs(fullpath)
 	if err != nil {
 		return fmt.Errorf("err getting full path: %s", err)
@@ -545,6 +1102,19 @@ func keygenCmd(c *cli.Context) error {
 	return nil
 }
 
+// keyScheme checks the scheme with the given ID is known and returns the ID
+// recorded with the key: empty for the default scheme.
+func keyScheme(id string) (string, error) {
+	s, err := chain.SchemeFromID(id)
+	if err != nil {
+		return "", fmt.Errorf("%v, the schemes available are %s", err, strings.Join(chain.SchemeIDs(), ", "))
+	}
+	if s.ID() == chain.DefaultSchemeID {
+		return "", nil
+	}
+	return s.ID(), nil
+}
+
 func groupOut(c *cli.Context, group *key.Group) error {
 	if c.IsSet("out") {
 		groupPath := c.String("out")
@@ -646,6 +1216,353 @@ func checkIdentityAddress(conf *core.Config, addr string, tls bool) error {
 	return nil
 }
 
+// migrateDBCmd copies the beacon database to another storage engine
+func migrateDBCmd(c *cli.Context) error {
+	if c.String(toDBFlag.Name) == c.String(dbFlag.Name) && c.String(toDBURLFlag.Name) == c.String(dbURLFlag.Name) {
+		return errors.New("the destination database is the database of the node")
+	}
+	conf := contextToConfig(c)
+	src, err := conf.OpenStore()
+	if err != nil {
+		return fmt.Errorf("can't open the database: %s", err)
+	}
+	defer src.Close()
+	dst, err := core.OpenStore(c.String(toDBFlag.Name), conf.DBFolder(), c.String(toDBURLFlag.Name), nil)
+	if err != nil {
+		return fmt.Errorf("can't open the destination database: %s", err)
+	}
+	defer dst.Close()
+	if dst.Len() > 0 {
+		return errors.New("the destination database is not empty")
+	}
+	n, err := chain.CopyStore(dst, src)
+	if err != nil {
+		return fmt.Errorf("migration failed after %d beacons: %s", n, err)
+	}
+	fmt.Fprintf(output, "Migrated %d beacons to the %s database. Restart the daemon with --db %s.\n",
+		n, c.String(toDBFlag.Name), c.String(toDBFlag.Name))
+	return nil
+}
+
+// snapshotChainInfo returns the chain info of the group of the node, or nil if
+// the node has no group.
+func snapshotChainInfo(conf *core.Config) *chain.Info {
+	group, err := key.NewFileStore(conf.ConfigFolder()).LoadGroup()
+	if err != nil {
+		return nil
+	}
+	return chain.NewChainInfo(group)
+}
+
+// checkSnapshotHash returns an error if the snapshot info does not match the
+// chain hash flag, when set.
+func checkSnapshotHash(c *cli.Context, info *chain.Info) error {
+	if !c.IsSet(snapshotHashFlag.Name) {
+		return nil
+	}
+	hash, err := hex.DecodeString(c.String(snapshotHashFlag.Name))
+	if err != nil {
+		return fmt.Errorf("invalid chain hash: %s", err)
+	}
+	if !bytes.Equal(hash, info.Hash()) {
+		return fmt.Errorf("snapshot of the chain %x instead of %x", info.Hash(), hash)
+	}
+	return nil
+}
+
+func exportSnapshotCmd(c *cli.Context) error {
+	if c.Args().Len() != 1 {
+		return errors.New("export-snapshot needs the path of the snapshot file")
+	}
+	conf := contextToConfig(c)
+	info := snapshotChainInfo(conf)
+	if info == nil {
+		return errors.New("can't load the group of the node")
+	}
+	store, err := conf.OpenStore()
+	if err != nil {
+		return fmt.Errorf("can't open the database: %s", err)
+	}
+	defer store.Close()
+	file, err := os.Create(c.Args().First())
+	if err != nil {
+		return err
+	}
+	n, err := chain.ExportSnapshot(file, info, store, c.Uint64(snapshotFromFlag.Name), c.Uint64(snapshotToFlag.Name))
+	if err != nil {
+		file.Close()
+		return fmt.Errorf("export failed after %d beacons: %s", n, err)
+	}
+	if err := file.Close(); err != nil {
+		return err
+	}
+	fmt.Fprintf(output, "Exported %d beacons of the chain %x.\n", n, info.Hash())
+	return nil
+}
+
+func importSnapshotCmd(c *cli.Context) error {
+	if c.Args().Len() != 1 {
+		return errors.New("import-snapshot needs the path of the snapshot file")
+	}
+	conf := contextToConfig(c)
+	trusted := snapshotChainInfo(conf)
+	if trusted == nil && !c.IsSet(snapshotHashFlag.Name) {
+		return errors.New("the node has no group, the chain hash of the snapshot must be given")
+	}
+	file, err := os.Open(c.Args().First())
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	sr, err := chain.NewSnapshotReader(file)
+	if err != nil {
+		return err
+	}
+	if err := checkSnapshotHash(c, sr.Info); err != nil {
+		return err
+	}
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+	store, err := conf.OpenStore()
+	if err != nil {
+		return fmt.Errorf("can't open the database: %s", err)
+	}
+	defer store.Close()
+	info, n, err := chain.ImportSnapshot(store, file, trusted)
+	if err != nil {
+		return fmt.Errorf("import failed after %d beacons: %s", n, err)
+	}
+	fmt.Fprintf(output, "Imported %d beacons of the chain %x.\n", n, info.Hash())
+	return nil
+}
+
+func verifySnapshotCmd(c *cli.Context) error {
+	if c.Args().Len() != 1 {
+		return errors.New("verify-snapshot needs the path of the snapshot file")
+	}
+	file, err := os.Open(c.Args().First())
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	sr, err := chain.NewSnapshotReader(file)
+	if err != nil {
+		return err
+	}
+	if err := checkSnapshotHash(c, sr.Info); err != nil {
+		return err
+	}
+	report, err := chain.VerifyChain(sr.Info, sr)
+	if err != nil {
+		return err
+	}
+	if report.Inconsistency != nil {
+		return fmt.Errorf("invalid snapshot: %s", report.Inconsistency)
+	}
+	fmt.Fprintf(output, "Snapshot of the chain %x created at %s: rounds %d to %d, %d beacons verified.\n",
+		sr.Info.Hash(), time.Unix(sr.Header.Created, 0), report.First, report.Last, report.Verified)
+	return nil
+}
+
+// backupBucket opens the bucket of the backup url flag, and returns it with
+// the prefix of the backups.
+func backupBucket(c *cli.Context) (backup.Bucket, string, error) {
+	if !c.IsSet(backupURLFlag.Name) {
+		return nil, "", errors.New("no backup url given")
+	}
+	return backup.OpenBucket(c.String(backupURLFlag.Name), backup.S3Options{
+		Region:    c.String(backupRegionFlag.Name),
+		Endpoint:  c.String(backupEndpointFlag.Name),
+		PathStyle: c.Bool(backupPathStyleFlag.Name),
+	})
+}
+
+// backupPassphrase reads the passphrase file of the backups, if given.
+func backupPassphrase(c *cli.Context) ([]byte, error) {
+	if !c.IsSet(backupPassphraseFlag.Name) {
+		return nil, nil
+	}
+	buff, err := ioutil.ReadFile(c.String(backupPassphraseFlag.Name))
+	if err != nil {
+		return nil, fmt.Errorf("can't read the backup passphrase: %s", err)
+	}
+	passphrase := bytes.TrimRight(buff, "\r\n")
+	if len(passphrase) == 0 {
+		return nil, errors.New("the backup passphrase is empty")
+	}
+	return passphrase, nil
+}
+
+func backupConfig(c *cli.Context) (*backup.Config, error) {
+	bucket, prefix, err := backupBucket(c)
+	if err != nil {
+		return nil, err
+	}
+	passphrase, err := backupPassphrase(c)
+	if err != nil {
+		return nil, err
+	}
+	return &backup.Config{
+		Bucket:     bucket,
+		Prefix:     prefix,
+		Interval:   c.Duration(backupIntervalFlag.Name),
+		Keep:       c.Int(backupKeepFlag.Name),
+		MaxAge:     c.Duration(backupMaxAgeFlag.Name),
+		Passphrase: passphrase,
+	}, nil
+}
+
+// backupPrefix returns the prefix of the backups of the beacon given by the
+// beacon ID flag, as the daemon stores them.
+func backupPrefix(c *cli.Context, prefix string) string {
+	if id := c.String(beaconIDFlag.Name); id != "" {
+		return path.Join(prefix, id)
+	}
+	return prefix
+}
+
+func listBackupsCmd(c *cli.Context) error {
+	bucket, prefix, err := backupBucket(c)
+	if err != nil {
+		return err
+	}
+	backups, err := backup.List(context.Background(), bucket, backupPrefix(c, prefix))
+	if err != nil {
+		return fmt.Errorf("can't list the backups: %s", err)
+	}
+	return printJSON(backups)
+}
+
+// cliActor is the actor of the audit log of the commands run offline: the
+// user running them.
+func cliActor() string {
+	if u, err := user.Current(); err == nil {
+		return "user:" + u.Username
+	}
+	return "user:" + strconv.Itoa(os.Getuid())
+}
+
+func restoreBackupCmd(c *cli.Context) (err error) {
+	bucket, prefix, err := backupBucket(c)
+	if err != nil {
+		return err
+	}
+	prefix = backupPrefix(c, prefix)
+	ctx := context.Background()
+	bk, err := backup.Find(ctx, bucket, prefix, c.Args().First())
+	if err != nil {
+		return err
+	}
+	conf := contextToConfig(c)
+	folder := beaconFolder(c, conf)
+	event := audit.Event{
+		Action:   audit.ActionRestore,
+		Actor:    cliActor(),
+		BeaconID: c.String(beaconIDFlag.Name),
+		Details:  map[string]string{"name": bk.Name, "keys": strconv.FormatBool(bk.Keys)},
+	}
+	defer func() {
+		event.Outcome = audit.Outcome(err)
+		if err != nil {
+			event.Error = err.Error()
+		}
+		conf.AuditLog().Record(event)
+	}()
+	if bk.Manifest || !c.Bool(skipManifestFlag.Name) {
+		manifest, err := backup.Verify(ctx, bucket, prefix, bk)
+		if err != nil {
+			return fmt.Errorf("the backup is not intact: %s", err)
+		}
+		fmt.Fprintf(output, "Backup %s matches its manifest: %d beacons of the chain %s.\n",
+			bk.Name, manifest.Beacons, manifest.ChainHash)
+	}
+	if bk.Keys && !c.Bool(chainOnlyFlag.Name) {
+		passphrase, err := backupPassphrase(c)
+		if err != nil {
+			return err
+		}
+		if passphrase == nil {
+			return errors.New("the backup holds key material, the backup passphrase must be given")
+		}
+		files, err := backup.RestoreKeys(ctx, bucket, prefix, bk, passphrase, folder)
+		if err != nil {
+			return fmt.Errorf("can't restore the key material: %s", err)
+		}
+		for _, f := range files {
+			fmt.Fprintf(output, "Restored %s\n", f)
+		}
+	}
+	// the restored group is trusted to verify the beacons
+	group, err := key.NewFileStore(folder).LoadGroup()
+	if err != nil {
+		return fmt.Errorf("can't load the group of the node: %s", err)
+	}
+	dbFolder := path.Join(folder, core.DefaultDBFolder)
+	fs.CreateSecureFolder(dbFolder)
+	store, err := core.OpenStore(c.String(dbFlag.Name), dbFolder, c.String(dbURLFlag.Name), nil)
+	if err != nil {
+		return fmt.Errorf("can't open the database: %s", err)
+	}
+	defer store.Close()
+	n, err := backup.RestoreChain(ctx, bucket, prefix, bk, store, chain.NewChainInfo(group))
+	if err != nil {
+		return fmt.Errorf("can't restore the beacons after %d beacons: %s", n, err)
+	}
+	fmt.Fprintf(output, "Restored %d beacons of the backup %s.\n", n, bk.Name)
+	return nil
+}
+
+func backupNodeCmd(c *cli.Context) (err error) {
+	bucket, prefix, err := backupBucket(c)
+	if err != nil {
+		return err
+	}
+	prefix = backupPrefix(c, prefix)
+	passphrase, err := backupPassphrase(c)
+	if err != nil {
+		return err
+	}
+	conf := contextToConfig(c)
+	folder := beaconFolder(c, conf)
+	group, err := key.NewFileStore(folder).LoadGroup()
+	if err != nil {
+		return fmt.Errorf("can't load the group of the node: %s", err)
+	}
+	event := audit.Event{
+		Action:   audit.ActionBackup,
+		Actor:    cliActor(),
+		BeaconID: c.String(beaconIDFlag.Name),
+		Details:  map[string]string{"prefix": prefix},
+	}
+	defer func() {
+		event.Outcome = audit.Outcome(err)
+		if err != nil {
+			event.Error = err.Error()
+		}
+		conf.AuditLog().Record(event)
+	}()
+	// fail rather than wait for the database of a running daemon
+	store, err := core.OpenStore(c.String(dbFlag.Name), path.Join(folder, core.DefaultDBFolder),
+		c.String(dbURLFlag.Name), &bolt.Options{Timeout: time.Second})
+	if err != nil {
+		return fmt.Errorf("can't open the database, the daemon must be stopped: %s", err)
+	}
+	defer store.Close()
+	bk, n, err := backup.Create(context.Background(), bucket, prefix, folder, store, chain.NewChainInfo(group),
+		passphrase, time.Now())
+	if err != nil {
+		return fmt.Errorf("can't back up the node: %s", err)
+	}
+	event.Details["name"] = bk.Name
+	event.Details["keys"] = strconv.FormatBool(bk.Keys)
+	fmt.Fprintf(output, "Backed up %d beacons as the backup %s.\n", n, bk.Name)
+	if !bk.Keys {
+		fmt.Fprintln(output, "The key material is not backed up, as no backup passphrase was given.")
+	}
+	return nil
+}
+
 // deleteBeaconCmd deletes all beacon in the database from the given round until
 // the head of the chain
 func deleteBeaconCmd(c *cli.Context) error {
@@ -656,9 +1573,9 @@ func deleteBeaconCmd(c *cli.Context) error {
 		return fmt.Errorf("given round not valid: %d", sr)
 	}
 	startRound := uint64(sr)
-	store, err := boltdb.NewBoltStore(conf.DBFolder(), conf.BoltOptions())
+	store, err := conf.OpenStore()
 	if err != nil {
-		return fmt.Errorf("invalid bolt store creation: %s", err)
+		return fmt.Errorf("invalid store creation: %s", err)
 	}
 	defer store.Close()
 	lastBeacon, err := store.Last()
@@ -683,6 +1600,63 @@ func deleteBeaconCmd(c *cli.Context) error {
 	return nil
 }
 
+// beaconFolder returns the configuration folder of the beacon given by the
+// beacon ID flag.
+func beaconFolder(c *cli.Context, conf *core.Config) string {
+	if id := c.String(beaconIDFlag.Name); id != "" {
+		return path.Join(conf.ConfigFolder(), core.MultiBeaconFolder, id)
+	}
+	return conf.ConfigFolder()
+}
+
+// beaconDBFolder returns the folder of the database of the beacon given by the
+// --id flag, or of the default beacon.
+func beaconDBFolder(c *cli.Context, conf *core.Config) string {
+	if c.String(beaconIDFlag.Name) != "" {
+		return path.Join(beaconFolder(c, conf), core.DefaultDBFolder)
+	}
+	return conf.DBFolder()
+}
+
+func showDKGStateCmd(c *cli.Context) error {
+	conf := contextToConfig(c)
+	st, err := core.LoadDKGState(beaconFolder(c, conf))
+	if err != nil {
+		return fmt.Errorf("drand: can't load DKG state: %s", err)
+	}
+	if st == nil {
+		fmt.Fprintln(output, "drand: no DKG in progress")
+		return nil
+	}
+	group, _, err := st.Groups()
+	if err != nil {
+		return fmt.Errorf("drand: invalid DKG state: %s", err)
+	}
+	kind := "dkg"
+	if st.Reshare {
+		kind = "resharing"
+	}
+	fmt.Fprintf(output, "type: %s\n", kind)
+	fmt.Fprintf(output, "leader: %v\n", st.Leader)
+	fmt.Fprintf(output, "target group: %x (%d nodes, threshold %d)\n", group.Hash(), group.Len(), group.Threshold)
+	if deadline := st.Deadline(); deadline.IsZero() {
+		fmt.Fprintln(output, "phases: waiting for the leader")
+	} else {
+		fmt.Fprintf(output, "phases: started, ending at %s\n", deadline.Format(time.RFC3339))
+	}
+	fmt.Fprintf(output, "packets received: %d\n", len(st.Packets))
+	return nil
+}
+
+func abortDKGCmd(c *cli.Context) error {
+	conf := contextToConfig(c)
+	if err := core.AbortDKG(beaconFolder(c, conf)); err != nil {
+		return fmt.Errorf("drand: can't abort DKG: %s", err)
+	}
+	fmt.Fprintln(output, "drand: DKG state deleted")
+	return nil
+}
+
 func toArray(flags ...cli.Flag) []cli.Flag {
 	return flags
 }
@@ -702,23 +1676,20 @@ func getGroup(c *cli.Context) (*key.Group, error) {
 func contextToConfig(c *cli.Context) *core.Config {
 	var opts []core.ConfigOption
 
-	if c.IsSet(verboseFlag.Name) {
-		opts = append(opts, core.WithLogLevel(log.LogDebug))
-	} else {
-		opts = append(opts, core.WithLogLevel(log.LogInfo))
+	settings, err := daemonSettings(c)
+	if err != nil {
+		panic(err)
 	}
+	level, _ := log.ParseLevel(settings.LogLevel)
+	opts = append(opts, core.WithLogLevel(level))
 
-	if c.IsSet(pubListenFlag.Name) {
-		opts = append(opts, core.WithPublicListenAddress(c.String(pubListenFlag.Name)))
-	}
-	if c.IsSet(privListenFlag.Name) {
-		opts = append(opts, core.WithPrivateListenAddress(c.String(privListenFlag.Name)))
+	if settings.PublicListen != "" {
+		opts = append(opts, core.WithPublicListenAddress(settings.PublicListen))
 	}
-
-	port := c.String(controlFlag.Name)
-	if port != "" {
-		opts = append(opts, core.WithControlPort(port))
+	if settings.PrivateListen != "" {
+		opts = append(opts, core.WithPrivateListenAddress(settings.PrivateListen))
 	}
+	opts = append(opts, core.WithControlPort(settings.Control))
 	if c.IsSet(folderFlag.Name) {
 		opts = append(opts, core.WithConfigFolder(c.String(folderFlag.Name)))
 	}
@@ -732,6 +1703,23 @@ func contextToConfig(c *cli.Context) *core.Config {
 	} else {
 		certPath, keyPath := c.String("tls-cert"), c.String("tls-key")
 		opts = append(opts, core.WithTLS(certPath, keyPath))
+		if c.IsSet(mtlsCAFlag.Name) {
+			m, err := net.NewMTLS(certPath, keyPath, c.String(mtlsCAFlag.Name))
+			if err != nil {
+				panic(err)
+			}
+			go m.Watch(context.Background(), c.Duration(mtlsReloadFlag.Name))
+			opts = append(opts, core.WithMTLS(m))
+		}
+		if c.IsSet(acmeDomainFlag.Name) {
+			opts = append(opts, core.WithACME(&net.ACME{
+				Domains:      c.StringSlice(acmeDomainFlag.Name),
+				CacheDir:     path.Join(c.String(folderFlag.Name), "acme"),
+				Email:        c.String(acmeEmailFlag.Name),
+				DirectoryURL: c.String(acmeDirectoryFlag.Name),
+				HTTPAddr:     c.String(acmeHTTPFlag.Name),
+			}))
+		}
 	}
 	if c.IsSet("certs-dir") {
 		paths, err := fs.Files(c.String("certs-dir"))
@@ -740,9 +1728,56 @@ func contextToConfig(c *cli.Context) *core.Config {
 		}
 		opts = append(opts, core.WithTrustedCerts(paths...))
 	}
+	if c.IsSet(controlTokensFlag.Name) {
+		a, err := net.LoadControlAuth(c.String(controlTokensFlag.Name))
+		if err != nil {
+			panic(err)
+		}
+		opts = append(opts, core.WithControlAuth(a))
+	}
+	if c.IsSet(auditLogFlag.Name) {
+		a, err := audit.Open(c.String(auditLogFlag.Name))
+		if err != nil {
+			panic(err)
+		}
+		opts = append(opts, core.WithAuditLog(a))
+	}
+	if c.IsSet(proxyFlag.Name) {
+		d, err := net.NewProxyDialer(c.String(proxyFlag.Name))
+		if err != nil {
+			panic(err)
+		}
+		opts = append(opts, core.WithDialer(d))
+	}
 	if c.Bool(enablePrivateRand.Name) {
 		opts = append(opts, core.WithPrivateRandomness())
 	}
+	if c.IsSet(dkgRetriesFlag.Name) {
+		opts = append(opts, core.WithDKGRetries(c.Int(dkgRetriesFlag.Name)))
+	}
+	if c.IsSet(dbFlag.Name) || c.IsSet(dbURLFlag.Name) {
+		opts = append(opts, core.WithDBEngine(c.String(dbFlag.Name), c.String(dbURLFlag.Name)))
+	}
+	if c.IsSet(keepRoundsFlag.Name) || c.IsSet(keepForFlag.Name) {
+		opts = append(opts, core.WithRetention(beacon.Retention{
+			Rounds:   c.Uint64(keepRoundsFlag.Name),
+			Duration: c.Duration(keepForFlag.Name),
+		}))
+	}
+	if c.IsSet(backupURLFlag.Name) {
+		b, err := backupConfig(c)
+		if err != nil {
+			panic(err)
+		}
+		opts = append(opts, core.WithBackup(b))
+	}
+	if c.IsSet(remoteSignerFlag.Name) {
+		s, err := dialSigner(c)
+		if err != nil {
+			panic(err)
+		}
+		opts = append(opts, core.WithSigner(s))
+	}
 	conf := core.NewConfig(opts...)
 	return conf
 }
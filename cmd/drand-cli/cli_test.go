@@ -7,6 +7,8 @@ import (
 	"fmt"
 	"io/ioutil"
 	gnet "net"
+	nhttp "net/http"
+	"net/http/httptest"
 	"os"
 	"path"
 	"strconv"
@@ -19,12 +21,15 @@ import (
 	"github.com/BurntSushi/toml"
 	"github.com/drand/drand/chain"
 	"github.com/drand/drand/chain/boltdb"
+	"github.com/drand/drand/client"
 	"github.com/drand/drand/core"
 	"github.com/drand/drand/fs"
 	"github.com/drand/drand/key"
+	"github.com/drand/drand/log"
 	"github.com/drand/drand/test"
 	"github.com/drand/kyber"
 	"github.com/drand/kyber/share"
+	dkg "github.com/drand/kyber/share/dkg"
 	"github.com/drand/kyber/util/random"
 	"github.com/kabukky/httpscerts"
 
@@ -129,6 +134,75 @@ func TestKeyGen(t *testing.T) {
 	require.Nil(t, priv)
 }
 
+func TestKeyGenScheme(t *testing.T) {
+	tmp, err := ioutil.TempDir("", "drand-scheme")
+	require.NoError(t, err)
+	defer os.RemoveAll(tmp)
+	args := []string{"drand", "generate-keypair", "--folder", tmp, "--scheme", chain.UnchainedSchemeID, "127.0.0.1:8081"}
+	require.NoError(t, CLI().Run(args))
+
+	fileStore := key.NewFileStore(core.NewConfig(core.WithConfigFolder(tmp)).ConfigFolder())
+	priv, err := fileStore.LoadKeyPair()
+	require.NoError(t, err)
+	require.Equal(t, chain.UnchainedSchemeID, priv.Public.Scheme)
+
+	// the keys of the BN254 scheme are on the G2 group of its curve
+	tmp3, err := ioutil.TempDir("", "drand-scheme")
+	require.NoError(t, err)
+	defer os.RemoveAll(tmp3)
+	args = []string{"drand", "generate-keypair", "--folder", tmp3, "--scheme", chain.BN254UnchainedOnG1SchemeID, "127.0.0.1:8081"}
+	require.NoError(t, CLI().Run(args))
+	priv, err = key.NewFileStore(core.NewConfig(core.WithConfigFolder(tmp3)).ConfigFolder()).LoadKeyPair()
+	require.NoError(t, err)
+	require.Equal(t, chain.BN254UnchainedOnG1SchemeID, priv.Public.Scheme)
+	require.Equal(t, key.BN254Suite.KeyGroup.PointLen(), priv.Public.Key.MarshalSize())
+	require.NoError(t, priv.Public.ValidSignature())
+
+	tmp2, err := ioutil.TempDir("", "drand-scheme")
+	require.NoError(t, err)
+	defer os.RemoveAll(tmp2)
+	args = []string{"drand", "generate-keypair", "--folder", tmp2, "--scheme", "unknown", "127.0.0.1:8081"}
+	require.Error(t, CLI().Run(args))
+}
+
+func TestKeyGenBeaconID(t *testing.T) {
+	tmp, err := ioutil.TempDir("", "drand-beacon-id")
+	require.NoError(t, err)
+	defer os.RemoveAll(tmp)
+	args := []string{"drand", "generate-keypair", "--folder", tmp, "127.0.0.1:8081"}
+	require.NoError(t, CLI().Run(args))
+	args = []string{"drand", "generate-keypair", "--folder", tmp, "--id", "other", "127.0.0.1:8082"}
+	require.NoError(t, CLI().Run(args))
+
+	beacon := path.Join(tmp, core.MultiBeaconFolder, "other")
+	priv, err := key.NewFileStore(beacon).LoadKeyPair()
+	require.NoError(t, err)
+	require.Equal(t, "127.0.0.1:8082", priv.Public.Address())
+	priv, err = key.NewFileStore(tmp).LoadKeyPair()
+	require.NoError(t, err)
+	require.Equal(t, "127.0.0.1:8081", priv.Public.Address())
+
+	// resetting the beacon leaves the database of the default beacon
+	defaultDB := path.Join(tmp, core.DefaultDBFolder)
+	beaconDB := path.Join(beacon, core.DefaultDBFolder)
+	require.NoError(t, os.MkdirAll(defaultDB, 0740))
+	require.NoError(t, os.MkdirAll(beaconDB, 0740))
+	r, w, err := os.Pipe()
+	require.NoError(t, err)
+	_, err = w.Write([]byte("y\n"))
+	require.NoError(t, err)
+	stdin := os.Stdin
+	defer func() { os.Stdin = stdin }()
+	os.Stdin = r
+	require.NoError(t, CLI().Run([]string{"drand", "util", "reset", "--folder", tmp, "--id", "other"}))
+	exists, err := fs.Exists(defaultDB)
+	require.NoError(t, err)
+	require.True(t, exists)
+	exists, err = fs.Exists(beaconDB)
+	require.NoError(t, err)
+	require.False(t, exists)
+}
+
 // tests valid commands and then invalid commands
 func TestStartAndStop(t *testing.T) {
 	tmpPath := path.Join(os.TempDir(), "drand")
@@ -267,7 +341,7 @@ func TestStartWithoutGroup(t *testing.T) {
 	// fake share
 	scalarOne := key.KeyGroup.Scalar().One()
 	s := &share.PriShare{I: 2, V: scalarOne}
-	fakeShare := &key.Share{Share: s}
+	fakeShare := &key.Share{DistKeyShare: dkg.DistKeyShare{Share: s}}
 	require.NoError(t, fileStore.SaveShare(fakeShare))
 
 	fmt.Println(" --- DRAND START --- control ", ctrlPort2)
@@ -329,6 +403,17 @@ func testStartedDrandFunctional(t *testing.T, ctrlPort, rootPath, address string
 	expectedOutput = fmt.Sprintf("%x", chain.NewChainInfo(group).Hash())
 	testCommand(t, showChainInfo, expectedOutput)
 
+	showChainInfo = []string{"drand", "show", "chain-info", "--format", "toml", "--expect-hash", expectedOutput,
+		"--control", ctrlPort}
+	testCommand(t, showChainInfo, fmt.Sprintf("Hash = %q", expectedOutput))
+	showChainInfo = []string{"drand", "show", "chain-info", "--format", "pin", "--url", "https://relay.test",
+		"--control", ctrlPort}
+	testCommand(t, showChainInfo, expectedOutput+"@https://relay.test")
+	showChainInfo = []string{"drand", "show", "chain-info", "--expect-hash", "00", "--control", ctrlPort}
+	require.Error(t, CLI().Run(showChainInfo))
+	showChainInfo = []string{"drand", "show", "chain-info", "--format", "pin", "--control", ctrlPort}
+	require.Error(t, CLI().Run(showChainInfo))
+
 	// reset state
 	resetCmd := []string{"drand", "util", "reset", "--folder", rootPath}
 	r, w, err := os.Pipe()
@@ -394,7 +479,7 @@ func TestClientTLS(t *testing.T) {
 	// fake share
 	scalarOne := key.KeyGroup.Scalar().One()
 	s := &share.PriShare{I: 2, V: scalarOne}
-	fakeShare := &key.Share{Share: s}
+	fakeShare := &key.Share{DistKeyShare: dkg.DistKeyShare{Share: s}}
 	fileStore.SaveShare(fakeShare)
 
 	startArgs := []string{
@@ -472,3 +557,167 @@ func testCommand(t *testing.T, args []string, exp string) {
 	fmt.Println("CONTAINS: ", strings.Contains(strings.Trim(buff.String(), "\n"), exp))
 	require.True(t, strings.Contains(strings.Trim(buff.String(), "\n"), exp))
 }
+
+func TestReloadConfig(t *testing.T) {
+	tmp, err := ioutil.TempDir("", "drand-reload")
+	require.NoError(t, err)
+	defer os.RemoveAll(tmp)
+	file := path.Join(tmp, "drand.toml")
+
+	conf := core.NewConfig(core.WithLogLevel(log.LogInfo))
+	r := &reloader{
+		path:    file,
+		conf:    conf,
+		running: &daemonConfig{LogLevel: "info", Control: core.DefaultControlPort},
+	}
+	require.NoError(t, ioutil.WriteFile(file, []byte("log_level = \"debug\"\ncontrol = \"9999\"\n"), 0600))
+	report, err := r.reload()
+	require.NoError(t, err)
+	require.Equal(t, []string{"log_level"}, report.Applied)
+	require.Equal(t, []string{"control"}, report.RestartRequired)
+	require.Equal(t, "debug", r.running.LogLevel)
+
+	report, err = r.reload()
+	require.NoError(t, err)
+	require.Empty(t, report.Applied)
+
+	for _, invalid := range []string{"log_level = \"loud\"", "unknown = 1", "public_listen = \"nowhere\""} {
+		require.NoError(t, ioutil.WriteFile(file, []byte(invalid), 0600))
+		_, err = r.reload()
+		require.Error(t, err, invalid)
+	}
+	require.Equal(t, "debug", r.running.LogLevel)
+}
+
+func TestUtilRoundAndTime(t *testing.T) {
+	tmp, err := ioutil.TempDir("", "drand-round")
+	require.NoError(t, err)
+	defer os.RemoveAll(tmp)
+
+	info := &chain.Info{
+		PublicKey:   test.GenerateIDs(1)[0].Public.Key,
+		Period:      30 * time.Second,
+		GenesisTime: 1595431050,
+		GroupHash:   []byte("group"),
+	}
+	file := path.Join(tmp, "info.json")
+	f, err := os.Create(file)
+	require.NoError(t, err)
+	require.NoError(t, info.ToJSON(f))
+	require.NoError(t, f.Close())
+
+	testCommand(t, []string{"drand", "util", "round-at", "--chain-info", file, "2020-07-22T15:18:00Z"}, "2")
+	testCommand(t, []string{"drand", "util", "round-at", "--chain-info", file, "1595431110"}, "3")
+	testCommand(t, []string{"drand", "util", "time-of", "--chain-info", file, "3"}, "2020-07-22T15:18:30Z")
+
+	require.Error(t, CLI().Run([]string{"drand", "util", "round-at", "--chain-info", file, "2020-01-01T00:00:00Z"}))
+	require.Error(t, CLI().Run([]string{"drand", "util", "time-of", "--chain-info", file, "zero"}))
+}
+
+// testEndpoint serves the first rounds of a new chain over HTTP, as a relay.
+func testEndpoint(t *testing.T, period time.Duration, rounds uint64) (*httptest.Server, *chain.Info) {
+	priv := key.KeyGroup.Scalar().Pick(random.New())
+	info := &chain.Info{
+		PublicKey:   key.KeyGroup.Point().Mul(priv, nil),
+		Period:      period,
+		GenesisTime: time.Now().Add(-time.Duration(rounds-1) * period).Unix(),
+		GroupHash:   []byte("group"),
+	}
+	beacons := make(map[string]*client.RandomData)
+	prev := chain.GenesisBeacon(info).Signature
+	for round := uint64(1); round <= rounds; round++ {
+		sig, err := key.AuthScheme.Sign(priv, chain.Message(round, prev))
+		require.NoError(t, err)
+		randomness, err := info.Randomness(sig)
+		require.NoError(t, err)
+		beacons[strconv.FormatUint(round, 10)] = &client.RandomData{
+			Rnd: round, Random: randomness, Sig: sig, PreviousSignature: prev,
+		}
+		prev = sig
+	}
+	beacons["latest"] = beacons[strconv.FormatUint(rounds, 10)]
+
+	mux := nhttp.NewServeMux()
+	mux.HandleFunc("/info", func(w nhttp.ResponseWriter, r *nhttp.Request) {
+		_ = info.ToJSON(w)
+	})
+	mux.HandleFunc("/public/", func(w nhttp.ResponseWriter, r *nhttp.Request) {
+		b, ok := beacons[strings.TrimPrefix(r.URL.Path, "/public/")]
+		if !ok {
+			nhttp.NotFound(w, r)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(b)
+	})
+	return httptest.NewServer(mux), info
+}
+
+func TestCheckEndpoints(t *testing.T) {
+	ctx := context.Background()
+	srv, info := testEndpoint(t, time.Minute, 3)
+	defer srv.Close()
+	other, _ := testEndpoint(t, time.Minute, 3)
+	defer other.Close()
+
+	report := checkEndpoints(ctx, nhttp.DefaultClient, []string{srv.URL, srv.URL + "/"}, nil, 2, time.Now)
+	require.True(t, report.Pass)
+	require.Equal(t, hex.EncodeToString(info.Hash()), report.ChainHash)
+	require.Len(t, report.Endpoints[0].Checks, 4)
+
+	// an endpoint of another chain fails
+	report = checkEndpoints(ctx, nhttp.DefaultClient, []string{srv.URL, other.URL}, nil, 1, time.Now)
+	require.False(t, report.Pass)
+	require.True(t, report.Endpoints[0].Pass)
+	require.False(t, report.Endpoints[1].Pass)
+
+	// so does a lagging endpoint, or a missing round
+	later := func() time.Time { return time.Now().Add(3 * time.Minute) }
+	report = checkEndpoints(ctx, nhttp.DefaultClient, []string{srv.URL}, info.Hash(), 1, later)
+	require.False(t, report.Pass)
+	report = checkEndpoints(ctx, nhttp.DefaultClient, []string{srv.URL}, info.Hash(), 10, time.Now)
+	require.False(t, report.Pass)
+
+	require.NoError(t, CLI().Run([]string{"drand", "check", srv.URL}))
+	require.Error(t, CLI().Run([]string{"drand", "check", "--expect-hash", hex.EncodeToString(info.Hash()), other.URL}))
+}
+
+func TestDKGStatusReport(t *testing.T) {
+	now := time.Now()
+	status := &core.DKGStatus{
+		Running:  true,
+		Reshare:  true,
+		Phase:    core.PhaseResponse,
+		PhaseEnd: now.Add(90 * time.Second),
+		Timeouts: core.PhaseTimeouts{Deal: time.Minute, Response: 2 * time.Minute, Justification: time.Minute},
+		Participants: []*core.ParticipantStatus{
+			{Address: "a:1", Dealer: true, Holder: true, Deal: true, Response: true},
+			{Address: "b:2", Dealer: true, Deal: false},
+			{Address: "c:3", Holder: true, SendFailures: 2, LastError: "unreachable"},
+		},
+	}
+	report := newDKGStatusReport(status, now)
+	require.Equal(t, int64(90), report.Remaining)
+	require.Equal(t, []string{"b:2"}, report.MissingDeals)
+	require.Equal(t, []string{"c:3"}, report.MissingResponses)
+
+	buff, err := json.Marshal(report)
+	require.NoError(t, err)
+	var fields map[string]interface{}
+	require.NoError(t, json.Unmarshal(buff, &fields))
+	require.Equal(t, core.PhaseResponse, fields["phase"])
+	require.Equal(t, float64(90), fields["remaining"])
+
+	var out bytes.Buffer
+	writeDKGStatus(&out, report)
+	require.Contains(t, out.String(), "Resharing running")
+	require.Contains(t, out.String(), "(1m30s left)")
+	require.Contains(t, out.String(), "Missing deals from: b:2")
+	require.Contains(t, out.String(), "Missing responses from: c:3")
+	require.Contains(t, out.String(), "2 (unreachable)")
+
+	status.Running = false
+	status.Phase = core.PhaseFinished
+	report = newDKGStatusReport(status, now)
+	require.Zero(t, report.Remaining)
+	require.Empty(t, report.MissingDeals)
+}
@@ -5,12 +5,16 @@ import (
 	"io"
 	"io/ioutil"
 	"os"
+	"strconv"
 	"strings"
 	"sync/atomic"
+	"text/tabwriter"
 	"time"
 
 	"github.com/briandowns/spinner"
+	"github.com/drand/drand/backup"
 	"github.com/drand/drand/chain"
+	"github.com/drand/drand/chain/beacon"
 	"github.com/drand/drand/core"
 	"github.com/drand/drand/key"
 	"github.com/drand/drand/net"
@@ -85,6 +89,37 @@ func getShareArgs(c *cli.Context) (*shareArgs, error) {
 	return args, nil
 }
 
+// shareControlClient returns the control client of the share commands, which
+// sets the timeouts and retries of each DKG phase given to the leader.
+func shareControlClient(c *cli.Context, args *shareArgs) (*net.ControlClient, error) {
+	client, err := net.NewControlClientForBeacon(args.conf.ControlPort(), c.String(beaconIDFlag.Name))
+	if err != nil {
+		return nil, err
+	}
+	client.SetToken(os.Getenv(controlTokenEnv))
+	if c.IsSet(phaseRetriesFlag.Name) {
+		if c.Int(phaseRetriesFlag.Name) < 0 {
+			return nil, fmt.Errorf("invalid %s: negative", phaseRetriesFlag.Name)
+		}
+		client.SetDKGPhaseRetries(c.Int(phaseRetriesFlag.Name))
+	}
+	if !c.IsSet(dealTimeoutFlag.Name) && !c.IsSet(responseTimeoutFlag.Name) && !c.IsSet(justificationTimeoutFlag.Name) {
+		return client, nil
+	}
+	phases := make([]time.Duration, 3)
+	for i, f := range []*cli.StringFlag{dealTimeoutFlag, responseTimeoutFlag, justificationTimeoutFlag} {
+		phases[i] = args.timeout
+		if !c.IsSet(f.Name) {
+			continue
+		}
+		if phases[i], err = time.ParseDuration(c.String(f.Name)); err != nil {
+			return nil, fmt.Errorf("invalid %s: %v", f.Name, err)
+		}
+	}
+	client.SetDKGTimeouts(phases[0], phases[1], phases[2])
+	return client, nil
+}
+
 func shareCmd(c *cli.Context) error {
 	if c.IsSet(transitionFlag.Name) || c.IsSet(oldGroupFlag.Name) {
 		return reshareCmd(c)
@@ -104,7 +139,7 @@ func shareCmd(c *cli.Context) error {
 	coordAddress := c.String(connectFlag.Name)
 	connectPeer := net.CreatePeer(coordAddress, args.isTLS)
 
-	ctrlClient, err := net.NewControlClient(args.conf.ControlPort())
+	ctrlClient, err := shareControlClient(c, args)
 	if err != nil {
 		return fmt.Errorf("could not create client: %v", err)
 	}
@@ -137,7 +172,7 @@ func leadShareCmd(c *cli.Context) error {
 		fmt.Fprintln(output, "Warning: less than 2 nodes is an unsupported, degenerate mode.")
 	}
 
-	ctrlClient, err := net.NewControlClient(args.conf.ControlPort())
+	ctrlClient, err := shareControlClient(c, args)
 	if err != nil {
 		return fmt.Errorf("could not create client: %v", err)
 	}
@@ -156,6 +191,11 @@ func leadShareCmd(c *cli.Context) error {
 		return fmt.Errorf("catchup period given is invalid: %v", err)
 	}
 
+	// the chains of the default derivation do not name it
+	if d := c.String(derivationFlag.Name); d != chain.DefaultDerivationID {
+		ctrlClient.SetDerivation(d)
+	}
+
 	offset := int(core.DefaultGenesisOffset.Seconds())
 	if c.IsSet(beaconOffset.Name) {
 		offset = c.Int(beaconOffset.Name)
@@ -193,10 +233,18 @@ func reshareCmd(c *cli.Context) error {
 	coordAddress := c.String(connectFlag.Name)
 	connectPeer := net.CreatePeer(coordAddress, args.isTLS)
 
-	ctrlClient, err := net.NewControlClient(args.conf.ControlPort())
+	ctrlClient, err := shareControlClient(c, args)
 	if err != nil {
 		return fmt.Errorf("could not create client: %v", err)
 	}
+	// the chain switches to the new period at the transition round
+	if c.IsSet(periodFlag.Name) {
+		period, err := time.ParseDuration(c.String(periodFlag.Name))
+		if err != nil {
+			return fmt.Errorf("period given is invalid: %v", err)
+		}
+		ctrlClient.SetResharePeriod(period)
+	}
 
 	// resharing case needs the previous group
 	var oldPath string
@@ -229,16 +277,29 @@ func leadReshareCmd(c *cli.Context) error {
 		return err
 	}
 
-	if !c.IsSet(thresholdFlag.Name) || !c.IsSet(shareNodeFlag.Name) {
-		return fmt.Errorf("leader needs to specify --nodes and --threshold for sharing")
+	if !c.IsSet(shareNodeFlag.Name) {
+		return fmt.Errorf("leader needs to specify --nodes for resharing")
 	}
 
 	nodes := c.Int(shareNodeFlag.Name)
+	// without --threshold, the new group keeps the threshold of the old group
+	threshold := 0
+	if c.IsSet(thresholdFlag.Name) {
+		threshold = args.threshold
+	}
 
-	ctrlClient, err := net.NewControlClient(args.conf.ControlPort())
+	ctrlClient, err := shareControlClient(c, args)
 	if err != nil {
 		return fmt.Errorf("could not create client: %v", err)
 	}
+	// the chain switches to the new period at the transition round
+	if c.IsSet(periodFlag.Name) {
+		period, err := time.ParseDuration(c.String(periodFlag.Name))
+		if err != nil {
+			return fmt.Errorf("period given is invalid: %v", err)
+		}
+		ctrlClient.SetResharePeriod(period)
+	}
 
 	// resharing case needs the previous group
 	var oldPath string
@@ -265,7 +326,7 @@ func leadReshareCmd(c *cli.Context) error {
 		}
 	}
 	fmt.Fprintln(output, "Initiating the resharing as a leader")
-	groupP, shareErr := ctrlClient.InitReshareLeader(nodes, args.threshold, args.timeout, catchupPeriod, args.secret, oldPath, offset)
+	groupP, shareErr := ctrlClient.InitReshareLeader(nodes, threshold, args.timeout, catchupPeriod, args.secret, oldPath, offset)
 
 	if shareErr != nil {
 		return fmt.Errorf("error setting up the network: %v", shareErr)
@@ -297,6 +358,293 @@ func pingpongCmd(c *cli.Context) error {
 	return nil
 }
 
+func dkgStatusCmd(c *cli.Context) error {
+	return printDKGStatus(c, true)
+}
+
+func dkgStatusReportCmd(c *cli.Context) error {
+	return printDKGStatus(c, c.Bool(jsonFlag.Name))
+}
+
+// dkgStatusReport is the DKG status with the progress the coordinator looks
+// for: the time left in the current phase and the missing bundles.
+type dkgStatusReport struct {
+	*core.DKGStatus
+	// Remaining is the time left before the current phase times out, in
+	// seconds.
+	Remaining        int64    `json:"remaining,omitempty"`
+	MissingDeals     []string `json:"missingDeals,omitempty"`
+	MissingResponses []string `json:"missingResponses,omitempty"`
+}
+
+func newDKGStatusReport(s *core.DKGStatus, now time.Time) *dkgStatusReport {
+	r := &dkgStatusReport{DKGStatus: s, Remaining: int64(s.Remaining(now).Seconds())}
+	if s.Running && s.Phase != core.PhaseWaiting {
+		r.MissingDeals = s.Missing(core.PhaseDeal)
+		if s.Phase != core.PhaseDeal {
+			r.MissingResponses = s.Missing(core.PhaseResponse)
+		}
+	}
+	return r
+}
+
+// printDKGStatus prints the DKG status of the daemon, once or at each change
+// with the follow flag, in JSON, one status per line when following, or as a
+// report for humans.
+func printDKGStatus(c *cli.Context, inJSON bool) error {
+	client, err := controlClient(c)
+	if err != nil {
+		return err
+	}
+	if !c.Bool(followFlag.Name) {
+		status := new(core.DKGStatus)
+		if err := client.AdminCall(core.AdminDKGStatus, nil, status); err != nil {
+			return fmt.Errorf("drand: can't get the DKG status: %s", err)
+		}
+		report := newDKGStatusReport(status, time.Now())
+		if inJSON {
+			return printJSON(report)
+		}
+		writeDKGStatus(output, report)
+		return nil
+	}
+	stream, err := client.AdminWatch(c.Context, core.AdminDKGWatch, nil)
+	if err != nil {
+		return fmt.Errorf("drand: can't watch the DKG status: %s", err)
+	}
+	for {
+		status := new(core.DKGStatus)
+		if err := stream.Recv(status); err == io.EOF {
+			return nil
+		} else if err != nil {
+			return fmt.Errorf("drand: DKG status stream: %s", err)
+		}
+		report := newDKGStatusReport(status, time.Now())
+		if !inJSON {
+			writeDKGStatus(output, report)
+			fmt.Fprintln(output)
+			continue
+		}
+		// one status per line, for the tools consuming the stream
+		buff, err := json.Marshal(report)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintln(output, string(buff))
+	}
+}
+
+// writeDKGStatus writes the DKG status as a report for humans.
+func writeDKGStatus(w io.Writer, r *dkgStatusReport) {
+	kind := "DKG"
+	if r.Reshare {
+		kind = "Resharing"
+	}
+	state := "finished"
+	switch {
+	case r.Running:
+		state = "running"
+	case r.Phase == core.PhaseFailed:
+		state = "failed"
+	}
+	led := ""
+	if r.Leader {
+		led = ", led by this node"
+	}
+	fmt.Fprintf(w, "%s %s%s\n", kind, state, led)
+	retry := ""
+	if r.Retry > 0 {
+		retry = fmt.Sprintf(", extended %d times", r.Retry)
+	}
+	if r.Running && !r.PhaseEnd.IsZero() {
+		fmt.Fprintf(w, "Phase:    %s%s, times out at %s (%s left)\n", r.Phase, retry,
+			r.PhaseEnd.Format(time.RFC3339), time.Duration(r.Remaining)*time.Second)
+	} else {
+		fmt.Fprintf(w, "Phase:    %s\n", r.Phase)
+	}
+	fmt.Fprintf(w, "Timeouts: deal %s, response %s, justification %s, %d retries\n",
+		r.Timeouts.Deal, r.Timeouts.Response, r.Timeouts.Justification, r.Timeouts.Retries)
+	if r.Error != "" {
+		fmt.Fprintf(w, "Error:    %s\n", r.Error)
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "\nPARTICIPANT\tROLE\tDEAL\tRESPONSE\tJUSTIFICATION\tSEND FAILURES")
+	mark := func(expected, received bool) string {
+		switch {
+		case !expected:
+			return "-"
+		case received:
+			return "yes"
+		default:
+			return "no"
+		}
+	}
+	for _, p := range r.Participants {
+		var roles []string
+		if p.Dealer {
+			roles = append(roles, "dealer")
+		}
+		if p.Holder {
+			roles = append(roles, "holder")
+		}
+		failures := strconv.Itoa(p.SendFailures)
+		if p.LastError != "" {
+			failures += " (" + p.LastError + ")"
+		}
+		// the dealers only send a justification when complained about
+		justification := "-"
+		if p.Justification {
+			justification = "yes"
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\n", p.Address, strings.Join(roles, ","),
+			mark(p.Dealer, p.Deal), mark(p.Holder, p.Response), justification, failures)
+	}
+	tw.Flush()
+
+	if len(r.MissingDeals) > 0 {
+		fmt.Fprintf(w, "\nMissing deals from: %s\n", strings.Join(r.MissingDeals, ", "))
+	}
+	if len(r.MissingResponses) > 0 {
+		fmt.Fprintf(w, "Missing responses from: %s\n", strings.Join(r.MissingResponses, ", "))
+	}
+}
+
+func syncStatusCmd(c *cli.Context) error {
+	client, err := controlClient(c)
+	if err != nil {
+		return err
+	}
+	status := new(beacon.SyncStatus)
+	if err := client.AdminCall(core.AdminSyncStatus, nil, status); err != nil {
+		return fmt.Errorf("drand: can't get the sync status: %s", err)
+	}
+	return printJSON(status)
+}
+
+func nodeStatusCmd(c *cli.Context) error {
+	client, err := controlClient(c)
+	if err != nil {
+		return err
+	}
+	status := new(core.NodeStatus)
+	if err := client.AdminCall(core.AdminNodeStatus, nil, status); err != nil {
+		return fmt.Errorf("drand: can't get the status of the node: %s", err)
+	}
+	if err := printJSON(status); err != nil {
+		return err
+	}
+	if !status.Healthy {
+		return fmt.Errorf("drand: the node is unhealthy: %s", strings.Join(status.Problems, "; "))
+	}
+	return nil
+}
+
+func pruneCmd(c *cli.Context) error {
+	client, err := controlClient(c)
+	if err != nil {
+		return err
+	}
+	method := core.AdminPrune
+	if c.Bool(pruneStatusFlag.Name) {
+		method = core.AdminPruneStatus
+	}
+	status := new(beacon.PruneStatus)
+	if err := client.AdminCall(method, nil, status); err != nil {
+		return fmt.Errorf("drand: can't prune the database: %s", err)
+	}
+	return printJSON(status)
+}
+
+func reloadCmd(c *cli.Context) error {
+	client, err := controlClient(c)
+	if err != nil {
+		return err
+	}
+	report := new(core.ReloadReport)
+	if err := client.AdminCall(core.AdminReload, nil, report); err != nil {
+		return fmt.Errorf("drand: can't reload the configuration: %s", err)
+	}
+	return printJSON(report)
+}
+
+func pauseCmd(c *cli.Context) error {
+	client, err := controlClient(c)
+	if err != nil {
+		return err
+	}
+	method := core.AdminPause
+	if c.Bool(pauseStatusFlag.Name) {
+		method = core.AdminPauseStatus
+	}
+	status := new(beacon.PauseStatus)
+	if err := client.AdminCall(method, nil, status); err != nil {
+		return fmt.Errorf("drand: can't pause the beacon: %s", err)
+	}
+	return printJSON(status)
+}
+
+func resumeCmd(c *cli.Context) error {
+	client, err := controlClient(c)
+	if err != nil {
+		return err
+	}
+	status := new(beacon.PauseStatus)
+	if err := client.AdminCall(core.AdminResume, nil, status); err != nil {
+		return fmt.Errorf("drand: can't resume the beacon: %s", err)
+	}
+	return printJSON(status)
+}
+
+func drainCmd(c *cli.Context) error {
+	client, err := controlClient(c)
+	if err != nil {
+		return err
+	}
+	method := core.AdminDrain
+	switch {
+	case c.Bool(drainStatusFlag.Name) && c.Bool(drainExitFlag.Name):
+		return fmt.Errorf("drand: --status and --exit can't be used together")
+	case c.Bool(drainStatusFlag.Name):
+		method = core.AdminDrainStatus
+	case c.Bool(drainExitFlag.Name):
+		method = core.AdminUndrain
+	}
+	status := new(core.DrainStatus)
+	if err := client.AdminCall(method, nil, status); err != nil {
+		return fmt.Errorf("drand: can't drain the daemon: %s", err)
+	}
+	return printJSON(status)
+}
+
+func participationCmd(c *cli.Context) error {
+	client, err := controlClient(c)
+	if err != nil {
+		return err
+	}
+	report := new(beacon.ParticipationReport)
+	if err := client.AdminCall(core.AdminParticipationStatus, nil, report); err != nil {
+		return fmt.Errorf("drand: can't get the participation of the group: %s", err)
+	}
+	return printJSON(report)
+}
+
+func backupCmd(c *cli.Context) error {
+	client, err := controlClient(c)
+	if err != nil {
+		return err
+	}
+	method := core.AdminBackup
+	if c.Bool(backupStatusFlag.Name) {
+		method = core.AdminBackupStatus
+	}
+	status := new(backup.Status)
+	if err := client.AdminCall(method, nil, status); err != nil {
+		return fmt.Errorf("drand: can't back up the node: %s", err)
+	}
+	return printJSON(status)
+}
+
 func showGroupCmd(c *cli.Context) error {
 	client, err := controlClient(c)
 	if err != nil {
@@ -365,6 +713,10 @@ func showShareCmd(c *cli.Context) error {
 	return printJSON(resp)
 }
 
+// controlTokenEnv is the environment variable holding the token the control
+// commands present, when the daemon requires one.
+const controlTokenEnv = "DRAND_CONTROL_TOKEN"
+
 func controlPort(c *cli.Context) string {
 	port := c.String(controlFlag.Name)
 	if port == "" {
@@ -375,10 +727,11 @@ func controlPort(c *cli.Context) string {
 
 func controlClient(c *cli.Context) (*net.ControlClient, error) {
 	port := controlPort(c)
-	client, err := net.NewControlClient(port)
+	client, err := net.NewControlClientForBeacon(port, c.String(beaconIDFlag.Name))
 	if err != nil {
 		return nil, fmt.Errorf("can't instantiate control client: %s", err)
 	}
+	client.SetToken(os.Getenv(controlTokenEnv))
 	return client, nil
 }
 
@@ -1,25 +1,43 @@
 package drand
 
 import (
+	"context"
+	"encoding/hex"
 	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
 
 	"github.com/drand/drand/core"
 	"github.com/drand/drand/key"
+	"github.com/drand/drand/log"
 	"github.com/drand/drand/metrics"
 	"github.com/drand/drand/metrics/pprof"
+	"github.com/drand/drand/net"
 	"github.com/urfave/cli/v2"
 )
 
 func startCmd(c *cli.Context) error {
 	conf := contextToConfig(c)
+	r, err := newReloader(c, conf)
+	if err != nil {
+		return err
+	}
+	if ids, err := core.BeaconIDs(conf.ConfigFolder()); err == nil && len(ids) > 0 {
+		return startMultiBeacon(c, conf, r, ids)
+	}
 	fs := key.NewFileStore(conf.ConfigFolder())
 	var drand *core.Drand
 	// determine if we already ran a DKG or not
 	_, errG := fs.LoadGroup()
-	_, errS := fs.LoadShare()
+	share, errS := fs.LoadShare()
+	if errS == nil {
+		share.Wipe()
+	}
 	// XXX place that logic inside core/ directly with only one method
 	freshRun := errG != nil || errS != nil
-	var err error
 	if freshRun {
 		fmt.Println("drand: will run as fresh install -> expect to run DKG.")
 		drand, err = core.NewDrand(fs, conf)
@@ -38,11 +56,95 @@ func startCmd(c *cli.Context) error {
 		catchup := true
 		drand.StartBeacon(catchup)
 	}
+	if drand.ResumeDKG() {
+		fmt.Println("drand: rejoining the DKG in progress before the restart.")
+	}
 	// Start metrics server
+	r.startMetrics(drand.PeerMetrics)
+	r.reloadOnSignal()
+	stopOnSignal(conf.Logger(), c.Duration(drainTimeoutFlag.Name), drand.StopGracefully)
+	<-drand.WaitExit()
+
+	return nil
+}
+
+// startMultiBeacon runs a daemon hosting the beacons of the multibeacon
+// folder, and the default beacon when its keys were generated.
+func startMultiBeacon(c *cli.Context, conf *core.Config, r *reloader, ids []string) error {
+	fs := key.NewFileStore(conf.ConfigFolder())
+	if pair, err := fs.LoadKeyPair(); err == nil {
+		if conf.PrivateListenAddress("") == "" {
+			core.WithPrivateListenAddress(pair.Public.Address())(conf)
+		}
+		pair.Wipe()
+		ids = append([]string{""}, ids...)
+	}
+	if conf.PrivateListenAddress("") == "" {
+		return fmt.Errorf("drand: --%s is required to host beacons without default keys", privListenFlag.Name)
+	}
+	daemon, err := core.NewDaemon(conf)
+	if err != nil {
+		return fmt.Errorf("can't instantiate drand daemon %s", err)
+	}
+	for _, id := range ids {
+		fmt.Printf("drand: hosting beacon %q\n", id)
+		if _, err := daemon.AddBeacon(id); err != nil {
+			return fmt.Errorf("can't load beacon %q: %s", id, err)
+		}
+	}
+	// Start metrics server with the peers of the first beacon
+	if d, ok := daemon.Beacon(ids[0]); ok {
+		r.startMetrics(d.PeerMetrics)
+	}
+	r.reloadOnSignal()
+	stopOnSignal(conf.Logger(), c.Duration(drainTimeoutFlag.Name), daemon.StopGracefully)
+	<-daemon.WaitExit()
+
+	return nil
+}
+
+// stopOnSignal stops the daemon gracefully when the process receives SIGTERM
+// or an interrupt, giving it the drain timeout to do so. A second signal stops
+// it right away.
+func stopOnSignal(l log.Logger, timeout time.Duration, stop func(ctx context.Context)) {
+	sigs := make(chan os.Signal, 2)
+	signal.Notify(sigs, syscall.SIGTERM, os.Interrupt)
+	go func() {
+		<-sigs
+		l.Info("shutdown", "signal", "timeout", timeout, "after", "round in progress")
+		ctx, cancel := context.WithTimeout(context.Background(), timeout)
+		defer cancel()
+		go func() {
+			<-sigs
+			cancel()
+		}()
+		stop(ctx)
+	}()
+}
+
+// observeCmd runs an observer of the chain of the chain hash flag.
+func observeCmd(c *cli.Context) error {
+	conf := contextToConfig(c)
+	if conf.PrivateListenAddress("") == "" {
+		return fmt.Errorf("drand: --%s is required to observe a chain", privListenFlag.Name)
+	}
+	hash, err := hex.DecodeString(c.String(hashInfoFlag.Name))
+	if err != nil {
+		return fmt.Errorf("invalid chain hash: %s", err)
+	}
+	var peers []net.Peer
+	for _, addr := range strings.Split(c.String(syncNodeFlag.Name), ",") {
+		peers = append(peers, net.CreatePeer(strings.TrimSpace(addr), !c.Bool(insecureFlag.Name)))
+	}
+	observer, err := core.NewObserver(conf, hash, peers)
+	if err != nil {
+		return fmt.Errorf("can't start the observer: %s", err)
+	}
+	fmt.Printf("drand: observing the chain %x\n", observer.Info().Hash())
 	if c.IsSet(metricsFlag.Name) {
-		_ = metrics.Start(c.String(metricsFlag.Name), pprof.WithProfile(), drand.PeerMetrics)
+		_ = metrics.Start(c.String(metricsFlag.Name), pprof.WithProfile(), nil)
 	}
-	<-drand.WaitExit()
+	<-observer.WaitExit()
 
 	return nil
 }
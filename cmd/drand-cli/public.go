@@ -6,11 +6,14 @@ import (
 	"fmt"
 	gonet "net"
 	"os"
+	"strings"
 
+	"github.com/BurntSushi/toml"
 	"github.com/drand/drand/chain"
 	"github.com/drand/drand/client"
 	"github.com/drand/drand/client/grpc"
 	"github.com/drand/drand/core"
+	"github.com/drand/drand/key"
 	"github.com/drand/drand/net"
 	"github.com/urfave/cli/v2"
 )
@@ -129,9 +132,60 @@ func getChainInfo(c *cli.Context) error {
 }
 
 func printChainInfo(c *cli.Context, ci *chain.Info) error {
+	hash := hex.EncodeToString(ci.Hash())
+	if c.IsSet(expectHashFlag.Name) {
+		if expected := strings.ToLower(c.String(expectHashFlag.Name)); expected != hash {
+			return fmt.Errorf("drand: the hash of the chain is %s, not the expected %s", hash, expected)
+		}
+	}
 	if c.Bool(hashOnly.Name) {
-		fmt.Fprintf(output, "%s\n", hex.EncodeToString(ci.Hash()))
+		fmt.Fprintf(output, "%s\n", hash)
+		return nil
+	}
+	switch format := c.String(chainInfoFormatFlag.Name); format {
+	case "json":
+		return printJSON(ci.ToProto())
+	case "toml":
+		return toml.NewEncoder(output).Encode(chainInfoToTOML(ci))
+	case "pin":
+		if !c.IsSet(pinURLFlag.Name) {
+			return errors.New("drand: the pin string needs the URL serving the chain, given with --url")
+		}
+		fmt.Fprintf(output, "%s@%s\n", hash, c.String(pinURLFlag.Name))
 		return nil
+	default:
+		return fmt.Errorf("drand: unknown format %q, expected json, toml or pin", format)
 	}
-	return printJSON(ci.ToProto())
+}
+
+// chainInfoTOML is the TOML representation of the chain info, with its hash.
+type chainInfoTOML struct {
+	Hash        string
+	PublicKey   string
+	Period      string
+	GenesisTime int64
+	GroupHash   string
+	Scheme      string           `toml:",omitempty"`
+	BeaconID    string           `toml:",omitempty"`
+	V2From      uint64           `toml:",omitempty"`
+	Derivation  string           `toml:",omitempty"`
+	Epochs      []*key.EpochTOML `toml:",omitempty"`
+}
+
+func chainInfoToTOML(ci *chain.Info) *chainInfoTOML {
+	t := &chainInfoTOML{
+		Hash:        hex.EncodeToString(ci.Hash()),
+		PublicKey:   key.PointToString(ci.PublicKey),
+		Period:      ci.Period.String(),
+		GenesisTime: ci.GenesisTime,
+		GroupHash:   hex.EncodeToString(ci.GroupHash),
+		Scheme:      ci.Scheme,
+		BeaconID:    ci.BeaconID,
+		V2From:      ci.V2From,
+		Derivation:  ci.Derivation,
+	}
+	for _, e := range ci.Epochs {
+		t.Epochs = append(t.Epochs, e.TOML().(*key.EpochTOML))
+	}
+	return t
 }
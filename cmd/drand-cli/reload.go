@@ -0,0 +1,210 @@
+package drand
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+
+	"github.com/BurntSushi/toml"
+	"github.com/drand/drand/core"
+	"github.com/drand/drand/log"
+	"github.com/drand/drand/metrics"
+	"github.com/drand/drand/metrics/pprof"
+	"github.com/urfave/cli/v2"
+)
+
+// daemonConfig is the configuration file of the daemon, given with --config.
+// The flags of the start command take precedence over the file when the daemon
+// starts, while the values of the file apply when it is reloaded.
+type daemonConfig struct {
+	// LogLevel and Metrics are applied right away when the file is reloaded.
+	LogLevel string `toml:"log_level"`
+	Metrics  string `toml:"metrics"`
+	// The other settings only apply after a restart.
+	PrivateListen string `toml:"private_listen"`
+	PublicListen  string `toml:"public_listen"`
+	Control       string `toml:"control"`
+}
+
+func loadDaemonConfig(path string) (*daemonConfig, error) {
+	c := new(daemonConfig)
+	md, err := toml.DecodeFile(path, c)
+	if err != nil {
+		return nil, fmt.Errorf("reading %s: %s", path, err)
+	}
+	if undecoded := md.Undecoded(); len(undecoded) > 0 {
+		return nil, fmt.Errorf("%s: unknown setting %q", path, undecoded[0].String())
+	}
+	if c.LogLevel != "" {
+		if _, err := log.ParseLevel(c.LogLevel); err != nil {
+			return nil, fmt.Errorf("%s: %s", path, err)
+		}
+	}
+	for _, addr := range []string{c.PrivateListen, c.PublicListen} {
+		if addr == "" {
+			continue
+		}
+		if _, _, err := net.SplitHostPort(addr); err != nil {
+			return nil, fmt.Errorf("%s: invalid address %q: %s", path, addr, err)
+		}
+	}
+	if c.Control != "" {
+		if _, err := strconv.ParseUint(c.Control, 10, 16); err != nil {
+			return nil, fmt.Errorf("%s: invalid control port %q", path, c.Control)
+		}
+	}
+	return c, nil
+}
+
+// daemonSettings returns the settings of the configuration file, if any,
+// overridden by the flags.
+func daemonSettings(c *cli.Context) (*daemonConfig, error) {
+	s := new(daemonConfig)
+	if c.IsSet(configFileFlag.Name) {
+		var err error
+		if s, err = loadDaemonConfig(c.String(configFileFlag.Name)); err != nil {
+			return nil, err
+		}
+	}
+	if c.IsSet(verboseFlag.Name) {
+		s.LogLevel = "debug"
+	} else if s.LogLevel == "" {
+		s.LogLevel = "info"
+	}
+	if c.IsSet(metricsFlag.Name) {
+		s.Metrics = c.String(metricsFlag.Name)
+	}
+	if c.IsSet(privListenFlag.Name) {
+		s.PrivateListen = c.String(privListenFlag.Name)
+	}
+	if c.IsSet(pubListenFlag.Name) {
+		s.PublicListen = c.String(pubListenFlag.Name)
+	}
+	if port := c.String(controlFlag.Name); port != "" {
+		s.Control = port
+	} else if s.Control == "" {
+		s.Control = core.DefaultControlPort
+	}
+	return s, nil
+}
+
+// reloader applies the changes of the configuration file of the daemon, on
+// SIGHUP or when asked on the control port. It runs the metrics server, which
+// restarts when its address changes.
+type reloader struct {
+	sync.Mutex
+	path string
+	conf *core.Config
+	// settings in use
+	running *daemonConfig
+	peers   metrics.PeerHandler
+	metrics net.Listener
+}
+
+// newReloader returns the reloader of the daemon of the given config, which
+// reloads the configuration file when one is given.
+func newReloader(c *cli.Context, conf *core.Config) (*reloader, error) {
+	running, err := daemonSettings(c)
+	if err != nil {
+		return nil, err
+	}
+	r := &reloader{path: c.String(configFileFlag.Name), conf: conf, running: running}
+	if r.path != "" {
+		core.WithReloader(r.reload)(conf)
+	}
+	return r, nil
+}
+
+// startMetrics starts the metrics server with the peer metrics of the given
+// handler, when an address is set.
+func (r *reloader) startMetrics(peers metrics.PeerHandler) {
+	r.Lock()
+	defer r.Unlock()
+	r.peers = peers
+	if err := r.listenMetrics(r.running.Metrics); err != nil {
+		r.conf.Logger().Error("metrics", "start", "err", err)
+	}
+}
+
+func (r *reloader) listenMetrics(addr string) error {
+	if r.metrics != nil {
+		r.metrics.Close()
+		r.metrics = nil
+	}
+	r.running.Metrics = addr
+	if addr == "" {
+		return nil
+	}
+	if r.metrics = metrics.Start(addr, pprof.WithProfile(), r.peers); r.metrics == nil {
+		return fmt.Errorf("the metrics server can not listen on %s", addr)
+	}
+	return nil
+}
+
+// reload reads the configuration file again, applies the settings which can
+// change while the daemon runs, and reports the changed settings which only
+// apply after a restart.
+func (r *reloader) reload() (*core.ReloadReport, error) {
+	r.Lock()
+	defer r.Unlock()
+	file, err := loadDaemonConfig(r.path)
+	if err != nil {
+		return nil, err
+	}
+	report := &core.ReloadReport{Applied: []string{}}
+	if file.Metrics != r.running.Metrics {
+		if err := r.listenMetrics(file.Metrics); err != nil {
+			return nil, err
+		}
+		report.Applied = append(report.Applied, "metrics")
+	}
+	if file.LogLevel == "" {
+		file.LogLevel = "info"
+	}
+	if file.LogLevel != r.running.LogLevel {
+		level, _ := log.ParseLevel(file.LogLevel)
+		if err := r.conf.SetLogLevel(level); err != nil {
+			return nil, err
+		}
+		r.running.LogLevel = file.LogLevel
+		report.Applied = append(report.Applied, "log_level")
+	}
+	restart := []struct {
+		name          string
+		file, running string
+	}{
+		{"private_listen", file.PrivateListen, r.running.PrivateListen},
+		{"public_listen", file.PublicListen, r.running.PublicListen},
+		{"control", file.Control, r.running.Control},
+	}
+	for _, s := range restart {
+		if s.file != "" && s.file != s.running {
+			report.RestartRequired = append(report.RestartRequired, s.name)
+		}
+	}
+	r.conf.Logger().Info("reload", r.path, "applied", strings.Join(report.Applied, ","),
+		"restart_required", strings.Join(report.RestartRequired, ","))
+	return report, nil
+}
+
+// reloadOnSignal reloads the configuration file each time the process receives
+// SIGHUP.
+func (r *reloader) reloadOnSignal() {
+	if r.path == "" {
+		return
+	}
+	sigs := make(chan os.Signal, 1)
+	signal.Notify(sigs, syscall.SIGHUP)
+	go func() {
+		for range sigs {
+			if _, err := r.reload(); err != nil {
+				r.conf.Logger().Error("reload", r.path, "err", err)
+			}
+		}
+	}()
+}
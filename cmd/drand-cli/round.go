@@ -0,0 +1,97 @@
+package drand
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/drand/drand/chain"
+	"github.com/urfave/cli/v2"
+)
+
+func roundAtCmd(c *cli.Context) error {
+	if c.NArg() != 1 {
+		return errors.New("round-at takes a time as argument, as RFC 3339 or UNIX seconds")
+	}
+	t, err := parseTime(c.Args().First())
+	if err != nil {
+		return err
+	}
+	info, err := chainInfoOf(c)
+	if err != nil {
+		return err
+	}
+	if t.Unix() < info.GenesisTime {
+		return fmt.Errorf("%s is before the genesis of the chain at %s",
+			t.UTC().Format(time.RFC3339), time.Unix(info.GenesisTime, 0).UTC().Format(time.RFC3339))
+	}
+	fmt.Fprintln(output, info.RoundAt(t))
+	return nil
+}
+
+func timeOfCmd(c *cli.Context) error {
+	if c.NArg() != 1 {
+		return errors.New("time-of takes a round as argument")
+	}
+	round, err := strconv.ParseUint(c.Args().First(), 10, 64)
+	if err != nil || round == 0 {
+		return fmt.Errorf("invalid round %q", c.Args().First())
+	}
+	info, err := chainInfoOf(c)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintln(output, info.TimeOfRound(round).UTC().Format(time.RFC3339))
+	return nil
+}
+
+// chainInfoOf returns the chain info of the file or URL given with the
+// chain-info flag, or else the one of the daemon.
+func chainInfoOf(c *cli.Context) (*chain.Info, error) {
+	if !c.IsSet(chainInfoFlag.Name) {
+		client, err := controlClient(c)
+		if err != nil {
+			return nil, err
+		}
+		resp, err := client.ChainInfo()
+		if err != nil {
+			return nil, fmt.Errorf("could not request chain info: %s", err)
+		}
+		return chain.InfoFromProto(resp)
+	}
+
+	src := c.String(chainInfoFlag.Name)
+	if !strings.HasPrefix(src, "http://") && !strings.HasPrefix(src, "https://") {
+		b, err := ioutil.ReadFile(src)
+		if err != nil {
+			return nil, err
+		}
+		return chain.InfoFromJSON(bytes.NewReader(b))
+	}
+	resp, err := http.Get(src)
+	if err != nil {
+		return nil, fmt.Errorf("could not get chain info: %s", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("could not get chain info: %s", resp.Status)
+	}
+	return chain.InfoFromJSON(resp.Body)
+}
+
+// parseTime parses a time given as RFC 3339 or as UNIX seconds.
+func parseTime(s string) (time.Time, error) {
+	if secs, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return time.Unix(secs, 0), nil
+	}
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid time %q, expected RFC 3339 or UNIX seconds", s)
+	}
+	return t, nil
+}
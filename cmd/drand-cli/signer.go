@@ -0,0 +1,73 @@
+package drand
+
+import (
+	"crypto/tls"
+	"fmt"
+	gonet "net"
+
+	"github.com/drand/drand/chain"
+	"github.com/drand/drand/chain/beacon"
+	"github.com/drand/drand/core"
+	"github.com/drand/drand/key"
+	"github.com/drand/drand/signer"
+	"github.com/urfave/cli/v2"
+)
+
+// signerCmd runs a signing daemon with the private share of the folder.
+func signerCmd(c *cli.Context) error {
+	if !c.IsSet(signerListenFlag.Name) {
+		return fmt.Errorf("drand: --%s is required", signerListenFlag.Name)
+	}
+	tlsConf, err := signerTLSConfig(c, signer.ServerTLSConfig)
+	if err != nil {
+		return err
+	}
+	folder := core.DefaultConfigFolder()
+	if c.IsSet(folderFlag.Name) {
+		folder = c.String(folderFlag.Name)
+	}
+	store := key.NewFileStore(folder)
+	group, err := store.LoadGroup()
+	if err != nil {
+		return fmt.Errorf("drand: can't load group: %s", err)
+	}
+	share, err := store.LoadShare()
+	if err != nil {
+		return fmt.Errorf("drand: can't load private share: %s", err)
+	}
+	defer share.Wipe()
+
+	lis, err := gonet.Listen("tcp", c.String(signerListenFlag.Name))
+	if err != nil {
+		return err
+	}
+	s := signer.NewScheduledSigner(beacon.NewShareSigner(share), chain.NewChainInfo(group))
+	srv := signer.NewServer(s, tlsConf)
+	fmt.Fprintf(output, "drand: signing with share %d on %s\n", share.Share.I, lis.Addr())
+	return srv.Serve(lis)
+}
+
+// dialSigner connects to the remote signing daemon of the flags.
+func dialSigner(c *cli.Context) (beacon.Signer, error) {
+	tlsConf, err := signerTLSConfig(c, signer.ClientTLSConfig)
+	if err != nil {
+		return nil, err
+	}
+	return signer.Dial(c.String(remoteSignerFlag.Name), tlsConf)
+}
+
+// signerTLSConfig builds the mutual TLS config of the link between a node and
+// its signing daemon. The link carries the partial signatures, so it is never
+// in plaintext, whatever the TLS setting of the public endpoints.
+func signerTLSConfig(c *cli.Context, build func(cert, key, ca string) (*tls.Config, error)) (*tls.Config, error) {
+	for _, f := range []*cli.StringFlag{signerTLSCertFlag, signerTLSKeyFlag, signerTLSCAFlag} {
+		if !c.IsSet(f.Name) {
+			return nil, fmt.Errorf("drand: --%s is required for the signing daemon link", f.Name)
+		}
+	}
+	conf, err := build(c.String(signerTLSCertFlag.Name), c.String(signerTLSKeyFlag.Name), c.String(signerTLSCAFlag.Name))
+	if err != nil {
+		return nil, fmt.Errorf("drand: invalid signer TLS configuration: %s", err)
+	}
+	return conf, nil
+}
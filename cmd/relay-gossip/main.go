@@ -4,7 +4,9 @@ import (
 	"context"
 	"encoding/hex"
 	"fmt"
+	"net/http"
 	"os"
+	"strings"
 
 	"github.com/drand/drand/cmd/client/lib"
 	"github.com/drand/drand/log"
@@ -52,7 +54,7 @@ var (
 	}
 	peerWithFlag = &cli.StringSliceFlag{
 		Name:  "peer-with",
-		Usage: "peer multiaddr(s) for the relay to direct connect with",
+		Usage: "peer multiaddr(s) for the relay to direct connect with, the DNS ones being resolved again periodically",
 	}
 	storeFlag = &cli.StringFlag{
 		Name:  "store",
@@ -68,6 +70,23 @@ var (
 		Name:  "metrics",
 		Usage: "local host:port to bind a metrics servlet (optional)",
 	}
+	statusFlag = &cli.StringFlag{
+		Name:  "status",
+		Usage: "local host:port to serve the health of the mesh of the relay at /status (optional)",
+	}
+	natServiceFlag = &cli.BoolFlag{
+		Name:  "nat-service",
+		Usage: "dial back the peers asking whether they are behind a NAT (AutoNAT service)",
+	}
+	relayHopFlag = &cli.BoolFlag{
+		Name:  "relay-hop",
+		Usage: "act as a circuit relay (v1) for the peers behind a NAT",
+	}
+	chainFlag = &cli.StringSliceFlag{
+		Name: "chain",
+		Usage: "relay another chain on its own topic, from the given HTTP relay or node: <hash>=<url>," +
+			" repeated for each URL of the chain",
+	}
 )
 
 var runCmd = &cli.Command{
@@ -79,6 +98,10 @@ var runCmd = &cli.Command{
 		storeFlag,
 		listenFlag,
 		metricsFlag,
+		statusFlag,
+		natServiceFlag,
+		relayHopFlag,
+		chainFlag,
 	}...),
 	Action: func(cctx *cli.Context) error {
 		if cctx.IsSet(metricsFlag.Name) {
@@ -103,6 +126,13 @@ var runCmd = &cli.Command{
 			chainHash = hex.EncodeToString(info.Hash())
 		}
 
+		nat, err := lib.NATConfig(cctx)
+		if err != nil {
+			return err
+		}
+		nat.Service = cctx.Bool(natServiceFlag.Name)
+		nat.RelayHop = cctx.Bool(relayHopFlag.Name)
+
 		cfg := &lp2p.GossipRelayConfig{
 			ChainHash:    chainHash,
 			PeerWith:     cctx.StringSlice(peerWithFlag.Name),
@@ -110,15 +140,53 @@ var runCmd = &cli.Command{
 			DataDir:      cctx.String(storeFlag.Name),
 			IdentityPath: cctx.String(idFlag.Name),
 			Client:       c,
+			NAT:          nat,
+			Chains:       relayChains(cctx.StringSlice(chainFlag.Name)),
 		}
-		if _, err := lp2p.NewGossipRelayNode(log.DefaultLogger(), cfg); err != nil {
+		node, err := lp2p.NewGossipRelayNode(log.DefaultLogger(), cfg)
+		if err != nil {
 			return err
 		}
+		if cctx.IsSet(statusFlag.Name) {
+			mux := http.NewServeMux()
+			mux.Handle("/status", node.StatusHandler())
+			go func() {
+				if err := http.ListenAndServe(cctx.String(statusFlag.Name), mux); err != nil {
+					log.DefaultLogger().Error("relay_gossip", "status server stopped", "err", err)
+				}
+			}()
+		}
 		<-chan int(nil)
 		return nil
 	},
 }
 
+// relayChains creates the clients of the other chains relayed, given as
+// <hash>=<url>. The chains none of whose URLs could be loaded are skipped, so
+// that they do not prevent the relay of the other ones.
+func relayChains(flags []string) []lp2p.GossipRelayChain {
+	var hashes []string
+	byHash := make(map[string][]string)
+	for _, f := range flags {
+		hash := strings.SplitN(f, "=", 2)[0]
+		if _, ok := byHash[hash]; !ok {
+			hashes = append(hashes, hash)
+		}
+		byHash[hash] = append(byHash[hash], f)
+	}
+
+	var chains []lp2p.GossipRelayChain
+	for _, hash := range hashes {
+		clients, err := lib.ChainClients(byHash[hash])
+		if err != nil {
+			log.DefaultLogger().Error("relay_gossip", "could not create the client of a chain", "chain", hash, "err", err)
+			continue
+		}
+		chains = append(chains, lp2p.GossipRelayChain{ChainHash: hash, Client: clients[0]})
+	}
+	return chains
+}
+
 var clientCmd = &cli.Command{
 	Name:  "client",
 	Flags: lib.ClientFlags,
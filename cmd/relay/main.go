@@ -1,17 +1,23 @@
 package main
 
 import (
+	"crypto/tls"
+	"encoding/hex"
 	"fmt"
 	"net"
 	"net/http"
 	"net/http/httptest"
 	"os"
 
+	"github.com/drand/drand/client"
+	"github.com/drand/drand/client/sharedcache"
 	"github.com/drand/drand/cmd/client/lib"
 	dhttp "github.com/drand/drand/http"
 	"github.com/drand/drand/log"
 	"github.com/drand/drand/metrics"
+	"github.com/drand/drand/metrics/otlp"
 	"github.com/drand/drand/metrics/pprof"
+	dnet "github.com/drand/drand/net"
 
 	"github.com/gorilla/handlers"
 	grpc_prometheus "github.com/grpc-ecosystem/go-grpc-prometheus"
@@ -37,11 +43,153 @@ var listenFlag = &cli.StringFlag{
 	Usage: "local host:port to bind the listener",
 }
 
+var noCompressionFlag = &cli.BoolFlag{
+	Name:  "no-compression",
+	Usage: "do not gzip the responses, e.g. when a proxy or a CDN compresses them",
+}
+
+var corsOriginsFlag = &cli.StringSliceFlag{
+	Name:  "cors-origins",
+	Usage: "origins the web applications may call the relay from, e.g. https://*.example.com (default: any origin)",
+}
+
+var corsMethodsFlag = &cli.StringSliceFlag{
+	Name:  "cors-methods",
+	Usage: "methods allowed in the cross-origin requests (default: GET, HEAD and POST)",
+}
+
+var corsHeadersFlag = &cli.StringSliceFlag{
+	Name:  "cors-headers",
+	Usage: "headers allowed in the cross-origin requests",
+}
+
+var rateLimitFlag = &cli.Float64Flag{
+	Name:  "rate-limit",
+	Usage: "requests per second allowed for each client IP (default: no limit)",
+}
+
+var rateBurstFlag = &cli.IntFlag{
+	Name:  "rate-burst",
+	Usage: "requests a client IP may send at once",
+}
+
+var tokenRateLimitFlag = &cli.Float64Flag{
+	Name:  "token-rate-limit",
+	Usage: "requests per second allowed for each bearer token (default: no limit)",
+}
+
+var tokenRateBurstFlag = &cli.IntFlag{
+	Name:  "token-rate-burst",
+	Usage: "requests a bearer token may send at once",
+}
+
+var rateExemptFlag = &cli.StringSliceFlag{
+	Name:  "rate-exempt",
+	Usage: "IPs or CIDR networks whose requests are never rate limited",
+}
+
+var rateExemptTokensFlag = &cli.StringSliceFlag{
+	Name:  "rate-exempt-tokens",
+	Usage: "bearer tokens whose requests are never rate limited",
+}
+
+var realIPHeaderFlag = &cli.StringFlag{
+	Name:  "real-ip-header",
+	Usage: "header holding the client IP set by the proxy in front of the relay, e.g. X-Forwarded-For",
+}
+
+var acmeDomainFlag = &cli.StringSliceFlag{
+	Name:  "acme-domain",
+	Usage: "serve HTTPS with the certificates of an ACME certificate authority, Let's Encrypt by default, issued for these domains",
+}
+
+var acmeCacheFlag = &cli.StringFlag{
+	Name:  "acme-cache",
+	Usage: "folder keeping the ACME account and certificates across restarts",
+	Value: "acme",
+}
+
+var acmeEmailFlag = &cli.StringFlag{
+	Name:  "acme-email",
+	Usage: "contact email of the ACME account",
+}
+
+var acmeHTTPFlag = &cli.StringFlag{
+	Name:  "acme-http",
+	Usage: "address answering the HTTP-01 challenges, e.g. :80 (default: only the TLS-ALPN-01 challenges)",
+}
+
+var acmeDirectoryFlag = &cli.StringFlag{
+	Name:  "acme-directory",
+	Usage: "directory URL of the ACME certificate authority",
+}
+
+var sharedCacheFlag = &cli.StringFlag{
+	Name:  "shared-cache",
+	Usage: "cache the rounds and the chain info in a store shared by the relays of a fleet: redis://[:password@]host:port[/db] or memcached://host:port",
+}
+
+var sharedCacheTTLFlag = &cli.DurationFlag{
+	Name:  "shared-cache-ttl",
+	Usage: "how long the rounds are kept in the shared cache",
+	Value: sharedcache.DefaultTTL,
+}
+
+var readyLagFlag = &cli.Uint64Flag{
+	Name:  "ready-max-lag",
+	Usage: "number of rounds the relay may lag behind the chain while its /readyz probe reports it ready",
+	Value: 2,
+}
+
+var otlpEndpointFlag = &cli.StringFlag{
+	Name:  "otlp-endpoint",
+	Usage: "push the metrics and the traces of the relay to the OpenTelemetry collector at this OTLP/HTTP URL, e.g. http://localhost:4318",
+}
+
+var otlpIntervalFlag = &cli.DurationFlag{
+	Name:  "otlp-interval",
+	Usage: "how often the metrics and the traces are pushed to the OpenTelemetry collector",
+	Value: otlp.DefaultInterval,
+}
+
+var chainFlag = &cli.StringSliceFlag{
+	Name: "chain",
+	Usage: "serve another chain under /<hash>/, from the given HTTP relay or node: <hash>=<url>," +
+		" repeated for each URL of the chain",
+}
+
+var graphqlFlag = &cli.BoolFlag{
+	Name:  "graphql",
+	Usage: "serve the GraphQL API at /graphql",
+}
+
+var signResponsesFlag = &cli.BoolFlag{
+	Name:  "sign-responses",
+	Usage: "sign the responses with the identity of the relay, in the " + dhttp.SignatureHeader + " header",
+}
+
+var identityFlag = &cli.StringFlag{
+	Name:  "identity",
+	Usage: "file holding the identity of the relay signing the responses, created if it does not exist",
+	Value: "identity.key",
+}
+
 var metricsFlag = &cli.StringFlag{
 	Name:  "metrics",
 	Usage: "local host:port to bind a metrics servlet (optional)",
 }
 
+var relayFlags = []cli.Flag{
+	listenFlag, accessLogFlag, metricsFlag, noCompressionFlag,
+	corsOriginsFlag, corsMethodsFlag, corsHeadersFlag,
+	rateLimitFlag, rateBurstFlag, tokenRateLimitFlag, tokenRateBurstFlag,
+	rateExemptFlag, rateExemptTokensFlag, realIPHeaderFlag,
+	acmeDomainFlag, acmeCacheFlag, acmeEmailFlag, acmeHTTPFlag, acmeDirectoryFlag,
+	sharedCacheFlag, sharedCacheTTLFlag, readyLagFlag,
+	otlpEndpointFlag, otlpIntervalFlag, chainFlag, graphqlFlag,
+	signResponsesFlag, identityFlag,
+}
+
 // Relay a GRPC connection to an HTTP server.
 func Relay(c *cli.Context) error {
 	if c.IsSet(metricsFlag.Name) {
@@ -53,12 +201,77 @@ func Relay(c *cli.Context) error {
 		}
 	}
 
-	client, err := lib.Create(c, c.IsSet(metricsFlag.Name))
+	// the rounds are shared with the other relays of the fleet, keyed by the
+	// hash of the chain.
+	var clientOpts []client.Option
+	if c.IsSet(sharedCacheFlag.Name) {
+		clientOpts = append(clientOpts,
+			sharedcache.Option(c.String(sharedCacheFlag.Name), c.Duration(sharedCacheTTLFlag.Name)))
+	}
+	cl, err := lib.Create(c, c.IsSet(metricsFlag.Name), clientOpts...)
 	if err != nil {
 		return err
 	}
+	clients := []client.Client{cl}
+	if c.IsSet(chainFlag.Name) {
+		chains, err := lib.ChainClients(c.StringSlice(chainFlag.Name), clientOpts...)
+		if err != nil {
+			return err
+		}
+		clients = append(clients, chains...)
+	}
 
-	handler, err := dhttp.New(c.Context, client, fmt.Sprintf("drand/%s (%s)", version, gitCommit), log.DefaultLogger().With("binary", "relay"))
+	var opts []dhttp.Option
+	if c.IsSet(otlpEndpointFlag.Name) {
+		if err := metrics.Bind(); err != nil {
+			return err
+		}
+		exporter := otlp.New(c.String(otlpEndpointFlag.Name), "drand-relay", metrics.PrivateMetrics, c.Duration(otlpIntervalFlag.Name))
+		go exporter.Start(c.Context)
+		opts = append(opts, dhttp.WithTracer(exporter))
+	}
+	if c.Bool(noCompressionFlag.Name) {
+		opts = append(opts, dhttp.WithoutCompression())
+	}
+	if c.Bool(graphqlFlag.Name) {
+		opts = append(opts, dhttp.WithGraphQL())
+	}
+	if c.Bool(signResponsesFlag.Name) {
+		id, err := dhttp.LoadOrCreateIdentity(c.String(identityFlag.Name))
+		if err != nil {
+			return fmt.Errorf("loading the relay identity: %w", err)
+		}
+		log.DefaultLogger().Info("binary", "relay", "signing responses", id.ID())
+		opts = append(opts, dhttp.WithIdentity(id))
+	}
+	cors := dhttp.DefaultCORSPolicy()
+	if c.IsSet(corsOriginsFlag.Name) {
+		cors.AllowedOrigins = c.StringSlice(corsOriginsFlag.Name)
+	}
+	if c.IsSet(corsMethodsFlag.Name) {
+		cors.AllowedMethods = c.StringSlice(corsMethodsFlag.Name)
+	}
+	if c.IsSet(corsHeadersFlag.Name) {
+		cors.AllowedHeaders = c.StringSlice(corsHeadersFlag.Name)
+	}
+	opts = append(opts, dhttp.WithCORS(cors))
+	opts = append(opts, dhttp.WithReadyLag(c.Uint64(readyLagFlag.Name)))
+	if c.IsSet(rateLimitFlag.Name) || c.IsSet(tokenRateLimitFlag.Name) {
+		exempt, err := dhttp.ParseExemptNets(c.StringSlice(rateExemptFlag.Name))
+		if err != nil {
+			return fmt.Errorf("invalid rate limit exemption: %w", err)
+		}
+		opts = append(opts, dhttp.WithRateLimit(dhttp.RateLimit{
+			Rate:         c.Float64(rateLimitFlag.Name),
+			Burst:        c.Int(rateBurstFlag.Name),
+			TokenRate:    c.Float64(tokenRateLimitFlag.Name),
+			TokenBurst:   c.Int(tokenRateBurstFlag.Name),
+			ExemptNets:   exempt,
+			ExemptTokens: c.StringSlice(rateExemptTokensFlag.Name),
+			RealIPHeader: c.String(realIPHeaderFlag.Name),
+		}))
+	}
+	handler, err := dhttp.NewMultiChain(c.Context, clients, fmt.Sprintf("drand/%s (%s)", version, gitCommit), log.DefaultLogger().With("binary", "relay"), opts...)
 	if err != nil {
 		return fmt.Errorf("failed to create rest handler: %w", err)
 	}
@@ -90,17 +303,54 @@ func Relay(c *cli.Context) error {
 	if rr.Code != http.StatusOK {
 		log.DefaultLogger().Warn("binary", "relay", "startup failed", rr.Code)
 	}
+	for _, cl := range clients[1:] {
+		info, err := cl.Info(c.Context)
+		if err != nil {
+			continue
+		}
+		req, _ := http.NewRequest("GET", "/"+hex.EncodeToString(info.Hash())+"/public/0", nil)
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, req)
+		if rr.Code != http.StatusOK {
+			log.DefaultLogger().Warn("binary", "relay", "startup failed", rr.Code, "chain", hex.EncodeToString(info.Hash()))
+		}
+	}
 
 	fmt.Printf("Listening at %s\n", listener.Addr())
+	if c.IsSet(acmeDomainFlag.Name) {
+		return serveACME(c, listener, handler)
+	}
 	return http.Serve(listener, handler)
 }
 
+// serveACME serves HTTPS with the certificates of an ACME certificate
+// authority.
+func serveACME(c *cli.Context, listener net.Listener, handler http.Handler) error {
+	a := &dnet.ACME{
+		Domains:      c.StringSlice(acmeDomainFlag.Name),
+		CacheDir:     c.String(acmeCacheFlag.Name),
+		Email:        c.String(acmeEmailFlag.Name),
+		DirectoryURL: c.String(acmeDirectoryFlag.Name),
+		HTTPAddr:     c.String(acmeHTTPFlag.Name),
+	}
+	config, err := a.TLSConfig(&tls.Config{MinVersion: tls.VersionTLS12, NextProtos: []string{"h2", "http/1.1"}})
+	if err != nil {
+		return err
+	}
+	stop, err := a.ServeHTTPChallenges()
+	if err != nil {
+		return err
+	}
+	defer stop()
+	return http.Serve(tls.NewListener(listener, config), handler)
+}
+
 func main() {
 	app := &cli.App{
 		Name:    "relay",
 		Version: version,
 		Usage:   "Relay a Drand group to a public HTTP Rest API",
-		Flags:   append(lib.ClientFlags, listenFlag, accessLogFlag, metricsFlag),
+		Flags:   append(lib.ClientFlags, relayFlags...),
 		Action:  Relay,
 	}
 	cli.VersionPrinter = func(c *cli.Context) {
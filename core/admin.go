@@ -0,0 +1,296 @@
+package core
+
+import (
+	"context"
+	"errors"
+
+	"github.com/drand/drand/audit"
+	"github.com/drand/drand/backup"
+	"github.com/drand/drand/chain/beacon"
+	"github.com/drand/drand/net"
+)
+
+// The methods of the admin service of the control port, see net.AdminServer.
+const (
+	// AdminDKGStatus returns the DKGStatus of the last DKG or resharing.
+	AdminDKGStatus = "dkg.status"
+	// AdminDKGWatch streams the DKGStatus of the DKG or resharing in
+	// progress at each change, until it ends.
+	AdminDKGWatch = "dkg.watch"
+	// AdminSyncStatus returns the beacon.SyncStatus of the current or last
+	// sync of the chain with the other nodes.
+	AdminSyncStatus = "sync.status"
+	// AdminPrune deletes right away the rounds the retention policy does not
+	// keep, and returns the resulting beacon.PruneStatus.
+	AdminPrune = "store.prune"
+	// AdminPruneStatus returns the beacon.PruneStatus of the store.
+	AdminPruneStatus = "store.prune_status"
+	// AdminPause stops the node from signing the beacons until it is resumed,
+	// and returns the resulting beacon.PauseStatus.
+	AdminPause = "beacon.pause"
+	// AdminResume lets a paused node sign the beacons again, and returns the
+	// resulting beacon.PauseStatus.
+	AdminResume = "beacon.resume"
+	// AdminPauseStatus returns the beacon.PauseStatus of the node.
+	AdminPauseStatus = "beacon.pause_status"
+	// AdminParticipationStatus returns the beacon.ParticipationReport of the
+	// members of the group, as observed by the node.
+	AdminParticipationStatus = "beacon.participation_status"
+	// AdminDrain drains the node before a maintenance: it refuses the public
+	// traffic and the new syncs, lets its peers finish syncing from it, and
+	// keeps signing until it is stopped. It returns the resulting DrainStatus.
+	AdminDrain = "node.drain"
+	// AdminUndrain lets a draining node serve all its traffic again, and
+	// returns the resulting DrainStatus.
+	AdminUndrain = "node.undrain"
+	// AdminDrainStatus returns the DrainStatus of the node.
+	AdminDrainStatus = "node.drain_status"
+	// AdminNodeStatus returns the NodeStatus of the node.
+	AdminNodeStatus = "node.status"
+	// AdminReload reloads the configuration of the daemon, and returns the
+	// resulting ReloadReport.
+	AdminReload = "config.reload"
+	// AdminBackup backs up the node right away, and returns the resulting
+	// backup.Status.
+	AdminBackup = "backup.run"
+	// AdminBackupStatus returns the backup.Status of the node.
+	AdminBackupStatus = "backup.status"
+)
+
+var _ net.AdminServer = (*Drand)(nil)
+var _ net.AdminServer = (*Daemon)(nil)
+
+type adminCall func(d *Drand, ctx context.Context, req *net.AdminRequest) (interface{}, error)
+
+type adminWatch func(d *Drand, req *net.AdminRequest, stream net.AdminSender) error
+
+var adminCalls = map[string]adminCall{
+	AdminDKGStatus:           (*Drand).adminDKGStatus,
+	AdminSyncStatus:          (*Drand).adminSyncStatus,
+	AdminPrune:               (*Drand).adminPrune,
+	AdminPruneStatus:         (*Drand).adminPruneStatus,
+	AdminPause:               (*Drand).adminPause,
+	AdminResume:              (*Drand).adminResume,
+	AdminPauseStatus:         (*Drand).adminPauseStatus,
+	AdminParticipationStatus: (*Drand).adminParticipationStatus,
+	AdminDrain:               (*Drand).adminDrain,
+	AdminUndrain:             (*Drand).adminUndrain,
+	AdminDrainStatus:         (*Drand).adminDrainStatus,
+	AdminNodeStatus:          (*Drand).adminNodeStatus,
+	AdminReload:              (*Drand).adminReload,
+	AdminBackup:              (*Drand).adminBackup,
+	AdminBackupStatus:        (*Drand).adminBackupStatus,
+}
+
+var adminWatches = map[string]adminWatch{
+	AdminDKGWatch: (*Drand).adminDKGWatch,
+}
+
+// AdminCall answers the unary requests of the admin service.
+func (d *Drand) AdminCall(ctx context.Context, req *net.AdminRequest) (interface{}, error) {
+	call, ok := adminCalls[req.Method]
+	if !ok {
+		return nil, net.ErrUnknownAdminMethod(req.Method)
+	}
+	return call(d, ctx, req)
+}
+
+// AdminWatch answers the streaming requests of the admin service.
+func (d *Drand) AdminWatch(req *net.AdminRequest, stream net.AdminSender) error {
+	watch, ok := adminWatches[req.Method]
+	if !ok {
+		return net.ErrUnknownAdminMethod(req.Method)
+	}
+	return watch(d, req, stream)
+}
+
+var errNoDKG = errors.New("drand: no DKG ran since the node started")
+
+func (d *Drand) monitor() *dkgMonitor {
+	d.state.Lock()
+	defer d.state.Unlock()
+	return d.dkgMonitor
+}
+
+func (d *Drand) adminDKGStatus(ctx context.Context, req *net.AdminRequest) (interface{}, error) {
+	m := d.monitor()
+	if m == nil {
+		return nil, errNoDKG
+	}
+	return m.Status(), nil
+}
+
+func (d *Drand) adminDKGWatch(req *net.AdminRequest, stream net.AdminSender) error {
+	m := d.monitor()
+	if m == nil {
+		return errNoDKG
+	}
+	statuses, stop := m.watch()
+	defer stop()
+	for {
+		select {
+		case s := <-statuses:
+			if err := stream.Send(s); err != nil {
+				return err
+			}
+			if !s.Running {
+				return nil
+			}
+		case <-stream.Context().Done():
+			return stream.Context().Err()
+		}
+	}
+}
+
+var errNoBeacon = errors.New("drand: beacon not setup yet")
+
+func (d *Drand) runningBeacon() *beacon.Handler {
+	d.state.Lock()
+	defer d.state.Unlock()
+	return d.beacon
+}
+
+func (d *Drand) adminSyncStatus(ctx context.Context, req *net.AdminRequest) (interface{}, error) {
+	b := d.runningBeacon()
+	if b == nil {
+		return nil, errNoBeacon
+	}
+	return b.SyncStatus(), nil
+}
+
+func (d *Drand) adminPrune(ctx context.Context, req *net.AdminRequest) (interface{}, error) {
+	b := d.runningBeacon()
+	if b == nil {
+		return nil, errNoBeacon
+	}
+	return b.Prune()
+}
+
+func (d *Drand) adminPruneStatus(ctx context.Context, req *net.AdminRequest) (interface{}, error) {
+	b := d.runningBeacon()
+	if b == nil {
+		return nil, errNoBeacon
+	}
+	return b.PruneStatus(), nil
+}
+
+func (d *Drand) adminPause(ctx context.Context, req *net.AdminRequest) (interface{}, error) {
+	b := d.runningBeacon()
+	if b == nil {
+		d.opts.record(ctx, audit.ActionPause, audit.OutcomeFailed, errNoBeacon)
+		return nil, errNoBeacon
+	}
+	d.opts.record(ctx, audit.ActionPause, audit.OutcomeOK, nil)
+	return b.Pause(), nil
+}
+
+func (d *Drand) adminResume(ctx context.Context, req *net.AdminRequest) (interface{}, error) {
+	b := d.runningBeacon()
+	if b == nil {
+		d.opts.record(ctx, audit.ActionResume, audit.OutcomeFailed, errNoBeacon)
+		return nil, errNoBeacon
+	}
+	d.opts.record(ctx, audit.ActionResume, audit.OutcomeOK, nil)
+	return b.Resume(), nil
+}
+
+func (d *Drand) adminPauseStatus(ctx context.Context, req *net.AdminRequest) (interface{}, error) {
+	b := d.runningBeacon()
+	if b == nil {
+		return nil, errNoBeacon
+	}
+	return b.PauseStatus(), nil
+}
+
+func (d *Drand) adminParticipationStatus(ctx context.Context, req *net.AdminRequest) (interface{}, error) {
+	b := d.runningBeacon()
+	if b == nil {
+		return nil, errNoBeacon
+	}
+	return b.Participation(), nil
+}
+
+func (d *Drand) adminDrain(ctx context.Context, req *net.AdminRequest) (interface{}, error) {
+	st := d.drain.start(d.opts.clock.Now().Unix())
+	d.opts.record(ctx, audit.ActionDrain, audit.OutcomeOK, nil)
+	d.log.Info("drain", st.State, "syncs", st.Syncs)
+	return st, nil
+}
+
+func (d *Drand) adminUndrain(ctx context.Context, req *net.AdminRequest) (interface{}, error) {
+	st := d.drain.exit()
+	d.opts.record(ctx, audit.ActionUndrain, audit.OutcomeOK, nil)
+	d.log.Info("drain", st.State)
+	return st, nil
+}
+
+func (d *Drand) adminDrainStatus(ctx context.Context, req *net.AdminRequest) (interface{}, error) {
+	return d.drain.status(), nil
+}
+
+func (d *Drand) adminNodeStatus(ctx context.Context, req *net.AdminRequest) (interface{}, error) {
+	return d.nodeStatus(ctx), nil
+}
+
+// paused returns true if the running beacon of the node does not sign.
+func (d *Drand) paused() bool {
+	b := d.runningBeacon()
+	return b != nil && b.Paused()
+}
+
+func (d *Drand) adminReload(ctx context.Context, req *net.AdminRequest) (interface{}, error) {
+	if d.opts.reloader == nil {
+		return nil, errors.New("drand: the daemon was not started with a configuration file")
+	}
+	return d.opts.reloader()
+}
+
+var errNoBackup = errors.New("drand: backups are not enabled or the beacon is not setup yet")
+
+func (d *Drand) backupManager() *backup.Manager {
+	d.state.Lock()
+	defer d.state.Unlock()
+	if d.backupCancel == nil {
+		return nil
+	}
+	return d.backups
+}
+
+func (d *Drand) adminBackup(ctx context.Context, req *net.AdminRequest) (interface{}, error) {
+	m := d.backupManager()
+	if m == nil {
+		return nil, errNoBackup
+	}
+	if _, err := m.Backup(ctx); err != nil {
+		return nil, err
+	}
+	status := m.Status()
+	return &status, nil
+}
+
+func (d *Drand) adminBackupStatus(ctx context.Context, req *net.AdminRequest) (interface{}, error) {
+	m := d.backupManager()
+	if m == nil {
+		return nil, errNoBackup
+	}
+	status := m.Status()
+	return &status, nil
+}
+
+// AdminCall routes the request to the beacon process.
+func (dd *Daemon) AdminCall(ctx context.Context, req *net.AdminRequest) (interface{}, error) {
+	d, err := dd.process(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return d.AdminCall(ctx, req)
+}
+
+// AdminWatch routes the request to the beacon process.
+func (dd *Daemon) AdminWatch(req *net.AdminRequest, stream net.AdminSender) error {
+	d, err := dd.process(stream.Context())
+	if err != nil {
+		return err
+	}
+	return d.AdminWatch(req, stream)
+}
@@ -7,11 +7,13 @@ import (
 	"fmt"
 	"math/rand"
 	"sync"
+	"time"
 
 	"github.com/drand/drand/key"
 	"github.com/drand/drand/log"
 	"github.com/drand/drand/net"
 	"github.com/drand/drand/protobuf/drand"
+	"github.com/drand/kyber"
 	"github.com/drand/kyber/share/dkg"
 )
 
@@ -46,6 +48,13 @@ type broadcast struct {
 	respCh chan dkg.ResponseBundle
 	justCh chan dkg.JustificationBundle
 	verif  verifier
+	// keyGroup is the group of the points and scalars of the packets
+	keyGroup kyber.Group
+	// record is called with each new valid packet received, to persist it
+	record func(*drand.DKGPacket)
+	// observe is called with each packet of the ceremony, received or sent,
+	// to track its status
+	observe func(packet)
 }
 
 type packet = dkg.Packet
@@ -56,15 +65,16 @@ var _ dkg.Board = (*broadcast)(nil)
 // Packet, namely that the signature is correct.
 type verifier func(packet) error
 
-func newBroadcast(l log.Logger, c net.ProtocolClient, own string, to []*key.Node, v verifier) *broadcast {
+func newBroadcast(l log.Logger, c net.ProtocolClient, own string, to []*key.Node, v verifier, r retryPolicy) *broadcast {
 	return &broadcast{
 		l:          l,
-		dispatcher: newDispatcher(l, c, to, own),
+		dispatcher: newDispatcher(l, c, to, own, r),
 		dealCh:     make(chan dkg.DealBundle, len(to)),
 		respCh:     make(chan dkg.ResponseBundle, len(to)),
 		justCh:     make(chan dkg.JustificationBundle, len(to)),
 		hashes:     new(arraySet),
 		verif:      v,
+		keyGroup:   key.KeyGroup,
 	}
 }
 
@@ -74,6 +84,7 @@ func (b *broadcast) PushDeals(bundle *dkg.DealBundle) {
 	defer b.Unlock()
 	h := hash(bundle.Hash())
 	b.l.Debug("broadcast", "push", "deal")
+	b.observed(bundle)
 	b.sendout(h, bundle, true)
 }
 
@@ -83,6 +94,7 @@ func (b *broadcast) PushResponses(bundle *dkg.ResponseBundle) {
 	defer b.Unlock()
 	h := hash(bundle.Hash())
 	b.l.Debug("broadcast", "push", "response", bundle.String())
+	b.observed(bundle)
 	b.sendout(h, bundle, true)
 }
 
@@ -92,14 +104,32 @@ func (b *broadcast) PushJustifications(bundle *dkg.JustificationBundle) {
 	defer b.Unlock()
 	h := hash(bundle.Hash())
 	b.l.Debug("broadcast", "push", "justification")
+	b.observed(bundle)
 	b.sendout(h, bundle, true)
 }
 
 func (b *broadcast) BroadcastDKG(c context.Context, p *drand.DKGPacket) (*drand.Empty, error) {
 	b.Lock()
 	defer b.Unlock()
-	addr := net.RemoteAddress(c)
-	dkgPacket, err := protoToDKGPacket(p.GetDkg())
+	return b.receive(net.RemoteAddress(c), p, b.record)
+}
+
+// replay processes packets received before the node restarted, without
+// recording them again.
+func (b *broadcast) replay(packets []*drand.DKGPacket) {
+	b.Lock()
+	defer b.Unlock()
+	for _, p := range packets {
+		if _, err := b.receive("replay", p, nil); err != nil {
+			b.l.Error("broadcast", "invalid replayed packet", "err", err)
+		}
+	}
+}
+
+// receive verifies, rebroadcasts and passes a new packet to the application.
+// It requires the broadcast lock.
+func (b *broadcast) receive(addr string, p *drand.DKGPacket, record func(*drand.DKGPacket)) (*drand.Empty, error) {
+	dkgPacket, err := protoToDKGPacket(p.GetDkg(), b.keyGroup)
 	if err != nil {
 		b.l.Debug("broadcast", "received invalid packet", "from", addr, "err", err)
 		return nil, errors.New("invalid packet")
@@ -118,11 +148,22 @@ func (b *broadcast) BroadcastDKG(c context.Context, p *drand.DKGPacket) (*drand.
 	}
 
 	b.l.Debug("broadcast", "received new packet to broadcast", "from", addr, "type", fmt.Sprintf("%T", dkgPacket))
+	if record != nil {
+		record(p)
+	}
+	b.observed(dkgPacket)
 	b.sendout(hash, dkgPacket, false) // we're using the rate limiting
 	b.passToApplication(dkgPacket)
 	return new(drand.Empty), nil
 }
 
+// observed passes a packet of the ceremony to the observer, if any.
+func (b *broadcast) observed(p packet) {
+	if b.observe != nil {
+		b.observe(p)
+	}
+}
+
 func (b *broadcast) passToApplication(p packet) {
 	switch pp := p.(type) {
 	case *dkg.DealBundle:
@@ -231,14 +272,14 @@ type dispatcher struct {
 	senders []*sender
 }
 
-func newDispatcher(l log.Logger, client net.ProtocolClient, to []*key.Node, us string) *dispatcher {
+func newDispatcher(l log.Logger, client net.ProtocolClient, to []*key.Node, us string, r retryPolicy) *dispatcher {
 	var senders = make([]*sender, 0, len(to)-1)
 	queue := senderQueueSize(len(to))
 	for _, node := range to {
 		if node.Address() == us {
 			continue
 		}
-		sender := newSender(l, client, node, queue)
+		sender := newSender(l, client, node, queue, r)
 		go sender.run()
 		senders = append(senders, sender)
 	}
@@ -256,10 +297,12 @@ func (d *dispatcher) broadcast(p broadcastPacket) {
 }
 
 // broadcastDirect directly send to the other peers - it is used only for our
-// own packets so we're not bound to congestion events.
+// own packets so we're not bound to congestion events. The peers are sent to
+// concurrently, so that the retries to an unreachable peer do not delay the
+// packet to the others.
 func (d *dispatcher) broadcastDirect(p broadcastPacket) {
 	for _, i := range rand.Perm(len(d.senders)) {
-		d.senders[i].sendDirect(p)
+		go d.senders[i].sendDirect(p)
 	}
 }
 
@@ -269,19 +312,36 @@ func (d *dispatcher) stop() {
 	}
 }
 
+// retryPolicy bounds the retries of delivering the packets of this node to a
+// peer. The packets of the other nodes are not retried, since they are
+// rebroadcast by several nodes.
+type retryPolicy struct {
+	// retries is the number of retries after a failed delivery.
+	retries int
+	// backoff is the wait before the first retry, doubled at each retry.
+	backoff time.Duration
+	// failed is called when a packet could not be delivered despite the
+	// retries.
+	failed func(addr string, err error)
+}
+
 type sender struct {
 	l      log.Logger
 	client net.ProtocolClient
 	to     net.Peer
 	newCh  chan broadcastPacket
+	retry  retryPolicy
+	done   chan struct{}
 }
 
-func newSender(l log.Logger, client net.ProtocolClient, to net.Peer, queueSize int) *sender {
+func newSender(l log.Logger, client net.ProtocolClient, to net.Peer, queueSize int, r retryPolicy) *sender {
 	return &sender{
 		l:      l,
 		client: client,
 		to:     to,
 		newCh:  make(chan broadcastPacket, queueSize),
+		retry:  r,
+		done:   make(chan struct{}),
 	}
 }
 
@@ -295,19 +355,47 @@ func (s *sender) sendPacket(p broadcastPacket) {
 
 func (s *sender) run() {
 	for newPacket := range s.newCh {
-		s.sendDirect(newPacket)
+		if err := s.send(newPacket); err != nil {
+			s.l.Debug("broadcast", "sending out", "error to", s.to.Address(), "err:", err)
+		}
 	}
 }
 
+// sendDirect sends a packet of this node, retrying failed deliveries until
+// the retries are exhausted or the sender stops.
 func (s *sender) sendDirect(newPacket broadcastPacket) {
+	backoff := s.retry.backoff
+	for attempt := 0; ; attempt++ {
+		err := s.send(newPacket)
+		if err == nil {
+			return
+		}
+		if attempt >= s.retry.retries {
+			s.l.Error("broadcast", "sending out", "error to", s.to.Address(), "attempts", attempt+1, "err", err)
+			if s.retry.failed != nil {
+				s.retry.failed(s.to.Address(), err)
+			}
+			return
+		}
+		s.l.Debug("broadcast", "sending out", "retry to", s.to.Address(), "in", backoff, "err", err)
+		select {
+		case <-time.After(backoff):
+			backoff *= 2
+		case <-s.done:
+			return
+		}
+	}
+}
+
+func (s *sender) send(newPacket broadcastPacket) error {
 	err := s.client.BroadcastDKG(context.Background(), s.to, newPacket)
-	if err != nil {
-		s.l.Debug("broadcast", "sending out", "error to", s.to.Address(), "err:", err)
-	} else {
+	if err == nil {
 		s.l.Debug("broadcast", "sending out", "to", s.to.Address())
 	}
+	return err
 }
 
 func (s *sender) stop() {
 	close(s.newCh)
+	close(s.done)
 }
@@ -44,13 +44,17 @@ func (c *Client) ChainInfo(p net.Peer) (*chain.Info, error) {
 // and decrypts the response, the randomness. Client will attempt a TLS
 // connection to the address in the identity if id.IsTLS() returns true
 func (c *Client) Private(id *key.Identity) ([]byte, error) {
-	ephScalar := key.KeyGroup.Scalar()
-	ephPoint := key.KeyGroup.Point().Mul(ephScalar, nil)
+	suite, err := id.Suite()
+	if err != nil {
+		return nil, err
+	}
+	ephScalar := suite.KeyGroup.Scalar()
+	ephPoint := suite.KeyGroup.Point().Mul(ephScalar, nil)
 	ephBuff, err := ephPoint.MarshalBinary()
 	if err != nil {
 		return nil, err
 	}
-	obj, err := ecies.Encrypt(key.KeyGroup, id.Key, ephBuff, EciesHash)
+	obj, err := ecies.Encrypt(suite.KeyGroup, id.Key, ephBuff, EciesHash)
 	if err != nil {
 		return nil, err
 	}
@@ -58,5 +62,5 @@ func (c *Client) Private(id *key.Identity) ([]byte, error) {
 	if err != nil {
 		return nil, err
 	}
-	return ecies.Decrypt(key.KeyGroup, ephScalar, resp.GetResponse(), EciesHash)
+	return ecies.Decrypt(suite.KeyGroup, ephScalar, resp.GetResponse(), EciesHash)
 }
@@ -1,10 +1,16 @@
 package core
 
 import (
+	"context"
+	"errors"
+	gohttp "net/http"
 	"path"
 	"time"
 
+	"github.com/drand/drand/audit"
+	"github.com/drand/drand/backup"
 	"github.com/drand/drand/chain"
+	"github.com/drand/drand/chain/beacon"
 	"github.com/drand/drand/key"
 	"github.com/drand/drand/log"
 	"github.com/drand/drand/net"
@@ -18,6 +24,7 @@ type ConfigOption func(*Config)
 
 // Config holds all relevant information for a drand node to run.
 type Config struct {
+	beaconID          string
 	configFolder      string
 	dbFolder          string
 	version           string
@@ -25,16 +32,28 @@ type Config struct {
 	publicListenAddr  string
 	controlPort       string
 	grpcOpts          []grpc.DialOption
+	dialer            net.Dialer
 	callOpts          []grpc.CallOption
 	dkgTimeout        time.Duration
+	dkgRetries        int
 	boltOpts          *bolt.Options
+	dbEngine          string
+	dbURL             string
 	beaconCbs         []func(*chain.Beacon)
 	dkgCallback       func(*key.Share)
+	signer            beacon.Signer
+	retention         beacon.Retention
+	backup            *backup.Config
 	insecure          bool
 	certPath          string
 	keyPath           string
 	certmanager       *net.CertManager
+	acme              *net.ACME
+	controlAuth       *net.ControlAuth
+	audit             *audit.Log
 	logger            log.Logger
+	logLevel          *log.Level
+	reloader          func() (*ReloadReport, error)
 	clock             clock.Clock
 	enablePrivate     bool
 }
@@ -45,6 +64,7 @@ func NewConfig(opts ...ConfigOption) *Config {
 	d := &Config{
 		configFolder: DefaultConfigFolder(),
 		dkgTimeout:   DefaultDKGTimeout,
+		dkgRetries:   DefaultDKGRetries,
 		//certmanager: net.NewCertManager(),
 		controlPort: DefaultControlPort,
 		logger:      log.DefaultLogger(),
@@ -63,6 +83,26 @@ func (d *Config) ConfigFolder() string {
 	return d.configFolder
 }
 
+// BeaconID returns the ID of the beacon this config is for, empty for the
+// default beacon of the daemon.
+func (d *Config) BeaconID() string {
+	return d.beaconID
+}
+
+// forBeacon returns the config of a beacon hosted by the daemon: it only
+// differs by the folders, which are namespaced by the beacon ID.
+func (d *Config) forBeacon(id string) *Config {
+	if id == "" {
+		return d
+	}
+	c := *d
+	c.beaconID = id
+	c.configFolder = path.Join(d.configFolder, MultiBeaconFolder, id)
+	c.dbFolder = path.Join(c.configFolder, DefaultDBFolder)
+	c.logger = d.logger.With("beacon_id", id)
+	return &c
+}
+
 // DBFolder returns the folder under which drand stores all generated beacons.
 func (d *Config) DBFolder() string {
 	return d.dbFolder
@@ -107,6 +147,36 @@ func (d *Config) Logger() log.Logger {
 	return d.logger
 }
 
+// AuditLog returns the audit log of the node, nil if it has none.
+func (d *Config) AuditLog() *audit.Log {
+	return d.audit
+}
+
+// record writes an operation of the beacon run by the actor of the context to
+// the audit log, if any. The details are key value pairs.
+func (d *Config) record(ctx context.Context, action, outcome string, err error, details ...string) {
+	if d.audit == nil {
+		return
+	}
+	e := audit.Event{
+		Action:   action,
+		Outcome:  outcome,
+		Actor:    audit.Actor(ctx),
+		Remote:   net.RemoteAddress(ctx),
+		BeaconID: d.beaconID,
+	}
+	if err != nil {
+		e.Error = err.Error()
+	}
+	if len(details) > 0 {
+		e.Details = make(map[string]string, len(details)/2)
+		for i := 0; i+1 < len(details); i += 2 {
+			e.Details[details[i]] = details[i+1]
+		}
+	}
+	d.audit.Record(e)
+}
+
 func (d *Config) callbacks(b *chain.Beacon) {
 	for _, fn := range d.beaconCbs {
 		fn(b)
@@ -127,6 +197,41 @@ func WithGrpcOptions(opts ...grpc.DialOption) ConfigOption {
 	}
 }
 
+// WithDialer makes the node open its connections to the other nodes with the
+// given dialer, for instance through a proxy, see net.NewProxyDialer.
+func WithDialer(dialer net.Dialer) ConfigOption {
+	return func(d *Config) {
+		d.dialer = dialer
+	}
+}
+
+// WithACME makes the public endpoint of the node serve the certificates of
+// an ACME certificate authority, such as Let's Encrypt, instead of the ones
+// given by WithTLS.
+func WithACME(a *net.ACME) ConfigOption {
+	return func(d *Config) {
+		d.acme = a
+	}
+}
+
+// publicGateway returns the gateway serving the handler on the public
+// endpoint of the node.
+func (d *Config) publicGateway(ctx context.Context, addr string, handler gohttp.Handler) (*net.PublicGateway, error) {
+	if d.acme != nil && !d.insecure {
+		return net.NewACMEPublicGateway(ctx, addr, d.acme, handler)
+	}
+	return net.NewRESTPublicGateway(ctx, addr, d.certPath, d.keyPath, d.certmanager, handler, d.insecure)
+}
+
+// dialOptions returns the grpc dialing options used when the node contacts
+// another, including its dialer if any.
+func (d *Config) dialOptions() []grpc.DialOption {
+	if d.dialer == nil {
+		return d.grpcOpts
+	}
+	return append(append([]grpc.DialOption{}, d.grpcOpts...), d.dialer.DialOption())
+}
+
 // WithCallOption applies grpc options when drand calls a gRPC method.
 func WithCallOption(opts ...grpc.CallOption) ConfigOption {
 	return func(d *Config) {
@@ -141,6 +246,14 @@ func WithDkgTimeout(t time.Duration) ConfigOption {
 	}
 }
 
+// WithDKGRetries sets the number of times the node retries to deliver one of
+// its DKG packets to a participant.
+func WithDKGRetries(n int) ConfigOption {
+	return func(d *Config) {
+		d.dkgRetries = n
+	}
+}
+
 // WithBoltOptions applies boltdb specific options when storing random beacons.
 func WithBoltOptions(opts *bolt.Options) ConfigOption {
 	return func(d *Config) {
@@ -148,6 +261,17 @@ func WithBoltOptions(opts *bolt.Options) ConfigOption {
 	}
 }
 
+// WithDBEngine sets the storage engine of the beacon database, one of
+// DBEngines, and the data source of the SQL engines. The data source is
+// optional for the SQLite engine, whose database is in the database folder by
+// default.
+func WithDBEngine(engine, url string) ConfigOption {
+	return func(d *Config) {
+		d.dbEngine = engine
+		d.dbURL = url
+	}
+}
+
 // BoltOptions returns the options given to the bolt db
 func (d *Config) BoltOptions() *bolt.Options {
 	return d.boltOpts
@@ -185,6 +309,31 @@ func WithDKGCallback(fn func(*key.Share)) ConfigOption {
 	}
 }
 
+// WithSigner sets the signer producing the partial signatures of the node,
+// such as a remote signing daemon, so that the node does not need the private
+// share on disk.
+func WithSigner(s beacon.Signer) ConfigOption {
+	return func(d *Config) {
+		d.signer = s
+	}
+}
+
+// WithRetention sets the policy deciding which past rounds the node prunes
+// from its store.
+func WithRetention(r beacon.Retention) ConfigOption {
+	return func(d *Config) {
+		d.retention = r
+	}
+}
+
+// WithBackup periodically backs up the beacon database and the key material
+// of the node to an object storage.
+func WithBackup(c *backup.Config) ConfigOption {
+	return func(d *Config) {
+		d.backup = c
+	}
+}
+
 // WithInsecure allows drand to listen on standard non-encrypted port and to
 // contact other nodes over non-encrypted TCP connections.
 func WithInsecure() ConfigOption {
@@ -220,6 +369,41 @@ func WithTrustedCerts(certPaths ...string) ConfigOption {
 	}
 }
 
+// WithControlAuth requires the commands of the control port to present a
+// token of a, allowing them according to its role.
+func WithControlAuth(a *net.ControlAuth) ConfigOption {
+	return func(d *Config) {
+		d.controlAuth = a
+		if d.audit != nil {
+			a.SetAuditLog(d.audit)
+		}
+	}
+}
+
+// WithAuditLog records the operations on the control plane and on the key
+// material of the node in the given audit log, including the commands its
+// control authentication denies.
+func WithAuditLog(a *audit.Log) ConfigOption {
+	return func(d *Config) {
+		d.audit = a
+		if d.controlAuth != nil {
+			d.controlAuth.SetAuditLog(a)
+		}
+	}
+}
+
+// WithMTLS enables the mutual TLS between the nodes: the node presents the
+// certificate of m to its peers, trusts its CA, and only serves the protocol
+// API to the peers presenting a certificate of the CA.
+func WithMTLS(m *net.MTLS) ConfigOption {
+	return func(d *Config) {
+		if d.certmanager == nil {
+			d.certmanager = net.NewCertManager()
+		}
+		d.certmanager.EnableMTLS(m)
+	}
+}
+
 // WithPublicListenAddress specifies the address the drand instance should bind to. It
 // is useful if you want to advertise a public proxy address and the drand
 // instance runs behind your network.
@@ -246,10 +430,37 @@ func WithControlPort(port string) ConfigOption {
 	}
 }
 
-// WithLogLevel sets the logging verbosity to the given level.
+// WithLogLevel sets the logging verbosity to the given level, which
+// SetLogLevel changes afterwards.
 func WithLogLevel(level int) ConfigOption {
 	return func(d *Config) {
-		d.logger = log.NewLogger(nil, level)
+		d.logLevel = log.NewLevel(level)
+		d.logger = log.NewLeveledLogger(nil, d.logLevel)
+	}
+}
+
+// SetLogLevel changes the logging verbosity of the running daemon, when it
+// was set with WithLogLevel.
+func (d *Config) SetLogLevel(level int) error {
+	if d.logLevel == nil {
+		return errors.New("the log level of the daemon can not be changed")
+	}
+	return d.logLevel.Set(level)
+}
+
+// ReloadReport is the outcome of a reload of the configuration of the daemon:
+// the settings applied right away, and the changed settings which only apply
+// after a restart.
+type ReloadReport struct {
+	Applied         []string `json:"applied"`
+	RestartRequired []string `json:"restart_required,omitempty"`
+}
+
+// WithReloader sets the function reloading the configuration of the daemon,
+// when asked on the control port.
+func WithReloader(reload func() (*ReloadReport, error)) ConfigOption {
+	return func(d *Config) {
+		d.reloader = reload
 	}
 }
 
@@ -22,6 +22,11 @@ func DefaultConfigFolder() string {
 // default it is relative to the DefaultConfigFolder path.
 const DefaultDBFolder = "db"
 
+// MultiBeaconFolder is the name of the folder holding the configuration of
+// each of the beacons hosted by a daemon besides the default one, in a folder
+// named after the beacon ID. It is relative to the configuration folder.
+const MultiBeaconFolder = "multibeacon"
+
 // DefaultBeaconPeriod is the period in which the beacon logic creates new
 // random beacon.
 const DefaultBeaconPeriod time.Duration = 1 * time.Minute
@@ -35,6 +40,14 @@ const DefaultControlPort = "8888"
 // (there is no malicious party).
 const DefaultDKGTimeout = 10 * time.Second
 
+// DefaultDKGRetries is the number of times a node retries to deliver one of
+// its DKG packets to a participant before giving up on it.
+const DefaultDKGRetries = 3
+
+// DKGRetryBackoff is the wait before retrying to deliver a DKG packet, doubled
+// at each retry.
+var DKGRetryBackoff = 1 * time.Second
+
 // EciesHash is the hash function used for the ECIES encryption used in the
 // private randomness feature.
 var EciesHash = sha256.New
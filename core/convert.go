@@ -5,30 +5,33 @@ import (
 	"fmt"
 
 	"github.com/drand/drand/chain"
-	"github.com/drand/drand/key"
 	pdkg "github.com/drand/drand/protobuf/crypto/dkg"
 	"github.com/drand/drand/protobuf/drand"
 	"github.com/drand/kyber"
 	"github.com/drand/kyber/share/dkg"
 )
 
-func beaconToProto(b *chain.Beacon) *drand.PublicRandResponse {
+// beaconToProto returns the message of a beacon, whose randomness is derived
+// from its signature with the derivation of the chain.
+func beaconToProto(b *chain.Beacon, d *chain.Derivation) *drand.PublicRandResponse {
 	return &drand.PublicRandResponse{
 		Round:       b.Round,
 		Signature:   b.Signature,
-		Randomness:  b.Randomness(),
+		Randomness:  d.Randomness(b.Signature),
 		SignatureV2: b.SignatureV2,
 	}
 }
 
-func protoToDKGPacket(d *pdkg.Packet) (dkg.Packet, error) {
+// protoToDKGPacket decodes a DKG packet whose points and scalars are of the
+// given key group.
+func protoToDKGPacket(d *pdkg.Packet, g kyber.Group) (dkg.Packet, error) {
 	switch packet := d.GetBundle().(type) {
 	case *pdkg.Packet_Deal:
-		return protoToDeal(packet.Deal)
+		return protoToDeal(packet.Deal, g)
 	case *pdkg.Packet_Response:
 		return protoToResp(packet.Response), nil
 	case *pdkg.Packet_Justification:
-		return protoToJustif(packet.Justification)
+		return protoToJustif(packet.Justification, g)
 	default:
 		return nil, errors.New("unknown packet")
 	}
@@ -47,12 +50,12 @@ func dkgPacketToProto(p dkg.Packet) (*pdkg.Packet, error) {
 	}
 }
 
-func protoToDeal(d *pdkg.DealBundle) (*dkg.DealBundle, error) {
+func protoToDeal(d *pdkg.DealBundle, g kyber.Group) (*dkg.DealBundle, error) {
 	bundle := new(dkg.DealBundle)
 	bundle.DealerIndex = d.DealerIndex
 	publics := make([]kyber.Point, 0, len(d.Commits))
 	for _, c := range d.Commits {
-		coeff := key.KeyGroup.Point()
+		coeff := g.Point()
 		if err := coeff.UnmarshalBinary(c); err != nil {
 			return nil, fmt.Errorf("invalid public coeff:%s", err)
 		}
@@ -89,12 +92,12 @@ func protoToResp(r *pdkg.ResponseBundle) *dkg.ResponseBundle {
 	return resp
 }
 
-func protoToJustif(j *pdkg.JustificationBundle) (*dkg.JustificationBundle, error) {
+func protoToJustif(j *pdkg.JustificationBundle, g kyber.Group) (*dkg.JustificationBundle, error) {
 	just := new(dkg.JustificationBundle)
 	just.DealerIndex = j.DealerIndex
 	just.Justifications = make([]dkg.Justification, len(j.Justifications))
 	for i, j := range j.Justifications {
-		share := key.KeyGroup.Scalar()
+		share := g.Scalar()
 		if err := share.UnmarshalBinary(j.Share); err != nil {
 			return nil, fmt.Errorf("invalid share: %s", err)
 		}
@@ -24,7 +24,7 @@ func TestConvertJustification(t *testing.T) {
 	justifProto, ok := proto.Bundle.(*pdkg.Packet_Justification)
 	require.True(t, ok)
 	require.NotNil(t, justifProto)
-	bundle, err := protoToJustif(justifProto.Justification)
+	bundle, err := protoToJustif(justifProto.Justification, key.KeyGroup)
 	require.NoError(t, err)
 	require.Equal(t, j, bundle)
 }
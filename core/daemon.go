@@ -0,0 +1,455 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	gohttp "net/http"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/drand/drand/chain"
+	"github.com/drand/drand/fs"
+	"github.com/drand/drand/http"
+	"github.com/drand/drand/key"
+	"github.com/drand/drand/log"
+	"github.com/drand/drand/net"
+	"github.com/drand/drand/protobuf/drand"
+)
+
+var _ net.Service = (*Daemon)(nil)
+
+// Daemon hosts several beacon processes, with different IDs, periods or
+// schemes, in a single process behind a single set of listeners. Each beacon
+// process has its own keys, group and store, under a folder named after its
+// ID. Requests are routed to the beacon process named by the beacon ID they
+// carry (see net.WithBeaconID), the requests without one going to the default
+// beacon, with the empty ID. The public HTTP API of a beacon is served under
+// its ID, for instance /beaconID/public/latest, the default beacon being
+// served at the root.
+type Daemon struct {
+	opts *Config
+	log  log.Logger
+
+	privGateway *net.PrivateGateway
+	pubGateway  *net.PublicGateway
+	control     net.ControlListener
+
+	lk        sync.RWMutex
+	processes map[string]*Drand
+	// handlers are the HTTP handlers of the public API of each beacon.
+	handlers map[string]gohttp.Handler
+	exitCh   chan bool
+}
+
+// NewDaemon returns a daemon listening on the addresses of the config, without
+// any beacon process. The private listen address must be set.
+func NewDaemon(c *Config) (*Daemon, error) {
+	if !c.insecure && (c.certPath == "" || c.keyPath == "") {
+		return nil, errors.New("config: need to set WithInsecure if no certificate and private key path given")
+	}
+	privAddr := c.PrivateListenAddress("")
+	if privAddr == "" {
+		return nil, errors.New("config: a daemon needs a private listen address")
+	}
+	pubAddr := c.PublicListenAddress("")
+	dd := &Daemon{
+		opts:      c,
+		log:       c.Logger(),
+		processes: make(map[string]*Drand),
+		handlers:  make(map[string]gohttp.Handler),
+		exitCh:    make(chan bool, 1),
+	}
+	// see setupDrand about the context
+	ctx := context.Background()
+	var err error
+	dd.log.Info("network", "init", "insecure", c.insecure)
+	if pubAddr != "" {
+		if dd.pubGateway, err = c.publicGateway(ctx, pubAddr, dd); err != nil {
+			return nil, err
+		}
+	}
+	dd.privGateway, err = net.NewGRPCPrivateGateway(ctx, privAddr, c.certPath, c.keyPath, c.certmanager, dd, c.insecure, c.dialOptions()...)
+	if err != nil {
+		return nil, err
+	}
+	dd.control = net.NewTCPGrpcControlListener(dd, c.ControlPort(), c.controlAuth.ServerOptions()...)
+	go dd.control.Start()
+	dd.log.Info("private_listen", privAddr, "control_port", c.ControlPort(), "public_listen", pubAddr, "folder", c.ConfigFolder())
+	dd.privGateway.StartAll()
+	if dd.pubGateway != nil {
+		dd.pubGateway.StartAll()
+	}
+	return dd, nil
+}
+
+// BeaconIDs returns the IDs of the beacons configured in a configuration
+// folder besides the default one, in the MultiBeaconFolder folder.
+func BeaconIDs(configFolder string) ([]string, error) {
+	folders, err := fs.Folders(path.Join(configFolder, MultiBeaconFolder))
+	if err != nil {
+		return nil, err
+	}
+	ids := make([]string, 0, len(folders))
+	for _, f := range folders {
+		ids = append(ids, path.Base(f))
+	}
+	return ids, nil
+}
+
+// AddBeacon starts hosting the beacon with the given ID, the empty ID being
+// the default beacon. The key pair of the beacon must have been generated
+// already. When the beacon already ran its DKG, it catches up with its chain
+// and starts generating randomness; otherwise it waits for a DKG.
+func (dd *Daemon) AddBeacon(id string) (*Drand, error) {
+	c := dd.opts.forBeacon(id)
+	dd.lk.Lock()
+	defer dd.lk.Unlock()
+	if _, ok := dd.processes[id]; ok {
+		return nil, fmt.Errorf("drand: beacon %q already hosted", id)
+	}
+	store := key.NewFileStore(c.ConfigFolder())
+	d, err := newBeaconProcess(store, c)
+	if err != nil {
+		return nil, err
+	}
+	d.daemon = dd
+	d.privGateway = dd.privGateway.ForBeacon(id)
+	if dd.pubGateway != nil {
+		handler, err := http.New(context.Background(), &drandProxy{d}, c.Version(), d.log.With("server", "http"))
+		if err != nil {
+			return nil, err
+		}
+		dd.handlers[id] = handler
+	}
+	dd.processes[id] = d
+
+	// XXX same logic as the daemon command for a single beacon
+	_, errG := store.LoadGroup()
+	share, errS := store.LoadShare()
+	if errG != nil || errS != nil {
+		d.log.Info("beacon", "fresh", "expect", "dkg")
+		d.ResumeDKG()
+		return d, nil
+	}
+	share.Wipe()
+	if err := d.load(); err != nil {
+		return nil, err
+	}
+	d.StartBeacon(true)
+	d.ResumeDKG()
+	return d, nil
+}
+
+// Beacon returns the process of a hosted beacon.
+func (dd *Daemon) Beacon(id string) (*Drand, bool) {
+	dd.lk.RLock()
+	defer dd.lk.RUnlock()
+	d, ok := dd.processes[id]
+	return d, ok
+}
+
+// Beacons returns the IDs of the hosted beacons.
+func (dd *Daemon) Beacons() []string {
+	dd.lk.RLock()
+	defer dd.lk.RUnlock()
+	ids := make([]string, 0, len(dd.processes))
+	for id := range dd.processes {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// remove stops routing the requests to a beacon process.
+func (dd *Daemon) remove(id string) {
+	dd.lk.Lock()
+	defer dd.lk.Unlock()
+	delete(dd.processes, id)
+	delete(dd.handlers, id)
+}
+
+// Stop stops all the beacon processes and the listeners.
+func (dd *Daemon) Stop(ctx context.Context) {
+	dd.lk.Lock()
+	processes := dd.processes
+	dd.processes = make(map[string]*Drand)
+	dd.handlers = make(map[string]gohttp.Handler)
+	dd.lk.Unlock()
+	for _, d := range processes {
+		d.StopBeacon()
+		select {
+		case d.exitCh <- true:
+		default:
+		}
+	}
+	if dd.pubGateway != nil {
+		dd.pubGateway.StopAll(ctx)
+	}
+	dd.privGateway.StopAll(ctx)
+	dd.control.Stop()
+	dd.exitCh <- true
+}
+
+// StopGracefully stops the daemon once each beacon it hosts contributed to
+// the round in progress, as Drand.StopGracefully does.
+func (dd *Daemon) StopGracefully(ctx context.Context) {
+	var wg sync.WaitGroup
+	for _, id := range dd.Beacons() {
+		d, ok := dd.Beacon(id)
+		if !ok {
+			continue
+		}
+		wg.Add(1)
+		go func(d *Drand) {
+			defer wg.Done()
+			d.finishRound(ctx)
+		}(d)
+	}
+	wg.Wait()
+	dd.Stop(ctx)
+}
+
+// WaitExit returns a channel that signals when the daemon stops its
+// operations.
+func (dd *Daemon) WaitExit() chan bool {
+	return dd.exitCh
+}
+
+// process returns the beacon process a request is meant for.
+func (dd *Daemon) process(ctx context.Context) (*Drand, error) {
+	id := net.BeaconIDFromContext(ctx)
+	d, ok := dd.Beacon(id)
+	if !ok {
+		return nil, fmt.Errorf("drand: beacon %q not hosted by this node", id)
+	}
+	return d, nil
+}
+
+// ServeHTTP routes the requests of the public HTTP API to the handler of the
+// beacon named by the first element of the path, or to the handler of the
+// default beacon.
+func (dd *Daemon) ServeHTTP(w gohttp.ResponseWriter, r *gohttp.Request) {
+	first := strings.SplitN(strings.TrimPrefix(r.URL.Path, "/"), "/", 2)[0]
+	dd.lk.RLock()
+	h, ok := dd.handlers[first]
+	if ok && first != "" {
+		h = gohttp.StripPrefix("/"+first, h)
+	} else {
+		h, ok = dd.handlers[""]
+	}
+	dd.lk.RUnlock()
+	if !ok {
+		gohttp.NotFound(w, r)
+		return
+	}
+	h.ServeHTTP(w, r)
+}
+
+// Public API
+
+// PublicRand routes the request to the beacon process.
+func (dd *Daemon) PublicRand(ctx context.Context, in *drand.PublicRandRequest) (*drand.PublicRandResponse, error) {
+	d, err := dd.process(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return d.PublicRand(ctx, in)
+}
+
+// PublicRandStream routes the request to the beacon process.
+func (dd *Daemon) PublicRandStream(in *drand.PublicRandRequest, stream drand.Public_PublicRandStreamServer) error {
+	d, err := dd.process(stream.Context())
+	if err != nil {
+		return err
+	}
+	return d.PublicRandStream(in, stream)
+}
+
+// PrivateRand routes the request to the beacon process.
+func (dd *Daemon) PrivateRand(ctx context.Context, in *drand.PrivateRandRequest) (*drand.PrivateRandResponse, error) {
+	d, err := dd.process(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return d.PrivateRand(ctx, in)
+}
+
+// ChainInfo routes the request, of the public or control API, to the beacon
+// process.
+func (dd *Daemon) ChainInfo(ctx context.Context, in *drand.ChainInfoRequest) (*drand.ChainInfoPacket, error) {
+	d, err := dd.process(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return d.ChainInfo(ctx, in)
+}
+
+// chainInfo routes the request to the beacon process.
+func (dd *Daemon) chainInfo(ctx context.Context) (*chain.Info, error) {
+	d, err := dd.process(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return d.chainInfo(ctx)
+}
+
+// Home routes the request to the beacon process.
+func (dd *Daemon) Home(ctx context.Context, in *drand.HomeRequest) (*drand.HomeResponse, error) {
+	d, err := dd.process(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return d.Home(ctx, in)
+}
+
+// Protocol API
+
+// GetIdentity routes the request to the beacon process.
+func (dd *Daemon) GetIdentity(ctx context.Context, in *drand.IdentityRequest) (*drand.Identity, error) {
+	d, err := dd.process(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return d.GetIdentity(ctx, in)
+}
+
+// SignalDKGParticipant routes the request to the beacon process.
+func (dd *Daemon) SignalDKGParticipant(ctx context.Context, in *drand.SignalDKGPacket) (*drand.Empty, error) {
+	d, err := dd.process(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return d.SignalDKGParticipant(ctx, in)
+}
+
+// PushDKGInfo routes the request to the beacon process.
+func (dd *Daemon) PushDKGInfo(ctx context.Context, in *drand.DKGInfoPacket) (*drand.Empty, error) {
+	d, err := dd.process(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return d.PushDKGInfo(ctx, in)
+}
+
+// BroadcastDKG routes the request to the beacon process.
+func (dd *Daemon) BroadcastDKG(ctx context.Context, in *drand.DKGPacket) (*drand.Empty, error) {
+	d, err := dd.process(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return d.BroadcastDKG(ctx, in)
+}
+
+// PartialBeacon routes the request to the beacon process.
+func (dd *Daemon) PartialBeacon(ctx context.Context, in *drand.PartialBeaconPacket) (*drand.Empty, error) {
+	d, err := dd.process(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return d.PartialBeacon(ctx, in)
+}
+
+// Departure routes the request to the beacon process.
+func (dd *Daemon) Departure(ctx context.Context, in *drand.DeparturePacket) (*drand.Empty, error) {
+	d, err := dd.process(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return d.Departure(ctx, in)
+}
+
+// SyncChain routes the request to the beacon process.
+func (dd *Daemon) SyncChain(in *drand.SyncRequest, stream drand.Protocol_SyncChainServer) error {
+	d, err := dd.process(stream.Context())
+	if err != nil {
+		return err
+	}
+	return d.SyncChain(in, stream)
+}
+
+// Control API
+
+// PingPong responds for the daemon, whatever the beacon.
+func (dd *Daemon) PingPong(ctx context.Context, in *drand.Ping) (*drand.Pong, error) {
+	return &drand.Pong{}, nil
+}
+
+// InitDKG routes the request to the beacon process.
+func (dd *Daemon) InitDKG(ctx context.Context, in *drand.InitDKGPacket) (*drand.GroupPacket, error) {
+	d, err := dd.process(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return d.InitDKG(ctx, in)
+}
+
+// InitReshare routes the request to the beacon process.
+func (dd *Daemon) InitReshare(ctx context.Context, in *drand.InitResharePacket) (*drand.GroupPacket, error) {
+	d, err := dd.process(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return d.InitReshare(ctx, in)
+}
+
+// Share routes the request to the beacon process.
+func (dd *Daemon) Share(ctx context.Context, in *drand.ShareRequest) (*drand.ShareResponse, error) {
+	d, err := dd.process(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return d.Share(ctx, in)
+}
+
+// PublicKey routes the request to the beacon process.
+func (dd *Daemon) PublicKey(ctx context.Context, in *drand.PublicKeyRequest) (*drand.PublicKeyResponse, error) {
+	d, err := dd.process(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return d.PublicKey(ctx, in)
+}
+
+// PrivateKey routes the request to the beacon process.
+func (dd *Daemon) PrivateKey(ctx context.Context, in *drand.PrivateKeyRequest) (*drand.PrivateKeyResponse, error) {
+	d, err := dd.process(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return d.PrivateKey(ctx, in)
+}
+
+// GroupFile routes the request to the beacon process.
+func (dd *Daemon) GroupFile(ctx context.Context, in *drand.GroupRequest) (*drand.GroupPacket, error) {
+	d, err := dd.process(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return d.GroupFile(ctx, in)
+}
+
+// Shutdown stops the beacon process named by the request, or the whole
+// daemon when the request does not name a beacon.
+func (dd *Daemon) Shutdown(ctx context.Context, in *drand.ShutdownRequest) (*drand.ShutdownResponse, error) {
+	if net.BeaconIDFromContext(ctx) == "" {
+		dd.Stop(ctx)
+		return nil, nil
+	}
+	d, err := dd.process(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return d.Shutdown(ctx, in)
+}
+
+// StartFollowChain routes the request to the beacon process.
+func (dd *Daemon) StartFollowChain(in *drand.StartFollowRequest, stream drand.Control_StartFollowChainServer) error {
+	d, err := dd.process(stream.Context())
+	if err != nil {
+		return err
+	}
+	return d.StartFollowChain(in, stream)
+}
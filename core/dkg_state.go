@@ -0,0 +1,457 @@
+package core
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"sync"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"github.com/drand/drand/fs"
+	"github.com/drand/drand/key"
+	"github.com/drand/drand/log"
+	"github.com/drand/drand/protobuf/drand"
+	"github.com/drand/kyber/share/dkg"
+	"github.com/golang/protobuf/proto"
+	"golang.org/x/crypto/blake2b"
+)
+
+// DKGStateFileName is the name of the file holding the state of the DKG or
+// resharing in progress, in the group folder.
+const DKGStateFileName = "dkg_state.private"
+
+// dkgSeedSize is the size of the seed of the randomness of the node's deals.
+const dkgSeedSize = 32
+
+// DKGState is the state of a DKG or resharing in progress, persisted so that
+// a node restarting during the ceremony can rejoin it. The randomness of the
+// node's deals is derived from a seed kept in the state, so that the restarted
+// node sends the same deals again, and the packets received from the other
+// nodes are replayed on restart.
+type DKGState struct {
+	Reshare  bool          `json:"reshare"`
+	Leader   bool          `json:"leader"`
+	Timeouts PhaseTimeouts `json:"timeouts"`
+	// Seed derives the randomness of the node's deals. It is as secret as
+	// the share.
+	Seed []byte `json:"seed"`
+	// Started is the time the phases started, in unix nanoseconds, zero
+	// before the leader kicked off the ceremony.
+	Started int64 `json:"started"`
+	// DealRetries and ResponseRetries count the times the node extended the
+	// deal and response phases, see PhaseTimeouts.Retries.
+	DealRetries     int `json:"dealRetries,omitempty"`
+	ResponseRetries int `json:"responseRetries,omitempty"`
+	// Delay shifts the end of the phases, by the time the node ended phases
+	// later than planned, so that each phase lasts its whole timeout.
+	Delay time.Duration `json:"delay,omitempty"`
+	// Group is the TOML encoding of the target group, OldGroup the encoding
+	// of the group resharing.
+	Group    string `json:"group"`
+	OldGroup string `json:"oldGroup,omitempty"`
+	// Packets are the DKG packets received from the other nodes, encoded in
+	// protobuf.
+	Packets [][]byte `json:"packets"`
+}
+
+// newDKGState returns the state of a ceremony about to start. The seed is
+// mixed with the user entropy when given, or taken from it only when the user
+// asks so.
+func newDKGState(reshare, leader bool, timeouts PhaseTimeouts, group, oldGroup *key.Group, entropy io.Reader, userOnly bool) (*DKGState, error) {
+	seed := make([]byte, dkgSeedSize)
+	if entropy != nil && userOnly {
+		if _, err := io.ReadFull(entropy, seed); err != nil {
+			return nil, fmt.Errorf("reading user entropy: %w", err)
+		}
+	} else {
+		if _, err := rand.Read(seed); err != nil {
+			return nil, err
+		}
+		if entropy != nil {
+			user := make([]byte, dkgSeedSize)
+			if _, err := io.ReadFull(entropy, user); err != nil {
+				return nil, fmt.Errorf("reading user entropy: %w", err)
+			}
+			h := sha256.New()
+			_, _ = h.Write(seed)
+			_, _ = h.Write(user)
+			seed = h.Sum(nil)
+		}
+	}
+	st := &DKGState{Reshare: reshare, Leader: leader, Timeouts: timeouts, Seed: seed}
+	var err error
+	if st.Group, err = encodeGroup(group); err != nil {
+		return nil, err
+	}
+	if oldGroup != nil {
+		if st.OldGroup, err = encodeGroup(oldGroup); err != nil {
+			return nil, err
+		}
+	}
+	return st, nil
+}
+
+// reader returns the randomness of the node's deals.
+func (s *DKGState) reader() (io.Reader, error) {
+	return blake2b.NewXOF(blake2b.OutputLengthUnknown, s.Seed)
+}
+
+// Groups returns the target group and, for a resharing, the old group.
+func (s *DKGState) Groups() (group, oldGroup *key.Group, err error) {
+	if group, err = decodeGroup(s.Group); err != nil {
+		return nil, nil, err
+	}
+	if s.OldGroup == "" {
+		return group, nil, nil
+	}
+	if oldGroup, err = decodeGroup(s.OldGroup); err != nil {
+		return nil, nil, err
+	}
+	return group, oldGroup, nil
+}
+
+// Deadline returns the end of the ceremony, zero when it did not start.
+func (s *DKGState) Deadline() time.Time {
+	if s.Started == 0 {
+		return time.Time{}
+	}
+	return s.start().Add(s.Timeouts.Total())
+}
+
+// start returns the start of the phases, shifted by the delay of the phases
+// which ended late.
+func (s *DKGState) start() time.Time {
+	return time.Unix(0, s.Started).Add(s.Delay)
+}
+
+func encodeGroup(g *key.Group) (string, error) {
+	var buff bytes.Buffer
+	if err := toml.NewEncoder(&buff).Encode(g.TOML()); err != nil {
+		return "", err
+	}
+	return buff.String(), nil
+}
+
+func decodeGroup(s string) (*key.Group, error) {
+	g := new(key.Group)
+	v := g.TOMLValue()
+	if _, err := toml.Decode(s, v); err != nil {
+		return nil, err
+	}
+	return g, g.FromTOML(v)
+}
+
+// DKGStatePath returns the path of the DKG state file in a configuration
+// folder.
+func DKGStatePath(configFolder string) string {
+	return path.Join(configFolder, key.GroupFolderName, DKGStateFileName)
+}
+
+// LoadDKGState returns the state of the DKG in progress persisted in a
+// configuration folder, nil when there is none.
+func LoadDKGState(configFolder string) (*DKGState, error) {
+	buff, err := ioutil.ReadFile(DKGStatePath(configFolder))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	content := key.SecureBytes(buff)
+	defer content.Wipe()
+	st := new(DKGState)
+	if err := json.Unmarshal(content, st); err != nil {
+		return nil, fmt.Errorf("invalid DKG state: %w", err)
+	}
+	return st, nil
+}
+
+// AbortDKG deletes the state of the DKG in progress persisted in a
+// configuration folder, so that the node does not rejoin the ceremony when it
+// restarts.
+func AbortDKG(configFolder string) error {
+	err := os.Remove(DKGStatePath(configFolder))
+	if os.IsNotExist(err) {
+		return errors.New("no DKG in progress")
+	}
+	return err
+}
+
+// dkgStateFile persists the state of a ceremony as it progresses.
+type dkgStateFile struct {
+	sync.Mutex
+	path  string
+	state *DKGState
+}
+
+// save writes the state to a temporary file first, so that a crash while
+// writing does not lose the previous state.
+func (f *dkgStateFile) save() error {
+	buff, err := json.Marshal(f.state)
+	if err != nil {
+		return err
+	}
+	content := key.SecureBytes(buff)
+	defer content.Wipe()
+	tmp := f.path + ".tmp"
+	fd, err := fs.CreateSecureFile(tmp)
+	if err != nil {
+		return err
+	}
+	if _, err := fd.Write(content); err != nil {
+		fd.Close()
+		return err
+	}
+	if err := fd.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp, f.path)
+}
+
+// start records the time the phases started, unless they started before the
+// node restarted.
+func (f *dkgStateFile) start(t time.Time) error {
+	f.Lock()
+	defer f.Unlock()
+	if f.state.Started != 0 {
+		return nil
+	}
+	f.state.Started = t.UnixNano()
+	return f.save()
+}
+
+// retries returns a pointer to the count of the extensions of a phase, nil
+// for the phases which are never extended. It must be called with the lock.
+func (f *dkgStateFile) retries(phase dkg.Phase) *int {
+	switch phase {
+	case dkg.DealPhase:
+		return &f.state.DealRetries
+	case dkg.ResponsePhase:
+		return &f.state.ResponseRetries
+	default:
+		return nil
+	}
+}
+
+// extend extends a phase of the ceremony by its timeout, unless it was
+// extended as many times as allowed or it would not run anymore at the given
+// time. It returns the number of times the phase was extended and whether it
+// was extended now.
+func (f *dkgStateFile) extend(phase dkg.Phase, now time.Time) (int, bool, error) {
+	f.Lock()
+	defer f.Unlock()
+	count := f.retries(phase)
+	if count == nil || *count >= f.state.Timeouts.Retries {
+		return 0, false, nil
+	}
+	st := f.state
+	end := st.Timeouts.end(st.start(), phase, st.DealRetries, st.ResponseRetries)
+	timeout := st.Timeouts.Deal
+	if phase == dkg.ResponsePhase {
+		timeout = st.Timeouts.Response
+	}
+	if !now.Before(end.Add(timeout)) {
+		return *count, false, nil
+	}
+	*count++
+	return *count, true, f.save()
+}
+
+// addPacket records a packet received from another node.
+func (f *dkgStateFile) addPacket(p *drand.DKGPacket) error {
+	buff, err := proto.Marshal(p)
+	if err != nil {
+		return err
+	}
+	f.Lock()
+	defer f.Unlock()
+	f.state.Packets = append(f.state.Packets, buff)
+	return f.save()
+}
+
+// remove deletes the state once the ceremony is over.
+func (f *dkgStateFile) remove() {
+	f.Lock()
+	defer f.Unlock()
+	_ = os.Remove(f.path)
+}
+
+// packets returns the recorded packets.
+func (f *dkgStateFile) packets() ([]*drand.DKGPacket, error) {
+	f.Lock()
+	defer f.Unlock()
+	out := make([]*drand.DKGPacket, 0, len(f.state.Packets))
+	for _, buff := range f.state.Packets {
+		p := new(drand.DKGPacket)
+		if err := proto.Unmarshal(buff, p); err != nil {
+			return nil, err
+		}
+		out = append(out, p)
+	}
+	return out, nil
+}
+
+// persistDKG starts persisting the state of a ceremony and sets the
+// randomness of the node's deals, derived from the seed of the state.
+func (d *Drand) persistDKG(st *DKGState, config *dkg.Config) (*dkgStateFile, error) {
+	reader, err := st.reader()
+	if err != nil {
+		return nil, err
+	}
+	config.Reader = reader
+	config.UserReaderOnly = true
+	f := &dkgStateFile{path: DKGStatePath(d.opts.ConfigFolder()), state: st}
+	f.Lock()
+	defer f.Unlock()
+	if err := f.save(); err != nil {
+		return nil, fmt.Errorf("persisting DKG state: %w", err)
+	}
+	return f, nil
+}
+
+// record returns the callback recording the packets received by the board.
+func (f *dkgStateFile) record(l log.Logger) func(*drand.DKGPacket) {
+	return func(p *drand.DKGPacket) {
+		if err := f.addPacket(p); err != nil {
+			l.Error("dkg_persist", "packet", "err", err)
+		}
+	}
+}
+
+// replayDKG passes the packets received before the node restarted to the
+// board.
+func (d *Drand) replayDKG(board *broadcast, f *dkgStateFile) {
+	packets, err := f.packets()
+	if err != nil {
+		d.log.Error("dkg_resume", "packets", "err", err)
+		return
+	}
+	if len(packets) > 0 {
+		board.replay(packets)
+	}
+}
+
+// hasStarted returns true when the phases of the ceremony started before the
+// node restarted.
+func (s *DKGState) hasStarted() bool {
+	return s != nil && s.Started != 0
+}
+
+// phaseEnd returns the end of a phase of the ceremony, which must have
+// started, and the number of times the phase was extended.
+func (f *dkgStateFile) phaseEnd(phase dkg.Phase) (time.Time, int) {
+	f.Lock()
+	defer f.Unlock()
+	st := f.state
+	retry := 0
+	if count := f.retries(phase); count != nil {
+		retry = *count
+	}
+	return st.Timeouts.end(st.start(), phase, st.DealRetries, st.ResponseRetries), retry
+}
+
+// delay shifts the end of the phases by the time the node ended a phase late,
+// such as when its clock jumped, so that the next phases still last their
+// whole timeout.
+func (f *dkgStateFile) delay(late time.Duration) error {
+	f.Lock()
+	defer f.Unlock()
+	f.state.Delay += late
+	return f.save()
+}
+
+// getPhaser returns the phaser of a ceremony, ending the phases at fixed times
+// from the start of the ceremony, so that a node which restarted ends them at
+// the same times as the other nodes. A phase the node ends late delays the
+// next ones, which last their whole timeout. A deal or response phase which
+// some participants did not complete is extended by its timeout, as many
+// times as the timeouts allow, to let them retry.
+func (d *Drand) getPhaser(f *dkgStateFile, m *dkgMonitor) *dkg.TimePhaser {
+	return dkg.NewTimePhaserFunc(func(phase dkg.Phase) {
+		for {
+			end, retry := f.phaseEnd(phase)
+			m.phase(phase, end, retry)
+			if wait := end.Sub(d.opts.clock.Now()); wait > 0 {
+				d.opts.clock.Sleep(wait)
+				if late := d.opts.clock.Now().Sub(end); late > 0 {
+					if err := f.delay(late); err != nil {
+						d.log.Error("dkg_persist", "delay", "err", err)
+					}
+				}
+			}
+			missing := m.Status().Missing(phaseName(phase))
+			if len(missing) == 0 {
+				break
+			}
+			retry, extended, err := f.extend(phase, d.opts.clock.Now())
+			if err != nil {
+				d.log.Error("dkg_persist", "extend", "err", err)
+			}
+			if !extended {
+				break
+			}
+			d.log.Warn("phaser_retry", phase, "retry", retry, "missing", missing)
+		}
+		d.log.Debug("phaser_finished", phase)
+	})
+}
+
+// ResumeDKG rejoins, in the background, the DKG or resharing the node took
+// part in before it restarted. It returns false when there is no ceremony to
+// rejoin.
+func (d *Drand) ResumeDKG() bool {
+	st, err := LoadDKGState(d.opts.ConfigFolder())
+	if err != nil {
+		d.log.Error("dkg_resume", "load", "err", err)
+		return false
+	}
+	if st == nil {
+		return false
+	}
+	if deadline := st.Deadline(); !deadline.IsZero() && d.opts.clock.Now().After(deadline) {
+		d.log.Info("dkg_resume", "expired", "deadline", deadline)
+		_ = AbortDKG(d.opts.ConfigFolder())
+		return false
+	}
+	group, oldGroup, err := st.Groups()
+	if err != nil {
+		d.log.Error("dkg_resume", "groups", "err", err)
+		return false
+	}
+	d.log.Info("dkg_resume", "rejoin", "reshare", st.Reshare, "leader", st.Leader, "packets", len(st.Packets))
+	go func() {
+		var err error
+		if st.Reshare {
+			_, err = d.runResharing(st.Leader, oldGroup, group, st.Timeouts, st)
+		} else {
+			_, err = d.runDKG(st.Leader, group, st.Timeouts, nil, st)
+		}
+		if err != nil {
+			d.log.Error("dkg_resume", "failed", "err", err)
+		}
+	}()
+	return true
+}
+
+// startPhases starts the phases of the ceremony in progress and records their
+// start, unless they already started. It must be called with the state lock.
+func (d *Drand) startPhases() {
+	if d.dkgInfo.started {
+		return
+	}
+	d.dkgInfo.started = true
+	if d.dkgInfo.persisted != nil {
+		if err := d.dkgInfo.persisted.start(d.opts.clock.Now()); err != nil {
+			d.log.Error("dkg_persist", "start", "err", err)
+		}
+	}
+	go d.dkgInfo.phaser.Start()
+}
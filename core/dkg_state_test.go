@@ -0,0 +1,92 @@
+package core
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"testing"
+	"time"
+
+	"github.com/drand/drand/key"
+	"github.com/drand/drand/protobuf/drand"
+	"github.com/drand/drand/test"
+	"github.com/drand/kyber/share/dkg"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDKGStatePersistence(t *testing.T) {
+	folder, err := ioutil.TempDir("", "drand-dkg-state")
+	require.NoError(t, err)
+	defer os.RemoveAll(folder)
+	require.NoError(t, os.MkdirAll(path.Join(folder, key.GroupFolderName), 0740))
+
+	st, err := LoadDKGState(folder)
+	require.NoError(t, err)
+	require.Nil(t, st)
+
+	_, oldGroup := test.BatchIdentities(3)
+	_, group := test.BatchIdentities(4)
+	timeouts := PhaseTimeouts{Deal: 10 * time.Second, Response: 10 * time.Second, Justification: 10 * time.Second}
+	st, err = newDKGState(true, false, timeouts, group, oldGroup, nil, false)
+	require.NoError(t, err)
+	f := &dkgStateFile{path: DKGStatePath(folder), state: st}
+	require.NoError(t, f.save())
+
+	j := &dkg.JustificationBundle{DealerIndex: 2, Signature: []byte{1, 2, 3}}
+	require.NoError(t, f.addPacket(&drand.DKGPacket{Dkg: justifToProto(j)}))
+	now := time.Now()
+	require.NoError(t, f.start(now))
+	// the phases started before a restart keep their start time
+	require.NoError(t, f.start(now.Add(time.Minute)))
+
+	loaded, err := LoadDKGState(folder)
+	require.NoError(t, err)
+	require.True(t, loaded.Reshare)
+	require.Equal(t, st.Seed, loaded.Seed)
+	require.Equal(t, now.Add(30*time.Second).UnixNano(), loaded.Deadline().UnixNano())
+	require.Len(t, loaded.Packets, 1)
+	packets, err := (&dkgStateFile{state: loaded}).packets()
+	require.NoError(t, err)
+	replayed, err := protoToDKGPacket(packets[0].GetDkg(), key.KeyGroup)
+	require.NoError(t, err)
+	require.Equal(t, j.Hash(), replayed.Hash())
+	g, og, err := loaded.Groups()
+	require.NoError(t, err)
+	require.Equal(t, group.Hash(), g.Hash())
+	require.Equal(t, oldGroup.Hash(), og.Hash())
+
+	// the deals of a restarted node are derived from the same randomness
+	r1, err := st.reader()
+	require.NoError(t, err)
+	r2, err := loaded.reader()
+	require.NoError(t, err)
+	b1, b2 := make([]byte, 64), make([]byte, 64)
+	_, err = io.ReadFull(r1, b1)
+	require.NoError(t, err)
+	_, err = io.ReadFull(r2, b2)
+	require.NoError(t, err)
+	require.Equal(t, b1, b2)
+
+	require.NoError(t, AbortDKG(folder))
+	require.Error(t, AbortDKG(folder))
+}
+
+func TestDKGStateUserEntropy(t *testing.T) {
+	_, group := test.BatchIdentities(3)
+	user := bytes.Repeat([]byte{42}, dkgSeedSize)
+	timeouts, err := newPhaseTimeouts(0, nil, 0)
+	require.NoError(t, err)
+	st, err := newDKGState(false, true, timeouts, group, nil, bytes.NewReader(user), true)
+	require.NoError(t, err)
+	require.Equal(t, user, st.Seed)
+	def := PhaseTimeouts{Deal: DefaultDKGTimeout, Response: DefaultDKGTimeout, Justification: DefaultDKGTimeout}
+	require.Equal(t, def, st.Timeouts)
+	require.Equal(t, 3*DefaultDKGTimeout, st.Timeouts.Total())
+
+	st, err = newDKGState(false, true, timeouts, group, nil, bytes.NewReader(user), false)
+	require.NoError(t, err)
+	require.NotEqual(t, user, st.Seed)
+	require.Len(t, st.Seed, dkgSeedSize)
+}
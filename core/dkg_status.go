@@ -0,0 +1,310 @@
+package core
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/drand/drand/key"
+	"github.com/drand/kyber/share/dkg"
+)
+
+// PhaseTimeouts are the durations of the deal, response and justification
+// phases of a DKG or resharing.
+type PhaseTimeouts struct {
+	Deal          time.Duration `json:"deal"`
+	Response      time.Duration `json:"response"`
+	Justification time.Duration `json:"justification"`
+	// Retries is the number of times a node extends the deal phase, and then
+	// the response phase, by their timeout while some participants did not
+	// complete them.
+	Retries int `json:"retries,omitempty"`
+}
+
+// newPhaseTimeouts returns the phase timeouts of a DKG whose packets set the
+// given timeout in seconds, overridden by the per-phase timeouts in seconds of
+// the leader when set. A zero timeout is the default one.
+func newPhaseTimeouts(timeout uint32, overrides []uint32, retries uint32) (PhaseTimeouts, error) {
+	def := time.Duration(timeout) * time.Second
+	if timeout == 0 {
+		def = DefaultDKGTimeout
+	}
+	t := PhaseTimeouts{Deal: def, Response: def, Justification: def, Retries: int(retries)}
+	if len(overrides) == 0 {
+		return t, nil
+	}
+	if len(overrides) != 3 {
+		return t, errors.New("dkg: expected the timeouts of the deal, response and justification phases")
+	}
+	for i, p := range []*time.Duration{&t.Deal, &t.Response, &t.Justification} {
+		if overrides[i] != 0 {
+			*p = time.Duration(overrides[i]) * time.Second
+		}
+	}
+	return t, nil
+}
+
+// end returns the end of a phase of a ceremony whose phases started at the
+// given time, once the deal and response phases were extended the given
+// number of times.
+func (t PhaseTimeouts) end(started time.Time, phase dkg.Phase, deal, response int) time.Time {
+	dealEnd := started.Add(time.Duration(1+deal) * t.Deal)
+	switch phase {
+	case dkg.DealPhase:
+		return dealEnd
+	case dkg.ResponsePhase:
+		return dealEnd.Add(time.Duration(1+response) * t.Response)
+	default:
+		return dealEnd.Add(time.Duration(1+response)*t.Response + t.Justification)
+	}
+}
+
+// Total returns the longest duration of the whole ceremony, when the deal and
+// response phases are extended as many times as allowed.
+func (t PhaseTimeouts) Total() time.Duration {
+	return time.Duration(1+t.Retries)*(t.Deal+t.Response) + t.Justification
+}
+
+// seconds returns the timeouts in seconds in the order of the phases, as the
+// DKG packets carry them.
+func (t PhaseTimeouts) seconds() []uint32 {
+	return []uint32{uint32(t.Deal.Seconds()), uint32(t.Response.Seconds()), uint32(t.Justification.Seconds())}
+}
+
+// The phases of a ceremony, as reported in its status.
+const (
+	PhaseWaiting       = "waiting"
+	PhaseDeal          = "deal"
+	PhaseResponse      = "response"
+	PhaseJustification = "justification"
+	PhaseFinished      = "finished"
+	PhaseFailed        = "failed"
+)
+
+func phaseName(p dkg.Phase) string {
+	switch p {
+	case dkg.DealPhase:
+		return PhaseDeal
+	case dkg.ResponsePhase:
+		return PhaseResponse
+	case dkg.JustifPhase:
+		return PhaseJustification
+	default:
+		return PhaseFinished
+	}
+}
+
+// DKGStatus is the status of the last DKG or resharing of the node, as seen by
+// the node: which packets it received from which participant in which phase,
+// so that the coordinator can tell which participant stalled.
+type DKGStatus struct {
+	// Running is true until the ceremony finishes or fails.
+	Running bool   `json:"running"`
+	Reshare bool   `json:"reshare"`
+	Leader  bool   `json:"leader"`
+	Phase   string `json:"phase"`
+	// PhaseEnd is the time the current phase times out.
+	PhaseEnd time.Time `json:"phaseEnd,omitempty"`
+	// Retry is the number of times the current phase was extended.
+	Retry        int                  `json:"retry,omitempty"`
+	Timeouts     PhaseTimeouts        `json:"timeouts"`
+	Participants []*ParticipantStatus `json:"participants"`
+	Error        string               `json:"error,omitempty"`
+}
+
+// ParticipantStatus is the progress of a participant of a ceremony.
+type ParticipantStatus struct {
+	Address string `json:"address"`
+	// Dealer is true for the participants sending deals, the nodes of the
+	// old group of a resharing, Holder for the participants receiving a
+	// share, the nodes of the new group.
+	Dealer bool `json:"dealer"`
+	Holder bool `json:"holder"`
+	// Deal, Response and Justification are true once the node received the
+	// bundle of the participant for the phase.
+	Deal          bool `json:"deal"`
+	Response      bool `json:"response"`
+	Justification bool `json:"justification"`
+	// SendFailures counts the packets of the node which it could not deliver
+	// to the participant, despite the retries.
+	SendFailures int    `json:"sendFailures,omitempty"`
+	LastError    string `json:"lastError,omitempty"`
+}
+
+// Remaining returns the time left at the given time before the current phase
+// times out, zero once the ceremony ended.
+func (s *DKGStatus) Remaining(now time.Time) time.Duration {
+	if !s.Running || s.PhaseEnd.IsZero() || !now.Before(s.PhaseEnd) {
+		return 0
+	}
+	return s.PhaseEnd.Sub(now)
+}
+
+// Missing returns the addresses of the participants whose bundle of the given
+// phase the node did not receive: the deals of the dealers, or the responses
+// of the holders. The dealers only send justifications when they were
+// complained about, so none is ever missing.
+func (s *DKGStatus) Missing(phase string) []string {
+	var missing []string
+	for _, p := range s.Participants {
+		switch {
+		case phase == PhaseDeal && p.Dealer && !p.Deal,
+			phase == PhaseResponse && p.Holder && !p.Response:
+			missing = append(missing, p.Address)
+		}
+	}
+	return missing
+}
+
+// copy returns a deep copy of the status.
+func (s *DKGStatus) copy() *DKGStatus {
+	c := *s
+	c.Participants = make([]*ParticipantStatus, len(s.Participants))
+	for i, p := range s.Participants {
+		pc := *p
+		c.Participants[i] = &pc
+	}
+	return &c
+}
+
+// dkgMonitor tracks the status of a ceremony and notifies the watchers of its
+// changes.
+type dkgMonitor struct {
+	sync.Mutex
+	status   *DKGStatus
+	dealers  map[uint32]*ParticipantStatus
+	holders  map[uint32]*ParticipantStatus
+	byAddr   map[string]*ParticipantStatus
+	watchers map[chan *DKGStatus]bool
+}
+
+// newDKGMonitor returns the monitor of a ceremony whose dealers are the nodes
+// of the old group, the new group for a fresh DKG.
+func newDKGMonitor(st *DKGState, group, oldGroup *key.Group) *dkgMonitor {
+	m := &dkgMonitor{
+		status: &DKGStatus{
+			Running:  true,
+			Reshare:  st.Reshare,
+			Leader:   st.Leader,
+			Phase:    PhaseWaiting,
+			Timeouts: st.Timeouts,
+		},
+		dealers:  make(map[uint32]*ParticipantStatus),
+		holders:  make(map[uint32]*ParticipantStatus),
+		byAddr:   make(map[string]*ParticipantStatus),
+		watchers: make(map[chan *DKGStatus]bool),
+	}
+	participant := func(n *key.Node) *ParticipantStatus {
+		p, ok := m.byAddr[n.Address()]
+		if !ok {
+			p = &ParticipantStatus{Address: n.Address()}
+			m.byAddr[n.Address()] = p
+			m.status.Participants = append(m.status.Participants, p)
+		}
+		return p
+	}
+	dealers := group
+	if oldGroup != nil {
+		dealers = oldGroup
+	}
+	for _, n := range dealers.Nodes {
+		p := participant(n)
+		p.Dealer = true
+		m.dealers[n.Index] = p
+	}
+	for _, n := range group.Nodes {
+		p := participant(n)
+		p.Holder = true
+		m.holders[n.Index] = p
+	}
+	return m
+}
+
+// Status returns a copy of the current status.
+func (m *dkgMonitor) Status() *DKGStatus {
+	m.Lock()
+	defer m.Unlock()
+	return m.status.copy()
+}
+
+// update applies a change to the status and notifies the watchers. Slow
+// watchers miss intermediate statuses but always receive the latest one.
+func (m *dkgMonitor) update(change func(s *DKGStatus)) {
+	m.Lock()
+	defer m.Unlock()
+	change(m.status)
+	for w := range m.watchers {
+		select {
+		case <-w:
+		default:
+		}
+		w <- m.status.copy()
+	}
+}
+
+// watch returns a channel receiving the status at each change, starting with
+// the current one, and the function to stop watching.
+func (m *dkgMonitor) watch() (<-chan *DKGStatus, func()) {
+	m.Lock()
+	defer m.Unlock()
+	w := make(chan *DKGStatus, 1)
+	w <- m.status.copy()
+	m.watchers[w] = true
+	return w, func() {
+		m.Lock()
+		defer m.Unlock()
+		delete(m.watchers, w)
+	}
+}
+
+func (m *dkgMonitor) phase(p dkg.Phase, end time.Time, retry int) {
+	m.update(func(s *DKGStatus) {
+		s.Phase = phaseName(p)
+		s.PhaseEnd = end
+		s.Retry = retry
+	})
+}
+
+// packet records the packet received from a participant.
+func (m *dkgMonitor) packet(p packet) {
+	m.update(func(s *DKGStatus) {
+		switch b := p.(type) {
+		case *dkg.DealBundle:
+			if pp, ok := m.dealers[b.DealerIndex]; ok {
+				pp.Deal = true
+			}
+		case *dkg.ResponseBundle:
+			if pp, ok := m.holders[b.ShareIndex]; ok {
+				pp.Response = true
+			}
+		case *dkg.JustificationBundle:
+			if pp, ok := m.dealers[b.DealerIndex]; ok {
+				pp.Justification = true
+			}
+		}
+	})
+}
+
+// sendFailed records a packet the node could not deliver to a participant.
+func (m *dkgMonitor) sendFailed(addr string, err error) {
+	m.update(func(s *DKGStatus) {
+		if p, ok := m.byAddr[addr]; ok {
+			p.SendFailures++
+			p.LastError = err.Error()
+		}
+	})
+}
+
+// finish records the end of the ceremony.
+func (m *dkgMonitor) finish(err error) {
+	m.update(func(s *DKGStatus) {
+		s.Running = false
+		s.PhaseEnd = time.Time{}
+		if err != nil {
+			s.Phase = PhaseFailed
+			s.Error = err.Error()
+		} else {
+			s.Phase = PhaseFinished
+		}
+	})
+}
@@ -0,0 +1,140 @@
+package core
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path"
+	"testing"
+	"time"
+
+	"github.com/drand/drand/test"
+	"github.com/drand/kyber/share/dkg"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPhaseTimeouts(t *testing.T) {
+	timeouts, err := newPhaseTimeouts(0, nil, 0)
+	require.NoError(t, err)
+	require.Equal(t, 3*DefaultDKGTimeout, timeouts.Total())
+
+	timeouts, err = newPhaseTimeouts(20, []uint32{60, 0, 120}, 0)
+	require.NoError(t, err)
+	require.Equal(t, PhaseTimeouts{Deal: time.Minute, Response: 20 * time.Second, Justification: 2 * time.Minute}, timeouts)
+	require.Equal(t, []uint32{60, 20, 120}, timeouts.seconds())
+	start := time.Now()
+	require.Equal(t, start.Add(time.Minute), timeouts.end(start, dkg.DealPhase, 0, 0))
+	require.Equal(t, start.Add(80*time.Second), timeouts.end(start, dkg.ResponsePhase, 0, 0))
+	require.Equal(t, start.Add(200*time.Second), timeouts.end(start, dkg.JustifPhase, 0, 0))
+
+	// each extension of the deal and response phases shifts the next phases
+	timeouts.Retries = 2
+	require.Equal(t, start.Add(2*time.Minute), timeouts.end(start, dkg.DealPhase, 1, 0))
+	require.Equal(t, start.Add(160*time.Second), timeouts.end(start, dkg.ResponsePhase, 1, 1))
+	require.Equal(t, start.Add(280*time.Second), timeouts.end(start, dkg.JustifPhase, 1, 1))
+	require.Equal(t, 360*time.Second, timeouts.Total())
+
+	_, err = newPhaseTimeouts(20, []uint32{60}, 0)
+	require.Error(t, err)
+}
+
+func TestDKGStateExtend(t *testing.T) {
+	folder, err := ioutil.TempDir("", "drand-dkg-extend")
+	require.NoError(t, err)
+	defer os.RemoveAll(folder)
+	require.NoError(t, os.MkdirAll(path.Dir(DKGStatePath(folder)), 0740))
+	start := time.Now()
+	f := &dkgStateFile{
+		path: DKGStatePath(folder),
+		state: &DKGState{
+			Timeouts: PhaseTimeouts{Deal: time.Minute, Response: time.Minute, Justification: time.Minute, Retries: 1},
+			Started:  start.UnixNano(),
+		},
+	}
+	now := start.Add(time.Minute)
+	retry, extended, err := f.extend(dkg.DealPhase, now)
+	require.NoError(t, err)
+	require.True(t, extended)
+	require.Equal(t, 1, retry)
+	end, retry := f.phaseEnd(dkg.DealPhase)
+	require.Equal(t, start.Add(2*time.Minute).UnixNano(), end.UnixNano())
+	require.Equal(t, 1, retry)
+	// the retries are exhausted
+	_, extended, err = f.extend(dkg.DealPhase, now)
+	require.NoError(t, err)
+	require.False(t, extended)
+	// a phase which ended more than its timeout ago is not extended
+	_, extended, err = f.extend(dkg.ResponsePhase, start.Add(10*time.Minute))
+	require.NoError(t, err)
+	require.False(t, extended)
+	// the justification phase is never extended
+	_, extended, err = f.extend(dkg.JustifPhase, now)
+	require.NoError(t, err)
+	require.False(t, extended)
+
+	// a phase ended late delays the next ones
+	require.NoError(t, f.delay(time.Second))
+	end, _ = f.phaseEnd(dkg.ResponsePhase)
+	require.Equal(t, start.Add(3*time.Minute+time.Second).UnixNano(), end.UnixNano())
+
+	st, err := LoadDKGState(folder)
+	require.NoError(t, err)
+	require.Equal(t, 1, st.DealRetries)
+	require.Zero(t, st.ResponseRetries)
+	require.Equal(t, time.Second, st.Delay)
+	require.Equal(t, start.Add(time.Second+PhaseTimeouts{Deal: time.Minute, Response: time.Minute,
+		Justification: time.Minute, Retries: 1}.Total()).UnixNano(), st.Deadline().UnixNano())
+}
+
+func TestDKGMonitor(t *testing.T) {
+	_, oldGroup := test.BatchIdentities(3)
+	_, group := test.BatchIdentities(4)
+	// the second node of the old group stays in the new group
+	group.Nodes[1] = oldGroup.Nodes[1]
+	group.Nodes[1].Index = 1
+	st := &DKGState{Reshare: true, Timeouts: PhaseTimeouts{Deal: time.Second}}
+	m := newDKGMonitor(st, group, oldGroup)
+
+	s := m.Status()
+	require.True(t, s.Running)
+	require.Equal(t, PhaseWaiting, s.Phase)
+	require.Len(t, s.Participants, 6)
+
+	statuses, stop := m.watch()
+	defer stop()
+	require.Equal(t, PhaseWaiting, (<-statuses).Phase)
+
+	end := time.Now().Add(time.Second)
+	m.phase(dkg.DealPhase, end, 1)
+	m.packet(&dkg.DealBundle{DealerIndex: oldGroup.Nodes[1].Index})
+	m.packet(&dkg.ResponseBundle{ShareIndex: group.Nodes[3].Index})
+	m.sendFailed(group.Nodes[2].Address(), errors.New("unreachable"))
+
+	// a slow watcher only receives the latest status
+	s = <-statuses
+	require.Equal(t, PhaseDeal, s.Phase)
+	require.Equal(t, 1, s.Retry)
+	byAddr := make(map[string]*ParticipantStatus)
+	for _, p := range s.Participants {
+		byAddr[p.Address] = p
+	}
+	stayer := byAddr[oldGroup.Nodes[1].Address()]
+	require.True(t, stayer.Dealer)
+	require.True(t, stayer.Holder)
+	require.True(t, stayer.Deal)
+	require.False(t, byAddr[oldGroup.Nodes[0].Address()].Deal)
+	require.True(t, byAddr[group.Nodes[3].Address()].Response)
+	require.False(t, byAddr[group.Nodes[3].Address()].Dealer)
+	require.Equal(t, 1, byAddr[group.Nodes[2].Address()].SendFailures)
+	require.ElementsMatch(t, []string{oldGroup.Nodes[0].Address(), oldGroup.Nodes[2].Address()}, s.Missing(PhaseDeal))
+	require.Len(t, s.Missing(PhaseResponse), 3)
+	require.Equal(t, time.Second, s.Remaining(end.Add(-time.Second)))
+	require.Zero(t, s.Remaining(end))
+
+	m.finish(errors.New("dkg failed"))
+	s = <-statuses
+	require.False(t, s.Running)
+	require.Equal(t, PhaseFailed, s.Phase)
+	require.Equal(t, "dkg failed", s.Error)
+	require.Zero(t, s.Remaining(end.Add(-time.Second)))
+}
@@ -0,0 +1,127 @@
+package core
+
+import (
+	"context"
+	"sync"
+
+	"github.com/drand/drand/chain"
+	"github.com/drand/drand/protobuf/drand"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// The states of the drain of a node before a planned maintenance, see
+// AdminDrain.
+const (
+	// DrainActive is the state of a node serving all its traffic.
+	DrainActive = "active"
+	// DrainDraining is the state of a node which refuses the public traffic
+	// and the new syncs, while its peers finish syncing from it.
+	DrainDraining = "draining"
+	// DrainDrained is the state of a draining node no peer syncs from
+	// anymore, which its operator can stop.
+	DrainDrained = "drained"
+)
+
+// DrainStatus is the state of the drain of a node. A draining node keeps
+// signing the beacons until its operator stops it.
+type DrainStatus struct {
+	State string `json:"state"`
+	// Since is the unix time at which the node started draining.
+	Since int64 `json:"since,omitempty"`
+	// Syncs is the number of peers syncing from the node.
+	Syncs int `json:"syncs"`
+}
+
+var errDraining = status.Error(codes.Unavailable, "drand: the node is draining for maintenance")
+
+// drainState tracks the drain of a node and the peers syncing from it.
+type drainState struct {
+	sync.Mutex
+	since int64
+	syncs int
+}
+
+func (s *drainState) start(now int64) *DrainStatus {
+	s.Lock()
+	defer s.Unlock()
+	if s.since == 0 {
+		s.since = now
+	}
+	return s.statusLocked()
+}
+
+func (s *drainState) exit() *DrainStatus {
+	s.Lock()
+	defer s.Unlock()
+	s.since = 0
+	return s.statusLocked()
+}
+
+func (s *drainState) status() *DrainStatus {
+	s.Lock()
+	defer s.Unlock()
+	return s.statusLocked()
+}
+
+func (s *drainState) statusLocked() *DrainStatus {
+	st := &DrainStatus{State: DrainActive, Since: s.since, Syncs: s.syncs}
+	switch {
+	case s.since != 0 && s.syncs > 0:
+		st.State = DrainDraining
+	case s.since != 0:
+		st.State = DrainDrained
+	}
+	return st
+}
+
+func (s *drainState) draining() bool {
+	s.Lock()
+	defer s.Unlock()
+	return s.since != 0
+}
+
+// beginSync registers a peer syncing from the node, and returns false if the
+// node drains and refuses new syncs.
+func (s *drainState) beginSync() bool {
+	s.Lock()
+	defer s.Unlock()
+	if s.since != 0 {
+		return false
+	}
+	s.syncs++
+	return true
+}
+
+func (s *drainState) endSync() {
+	s.Lock()
+	defer s.Unlock()
+	s.syncs--
+}
+
+// drainingSyncStream ends a sync following the chain once it sent the last
+// stored beacon, when the node drains, so that the peer follows the chain
+// from another node.
+type drainingSyncStream struct {
+	drand.Protocol_SyncChainServer
+	ctx    context.Context
+	cancel context.CancelFunc
+	drain  *drainState
+	store  chain.Store
+}
+
+func (s *drainingSyncStream) Context() context.Context {
+	return s.ctx
+}
+
+func (s *drainingSyncStream) Send(b *drand.BeaconPacket) error {
+	if err := s.Protocol_SyncChainServer.Send(b); err != nil {
+		return err
+	}
+	if s.drain.draining() {
+		if last, err := s.store.Last(); err == nil && b.GetRound() >= last.Round {
+			s.cancel()
+		}
+	}
+	return nil
+}
@@ -0,0 +1,28 @@
+package core
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDrainState(t *testing.T) {
+	var s drainState
+	require.Equal(t, DrainActive, s.status().State)
+	require.True(t, s.beginSync())
+
+	// the peer syncing keeps the node draining, and the new syncs are refused
+	st := s.start(1000)
+	require.Equal(t, DrainDraining, st.State)
+	require.Equal(t, 1, st.Syncs)
+	require.False(t, s.beginSync())
+	require.Equal(t, int64(1000), s.start(2000).Since)
+
+	s.endSync()
+	require.Equal(t, DrainDrained, s.status().State)
+
+	st = s.exit()
+	require.Equal(t, DrainActive, st.State)
+	require.Zero(t, st.Since)
+	require.True(t, s.beginSync())
+}
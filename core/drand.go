@@ -2,20 +2,23 @@ package core
 
 import (
 	"context"
+	"crypto/sha256"
 	"errors"
 	"fmt"
+	"path"
 	"strings"
 	"sync"
 	"time"
 
+	"github.com/drand/drand/audit"
+	"github.com/drand/drand/backup"
 	"github.com/drand/drand/chain"
 	"github.com/drand/drand/chain/beacon"
-	"github.com/drand/drand/chain/boltdb"
-	"github.com/drand/drand/fs"
 	"github.com/drand/drand/http"
 	"github.com/drand/drand/key"
 	"github.com/drand/drand/log"
 	"github.com/drand/drand/net"
+	"github.com/drand/drand/protobuf/drand"
 	"github.com/drand/kyber/share/dkg"
 )
 
@@ -33,10 +36,20 @@ type Drand struct {
 	privGateway *net.PrivateGateway
 	pubGateway  *net.PublicGateway
 	control     net.ControlListener
+	// daemon, when set, is the daemon hosting this beacon process and owning
+	// the listeners.
+	daemon *Daemon
 
 	beacon *beacon.Handler
-	// dkg private share. can be nil if dkg not finished yet.
-	share   *key.Share
+	// backups backs up the node when backups are enabled, until
+	// backupCancel is called.
+	backups      *backup.Manager
+	backupCancel context.CancelFunc
+	// dkg private share. can be nil if dkg not finished yet, or if the
+	// signer holds it.
+	share *key.Share
+	// signer, when set, produces the partial signatures instead of share.
+	signer  beacon.Signer
 	dkgDone bool
 	// manager is created and destroyed during a setup phase
 	manager  *setupManager
@@ -45,6 +58,10 @@ type Drand struct {
 	// dkgInfo contains all the information related to an upcoming or in
 	// progress dkg protocol. It is nil for the rest of the time.
 	dkgInfo *dkgInfo
+	// dkgMonitor tracks the status of the last DKG or resharing
+	dkgMonitor *dkgMonitor
+	// drain tracks the drain of the node before a maintenance
+	drain drainState
 	// general logger
 	log log.Logger
 
@@ -76,11 +93,25 @@ func NewDrand(s key.Store, c *Config) (*Drand, error) {
 // initDrand inits the drand struct by loading the private key, and by creating the
 // gateway with the correct options.
 func initDrand(s key.Store, c *Config) (*Drand, error) {
+	d, err := newBeaconProcess(s, c)
+	if err != nil {
+		return nil, err
+	}
+	if err := setupDrand(d, c); err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+// newBeaconProcess inits the drand struct by loading the private key, without
+// setting up the network.
+func newBeaconProcess(s key.Store, c *Config) (*Drand, error) {
 	logger := c.Logger()
 	if !c.insecure && (c.certPath == "" || c.keyPath == "") {
 		return nil, errors.New("config: need to set WithInsecure if no certificate and private key path given")
 	}
 	priv, err := s.LoadKeyPair()
+	c.record(context.Background(), audit.ActionKeyLoad, audit.Outcome(err), err, "key", "keypair")
 	if err != nil {
 		return nil, err
 	}
@@ -95,12 +126,10 @@ func initDrand(s key.Store, c *Config) (*Drand, error) {
 		store:  s,
 		priv:   priv,
 		opts:   c,
+		signer: c.signer,
 		log:    logger,
 		exitCh: make(chan bool, 1),
 	}
-	if err := setupDrand(d, c); err != nil {
-		return nil, err
-	}
 	return d, nil
 }
 
@@ -120,16 +149,16 @@ func setupDrand(d *Drand, c *Config) error {
 		if err != nil {
 			return err
 		}
-		if d.pubGateway, err = net.NewRESTPublicGateway(ctx, pubAddr, c.certPath, c.keyPath, c.certmanager, handler, c.insecure); err != nil {
+		if d.pubGateway, err = c.publicGateway(ctx, pubAddr, handler); err != nil {
 			return err
 		}
 	}
-	d.privGateway, err = net.NewGRPCPrivateGateway(ctx, privAddr, c.certPath, c.keyPath, c.certmanager, d, c.insecure, d.opts.grpcOpts...)
+	d.privGateway, err = net.NewGRPCPrivateGateway(ctx, privAddr, c.certPath, c.keyPath, c.certmanager, d, c.insecure, d.opts.dialOptions()...)
 	if err != nil {
 		return err
 	}
 	p := c.ControlPort()
-	d.control = net.NewTCPGrpcControlListener(d, p)
+	d.control = net.NewTCPGrpcControlListener(d, p, c.controlAuth.ServerOptions()...)
 	go d.control.Start()
 	d.log.Info("private_listen", privAddr, "control_port", c.ControlPort(), "public_listen", pubAddr, "folder", d.opts.ConfigFolder())
 	d.privGateway.StartAll()
@@ -146,18 +175,34 @@ func LoadDrand(s key.Store, c *Config) (*Drand, error) {
 	if err != nil {
 		return nil, err
 	}
-	d.group, err = s.LoadGroup()
-	if err != nil {
+	if err := d.load(); err != nil {
 		return nil, err
 	}
+	return d, nil
+}
+
+// load restores the group and distributed share of the node.
+func (d *Drand) load() error {
+	var err error
+	d.group, err = d.store.LoadGroup()
+	if err != nil {
+		return err
+	}
 	checkGroup(d.log, d.group)
-	d.share, err = s.LoadShare()
+	d.share, err = d.store.LoadShare()
 	if err != nil {
-		return nil, err
+		// the signer holds the share
+		if d.signer == nil {
+			d.opts.record(context.Background(), audit.ActionKeyLoad, audit.OutcomeFailed, err, "key", "share")
+			return err
+		}
+		d.share = nil
+	} else {
+		d.opts.record(context.Background(), audit.ActionKeyLoad, audit.OutcomeOK, nil, "key", "share")
 	}
 	d.log.Debug("serving", d.priv.Public.Address())
 	d.dkgDone = true
-	return d, nil
+	return nil
 }
 
 // WaitDKG waits on the running dkg protocol. In case of an error, it returns
@@ -190,8 +235,20 @@ func (d *Drand) WaitDKG() (*key.Group, error) {
 		}
 	}
 
-	s := key.Share(*res.Result.Key)
-	d.share = &s
+	// the new group must be able to sign with its threshold, and a resharing
+	// keeps the distributed key whatever the thresholds
+	if len(qualNodes) < d.dkgInfo.target.Threshold {
+		return nil, fmt.Errorf("drand: %d qualified nodes for a threshold of %d", len(qualNodes), d.dkgInfo.target.Threshold)
+	}
+	if old := d.dkgInfo.oldGroup; old != nil && !res.Result.Key.Public().Equal(old.PublicKey.Key()) {
+		return nil, errors.New("drand: resharing changed the distributed public key")
+	}
+
+	if d.signer != nil {
+		// the node never holds the share of a remote signer
+		return nil, errRemoteSigner
+	}
+	d.share = &key.Share{DistKeyShare: *res.Result.Key, Scheme: d.dkgInfo.target.Scheme}
 	if err := d.store.SaveShare(d.share); err != nil {
 		return nil, err
 	}
@@ -211,6 +268,9 @@ func (d *Drand) WaitDKG() (*key.Group, error) {
 	}
 	d.opts.applyDkgCallback(d.share)
 	d.dkgInfo.board.stop()
+	if d.dkgInfo.persisted != nil {
+		d.dkgInfo.persisted.remove()
+	}
 	d.dkgInfo = nil
 	return d.group, nil
 }
@@ -285,11 +345,21 @@ func (d *Drand) StopBeacon() {
 	}
 	d.beacon.Stop()
 	d.beacon = nil
+	if d.backupCancel != nil {
+		d.backupCancel()
+		d.backupCancel = nil
+	}
 }
 
-// Stop simply stops all drand operations.
+// Stop simply stops all drand operations. A beacon process hosted by a daemon
+// only stops its beacon and leaves the daemon.
 func (d *Drand) Stop(ctx context.Context) {
 	d.StopBeacon()
+	if d.daemon != nil {
+		d.daemon.remove(d.opts.BeaconID())
+		d.exitCh <- true
+		return
+	}
 	d.state.Lock()
 	if d.pubGateway != nil {
 		d.pubGateway.StopAll(ctx)
@@ -300,20 +370,96 @@ func (d *Drand) Stop(ctx context.Context) {
 	d.exitCh <- true
 }
 
+// StopGracefully stops drand once it contributed to the round in progress: it
+// waits for the beacon of the current round to be stored, announces its
+// departure to the other nodes of the group, stops the beacon, which closes the
+// store, and lets the calls in progress finish before closing the listeners. It
+// stops right away when the context is done.
+func (d *Drand) StopGracefully(ctx context.Context) {
+	d.finishRound(ctx)
+	d.Stop(ctx)
+}
+
+// finishRound waits for the beacon of the current round to be stored, then
+// announces the departure of the node to the other nodes of the group.
+func (d *Drand) finishRound(ctx context.Context) {
+	b := d.runningBeacon()
+	if b == nil {
+		return
+	}
+	if err := b.WaitCurrentRound(ctx); err != nil {
+		d.log.Warn("shutdown", "round_in_progress", "err", err)
+	}
+	last, err := b.Store().Last()
+	if err != nil {
+		d.log.Warn("shutdown", "departure", "err", err)
+		return
+	}
+	d.announceDeparture(ctx, last.Round)
+}
+
+// departureMessage is the message a node signs to announce it stops after the
+// round.
+func departureMessage(addr string, round uint64) []byte {
+	h := sha256.New()
+	_, _ = h.Write([]byte("drand-departure"))
+	_, _ = h.Write([]byte(addr))
+	_, _ = h.Write(chain.RoundToBytes(round))
+	return h.Sum(nil)
+}
+
+// announceDeparture tells the other nodes of the group that the node stops
+// after the round, so that they stop sending it partial signatures until it
+// starts again.
+func (d *Drand) announceDeparture(ctx context.Context, round uint64) {
+	d.state.Lock()
+	group := d.group
+	d.state.Unlock()
+	if group == nil {
+		return
+	}
+	suite, err := d.priv.Public.Suite()
+	if err != nil {
+		d.log.Warn("shutdown", "departure", "err", err)
+		return
+	}
+	addr := d.priv.Public.Address()
+	sig, err := suite.AuthScheme.Sign(d.priv.Key, departureMessage(addr, round))
+	if err != nil {
+		d.log.Warn("shutdown", "departure", "err", err)
+		return
+	}
+	packet := &drand.DeparturePacket{Address: addr, Round: round, Signature: sig}
+	var wg sync.WaitGroup
+	for _, n := range group.Nodes {
+		if n.Address() == addr {
+			continue
+		}
+		wg.Add(1)
+		go func(n *key.Node) {
+			defer wg.Done()
+			if err := d.privGateway.ProtocolClient.Departure(ctx, n.Identity, packet); err != nil {
+				d.log.Debug("shutdown", "departure", "to", n.Address(), "err", err)
+			}
+		}(n)
+	}
+	wg.Wait()
+	d.log.Info("shutdown", "departure announced", "after_round", round)
+}
+
 // WaitExit returns a channel that signals when drand stops its operations
 func (d *Drand) WaitExit() chan bool {
 	return d.exitCh
 }
 
-func (d *Drand) createBoltStore() (chain.Store, error) {
-	fs.CreateSecureFolder(d.opts.DBFolder())
-	return boltdb.NewBoltStore(d.opts.dbFolder, d.opts.boltOpts)
+func (d *Drand) createStore() (chain.Store, error) {
+	return d.opts.OpenStore()
 }
 
 func (d *Drand) newBeacon() (*beacon.Handler, error) {
 	d.state.Lock()
 	defer d.state.Unlock()
-	store, err := d.createBoltStore()
+	store, err := d.createStore()
 	if err != nil {
 		return nil, err
 	}
@@ -323,10 +469,13 @@ func (d *Drand) newBeacon() (*beacon.Handler, error) {
 		return nil, fmt.Errorf("public key %s not found in group", pub)
 	}
 	conf := &beacon.Config{
-		Public: node,
-		Group:  d.group,
-		Share:  d.share,
-		Clock:  d.opts.clock,
+		Public:    node,
+		Group:     d.group,
+		Share:     d.share,
+		Signer:    d.signer,
+		Clock:     d.opts.clock,
+		Retention: d.opts.retention,
+		BeaconID:  d.opts.BeaconID(),
 	}
 	b, err := beacon.NewHandler(d.privGateway.ProtocolClient, store, conf, d.log)
 	if err != nil {
@@ -334,6 +483,9 @@ func (d *Drand) newBeacon() (*beacon.Handler, error) {
 	}
 	d.beacon = b
 	d.beacon.AddCallback("opts", d.opts.callbacks)
+	if d.opts.backup != nil {
+		d.startBackups(store)
+	}
 	// cancel any sync operations
 	if d.syncerCancel != nil {
 		d.syncerCancel()
@@ -342,6 +494,32 @@ func (d *Drand) newBeacon() (*beacon.Handler, error) {
 	return d.beacon, nil
 }
 
+// startBackups backs up the node with the given store, in place of the store
+// of the previous beacon if any. It is called with the state lock held.
+func (d *Drand) startBackups(store chain.Store) {
+	if d.backupCancel != nil {
+		d.backupCancel()
+	}
+	info := func() *chain.Info {
+		d.state.Lock()
+		defer d.state.Unlock()
+		if d.group == nil {
+			return nil
+		}
+		return chain.NewChainInfo(d.group)
+	}
+	conf := *d.opts.backup
+	conf.Audit = d.opts.audit
+	if id := d.opts.BeaconID(); id != "" {
+		// the beacons hosted by a daemon share the backup configuration
+		conf.Prefix = path.Join(conf.Prefix, id)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	d.backups = backup.NewManager(&conf, d.opts.ConfigFolder(), store, info, d.log)
+	d.backupCancel = cancel
+	go d.backups.Run(ctx)
+}
+
 func checkGroup(l log.Logger, group *key.Group) {
 	unsigned := group.UnsignedIdentities()
 	if unsigned == nil {
@@ -357,10 +535,15 @@ func checkGroup(l log.Logger, group *key.Group) {
 // dkgInfo is a simpler wrapper that keeps the relevant config and logic
 // necessary during the DKG protocol.
 type dkgInfo struct {
-	target  *key.Group
-	board   *broadcast
-	phaser  *dkg.TimePhaser
-	conf    *dkg.Config
-	proto   *dkg.Protocol
-	started bool
+	target *key.Group
+	// oldGroup is the group a resharing starts from, nil for a fresh DKG
+	oldGroup *key.Group
+	board    *broadcast
+	phaser   *dkg.TimePhaser
+	conf     *dkg.Config
+	proto    *dkg.Protocol
+	started  bool
+	// persisted keeps the state of the ceremony on disk to rejoin it after a
+	// restart
+	persisted *dkgStateFile
 }
@@ -9,9 +9,11 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"strconv"
 	"strings"
 	"time"
 
+	"github.com/drand/drand/audit"
 	"github.com/drand/drand/chain"
 	"github.com/drand/drand/chain/beacon"
 	"github.com/drand/drand/entropy"
@@ -27,10 +29,38 @@ import (
 // errPreempted is returned on reshares when a subsequent reshare is started concurrently
 var errPreempted = errors.New("time out: pre-empted")
 
+// errRemoteSigner is returned by the DKG and the resharings of a node whose
+// share is held by a remote signer: the share they produce would be held by
+// the node.
+var errRemoteSigner = errors.New("drand: the remote signer holds the private share, " +
+	"run the DKG or resharing without remote signer and move the new share to it")
+
+// checkNoRemoteSigner refuses to run a DKG or a resharing when a remote signer
+// holds the share of the node.
+func (d *Drand) checkNoRemoteSigner() error {
+	d.state.Lock()
+	defer d.state.Unlock()
+	if d.signer != nil {
+		return errRemoteSigner
+	}
+	return nil
+}
+
 // InitDKG take a InitDKGPacket, extracts the informations needed and wait for
 // the DKG protocol to finish. If the request specifies this node is a leader,
 // it starts the DKG protocol.
 func (d *Drand) InitDKG(c context.Context, in *drand.InitDKGPacket) (*drand.GroupPacket, error) {
+	leader := strconv.FormatBool(in.GetInfo().GetLeader())
+	d.opts.record(c, audit.ActionDKG, audit.OutcomeStarted, nil, "leader", leader)
+	group, err := d.initDKG(c, in)
+	d.opts.record(c, audit.ActionDKG, audit.Outcome(err), err, "leader", leader)
+	return group, err
+}
+
+func (d *Drand) initDKG(c context.Context, in *drand.InitDKGPacket) (*drand.GroupPacket, error) {
+	if err := d.checkNoRemoteSigner(); err != nil {
+		return nil, err
+	}
 	isLeader := in.GetInfo().GetLeader()
 	d.state.Lock()
 	if d.dkgDone {
@@ -44,10 +74,22 @@ func (d *Drand) InitDKG(c context.Context, in *drand.InitDKGPacket) (*drand.Grou
 		return out, err
 	}
 	d.log.Info("init_dkg", "begin", "time", d.opts.clock.Now().Unix(), "leader", true)
+	timeouts, err := setupPhaseTimeouts(in.GetInfo())
+	if err != nil {
+		return nil, err
+	}
+	if _, err := chain.DerivationFromID(in.GetDerivation()); err != nil {
+		return nil, fmt.Errorf("drand: invalid setup configuration: %s", err)
+	}
 
 	// setup the manager
 	newSetup := func(d *Drand) (*setupManager, error) {
-		return newDKGSetup(d.log, d.opts.clock, d.priv.Public, in.GetBeaconPeriod(), in.GetCatchupPeriod(), in.GetInfo())
+		sm, err := newDKGSetup(d.log, d.opts.clock, d.priv.Public, in.GetBeaconPeriod(), in.GetCatchupPeriod(), in.GetInfo())
+		if err != nil {
+			return nil, err
+		}
+		sm.derivation = in.GetDerivation()
+		return sm, nil
 	}
 
 	// expect the group
@@ -59,10 +101,10 @@ func (d *Drand) InitDKG(c context.Context, in *drand.InitDKGPacket) (*drand.Grou
 
 	// send it to everyone in the group nodes
 	nodes := group.Nodes
-	if err := d.pushDKGInfo([]*key.Node{}, nodes, 0, group, in.GetInfo().GetSecret(), in.GetInfo().GetTimeout()); err != nil {
+	if err := d.pushDKGInfo([]*key.Node{}, nodes, 0, group, in.GetInfo().GetSecret(), in.GetInfo().GetTimeout(), timeouts); err != nil {
 		return nil, err
 	}
-	finalGroup, err := d.runDKG(true, group, in.GetInfo().GetTimeout(), in.GetEntropy())
+	finalGroup, err := d.runDKG(true, group, timeouts, in.GetEntropy(), nil)
 	if err != nil {
 		return nil, err
 	}
@@ -119,24 +161,45 @@ func (d *Drand) leaderRunSetup(newSetup func(d *Drand) (*setupManager, error)) (
 }
 
 // runDKG setups the proper structures and protocol to run the DKG and waits
-// until it finishes. If leader is true, this node sends the first packet.
-func (d *Drand) runDKG(leader bool, group *key.Group, timeout uint32, randomness *drand.EntropyInfo) (*key.Group, error) {
-	reader, user := extractEntropy(randomness)
+// until it finishes. If leader is true, this node sends the first packet. If
+// resume is set, the node rejoins the DKG it took part in before restarting.
+func (d *Drand) runDKG(leader bool, group *key.Group, timeouts PhaseTimeouts, randomness *drand.EntropyInfo, resume *DKGState) (*key.Group, error) {
+	if err := d.checkNoRemoteSigner(); err != nil {
+		return nil, err
+	}
+	if err := checkScheme(d.priv.Public, group); err != nil {
+		return nil, err
+	}
+	if _, err := chain.DerivationFromID(group.Derivation); err != nil {
+		return nil, err
+	}
+	suite, dkgSuite, err := dkgSuites(group)
+	if err != nil {
+		return nil, err
+	}
+	st := resume
+	if st == nil {
+		reader, user := extractEntropy(randomness)
+		if st, err = newDKGState(false, leader, timeouts, group, nil, reader, user); err != nil {
+			return nil, err
+		}
+	}
 	config := &dkg.Config{
-		Suite:          key.KeyGroup.(dkg.Suite),
-		NewNodes:       group.DKGNodes(),
-		Longterm:       d.priv.Key,
-		Reader:         reader,
-		UserReaderOnly: user,
-		FastSync:       true,
-		Threshold:      group.Threshold,
-		Nonce:          getNonce(group),
-		Auth:           key.DKGAuthScheme,
-	}
-	phaser := d.getPhaser(timeout)
-	board := newBroadcast(d.log, d.privGateway.ProtocolClient, d.priv.Public.Address(), group.Nodes, func(p dkg.Packet) error {
-		return dkg.VerifyPacketSignature(config, p)
-	})
+		Suite:     dkgSuite,
+		NewNodes:  group.DKGNodes(),
+		Longterm:  d.priv.Key,
+		FastSync:  true,
+		Threshold: group.Threshold,
+		Nonce:     getNonce(group),
+		Auth:      suite.DKGAuthScheme,
+	}
+	persisted, err := d.persistDKG(st, config)
+	if err != nil {
+		return nil, err
+	}
+	monitor := newDKGMonitor(st, group, nil)
+	phaser := d.getPhaser(persisted, monitor)
+	board := d.newDKGBoard(group.Nodes, config, persisted, monitor)
 	dkgProto, err := dkg.NewProtocol(config, board, phaser, true)
 	if err != nil {
 		return nil, err
@@ -144,26 +207,27 @@ func (d *Drand) runDKG(leader bool, group *key.Group, timeout uint32, randomness
 
 	d.state.Lock()
 	dkgInfo := &dkgInfo{
-		target: group,
-		board:  board,
-		phaser: phaser,
-		conf:   config,
-		proto:  dkgProto,
+		target:    group,
+		board:     board,
+		phaser:    phaser,
+		conf:      config,
+		proto:     dkgProto,
+		persisted: persisted,
 	}
 	d.dkgInfo = dkgInfo
-	if leader {
-		d.dkgInfo.started = true
-	}
-	d.state.Unlock()
-
-	if leader {
+	d.dkgMonitor = monitor
+	if leader || resume.hasStarted() {
 		// phaser will kick off the first phase for every other nodes so
 		// nodes will send their deals
 		d.log.Info("init_dkg", "START_DKG")
-		go phaser.Start()
+		d.startPhases()
 	}
+	d.state.Unlock()
+	d.replayDKG(board, persisted)
+
 	d.log.Info("init_dkg", "wait_dkg_end")
 	finalGroup, err := d.WaitDKG()
+	monitor.finish(err)
 	if err != nil {
 		d.log.Error("init_dkg", err)
 		d.state.Lock()
@@ -186,14 +250,41 @@ func (d *Drand) runDKG(leader bool, group *key.Group, timeout uint32, randomness
 func (d *Drand) cleanupDKG() {
 	if d.dkgInfo != nil {
 		d.dkgInfo.board.stop()
+		if d.dkgInfo.persisted != nil {
+			d.dkgInfo.persisted.remove()
+		}
 	}
 	d.dkgInfo = nil
 }
 
+// newDKGBoard returns the board of a ceremony, which persists the packets it
+// receives and reports the progress of the participants to the monitor.
+func (d *Drand) newDKGBoard(nodes []*key.Node, config *dkg.Config, f *dkgStateFile, m *dkgMonitor) *broadcast {
+	retry := retryPolicy{
+		retries: d.opts.dkgRetries,
+		backoff: DKGRetryBackoff,
+		failed:  m.sendFailed,
+	}
+	board := newBroadcast(d.log, d.privGateway.ProtocolClient, d.priv.Public.Address(), nodes, func(p dkg.Packet) error {
+		return dkg.VerifyPacketSignature(config, p)
+	}, retry)
+	board.keyGroup = config.Suite
+	board.record = f.record(d.log)
+	board.observe = m.packet
+	return board
+}
+
 // runResharing setups all necessary structures to run the resharing protocol
 // and waits until it finishes (or timeouts). If leader is true, it sends the
-// first packet so other nodes will start as soon as they receive it.
-func (d *Drand) runResharing(leader bool, oldGroup, newGroup *key.Group, timeout uint32) (*key.Group, error) {
+// first packet so other nodes will start as soon as they receive it. If resume
+// is set, the node rejoins the resharing it took part in before restarting.
+func (d *Drand) runResharing(leader bool, oldGroup, newGroup *key.Group, timeouts PhaseTimeouts, resume *DKGState) (*key.Group, error) {
+	if err := d.checkNoRemoteSigner(); err != nil {
+		return nil, err
+	}
+	if err := checkScheme(d.priv.Public, newGroup); err != nil {
+		return nil, err
+	}
 	oldNode := oldGroup.Find(d.priv.Public)
 	oldPresent := oldNode != nil
 	if leader && !oldPresent {
@@ -202,8 +293,12 @@ func (d *Drand) runResharing(leader bool, oldGroup, newGroup *key.Group, timeout
 	}
 	newNode := newGroup.Find(d.priv.Public)
 	newPresent := newNode != nil
+	suite, dkgSuite, err := dkgSuites(newGroup)
+	if err != nil {
+		return nil, err
+	}
 	config := &dkg.Config{
-		Suite:        key.KeyGroup.(dkg.Suite),
+		Suite:        dkgSuite,
 		NewNodes:     newGroup.DKGNodes(),
 		OldNodes:     oldGroup.DKGNodes(),
 		Longterm:     d.priv.Key,
@@ -211,9 +306,9 @@ func (d *Drand) runResharing(leader bool, oldGroup, newGroup *key.Group, timeout
 		OldThreshold: oldGroup.Threshold,
 		FastSync:     true,
 		Nonce:        getNonce(newGroup),
-		Auth:         key.DKGAuthScheme,
+		Auth:         suite.DKGAuthScheme,
 	}
-	err := func() error {
+	err = func() error {
 		d.state.Lock()
 		defer d.state.Unlock()
 		// gives the share to the dkg if we are a current node
@@ -224,7 +319,7 @@ func (d *Drand) runResharing(leader bool, oldGroup, newGroup *key.Group, timeout
 			if d.share == nil {
 				return errors.New("control: can't reshare without a share")
 			}
-			dkgShare := dkg.DistKeyShare(*d.share)
+			dkgShare := d.share.DistKeyShare
 			config.Share = &dkgShare
 		} else {
 			// we are a new node, we want to make sure we reshare from the old
@@ -237,40 +332,51 @@ func (d *Drand) runResharing(leader bool, oldGroup, newGroup *key.Group, timeout
 		return nil, err
 	}
 
+	st := resume
+	if st == nil {
+		if st, err = newDKGState(true, leader, timeouts, newGroup, oldGroup, nil, false); err != nil {
+			return nil, err
+		}
+	}
+	persisted, err := d.persistDKG(st, config)
+	if err != nil {
+		return nil, err
+	}
+
+	monitor := newDKGMonitor(st, newGroup, oldGroup)
 	allNodes := nodeUnion(oldGroup.Nodes, newGroup.Nodes)
-	board := newBroadcast(d.log, d.privGateway.ProtocolClient, d.priv.Public.Address(), allNodes, func(p dkg.Packet) error {
-		return dkg.VerifyPacketSignature(config, p)
-	})
-	phaser := d.getPhaser(timeout)
+	board := d.newDKGBoard(allNodes, config, persisted, monitor)
+	phaser := d.getPhaser(persisted, monitor)
 
 	dkgProto, err := dkg.NewProtocol(config, board, phaser, true)
 	if err != nil {
 		return nil, err
 	}
 	info := &dkgInfo{
-		target: newGroup,
-		board:  board,
-		phaser: phaser,
-		conf:   config,
-		proto:  dkgProto,
+		target:    newGroup,
+		oldGroup:  oldGroup,
+		board:     board,
+		phaser:    phaser,
+		conf:      config,
+		proto:     dkgProto,
+		persisted: persisted,
 	}
 	d.state.Lock()
 	d.dkgInfo = info
-	if leader {
-		d.log.Info("dkg_reshare", "leader_start", "target_group", hex.EncodeToString(newGroup.Hash()), "index", newNode.Index)
-		d.dkgInfo.started = true
-	}
-	d.state.Unlock()
-
-	if leader {
+	d.dkgMonitor = monitor
+	if leader || resume.hasStarted() {
+		d.log.Info("dkg_reshare", "leader_start", "target_group", hex.EncodeToString(newGroup.Hash()), "leader", leader)
 		// start the protocol so everyone else follows
 		// it sends to all previous and new nodes. old nodes will start their
 		// phaser so they will send the deals as soon as they receive this.
-		go phaser.Start()
+		d.startPhases()
 	}
+	d.state.Unlock()
+	d.replayDKG(board, persisted)
 
 	d.log.Info("dkg_reshare", "wait_dkg_end")
 	finalGroup, err := d.WaitDKG()
+	monitor.finish(err)
 	if err != nil {
 		d.state.Lock()
 		if d.dkgInfo == info {
@@ -279,7 +385,7 @@ func (d *Drand) runResharing(leader bool, oldGroup, newGroup *key.Group, timeout
 		d.state.Unlock()
 		return nil, fmt.Errorf("drand: err during DKG: %v", err)
 	}
-	d.log.Info("dkg_reshare", "finished", "leader", leader)
+	d.log.Info("dkg_reshare", "finished", "leader", leader, "old_threshold", oldGroup.Threshold, "new_threshold", finalGroup.Threshold)
 	// runs the transition of the beacon
 	go d.transition(oldGroup, oldPresent, newPresent)
 	return finalGroup, nil
@@ -334,7 +440,7 @@ func (d *Drand) setupAutomaticDKG(_ context.Context, in *drand.InitDKGPacket) (*
 
 	d.log.Debug("init_dkg", "wait_group")
 
-	group, dkgTimeout, err := d.receiver.WaitDKGInfo(nc)
+	group, timeouts, err := d.receiver.WaitDKGInfo(nc)
 	if err != nil {
 		return nil, err
 	}
@@ -359,7 +465,7 @@ func (d *Drand) setupAutomaticDKG(_ context.Context, in *drand.InitDKGPacket) (*
 	d.state.Unlock()
 
 	// run the dkg
-	finalGroup, err := d.runDKG(false, group, dkgTimeout, in.GetEntropy())
+	finalGroup, err := d.runDKG(false, group, timeouts, in.GetEntropy(), nil)
 	if err != nil {
 		return nil, err
 	}
@@ -423,7 +529,7 @@ func (d *Drand) setupAutomaticResharing(_ context.Context, oldGroup *key.Group,
 		return nil, fmt.Errorf("drand: err when signaling key to leader: %s", err)
 	}
 
-	newGroup, dkgTimeout, err := d.receiver.WaitDKGInfo(nc)
+	newGroup, timeouts, err := d.receiver.WaitDKGInfo(nc)
 	if err != nil {
 		d.log.Error("setup_reshare", "failed to receive dkg info", "err", err)
 		return nil, err
@@ -433,6 +539,9 @@ func (d *Drand) setupAutomaticResharing(_ context.Context, oldGroup *key.Group,
 	if err := d.validateGroupTransition(oldGroup, newGroup); err != nil {
 		return nil, err
 	}
+	setEpochs(oldGroup, newGroup)
+	newGroup.Scheme = oldGroup.Scheme
+	newGroup.Derivation = oldGroup.Derivation
 
 	node := newGroup.Find(d.priv.Public)
 	if node == nil {
@@ -448,7 +557,7 @@ func (d *Drand) setupAutomaticResharing(_ context.Context, oldGroup *key.Group,
 	}
 
 	// run the dkg !
-	finalGroup, err := d.runResharing(false, oldGroup, newGroup, dkgTimeout)
+	finalGroup, err := d.runResharing(false, oldGroup, newGroup, timeouts, nil)
 	if err != nil {
 		d.log.Error("setup_reshare", "failed to run resharing", "err", err)
 		return nil, err
@@ -462,9 +571,19 @@ func (d *Drand) validateGroupTransition(oldGroup, newGroup *key.Group) error {
 		return errors.New("control: old and new group have different genesis time")
 	}
 
+	// a new period starts at the transition round, which must be a round of
+	// the current schedule of the chain
 	if oldGroup.Period != newGroup.Period {
-		d.log.Error("setup_reshare", "invalid period time in received group")
-		return errors.New("control: old and new group have different period - unsupported feature at the moment")
+		if newGroup.Period < time.Second {
+			d.log.Error("setup_reshare", "invalid period time in received group")
+			return errors.New("control: invalid period of the new group")
+		}
+		old := oldGroup.Schedule()
+		round := chain.EpochCurrentRound(newGroup.TransitionTime, old)
+		if chain.EpochTimeOfRound(old, round) != newGroup.TransitionTime {
+			d.log.Error("setup_reshare", "invalid_period_transition", "transition", newGroup.TransitionTime)
+			return errors.New("control: new period does not start at the time of a round")
+		}
 	}
 
 	if !bytes.Equal(oldGroup.GetGenesisSeed(), newGroup.GetGenesisSeed()) {
@@ -476,9 +595,74 @@ func (d *Drand) validateGroupTransition(oldGroup, newGroup *key.Group) error {
 		d.log.Error("setup_reshare", "invalid_transition", "given", newGroup.TransitionTime, "now", now)
 		return errors.New("control: new group with transition time in the past")
 	}
+	// the threshold may change with the resharing, within the same bounds as
+	// for a fresh DKG
+	n := newGroup.Len()
+	if newGroup.Threshold < key.MinimumT(n) || newGroup.Threshold > n {
+		d.log.Error("setup_reshare", "invalid_threshold", "threshold", newGroup.Threshold, "nodes", n)
+		return fmt.Errorf("control: invalid threshold %d for a new group of %d nodes", newGroup.Threshold, n)
+	}
 	return nil
 }
 
+// setEpochs records the epochs of the chain in the new group of a resharing:
+// the ones of the old group, followed by the epoch of the new period from the
+// transition round on when the resharing changes the period. The group packets
+// do not carry the epochs, so every node derives them from its old group.
+func setEpochs(oldGroup, newGroup *key.Group) {
+	newGroup.Epochs = oldGroup.Epochs
+	if newGroup.Period == oldGroup.Period {
+		return
+	}
+	old := oldGroup.Schedule()
+	epochs := make([]*key.Epoch, len(old), len(old)+1)
+	copy(epochs, old)
+	newGroup.Epochs = append(epochs, &key.Epoch{
+		Round:  chain.EpochCurrentRound(newGroup.TransitionTime, old),
+		Time:   newGroup.TransitionTime,
+		Period: newGroup.Period,
+	})
+}
+
+// checkScheme refuses to run a ceremony for a group whose nodes use keys of
+// another scheme than the key of the node. A new group is of the scheme of the
+// key of its leader, and a resharing keeps the scheme of the old group.
+func checkScheme(pub *key.Identity, group *key.Group) error {
+	if !sameScheme(pub.Scheme, group.Scheme) {
+		return fmt.Errorf("drand: the key of the node is for the scheme %s, not for the scheme %s of the group",
+			schemeID(pub.Scheme), schemeID(group.Scheme))
+	}
+	return nil
+}
+
+// dkgSuites returns the suite of the keys of a group, and its key group as the
+// suite of the DKG.
+func dkgSuites(group *key.Group) (*key.Suite, dkg.Suite, error) {
+	suite, err := group.Suite()
+	if err != nil {
+		return nil, nil, fmt.Errorf("drand: %w", err)
+	}
+	dkgSuite, ok := suite.KeyGroup.(dkg.Suite)
+	if !ok {
+		return nil, nil, fmt.Errorf("drand: the key group %s of the scheme %s can not run a DKG", suite.KeyGroup, schemeID(group.Scheme))
+	}
+	return suite, dkgSuite, nil
+}
+
+// schemeID returns the ID of a scheme, the empty ID designating the default
+// scheme.
+func schemeID(id string) string {
+	if id == "" {
+		return chain.DefaultSchemeID
+	}
+	return id
+}
+
+// sameScheme indicates if two scheme IDs designate the same scheme.
+func sameScheme(a, b string) bool {
+	return schemeID(a) == schemeID(b)
+}
+
 func (d *Drand) extractGroup(old *drand.GroupInfo) (oldGroup *key.Group, err error) {
 	d.state.Lock()
 	if oldGroup, err = extractGroup(old); err != nil {
@@ -498,6 +682,17 @@ func (d *Drand) extractGroup(old *drand.GroupInfo) (oldGroup *key.Group, err err
 // InitReshare receives information about the old and new group from which to
 // operate the resharing protocol.
 func (d *Drand) InitReshare(c context.Context, in *drand.InitResharePacket) (*drand.GroupPacket, error) {
+	leader := strconv.FormatBool(in.GetInfo().GetLeader())
+	d.opts.record(c, audit.ActionReshare, audit.OutcomeStarted, nil, "leader", leader)
+	group, err := d.initReshare(c, in)
+	d.opts.record(c, audit.ActionReshare, audit.Outcome(err), err, "leader", leader)
+	return group, err
+}
+
+func (d *Drand) initReshare(c context.Context, in *drand.InitResharePacket) (*drand.GroupPacket, error) {
+	if err := d.checkNoRemoteSigner(); err != nil {
+		return nil, err
+	}
 	oldGroup, err := d.extractGroup(in.Old)
 	if err != nil {
 		return nil, err
@@ -509,9 +704,22 @@ func (d *Drand) InitReshare(c context.Context, in *drand.InitResharePacket) (*dr
 	}
 
 	d.log.Info("init_reshare", "begin", "leader", true, "time", d.opts.clock.Now())
+	timeouts, err := setupPhaseTimeouts(in.GetInfo())
+	if err != nil {
+		return nil, err
+	}
+
+	period := time.Duration(in.GetBeaconPeriod()) * time.Second
 
 	newSetup := func(d *Drand) (*setupManager, error) {
-		return newReshareSetup(d.log, d.opts.clock, d.priv.Public, oldGroup, in)
+		sm, err := newReshareSetup(d.log, d.opts.clock, d.priv.Public, oldGroup, in)
+		if err != nil {
+			return nil, err
+		}
+		if period != 0 {
+			sm.beaconPeriod = period
+		}
+		return sm, nil
 	}
 
 	newGroup, err := d.leaderRunSetup(newSetup)
@@ -532,7 +740,7 @@ func (d *Drand) InitReshare(c context.Context, in *drand.InitResharePacket) (*dr
 		return nil, errors.New("control: genesis time is in the future")
 	}
 	if oldGroup.Period != newGroup.Period {
-		return nil, errors.New("control: old and new group have different period - unsupported feature at the moment")
+		d.log.Info("init_reshare", "period_change", "old", oldGroup.Period, "new", newGroup.Period, "at", newGroup.TransitionTime)
 	}
 	if newGroup.TransitionTime < d.opts.clock.Now().Unix() {
 		return nil, errors.New("control: group with transition time in the past")
@@ -546,12 +754,13 @@ func (d *Drand) InitReshare(c context.Context, in *drand.InitResharePacket) (*dr
 		oldGroup.Threshold,
 		newGroup,
 		in.GetInfo().GetSecret(),
-		in.GetInfo().GetTimeout()); err != nil {
+		in.GetInfo().GetTimeout(),
+		timeouts); err != nil {
 		d.log.Error("push_group", err)
 		return nil, errors.New("fail to push new group")
 	}
 
-	finalGroup, err := d.runResharing(true, oldGroup, newGroup, in.GetInfo().GetTimeout())
+	finalGroup, err := d.runResharing(true, oldGroup, newGroup, timeouts, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -567,9 +776,11 @@ func (d *Drand) PingPong(c context.Context, in *drand.Ping) (*drand.Pong, error)
 // Share is a functionality of Control Service defined in protobuf/control that requests the private share of the drand node running locally
 func (d *Drand) Share(ctx context.Context, in *drand.ShareRequest) (*drand.ShareResponse, error) {
 	share, err := d.store.LoadShare()
+	d.opts.record(ctx, audit.ActionKeyExport, audit.Outcome(err), err, "key", "share")
 	if err != nil {
 		return nil, err
 	}
+	defer share.Wipe()
 	id := uint32(share.Share.I)
 	buff, err := share.Share.V.MarshalBinary()
 	if err != nil {
@@ -587,6 +798,7 @@ func (d *Drand) PublicKey(ctx context.Context, in *drand.PublicKeyRequest) (*dra
 	if err != nil {
 		return nil, err
 	}
+	defer keyPair.Wipe()
 	protoKey, err := keyPair.Public.Key.MarshalBinary()
 	if err != nil {
 		return nil, err
@@ -600,9 +812,11 @@ func (d *Drand) PrivateKey(ctx context.Context, in *drand.PrivateKeyRequest) (*d
 	d.state.Lock()
 	defer d.state.Unlock()
 	keyPair, err := d.store.LoadKeyPair()
+	d.opts.record(ctx, audit.ActionKeyExport, audit.Outcome(err), err, "key", "private")
 	if err != nil {
 		return nil, err
 	}
+	defer keyPair.Wipe()
 	protoKey, err := keyPair.Key.MarshalBinary()
 	if err != nil {
 		return nil, err
@@ -644,6 +858,13 @@ func extractGroup(i *drand.GroupInfo) (*key.Group, error) {
 	return g, nil
 }
 
+// setupPhaseTimeouts returns the timeouts of each phase of the DKG a leader
+// starts: the timeout of the setup packet, overridden per phase by the phase
+// timeouts of the packet.
+func setupPhaseTimeouts(in *drand.SetupInfoPacket) (PhaseTimeouts, error) {
+	return newPhaseTimeouts(in.GetTimeout(), in.GetPhaseTimeouts(), in.GetPhaseRetries())
+}
+
 func extractEntropy(i *drand.EntropyInfo) (io.Reader, bool) {
 	if i == nil {
 		return nil, false
@@ -653,17 +874,6 @@ func extractEntropy(i *drand.EntropyInfo) (io.Reader, bool) {
 	return r, user
 }
 
-func (d *Drand) getPhaser(timeout uint32) *dkg.TimePhaser {
-	tDuration := time.Duration(timeout) * time.Second
-	if timeout == 0 {
-		tDuration = DefaultDKGTimeout
-	}
-	return dkg.NewTimePhaserFunc(func(phase dkg.Phase) {
-		d.opts.clock.Sleep(tDuration)
-		d.log.Debug("phaser_finished", phase)
-	})
-}
-
 func nodesContainAddr(nodes []*key.Node, addr string) bool {
 	for _, n := range nodes {
 		if n.Address() == addr {
@@ -707,21 +917,30 @@ func (d *Drand) pushDKGInfoPacket(ctx context.Context, nodes []*key.Node, packet
 	return results
 }
 
-// pushDKGInfo sends the information to run the DKG to all specified nodes. The
-// call is blocking until all nodes have replied or after one minute timeouts.
-func (d *Drand) pushDKGInfo(outgoing, incoming []*key.Node, previousThreshold int, group *key.Group, secret []byte, timeout uint32) error {
-	// sign the group to prove you are the leader
-	signature, err := key.DKGAuthScheme.Sign(d.priv.Key, group.Hash())
-	if err != nil {
-		d.log.Error("setup", "leader", "group_signature", err)
-		return fmt.Errorf("drand: error signing group: %w", err)
-	}
+// pushDKGInfo sends the information to run the DKG to all specified nodes,
+// with the timeout of each phase when they differ from the timeout. The call
+// is blocking until all nodes have replied or after one minute timeouts.
+func (d *Drand) pushDKGInfo(outgoing, incoming []*key.Node, previousThreshold int, group *key.Group, secret []byte, timeout uint32, timeouts PhaseTimeouts) error {
 	packet := &drand.DKGInfoPacket{
 		NewGroup:    group.ToProto(),
 		SecretProof: secret,
 		DkgTimeout:  timeout,
-		Signature:   signature,
 	}
+	if uniform, _ := newPhaseTimeouts(timeout, nil, 0); timeouts != uniform {
+		packet.PhaseTimeouts = timeouts.seconds()
+		packet.PhaseRetries = uint32(timeouts.Retries)
+	}
+	// sign the group and the timeouts to prove you are the leader
+	suite, err := d.priv.Public.Suite()
+	if err != nil {
+		return err
+	}
+	signature, err := suite.DKGAuthScheme.Sign(d.priv.Key, dkgInfoMessage(group, packet))
+	if err != nil {
+		d.log.Error("setup", "leader", "group_signature", err)
+		return fmt.Errorf("drand: error signing group: %w", err)
+	}
+	packet.Signature = signature
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
@@ -828,7 +1047,7 @@ func (d *Drand) StartFollowChain(req *drand.StartFollowRequest, stream drand.Con
 		return errors.New("invalid chain info hash")
 	}
 
-	store, err := d.createBoltStore()
+	store, err := d.createStore()
 	if err != nil {
 		d.log.Error("start_follow_chain", "unable to create store", "err", err)
 		return fmt.Errorf("unable to create store: %s", err)
@@ -898,7 +1117,7 @@ func sendProgressCallback(
 	cb = func(b *chain.Beacon) {
 		err := stream.Send(&drand.FollowProgress{
 			Current: b.Round,
-			Target:  chain.CurrentRound(clk.Now().Unix(), info.Period, info.GenesisTime),
+			Target:  info.RoundAt(clk.Now()),
 		})
 		if err != nil {
 			l.Error("send_progress_callback", "sending_progress", "err", err)
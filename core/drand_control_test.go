@@ -1,14 +1,20 @@
 package core
 
 import (
+	"context"
 	"testing"
 	"time"
 
+	"github.com/drand/drand/chain"
+	"github.com/drand/drand/chain/beacon"
 	"github.com/drand/drand/key"
 	"github.com/drand/drand/log"
+	"github.com/drand/drand/protobuf/drand"
+	"github.com/drand/drand/test"
 	"github.com/drand/kyber"
 	"github.com/drand/kyber/util/random"
 	clock "github.com/jonboulle/clockwork"
+	"github.com/stretchr/testify/require"
 )
 
 func TestValidateGroupTransitionGenesisTime(t *testing.T) {
@@ -31,18 +37,51 @@ func TestValidateGroupTransitionPeriod(t *testing.T) {
 	d := Drand{log: log.DefaultLogger()}
 	var oldgrp, newgrp key.Group
 
-	oldgrp = key.Group{Period: 10}
-	newgrp = key.Group{Period: 20}
+	// the new period must start at the time of a round of the chain
+	oldgrp = key.Group{Period: 10 * time.Second, GenesisTime: 100}
+	newgrp = key.Group{Period: 20 * time.Second, GenesisTime: 100, TransitionTime: 125}
 
 	err := d.validateGroupTransition(&oldgrp, &newgrp)
 	if err == nil {
 		t.Fatal("expected error validating group period")
 	}
-	if err.Error() != "control: old and new group have different period - unsupported feature at the moment" {
+	if err.Error() != "control: new period does not start at the time of a round" {
 		t.Fatal("unexpected validation error", err)
 	}
 }
 
+func TestSetEpochs(t *testing.T) {
+	oldgrp := &key.Group{Period: 10 * time.Second, GenesisTime: 100}
+	newgrp := &key.Group{Period: 10 * time.Second, GenesisTime: 100, TransitionTime: 200}
+	setEpochs(oldgrp, newgrp)
+	require.Nil(t, newgrp.Epochs)
+
+	newgrp.Period = 3 * time.Second
+	setEpochs(oldgrp, newgrp)
+	require.Equal(t, []*key.Epoch{
+		{Round: 1, Time: 100, Period: 10 * time.Second},
+		{Round: 11, Time: 200, Period: 3 * time.Second},
+	}, newgrp.Epochs)
+
+	// a later change keeps the previous epochs
+	nextgrp := &key.Group{Period: 5 * time.Second, GenesisTime: 100, TransitionTime: 230}
+	setEpochs(newgrp, nextgrp)
+	require.Len(t, nextgrp.Epochs, 3)
+	require.Equal(t, &key.Epoch{Round: 21, Time: 230, Period: 5 * time.Second}, nextgrp.Epochs[2])
+	require.NoError(t, key.CheckEpochs(nextgrp.Epochs))
+}
+
+func TestCheckScheme(t *testing.T) {
+	pub := &key.Identity{}
+	require.NoError(t, checkScheme(pub, &key.Group{}))
+	require.NoError(t, checkScheme(pub, &key.Group{Scheme: chain.DefaultSchemeID}))
+	require.Error(t, checkScheme(pub, &key.Group{Scheme: chain.UnchainedSchemeID}))
+
+	pub.Scheme = chain.UnchainedSchemeID
+	require.Error(t, checkScheme(pub, &key.Group{}))
+	require.NoError(t, checkScheme(pub, &key.Group{Scheme: chain.UnchainedSchemeID}))
+}
+
 func TestValidateGroupTransitionGenesisSeed(t *testing.T) {
 	d := Drand{log: log.DefaultLogger()}
 	var oldgrp, newgrp key.Group
@@ -86,3 +125,96 @@ func TestValidateGroupTransitionTime(t *testing.T) {
 		t.Fatal("unexpected validation error", err)
 	}
 }
+
+func TestValidateGroupTransitionThreshold(t *testing.T) {
+	d := Drand{
+		log:  log.DefaultLogger(),
+		opts: &Config{clock: clock.NewRealClock()},
+	}
+	var oldgrp, newgrp key.Group
+
+	seed := []byte("genesis seed")
+	oldgrp = key.Group{Threshold: 3, Nodes: make([]*key.Node, 5), GenesisSeed: seed}
+	transition := time.Now().Unix() + 10
+	for _, thr := range []int{4, 10} {
+		newgrp = key.Group{
+			Threshold:      thr,
+			Nodes:          make([]*key.Node, 9),
+			TransitionTime: transition,
+			GenesisSeed:    seed,
+		}
+		err := d.validateGroupTransition(&oldgrp, &newgrp)
+		if err == nil {
+			t.Fatal("expected error validating group threshold", thr)
+		}
+	}
+
+	newgrp.Threshold = 5
+	if err := d.validateGroupTransition(&oldgrp, &newgrp); err != nil {
+		t.Fatal("unexpected validation error", err)
+	}
+}
+
+func TestDKGInfoSignature(t *testing.T) {
+	privs, group := test.BatchIdentities(3)
+	group.Period = time.Second
+	group.GenesisTime = time.Now().Unix()
+	leader := privs[0]
+	r := &setupReceiver{
+		ch:       make(chan *dkgGroup, 1),
+		l:        log.DefaultLogger(),
+		leaderID: leader.Public,
+		secret:   hashSecret([]byte("secret")),
+	}
+	sign := func(p *drand.DKGInfoPacket) *drand.DKGInfoPacket {
+		sig, err := key.DKGAuthScheme.Sign(leader.Key, dkgInfoMessage(group, p))
+		require.NoError(t, err)
+		p.Signature = sig
+		return p
+	}
+	newPacket := func() *drand.DKGInfoPacket {
+		return &drand.DKGInfoPacket{
+			NewGroup:      group.ToProto(),
+			SecretProof:   []byte("secret"),
+			DkgTimeout:    10,
+			PhaseTimeouts: []uint32{20, 0, 30},
+			PhaseRetries:  1,
+		}
+	}
+
+	require.NoError(t, r.PushDKGInfo(sign(newPacket())))
+	received := <-r.ch
+	require.Equal(t, PhaseTimeouts{Deal: 20 * time.Second, Response: 10 * time.Second, Justification: 30 * time.Second, Retries: 1}, received.timeouts)
+
+	// the timeouts are signed along with the group
+	p := sign(newPacket())
+	p.PhaseTimeouts[0] = 1
+	require.Error(t, r.PushDKGInfo(p))
+	p = sign(newPacket())
+	p.PhaseRetries = 5
+	require.Error(t, r.PushDKGInfo(p))
+	p = sign(newPacket())
+	p.DkgTimeout = 1
+	require.Error(t, r.PushDKGInfo(p))
+
+	// a packet without phase timeouts only signs the group
+	p = &drand.DKGInfoPacket{NewGroup: group.ToProto(), SecretProof: []byte("secret"), DkgTimeout: 10}
+	sig, err := key.DKGAuthScheme.Sign(leader.Key, group.Hash())
+	require.NoError(t, err)
+	p.Signature = sig
+	require.NoError(t, r.PushDKGInfo(p))
+	received = <-r.ch
+	require.Equal(t, 3*10*time.Second, received.timeouts.Total())
+}
+
+func TestRemoteSignerRefusesDKG(t *testing.T) {
+	d := &Drand{signer: beacon.NewShareSigner(nil)}
+	_, err := d.initDKG(context.Background(), &drand.InitDKGPacket{})
+	require.Equal(t, errRemoteSigner, err)
+	_, err = d.initReshare(context.Background(), &drand.InitResharePacket{})
+	require.Equal(t, errRemoteSigner, err)
+	_, err = d.runDKG(false, nil, PhaseTimeouts{}, nil, nil)
+	require.Equal(t, errRemoteSigner, err)
+	_, err = d.runResharing(false, nil, nil, PhaseTimeouts{}, nil)
+	require.Equal(t, errRemoteSigner, err)
+}
@@ -19,6 +19,30 @@ type drandProxy struct {
 	r drand.PublicServer
 }
 
+// chainInfoServer is implemented by the servers running the chain, which know
+// the fields of its info the protobuf description does not carry.
+type chainInfoServer interface {
+	chainInfo(ctx context.Context) (*chain.Info, error)
+}
+
+// pausedServer is implemented by the servers of nodes whose operator may
+// pause their participation in the beacons.
+type pausedServer interface {
+	paused() bool
+}
+
+// drainingServer is implemented by the servers of nodes whose operator may
+// drain them before a maintenance.
+type drainingServer interface {
+	draining() bool
+}
+
+// statusServer is implemented by the servers reporting the health of their
+// node.
+type statusServer interface {
+	nodeStatus(ctx context.Context) *NodeStatus
+}
+
 // Proxy wraps a server interface into a client interface so it can be queried
 func Proxy(s drand.PublicServer) client.Client {
 	return &drandProxy{s}
@@ -59,6 +83,9 @@ func (d *drandProxy) Watch(ctx context.Context) <-chan client.Result {
 // Info returns the parameters of the chain this client is connected to.
 // The public key, when it started, and how frequently it updates.
 func (d *drandProxy) Info(ctx context.Context) (*chain.Info, error) {
+	if s, ok := d.r.(chainInfoServer); ok {
+		return s.chainInfo(ctx)
+	}
 	info, err := d.r.ChainInfo(ctx, &drand.ChainInfoRequest{})
 	if err != nil {
 		return nil, err
@@ -75,7 +102,35 @@ func (d *drandProxy) RoundAt(t time.Time) uint64 {
 	if err != nil {
 		return 0
 	}
-	return chain.CurrentRound(t.Unix(), info.Period, info.GenesisTime)
+	return info.RoundAt(t)
+}
+
+// Paused returns true if the node was paused by its operator and does not sign
+// the beacons.
+func (d *drandProxy) Paused() bool {
+	if s, ok := d.r.(pausedServer); ok {
+		return s.paused()
+	}
+	return false
+}
+
+// Draining returns true if the node drains before a maintenance, and refuses
+// the public traffic.
+func (d *drandProxy) Draining() bool {
+	if s, ok := d.r.(drainingServer); ok {
+		return s.draining()
+	}
+	return false
+}
+
+// Status returns the NodeStatus of the node, and whether it is healthy.
+func (d *drandProxy) Status(ctx context.Context) (interface{}, bool) {
+	s, ok := d.r.(statusServer)
+	if !ok {
+		return nil, false
+	}
+	st := s.nodeStatus(ctx)
+	return st, st.Healthy
 }
 
 func (d *drandProxy) Close() error {
@@ -9,7 +9,6 @@ import (
 	"github.com/drand/drand/chain"
 	"github.com/drand/drand/chain/beacon"
 	"github.com/drand/drand/entropy"
-	"github.com/drand/drand/key"
 	"github.com/drand/drand/net"
 	"github.com/drand/drand/protobuf/drand"
 	"github.com/drand/kyber/encrypt/ecies"
@@ -25,8 +24,7 @@ func (d *Drand) BroadcastDKG(c context.Context, in *drand.DKGPacket) (*drand.Emp
 	addr := net.RemoteAddress(c)
 	if !d.dkgInfo.started {
 		d.log.Info("init_dkg", "START DKG", "signal from leader", addr, "group", hex.EncodeToString(d.dkgInfo.target.Hash()))
-		d.dkgInfo.started = true
-		go d.dkgInfo.phaser.Start()
+		d.startPhases()
 	}
 	if _, err := d.dkgInfo.board.BroadcastDKG(c, in); err != nil {
 		return nil, err
@@ -47,17 +45,48 @@ func (d *Drand) PartialBeacon(c context.Context, in *drand.PartialBeaconPacket)
 	return inst.ProcessPartialBeacon(c, in)
 }
 
+// Departure records that a node of the group stops after the round of the
+// packet, once its signature is verified.
+func (d *Drand) Departure(c context.Context, in *drand.DeparturePacket) (*drand.Empty, error) {
+	d.state.Lock()
+	inst, group := d.beacon, d.group
+	d.state.Unlock()
+	if inst == nil || group == nil {
+		return nil, errors.New("drand: beacon not setup yet")
+	}
+	for _, n := range group.Nodes {
+		if n.Address() != in.GetAddress() {
+			continue
+		}
+		suite, err := n.Suite()
+		if err != nil {
+			return nil, err
+		}
+		if err := suite.AuthScheme.Verify(n.Key, departureMessage(in.GetAddress(), in.GetRound()), in.GetSignature()); err != nil {
+			return nil, errors.New("drand: invalid departure signature")
+		}
+		return new(drand.Empty), inst.Departed(in.GetAddress(), in.GetRound())
+	}
+	return nil, fmt.Errorf("drand: %s is not a member of the group", in.GetAddress())
+}
+
 // PublicRand returns a public random beacon according to the request. If the Round
 // field is 0, then it returns the last one generated.
 func (d *Drand) PublicRand(c context.Context, in *drand.PublicRandRequest) (*drand.PublicRandResponse, error) {
+	if d.drain.draining() {
+		return nil, errDraining
+	}
 	var addr = net.RemoteAddress(c)
 	d.state.Lock()
 	defer d.state.Unlock()
 	if d.beacon == nil {
 		return nil, errors.New("drand: beacon generation not started yet")
 	}
+	der, err := d.derivation()
+	if err != nil {
+		return nil, err
+	}
 	var r *chain.Beacon
-	var err error
 	if in.GetRound() == 0 {
 		r, err = d.beacon.Store().Last()
 	} else {
@@ -69,11 +98,14 @@ func (d *Drand) PublicRand(c context.Context, in *drand.PublicRandRequest) (*dra
 		return nil, fmt.Errorf("can't retrieve beacon: %w %s", err, r)
 	}
 	d.log.Info("public_rand", addr, "round", r.Round, "reply", r.String())
-	return beaconToProto(r), nil
+	return beaconToProto(r, der), nil
 }
 
 // PublicRandStream exports a stream of new beacons as they are generated over gRPC
 func (d *Drand) PublicRandStream(req *drand.PublicRandRequest, stream drand.Public_PublicRandStreamServer) error {
+	if d.drain.draining() {
+		return errDraining
+	}
 	var b *beacon.Handler
 	d.state.Lock()
 	if d.beacon == nil {
@@ -81,7 +113,11 @@ func (d *Drand) PublicRandStream(req *drand.PublicRandRequest, stream drand.Publ
 		return errors.New("beacon has not started on this node yet")
 	}
 	b = d.beacon
+	der, err := d.derivation()
 	d.state.Unlock()
+	if err != nil {
+		return err
+	}
 	lastb, err := b.Store().Last()
 	if err != nil {
 		return err
@@ -94,7 +130,7 @@ func (d *Drand) PublicRandStream(req *drand.PublicRandRequest, stream drand.Publ
 		var err error
 		b.Store().Cursor(func(c chain.Cursor) {
 			for bb := c.Seek(req.GetRound()); bb != nil; bb = c.Next() {
-				if err = stream.Send(beaconToProto(bb)); err != nil {
+				if err = stream.Send(beaconToProto(bb, der)); err != nil {
 					d.log.Debug("stream", err)
 					return
 				}
@@ -107,12 +143,7 @@ func (d *Drand) PublicRandStream(req *drand.PublicRandRequest, stream drand.Publ
 	// then we can stream from any new rounds
 	// register a callback for the duration of this stream
 	d.beacon.AddCallback(addr, func(b *chain.Beacon) {
-		err := stream.Send(&drand.PublicRandResponse{
-			Round:       b.Round,
-			Signature:   b.Signature,
-			Randomness:  b.Randomness(),
-			SignatureV2: b.SignatureV2,
-		})
+		err := stream.Send(beaconToProto(b, der))
 		// if connection has a problem, we drop the callback
 		if err != nil {
 			d.beacon.RemoveCallback(addr)
@@ -124,16 +155,23 @@ func (d *Drand) PublicRandStream(req *drand.PublicRandRequest, stream drand.Publ
 
 // PrivateRand returns an ECIES encrypted random blob of 32 bytes from /dev/urandom
 func (d *Drand) PrivateRand(c context.Context, priv *drand.PrivateRandRequest) (*drand.PrivateRandResponse, error) {
+	if d.drain.draining() {
+		return nil, errDraining
+	}
 	if !d.opts.enablePrivate {
 		return nil, errors.New("private randomness is disabled")
 	}
-	msg, err := ecies.Decrypt(key.KeyGroup, d.priv.Key, priv.GetRequest(), EciesHash)
+	suite, err := d.priv.Public.Suite()
+	if err != nil {
+		return nil, err
+	}
+	msg, err := ecies.Decrypt(suite.KeyGroup, d.priv.Key, priv.GetRequest(), EciesHash)
 	if err != nil {
 		d.log.With("module", "public").Error("private", "invalid ECIES", "err", err.Error())
 		return nil, errors.New("invalid ECIES request")
 	}
 
-	clientKey := key.KeyGroup.Point()
+	clientKey := suite.KeyGroup.Point()
 	if err := clientKey.UnmarshalBinary(msg); err != nil {
 		return nil, errors.New("invalid client key")
 	}
@@ -144,13 +182,19 @@ func (d *Drand) PrivateRand(c context.Context, priv *drand.PrivateRandRequest) (
 		return nil, fmt.Errorf("error gathering randomness: expected 32 bytes, got %d", len(randomness))
 	}
 
-	obj, err := ecies.Encrypt(key.KeyGroup, clientKey, randomness, EciesHash)
+	obj, err := ecies.Encrypt(suite.KeyGroup, clientKey, randomness, EciesHash)
 	return &drand.PrivateRandResponse{Response: obj}, err
 }
 
 // Home provides the address the local node is listening
 func (d *Drand) Home(c context.Context, in *drand.HomeRequest) (*drand.HomeResponse, error) {
 	d.log.With("module", "public").Info("home", net.RemoteAddress(c))
+	if d.drain.draining() {
+		// tells the peers the node is leaving for maintenance
+		return &drand.HomeResponse{
+			Status: fmt.Sprintf("drand draining on %s", d.priv.Public.Address()),
+		}, nil
+	}
 	return &drand.HomeResponse{
 		Status: fmt.Sprintf("drand up and running on %s",
 			d.priv.Public.Address()),
@@ -159,12 +203,31 @@ func (d *Drand) Home(c context.Context, in *drand.HomeRequest) (*drand.HomeRespo
 
 // ChainInfo replies with the chain information this node participates to
 func (d *Drand) ChainInfo(ctx context.Context, in *drand.ChainInfoRequest) (*drand.ChainInfoPacket, error) {
+	info, err := d.chainInfo(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return info.ToProto(), nil
+}
+
+// chainInfo returns the complete info of the chain, with the fields its
+// protobuf description does not carry, such as its epochs.
+// derivation returns the randomness derivation of the chain. It must be called
+// with the state locked.
+func (d *Drand) derivation() (*chain.Derivation, error) {
+	if d.group == nil {
+		return nil, errors.New("drand: no dkg group setup yet")
+	}
+	return chain.DerivationFromID(d.group.Derivation)
+}
+
+func (d *Drand) chainInfo(ctx context.Context) (*chain.Info, error) {
 	d.state.Lock()
 	defer d.state.Unlock()
 	if d.group == nil {
 		return nil, errors.New("drand: no dkg group setup yet")
 	}
-	return chain.NewChainInfo(d.group).ToProto(), nil
+	return chain.NewChainInfo(d.group), nil
 }
 
 // SignalDKGParticipant receives a dkg signal packet from another member
@@ -202,10 +265,29 @@ func (d *Drand) SyncChain(req *drand.SyncRequest, stream drand.Protocol_SyncChai
 	d.state.Lock()
 	b := d.beacon
 	d.state.Unlock()
-	if b != nil {
-		return b.SyncChain(req, stream)
+	if b == nil {
+		return nil
 	}
-	return nil
+	// a draining node lets the peers syncing from it finish, and sends the
+	// new ones to the other nodes
+	if !d.drain.beginSync() {
+		return errDraining
+	}
+	defer d.drain.endSync()
+	ctx, cancel := context.WithCancel(stream.Context())
+	defer cancel()
+	return b.SyncChain(req, &drainingSyncStream{
+		Protocol_SyncChainServer: stream,
+		ctx:                      ctx,
+		cancel:                   cancel,
+		drain:                    &d.drain,
+		store:                    b.Store(),
+	})
+}
+
+// draining returns true if the node drains for a maintenance.
+func (d *Drand) draining() bool {
+	return d.drain.draining()
 }
 
 // GetIdentity returns the identity of this drand node
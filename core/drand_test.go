@@ -87,6 +87,102 @@ func TestDrandDKGFresh(t *testing.T) {
 	dt.TestPublicBeacon(lastID, false)
 }
 
+func TestDrandDKGScheme(t *testing.T) {
+	n := 3
+	beaconPeriod := 1 * time.Second
+
+	dt := NewDrandTest2(t, n, key.DefaultThreshold(n), beaconPeriod)
+	defer dt.Cleanup()
+	dt.UseScheme(chain.BN254UnchainedOnG1SchemeID)
+	finalGroup := dt.RunDKG()
+	require.Equal(t, chain.BN254UnchainedOnG1SchemeID, finalGroup.Scheme)
+	time.Sleep(getSleepDuration())
+
+	dt.MoveTime(time.Duration(finalGroup.GenesisTime-dt.Now().Unix()) * time.Second)
+	dt.TestBeaconLength(2, false, dt.Ids(n, false)...)
+
+	info := chain.NewChainInfo(finalGroup)
+	require.Equal(t, chain.BN254UnchainedOnG1SchemeID, info.Scheme)
+	resp := dt.TestPublicBeacon(dt.nodes[0].addr, false)
+	b := &chain.Beacon{
+		Round:       resp.GetRound(),
+		Signature:   resp.GetSignature(),
+		SignatureV2: resp.GetSignatureV2(),
+		PreviousSig: resp.GetPreviousSignature(),
+	}
+	require.NoError(t, info.VerifyBeacon(b))
+}
+
+func TestDrandDKGDerivation(t *testing.T) {
+	n := 3
+	beaconPeriod := 1 * time.Second
+
+	dt := NewDrandTest2(t, n, key.DefaultThreshold(n), beaconPeriod)
+	defer dt.Cleanup()
+	dt.derivation = chain.SHA3DerivationID
+	finalGroup := dt.RunDKG()
+	require.Equal(t, chain.SHA3DerivationID, finalGroup.Derivation)
+	time.Sleep(getSleepDuration())
+	defer func() {
+		for _, node := range dt.nodes {
+			node.drand.Stop(context.Background())
+		}
+	}()
+
+	dt.MoveTime(time.Duration(finalGroup.GenesisTime-dt.Now().Unix()) * time.Second)
+	dt.TestBeaconLength(2, false, dt.Ids(n, false)...)
+
+	// the nodes serve the derivation in the chain info and derive the
+	// randomness with it
+	p, err := dt.nodes[1].drand.ChainInfo(context.Background(), new(drand.ChainInfoRequest))
+	require.NoError(t, err)
+	info, err := chain.InfoFromProto(p)
+	require.NoError(t, err)
+	require.Equal(t, chain.SHA3DerivationID, info.Derivation)
+	require.Equal(t, chain.NewChainInfo(finalGroup).Hash(), info.Hash())
+	resp := dt.TestPublicBeacon(dt.nodes[0].addr, false)
+	random, err := info.Randomness(resp.GetSignature())
+	require.NoError(t, err)
+	require.Equal(t, random, resp.GetRandomness())
+}
+
+func TestDrandDeparture(t *testing.T) {
+	n := 3
+	beaconPeriod := 1 * time.Second
+
+	dt := NewDrandTest2(t, n, key.DefaultThreshold(n), beaconPeriod)
+	defer dt.Cleanup()
+	finalGroup := dt.RunDKG()
+	time.Sleep(getSleepDuration())
+	dt.MoveTime(time.Duration(finalGroup.GenesisTime-dt.Now().Unix()) * time.Second)
+	dt.TestBeaconLength(2, false, dt.Ids(n, false)...)
+
+	defer func() {
+		for _, node := range dt.nodes {
+			node.drand.Stop(context.Background())
+		}
+	}()
+
+	leaving := dt.nodes[0].drand
+	leaving.announceDeparture(context.Background(), 1)
+	for _, node := range dt.nodes[1:] {
+		report := node.drand.runningBeacon().Participation()
+		var found bool
+		for _, peer := range report.Peers {
+			if peer.Address == leaving.priv.Public.Address() {
+				found = true
+				require.Equal(t, uint64(1), peer.DepartedAfter)
+			}
+		}
+		require.True(t, found)
+	}
+
+	// a forged departure is refused
+	forged := &drand.DeparturePacket{Address: leaving.priv.Public.Address(), Round: 5, Signature: []byte("forged")}
+	_, err := dt.nodes[1].drand.Departure(context.Background(), forged)
+	require.Error(t, err)
+}
+
 func TestDrandDKGBroadcastDeny(t *testing.T) {
 	n := 4
 	thr := 3
@@ -258,6 +354,110 @@ func TestDrandDKGReshareTimeout(t *testing.T) {
 	dt.TestBeaconLength(int(lastBeacon.Round+1), true, dt.Ids(newN, true)...)
 }
 
+// This tests a resharing raising the threshold above the default one of the
+// new group: the new nodes must produce the beacons after the transition round
+// with the new threshold and the same distributed key.
+func TestDrandReshareThresholdChange(t *testing.T) {
+	oldN := 3
+	oldThr := 2
+	newN := 5
+	newThr := 4
+	timeout := 1 * time.Second
+	beaconPeriod := 2 * time.Second
+
+	dt := NewDrandTest2(t, oldN, oldThr, beaconPeriod)
+	defer dt.Cleanup()
+	group1 := dt.RunDKG()
+	// make sure all nodes had enough time to run their go routines to start the
+	// beacon handler - related to CI problems
+	time.Sleep(getSleepDuration())
+	dt.MoveToTime(group1.GenesisTime)
+	dt.TestBeaconLength(2, false, dt.Ids(oldN, false)...)
+	dt.MoveTime(1 * time.Second)
+
+	toAdd := newN - oldN
+	dt.SetupNewNodes(toAdd)
+	resharedGroup, err := dt.RunReshare(oldN, toAdd, newThr, timeout, false, false)
+	require.NoError(t, err)
+	require.Equal(t, newThr, resharedGroup.Threshold)
+	require.Equal(t, newN, resharedGroup.Len())
+	require.Len(t, resharedGroup.PublicKey.Coefficients, newThr)
+	require.True(t, resharedGroup.PublicKey.Key().Equal(group1.PublicKey.Key()))
+
+	target := resharedGroup.TransitionTime
+	now := dt.Now().Unix()
+	lastBeacon := dt.TestPublicBeacon(dt.Ids(1, false)[0], false)
+	// move to the transition time period by period
+	for now < target-1 {
+		dt.MoveTime(beaconPeriod)
+		lastBeacon = dt.TestPublicBeacon(dt.Ids(1, false)[0], false)
+		now = dt.Now().Unix()
+	}
+	dt.MoveToTime(target)
+	time.Sleep(getSleepDuration())
+	// the beacon of the transition round is signed by the new group: the
+	// stores hold the genesis, the rounds up to the last one and the
+	// transition round
+	dt.TestBeaconLength(int(lastBeacon.Round+2), true, dt.Ids(newN, true)...)
+}
+
+// This tests a resharing changing the period: the nodes must switch to the new
+// period at the transition round while the chain info keeps its hash and
+// exposes both epochs.
+func TestDrandResharePeriodChange(t *testing.T) {
+	n := 3
+	thr := 2
+	timeout := 1 * time.Second
+	beaconPeriod := 2 * time.Second
+	newPeriod := 4 * time.Second
+
+	dt := NewDrandTest2(t, n, thr, beaconPeriod)
+	defer dt.Cleanup()
+	group1 := dt.RunDKG()
+	time.Sleep(getSleepDuration())
+	dt.MoveToTime(group1.GenesisTime)
+	dt.TestBeaconLength(2, false, dt.Ids(n, false)...)
+	dt.MoveTime(1 * time.Second)
+
+	dt.resharePeriod = newPeriod
+	resharedGroup, err := dt.RunReshare(n, 0, thr, timeout, false, false)
+	require.NoError(t, err)
+	require.Equal(t, newPeriod, resharedGroup.Period)
+
+	info, err := dt.nodes[0].drand.chainInfo(context.Background())
+	require.NoError(t, err)
+	// the new schedule is part of the hash of the chain
+	require.NotEqual(t, chain.NewChainInfo(group1).Hash(), info.Hash())
+	require.Equal(t, beaconPeriod, info.Period)
+	epochs := info.Schedule()
+	require.Len(t, epochs, 2)
+	require.Equal(t, resharedGroup.TransitionTime, epochs[1].Time)
+	require.Equal(t, newPeriod, epochs[1].Period)
+	transitionRound := epochs[1].Round
+	require.Equal(t, resharedGroup.TransitionTime, info.TimeOfRound(transitionRound).Unix())
+	require.Equal(t, resharedGroup.TransitionTime+int64(newPeriod.Seconds()), info.TimeOfRound(transitionRound+1).Unix())
+
+	target := resharedGroup.TransitionTime
+	now := dt.Now().Unix()
+	// move to the transition time period by period
+	for now < target-1 {
+		dt.MoveTime(beaconPeriod)
+		dt.TestPublicBeacon(dt.Ids(1, false)[0], false)
+		now = dt.Now().Unix()
+	}
+	dt.MoveToTime(target)
+	time.Sleep(getSleepDuration())
+	dt.TestBeaconLength(int(transitionRound+1), true, dt.Ids(n, true)...)
+
+	// the next round comes after the new period, not the old one
+	dt.MoveTime(beaconPeriod)
+	time.Sleep(getSleepDuration())
+	dt.TestBeaconLength(int(transitionRound+1), true, dt.Ids(n, true)...)
+	dt.MoveTime(newPeriod - beaconPeriod)
+	time.Sleep(getSleepDuration())
+	dt.TestBeaconLength(int(transitionRound+2), true, dt.Ids(n, true)...)
+}
+
 func TestDrandResharePreempt(t *testing.T) {
 	if os.Getenv("CI") != "" {
 		t.Skip("Skipping testing in CI environment")
@@ -564,7 +764,7 @@ func TestDrandFollowChain(tt *testing.T) {
 		cancel()
 
 		// check if the beacon is in the database
-		store, err := newNode.drand.createBoltStore()
+		store, err := newNode.drand.createStore()
 		require.NoError(tt, err)
 		defer store.Close()
 		lastB, err := store.Last()
@@ -576,6 +776,72 @@ func TestDrandFollowChain(tt *testing.T) {
 	fn(0, resp.GetRound())
 }
 
+func TestDrandObserver(tt *testing.T) {
+	n := 4
+	p := 1 * time.Second
+	dt := NewDrandTest2(tt, n, key.DefaultThreshold(n), p)
+	defer dt.Cleanup()
+	group := dt.RunDKG()
+	time.Sleep(getSleepDuration())
+	rootID := dt.nodes[0].drand.priv.Public
+
+	dt.MoveToTime(group.GenesisTime)
+	for i := 0; i < 4; i++ {
+		dt.MoveTime(group.Period)
+	}
+	last := dt.TestPublicBeacon(rootID.Address(), false)
+
+	folder := path.Join(dt.dir, "observer")
+	// the observer needs a certificate of its own to verify the nodes with
+	// the trusted ones
+	privAddr := test.FreeBind("127.0.0.1")
+	certPath, keyPath := path.Join(dt.dir, "observer.crt"), path.Join(dt.dir, "observer.key")
+	host, _, err := gnet.SplitHostPort(privAddr)
+	require.NoError(tt, err)
+	require.NoError(tt, httpscerts.Generate(certPath, keyPath, host))
+	conf := NewConfig(
+		WithConfigFolder(folder),
+		WithDBFolder(path.Join(folder, DefaultDBFolder)),
+		WithPrivateListenAddress(privAddr),
+		WithControlPort(test.FreePort()),
+		WithTLS(certPath, keyPath),
+		WithTrustedCerts(dt.certPaths...),
+		WithLogLevel(log.LogDebug))
+	conf.clock = dt.clock
+	hash := chain.NewChainInfo(group).Hash()
+	peers := []net.Peer{test.NewTLSPeer(rootID.Address())}
+
+	_, err = NewObserver(conf, []byte("deadbeef"), peers)
+	require.Error(tt, err)
+
+	observer, err := NewObserver(conf, hash, peers)
+	require.NoError(tt, err)
+	defer observer.Stop(context.Background())
+
+	ctx := context.Background()
+	waitRound := func(round uint64) {
+		for i := 0; i < 50; i++ {
+			resp, err := observer.PublicRand(ctx, new(drand.PublicRandRequest))
+			if err == nil && resp.GetRound() == round {
+				return
+			}
+			time.Sleep(100 * time.Millisecond)
+		}
+		tt.Fatalf("the observer did not sync up to round %d", round)
+	}
+	waitRound(last.GetRound())
+	info, err := observer.ChainInfo(ctx, new(drand.ChainInfoRequest))
+	require.NoError(tt, err)
+	require.Equal(tt, hash, info.GetHash())
+
+	// the observer keeps following the chain
+	dt.MoveTime(group.Period)
+	waitRound(last.GetRound() + 1)
+	resp, err := observer.PublicRand(ctx, &drand.PublicRandRequest{Round: 1})
+	require.NoError(tt, err)
+	require.Equal(tt, uint64(1), resp.GetRound())
+}
+
 // Test if the we can correctly fetch the rounds through the local proxy
 func TestDrandPublicStreamProxy(t *testing.T) {
 	n := 4
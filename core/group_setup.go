@@ -5,6 +5,7 @@ import (
 	"context"
 	"crypto/sha256"
 	"crypto/subtle"
+	"encoding/binary"
 	"errors"
 	"fmt"
 	"sync"
@@ -15,6 +16,7 @@ import (
 	"github.com/drand/drand/log"
 	"github.com/drand/drand/net"
 	"github.com/drand/drand/protobuf/drand"
+	"github.com/golang/protobuf/proto"
 	clock "github.com/jonboulle/clockwork"
 )
 
@@ -46,9 +48,11 @@ type setupManager struct {
 	beaconOffset  time.Duration
 	catchupPeriod time.Duration
 	beaconPeriod  time.Duration
-	dkgTimeout    time.Duration
+	dkgTimeouts   PhaseTimeouts
 	clock         clock.Clock
 	leaderKey     *key.Identity
+	// derivation is the randomness derivation of a new group.
+	derivation string
 	verifyKeys    func([]*key.Identity) bool
 	l             log.Logger
 
@@ -69,7 +73,11 @@ func newDKGSetup(
 	beaconPeriod,
 	catchupPeriod uint32,
 	in *drand.SetupInfoPacket) (*setupManager, error) {
-	n, thr, dkgTimeout, err := validInitPacket(in)
+	n, thr, _, err := validInitPacket(in)
+	if err != nil {
+		return nil, err
+	}
+	dkgTimeouts, err := setupPhaseTimeouts(in)
 	if err != nil {
 		return nil, err
 	}
@@ -90,7 +98,7 @@ func newDKGSetup(
 		beaconOffset:  offset,
 		beaconPeriod:  time.Duration(beaconPeriod) * time.Second,
 		catchupPeriod: time.Duration(catchupPeriod) * time.Second,
-		dkgTimeout:    dkgTimeout,
+		dkgTimeouts:   dkgTimeouts,
 		l:             l,
 		startDKG:      make(chan *key.Group, 1),
 		pushKeyCh:     make(chan pushKey, n),
@@ -109,16 +117,26 @@ func newReshareSetup(
 	leaderKey *key.Identity,
 	oldGroup *key.Group,
 	in *drand.InitResharePacket) (*setupManager, error) {
-	// period isn't included for resharing since we keep the same period
+	// period isn't included for resharing: the period is kept unless the
+	// leader sets a new one in the InitResharePacket
 	beaconPeriod := uint32(oldGroup.Period.Seconds())
 	catchupPeriod := in.CatchupPeriod
 	if !in.CatchupPeriodChanged {
 		catchupPeriod = uint32(oldGroup.CatchupPeriod.Seconds())
 	}
-	sm, err := newDKGSetup(l, c, leaderKey, beaconPeriod, catchupPeriod, in.GetInfo())
+	// the threshold of the new group defaults to the one of the old group
+	info := in.GetInfo()
+	if info.GetThreshold() == 0 {
+		info = proto.Clone(info).(*drand.SetupInfoPacket)
+		info.Threshold = uint32(oldGroup.Threshold)
+	}
+	sm, err := newDKGSetup(l, c, leaderKey, beaconPeriod, catchupPeriod, info)
 	if err != nil {
 		return nil, err
 	}
+	if sm.thr != oldGroup.Threshold {
+		l.Info("setup", "threshold_change", "old", oldGroup.Threshold, "new", sm.thr, "nodes", sm.expected)
+	}
 
 	sm.oldGroup = oldGroup
 	sm.oldHash = oldGroup.Hash()
@@ -159,6 +177,10 @@ func (s *setupManager) ReceivedKey(addr string, p *drand.SignalDKGPacket) error
 		s.l.Info("setup", "invalid_sig", "id", addr, "err", err)
 		return fmt.Errorf("invalid sig: %s", err)
 	}
+	if !sameScheme(newID.Scheme, s.leaderKey.Scheme) {
+		s.l.Info("setup", "invalid_scheme", "id", addr, "scheme", newID.Scheme)
+		return fmt.Errorf("key of scheme %s, while the group is of scheme %s", schemeID(newID.Scheme), schemeID(s.leaderKey.Scheme))
+	}
 
 	s.l.Debug("setup", "received_new_key", "id", newID.String())
 
@@ -218,22 +240,29 @@ func (s *setupManager) run() {
 func (s *setupManager) createAndSend(keys []*key.Identity) {
 	// create group
 	var group *key.Group
-	totalDKG := s.dkgTimeout*3 + s.beaconOffset
+	totalDKG := s.dkgTimeouts.Total() + s.beaconOffset
 	if !s.isResharing {
 		genesis := s.clock.Now().Add(totalDKG).Unix()
 		// round the genesis time to a period modulo
 		ps := int64(s.beaconPeriod.Seconds())
 		genesis += (ps - genesis%ps)
 		group = key.NewGroup(keys, s.thr, genesis, s.beaconPeriod, s.catchupPeriod)
+		// the nodes joined with keys of the scheme of the leader
+		group.Scheme = s.leaderKey.Scheme
+		group.Derivation = s.derivation
 	} else {
 		genesis := s.oldGroup.GenesisTime
 		atLeast := s.clock.Now().Add(totalDKG).Unix()
 		// transitioning to the next round time that is at least
-		// "DefaultResharingOffset" time from now.
-		_, transition := chain.NextRound(atLeast, s.beaconPeriod, s.oldGroup.GenesisTime)
+		// "DefaultResharingOffset" time from now, on the current schedule of
+		// the chain: the period of the new group starts at this round.
+		_, transition := chain.EpochNextRound(atLeast, s.oldGroup.Schedule())
 		group = key.NewGroup(keys, s.thr, genesis, s.beaconPeriod, s.catchupPeriod)
 		group.TransitionTime = transition
 		group.GenesisSeed = s.oldGroup.GetGenesisSeed()
+		setEpochs(s.oldGroup, group)
+		group.Scheme = s.oldGroup.Scheme
+		group.Derivation = s.oldGroup.Derivation
 	}
 	s.l.Debug("setup", "created_group")
 	fmt.Printf("Generated group:\n%s\n", group.String())
@@ -309,13 +338,39 @@ func (r *setupReceiver) fetchLeaderKey() error {
 }
 
 type dkgGroup struct {
-	group   *key.Group
-	timeout uint32
+	group    *key.Group
+	timeouts PhaseTimeouts
+}
+
+// dkgInfoMessage returns the message the leader signs in a DKG info packet:
+// the hash of the group, along with the timeouts when the packet sets the
+// timeout or retries of the phases, so that only the leader sets them, and the
+// scheme and derivation of the group when they are not the default ones.
+func dkgInfoMessage(group *key.Group, pg *drand.DKGInfoPacket) []byte {
+	if len(pg.GetPhaseTimeouts()) == 0 && pg.GetPhaseRetries() == 0 && group.Scheme == "" && group.Derivation == "" {
+		return group.Hash()
+	}
+	h := sha256.New()
+	_, _ = h.Write(group.Hash())
+	_ = binary.Write(h, binary.BigEndian, pg.GetDkgTimeout())
+	_ = binary.Write(h, binary.BigEndian, uint32(len(pg.GetPhaseTimeouts())))
+	for _, t := range pg.GetPhaseTimeouts() {
+		_ = binary.Write(h, binary.BigEndian, t)
+	}
+	_ = binary.Write(h, binary.BigEndian, pg.GetPhaseRetries())
+	_ = binary.Write(h, binary.BigEndian, uint32(len(group.Scheme)))
+	_, _ = h.Write([]byte(group.Scheme))
+	if group.Derivation != "" {
+		_ = binary.Write(h, binary.BigEndian, uint32(len(group.Derivation)))
+		_, _ = h.Write([]byte(group.Derivation))
+	}
+	return h.Sum(nil)
 }
 
 // PushDKGInfo method is being called when a node received a group from the
 // leader. It runs some routines verification of the group before passing it on
-// to the routine that waits for the group to start the DKG.
+// to the routine that waits for the group to start the DKG. The leader may
+// override the timeout of the packet for each phase.
 func (r *setupReceiver) PushDKGInfo(pg *drand.DKGInfoPacket) error {
 	if !correctSecret(r.secret, pg.GetSecretProof()) {
 		r.l.Debug("received", "invalid_secret_proof")
@@ -326,31 +381,39 @@ func (r *setupReceiver) PushDKGInfo(pg *drand.DKGInfoPacket) error {
 	if err != nil {
 		return fmt.Errorf("group from leader invalid: %s", err)
 	}
-	if err := key.DKGAuthScheme.Verify(r.leaderID.Key, group.Hash(), pg.Signature); err != nil {
+	suite, err := r.leaderID.Suite()
+	if err != nil {
+		return err
+	}
+	if err := suite.DKGAuthScheme.Verify(r.leaderID.Key, dkgInfoMessage(group, pg), pg.Signature); err != nil {
 		r.l.Error("received", "group", "invalid_sig", err)
 		return fmt.Errorf("invalid group sig: %s", err)
 	}
+	phases, err := newPhaseTimeouts(pg.GetDkgTimeout(), pg.GetPhaseTimeouts(), pg.GetPhaseRetries())
+	if err != nil {
+		return err
+	}
 	checkGroup(r.l, group)
 	r.ch <- &dkgGroup{
-		group:   group,
-		timeout: pg.GetDkgTimeout(),
+		group:    group,
+		timeouts: phases,
 	}
 	return nil
 }
 
-func (r *setupReceiver) WaitDKGInfo(ctx context.Context) (*key.Group, uint32, error) {
+func (r *setupReceiver) WaitDKGInfo(ctx context.Context) (*key.Group, PhaseTimeouts, error) {
 	select {
 	case dkgGroup := <-r.ch:
 		if dkgGroup == nil {
-			return nil, 0, errors.New("unable to fetch group")
+			return nil, PhaseTimeouts{}, errors.New("unable to fetch group")
 		}
 		r.l.Debug("init_dkg", "received_group")
-		return dkgGroup.group, dkgGroup.timeout, nil
+		return dkgGroup.group, dkgGroup.timeouts, nil
 	case <-r.clock.After(MaxWaitPrepareDKG):
 		r.l.Error("init_dkg", "wait_group", "err", "timeout")
-		return nil, 0, errors.New("wait_group timeouts from coordinator")
+		return nil, PhaseTimeouts{}, errors.New("wait_group timeouts from coordinator")
 	case <-ctx.Done():
-		return nil, 0, ctx.Err()
+		return nil, PhaseTimeouts{}, ctx.Err()
 	}
 }
 
@@ -0,0 +1,323 @@
+package core
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"sync"
+	"time"
+
+	"github.com/drand/drand/chain"
+	"github.com/drand/drand/chain/beacon"
+	"github.com/drand/drand/fs"
+	"github.com/drand/drand/http"
+	"github.com/drand/drand/log"
+	"github.com/drand/drand/net"
+	"github.com/drand/drand/protobuf/drand"
+)
+
+// ObserverInfoFile is the name of the file, in the configuration folder, where
+// an observer keeps the info of the chain it follows, so that it restarts
+// without fetching it again.
+const ObserverInfoFile = "chain_info.json"
+
+// ObserverRetryPeriod is the time an observer waits before following the chain
+// again when none of its peers could be followed.
+var ObserverRetryPeriod = 10 * time.Second
+
+// Observer is a node that follows a chain from the nodes of its network and
+// serves it, without holding any key nor taking part in any DKG or in the
+// generation of the beacons. It serves the same public API as a drand node,
+// and serves the chain to the nodes syncing from it.
+type Observer struct {
+	drand.UnimplementedPublicServer
+	drand.UnimplementedControlServer
+	drand.UnimplementedProtocolServer
+
+	opts *Config
+	info *chain.Info
+	// derivation is the randomness derivation of the chain.
+	derivation *chain.Derivation
+	peers      []net.Peer
+	store      beacon.CallbackStore
+	syncer     beacon.Syncer
+	log        log.Logger
+
+	privGateway *net.PrivateGateway
+	pubGateway  *net.PublicGateway
+	control     net.ControlListener
+
+	cancel   context.CancelFunc
+	exitCh   chan bool
+	stopOnce sync.Once
+}
+
+var _ net.Service = (*Observer)(nil)
+var _ net.AdminServer = (*Observer)(nil)
+
+// NewObserver starts an observer of the chain of the given hash, following it
+// from the given peers. The info of the chain is fetched from the peers the
+// first time, then loaded from the configuration folder.
+func NewObserver(c *Config, chainHash []byte, peers []net.Peer) (*Observer, error) {
+	privAddr := c.PrivateListenAddress("")
+	if privAddr == "" {
+		return nil, errors.New("observer: the private listen address must be given")
+	}
+	if len(peers) == 0 {
+		return nil, errors.New("observer: no node to follow the chain from")
+	}
+	o := &Observer{
+		opts:   c,
+		peers:  peers,
+		log:    c.Logger().With("observer", fmt.Sprintf("%x", chainHash)),
+		exitCh: make(chan bool, 1),
+	}
+	ctx := context.Background()
+	var err error
+	o.privGateway, err = net.NewGRPCPrivateGateway(ctx, privAddr, c.certPath, c.keyPath, c.certmanager, o, c.insecure, c.dialOptions()...)
+	if err != nil {
+		return nil, err
+	}
+	if o.info, err = o.loadInfo(ctx, chainHash); err != nil {
+		o.privGateway.StopAll(ctx)
+		return nil, err
+	}
+	if o.derivation, err = chain.DerivationFromID(o.info.Derivation); err != nil {
+		o.privGateway.StopAll(ctx)
+		return nil, err
+	}
+	fs.CreateSecureFolder(c.dbFolder)
+	store, err := c.OpenStore()
+	if err != nil {
+		o.privGateway.StopAll(ctx)
+		return nil, err
+	}
+	if _, err := store.Last(); err != nil {
+		if err := store.Put(chain.GenesisBeacon(o.info)); err != nil {
+			store.Close()
+			o.privGateway.StopAll(ctx)
+			return nil, fmt.Errorf("observer: unable to insert genesis block: %s", err)
+		}
+	}
+	o.store = beacon.NewCallbackStore(store)
+	o.store.AddCallback("opts", c.callbacks)
+	o.syncer = beacon.NewSyncer(o.log, o.store, o.info, o.privGateway.ProtocolClient)
+
+	if pubAddr := c.PublicListenAddress(""); pubAddr != "" {
+		handler, err := http.New(ctx, &drandProxy{o}, c.Version(), o.log.With("server", "http"))
+		if err != nil {
+			o.store.Close()
+			o.privGateway.StopAll(ctx)
+			return nil, err
+		}
+		if o.pubGateway, err = c.publicGateway(ctx, pubAddr, handler); err != nil {
+			o.store.Close()
+			o.privGateway.StopAll(ctx)
+			return nil, err
+		}
+	}
+	o.control = net.NewTCPGrpcControlListener(o, c.ControlPort(), c.controlAuth.ServerOptions()...)
+	go o.control.Start()
+	o.privGateway.StartAll()
+	if o.pubGateway != nil {
+		o.pubGateway.StartAll()
+	}
+	o.log.Info("private_listen", privAddr, "control_port", c.ControlPort(), "public_listen", c.PublicListenAddress(""),
+		"folder", c.ConfigFolder())
+
+	var followCtx context.Context
+	followCtx, o.cancel = context.WithCancel(context.Background())
+	go o.follow(followCtx)
+	return o, nil
+}
+
+// loadInfo returns the info of the chain saved in the configuration folder,
+// or else fetches it from the peers and saves it.
+func (o *Observer) loadInfo(ctx context.Context, chainHash []byte) (*chain.Info, error) {
+	infoPath := path.Join(o.opts.ConfigFolder(), ObserverInfoFile)
+	if f, err := os.Open(infoPath); err == nil {
+		defer f.Close()
+		info, err := chain.InfoFromJSON(f)
+		if err != nil {
+			return nil, fmt.Errorf("observer: invalid chain info in %s: %s", infoPath, err)
+		}
+		if !bytes.Equal(info.Hash(), chainHash) {
+			return nil, fmt.Errorf("observer: %s holds the info of the chain %x", infoPath, info.Hash())
+		}
+		return info, nil
+	}
+	info, err := chainInfoFromPeers(ctx, o.privGateway, o.peers, o.log)
+	if err != nil {
+		return nil, err
+	}
+	if !bytes.Equal(info.Hash(), chainHash) {
+		return nil, errors.New("observer: invalid chain info hash")
+	}
+	fs.CreateSecureFolder(o.opts.ConfigFolder())
+	f, err := os.Create(infoPath)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	if err := info.ToJSON(f); err != nil {
+		return nil, err
+	}
+	return info, nil
+}
+
+// follow catches up with the chain from all the peers at once, then follows
+// it from one peer at a time, until the context is canceled.
+func (o *Observer) follow(ctx context.Context) {
+	for {
+		upTo := o.info.RoundAt(o.opts.clock.Now())
+		if last, err := o.store.Last(); err == nil && last.Round+1 < upTo {
+			if err := o.syncer.Follow(ctx, upTo, o.peers); err != nil {
+				o.log.Debug("observer", "catch_up", "err", err)
+			}
+		}
+		if err := o.syncer.Follow(ctx, 0, o.peers); err != nil {
+			o.log.Error("observer", "follow", "err", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-o.opts.clock.After(ObserverRetryPeriod):
+		}
+	}
+}
+
+// Info returns the info of the chain the observer follows.
+func (o *Observer) Info() *chain.Info {
+	return o.info
+}
+
+// Stop stops following the chain and closes the listeners.
+func (o *Observer) Stop(ctx context.Context) {
+	o.stopOnce.Do(func() {
+		o.cancel()
+		if o.pubGateway != nil {
+			o.pubGateway.StopAll(ctx)
+		}
+		o.privGateway.StopAll(ctx)
+		o.control.Stop()
+		o.store.Close()
+		o.exitCh <- true
+	})
+}
+
+// WaitExit returns a channel that signals when the observer stops
+func (o *Observer) WaitExit() chan bool {
+	return o.exitCh
+}
+
+// PublicRand returns the beacon of the requested round, or the last one if the
+// round is 0.
+func (o *Observer) PublicRand(c context.Context, in *drand.PublicRandRequest) (*drand.PublicRandResponse, error) {
+	var r *chain.Beacon
+	var err error
+	if in.GetRound() == 0 {
+		r, err = o.store.Last()
+	} else {
+		r, err = o.store.Get(in.GetRound())
+	}
+	if err != nil || r == nil {
+		o.log.Debug("public_rand", "unstored_beacon", "round", in.GetRound(), "from", net.RemoteAddress(c))
+		return nil, fmt.Errorf("can't retrieve beacon: %w %s", err, r)
+	}
+	return beaconToProto(r, o.derivation), nil
+}
+
+// PublicRandStream streams the beacons from the requested round, if any, then
+// the new beacons as the observer stores them.
+func (o *Observer) PublicRandStream(req *drand.PublicRandRequest, stream drand.Public_PublicRandStreamServer) error {
+	addr := net.RemoteAddress(stream.Context())
+	last, err := o.store.Last()
+	if err != nil {
+		return err
+	}
+	if req.GetRound() != 0 && req.GetRound() <= last.Round {
+		var err error
+		o.store.Cursor(func(c chain.Cursor) {
+			for b := c.Seek(req.GetRound()); b != nil; b = c.Next() {
+				if err = stream.Send(beaconToProto(b, o.derivation)); err != nil {
+					return
+				}
+			}
+		})
+		if err != nil {
+			return err
+		}
+	}
+	done := make(chan error, 1)
+	o.store.AddCallback(addr, func(b *chain.Beacon) {
+		if err := stream.Send(beaconToProto(b, o.derivation)); err != nil {
+			o.store.RemoveCallback(addr)
+			select {
+			case done <- err:
+			default:
+			}
+		}
+	})
+	defer o.store.RemoveCallback(addr)
+	select {
+	case err := <-done:
+		return err
+	case <-stream.Context().Done():
+		return stream.Context().Err()
+	}
+}
+
+// ChainInfo returns the info of the chain the observer follows.
+func (o *Observer) ChainInfo(ctx context.Context, in *drand.ChainInfoRequest) (*drand.ChainInfoPacket, error) {
+	return o.info.ToProto(), nil
+}
+
+func (o *Observer) chainInfo(ctx context.Context) (*chain.Info, error) {
+	return o.info, nil
+}
+
+// Home provides the address the observer is listening on.
+func (o *Observer) Home(c context.Context, in *drand.HomeRequest) (*drand.HomeResponse, error) {
+	return &drand.HomeResponse{
+		Status: fmt.Sprintf("drand observer up and running on %s", o.opts.PrivateListenAddress("")),
+	}, nil
+}
+
+// SyncChain serves the chain to the nodes syncing from the observer.
+func (o *Observer) SyncChain(req *drand.SyncRequest, stream drand.Protocol_SyncChainServer) error {
+	return o.syncer.SyncChain(req, stream)
+}
+
+// PingPong simply responds with an empty packet, proving that the observer
+// is alive.
+func (o *Observer) PingPong(c context.Context, in *drand.Ping) (*drand.Pong, error) {
+	return &drand.Pong{}, nil
+}
+
+// Shutdown stops the observer.
+func (o *Observer) Shutdown(ctx context.Context, in *drand.ShutdownRequest) (*drand.ShutdownResponse, error) {
+	o.Stop(ctx)
+	return nil, nil
+}
+
+// AdminCall answers the admin requests of the observer, which only knows about
+// the sync of the chain and its health.
+func (o *Observer) AdminCall(ctx context.Context, req *net.AdminRequest) (interface{}, error) {
+	switch req.Method {
+	case AdminSyncStatus:
+		return o.syncer.Status(), nil
+	case AdminNodeStatus:
+		return o.nodeStatus(ctx), nil
+	default:
+		return nil, net.ErrUnknownAdminMethod(req.Method)
+	}
+}
+
+// AdminWatch answers the streaming admin requests of the observer, which has
+// none.
+func (o *Observer) AdminWatch(req *net.AdminRequest, stream net.AdminSender) error {
+	return net.ErrUnknownAdminMethod(req.Method)
+}
@@ -0,0 +1,140 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/drand/drand/chain"
+	"github.com/drand/drand/chain/beacon"
+	"github.com/drand/drand/key"
+)
+
+// NodeStatus is the health of a node, served on the /status endpoint of its
+// public API and by the AdminNodeStatus method of its control port, for the
+// load balancers and the monitoring.
+type NodeStatus struct {
+	// Healthy is false when the node has problems, listed in Problems.
+	Healthy  bool     `json:"healthy"`
+	Problems []string `json:"problems,omitempty"`
+	BeaconID string   `json:"beacon_id,omitempty"`
+	// Chain is the state of the chain of the node, once it has one.
+	Chain   *ChainStatus  `json:"chain,omitempty"`
+	Storage StorageStatus `json:"storage"`
+	// DKG is the state of the last DKG or resharing, if any.
+	DKG    *DKGSummary `json:"dkg,omitempty"`
+	Paused bool        `json:"paused"`
+	Drain  string      `json:"drain"`
+	// Peers counts the members of the group which sent their partial
+	// signature of the last accounted round.
+	Peers *PeersStatus `json:"peers,omitempty"`
+	// Participation details the participation of each member of the group.
+	Participation *beacon.ParticipationReport `json:"participation,omitempty"`
+}
+
+// ChainStatus is the progress of the chain of a node.
+type ChainStatus struct {
+	LastRound     uint64 `json:"last_round"`
+	ExpectedRound uint64 `json:"expected_round"`
+	// Lag is the number of rounds the node is behind.
+	Lag uint64 `json:"lag"`
+	// LastBeaconTime is the unix time at which the node stored its last
+	// beacon, zero if it stored none since it started.
+	LastBeaconTime int64 `json:"last_beacon_time"`
+}
+
+// StorageStatus tells whether the database of the beacons of the node can be
+// read.
+type StorageStatus struct {
+	Healthy bool   `json:"healthy"`
+	Error   string `json:"error,omitempty"`
+}
+
+// DKGSummary is the summary of a DKGStatus.
+type DKGSummary struct {
+	Running bool   `json:"running"`
+	Reshare bool   `json:"reshare"`
+	Phase   string `json:"phase"`
+	Error   string `json:"error,omitempty"`
+}
+
+// PeersStatus counts the active members of the group.
+type PeersStatus struct {
+	Active    int `json:"active"`
+	Total     int `json:"total"`
+	Threshold int `json:"threshold"`
+}
+
+func (s *NodeStatus) problem(format string, args ...interface{}) {
+	s.Problems = append(s.Problems, fmt.Sprintf(format, args...))
+}
+
+// checkChain sets the state of the chain and of the storage of the node, whose
+// chain follows the given schedule.
+func (s *NodeStatus) checkChain(store chain.Store, schedule []*key.Epoch, now, lastStored time.Time) {
+	last, err := store.Last()
+	if err != nil {
+		s.Storage.Error = err.Error()
+		s.problem("storage: %s", err)
+		return
+	}
+	s.Storage.Healthy = true
+	expected := chain.EpochCurrentRound(now.Unix(), schedule)
+	s.Chain = &ChainStatus{LastRound: last.Round, ExpectedRound: expected}
+	if !lastStored.IsZero() {
+		s.Chain.LastBeaconTime = lastStored.Unix()
+	}
+	if expected > last.Round {
+		s.Chain.Lag = expected - last.Round
+	}
+	// the beacon of the current round may not be aggregated yet
+	if s.Chain.Lag > 1 {
+		s.problem("chain: %d rounds behind", s.Chain.Lag)
+	}
+}
+
+// nodeStatus returns the health of the node.
+func (d *Drand) nodeStatus(ctx context.Context) *NodeStatus {
+	s := &NodeStatus{BeaconID: d.opts.BeaconID(), Drain: d.drain.status().State}
+	d.state.Lock()
+	group, b, m := d.group, d.beacon, d.dkgMonitor
+	d.state.Unlock()
+	if m != nil {
+		dkg := m.Status()
+		s.DKG = &DKGSummary{Running: dkg.Running, Reshare: dkg.Reshare, Phase: dkg.Phase, Error: dkg.Error}
+	}
+	if s.Drain != DrainActive {
+		s.problem("drain: the node is %s", s.Drain)
+	}
+	switch {
+	case group == nil:
+		s.problem("beacon: no group, the node waits for a DKG")
+	case b == nil:
+		s.problem("beacon: not running")
+	default:
+		s.Paused = b.Paused()
+		s.checkChain(b.Store(), group.Schedule(), d.opts.clock.Now(), b.LastStoredTime())
+		report := b.Participation()
+		s.Participation = report
+		s.Peers = &PeersStatus{Total: len(report.Peers), Threshold: group.Threshold}
+		for _, p := range report.Peers {
+			if report.Round > 0 && p.LastRound >= report.Round {
+				s.Peers.Active++
+			}
+		}
+		if report.Round > 0 && s.Peers.Active < group.Threshold {
+			s.problem("peers: %d of the %d members are active, below the threshold of %d",
+				s.Peers.Active, s.Peers.Total, group.Threshold)
+		}
+	}
+	s.Healthy = len(s.Problems) == 0
+	return s
+}
+
+// nodeStatus returns the health of the observer.
+func (o *Observer) nodeStatus(ctx context.Context) *NodeStatus {
+	s := &NodeStatus{Drain: DrainActive}
+	s.checkChain(o.store, o.info.Schedule(), o.opts.clock.Now(), time.Time{})
+	s.Healthy = len(s.Problems) == 0
+	return s
+}
@@ -0,0 +1,47 @@
+package core
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/drand/drand/chain"
+	"github.com/drand/drand/chain/boltdb"
+	"github.com/drand/drand/key"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNodeStatusChain(t *testing.T) {
+	dir, err := ioutil.TempDir("", "status")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	store, err := boltdb.NewBoltStore(dir, nil)
+	require.NoError(t, err)
+	defer store.Close()
+
+	schedule := []*key.Epoch{{Round: 1, Time: 1000, Period: 10 * time.Second}}
+	now := time.Unix(1055, 0)
+
+	// an empty store cannot tell the last beacon
+	s := &NodeStatus{}
+	s.checkChain(store, schedule, now, time.Time{})
+	require.False(t, s.Storage.Healthy)
+	require.Len(t, s.Problems, 1)
+
+	require.NoError(t, store.Put(&chain.Beacon{Round: 3, Signature: []byte{0x01}}))
+	s = &NodeStatus{}
+	s.checkChain(store, schedule, now, now)
+	require.True(t, s.Storage.Healthy)
+	require.Equal(t, uint64(6), s.Chain.ExpectedRound)
+	require.Equal(t, uint64(3), s.Chain.Lag)
+	require.Equal(t, now.Unix(), s.Chain.LastBeaconTime)
+	require.Len(t, s.Problems, 1)
+
+	// the beacon of the current round may still be aggregated
+	require.NoError(t, store.Put(&chain.Beacon{Round: 5, Signature: []byte{0x02}}))
+	s = &NodeStatus{}
+	s.checkChain(store, schedule, now, now)
+	require.Equal(t, uint64(1), s.Chain.Lag)
+	require.Empty(t, s.Problems)
+}
@@ -0,0 +1,64 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"path"
+
+	"github.com/drand/drand/chain"
+	"github.com/drand/drand/chain/badgerdb"
+	"github.com/drand/drand/chain/boltdb"
+	"github.com/drand/drand/chain/sqldb"
+	"github.com/drand/drand/fs"
+	bolt "go.etcd.io/bbolt"
+)
+
+// The storage engines of the beacon database, see WithDBEngine.
+const (
+	BoltEngine     = "bolt"
+	BadgerEngine   = "badger"
+	SQLiteEngine   = "sqlite"
+	PostgresEngine = "postgres"
+)
+
+// DefaultDBEngine is the storage engine used when none is set.
+const DefaultDBEngine = BoltEngine
+
+// SQLiteFileName is the name of the SQLite database file in the database
+// folder, when no data source is given.
+const SQLiteFileName = "drand.sqlite"
+
+// DBEngines returns the names of the storage engines of the beacon database.
+func DBEngines() []string {
+	return []string{BoltEngine, BadgerEngine, SQLiteEngine, PostgresEngine}
+}
+
+// OpenStore opens the beacon database using the given storage engine. The
+// file based engines store the database in the folder, and the SQL engines
+// use the data source url when set. The bolt options only apply to the bolt
+// engine.
+func OpenStore(engine, folder, url string, boltOpts *bolt.Options) (chain.Store, error) {
+	switch engine {
+	case "", BoltEngine:
+		return boltdb.NewBoltStore(folder, boltOpts)
+	case BadgerEngine:
+		return badgerdb.NewBadgerStore(folder)
+	case SQLiteEngine:
+		if url == "" {
+			url = path.Join(folder, SQLiteFileName)
+		}
+		return sqldb.NewSQLStore("sqlite3", url)
+	case PostgresEngine:
+		if url == "" {
+			return nil, errors.New("the postgres engine needs the data source of the database")
+		}
+		return sqldb.NewSQLStore("postgres", url)
+	}
+	return nil, fmt.Errorf("unknown database engine %q", engine)
+}
+
+// OpenStore opens the beacon database with the storage engine of the config.
+func (d *Config) OpenStore() (chain.Store, error) {
+	fs.CreateSecureFolder(d.dbFolder)
+	return OpenStore(d.dbEngine, d.dbFolder, d.dbURL, d.boltOpts)
+}
@@ -53,6 +53,10 @@ type DrandTest2 struct {
 	groupPath string
 	// only set after the resharing
 	newGroup *key.Group
+	// period the next resharing switches the chain to, when set
+	resharePeriod time.Duration
+	// derivation of the chain of the DKG, when set
+	derivation string
 	// nodes that are created for running a first DKG
 	nodes []*Node
 	// new additional nodes that are created for running a resharing
@@ -107,6 +111,7 @@ func (d *DrandTest2) RunDKG() *key.Group {
 	root := d.nodes[0]
 	controlClient, err := net.NewControlClient(root.drand.opts.controlPort)
 	require.NoError(d.t, err)
+	controlClient.SetDerivation(d.derivation)
 	// the root node will return the group over this channel
 	var wg sync.WaitGroup
 	wg.Add(d.n)
@@ -292,6 +297,20 @@ func (d *DrandTest2) TestPublicBeacon(id string, newGroup bool) *drand.PublicRan
 	return resp
 }
 
+// UseScheme gives the initial nodes new keys of the given scheme, so that the
+// DKG creates a group of that scheme.
+func (d *DrandTest2) UseScheme(schemeID string) {
+	for _, node := range d.nodes {
+		dr := node.drand
+		kp, err := key.NewSchemeKeyPair(dr.priv.Public.Addr, schemeID)
+		require.NoError(d.t, err)
+		kp.Public.TLS = dr.priv.Public.TLS
+		kp.SelfSign()
+		require.NoError(d.t, dr.store.SaveKeyPair(kp))
+		dr.priv = kp
+	}
+}
+
 // SetupNewNodes creates new additional nodes that can participate during the
 // resharing
 func (d *DrandTest2) SetupNewNodes(newNodes int) []*Node {
@@ -344,6 +363,7 @@ func (d *DrandTest2) runLeaderReshare(timeout time.Duration, errCh chan error, g
 	// old root: oldNode.Index leater: leader.addr
 	client, err := net.NewControlClient(leader.drand.opts.controlPort)
 	require.NoError(d.t, err)
+	client.SetResharePeriod(d.resharePeriod)
 	finalGroup, err := client.InitReshareLeader(d.newN, d.newThr, timeout, 0, secret, "", testBeaconOffset)
 	// Done resharing
 	if err != nil {
@@ -87,6 +87,22 @@ func Files(folderPath string) ([]string, error) {
 	return files, nil
 }
 
+// Folders returns the list of folder names included in the given path or error
+// if any.
+func Folders(folderPath string) ([]string, error) {
+	fi, err := ioutil.ReadDir(folderPath)
+	if err != nil {
+		return nil, err
+	}
+	var folders []string
+	for _, f := range fi {
+		if f.IsDir() {
+			folders = append(folders, path.Join(folderPath, f.Name()))
+		}
+	}
+	return folders, nil
+}
+
 // FileExists returns true if the given name is a file in the given path. name
 // must be the "basename" of the file and path must be the folder where it lies.
 func FileExists(filePath, name string) bool {
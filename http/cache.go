@@ -0,0 +1,57 @@
+package http
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"math"
+	"net/http"
+	"time"
+)
+
+// immutableMaxAge is how long the caches keep the responses which never
+// change, such as the past rounds.
+const immutableMaxAge = 365 * 24 * time.Hour
+
+// etag returns the strong entity tag of a response body. The bodies are
+// encoded the same way on every relay, so that a CDN in front of several
+// relays revalidates its copies with any of them.
+func etag(data []byte) string {
+	h := sha256.Sum256(data)
+	return `"` + hex.EncodeToString(h[:16]) + `"`
+}
+
+// serveImmutable serves a response which never changes, letting the caches
+// keep it for a year and answering the conditional requests.
+func serveImmutable(w http.ResponseWriter, r *http.Request, name string, modtime time.Time, data []byte) {
+	w.Header().Set("Cache-Control", fmt.Sprintf("public, max-age=%d, immutable", int(immutableMaxAge.Seconds())))
+	w.Header().Set("Expires", time.Now().Add(immutableMaxAge).Format(http.TimeFormat))
+	w.Header().Set("ETag", etag(data))
+	http.ServeContent(w, r, name, modtime, bytes.NewReader(data))
+}
+
+// serveUntil serves a response which changes at `expires`, letting the
+// caches keep it until then and answering the conditional requests.
+func serveUntil(w http.ResponseWriter, r *http.Request, name string, modtime, expires time.Time, data []byte) {
+	if remaining := time.Until(expires); remaining > 0 {
+		seconds := int(math.Ceil(remaining.Seconds()))
+		w.Header().Set("Cache-Control", fmt.Sprintf("public, max-age=%d, must-revalidate", seconds))
+	} else {
+		w.Header().Set("Cache-Control", "public, max-age=0, must-revalidate")
+	}
+	w.Header().Set("Expires", expires.Format(http.TimeFormat))
+	w.Header().Set("ETag", etag(data))
+	http.ServeContent(w, r, name, modtime, bytes.NewReader(data))
+}
+
+// notYet answers a request for a round that does not exist yet, which the
+// caches may remember until the round is expected.
+func notYet(w http.ResponseWriter, expected time.Time) {
+	if remaining := time.Until(expected); remaining > 0 {
+		w.Header().Set("Cache-Control", fmt.Sprintf("public, must-revalidate, max-age=%d", int(remaining.Seconds())))
+	} else {
+		w.Header().Set("Cache-Control", "must-revalidate, no-cache, max-age=0")
+	}
+	w.WriteHeader(http.StatusNotFound)
+}
@@ -0,0 +1,47 @@
+package http
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestServeImmutable(t *testing.T) {
+	data := []byte(`{"round":1}`)
+	modtime := time.Unix(1595431050, 0)
+
+	w := httptest.NewRecorder()
+	serveImmutable(w, httptest.NewRequest("GET", "/public/1", nil), "rand.json", modtime, data)
+	require.Equal(t, http.StatusOK, w.Code)
+	require.Contains(t, w.Header().Get("Cache-Control"), "immutable")
+	tag := w.Header().Get("ETag")
+	require.Equal(t, etag(data), tag)
+
+	// the caches revalidate their copy with its entity tag
+	r := httptest.NewRequest("GET", "/public/1", nil)
+	r.Header.Set("If-None-Match", tag)
+	w = httptest.NewRecorder()
+	serveImmutable(w, r, "rand.json", modtime, data)
+	require.Equal(t, http.StatusNotModified, w.Code)
+	require.Zero(t, w.Body.Len())
+}
+
+func TestServeUntil(t *testing.T) {
+	data := []byte(`{"round":2}`)
+	w := httptest.NewRecorder()
+	serveUntil(w, httptest.NewRequest("GET", "/public/latest", nil), "latest.json", time.Now(), time.Now().Add(10*time.Second), data)
+	require.Equal(t, http.StatusOK, w.Code)
+	cc := w.Header().Get("Cache-Control")
+	require.True(t, strings.HasPrefix(cc, "public, max-age="), cc)
+	require.NotContains(t, cc, "immutable")
+	require.NotEmpty(t, w.Header().Get("ETag"))
+
+	w = httptest.NewRecorder()
+	notYet(w, time.Now().Add(time.Minute))
+	require.Equal(t, http.StatusNotFound, w.Code)
+	require.Contains(t, w.Header().Get("Cache-Control"), "max-age=")
+}
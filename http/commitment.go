@@ -0,0 +1,147 @@
+package http
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/drand/drand/chain"
+	"github.com/drand/drand/client"
+
+	json "github.com/nikkolasg/hexjson"
+)
+
+const (
+	// commitmentTimeout is how long fetching the rounds of a commitment may
+	// take.
+	commitmentTimeout = time.Minute
+	// commitmentConcurrency is the number of rounds of a commitment fetched
+	// concurrently.
+	commitmentConcurrency = 16
+	// commitmentCacheSize is the number of Merkle trees kept in memory.
+	commitmentCacheSize = 16
+)
+
+// proofResponse is the response of the /proof endpoint: the commitment to the
+// range of a round, and the proof that the round is part of it.
+type proofResponse struct {
+	Commitment *chain.Commitment     `json:"commitment"`
+	Proof      *chain.InclusionProof `json:"proof"`
+}
+
+// Proof serves the inclusion proof of a round in the commitment to its range
+// of rounds, once all the rounds of the range were produced.
+func (h *handler) Proof(w http.ResponseWriter, r *http.Request) {
+	round := strings.Replace(r.URL.Path, "/proof/", "", 1)
+	roundN, err := strconv.ParseUint(round, 10, 64)
+	if err != nil || roundN == 0 {
+		w.WriteHeader(http.StatusBadRequest)
+		h.log.Warn("http_server", "failed to parse client round", "client", r.RemoteAddr, "req", url.PathEscape(r.URL.Path))
+		return
+	}
+
+	info := h.getChainInfo(r.Context())
+	if info == nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		h.log.Warn("http_server", "failed to get chain info", "client", r.RemoteAddr, "req", url.PathEscape(r.URL.Path))
+		return
+	}
+
+	from, to := chain.CommitmentRange(roundN)
+	complete := info.TimeOfRound(to)
+	if complete.After(time.Now()) {
+		notYet(w, complete)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), commitmentTimeout)
+	defer cancel()
+	tree, err := h.commitments.tree(ctx, h.client, from, to)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		h.log.Warn("http_server", "failed to build commitment", "client", r.RemoteAddr, "req", url.PathEscape(r.URL.Path), "err", err)
+		return
+	}
+	proof, err := tree.Prove(roundN)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		h.log.Warn("http_server", "failed to prove round", "client", r.RemoteAddr, "req", url.PathEscape(r.URL.Path), "err", err)
+		return
+	}
+	data, err := json.Marshal(&proofResponse{Commitment: tree.Commitment(), Proof: proof})
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		h.log.Warn("http_server", "failed to marshal proof", "client", r.RemoteAddr, "req", url.PathEscape(r.URL.Path), "err", err)
+		return
+	}
+
+	serveImmutable(w, r, "proof.json", complete, data)
+}
+
+// commitments keeps the Merkle trees over the most recently requested ranges
+// of rounds.
+type commitments struct {
+	// lk serializes the building of trees, so that concurrent requests for a
+	// range fetch its rounds once.
+	lk    sync.Mutex
+	trees map[uint64]*chain.MerkleTree
+	// order holds the first rounds of the trees, oldest first.
+	order []uint64
+}
+
+// tree returns the Merkle tree over rounds `from` to `to`, fetching them with
+// `c` unless the tree is cached.
+func (cm *commitments) tree(ctx context.Context, c client.Client, from, to uint64) (*chain.MerkleTree, error) {
+	cm.lk.Lock()
+	defer cm.lk.Unlock()
+	if t, ok := cm.trees[from]; ok {
+		return t, nil
+	}
+
+	beacons := make([]*chain.Beacon, to-from+1)
+	errs := make([]error, len(beacons))
+	tokens := make(chan struct{}, commitmentConcurrency)
+	wg := sync.WaitGroup{}
+	for i := range beacons {
+		tokens <- struct{}{}
+		wg.Add(1)
+		go func(i int) {
+			defer func() {
+				<-tokens
+				wg.Done()
+			}()
+			res, err := c.Get(ctx, from+uint64(i))
+			if err != nil {
+				errs[i] = err
+				return
+			}
+			beacons[i] = &chain.Beacon{Round: res.Round(), Signature: res.Signature()}
+		}(i)
+	}
+	wg.Wait()
+	for i, err := range errs {
+		if err != nil {
+			return nil, fmt.Errorf("getting round %d: %w", from+uint64(i), err)
+		}
+	}
+
+	t, err := chain.NewMerkleTree(beacons)
+	if err != nil {
+		return nil, err
+	}
+	if cm.trees == nil {
+		cm.trees = make(map[uint64]*chain.MerkleTree)
+	}
+	if len(cm.order) == commitmentCacheSize {
+		delete(cm.trees, cm.order[0])
+		cm.order = cm.order[1:]
+	}
+	cm.trees[from] = t
+	cm.order = append(cm.order, from)
+	return t, nil
+}
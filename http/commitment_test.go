@@ -0,0 +1,67 @@
+package http
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/drand/drand/chain"
+	"github.com/drand/drand/client"
+	resultmock "github.com/drand/drand/client/test/result/mock"
+	"github.com/drand/drand/key"
+	"github.com/stretchr/testify/require"
+
+	json "github.com/nikkolasg/hexjson"
+)
+
+// historyClient serves mock results for any round.
+type historyClient struct {
+	client.Client
+}
+
+func (c *historyClient) Get(ctx context.Context, round uint64) (client.Result, error) {
+	r := resultmock.NewMockResult(round)
+	return &r, nil
+}
+
+func TestHTTPProof(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	info := &chain.Info{
+		PublicKey:   key.KeyGroup.Point().Base(),
+		Period:      time.Second,
+		GenesisTime: time.Now().Unix() - 2*chain.CommitmentSpan,
+	}
+	c := &historyClient{client.EmptyClientWithInfo(info)}
+	handler, err := New(ctx, c, "", nil)
+	require.NoError(t, err)
+
+	listener, err := net.Listen("tcp", "localhost:0")
+	require.NoError(t, err)
+	server := http.Server{Handler: handler}
+	go func() { _ = server.Serve(listener) }()
+	defer func() { _ = server.Shutdown(ctx) }()
+
+	resp, err := http.Get(fmt.Sprintf("http://%s/proof/42", listener.Addr().String()))
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	var res proofResponse
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&res))
+	require.NoError(t, resp.Body.Close())
+	require.Equal(t, uint64(1), res.Commitment.From)
+	require.Equal(t, uint64(chain.CommitmentSpan), res.Commitment.To)
+
+	r := resultmock.NewMockResult(42)
+	b := &chain.Beacon{Round: r.Round(), Signature: r.Signature()}
+	require.NoError(t, chain.VerifyInclusion(res.Commitment.Root, res.Proof, b))
+
+	// the range of the current round is not complete yet.
+	resp, err = http.Get(fmt.Sprintf("http://%s/proof/%d", listener.Addr().String(), 2*chain.CommitmentSpan+1))
+	require.NoError(t, err)
+	require.Equal(t, http.StatusNotFound, resp.StatusCode)
+	require.NoError(t, resp.Body.Close())
+}
@@ -0,0 +1,95 @@
+package http
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/drand/drand/client"
+	resultmock "github.com/drand/drand/client/test/result/mock"
+	"github.com/stretchr/testify/require"
+
+	json "github.com/nikkolasg/hexjson"
+)
+
+func TestCompress(t *testing.T) {
+	data := []byte(strings.Repeat(`{"round":1}`, 100))
+	h := compress(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		serveImmutable(w, r, "rand.json", time.Unix(1595431050, 0), data)
+	}))
+
+	r := httptest.NewRequest("GET", "/public/1", nil)
+	r.Header.Set("Accept-Encoding", "br;q=1.0, gzip;q=0.8")
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	require.Equal(t, http.StatusOK, w.Code)
+	require.Equal(t, "gzip", w.Header().Get("Content-Encoding"))
+	require.Equal(t, "Accept-Encoding", w.Header().Get("Vary"))
+	require.Less(t, w.Body.Len(), len(data))
+	gz, err := gzip.NewReader(w.Body)
+	require.NoError(t, err)
+	plain, err := ioutil.ReadAll(gz)
+	require.NoError(t, err)
+	require.Equal(t, data, plain)
+
+	// the compressed response is revalidated with its own tag
+	tag := w.Header().Get("ETag")
+	require.NotEqual(t, etag(data), tag)
+	r.Header.Set("If-None-Match", tag)
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	require.Equal(t, http.StatusNotModified, w.Code)
+	require.Empty(t, w.Header().Get("Content-Encoding"))
+
+	for _, accept := range []string{"", "identity", "gzip;q=0"} {
+		r = httptest.NewRequest("GET", "/public/1", nil)
+		r.Header.Set("Accept-Encoding", accept)
+		w = httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+		require.Empty(t, w.Header().Get("Content-Encoding"), accept)
+		require.Equal(t, data, w.Body.Bytes())
+	}
+}
+
+// BenchmarkRoundsCompression reports the bytes sent for a full page of the
+// rounds endpoint, with and without compression.
+func BenchmarkRoundsCompression(b *testing.B) {
+	results := make([]client.Result, maxRoundsPage)
+	for i := range results {
+		r := resultmock.NewMockResult(uint64(i + 1))
+		r.Sig = make([]byte, 96)
+		r.PSig = make([]byte, 96)
+		copy(r.Sig, r.Rand)
+		copy(r.PSig, r.Rand)
+		results[i] = &r
+	}
+	data, err := json.Marshal(&roundsResponse{Rounds: results})
+	require.NoError(b, err)
+	h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		serveImmutable(w, r, "rounds.json", time.Unix(1595431050, 0), data)
+	})
+
+	for _, gzip := range []bool{false, true} {
+		b.Run(fmt.Sprintf("gzip=%v", gzip), func(b *testing.B) {
+			served := http.Handler(h)
+			if gzip {
+				served = compress(h)
+			}
+			var sent int
+			for i := 0; i < b.N; i++ {
+				r := httptest.NewRequest("GET", "/public/rounds?start=1&end=1000", nil)
+				r.Header.Set("Accept-Encoding", "gzip")
+				w := httptest.NewRecorder()
+				served.ServeHTTP(w, r)
+				sent = w.Body.Len()
+			}
+			b.ReportMetric(float64(sent), "bytes/page")
+			b.ReportMetric(float64(sent)/float64(len(data)), "ratio")
+		})
+	}
+}
@@ -0,0 +1,125 @@
+package http
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CORSPolicy is the cross-origin policy of the relay, telling the browsers
+// which web applications may call it.
+type CORSPolicy struct {
+	// AllowedOrigins are the origins allowed to call the relay, "*" allowing
+	// any origin. Each origin may hold a "*" wildcard for a subdomain, such
+	// as "https://*.example.com".
+	AllowedOrigins []string
+	// AllowedMethods are the methods allowed in the cross-origin requests.
+	AllowedMethods []string
+	// AllowedHeaders are the headers allowed in the cross-origin requests.
+	AllowedHeaders []string
+	// MaxAge is how long the browsers may cache the result of a preflight
+	// request.
+	MaxAge time.Duration
+}
+
+// DefaultCORSPolicy lets any web application read the randomness, including
+// with the gRPC-Web clients which POST their requests.
+func DefaultCORSPolicy() CORSPolicy {
+	return CORSPolicy{
+		AllowedOrigins: []string{"*"},
+		AllowedMethods: []string{http.MethodGet, http.MethodHead, http.MethodPost},
+		AllowedHeaders: []string{"Authorization", "Last-Event-ID", "If-None-Match", "If-Modified-Since",
+			"Content-Type", "X-Grpc-Web", "X-User-Agent"},
+		MaxAge: 24 * time.Hour,
+	}
+}
+
+// corsExposedHeaders are the headers of the responses the web applications
+// may read.
+var corsExposedHeaders = "ETag, Expires, Last-Modified, Retry-After, Grpc-Status, Grpc-Message, " + SignatureHeader
+
+func (p *CORSPolicy) allowsOrigin(origin string) bool {
+	for _, o := range p.AllowedOrigins {
+		if o == "*" || strings.EqualFold(o, origin) {
+			return true
+		}
+		if i := strings.Index(o, "*"); i >= 0 {
+			prefix, suffix := o[:i], o[i+1:]
+			if len(origin) > len(prefix)+len(suffix) &&
+				strings.HasPrefix(strings.ToLower(origin), strings.ToLower(prefix)) &&
+				strings.HasSuffix(strings.ToLower(origin), strings.ToLower(suffix)) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (p *CORSPolicy) allowsMethod(method string) bool {
+	if method == http.MethodOptions {
+		return true
+	}
+	for _, m := range p.AllowedMethods {
+		if strings.EqualFold(m, method) {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *CORSPolicy) anyOrigin() bool {
+	for _, o := range p.AllowedOrigins {
+		if o == "*" {
+			return true
+		}
+	}
+	return false
+}
+
+// cors applies the policy to the requests of h, and answers the preflight
+// requests. The requests from the origins the policy refuses are served
+// without the headers allowing the browsers to read their responses. A policy
+// allowing any origin sets the same headers on all the responses, so that the
+// caches need not vary them by origin.
+func (p CORSPolicy) cors(h http.Handler) http.Handler {
+	methods := strings.Join(append(append([]string{}, p.AllowedMethods...), http.MethodOptions), ", ")
+	headers := strings.Join(p.AllowedHeaders, ", ")
+	maxAge := strconv.Itoa(int(p.MaxAge.Seconds()))
+	anyOrigin := p.anyOrigin()
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		origin := r.Header.Get("Origin")
+		allowed := anyOrigin || (origin != "" && p.allowsOrigin(origin))
+		if !anyOrigin {
+			w.Header().Add("Vary", "Origin")
+		}
+
+		if r.Method == http.MethodOptions && r.Header.Get("Access-Control-Request-Method") != "" {
+			if !allowed || !p.allowsMethod(r.Header.Get("Access-Control-Request-Method")) {
+				w.WriteHeader(http.StatusForbidden)
+				return
+			}
+			p.allow(w, origin, anyOrigin)
+			w.Header().Set("Access-Control-Allow-Methods", methods)
+			if headers != "" {
+				w.Header().Set("Access-Control-Allow-Headers", headers)
+			}
+			w.Header().Set("Access-Control-Max-Age", maxAge)
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		if allowed && p.allowsMethod(r.Method) {
+			p.allow(w, origin, anyOrigin)
+			w.Header().Set("Access-Control-Expose-Headers", corsExposedHeaders)
+		}
+		h.ServeHTTP(w, r)
+	})
+}
+
+func (p *CORSPolicy) allow(w http.ResponseWriter, origin string, anyOrigin bool) {
+	if anyOrigin {
+		w.Header().Set("Access-Control-Allow-Origin", "*")
+		return
+	}
+	w.Header().Set("Access-Control-Allow-Origin", origin)
+}
@@ -0,0 +1,54 @@
+package http
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCORS(t *testing.T) {
+	ok := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	request := func(h http.Handler, method, origin string, preflight bool) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(method, "/public/latest", nil)
+		if origin != "" {
+			r.Header.Set("Origin", origin)
+		}
+		if preflight {
+			r.Header.Set("Access-Control-Request-Method", http.MethodGet)
+		}
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+		return w
+	}
+
+	// by default any origin may read the randomness
+	h := DefaultCORSPolicy().cors(ok)
+	w := request(h, http.MethodGet, "", false)
+	require.Equal(t, "*", w.Header().Get("Access-Control-Allow-Origin"))
+	w = request(h, http.MethodOptions, "https://app.example.com", true)
+	require.Equal(t, http.StatusNoContent, w.Code)
+	require.Equal(t, "*", w.Header().Get("Access-Control-Allow-Origin"))
+	require.Contains(t, w.Header().Get("Access-Control-Allow-Methods"), http.MethodGet)
+
+	policy := DefaultCORSPolicy()
+	policy.AllowedOrigins = []string{"https://*.example.com", "https://drand.love"}
+	h = policy.cors(ok)
+	for _, origin := range []string{"https://app.example.com", "https://drand.love"} {
+		w = request(h, http.MethodGet, origin, false)
+		require.Equal(t, origin, w.Header().Get("Access-Control-Allow-Origin"))
+		require.Equal(t, "Origin", w.Header().Get("Vary"))
+		w = request(h, http.MethodOptions, origin, true)
+		require.Equal(t, http.StatusNoContent, w.Code)
+	}
+	for _, origin := range []string{"https://example.com", "https://evil.com", "http://app.example.com"} {
+		w = request(h, http.MethodGet, origin, false)
+		require.Equal(t, http.StatusOK, w.Code)
+		require.Empty(t, w.Header().Get("Access-Control-Allow-Origin"))
+		w = request(h, http.MethodOptions, origin, true)
+		require.Equal(t, http.StatusForbidden, w.Code)
+	}
+}
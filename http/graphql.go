@@ -0,0 +1,770 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"math"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/drand/drand/chain"
+	"github.com/drand/drand/client"
+	"github.com/gorilla/websocket"
+
+	json "github.com/nikkolasg/hexjson"
+)
+
+const (
+	// gqlMaxRequest bounds the size of a request.
+	gqlMaxRequest = 64 << 10
+	// gqlMaxFields bounds the fields an operation resolves, each alias of a
+	// field counting as one more field.
+	gqlMaxFields = 256
+	// gqlMaxRounds bounds the rounds an operation fetches, over all its
+	// fields.
+	gqlMaxRounds = maxRoundsPage
+	// gqlMaxSubscriptions bounds the subscriptions of a websocket.
+	gqlMaxSubscriptions = 16
+	// gqlInitTimeout is how long a websocket client has to initialize its
+	// connection.
+	gqlInitTimeout = 10 * time.Second
+	// gqlSubprotocol is the websocket subprotocol of the GraphQL
+	// subscriptions, of the graphql-ws library.
+	gqlSubprotocol = "graphql-transport-ws"
+)
+
+// gqlSchema describes the schema served, which the clients may read with the
+// `schema` query parameter. The introspection queries are not supported.
+const gqlSchema = `type Query {
+  "The round of the given number, or the latest round for 0."
+  round(number: Int = 0): Round
+  "The latest round."
+  latest: Round
+  "The rounds from start to end, up to 1000 at a time."
+  rounds(start: Int!, end: Int!): [Round!]!
+  chainInfo: ChainInfo
+}
+
+type Subscription {
+  "The new rounds, starting at the given round if any."
+  rounds(from: Int = 0): Round!
+}
+
+type Round {
+  round: Int!
+  randomness: String!
+  signature: String!
+  previousSignature: String
+  "The unix time of the round."
+  time: Int!
+}
+
+type ChainInfo {
+  hash: String!
+  publicKey: String!
+  "The period in seconds."
+  period: Int!
+  genesisTime: Int!
+  groupHash: String!
+  scheme: String
+  beaconID: String
+}
+`
+
+var gqlUpgrader = websocket.Upgrader{
+	// the relay serves public data to any origin, as the other endpoints do.
+	CheckOrigin:  func(r *http.Request) bool { return true },
+	Subprotocols: []string{gqlSubprotocol},
+}
+
+type gqlRequest struct {
+	Query         string                 `json:"query"`
+	OperationName string                 `json:"operationName,omitempty"`
+	Variables     map[string]interface{} `json:"variables,omitempty"`
+}
+
+type gqlError struct {
+	Message string        `json:"message"`
+	Path    []interface{} `json:"path,omitempty"`
+}
+
+type gqlResponse struct {
+	Data   interface{} `json:"data,omitempty"`
+	Errors []gqlError  `json:"errors,omitempty"`
+}
+
+// gqlObject is a response object, whose fields are kept in the order of the
+// selections as GraphQL requires.
+type gqlObject []gqlEntry
+
+type gqlEntry struct {
+	key   string
+	value interface{}
+}
+
+// MarshalJSON encodes the fields in their order.
+func (o gqlObject) MarshalJSON() ([]byte, error) {
+	var buff bytes.Buffer
+	buff.WriteByte('{')
+	for i, e := range o {
+		if i > 0 {
+			buff.WriteByte(',')
+		}
+		k, err := json.Marshal(e.key)
+		if err != nil {
+			return nil, err
+		}
+		v, err := json.Marshal(e.value)
+		if err != nil {
+			return nil, err
+		}
+		buff.Write(k)
+		buff.WriteByte(':')
+		buff.Write(v)
+	}
+	buff.WriteByte('}')
+	return buff.Bytes(), nil
+}
+
+// merge adds the fields of another object which are not in the object.
+func (o gqlObject) merge(other gqlObject) gqlObject {
+	for _, e := range other {
+		found := false
+		for _, f := range o {
+			if f.key == e.key {
+				found = true
+				break
+			}
+		}
+		if !found {
+			o = append(o, e)
+		}
+	}
+	return o
+}
+
+// gqlRound is a round with the info of its chain.
+type gqlRound struct {
+	client.Result
+	info *chain.Info
+}
+
+// GraphQL serves the rounds and the chain info with GraphQL, so that the
+// clients query the fields they need only. The queries are served over GET
+// and POST requests, and over a websocket with the graphql-transport-ws
+// protocol which also serves the subscription to the new rounds. A GET
+// request with the `schema` parameter returns the schema.
+func (h *handler) GraphQL(w http.ResponseWriter, r *http.Request) {
+	if websocket.IsWebSocketUpgrade(r) {
+		h.graphQLWebSocket(w, r)
+		return
+	}
+	var req gqlRequest
+	switch r.Method {
+	case http.MethodGet:
+		q := r.URL.Query()
+		if _, ok := q["schema"]; ok {
+			w.Header().Set("Server", h.version)
+			w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+			_, _ = w.Write([]byte(gqlSchema))
+			return
+		}
+		req.Query = q.Get("query")
+		req.OperationName = q.Get("operationName")
+		if v := q.Get("variables"); v != "" {
+			if err := json.Unmarshal([]byte(v), &req.Variables); err != nil {
+				http.Error(w, "invalid variables", http.StatusBadRequest)
+				return
+			}
+		}
+	case http.MethodPost:
+		body, err := ioutil.ReadAll(http.MaxBytesReader(w, r.Body, gqlMaxRequest))
+		if err != nil {
+			w.WriteHeader(http.StatusRequestEntityTooLarge)
+			return
+		}
+		if r.Header.Get("Content-Type") == "application/graphql" {
+			req.Query = string(body)
+		} else if err := json.Unmarshal(body, &req); err != nil {
+			http.Error(w, "invalid request", http.StatusBadRequest)
+			return
+		}
+	default:
+		w.Header().Set("Allow", "GET, POST")
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	resp := h.graphQLQuery(r.Context(), &req)
+	w.Header().Set("Server", h.version)
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(resp)
+}
+
+// graphQLQuery answers a query or reports that its operation is a
+// subscription, which is only served over a websocket.
+func (h *handler) graphQLQuery(ctx context.Context, req *gqlRequest) *gqlResponse {
+	e, op, err := h.gqlPrepare(ctx, req)
+	if err != nil {
+		return &gqlResponse{Errors: []gqlError{{Message: err.Error()}}}
+	}
+	if op.kind == "subscription" {
+		return &gqlResponse{Errors: []gqlError{{Message: "the subscriptions are served over a websocket with the " + gqlSubprotocol + " protocol"}}}
+	}
+	data := e.selectObject("Query", nil, op.selections, nil)
+	return &gqlResponse{Data: data, Errors: e.errors}
+}
+
+// gqlExecution executes an operation.
+type gqlExecution struct {
+	h      *handler
+	ctx    context.Context
+	doc    *gqlDocument
+	vars   map[string]interface{}
+	errors []gqlError
+	// fields and fetched count the fields resolved and the rounds fetched so
+	// far, against gqlMaxFields and gqlMaxRounds.
+	fields  int
+	fetched uint64
+}
+
+// gqlPrepare parses the request and selects its operation, with its
+// variables.
+func (h *handler) gqlPrepare(ctx context.Context, req *gqlRequest) (*gqlExecution, *gqlOperation, error) {
+	if req.Query == "" {
+		return nil, nil, errors.New("no query")
+	}
+	doc, err := gqlParse(req.Query)
+	if err != nil {
+		return nil, nil, err
+	}
+	var op *gqlOperation
+	for _, o := range doc.operations {
+		if req.OperationName == "" || o.name == req.OperationName {
+			if op != nil {
+				return nil, nil, errors.New("the operation to execute must be named")
+			}
+			op = o
+		}
+	}
+	if op == nil {
+		return nil, nil, fmt.Errorf("unknown operation %q", req.OperationName)
+	}
+	if op.kind == "mutation" {
+		return nil, nil, errors.New("the mutations are not supported")
+	}
+
+	vars := make(map[string]interface{})
+	for _, def := range op.variables {
+		if v, ok := req.Variables[def.name]; ok && v != nil {
+			vars[def.name] = v
+		} else if def.defaultValue != nil {
+			vars[def.name] = def.defaultValue
+		} else if def.required {
+			return nil, nil, fmt.Errorf("variable $%s is required", def.name)
+		}
+	}
+	return &gqlExecution{h: h, ctx: ctx, doc: doc, vars: vars}, op, nil
+}
+
+func (e *gqlExecution) fail(path []interface{}, err error) {
+	e.errors = append(e.errors, gqlError{Message: err.Error(), Path: append([]interface{}{}, path...)})
+}
+
+// collect returns the fields selected on an object of the type, expanding the
+// fragments and applying the @skip and @include directives.
+func (e *gqlExecution) collect(typeName string, selections []*gqlSelection, visited map[string]bool) []*gqlSelection {
+	var fields []*gqlSelection
+	for _, s := range selections {
+		if !e.included(s) {
+			continue
+		}
+		switch {
+		case s.fragment != "":
+			f, ok := e.doc.fragments[s.fragment]
+			if !ok || visited[s.fragment] || f.typeCondition != typeName {
+				continue
+			}
+			visited[s.fragment] = true
+			fields = append(fields, e.collect(typeName, f.selections, visited)...)
+		case s.name == "":
+			if s.typeCondition != "" && s.typeCondition != typeName {
+				continue
+			}
+			fields = append(fields, e.collect(typeName, s.selections, visited)...)
+		default:
+			fields = append(fields, s)
+		}
+	}
+	return fields
+}
+
+func (e *gqlExecution) included(s *gqlSelection) bool {
+	for _, d := range s.directives {
+		cond, _ := e.resolve(d.args["if"]).(bool)
+		if (d.name == "skip" && cond) || (d.name == "include" && !cond) {
+			return false
+		}
+	}
+	return true
+}
+
+// resolve replaces the variables of a value by their values.
+func (e *gqlExecution) resolve(v interface{}) interface{} {
+	if name, ok := v.(gqlVariable); ok {
+		return e.vars[string(name)]
+	}
+	return v
+}
+
+// intArg returns the non-negative integer argument of a field, or the default
+// value when it is absent.
+func (e *gqlExecution) intArg(f *gqlSelection, name string, def uint64, required bool) (uint64, error) {
+	switch v := e.resolve(f.args[name]).(type) {
+	case nil:
+		if required {
+			return 0, fmt.Errorf("argument %q of field %q is required", name, f.name)
+		}
+		return def, nil
+	case int64:
+		if v >= 0 {
+			return uint64(v), nil
+		}
+	case float64:
+		if v >= 0 && v == math.Trunc(v) && v < math.MaxInt64 {
+			return uint64(v), nil
+		}
+	}
+	return 0, fmt.Errorf("argument %q of field %q must be a non-negative Int", name, f.name)
+}
+
+// selectObject returns the fields selected on an object.
+func (e *gqlExecution) selectObject(typeName string, parent interface{}, selections []*gqlSelection, path []interface{}) gqlObject {
+	fields := e.collect(typeName, selections, make(map[string]bool))
+	object := make(gqlObject, 0, len(fields))
+	index := make(map[string]int)
+	for _, f := range fields {
+		key := f.responseKey()
+		if i, ok := index[key]; ok {
+			// the fields of the same key are merged, which only matters for
+			// the objects
+			o, ok := object[i].value.(gqlObject)
+			if !ok || len(f.selections) == 0 {
+				continue
+			}
+			if more, ok := e.complete(e.field(typeName, parent, f, path), f, append(path, key)).(gqlObject); ok {
+				object[i].value = o.merge(more)
+			}
+			continue
+		}
+		var value interface{}
+		if f.name == "__typename" {
+			value = typeName
+		} else {
+			value = e.complete(e.field(typeName, parent, f, path), f, append(path, key))
+		}
+		index[key] = len(object)
+		object = append(object, gqlEntry{key: key, value: value})
+	}
+	return object
+}
+
+// field resolves a field, recording its error if any.
+func (e *gqlExecution) field(typeName string, parent interface{}, f *gqlSelection, path []interface{}) interface{} {
+	e.fields++
+	if e.fields > gqlMaxFields {
+		e.fail(append(path, f.responseKey()), fmt.Errorf("more than %d fields requested", gqlMaxFields))
+		return nil
+	}
+	v, err := e.resolveField(typeName, parent, f)
+	if err != nil {
+		e.fail(append(path, f.responseKey()), err)
+		return nil
+	}
+	return v
+}
+
+// complete returns the response of a resolved value, with the fields selected
+// on the objects.
+func (e *gqlExecution) complete(value interface{}, f *gqlSelection, path []interface{}) interface{} {
+	var typeName string
+	switch value.(type) {
+	case nil:
+		return nil
+	case *gqlRound, []*gqlRound:
+		typeName = "Round"
+	case *chain.Info:
+		typeName = "ChainInfo"
+	default:
+		if len(f.selections) > 0 {
+			e.fail(path, fmt.Errorf("field %q is a scalar and has no selection", f.name))
+			return nil
+		}
+		return value
+	}
+	if len(f.selections) == 0 {
+		e.fail(path, fmt.Errorf("field %q of type %s must have a selection", f.name, typeName))
+		return nil
+	}
+	if list, ok := value.([]*gqlRound); ok {
+		items := make([]interface{}, len(list))
+		// the items select the same fields, which count once
+		var counted int
+		for i, item := range list {
+			items[i] = e.selectObject(typeName, item, f.selections, append(path, i))
+			if i == 0 {
+				counted = e.fields
+			}
+			e.fields = counted
+		}
+		return items
+	}
+	return e.selectObject(typeName, value, f.selections, path)
+}
+
+func (e *gqlExecution) resolveField(typeName string, parent interface{}, f *gqlSelection) (interface{}, error) {
+	switch typeName {
+	case "Query":
+		switch f.name {
+		case "round":
+			number, err := e.intArg(f, "number", 0, false)
+			if err != nil {
+				return nil, err
+			}
+			return e.round(number)
+		case "latest":
+			return e.round(0)
+		case "rounds":
+			return e.rounds(f)
+		case "chainInfo":
+			return e.chainInfo()
+		}
+	case "Round":
+		r := parent.(*gqlRound)
+		switch f.name {
+		case "round":
+			return r.Round(), nil
+		case "randomness":
+			random, err := r.info.Randomness(r.Signature())
+			if err != nil {
+				return nil, err
+			}
+			return hex.EncodeToString(random), nil
+		case "signature":
+			return hex.EncodeToString(r.Signature()), nil
+		case "previousSignature":
+			var prev []byte
+			switch rd := r.Result.(type) {
+			case *client.RandomData:
+				prev = rd.PreviousSignature
+			case interface{ PreviousSignature() []byte }:
+				prev = rd.PreviousSignature()
+			}
+			if len(prev) == 0 {
+				return nil, nil
+			}
+			return hex.EncodeToString(prev), nil
+		case "time":
+			return r.info.TimeOfRound(r.Round()).Unix(), nil
+		}
+	case "ChainInfo":
+		info := parent.(*chain.Info)
+		switch f.name {
+		case "hash":
+			return hex.EncodeToString(info.Hash()), nil
+		case "publicKey":
+			key, err := info.PublicKey.MarshalBinary()
+			if err != nil {
+				return nil, err
+			}
+			return hex.EncodeToString(key), nil
+		case "period":
+			return int64(info.Period.Seconds()), nil
+		case "genesisTime":
+			return info.GenesisTime, nil
+		case "groupHash":
+			return hex.EncodeToString(info.GroupHash), nil
+		case "scheme":
+			return nilIfEmpty(info.Scheme), nil
+		case "beaconID":
+			return nilIfEmpty(info.BeaconID), nil
+		}
+	}
+	return nil, fmt.Errorf("cannot query field %q on type %s", f.name, typeName)
+}
+
+func nilIfEmpty(s string) interface{} {
+	if s == "" {
+		return nil
+	}
+	return s
+}
+
+func (e *gqlExecution) chainInfo() (*chain.Info, error) {
+	info := e.h.getChainInfo(e.ctx)
+	if info == nil {
+		return nil, errors.New("chain info not available")
+	}
+	return info, nil
+}
+
+// fetch counts the rounds about to be fetched, and returns an error if the
+// operation would fetch more than gqlMaxRounds rounds.
+func (e *gqlExecution) fetch(rounds uint64) error {
+	if e.fetched+rounds > gqlMaxRounds {
+		return fmt.Errorf("more than %d rounds requested by the operation", gqlMaxRounds)
+	}
+	e.fetched += rounds
+	return nil
+}
+
+func (e *gqlExecution) round(number uint64) (interface{}, error) {
+	info, err := e.chainInfo()
+	if err != nil {
+		return nil, err
+	}
+	if number > info.RoundAt(time.Now()) {
+		return nil, fmt.Errorf("round %d is not produced yet", number)
+	}
+	if err := e.fetch(1); err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithTimeout(e.ctx, e.h.timeout)
+	defer cancel()
+	r, err := e.h.get(ctx, number)
+	if err != nil {
+		e.h.log.Warn("http_server", "graphql: failed to get randomness", "round", number, "err", err)
+		return nil, errors.New("failed to get randomness")
+	}
+	return &gqlRound{Result: r, info: info}, nil
+}
+
+func (e *gqlExecution) rounds(f *gqlSelection) (interface{}, error) {
+	start, err := e.intArg(f, "start", 0, true)
+	if err != nil {
+		return nil, err
+	}
+	end, err := e.intArg(f, "end", 0, true)
+	if err != nil {
+		return nil, err
+	}
+	info, err := e.chainInfo()
+	if err != nil {
+		return nil, err
+	}
+	if start == 0 || end < start {
+		return nil, fmt.Errorf("invalid range of rounds %d to %d", start, end)
+	}
+	if end-start >= maxRoundsPage {
+		return nil, fmt.Errorf("more than %d rounds requested", maxRoundsPage)
+	}
+	if end > info.RoundAt(time.Now()) {
+		return nil, fmt.Errorf("round %d is not produced yet", end)
+	}
+	if err := e.fetch(end - start + 1); err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithTimeout(e.ctx, e.h.timeout)
+	defer cancel()
+	results, err := e.h.getRange(ctx, start, end)
+	if err != nil {
+		e.h.log.Warn("http_server", "graphql: failed to get rounds", "start", start, "end", end, "err", err)
+		return nil, errors.New("failed to get randomness")
+	}
+	rounds := make([]*gqlRound, len(results))
+	for i, r := range results {
+		rounds[i] = &gqlRound{Result: r, info: info}
+	}
+	return rounds, nil
+}
+
+// gqlMessage is a message of the graphql-transport-ws protocol.
+type gqlMessage struct {
+	ID      string          `json:"id,omitempty"`
+	Type    string          `json:"type"`
+	Payload json.RawMessage `json:"payload,omitempty"`
+}
+
+// graphQLWebSocket serves the queries and the subscriptions sent over a
+// websocket with the graphql-transport-ws protocol.
+func (h *handler) graphQLWebSocket(w http.ResponseWriter, r *http.Request) {
+	conn, err := gqlUpgrader.Upgrade(w, r, http.Header{"Server": []string{h.version}})
+	if err != nil {
+		h.log.Warn("http_server", "failed to upgrade graphql websocket", "client", r.RemoteAddr, "err", err)
+		return
+	}
+	defer conn.Close()
+
+	ctx, cancel := context.WithCancel(r.Context())
+	defer cancel()
+	var writeLk sync.Mutex
+	send := func(m *gqlMessage) error {
+		b, err := json.Marshal(m)
+		if err != nil {
+			return err
+		}
+		writeLk.Lock()
+		defer writeLk.Unlock()
+		_ = conn.SetWriteDeadline(time.Now().Add(wsWriteTimeout))
+		return conn.WriteMessage(websocket.TextMessage, b)
+	}
+	closeWith := func(code int, reason string) {
+		writeLk.Lock()
+		defer writeLk.Unlock()
+		_ = conn.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(code, reason), time.Now().Add(wsWriteTimeout))
+	}
+
+	var subsLk sync.Mutex
+	subs := make(map[string]context.CancelFunc)
+	defer func() {
+		subsLk.Lock()
+		defer subsLk.Unlock()
+		for _, cancel := range subs {
+			cancel()
+		}
+	}()
+
+	conn.SetReadLimit(gqlMaxRequest)
+	_ = conn.SetReadDeadline(time.Now().Add(gqlInitTimeout))
+	initialized := false
+	for {
+		_, b, err := conn.ReadMessage()
+		if err != nil {
+			if !initialized {
+				closeWith(4408, "Connection initialisation timeout")
+			}
+			h.log.Debug("http_server", "graphql websocket ended", "client", r.RemoteAddr, "err", err)
+			return
+		}
+		var m gqlMessage
+		if err := json.Unmarshal(b, &m); err != nil {
+			closeWith(4400, "Invalid message")
+			return
+		}
+		switch m.Type {
+		case "connection_init":
+			if initialized {
+				closeWith(4429, "Too many initialisation requests")
+				return
+			}
+			initialized = true
+			_ = conn.SetReadDeadline(time.Time{})
+			_ = send(&gqlMessage{Type: "connection_ack"})
+		case "ping":
+			_ = send(&gqlMessage{Type: "pong"})
+		case "pong":
+		case "subscribe":
+			if !initialized {
+				closeWith(4401, "Unauthorized")
+				return
+			}
+			var req gqlRequest
+			if m.ID == "" || json.Unmarshal(m.Payload, &req) != nil {
+				closeWith(4400, "Invalid message")
+				return
+			}
+			subsLk.Lock()
+			_, exists := subs[m.ID]
+			full := len(subs) >= gqlMaxSubscriptions
+			var sctx context.Context
+			if !exists && !full {
+				var scancel context.CancelFunc
+				sctx, scancel = context.WithCancel(ctx)
+				subs[m.ID] = scancel
+			}
+			subsLk.Unlock()
+			if exists {
+				closeWith(4409, "Subscriber for "+m.ID+" already exists")
+				return
+			}
+			if full {
+				errs, _ := json.Marshal([]gqlError{{Message: fmt.Sprintf("more than %d subscriptions", gqlMaxSubscriptions)}})
+				_ = send(&gqlMessage{ID: m.ID, Type: "error", Payload: errs})
+				continue
+			}
+			go func(id string) {
+				h.graphQLSubscribe(sctx, id, &req, send)
+				subsLk.Lock()
+				if cancel, ok := subs[id]; ok {
+					cancel()
+					delete(subs, id)
+				}
+				subsLk.Unlock()
+			}(m.ID)
+		case "complete":
+			subsLk.Lock()
+			if cancel, ok := subs[m.ID]; ok {
+				cancel()
+				delete(subs, m.ID)
+			}
+			subsLk.Unlock()
+		default:
+			closeWith(4400, "Invalid message")
+			return
+		}
+	}
+}
+
+// graphQLSubscribe executes the operation of a subscribe message, sending
+// the result of a query, or the rounds of a subscription, until the context is
+// done.
+func (h *handler) graphQLSubscribe(ctx context.Context, id string, req *gqlRequest, send func(*gqlMessage) error) {
+	next := func(resp *gqlResponse) error {
+		b, err := json.Marshal(resp)
+		if err != nil {
+			return err
+		}
+		return send(&gqlMessage{ID: id, Type: "next", Payload: b})
+	}
+	fail := func(err error) {
+		errs, _ := json.Marshal([]gqlError{{Message: err.Error()}})
+		_ = send(&gqlMessage{ID: id, Type: "error", Payload: errs})
+	}
+
+	e, op, err := h.gqlPrepare(ctx, req)
+	if err != nil {
+		fail(err)
+		return
+	}
+	if op.kind != "subscription" {
+		data := e.selectObject("Query", nil, op.selections, nil)
+		if next(&gqlResponse{Data: data, Errors: e.errors}) == nil {
+			_ = send(&gqlMessage{ID: id, Type: "complete"})
+		}
+		return
+	}
+
+	fields := e.collect("Subscription", op.selections, make(map[string]bool))
+	if len(fields) != 1 || fields[0].name != "rounds" {
+		fail(errors.New("a subscription must select the rounds field only"))
+		return
+	}
+	f := fields[0]
+	from, err := e.intArg(f, "from", 0, false)
+	if err != nil {
+		fail(err)
+		return
+	}
+	info, err := e.chainInfo()
+	if err != nil {
+		fail(err)
+		return
+	}
+
+	defer h.streaming("graphql")()
+	err = h.streamRounds(ctx, from, func(res client.Result) error {
+		re := &gqlExecution{h: h, ctx: ctx, doc: e.doc, vars: e.vars}
+		key := f.responseKey()
+		data := gqlObject{{key: key, value: re.complete(&gqlRound{Result: res, info: info}, f, []interface{}{key})}}
+		return next(&gqlResponse{Data: data, Errors: re.errors})
+	})
+	h.log.Debug("http_server", "graphql subscription ended", "id", id, "err", err)
+	if ctx.Err() == nil {
+		fail(err)
+	}
+}
@@ -0,0 +1,490 @@
+package http
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// This file parses the GraphQL documents, with their operations, variables,
+// fragments, aliases and @skip / @include directives. The schema served is
+// small and read-only, so the parser only covers the executable documents,
+// instead of pulling a GraphQL library along with its schema language,
+// introspection and validation into every relay.
+
+type gqlTokenKind int
+
+const (
+	gqlEOF gqlTokenKind = iota
+	gqlPunct
+	gqlName
+	gqlInt
+	gqlFloat
+	gqlString
+)
+
+type gqlToken struct {
+	kind  gqlTokenKind
+	value string
+	pos   int
+}
+
+// gqlLex splits a document into its tokens, skipping the whitespace, the
+// commas and the comments.
+func gqlLex(src string) ([]gqlToken, error) {
+	var tokens []gqlToken
+	i := 0
+	for i < len(src) {
+		c := src[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == ',':
+			i++
+		case c == '#':
+			for i < len(src) && src[i] != '\n' && src[i] != '\r' {
+				i++
+			}
+		case strings.HasPrefix(src[i:], "..."):
+			tokens = append(tokens, gqlToken{kind: gqlPunct, value: "...", pos: i})
+			i += 3
+		case strings.ContainsRune("!$()[]{}:=@|&", rune(c)):
+			tokens = append(tokens, gqlToken{kind: gqlPunct, value: string(c), pos: i})
+			i++
+		case c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z'):
+			start := i
+			for i < len(src) && (src[i] == '_' || (src[i] >= 'a' && src[i] <= 'z') ||
+				(src[i] >= 'A' && src[i] <= 'Z') || (src[i] >= '0' && src[i] <= '9')) {
+				i++
+			}
+			tokens = append(tokens, gqlToken{kind: gqlName, value: src[start:i], pos: start})
+		case c == '-' || (c >= '0' && c <= '9'):
+			start := i
+			kind := gqlInt
+			i++
+			for i < len(src) {
+				d := src[i]
+				if d >= '0' && d <= '9' {
+					i++
+				} else if d == '.' || d == 'e' || d == 'E' || ((d == '+' || d == '-') && kind == gqlFloat) {
+					kind = gqlFloat
+					i++
+				} else {
+					break
+				}
+			}
+			tokens = append(tokens, gqlToken{kind: kind, value: src[start:i], pos: start})
+		case c == '"':
+			start := i
+			if strings.HasPrefix(src[i:], `"""`) {
+				end := strings.Index(src[i+3:], `"""`)
+				if end < 0 {
+					return nil, fmt.Errorf("unterminated string at %d", start)
+				}
+				tokens = append(tokens, gqlToken{kind: gqlString, value: src[i+3 : i+3+end], pos: start})
+				i += end + 6
+				continue
+			}
+			i++
+			for i < len(src) && src[i] != '"' {
+				if src[i] == '\\' {
+					i++
+				}
+				if i < len(src) && (src[i] == '\n' || src[i] == '\r') {
+					return nil, fmt.Errorf("unterminated string at %d", start)
+				}
+				i++
+			}
+			if i >= len(src) {
+				return nil, fmt.Errorf("unterminated string at %d", start)
+			}
+			value, err := strconv.Unquote(src[start : i+1])
+			if err != nil {
+				return nil, fmt.Errorf("invalid string at %d", start)
+			}
+			tokens = append(tokens, gqlToken{kind: gqlString, value: value, pos: start})
+			i++
+		default:
+			return nil, fmt.Errorf("unexpected character %q at %d", c, i)
+		}
+	}
+	return append(tokens, gqlToken{kind: gqlEOF, pos: len(src)}), nil
+}
+
+// gqlVariable is a reference to a variable, in the arguments of a field.
+type gqlVariable string
+
+// gqlDirective is a @skip or an @include directive, the other ones being
+// ignored.
+type gqlDirective struct {
+	name string
+	args map[string]interface{}
+}
+
+// gqlSelection is a field, a fragment spread or an inline fragment.
+type gqlSelection struct {
+	// field
+	alias string
+	name  string
+	args  map[string]interface{}
+	// fragment spread
+	fragment string
+	// inline fragment
+	typeCondition string
+	// the selections of the field or of the inline fragment
+	selections []*gqlSelection
+	directives []gqlDirective
+}
+
+func (s *gqlSelection) responseKey() string {
+	if s.alias != "" {
+		return s.alias
+	}
+	return s.name
+}
+
+type gqlVariableDefinition struct {
+	name         string
+	defaultValue interface{}
+	required     bool
+}
+
+type gqlOperation struct {
+	kind       string
+	name       string
+	variables  []gqlVariableDefinition
+	selections []*gqlSelection
+}
+
+type gqlFragment struct {
+	typeCondition string
+	selections    []*gqlSelection
+}
+
+type gqlDocument struct {
+	operations []*gqlOperation
+	fragments  map[string]*gqlFragment
+}
+
+type gqlParser struct {
+	tokens []gqlToken
+	i      int
+}
+
+func (p *gqlParser) peek() gqlToken {
+	return p.tokens[p.i]
+}
+
+func (p *gqlParser) next() gqlToken {
+	t := p.tokens[p.i]
+	if t.kind != gqlEOF {
+		p.i++
+	}
+	return t
+}
+
+func (p *gqlParser) errorf(format string, args ...interface{}) error {
+	return fmt.Errorf("syntax error at %d: %s", p.peek().pos, fmt.Sprintf(format, args...))
+}
+
+// punct consumes the punctuator if it is the next token.
+func (p *gqlParser) punct(value string) bool {
+	if t := p.peek(); t.kind == gqlPunct && t.value == value {
+		p.i++
+		return true
+	}
+	return false
+}
+
+func (p *gqlParser) expect(value string) error {
+	if !p.punct(value) {
+		return p.errorf("expected %q", value)
+	}
+	return nil
+}
+
+func (p *gqlParser) name() (string, error) {
+	t := p.peek()
+	if t.kind != gqlName {
+		return "", p.errorf("expected a name")
+	}
+	p.i++
+	return t.value, nil
+}
+
+// gqlParse parses a document.
+func gqlParse(src string) (*gqlDocument, error) {
+	tokens, err := gqlLex(src)
+	if err != nil {
+		return nil, fmt.Errorf("syntax error: %w", err)
+	}
+	p := &gqlParser{tokens: tokens}
+	doc := &gqlDocument{fragments: make(map[string]*gqlFragment)}
+	for p.peek().kind != gqlEOF {
+		t := p.peek()
+		switch {
+		case t.kind == gqlPunct && t.value == "{":
+			selections, err := p.selectionSet()
+			if err != nil {
+				return nil, err
+			}
+			doc.operations = append(doc.operations, &gqlOperation{kind: "query", selections: selections})
+		case t.kind == gqlName && (t.value == "query" || t.value == "subscription" || t.value == "mutation"):
+			op, err := p.operation()
+			if err != nil {
+				return nil, err
+			}
+			doc.operations = append(doc.operations, op)
+		case t.kind == gqlName && t.value == "fragment":
+			p.next()
+			name, err := p.name()
+			if err != nil {
+				return nil, err
+			}
+			if on, err := p.name(); err != nil || on != "on" {
+				return nil, p.errorf(`expected "on"`)
+			}
+			typeCondition, err := p.name()
+			if err != nil {
+				return nil, err
+			}
+			if _, err := p.directives(); err != nil {
+				return nil, err
+			}
+			selections, err := p.selectionSet()
+			if err != nil {
+				return nil, err
+			}
+			doc.fragments[name] = &gqlFragment{typeCondition: typeCondition, selections: selections}
+		default:
+			return nil, p.errorf("expected an operation or a fragment")
+		}
+	}
+	if len(doc.operations) == 0 {
+		return nil, fmt.Errorf("the document holds no operation")
+	}
+	return doc, nil
+}
+
+func (p *gqlParser) operation() (*gqlOperation, error) {
+	op := &gqlOperation{kind: p.next().value}
+	if p.peek().kind == gqlName {
+		op.name = p.next().value
+	}
+	if p.punct("(") {
+		for !p.punct(")") {
+			if err := p.expect("$"); err != nil {
+				return nil, err
+			}
+			name, err := p.name()
+			if err != nil {
+				return nil, err
+			}
+			if err := p.expect(":"); err != nil {
+				return nil, err
+			}
+			required, err := p.typeRef()
+			if err != nil {
+				return nil, err
+			}
+			def := gqlVariableDefinition{name: name, required: required}
+			if p.punct("=") {
+				if def.defaultValue, err = p.value(true); err != nil {
+					return nil, err
+				}
+			}
+			op.variables = append(op.variables, def)
+		}
+	}
+	if _, err := p.directives(); err != nil {
+		return nil, err
+	}
+	var err error
+	op.selections, err = p.selectionSet()
+	return op, err
+}
+
+// typeRef parses the type of a variable, telling whether it is non null.
+func (p *gqlParser) typeRef() (bool, error) {
+	if p.punct("[") {
+		if _, err := p.typeRef(); err != nil {
+			return false, err
+		}
+		if err := p.expect("]"); err != nil {
+			return false, err
+		}
+	} else if _, err := p.name(); err != nil {
+		return false, err
+	}
+	return p.punct("!"), nil
+}
+
+func (p *gqlParser) selectionSet() ([]*gqlSelection, error) {
+	if err := p.expect("{"); err != nil {
+		return nil, err
+	}
+	var selections []*gqlSelection
+	for !p.punct("}") {
+		if p.peek().kind == gqlEOF {
+			return nil, p.errorf(`expected "}"`)
+		}
+		s, err := p.selection()
+		if err != nil {
+			return nil, err
+		}
+		selections = append(selections, s)
+	}
+	if len(selections) == 0 {
+		return nil, p.errorf("empty selection set")
+	}
+	return selections, nil
+}
+
+func (p *gqlParser) selection() (*gqlSelection, error) {
+	var err error
+	s := &gqlSelection{}
+	if p.punct("...") {
+		if t := p.peek(); t.kind == gqlName && t.value != "on" {
+			s.fragment = p.next().value
+			s.directives, err = p.directives()
+			return s, err
+		}
+		if t := p.peek(); t.kind == gqlName && t.value == "on" {
+			p.next()
+			if s.typeCondition, err = p.name(); err != nil {
+				return nil, err
+			}
+		}
+		if s.directives, err = p.directives(); err != nil {
+			return nil, err
+		}
+		s.selections, err = p.selectionSet()
+		return s, err
+	}
+
+	if s.name, err = p.name(); err != nil {
+		return nil, err
+	}
+	if p.punct(":") {
+		s.alias = s.name
+		if s.name, err = p.name(); err != nil {
+			return nil, err
+		}
+	}
+	if p.punct("(") {
+		if s.args, err = p.arguments(); err != nil {
+			return nil, err
+		}
+	}
+	if s.directives, err = p.directives(); err != nil {
+		return nil, err
+	}
+	if t := p.peek(); t.kind == gqlPunct && t.value == "{" {
+		s.selections, err = p.selectionSet()
+	}
+	return s, err
+}
+
+// arguments parses the arguments after their opening parenthesis.
+func (p *gqlParser) arguments() (map[string]interface{}, error) {
+	args := make(map[string]interface{})
+	for !p.punct(")") {
+		name, err := p.name()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expect(":"); err != nil {
+			return nil, err
+		}
+		if args[name], err = p.value(false); err != nil {
+			return nil, err
+		}
+	}
+	return args, nil
+}
+
+func (p *gqlParser) directives() ([]gqlDirective, error) {
+	var directives []gqlDirective
+	for p.punct("@") {
+		name, err := p.name()
+		if err != nil {
+			return nil, err
+		}
+		d := gqlDirective{name: name}
+		if p.punct("(") {
+			if d.args, err = p.arguments(); err != nil {
+				return nil, err
+			}
+		}
+		directives = append(directives, d)
+	}
+	return directives, nil
+}
+
+// value parses a value, which may not be a variable when constant.
+func (p *gqlParser) value(constant bool) (interface{}, error) {
+	t := p.next()
+	switch t.kind {
+	case gqlInt:
+		v, err := strconv.ParseInt(t.value, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("syntax error at %d: invalid integer", t.pos)
+		}
+		return v, nil
+	case gqlFloat:
+		v, err := strconv.ParseFloat(t.value, 64)
+		if err != nil {
+			return nil, fmt.Errorf("syntax error at %d: invalid float", t.pos)
+		}
+		return v, nil
+	case gqlString:
+		return t.value, nil
+	case gqlName:
+		switch t.value {
+		case "true":
+			return true, nil
+		case "false":
+			return false, nil
+		case "null":
+			return nil, nil
+		}
+		// an enum value
+		return t.value, nil
+	case gqlPunct:
+		switch t.value {
+		case "$":
+			if constant {
+				return nil, fmt.Errorf("syntax error at %d: unexpected variable", t.pos)
+			}
+			name, err := p.name()
+			return gqlVariable(name), err
+		case "[":
+			list := []interface{}{}
+			for !p.punct("]") {
+				if p.peek().kind == gqlEOF {
+					return nil, p.errorf(`expected "]"`)
+				}
+				v, err := p.value(constant)
+				if err != nil {
+					return nil, err
+				}
+				list = append(list, v)
+			}
+			return list, nil
+		case "{":
+			object := make(map[string]interface{})
+			for !p.punct("}") {
+				name, err := p.name()
+				if err != nil {
+					return nil, err
+				}
+				if err := p.expect(":"); err != nil {
+					return nil, err
+				}
+				if object[name], err = p.value(constant); err != nil {
+					return nil, err
+				}
+			}
+			return object, nil
+		}
+	}
+	return nil, fmt.Errorf("syntax error at %d: expected a value", t.pos)
+}
@@ -0,0 +1,126 @@
+package http
+
+import (
+	"context"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/drand/drand/chain"
+	"github.com/drand/drand/client"
+	"github.com/drand/drand/key"
+	"github.com/stretchr/testify/require"
+
+	json "github.com/nikkolasg/hexjson"
+)
+
+type gqlTestResponse struct {
+	Data   map[string]json.RawMessage `json:"data"`
+	Errors []gqlError                 `json:"errors"`
+}
+
+func TestGraphQL(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	info := &chain.Info{
+		PublicKey:   key.KeyGroup.Point().Base(),
+		Period:      time.Second,
+		GenesisTime: time.Now().Unix() - 100,
+	}
+	c := &historyClient{client.EmptyClientWithInfo(info)}
+	handler, err := New(ctx, c, "", nil, WithGraphQL())
+	require.NoError(t, err)
+
+	query := func(body string) gqlTestResponse {
+		r := httptest.NewRequest(http.MethodPost, "/graphql", strings.NewReader(body))
+		r.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, r)
+		require.Equal(t, http.StatusOK, w.Code)
+		var resp gqlTestResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		return resp
+	}
+
+	resp := query(`{
+		"query": "query Get($n: Int!) { first: round(number: $n) { ...R } info: chainInfo { hash period } } fragment R on Round { round __typename }",
+		"variables": {"n": 10}
+	}`)
+	require.Empty(t, resp.Errors)
+	var first struct {
+		Round    uint64
+		Typename string `json:"__typename"`
+	}
+	require.NoError(t, json.Unmarshal(resp.Data["first"], &first))
+	require.Equal(t, uint64(10), first.Round)
+	require.Equal(t, "Round", first.Typename)
+	var gotInfo struct {
+		Hash   string
+		Period int64
+	}
+	require.NoError(t, json.Unmarshal(resp.Data["info"], &gotInfo))
+	require.Equal(t, hex.EncodeToString(info.Hash()), gotInfo.Hash)
+	require.Equal(t, int64(1), gotInfo.Period)
+
+	// the fields are kept in the order of the selections
+	raw, err := json.Marshal(gqlObject{{key: "b", value: 1}, {key: "a", value: 2}})
+	require.NoError(t, err)
+	require.Equal(t, `{"b":1,"a":2}`, string(raw))
+
+	resp = query(`{"query": "{ rounds(start: 5, end: 7) { round } }"}`)
+	require.Empty(t, resp.Errors)
+	var rounds []struct{ Round uint64 }
+	require.NoError(t, json.Unmarshal(resp.Data["rounds"], &rounds))
+	require.Len(t, rounds, 3)
+	require.Equal(t, uint64(7), rounds[2].Round)
+
+	// the errors are reported with the path of their field
+	resp = query(`{"query": "{ round(number: 100000) { round } latest { nope } }"}`)
+	require.Len(t, resp.Errors, 2)
+	require.Equal(t, []interface{}{"round"}, resp.Errors[0].Path)
+	require.Contains(t, resp.Errors[1].Message, "nope")
+
+	// the aliases count against the limits of an operation
+	var aliases strings.Builder
+	for i := 0; i <= gqlMaxRounds/100; i++ {
+		fmt.Fprintf(&aliases, "r%d: rounds(start: 1, end: 100) { round } ", i)
+	}
+	resp = query(`{"query": "{ ` + aliases.String() + `}"}`)
+	require.Len(t, resp.Errors, 1)
+	require.Contains(t, resp.Errors[0].Message, "rounds requested by the operation")
+	aliases.Reset()
+	for i := 0; i <= gqlMaxFields; i++ {
+		fmt.Fprintf(&aliases, "c%d: chainInfo { period } ", i)
+	}
+	resp = query(`{"query": "{ ` + aliases.String() + `}"}`)
+	require.NotEmpty(t, resp.Errors)
+	require.Contains(t, resp.Errors[0].Message, "fields requested")
+
+	resp = query(`{"query": "query Get($n: Int!) { round(number: $n) { round } }"}`)
+	require.Len(t, resp.Errors, 1)
+	resp = query(`{"query": "subscription { rounds { round } }"}`)
+	require.Len(t, resp.Errors, 1)
+	resp = query(`{"query": "{ round { round "}`)
+	require.Len(t, resp.Errors, 1)
+}
+
+func TestGraphQLDisabled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	info := &chain.Info{
+		PublicKey:   key.KeyGroup.Point().Base(),
+		Period:      time.Second,
+		GenesisTime: time.Now().Unix() - 100,
+	}
+	handler, err := New(ctx, &historyClient{client.EmptyClientWithInfo(info)}, "", nil)
+	require.NoError(t, err)
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/graphql", strings.NewReader(`{"query":"{ latest { round } }"}`)))
+	require.Equal(t, http.StatusNotFound, w.Code)
+}
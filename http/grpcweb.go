@@ -0,0 +1,243 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/drand/drand/chain"
+	"github.com/drand/drand/client"
+	"github.com/drand/drand/protobuf/drand"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/protobuf/proto"
+)
+
+const (
+	grpcWebContentType     = "application/grpc-web"
+	grpcWebTextContentType = "application/grpc-web-text"
+	// grpcWebMaxRequest bounds the size of the requests, whose messages only
+	// hold a round number.
+	grpcWebMaxRequest = 4 << 10
+	// grpcWebTrailerFlag marks the frame holding the trailers of a response.
+	grpcWebTrailerFlag = 0x80
+	// grpcWebCompressedFlag marks a compressed message, which is not
+	// supported.
+	grpcWebCompressedFlag = 0x01
+)
+
+// GRPCWeb serves the drand.Public service with the gRPC-Web protocol, so that
+// the web applications may call the same PublicRand, PublicRandStream and
+// ChainInfo methods as the gRPC clients of the nodes, including the streaming
+// one. Both the binary and the base64 text encodings are supported.
+func (h *handler) GRPCWeb(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	contentType := r.Header.Get("Content-Type")
+	if !strings.HasPrefix(contentType, grpcWebContentType) {
+		w.WriteHeader(http.StatusUnsupportedMediaType)
+		return
+	}
+	text := strings.HasPrefix(contentType, grpcWebTextContentType)
+
+	gw := &grpcWebWriter{w: w, text: text}
+	w.Header().Set("Server", h.version)
+	if text {
+		w.Header().Set("Content-Type", grpcWebTextContentType+"+proto")
+	} else {
+		w.Header().Set("Content-Type", grpcWebContentType+"+proto")
+	}
+	w.Header().Set("Cache-Control", "no-cache")
+
+	var req drand.PublicRandRequest
+	method := strings.TrimPrefix(r.URL.Path, "/drand.Public/")
+	switch method {
+	case "PublicRand", "PublicRandStream":
+		if err := readGRPCWebRequest(r, text, &req); err != nil {
+			gw.status(codes.InvalidArgument, err.Error())
+			return
+		}
+	case "ChainInfo":
+		if err := readGRPCWebRequest(r, text, &drand.ChainInfoRequest{}); err != nil {
+			gw.status(codes.InvalidArgument, err.Error())
+			return
+		}
+	default:
+		gw.status(codes.Unimplemented, fmt.Sprintf("method %s is not served by the relay", method))
+		return
+	}
+
+	switch method {
+	case "PublicRand":
+		h.grpcWebPublicRand(r.Context(), gw, req.GetRound())
+	case "PublicRandStream":
+		h.grpcWebPublicRandStream(r, gw, req.GetRound())
+	case "ChainInfo":
+		info := h.getChainInfo(r.Context())
+		if info == nil {
+			gw.status(codes.Unavailable, "chain info not available")
+			return
+		}
+		if err := gw.message(info.ToProto()); err != nil {
+			return
+		}
+		gw.status(codes.OK, "")
+	}
+}
+
+func (h *handler) grpcWebPublicRand(ctx context.Context, gw *grpcWebWriter, round uint64) {
+	info := h.getChainInfo(ctx)
+	if info == nil {
+		gw.status(codes.Unavailable, "chain info not available")
+		return
+	}
+	if round > info.RoundAt(time.Now()) {
+		gw.status(codes.NotFound, fmt.Sprintf("round %d is not produced yet", round))
+		return
+	}
+	ctx, cancel := context.WithTimeout(ctx, h.timeout)
+	defer cancel()
+	res, err := h.get(ctx, round)
+	if err != nil {
+		h.log.Warn("http_server", "grpc-web: failed to get randomness", "round", round, "err", err)
+		gw.status(codes.Unavailable, "failed to get randomness")
+		return
+	}
+	resp, err := resultToProto(info, res)
+	if err != nil {
+		gw.status(codes.Internal, err.Error())
+		return
+	}
+	if err := gw.message(resp); err != nil {
+		return
+	}
+	gw.status(codes.OK, "")
+}
+
+func (h *handler) grpcWebPublicRandStream(r *http.Request, gw *grpcWebWriter, from uint64) {
+	flusher, ok := gw.w.(http.Flusher)
+	if !ok {
+		gw.status(codes.Internal, "streaming unsupported")
+		return
+	}
+	info := h.getChainInfo(r.Context())
+	if info == nil {
+		gw.status(codes.Unavailable, "chain info not available")
+		return
+	}
+	// stops the reverse proxies buffering the rounds
+	gw.w.Header().Set("X-Accel-Buffering", "no")
+	gw.w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+	defer h.streaming("grpc-web")()
+
+	err := h.streamRounds(r.Context(), from, func(res client.Result) error {
+		resp, err := resultToProto(info, res)
+		if err != nil {
+			return err
+		}
+		if err := gw.message(resp); err != nil {
+			return err
+		}
+		flusher.Flush()
+		return nil
+	})
+	h.log.Debug("http_server", "grpc-web stream ended", "client", r.RemoteAddr, "err", err)
+	if r.Context().Err() == nil {
+		gw.status(codes.Unavailable, err.Error())
+	}
+}
+
+// resultToProto returns the message of a round of the chain, as sent by the
+// nodes.
+func resultToProto(info *chain.Info, r client.Result) (*drand.PublicRandResponse, error) {
+	random, err := info.Randomness(r.Signature())
+	if err != nil {
+		return nil, err
+	}
+	resp := &drand.PublicRandResponse{
+		Round:      r.Round(),
+		Signature:  r.Signature(),
+		Randomness: random,
+	}
+	if rd, ok := r.(*client.RandomData); ok {
+		resp.PreviousSignature = rd.PreviousSignature
+	}
+	return resp, nil
+}
+
+// readGRPCWebRequest reads the single message of a request. An empty body
+// holds the empty message.
+func readGRPCWebRequest(r *http.Request, text bool, m proto.Message) error {
+	var body io.Reader = io.LimitReader(r.Body, grpcWebMaxRequest)
+	if text {
+		body = base64.NewDecoder(base64.StdEncoding, body)
+	}
+	b, err := ioutil.ReadAll(body)
+	if err != nil {
+		return fmt.Errorf("invalid request: %w", err)
+	}
+	if len(b) == 0 {
+		return nil
+	}
+	if len(b) < 5 {
+		return fmt.Errorf("invalid request frame")
+	}
+	if b[0]&grpcWebCompressedFlag != 0 {
+		return fmt.Errorf("compressed requests are not supported")
+	}
+	n := binary.BigEndian.Uint32(b[1:5])
+	if uint32(len(b)-5) < n {
+		return fmt.Errorf("truncated request frame")
+	}
+	return proto.Unmarshal(b[5:5+n], m)
+}
+
+// grpcWebWriter writes the frames of a gRPC-Web response.
+type grpcWebWriter struct {
+	w    http.ResponseWriter
+	text bool
+}
+
+// frame writes a frame, encoded in base64 on its own for the text encoding so
+// that the clients may decode the frames as they come.
+func (g *grpcWebWriter) frame(flag byte, data []byte) error {
+	var buff bytes.Buffer
+	buff.WriteByte(flag)
+	_ = binary.Write(&buff, binary.BigEndian, uint32(len(data)))
+	buff.Write(data)
+	b := buff.Bytes()
+	if g.text {
+		b = []byte(base64.StdEncoding.EncodeToString(b))
+	}
+	_, err := g.w.Write(b)
+	return err
+}
+
+func (g *grpcWebWriter) message(m proto.Message) error {
+	b, err := proto.Marshal(m)
+	if err != nil {
+		return err
+	}
+	return g.frame(0, b)
+}
+
+// status ends the response with the trailers carrying its gRPC status.
+func (g *grpcWebWriter) status(code codes.Code, msg string) {
+	trailers := fmt.Sprintf("grpc-status:%d\r\n", code)
+	if msg != "" {
+		trailers += fmt.Sprintf("grpc-message:%s\r\n", url.PathEscape(msg))
+	}
+	_ = g.frame(grpcWebTrailerFlag, []byte(trailers))
+}
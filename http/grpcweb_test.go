@@ -0,0 +1,130 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/binary"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/drand/drand/chain"
+	"github.com/drand/drand/client"
+	"github.com/drand/drand/key"
+	"github.com/drand/drand/protobuf/drand"
+	"github.com/stretchr/testify/require"
+
+	"google.golang.org/protobuf/proto"
+)
+
+// grpcWebFrames splits a gRPC-Web response into its messages and its
+// trailers.
+func grpcWebFrames(t *testing.T, body []byte) (messages [][]byte, trailers string) {
+	for len(body) > 0 {
+		require.True(t, len(body) >= 5)
+		n := binary.BigEndian.Uint32(body[1:5])
+		data := body[5 : 5+n]
+		if body[0]&grpcWebTrailerFlag != 0 {
+			trailers = string(data)
+		} else {
+			messages = append(messages, data)
+		}
+		body = body[5+n:]
+	}
+	return messages, trailers
+}
+
+// decodeGRPCWebText decodes a gRPC-Web text body, whose frames are encoded in
+// base64 one by one: the padding of a frame may end a chunk of base64.
+func decodeGRPCWebText(body string) ([]byte, error) {
+	var out []byte
+	for body != "" {
+		n := strings.IndexByte(body, '=')
+		if n < 0 {
+			n = len(body)
+		}
+		for n < len(body) && body[n] == '=' {
+			n++
+		}
+		b, err := base64.StdEncoding.DecodeString(body[:n])
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, b...)
+		body = body[n:]
+	}
+	return out, nil
+}
+
+func TestGRPCWeb(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	info := &chain.Info{
+		PublicKey:   key.KeyGroup.Point().Base(),
+		Period:      time.Second,
+		GenesisTime: time.Now().Unix() - 100,
+	}
+	c := &historyClient{client.EmptyClientWithInfo(info)}
+	handler, err := New(ctx, c, "", nil)
+	require.NoError(t, err)
+
+	call := func(method, contentType string, req proto.Message) *httptest.ResponseRecorder {
+		b, err := proto.Marshal(req)
+		require.NoError(t, err)
+		var frame bytes.Buffer
+		frame.WriteByte(0)
+		require.NoError(t, binary.Write(&frame, binary.BigEndian, uint32(len(b))))
+		frame.Write(b)
+		body := frame.Bytes()
+		if contentType == grpcWebTextContentType {
+			body = []byte(base64.StdEncoding.EncodeToString(body))
+		}
+		r := httptest.NewRequest(http.MethodPost, "/drand.Public/"+method, bytes.NewReader(body))
+		r.Header.Set("Content-Type", contentType)
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, r)
+		return w
+	}
+
+	w := call("PublicRand", grpcWebContentType, &drand.PublicRandRequest{Round: 10})
+	require.Equal(t, http.StatusOK, w.Code)
+	require.Equal(t, grpcWebContentType+"+proto", w.Header().Get("Content-Type"))
+	messages, trailers := grpcWebFrames(t, w.Body.Bytes())
+	require.Len(t, messages, 1)
+	require.Equal(t, "grpc-status:0\r\n", trailers)
+	var resp drand.PublicRandResponse
+	require.NoError(t, proto.Unmarshal(messages[0], &resp))
+	require.Equal(t, uint64(10), resp.GetRound())
+
+	// the text mode encodes the frames in base64
+	w = call("ChainInfo", grpcWebTextContentType, &drand.ChainInfoRequest{})
+	require.Equal(t, http.StatusOK, w.Code)
+	body, err := decodeGRPCWebText(w.Body.String())
+	require.NoError(t, err)
+	messages, trailers = grpcWebFrames(t, body)
+	require.Len(t, messages, 1)
+	require.Equal(t, "grpc-status:0\r\n", trailers)
+	var packet drand.ChainInfoPacket
+	require.NoError(t, proto.Unmarshal(messages[0], &packet))
+	got, err := chain.InfoFromProto(&packet)
+	require.NoError(t, err)
+	require.True(t, got.Equal(info))
+
+	w = call("PublicRand", grpcWebContentType, &drand.PublicRandRequest{Round: 1000})
+	messages, trailers = grpcWebFrames(t, w.Body.Bytes())
+	require.Empty(t, messages)
+	require.Contains(t, trailers, "grpc-status:5\r\n")
+
+	w = call("PrivateRand", grpcWebContentType, &drand.PrivateRandRequest{})
+	_, trailers = grpcWebFrames(t, w.Body.Bytes())
+	require.Contains(t, trailers, "grpc-status:12\r\n")
+
+	r := httptest.NewRequest(http.MethodGet, "/drand.Public/PublicRand", nil)
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, r)
+	require.Equal(t, http.StatusMethodNotAllowed, w.Code)
+}
@@ -0,0 +1,428 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/drand/drand/client"
+	"github.com/gorilla/websocket"
+
+	json "github.com/nikkolasg/hexjson"
+)
+
+const (
+	rpcVersion = "2.0"
+	// rpcMaxRequest bounds the size of a request or of a batch.
+	rpcMaxRequest = 64 << 10
+	// rpcMaxBatch bounds the number of calls of a batch.
+	rpcMaxBatch = 100
+	// rpcMaxSubscriptions bounds the subscriptions of a websocket.
+	rpcMaxSubscriptions = 16
+)
+
+// The error codes of JSON-RPC 2.0, and the ones of the relay.
+const (
+	rpcParseError     = -32700
+	rpcInvalidRequest = -32600
+	rpcMethodNotFound = -32601
+	rpcInvalidParams  = -32602
+	rpcInternalError  = -32603
+	rpcUnavailable    = -32000
+	rpcNotYet         = -32001
+)
+
+type rpcRequest struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id,omitempty"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params,omitempty"`
+}
+
+// notification tells whether the request expects no response.
+func (r *rpcRequest) notification() bool {
+	return r.ID == nil
+}
+
+type rpcResponse struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Result  interface{}     `json:"result,omitempty"`
+	Error   *rpcError       `json:"error,omitempty"`
+}
+
+type rpcError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e *rpcError) Error() string {
+	return e.Message
+}
+
+// rpcNotification is sent to the websocket clients for each round of their
+// subscriptions.
+type rpcNotification struct {
+	JSONRPC string                `json:"jsonrpc"`
+	Method  string                `json:"method"`
+	Params  rpcSubscriptionResult `json:"params"`
+}
+
+type rpcSubscriptionResult struct {
+	Subscription string        `json:"subscription"`
+	Result       client.Result `json:"result"`
+}
+
+// JSONRPC serves the randomness with JSON-RPC 2.0, for the tooling speaking
+// it, over HTTP POST requests and over a websocket. drand_getRound returns the
+// round given, or the latest one for 0, drand_getLatest the latest round, and
+// drand_chainInfo the info of the chain. Over a websocket, drand_subscribe
+// subscribes to the new rounds, resuming at the round given if any, which are
+// sent as drand_subscription notifications until drand_unsubscribe cancels the
+// subscription. The parameters are given by position or by name, and batches
+// of calls are supported.
+func (h *handler) JSONRPC(w http.ResponseWriter, r *http.Request) {
+	if websocket.IsWebSocketUpgrade(r) {
+		h.jsonRPCWebSocket(w, r)
+		return
+	}
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	body, err := ioutil.ReadAll(http.MaxBytesReader(w, r.Body, rpcMaxRequest))
+	if err != nil {
+		w.WriteHeader(http.StatusRequestEntityTooLarge)
+		return
+	}
+	resp := h.handleRPC(r.Context(), body, nil)
+	w.Header().Set("Server", h.version)
+	if resp == nil {
+		// only notifications
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_, _ = w.Write(resp)
+}
+
+// handleRPC answers a call or a batch of calls, returning nil when there is
+// nothing to answer. The subscriptions are only served with a websocket.
+func (h *handler) handleRPC(ctx context.Context, body []byte, subs *rpcSubscriptions) []byte {
+	body = bytes.TrimSpace(body)
+	if len(body) > 0 && body[0] == '[' {
+		var batch []json.RawMessage
+		if err := json.Unmarshal(body, &batch); err != nil {
+			return marshalRPC(rpcErrorResponse(nil, rpcParseError, "parse error"))
+		}
+		if len(batch) == 0 {
+			return marshalRPC(rpcErrorResponse(nil, rpcInvalidRequest, "empty batch"))
+		}
+		if len(batch) > rpcMaxBatch {
+			return marshalRPC(rpcErrorResponse(nil, rpcInvalidRequest, fmt.Sprintf("batch of more than %d calls", rpcMaxBatch)))
+		}
+		responses := make([]*rpcResponse, len(batch))
+		wg := sync.WaitGroup{}
+		for i := range batch {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				responses[i] = h.call(ctx, batch[i], subs)
+			}(i)
+		}
+		wg.Wait()
+		answered := make([]*rpcResponse, 0, len(responses))
+		for _, resp := range responses {
+			if resp != nil {
+				answered = append(answered, resp)
+			}
+		}
+		if len(answered) == 0 {
+			return nil
+		}
+		return marshalRPC(answered)
+	}
+	resp := h.call(ctx, body, subs)
+	if resp == nil {
+		return nil
+	}
+	return marshalRPC(resp)
+}
+
+func marshalRPC(v interface{}) []byte {
+	b, err := json.Marshal(v)
+	if err != nil {
+		b, _ = json.Marshal(rpcErrorResponse(nil, rpcInternalError, err.Error()))
+	}
+	return b
+}
+
+func rpcErrorResponse(id json.RawMessage, code int, msg string) *rpcResponse {
+	return &rpcResponse{JSONRPC: rpcVersion, ID: id, Error: &rpcError{Code: code, Message: msg}}
+}
+
+// call answers a single call, returning nil for a notification.
+func (h *handler) call(ctx context.Context, raw json.RawMessage, subs *rpcSubscriptions) *rpcResponse {
+	var req rpcRequest
+	if err := json.Unmarshal(raw, &req); err != nil {
+		return rpcErrorResponse(nil, rpcParseError, "parse error")
+	}
+	if req.JSONRPC != rpcVersion || req.Method == "" {
+		return rpcErrorResponse(req.ID, rpcInvalidRequest, "invalid request")
+	}
+
+	result, err := h.rpcMethod(ctx, &req, subs)
+	if req.notification() {
+		return nil
+	}
+	if err != nil {
+		if rerr, ok := err.(*rpcError); ok {
+			return &rpcResponse{JSONRPC: rpcVersion, ID: req.ID, Error: rerr}
+		}
+		return rpcErrorResponse(req.ID, rpcInternalError, err.Error())
+	}
+	return &rpcResponse{JSONRPC: rpcVersion, ID: req.ID, Result: result}
+}
+
+func (h *handler) rpcMethod(ctx context.Context, req *rpcRequest, subs *rpcSubscriptions) (interface{}, error) {
+	switch req.Method {
+	case "drand_getRound":
+		round, err := rpcUintParam(req.Params, "round")
+		if err != nil {
+			return nil, err
+		}
+		return h.rpcRound(ctx, round)
+	case "drand_getLatest":
+		return h.rpcRound(ctx, 0)
+	case "drand_chainInfo":
+		info := h.getChainInfo(ctx)
+		if info == nil {
+			return nil, &rpcError{Code: rpcUnavailable, Message: "chain info not available"}
+		}
+		var buff bytes.Buffer
+		if err := info.ToJSON(&buff); err != nil {
+			return nil, err
+		}
+		return json.RawMessage(buff.Bytes()), nil
+	case "drand_subscribe", "drand_unsubscribe":
+		if subs == nil {
+			return nil, &rpcError{Code: rpcMethodNotFound, Message: req.Method + " is only served over a websocket"}
+		}
+		if req.Method == "drand_unsubscribe" {
+			id, err := rpcStringParam(req.Params, "subscription")
+			if err != nil {
+				return nil, err
+			}
+			return subs.cancel(id), nil
+		}
+		from, err := rpcUintParam(req.Params, "from")
+		if err != nil {
+			return nil, err
+		}
+		return subs.subscribe(from)
+	default:
+		return nil, &rpcError{Code: rpcMethodNotFound, Message: "method not found"}
+	}
+}
+
+func (h *handler) rpcRound(ctx context.Context, round uint64) (client.Result, error) {
+	info := h.getChainInfo(ctx)
+	if info == nil {
+		return nil, &rpcError{Code: rpcUnavailable, Message: "chain info not available"}
+	}
+	if round > info.RoundAt(time.Now()) {
+		return nil, &rpcError{Code: rpcNotYet, Message: fmt.Sprintf("round %d is not produced yet", round)}
+	}
+	ctx, cancel := context.WithTimeout(ctx, h.timeout)
+	defer cancel()
+	r, err := h.get(ctx, round)
+	if err != nil {
+		h.log.Warn("http_server", "json-rpc: failed to get randomness", "round", round, "err", err)
+		return nil, &rpcError{Code: rpcUnavailable, Message: "failed to get randomness"}
+	}
+	return r, nil
+}
+
+// rpcParam returns the single parameter of a call, given by position or by
+// name, or nil if there is none.
+func rpcParam(params json.RawMessage, name string) (json.RawMessage, error) {
+	params = bytes.TrimSpace(params)
+	if len(params) == 0 || bytes.Equal(params, []byte("null")) {
+		return nil, nil
+	}
+	switch params[0] {
+	case '[':
+		var byPosition []json.RawMessage
+		if err := json.Unmarshal(params, &byPosition); err != nil || len(byPosition) > 1 {
+			return nil, &rpcError{Code: rpcInvalidParams, Message: "invalid params"}
+		}
+		if len(byPosition) == 0 {
+			return nil, nil
+		}
+		return byPosition[0], nil
+	case '{':
+		var byName map[string]json.RawMessage
+		if err := json.Unmarshal(params, &byName); err != nil {
+			return nil, &rpcError{Code: rpcInvalidParams, Message: "invalid params"}
+		}
+		return byName[name], nil
+	default:
+		return nil, &rpcError{Code: rpcInvalidParams, Message: "invalid params"}
+	}
+}
+
+// rpcUintParam returns the number parameter of a call, given as a number or
+// as a decimal string, 0 when absent.
+func rpcUintParam(params json.RawMessage, name string) (uint64, error) {
+	p, err := rpcParam(params, name)
+	if err != nil || p == nil {
+		return 0, err
+	}
+	var v uint64
+	if err := json.Unmarshal(p, &v); err == nil {
+		return v, nil
+	}
+	var s string
+	if err := json.Unmarshal(p, &s); err == nil {
+		if v, err := strconv.ParseUint(s, 10, 64); err == nil {
+			return v, nil
+		}
+	}
+	return 0, &rpcError{Code: rpcInvalidParams, Message: fmt.Sprintf("invalid %s", name)}
+}
+
+func rpcStringParam(params json.RawMessage, name string) (string, error) {
+	p, err := rpcParam(params, name)
+	if err != nil {
+		return "", err
+	}
+	var s string
+	if p == nil || json.Unmarshal(p, &s) != nil {
+		return "", &rpcError{Code: rpcInvalidParams, Message: fmt.Sprintf("invalid %s", name)}
+	}
+	return s, nil
+}
+
+// jsonRPCWebSocket serves the calls sent over a websocket, and the
+// notifications of their subscriptions.
+func (h *handler) jsonRPCWebSocket(w http.ResponseWriter, r *http.Request) {
+	conn, err := upgrader.Upgrade(w, r, http.Header{"Server": []string{h.version}})
+	if err != nil {
+		h.log.Warn("http_server", "failed to upgrade json-rpc websocket", "client", r.RemoteAddr, "err", err)
+		return
+	}
+	defer conn.Close()
+
+	ctx, cancel := context.WithCancel(r.Context())
+	defer cancel()
+	var writeLk sync.Mutex
+	write := func(b []byte) error {
+		writeLk.Lock()
+		defer writeLk.Unlock()
+		_ = conn.SetWriteDeadline(time.Now().Add(wsWriteTimeout))
+		return conn.WriteMessage(websocket.TextMessage, b)
+	}
+	subs := &rpcSubscriptions{h: h, ctx: ctx, write: write, cancels: make(map[string]context.CancelFunc)}
+	defer subs.close()
+
+	conn.SetReadLimit(rpcMaxRequest)
+	for {
+		_, body, err := conn.ReadMessage()
+		if err != nil {
+			h.log.Debug("http_server", "json-rpc websocket ended", "client", r.RemoteAddr, "err", err)
+			return
+		}
+		if resp := h.handleRPC(ctx, body, subs); resp != nil {
+			if err := write(resp); err != nil {
+				return
+			}
+		}
+		// the subscriptions are only streamed once their id is sent
+		subs.start()
+	}
+}
+
+// rpcSubscriptions are the subscriptions of a websocket.
+type rpcSubscriptions struct {
+	h     *handler
+	ctx   context.Context
+	write func([]byte) error
+
+	lk      sync.Mutex
+	next    uint64
+	cancels map[string]context.CancelFunc
+	// pending are the streams of the subscriptions made by the message being
+	// answered, started once it is answered.
+	pending []func()
+}
+
+// subscribe makes a subscription streaming the rounds from `from`, or the new
+// ones for 0, as notifications, and returns its id. The stream is started by
+// start, after the id is sent.
+func (s *rpcSubscriptions) subscribe(from uint64) (string, error) {
+	s.lk.Lock()
+	defer s.lk.Unlock()
+	if len(s.cancels) >= rpcMaxSubscriptions {
+		return "", &rpcError{Code: rpcInvalidRequest, Message: fmt.Sprintf("more than %d subscriptions", rpcMaxSubscriptions)}
+	}
+	s.next++
+	id := "0x" + strconv.FormatUint(s.next, 16)
+	ctx, cancel := context.WithCancel(s.ctx)
+	s.cancels[id] = cancel
+
+	s.pending = append(s.pending, func() {
+		defer s.cancel(id)
+		defer s.h.streaming("jsonrpc")()
+		err := s.h.streamRounds(ctx, from, func(res client.Result) error {
+			b, err := json.Marshal(&rpcNotification{
+				JSONRPC: rpcVersion,
+				Method:  "drand_subscription",
+				Params:  rpcSubscriptionResult{Subscription: id, Result: res},
+			})
+			if err != nil {
+				return err
+			}
+			return s.write(b)
+		})
+		s.h.log.Debug("http_server", "json-rpc subscription ended", "id", id, "err", err)
+	})
+	return id, nil
+}
+
+// start streams the pending subscriptions.
+func (s *rpcSubscriptions) start() {
+	s.lk.Lock()
+	pending := s.pending
+	s.pending = nil
+	s.lk.Unlock()
+	for _, stream := range pending {
+		go stream()
+	}
+}
+
+// cancel ends a subscription, telling whether it existed.
+func (s *rpcSubscriptions) cancel(id string) bool {
+	s.lk.Lock()
+	defer s.lk.Unlock()
+	cancel, ok := s.cancels[id]
+	if ok {
+		cancel()
+		delete(s.cancels, id)
+	}
+	return ok
+}
+
+func (s *rpcSubscriptions) close() {
+	s.lk.Lock()
+	defer s.lk.Unlock()
+	for id, cancel := range s.cancels {
+		cancel()
+		delete(s.cancels, id)
+	}
+}
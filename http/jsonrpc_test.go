@@ -0,0 +1,185 @@
+package http
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/drand/drand/chain"
+	"github.com/drand/drand/client"
+	"github.com/drand/drand/key"
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/require"
+
+	json "github.com/nikkolasg/hexjson"
+)
+
+type rpcTestResponse struct {
+	ID     json.RawMessage `json:"id"`
+	Result json.RawMessage `json:"result"`
+	Error  *rpcError       `json:"error"`
+}
+
+func TestJSONRPC(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	info := &chain.Info{
+		PublicKey:   key.KeyGroup.Point().Base(),
+		Period:      time.Second,
+		GenesisTime: time.Now().Unix() - 100,
+	}
+	c := &historyClient{client.EmptyClientWithInfo(info)}
+	handler, err := New(ctx, c, "", nil)
+	require.NoError(t, err)
+
+	post := func(body string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(http.MethodPost, "/rpc", strings.NewReader(body))
+		r.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, r)
+		return w
+	}
+
+	w := post(`{"jsonrpc":"2.0","id":1,"method":"drand_getRound","params":[10]}`)
+	require.Equal(t, http.StatusOK, w.Code)
+	var resp rpcTestResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	require.Nil(t, resp.Error)
+	require.Equal(t, "1", string(resp.ID))
+	var round struct{ Rnd uint64 }
+	require.NoError(t, json.Unmarshal(resp.Result, &round))
+	require.Equal(t, uint64(10), round.Rnd)
+
+	// the notifications are not answered in a batch
+	w = post(`[
+		{"jsonrpc":"2.0","id":"a","method":"drand_getRound","params":{"round":"12"}},
+		{"jsonrpc":"2.0","method":"drand_getLatest"},
+		{"jsonrpc":"2.0","id":"b","method":"drand_chainInfo"},
+		{"jsonrpc":"2.0","id":"c","method":"drand_getRound","params":[100000]},
+		{"jsonrpc":"2.0","id":"d","method":"eth_blockNumber"},
+		{"jsonrpc":"2.0","id":"e","method":"drand_subscribe"}
+	]`)
+	require.Equal(t, http.StatusOK, w.Code)
+	var batch []rpcTestResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &batch))
+	require.Len(t, batch, 5)
+	require.NoError(t, json.Unmarshal(batch[0].Result, &round))
+	require.Equal(t, uint64(12), round.Rnd)
+	got, err := chain.InfoFromJSON(strings.NewReader(string(batch[1].Result)))
+	require.NoError(t, err)
+	require.True(t, got.Equal(info))
+	require.Equal(t, rpcNotYet, batch[2].Error.Code)
+	require.Equal(t, rpcMethodNotFound, batch[3].Error.Code)
+	require.Equal(t, rpcMethodNotFound, batch[4].Error.Code)
+
+	w = post(`{"jsonrpc":"2.0","method":"drand_getLatest"}`)
+	require.Equal(t, http.StatusNoContent, w.Code)
+	w = post(`{"jsonrpc":`)
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	require.Equal(t, rpcParseError, resp.Error.Code)
+	w = post(`[]`)
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	require.Equal(t, rpcInvalidRequest, resp.Error.Code)
+}
+
+func TestJSONRPCSubscription(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	info := &chain.Info{
+		PublicKey:   key.KeyGroup.Point().Base(),
+		Period:      time.Second,
+		GenesisTime: time.Now().Unix() - 100,
+	}
+	handler, err := New(ctx, &historyClient{client.EmptyClientWithInfo(info)}, "", nil)
+	require.NoError(t, err)
+	server := httptest.NewServer(handler)
+	defer server.Close()
+
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(server.URL, "http")+"/rpc", nil)
+	require.NoError(t, err)
+	defer conn.Close()
+
+	call := func(req string) rpcTestResponse {
+		require.NoError(t, conn.WriteMessage(websocket.TextMessage, []byte(req)))
+		_, b, err := conn.ReadMessage()
+		require.NoError(t, err)
+		var resp rpcTestResponse
+		require.NoError(t, json.Unmarshal(b, &resp))
+		return resp
+	}
+
+	resp := call(`{"jsonrpc":"2.0","id":1,"method":"drand_subscribe"}`)
+	require.Nil(t, resp.Error)
+	var id string
+	require.NoError(t, json.Unmarshal(resp.Result, &id))
+	require.NotEmpty(t, id)
+
+	resp = call(`{"jsonrpc":"2.0","id":2,"method":"drand_unsubscribe","params":["` + id + `"]}`)
+	require.Equal(t, "true", string(resp.Result))
+	resp = call(`{"jsonrpc":"2.0","id":3,"method":"drand_unsubscribe","params":["` + id + `"]}`)
+	require.Equal(t, "false", string(resp.Result))
+}
+
+// catchUpClient serves the rounds up to its latest one.
+type catchUpClient struct {
+	historyClient
+	latest uint64
+}
+
+func (c *catchUpClient) Get(ctx context.Context, round uint64) (client.Result, error) {
+	if round == 0 {
+		round = c.latest
+	}
+	return c.historyClient.Get(ctx, round)
+}
+
+func TestJSONRPCSubscriptionIDFirst(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	info := &chain.Info{
+		PublicKey:   key.KeyGroup.Point().Base(),
+		Period:      time.Second,
+		GenesisTime: time.Now().Unix() - 100,
+	}
+	handler, err := New(ctx, &catchUpClient{historyClient{client.EmptyClientWithInfo(info)}, 3}, "", nil)
+	require.NoError(t, err)
+	server := httptest.NewServer(handler)
+	defer server.Close()
+
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(server.URL, "http")+"/rpc", nil)
+	require.NoError(t, err)
+	defer conn.Close()
+
+	// the rounds caught up are only sent after the id of the subscription
+	req := `{"jsonrpc":"2.0","id":1,"method":"drand_subscribe","params":{"from":1}}`
+	require.NoError(t, conn.WriteMessage(websocket.TextMessage, []byte(req)))
+	_, b, err := conn.ReadMessage()
+	require.NoError(t, err)
+	var resp rpcTestResponse
+	require.NoError(t, json.Unmarshal(b, &resp))
+	require.Nil(t, resp.Error)
+	var id string
+	require.NoError(t, json.Unmarshal(resp.Result, &id))
+
+	for round := uint64(1); round <= 3; round++ {
+		_, b, err := conn.ReadMessage()
+		require.NoError(t, err)
+		var notif struct {
+			Method string
+			Params struct {
+				Subscription string
+				Result       struct{ Rnd uint64 }
+			}
+		}
+		require.NoError(t, json.Unmarshal(b, &notif))
+		require.Equal(t, "drand_subscription", notif.Method)
+		require.Equal(t, id, notif.Params.Subscription)
+		require.Equal(t, round, notif.Params.Result.Rnd)
+	}
+}
@@ -0,0 +1,63 @@
+package http
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/drand/drand/client"
+	"github.com/drand/drand/log"
+
+	json "github.com/nikkolasg/hexjson"
+)
+
+// NewMultiChain creates an HTTP handler serving the public Drand API of
+// several chains, each from its own client, under the /{chain-hash}/ prefix,
+// such as /{chain-hash}/public/latest. The /chains endpoint lists the hashes
+// of the chains served, and the requests without a prefix are served by the
+// first chain, as by a relay serving a single one.
+func NewMultiChain(ctx context.Context, clients []client.Client, version string, logger log.Logger, opts ...Option) (http.Handler, error) {
+	if len(clients) == 0 {
+		return nil, errors.New("no chain to serve")
+	}
+	o := newOptions(opts)
+	mux := http.NewServeMux()
+	hashes := make([]string, 0, len(clients))
+	seen := make(map[string]bool)
+	for i, c := range clients {
+		ictx, cancel := context.WithTimeout(ctx, reqTimeout)
+		info, err := c.Info(ictx)
+		cancel()
+		if err != nil {
+			return nil, fmt.Errorf("could not get the chain info of client %d: %w", i, err)
+		}
+		hash := hex.EncodeToString(info.Hash())
+		if seen[hash] {
+			return nil, fmt.Errorf("chain %s is served by several clients", hash)
+		}
+		seen[hash] = true
+		hashes = append(hashes, hash)
+
+		handler := newChainHandler(ctx, c, version, logger, &o)
+		prefix := "/" + hash
+		mux.Handle(prefix+"/", http.StripPrefix(prefix, handler))
+		if i == 0 {
+			mux.Handle("/", handler)
+		}
+	}
+
+	chains, err := json.Marshal(hashes)
+	if err != nil {
+		return nil, err
+	}
+	var list http.Handler = http.HandlerFunc(withCommonHeaders(version, func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write(chains)
+	}))
+	if o.identity != nil {
+		list = signResponses(o.identity, list)
+	}
+	mux.Handle("/chains", list)
+	return o.wrap(mux), nil
+}
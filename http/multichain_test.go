@@ -0,0 +1,68 @@
+package http
+
+import (
+	"context"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/drand/drand/chain"
+	"github.com/drand/drand/client"
+	"github.com/drand/drand/key"
+	"github.com/stretchr/testify/require"
+
+	json "github.com/nikkolasg/hexjson"
+)
+
+func TestMultiChain(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	infos := []*chain.Info{{
+		PublicKey:   key.KeyGroup.Point().Base(),
+		Period:      time.Second,
+		GenesisTime: time.Now().Unix() - 100,
+	}, {
+		PublicKey:   key.KeyGroup.Point().Base(),
+		Period:      3 * time.Second,
+		GenesisTime: time.Now().Unix() - 100,
+	}}
+	clients := make([]client.Client, len(infos))
+	hashes := make([]string, len(infos))
+	for i, info := range infos {
+		clients[i] = &historyClient{client.EmptyClientWithInfo(info)}
+		hashes[i] = hex.EncodeToString(info.Hash())
+	}
+	handler, err := NewMultiChain(ctx, clients, "", nil)
+	require.NoError(t, err)
+
+	get := func(path string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+		return w
+	}
+
+	w := get("/chains")
+	require.Equal(t, http.StatusOK, w.Code)
+	var listed []string
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &listed))
+	require.Equal(t, hashes, listed)
+
+	// each chain serves its own info, and the first one is served without
+	// prefix
+	for i, path := range []string{"/" + hashes[0] + "/info", "/" + hashes[1] + "/info", "/info"} {
+		w = get(path)
+		require.Equal(t, http.StatusOK, w.Code)
+		info, err := chain.InfoFromJSON(w.Body)
+		require.NoError(t, err)
+		require.True(t, info.Equal(infos[i%2]), path)
+	}
+
+	w = get("/" + hashes[1] + "/public/2")
+	require.Equal(t, http.StatusOK, w.Code)
+
+	_, err = NewMultiChain(ctx, []client.Client{clients[0], clients[0]}, "", nil)
+	require.Error(t, err)
+}
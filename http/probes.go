@@ -0,0 +1,84 @@
+package http
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	json "github.com/nikkolasg/hexjson"
+)
+
+// defaultReadyLag is the number of rounds a ready relay may lag behind the
+// chain by default.
+const defaultReadyLag = 2
+
+// probeStatus is the body of the /healthz and /readyz probes.
+type probeStatus struct {
+	Status string `json:"status"`
+	// the readiness details, only set by /readyz
+	ChainInfo     *bool  `json:"chain_info,omitempty"`
+	Upstream      string `json:"upstream,omitempty"`
+	LatestRound   uint64 `json:"latest_round,omitempty"`
+	ExpectedRound uint64 `json:"expected_round,omitempty"`
+	Lag           uint64 `json:"lag,omitempty"`
+	MaxLag        uint64 `json:"max_lag,omitempty"`
+}
+
+// Healthz tells that the process is alive, for the liveness probes of the
+// orchestrators. It does not depend on the upstream, so that a relay which
+// lost it is not restarted in a loop.
+func (h *handler) Healthz(w http.ResponseWriter, r *http.Request) {
+	writeProbe(w, http.StatusOK, &probeStatus{Status: "ok"})
+}
+
+// Readyz tells whether the relay can serve the chain, for the readiness
+// probes of the orchestrators: its upstream is reachable, its chain info is
+// loaded and its latest round lags behind the chain by at most the maximum
+// lag.
+func (h *handler) Readyz(w http.ResponseWriter, r *http.Request) {
+	st := &probeStatus{Status: "ready", MaxLag: h.readyLag}
+	ready := true
+
+	info := h.getChainInfo(r.Context())
+	loaded := info != nil
+	st.ChainInfo = &loaded
+	ready = ready && loaded
+
+	ctx, cancel := context.WithTimeout(r.Context(), h.timeout)
+	defer cancel()
+	latest, err := h.get(ctx, 0)
+	switch {
+	case err != nil:
+		st.Upstream = err.Error()
+		ready = false
+	case latest == nil:
+		st.Upstream = "no round"
+		ready = false
+	default:
+		st.Upstream = "ok"
+		st.LatestRound = latest.Round()
+	}
+
+	if loaded && st.LatestRound > 0 {
+		st.ExpectedRound = info.RoundAt(time.Now())
+		if st.ExpectedRound > st.LatestRound {
+			st.Lag = st.ExpectedRound - st.LatestRound
+		}
+		ready = ready && st.Lag <= h.readyLag
+	}
+
+	code := http.StatusOK
+	if !ready {
+		st.Status = "not ready"
+		code = http.StatusServiceUnavailable
+	}
+	writeProbe(w, code, st)
+}
+
+func writeProbe(w http.ResponseWriter, code int, st *probeStatus) {
+	b, _ := json.Marshal(st)
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(code)
+	_, _ = w.Write(b)
+}
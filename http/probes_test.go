@@ -0,0 +1,72 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/drand/drand/chain"
+	"github.com/drand/drand/client"
+	resultmock "github.com/drand/drand/client/test/result/mock"
+	"github.com/drand/drand/key"
+	"github.com/stretchr/testify/require"
+
+	json "github.com/nikkolasg/hexjson"
+)
+
+// latestClient serves `latest` as its latest round, or fails without it.
+type latestClient struct {
+	client.Client
+	latest uint64
+}
+
+func (c *latestClient) Get(ctx context.Context, round uint64) (client.Result, error) {
+	if c.latest == 0 {
+		return nil, errors.New("upstream unreachable")
+	}
+	r := resultmock.NewMockResult(c.latest)
+	return &r, nil
+}
+
+func TestProbes(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	info := &chain.Info{
+		PublicKey:   key.KeyGroup.Point().Base(),
+		Period:      time.Second,
+		GenesisTime: time.Now().Unix() - 100,
+	}
+	current := info.RoundAt(time.Now())
+	c := &latestClient{Client: client.EmptyClientWithInfo(info)}
+	h, err := New(ctx, c, "", nil, WithReadyLag(5))
+	require.NoError(t, err)
+
+	probe := func(path string) (int, *probeStatus) {
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, httptest.NewRequest("GET", path, nil))
+		st := new(probeStatus)
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), st))
+		return w.Code, st
+	}
+
+	// the relay is alive without its upstream, but not ready
+	code, _ := probe("/healthz")
+	require.Equal(t, http.StatusOK, code)
+	code, st := probe("/readyz")
+	require.Equal(t, http.StatusServiceUnavailable, code)
+	require.Equal(t, "upstream unreachable", st.Upstream)
+
+	c.latest = current - 20
+	code, st = probe("/readyz")
+	require.Equal(t, http.StatusServiceUnavailable, code)
+	require.True(t, st.Lag >= 20)
+
+	c.latest = current
+	code, st = probe("/readyz")
+	require.Equal(t, http.StatusOK, code)
+	require.True(t, *st.ChainInfo)
+	require.Equal(t, "ok", st.Upstream)
+}
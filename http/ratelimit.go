@@ -0,0 +1,214 @@
+package http
+
+import (
+	"fmt"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/drand/drand/metrics"
+)
+
+// rateLimitIdle is how long the bucket of a client that sends no request is
+// kept.
+const rateLimitIdle = 10 * time.Minute
+
+// RateLimit is the rate limiting of the requests to the relay, applied to each
+// client IP and to each bearer token, so that a public relay survives the
+// scraping storms.
+type RateLimit struct {
+	// Rate is the number of requests per second allowed for each IP, 0
+	// disabling the limit per IP.
+	Rate float64
+	// Burst is the number of requests an IP may send at once.
+	Burst int
+	// TokenRate is the number of requests per second allowed for each bearer
+	// token, 0 disabling the limit per token. The requests carrying a token
+	// are only limited by it.
+	TokenRate float64
+	// TokenBurst is the number of requests a token may send at once.
+	TokenBurst int
+	// ExemptNets are the networks whose requests are never limited.
+	ExemptNets []*net.IPNet
+	// ExemptTokens are the bearer tokens whose requests are never limited.
+	ExemptTokens []string
+	// RealIPHeader is the header holding the IP of the client, such as
+	// X-Forwarded-For, for a relay behind a proxy which sets it. It must not
+	// be set otherwise, since the clients could forge it.
+	RealIPHeader string
+}
+
+// ParseExemptNets parses IPs and CIDR networks.
+func ParseExemptNets(nets []string) ([]*net.IPNet, error) {
+	parsed := make([]*net.IPNet, 0, len(nets))
+	for _, n := range nets {
+		if !strings.Contains(n, "/") {
+			ip := net.ParseIP(n)
+			if ip == nil {
+				return nil, fmt.Errorf("invalid IP %q", n)
+			}
+			bits := 8 * net.IPv6len
+			if ip.To4() != nil {
+				ip, bits = ip.To4(), 8*net.IPv4len
+			}
+			parsed = append(parsed, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+			continue
+		}
+		_, ipNet, err := net.ParseCIDR(n)
+		if err != nil {
+			return nil, err
+		}
+		parsed = append(parsed, ipNet)
+	}
+	return parsed, nil
+}
+
+// bucket is a token bucket, refilled at `rate` tokens per second up to
+// `burst` tokens.
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// take takes a token from the bucket, and otherwise returns how long to wait
+// for the next one.
+func (b *bucket) take(now time.Time, rate float64, burst int) (bool, time.Duration) {
+	b.tokens = math.Min(float64(burst), b.tokens+now.Sub(b.last).Seconds()*rate)
+	b.last = now
+	if b.tokens >= 1 {
+		b.tokens--
+		return true, 0
+	}
+	return false, time.Duration((1 - b.tokens) / rate * float64(time.Second))
+}
+
+// limiter holds the buckets of the clients of one kind.
+type limiter struct {
+	sync.Mutex
+	rate    float64
+	burst   int
+	buckets map[string]*bucket
+	swept   time.Time
+}
+
+func newLimiter(rate float64, burst int) *limiter {
+	if burst < 1 {
+		burst = int(math.Max(1, math.Ceil(rate)))
+	}
+	return &limiter{rate: rate, burst: burst, buckets: make(map[string]*bucket)}
+}
+
+func (l *limiter) allow(key string, now time.Time) (bool, time.Duration) {
+	l.Lock()
+	defer l.Unlock()
+	// the buckets of the clients gone quiet are full again, so dropping them
+	// changes nothing
+	if now.Sub(l.swept) > rateLimitIdle {
+		for k, b := range l.buckets {
+			if now.Sub(b.last) > rateLimitIdle {
+				delete(l.buckets, k)
+			}
+		}
+		l.swept = now
+	}
+	b, ok := l.buckets[key]
+	if !ok {
+		b = &bucket{tokens: float64(l.burst), last: now}
+		l.buckets[key] = b
+	}
+	return b.take(now, l.rate, l.burst)
+}
+
+// rateLimiter applies a RateLimit.
+type rateLimiter struct {
+	RateLimit
+	ips    *limiter
+	tokens *limiter
+	exempt map[string]bool
+	now    func() time.Time
+}
+
+func newRateLimiter(rl RateLimit) *rateLimiter {
+	r := &rateLimiter{RateLimit: rl, exempt: make(map[string]bool), now: time.Now}
+	if rl.Rate > 0 {
+		r.ips = newLimiter(rl.Rate, rl.Burst)
+	}
+	if rl.TokenRate > 0 {
+		r.tokens = newLimiter(rl.TokenRate, rl.TokenBurst)
+	}
+	for _, t := range rl.ExemptTokens {
+		r.exempt[t] = true
+	}
+	return r
+}
+
+// clientIP returns the IP the request comes from.
+func (r *rateLimiter) clientIP(req *http.Request) net.IP {
+	if r.RealIPHeader != "" {
+		if v := req.Header.Get(r.RealIPHeader); v != "" {
+			// the first address of X-Forwarded-For is the client's
+			if ip := net.ParseIP(strings.TrimSpace(strings.Split(v, ",")[0])); ip != nil {
+				return ip
+			}
+		}
+	}
+	host, _, err := net.SplitHostPort(req.RemoteAddr)
+	if err != nil {
+		host = req.RemoteAddr
+	}
+	return net.ParseIP(host)
+}
+
+func (r *rateLimiter) exemptIP(ip net.IP) bool {
+	for _, n := range r.ExemptNets {
+		if n.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// limit refuses the requests over the rate of their token or of their IP
+// with a 429 status telling when to retry.
+func (r *rateLimiter) limit(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		// the probes of the orchestrators, for any chain served, are never
+		// throttled
+		if strings.HasSuffix(req.URL.Path, "/healthz") || strings.HasSuffix(req.URL.Path, "/readyz") {
+			h.ServeHTTP(w, req)
+			return
+		}
+		now := r.now()
+		kind := ""
+		var allowed = true
+		var retry time.Duration
+		if token := bearerToken(req); token != "" && r.tokens != nil {
+			if !r.exempt[token] {
+				kind = "token"
+				allowed, retry = r.tokens.allow(token, now)
+			}
+		} else if ip := r.clientIP(req); r.ips != nil && ip != nil && !r.exemptIP(ip) {
+			kind = "ip"
+			allowed, retry = r.ips.allow(ip.String(), now)
+		}
+		if !allowed {
+			metrics.HTTPThrottled.WithLabelValues(kind).Inc()
+			w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(retry.Seconds()))))
+			http.Error(w, "too many requests", http.StatusTooManyRequests)
+			return
+		}
+		h.ServeHTTP(w, req)
+	})
+}
+
+func bearerToken(req *http.Request) string {
+	auth := req.Header.Get("Authorization")
+	if len(auth) > 7 && strings.EqualFold(auth[:7], "Bearer ") {
+		return strings.TrimSpace(auth[7:])
+	}
+	return ""
+}
@@ -0,0 +1,59 @@
+package http
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRateLimit(t *testing.T) {
+	exempt, err := ParseExemptNets([]string{"10.0.0.0/8", "192.168.1.1"})
+	require.NoError(t, err)
+	_, err = ParseExemptNets([]string{"nope"})
+	require.Error(t, err)
+
+	rl := newRateLimiter(RateLimit{
+		Rate:         1,
+		Burst:        2,
+		TokenRate:    10,
+		TokenBurst:   1,
+		ExemptNets:   exempt,
+		ExemptTokens: []string{"ops"},
+	})
+	now := time.Unix(1000, 0)
+	rl.now = func() time.Time { return now }
+	h := rl.limit(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	request := func(remote, token string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest("GET", "/public/latest", nil)
+		r.RemoteAddr = remote + ":4444"
+		if token != "" {
+			r.Header.Set("Authorization", "Bearer "+token)
+		}
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+		return w
+	}
+
+	// an IP may burst, then is throttled until its bucket refills
+	require.Equal(t, http.StatusOK, request("1.2.3.4", "").Code)
+	require.Equal(t, http.StatusOK, request("1.2.3.4", "").Code)
+	w := request("1.2.3.4", "")
+	require.Equal(t, http.StatusTooManyRequests, w.Code)
+	require.Equal(t, "1", w.Header().Get("Retry-After"))
+	require.Equal(t, http.StatusOK, request("5.6.7.8", "").Code)
+	now = now.Add(time.Second)
+	require.Equal(t, http.StatusOK, request("1.2.3.4", "").Code)
+
+	// the requests with a token are limited by the token only
+	require.Equal(t, http.StatusOK, request("1.2.3.4", "app").Code)
+	require.Equal(t, http.StatusTooManyRequests, request("5.6.7.8", "app").Code)
+
+	for i := 0; i < 10; i++ {
+		require.Equal(t, http.StatusOK, request("10.1.2.3", "").Code)
+		require.Equal(t, http.StatusOK, request("192.168.1.1", "").Code)
+		require.Equal(t, http.StatusOK, request("1.2.3.4", "ops").Code)
+	}
+}
@@ -0,0 +1,204 @@
+package http
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/drand/drand/client"
+	"github.com/drand/drand/metrics/otlp"
+
+	json "github.com/nikkolasg/hexjson"
+)
+
+const (
+	// defaultRoundsPage is the number of rounds served in a page of the
+	// /public/rounds endpoint when the request does not set its limit.
+	defaultRoundsPage = 100
+	// maxRoundsPage caps the number of rounds served in a page.
+	maxRoundsPage = 1000
+	// roundsTimeout is how long fetching the rounds of a page may take.
+	roundsTimeout = 30 * time.Second
+	// roundsConcurrency is the number of rounds of a page fetched
+	// concurrently from clients that can not serve ranges.
+	roundsConcurrency = 16
+)
+
+// roundsResponse is a page of the /public/rounds endpoint. Next is the token
+// of the following page, empty on the last one.
+type roundsResponse struct {
+	Rounds []client.Result `json:"rounds"`
+	Next   string          `json:"next,omitempty"`
+}
+
+// roundsRange is the range of rounds asked by a request of the
+// /public/rounds endpoint.
+type roundsRange struct {
+	start, end uint64
+	limit      int
+}
+
+// pageToken returns the opaque token of the page of the range starting at
+// `start`.
+func (rg roundsRange) pageToken(start uint64) string {
+	raw := fmt.Sprintf("%d-%d-%d", start, rg.end, rg.limit)
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+// parseRoundsRange reads the range of a request, given either by the `start`
+// and `end` query parameters or by the `page` token of a previous response,
+// and an optional `limit` on the number of rounds of a page.
+func parseRoundsRange(q url.Values) (roundsRange, error) {
+	rg := roundsRange{limit: defaultRoundsPage}
+	if page := q.Get("page"); page != "" {
+		raw, err := base64.RawURLEncoding.DecodeString(page)
+		if err != nil {
+			return rg, errors.New("invalid page token")
+		}
+		parts := strings.Split(string(raw), "-")
+		if len(parts) != 3 {
+			return rg, errors.New("invalid page token")
+		}
+		if rg.start, err = strconv.ParseUint(parts[0], 10, 64); err != nil {
+			return rg, errors.New("invalid page token")
+		}
+		if rg.end, err = strconv.ParseUint(parts[1], 10, 64); err != nil {
+			return rg, errors.New("invalid page token")
+		}
+		if rg.limit, err = strconv.Atoi(parts[2]); err != nil {
+			return rg, errors.New("invalid page token")
+		}
+	} else {
+		var err error
+		if rg.start, err = strconv.ParseUint(q.Get("start"), 10, 64); err != nil {
+			return rg, fmt.Errorf("invalid start: %w", err)
+		}
+		if rg.end, err = strconv.ParseUint(q.Get("end"), 10, 64); err != nil {
+			return rg, fmt.Errorf("invalid end: %w", err)
+		}
+		if l := q.Get("limit"); l != "" {
+			if rg.limit, err = strconv.Atoi(l); err != nil {
+				return rg, fmt.Errorf("invalid limit: %w", err)
+			}
+		}
+	}
+	if rg.start == 0 || rg.end < rg.start {
+		return rg, fmt.Errorf("invalid range %d-%d", rg.start, rg.end)
+	}
+	if rg.limit <= 0 || rg.limit > maxRoundsPage {
+		rg.limit = maxRoundsPage
+	}
+	return rg, nil
+}
+
+// Rounds serves consecutive rounds of the chain, a page at a time, so that
+// archival consumers pull the history in bulk. The range is capped at the
+// current round; the token of the next page is set while rounds of the range
+// remain.
+func (h *handler) Rounds(w http.ResponseWriter, r *http.Request) {
+	rg, err := parseRoundsRange(r.URL.Query())
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		h.log.Warn("http_server", "failed to parse rounds range", "client", r.RemoteAddr, "req", url.PathEscape(r.URL.RawQuery), "err", err)
+		return
+	}
+
+	info := h.getChainInfo(r.Context())
+	if info == nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		h.log.Warn("http_server", "failed to get chain info", "client", r.RemoteAddr, "req", url.PathEscape(r.URL.RawQuery))
+		return
+	}
+	current := info.RoundAt(time.Now())
+	if rg.start > current {
+		notYet(w, info.TimeOfRound(rg.start))
+		return
+	}
+	to := rg.end
+	if to > current {
+		to = current
+	}
+	if to-rg.start >= uint64(rg.limit) {
+		to = rg.start + uint64(rg.limit) - 1
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), roundsTimeout)
+	defer cancel()
+	results, err := h.getRange(ctx, rg.start, to)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		h.log.Warn("http_server", "failed to get rounds", "client", r.RemoteAddr, "req", url.PathEscape(r.URL.RawQuery), "err", err)
+		return
+	}
+
+	resp := roundsResponse{Rounds: results}
+	last := rg.start + uint64(len(results)) - 1
+	if last < rg.end {
+		resp.Next = rg.pageToken(last + 1)
+	}
+	data, err := json.Marshal(&resp)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		h.log.Warn("http_server", "failed to marshal rounds", "client", r.RemoteAddr, "req", url.PathEscape(r.URL.RawQuery), "err", err)
+		return
+	}
+
+	// a full page never changes, while the last one grows with the chain
+	if last-rg.start+1 == uint64(rg.limit) || last == rg.end {
+		serveImmutable(w, r, "rounds.json", info.TimeOfRound(last), data)
+	} else {
+		serveUntil(w, r, "rounds.json", info.TimeOfRound(last), info.TimeOfRound(last+1), data)
+	}
+}
+
+// getRange returns the consecutive rounds from `from` up to `to`, in a single
+// request when the client can serve ranges, and with concurrent requests
+// otherwise. Rounds fetched before the first failing one are returned without
+// error.
+func (h *handler) getRange(ctx context.Context, from, to uint64) ([]client.Result, error) {
+	if rc, ok := h.client.(client.RangeClient); ok {
+		rctx, span := h.tracer.StartSpan(ctx, "upstream range", otlp.KindClient)
+		span.SetAttribute("drand.from", from)
+		span.SetAttribute("drand.to", to)
+		start := time.Now()
+		results, err := rc.GetRange(rctx, from, to)
+		observeUpstream(h.chainLabel(), "range", start, err)
+		span.End(err)
+		if err == nil && len(results) > 0 && results[0].Round() == from {
+			return results, nil
+		}
+	}
+
+	results := make([]client.Result, to-from+1)
+	errs := make([]error, len(results))
+	tokens := make(chan struct{}, roundsConcurrency)
+	wg := sync.WaitGroup{}
+	for i := range results {
+		tokens <- struct{}{}
+		wg.Add(1)
+		go func(i int) {
+			defer func() {
+				<-tokens
+				wg.Done()
+			}()
+			results[i], errs[i] = h.get(ctx, from+uint64(i))
+		}(i)
+	}
+	wg.Wait()
+	for i, err := range errs {
+		if err != nil {
+			if i == 0 {
+				return nil, fmt.Errorf("getting round %d: %w", from, err)
+			}
+			return results[:i], nil
+		}
+	}
+	return results, nil
+}
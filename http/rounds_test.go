@@ -0,0 +1,78 @@
+package http
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/drand/drand/chain"
+	"github.com/drand/drand/client"
+	"github.com/drand/drand/key"
+	"github.com/stretchr/testify/require"
+
+	json "github.com/nikkolasg/hexjson"
+)
+
+type roundsPage struct {
+	Rounds []struct{ Rnd uint64 } `json:"rounds"`
+	Next   string                 `json:"next"`
+}
+
+func TestHTTPRounds(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	info := &chain.Info{
+		PublicKey:   key.KeyGroup.Point().Base(),
+		Period:      time.Second,
+		GenesisTime: time.Now().Unix() - 2*maxRoundsPage,
+	}
+	c := &historyClient{client.EmptyClientWithInfo(info)}
+	handler, err := New(ctx, c, "", nil)
+	require.NoError(t, err)
+
+	listener, err := net.Listen("tcp", "localhost:0")
+	require.NoError(t, err)
+	server := http.Server{Handler: handler}
+	go func() { _ = server.Serve(listener) }()
+	defer func() { _ = server.Shutdown(ctx) }()
+
+	get := func(query string) (int, *roundsPage) {
+		resp, err := http.Get(fmt.Sprintf("http://%s/public/rounds?%s", listener.Addr().String(), query))
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			return resp.StatusCode, nil
+		}
+		page := new(roundsPage)
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(page))
+		return resp.StatusCode, page
+	}
+
+	// the range is served in pages following the tokens
+	code, page := get("start=10&end=24&limit=10")
+	require.Equal(t, http.StatusOK, code)
+	require.Len(t, page.Rounds, 10)
+	require.Equal(t, uint64(10), page.Rounds[0].Rnd)
+	require.NotEmpty(t, page.Next)
+	code, page = get("page=" + page.Next)
+	require.Equal(t, http.StatusOK, code)
+	require.Len(t, page.Rounds, 5)
+	require.Equal(t, uint64(20), page.Rounds[0].Rnd)
+	require.Empty(t, page.Next)
+
+	// the page size is capped
+	_, page = get("start=1&end=5000&limit=5000")
+	require.Len(t, page.Rounds, maxRoundsPage)
+	require.NotEmpty(t, page.Next)
+
+	code, _ = get("start=20&end=10")
+	require.Equal(t, http.StatusBadRequest, code)
+	code, _ = get("page=invalid")
+	require.Equal(t, http.StatusBadRequest, code)
+	code, _ = get(fmt.Sprintf("start=%d&end=%d", 10000, 10010))
+	require.Equal(t, http.StatusNotFound, code)
+}
@@ -3,8 +3,8 @@ package http
 import (
 	"bytes"
 	"context"
+	"encoding/hex"
 	"fmt"
-	"math"
 	"net/http"
 	"net/url"
 	"strconv"
@@ -16,6 +16,7 @@ import (
 	"github.com/drand/drand/client"
 	"github.com/drand/drand/log"
 	"github.com/drand/drand/metrics"
+	"github.com/drand/drand/metrics/otlp"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 
 	json "github.com/nikkolasg/hexjson"
@@ -31,8 +32,76 @@ var (
 	reqTimeout = 5 * time.Second
 )
 
+// Option configures the HTTP handler of the public Drand API.
+type Option func(o *options)
+
+type options struct {
+	noCompression bool
+	cors          CORSPolicy
+	rateLimit     *RateLimit
+	readyLag      uint64
+	tracer        *otlp.Exporter
+	graphql       bool
+	identity      *Identity
+}
+
+// WithoutCompression disables the gzip encoding of the responses, for relays
+// behind a proxy or a CDN which compresses them.
+func WithoutCompression() Option {
+	return func(o *options) {
+		o.noCompression = true
+	}
+}
+
+// WithCORS sets the cross-origin policy of the handler, which allows any
+// origin to read the randomness by default.
+func WithCORS(policy CORSPolicy) Option {
+	return func(o *options) {
+		o.cors = policy
+	}
+}
+
+// WithRateLimit limits the rate of the requests of each client, which is not
+// limited by default.
+func WithRateLimit(rl RateLimit) Option {
+	return func(o *options) {
+		o.rateLimit = &rl
+	}
+}
+
+// WithReadyLag sets the number of rounds the relay may lag behind the chain
+// while its /readyz probe reports it ready, 2 by default.
+func WithReadyLag(rounds uint64) Option {
+	return func(o *options) {
+		o.readyLag = rounds
+	}
+}
+
+// WithGraphQL serves the GraphQL API at /graphql, which is not served by
+// default.
+func WithGraphQL() Option {
+	return func(o *options) {
+		o.graphql = true
+	}
+}
+
 // New creates an HTTP handler for the public Drand API
-func New(ctx context.Context, c client.Client, version string, logger log.Logger) (http.Handler, error) {
+func New(ctx context.Context, c client.Client, version string, logger log.Logger, opts ...Option) (http.Handler, error) {
+	o := newOptions(opts)
+	return o.wrap(newChainHandler(ctx, c, version, logger, &o)), nil
+}
+
+func newOptions(opts []Option) options {
+	o := options{cors: DefaultCORSPolicy(), readyLag: defaultReadyLag}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
+// newChainHandler creates the handler of the endpoints serving the chain of a
+// client.
+func newChainHandler(ctx context.Context, c client.Client, version string, logger log.Logger, o *options) http.Handler {
 	if logger == nil {
 		logger = log.DefaultLogger()
 	}
@@ -45,39 +114,105 @@ func New(ctx context.Context, c client.Client, version string, logger log.Logger
 		context:     ctx,
 		latestRound: 0,
 		version:     version,
+		readyLag:    o.readyLag,
+		tracer:      o.tracer,
 	}
 
 	mux := http.NewServeMux()
-	//TODO: aggregated bulk round responses.
 	mux.HandleFunc("/public/latest", withCommonHeaders(version, handler.LatestRand))
+	mux.HandleFunc("/public/rounds", withCommonHeaders(version, handler.Rounds))
+	mux.HandleFunc("/public/stream", handler.Events)
 	mux.HandleFunc("/public/", withCommonHeaders(version, handler.PublicRand))
+	mux.HandleFunc("/proof/", withCommonHeaders(version, handler.Proof))
 	mux.HandleFunc("/info", withCommonHeaders(version, handler.ChainInfo))
 	mux.HandleFunc("/health", withCommonHeaders(version, handler.Health))
+	mux.HandleFunc("/status", withCommonHeaders(version, handler.Status))
+	mux.HandleFunc("/healthz", handler.Healthz)
+	mux.HandleFunc("/readyz", handler.Readyz)
+	mux.HandleFunc("/ws", handler.WebSocket)
+	mux.HandleFunc("/events", handler.Events)
+	mux.HandleFunc("/drand.Public/", handler.GRPCWeb)
+	mux.HandleFunc("/rpc", handler.JSONRPC)
+	if o.graphql {
+		mux.HandleFunc("/graphql", handler.GraphQL)
+	}
+
+	var served http.Handler = mux
+	if o.identity != nil {
+		served = signResponses(o.identity, served)
+	}
+	if !o.noCompression {
+		served = compress(served)
+	}
+	served = handler.observe(served)
+	if d, ok := c.(Drainer); ok {
+		served = refuseWhileDraining(d, served)
+	}
+	return served
+}
 
-	instrumented := promhttp.InstrumentHandlerCounter(
+// wrap applies the policies shared by all the chains served.
+func (o *options) wrap(served http.Handler) http.Handler {
+	if o.rateLimit != nil {
+		served = newRateLimiter(*o.rateLimit).limit(served)
+	}
+	served = o.cors.cors(served)
+	return promhttp.InstrumentHandlerCounter(
 		metrics.HTTPCallCounter,
 		promhttp.InstrumentHandlerDuration(
 			metrics.HTTPLatency,
 			promhttp.InstrumentHandlerInFlight(
 				metrics.HTTPInFlight,
-				mux)))
-	return instrumented, nil
+				served)))
+}
+
+// refuseWhileDraining refuses all the requests, including the health checks,
+// while the node drains, so that the load balancers stop sending it traffic.
+// The liveness probe still succeeds, since the draining node must not be
+// restarted.
+func refuseWhileDraining(d Drainer, h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if d.Draining() && r.URL.Path != "/healthz" {
+			w.Header().Set("Connection", "close")
+			http.Error(w, "the node is draining for maintenance", http.StatusServiceUnavailable)
+			return
+		}
+		h.ServeHTTP(w, r)
+	})
 }
 
 func withCommonHeaders(version string, h func(http.ResponseWriter, *http.Request)) func(http.ResponseWriter, *http.Request) {
 	return func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Server", version)
 		w.Header().Set("Content-Type", "application/json")
-		w.Header().Set("Access-Control-Allow-Origin", "*")
 		h(w, r)
 	}
 }
 
+// Pauser is implemented by the clients of a node whose operator may pause its
+// participation in the beacons, which the health endpoint reports.
+type Pauser interface {
+	Paused() bool
+}
+
+// Drainer is implemented by the clients of a node whose operator may drain it
+// before a maintenance, during which it refuses the public requests.
+type Drainer interface {
+	Draining() bool
+}
+
+// StatusReporter is implemented by the clients of a node which reports its
+// detailed health on the /status endpoint.
+type StatusReporter interface {
+	Status(ctx context.Context) (status interface{}, healthy bool)
+}
+
 type handler struct {
 	timeout time.Duration
 	client  client.Client
 	// NOTE: should only be accessed via getChainInfo
 	chainInfo   *chain.Info
+	chainHash   string
 	chainInfoLk sync.RWMutex
 	log         log.Logger
 
@@ -88,6 +223,17 @@ type handler struct {
 	context     context.Context
 	latestRound uint64
 	version     string
+	// readyLag is the number of rounds the relay may lag while ready.
+	readyLag uint64
+	// tracer exports the spans of the requests, when not nil.
+	tracer *otlp.Exporter
+
+	// subscribers streaming every new round.
+	subsLk sync.Mutex
+	subs   map[chan client.Result]struct{}
+
+	// commitments to ranges of rounds served by the proof endpoint.
+	commitments commitments
 }
 
 func (h *handler) start() {
@@ -138,6 +284,8 @@ RESET:
 			waiter <- b
 		}
 		h.pendingLk.Unlock()
+		h.publish(next)
+		h.updateCacheRatio()
 
 		select {
 		case <-ctx.Done():
@@ -164,7 +312,12 @@ func (h *handler) getChainInfo(ctx context.Context) *chain.Info {
 
 	ctx, cancel := context.WithTimeout(ctx, h.timeout)
 	defer cancel()
+	ctx, span := h.tracer.StartSpan(ctx, "upstream info", otlp.KindClient)
+	start := time.Now()
 	info, err := h.client.Info(ctx)
+	// the chain is not known before its info
+	observeUpstream(unknownChain, "info", start, err)
+	span.End(err)
 	if err != nil {
 		h.log.Warn("msg", "chain info fetch failed", "err", err)
 		return nil
@@ -174,6 +327,7 @@ func (h *handler) getChainInfo(ctx context.Context) *chain.Info {
 		return nil
 	}
 	h.chainInfo = info
+	h.chainHash = hex.EncodeToString(info.Hash())
 	return info
 }
 
@@ -218,13 +372,13 @@ func (h *handler) getRand(ctx context.Context, info *chain.Info, round uint64) (
 	}
 
 	// make sure we aren't going to ask for a round that doesn't exist yet.
-	if time.Unix(chain.TimeOfRound(info.Period, info.GenesisTime, round), 0).After(time.Now()) {
+	if info.TimeOfRound(round).After(time.Now()) {
 		return nil, nil
 	}
 
 	ctx, cancel := context.WithTimeout(ctx, h.timeout)
 	defer cancel()
-	resp, err := h.client.Get(ctx, round)
+	resp, err := h.get(ctx, round)
 
 	if err != nil {
 		return nil, err
@@ -251,12 +405,10 @@ func (h *handler) PublicRand(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	roundExpectedTime = time.Unix(chain.TimeOfRound(info.Period, info.GenesisTime, roundN), 0)
+	roundExpectedTime = info.TimeOfRound(roundN)
 
 	if roundExpectedTime.After(time.Now().Add(info.Period)) {
-		timeToExpected := int(time.Until(roundExpectedTime).Seconds())
-		w.WriteHeader(http.StatusNotFound)
-		w.Header().Set("Cache-Control", fmt.Sprintf("public, must-revalidate, max-age=%d", timeToExpected))
+		notYet(w, roundExpectedTime)
 		h.log.Warn("http_server", "request in the future", "client", r.RemoteAddr, "req", url.PathEscape(r.URL.Path))
 		return
 	}
@@ -268,24 +420,20 @@ func (h *handler) PublicRand(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	if data == nil {
-		w.WriteHeader(http.StatusNotFound)
-		w.Header().Set("Cache-Control", "must-revalidate, no-cache, max-age=0")
+		notYet(w, time.Time{})
 		h.log.Warn("http_server", "request in the future", "client", r.RemoteAddr, "req", url.PathEscape(r.URL.Path))
 		return
 	}
 
-	// Headers per recommendation for static assets at
-	// https://developer.mozilla.org/en-US/docs/Web/HTTP/Headers/Cache-Control
-	w.Header().Set("Cache-Control", "public, max-age=604800, immutable")
-	w.Header().Set("Expires", time.Now().Add(7*24*time.Hour).Format(http.TimeFormat))
-	http.ServeContent(w, r, "rand.json", roundExpectedTime, bytes.NewReader(data))
+	// a past round never changes
+	serveImmutable(w, r, "rand.json", roundExpectedTime, data)
 }
 
 func (h *handler) LatestRand(w http.ResponseWriter, r *http.Request) {
 	ctx, cancel := context.WithTimeout(r.Context(), h.timeout)
 	defer cancel()
 
-	resp, err := h.client.Get(ctx, 0)
+	resp, err := h.get(ctx, 0)
 
 	if err != nil {
 		w.WriteHeader(http.StatusInternalServerError)
@@ -301,29 +449,20 @@ func (h *handler) LatestRand(w http.ResponseWriter, r *http.Request) {
 	}
 
 	info := h.getChainInfo(r.Context())
-	roundTime := time.Now()
-	nextTime := time.Now()
-	if info != nil {
-		roundTime = time.Unix(chain.TimeOfRound(info.Period, info.GenesisTime, resp.Round()), 0)
-		next := time.Unix(chain.TimeOfRound(info.Period, info.GenesisTime, resp.Round()+1), 0)
-		if next.After(nextTime) {
-			nextTime = next
-		} else {
-			nextTime = nextTime.Add(info.Period / catchupExpiryFactor)
-		}
+	if info == nil {
+		w.Header().Set("Cache-Control", "must-revalidate, no-cache, max-age=0")
+		_, _ = w.Write(data)
+		return
 	}
-
-	remaining := time.Until(nextTime)
-	if remaining > 0 && remaining < info.Period {
-		seconds := int(math.Ceil(remaining.Seconds()))
-		w.Header().Set("Cache-Control", fmt.Sprintf("max-age:%d, public", seconds))
-	} else {
-		h.log.Warn("http_server", "latest rand in the past", "client", r.RemoteAddr, "req", url.PathEscape(r.URL.Path), "remaining", remaining)
+	// the latest round changes with the next one, or soon if the relay is
+	// catching up
+	roundTime := info.TimeOfRound(resp.Round())
+	nextTime := info.TimeOfRound(resp.Round() + 1)
+	if !nextTime.After(time.Now()) {
+		h.log.Warn("http_server", "latest rand in the past", "client", r.RemoteAddr, "req", url.PathEscape(r.URL.Path), "remaining", time.Until(nextTime))
+		nextTime = time.Now().Add(info.Period / catchupExpiryFactor)
 	}
-
-	w.Header().Set("Expires", nextTime.Format(http.TimeFormat))
-	w.Header().Set("Last-Modified", roundTime.Format(http.TimeFormat))
-	_, _ = w.Write(data)
+	serveUntil(w, r, "latest.json", roundTime, nextTime, data)
 }
 
 func (h *handler) ChainInfo(w http.ResponseWriter, r *http.Request) {
@@ -341,11 +480,35 @@ func (h *handler) ChainInfo(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Headers per recommendation for static assets at
-	// https://developer.mozilla.org/en-US/docs/Web/HTTP/Headers/Cache-Control
-	w.Header().Set("Cache-Control", "public, max-age=604800, immutable")
-	w.Header().Set("Expires", time.Now().Add(7*24*time.Hour).Format(http.TimeFormat))
-	http.ServeContent(w, r, "info.json", time.Unix(info.GenesisTime, 0), bytes.NewReader(chainBuff.Bytes()))
+	serveImmutable(w, r, "info.json", time.Unix(info.GenesisTime, 0), chainBuff.Bytes())
+}
+
+// Status serves the detailed health of the node, with the status code of the
+// health endpoint.
+func (h *handler) Status(w http.ResponseWriter, r *http.Request) {
+	reporter, ok := h.client.(StatusReporter)
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	status, healthy := reporter.Status(r.Context())
+	if status == nil {
+		http.NotFound(w, r)
+		return
+	}
+	b, err := json.Marshal(status)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		h.log.Warn("http_server", "failed to marshal status", "client", r.RemoteAddr, "err", err)
+		return
+	}
+	w.Header().Set("Cache-Control", "no-cache")
+	if healthy {
+		w.WriteHeader(http.StatusOK)
+	} else {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+	_, _ = w.Write(b)
 }
 
 func (h *handler) Health(w http.ResponseWriter, r *http.Request) {
@@ -359,15 +522,19 @@ func (h *handler) Health(w http.ResponseWriter, r *http.Request) {
 
 	w.Header().Set("Content-Type", "application/json")
 	w.Header().Set("Cache-Control", "no-cache")
-	resp := make(map[string]uint64)
+	resp := make(map[string]interface{})
 	resp["current"] = lastSeen
 	resp["expected"] = 0
+	if p, ok := h.client.(Pauser); ok {
+		// a paused node still serves the chain, so it stays healthy
+		resp["paused"] = p.Paused()
+	}
 	var b []byte
 
 	if info == nil {
 		w.WriteHeader(http.StatusServiceUnavailable)
 	} else {
-		expected := chain.CurrentRound(time.Now().Unix(), info.Period, info.GenesisTime)
+		expected := info.RoundAt(time.Now())
 		resp["expected"] = expected
 		if lastSeen == expected || lastSeen+1 == expected {
 			w.WriteHeader(http.StatusOK)
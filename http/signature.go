@@ -0,0 +1,169 @@
+package http
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SignatureHeader is the header of the responses holding the signature of the
+// relay serving them, as `id=<relay id>, t=<unix time>, sig=<signature>`. The
+// ID of a relay is its hex encoded ed25519 public key and the base64 encoded
+// signature covers the time and the body of the response. A client recording
+// the signature can prove which relay served a response, without changing the
+// verification of the beacons.
+const SignatureHeader = "Drand-Relay-Signature"
+
+// signatureContext separates the signatures of the responses from any other
+// use of the key of a relay.
+const signatureContext = "drand-relay-response:"
+
+// Identity is the key pair identifying a relay.
+type Identity struct {
+	key ed25519.PrivateKey
+}
+
+// NewIdentity returns the identity of a private key.
+func NewIdentity(key ed25519.PrivateKey) *Identity {
+	return &Identity{key: key}
+}
+
+// LoadOrCreateIdentity loads the identity of a relay from a file holding its
+// base64 encoded private key, as the identity of the gossip relay, or creates
+// the file with a new identity if it does not exist.
+func LoadOrCreateIdentity(path string) (*Identity, error) {
+	encoded, err := ioutil.ReadFile(path)
+	switch {
+	case err == nil:
+		key, err := base64.RawStdEncoding.DecodeString(strings.TrimSpace(string(encoded)))
+		if err != nil {
+			return nil, fmt.Errorf("decoding identity: %w", err)
+		}
+		if len(key) != ed25519.PrivateKeySize {
+			return nil, fmt.Errorf("identity of %d bytes, expected an ed25519 key of %d", len(key), ed25519.PrivateKeySize)
+		}
+		return NewIdentity(key), nil
+	case errors.Is(err, os.ErrNotExist):
+		_, key, err := ed25519.GenerateKey(rand.Reader)
+		if err != nil {
+			return nil, fmt.Errorf("generating identity: %w", err)
+		}
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			return nil, fmt.Errorf("creating identity folder: %w", err)
+		}
+		if err := ioutil.WriteFile(path, []byte(base64.RawStdEncoding.EncodeToString(key)), 0600); err != nil {
+			return nil, fmt.Errorf("writing identity: %w", err)
+		}
+		return NewIdentity(key), nil
+	default:
+		return nil, fmt.Errorf("reading identity: %w", err)
+	}
+}
+
+// ID returns the ID of the relay, its hex encoded public key.
+func (id *Identity) ID() string {
+	return hex.EncodeToString(id.key.Public().(ed25519.PublicKey))
+}
+
+// Sign returns the value of the signature header of a response sent at the
+// given time.
+func (id *Identity) Sign(t time.Time, body []byte) string {
+	sig := ed25519.Sign(id.key, signedMessage(t.Unix(), body))
+	return fmt.Sprintf("id=%s, t=%d, sig=%s", id.ID(), t.Unix(), base64.StdEncoding.EncodeToString(sig))
+}
+
+func signedMessage(t int64, body []byte) []byte {
+	msg := make([]byte, 0, len(signatureContext)+20+len(body))
+	msg = append(msg, signatureContext...)
+	msg = strconv.AppendInt(msg, t, 10)
+	msg = append(msg, ':')
+	return append(msg, body...)
+}
+
+// VerifySignature verifies the signature header of a response against its
+// body, returning the ID of the relay which signed it and the time of the
+// signature.
+func VerifySignature(header string, body []byte) (string, time.Time, error) {
+	var id, ts, sig string
+	for _, part := range strings.Split(header, ",") {
+		kv := strings.SplitN(strings.TrimSpace(part), "=", 2)
+		if len(kv) != 2 {
+			return "", time.Time{}, fmt.Errorf("malformed signature header %q", header)
+		}
+		switch kv[0] {
+		case "id":
+			id = kv[1]
+		case "t":
+			ts = kv[1]
+		case "sig":
+			sig = kv[1]
+		}
+	}
+	pub, err := hex.DecodeString(id)
+	if err != nil || len(pub) != ed25519.PublicKeySize {
+		return "", time.Time{}, fmt.Errorf("invalid relay id %q", id)
+	}
+	t, err := strconv.ParseInt(ts, 10, 64)
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("invalid signature time %q", ts)
+	}
+	s, err := base64.StdEncoding.DecodeString(sig)
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("invalid signature encoding: %w", err)
+	}
+	if !ed25519.Verify(pub, signedMessage(t, body), s) {
+		return "", time.Time{}, errors.New("invalid signature")
+	}
+	return id, time.Unix(t, 0), nil
+}
+
+// WithIdentity signs the responses with the identity of the relay, in the
+// signature header. The streams and the responses to the HEAD requests are not
+// signed.
+func WithIdentity(id *Identity) Option {
+	return func(o *options) {
+		o.identity = id
+	}
+}
+
+// signResponses holds the responses of h until they are complete, to sign
+// their uncompressed body.
+func signResponses(id *Identity, h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodHead || streamingEndpoints[endpointOf(r.URL.Path)] || r.Header.Get("Upgrade") != "" {
+			h.ServeHTTP(w, r)
+			return
+		}
+		sw := &signWriter{ResponseWriter: w, code: http.StatusOK}
+		h.ServeHTTP(sw, r)
+		w.Header().Set(SignatureHeader, id.Sign(time.Now(), sw.body.Bytes()))
+		w.WriteHeader(sw.code)
+		_, _ = w.Write(sw.body.Bytes())
+	})
+}
+
+// signWriter holds a response until it is signed.
+type signWriter struct {
+	http.ResponseWriter
+	code int
+	body bytes.Buffer
+}
+
+func (w *signWriter) WriteHeader(code int) {
+	w.code = code
+}
+
+func (w *signWriter) Write(b []byte) (int, error) {
+	return w.body.Write(b)
+}
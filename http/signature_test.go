@@ -0,0 +1,59 @@
+package http
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/drand/drand/chain"
+	"github.com/drand/drand/client"
+	"github.com/drand/drand/key"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSignedResponses(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	dir, err := ioutil.TempDir("", "relay-identity")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "identity.key")
+	id, err := LoadOrCreateIdentity(path)
+	require.NoError(t, err)
+	loaded, err := LoadOrCreateIdentity(path)
+	require.NoError(t, err)
+	require.Equal(t, id.ID(), loaded.ID())
+
+	info := &chain.Info{
+		PublicKey:   key.KeyGroup.Point().Base(),
+		Period:      time.Second,
+		GenesisTime: time.Now().Unix() - 100,
+	}
+	handler, err := New(ctx, &historyClient{client.EmptyClientWithInfo(info)}, "", nil, WithIdentity(id))
+	require.NoError(t, err)
+
+	// the signature covers the uncompressed body
+	r := httptest.NewRequest(http.MethodGet, "/public/10", nil)
+	r.Header.Set("Accept-Encoding", "identity")
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, r)
+	require.Equal(t, http.StatusOK, w.Code)
+	header := w.Header().Get(SignatureHeader)
+	require.NotEmpty(t, header)
+
+	relay, at, err := VerifySignature(header, w.Body.Bytes())
+	require.NoError(t, err)
+	require.Equal(t, id.ID(), relay)
+	require.WithinDuration(t, time.Now(), at, time.Minute)
+
+	_, _, err = VerifySignature(header, append(w.Body.Bytes(), ' '))
+	require.Error(t, err)
+	_, _, err = VerifySignature("id=00, t=1, sig=", w.Body.Bytes())
+	require.Error(t, err)
+}
@@ -0,0 +1,74 @@
+package http
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/drand/drand/client"
+
+	json "github.com/nikkolasg/hexjson"
+)
+
+// Events streams new rounds of randomness as Server-Sent Events, on the
+// /public/stream endpoint and on its older /events alias. Each event carries
+// the round number as its id and the JSON encoded round as its data. A stream
+// resumes after the round given by the `Last-Event-ID` header, or at the round
+// given by the `from` query parameter. The browsers are told to reconnect
+// after a period of the chain.
+func (h *handler) Events(w http.ResponseWriter, r *http.Request) {
+	from, err := streamStart(r)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		h.log.Warn("http_server", "failed to parse stream round", "client", r.RemoteAddr, "req", url.PathEscape(r.URL.RawQuery), "err", err)
+		return
+	}
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		w.WriteHeader(http.StatusInternalServerError)
+		h.log.Warn("http_server", "streaming unsupported", "client", r.RemoteAddr)
+		return
+	}
+
+	w.Header().Set("Server", h.version)
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	// stops the reverse proxies buffering the events
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+	if info := h.getChainInfo(r.Context()); info != nil {
+		_, _ = fmt.Fprintf(w, "retry: %d\n\n", info.Period.Milliseconds())
+	}
+	flusher.Flush()
+	defer h.streaming("sse")()
+
+	err = h.streamRounds(r.Context(), from, func(res client.Result) error {
+		b, err := json.Marshal(res)
+		if err != nil {
+			return err
+		}
+		if _, err := fmt.Fprintf(w, "id: %d\nevent: round\ndata: %s\n\n", res.Round(), b); err != nil {
+			return err
+		}
+		flusher.Flush()
+		return nil
+	})
+	h.log.Debug("http_server", "event stream ended", "client", r.RemoteAddr, "err", err)
+}
+
+// streamStart returns the first round a stream request asks for, 0 meaning
+// only new rounds.
+func streamStart(r *http.Request) (uint64, error) {
+	if id := r.Header.Get("Last-Event-ID"); id != "" {
+		last, err := strconv.ParseUint(id, 10, 64)
+		if err != nil {
+			return 0, err
+		}
+		return last + 1, nil
+	}
+	if f := r.URL.Query().Get("from"); f != "" {
+		return strconv.ParseUint(f, 10, 64)
+	}
+	return 0, nil
+}
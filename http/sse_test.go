@@ -0,0 +1,29 @@
+package http
+
+import (
+	"net/http/httptest"
+	"testing"
+)
+
+func TestStreamStart(t *testing.T) {
+	r := httptest.NewRequest("GET", "/events", nil)
+	from, err := streamStart(r)
+	if err != nil || from != 0 {
+		t.Fatal("expected to only stream new rounds", from, err)
+	}
+
+	r = httptest.NewRequest("GET", "/public/stream?from=10", nil)
+	if from, err = streamStart(r); err != nil || from != 10 {
+		t.Fatal("expected to start at the requested round", from, err)
+	}
+
+	r.Header.Set("Last-Event-ID", "41")
+	if from, err = streamStart(r); err != nil || from != 42 {
+		t.Fatal("expected to resume after the last event", from, err)
+	}
+
+	r.Header.Set("Last-Event-ID", "nope")
+	if _, err = streamStart(r); err == nil {
+		t.Fatal("expected invalid event id to fail")
+	}
+}
@@ -0,0 +1,105 @@
+package http
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/drand/drand/client"
+)
+
+const (
+	// subscriberBuffer is how many rounds a streaming subscriber may lag
+	// behind before it is disconnected.
+	subscriberBuffer = 5
+	// maxStreamCatchup bounds how many past rounds are replayed to a stream
+	// resuming from an earlier round.
+	maxStreamCatchup = 1000
+)
+
+// subscribe registers a channel receiving every new round seen by the handler.
+// The channel is closed if the subscriber falls too far behind, or once the
+// returned cancel function is called.
+func (h *handler) subscribe() (<-chan client.Result, func()) {
+	h.startOnce.Do(h.start)
+
+	ch := make(chan client.Result, subscriberBuffer)
+	h.subsLk.Lock()
+	if h.subs == nil {
+		h.subs = make(map[chan client.Result]struct{})
+	}
+	h.subs[ch] = struct{}{}
+	h.subsLk.Unlock()
+
+	return ch, func() {
+		h.subsLk.Lock()
+		defer h.subsLk.Unlock()
+		if _, ok := h.subs[ch]; ok {
+			delete(h.subs, ch)
+			close(ch)
+		}
+	}
+}
+
+// publish sends a new round to all subscribers, dropping the ones which are
+// not keeping up.
+func (h *handler) publish(r client.Result) {
+	h.subsLk.Lock()
+	defer h.subsLk.Unlock()
+	for ch := range h.subs {
+		select {
+		case ch <- r:
+		default:
+			h.log.Warn("http_server", "dropping slow stream subscriber", "round", r.Round())
+			delete(h.subs, ch)
+			close(ch)
+		}
+	}
+}
+
+// streamRounds calls send with every round starting at `from`, replaying past
+// rounds first and then following new ones as they are produced. A `from` of
+// 0 only streams new rounds. It returns once the context is done, sending
+// fails, or the subscription is dropped.
+func (h *handler) streamRounds(ctx context.Context, from uint64, send func(client.Result) error) error {
+	sub, cancel := h.subscribe()
+	defer cancel()
+
+	var last uint64
+	if from > 0 {
+		latest, err := h.get(ctx, 0)
+		if err != nil {
+			return err
+		}
+		if latest.Round() >= from && latest.Round()-from > maxStreamCatchup {
+			return fmt.Errorf("cannot resume more than %d rounds in the past", maxStreamCatchup)
+		}
+		for round := from; round <= latest.Round(); round++ {
+			r, err := h.get(ctx, round)
+			if err != nil {
+				return err
+			}
+			if err := send(r); err != nil {
+				return err
+			}
+			last = round
+		}
+	}
+
+	for {
+		select {
+		case r, ok := <-sub:
+			if !ok {
+				return fmt.Errorf("stream subscription dropped")
+			}
+			if r.Round() <= last {
+				continue
+			}
+			if err := send(r); err != nil {
+				return err
+			}
+			last = r.Round()
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
@@ -0,0 +1,161 @@
+package http
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/drand/drand/client"
+	"github.com/drand/drand/metrics"
+	"github.com/drand/drand/metrics/otlp"
+)
+
+// unknownChain labels the metrics recorded before the chain info is known.
+const unknownChain = "unknown"
+
+// streamingEndpoints hold the requests lasting as long as their clients
+// follow the chain, which are counted by the streams gauge rather than
+// measured by the latency and size histograms.
+var streamingEndpoints = map[string]bool{
+	"/public/stream":                 true,
+	"/events":                        true,
+	"/ws":                            true,
+	"/drand.Public/PublicRandStream": true,
+}
+
+// WithTracer exports a span for each request served by the relay and for each
+// request it makes to its upstream, continuing the traces of the requests
+// carrying a W3C traceparent header.
+func WithTracer(e *otlp.Exporter) Option {
+	return func(o *options) {
+		o.tracer = e
+	}
+}
+
+// endpointOf returns the endpoint of a request path, so that the metrics hold
+// one series per endpoint rather than one per round.
+func endpointOf(path string) string {
+	switch path {
+	case "/public/latest", "/public/rounds", "/public/stream", "/info", "/health", "/status",
+		"/healthz", "/readyz", "/ws", "/events", "/rpc", "/graphql",
+		"/drand.Public/PublicRand", "/drand.Public/PublicRandStream", "/drand.Public/ChainInfo":
+		return path
+	}
+	switch {
+	case strings.HasPrefix(path, "/public/"):
+		return "/public/{round}"
+	case strings.HasPrefix(path, "/proof/"):
+		return "/proof/{round}"
+	default:
+		return "other"
+	}
+}
+
+// chainLabel returns the hash of the chain served, which labels the metrics.
+func (h *handler) chainLabel() string {
+	h.chainInfoLk.RLock()
+	defer h.chainInfoLk.RUnlock()
+	if h.chainHash == "" {
+		return unknownChain
+	}
+	return h.chainHash
+}
+
+// observe measures the latency and the size of the responses of h by
+// endpoint, and traces the requests.
+func (h *handler) observe(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		endpoint := endpointOf(r.URL.Path)
+		ctx := r.Context()
+		if tp := r.Header.Get("traceparent"); tp != "" {
+			ctx = otlp.WithTraceParent(ctx, tp)
+		}
+		ctx, span := h.tracer.StartSpan(ctx, r.Method+" "+endpoint, otlp.KindServer)
+		span.SetAttribute("http.method", r.Method)
+		span.SetAttribute("http.route", endpoint)
+		r = r.WithContext(ctx)
+		// the websocket upgrades need the connection underneath
+		if streamingEndpoints[endpoint] || r.Header.Get("Upgrade") != "" {
+			next.ServeHTTP(w, r)
+			span.End(nil)
+			return
+		}
+
+		start := time.Now()
+		sw := &sizeWriter{ResponseWriter: w, code: http.StatusOK}
+		next.ServeHTTP(sw, r)
+		chain := h.chainLabel()
+		metrics.HTTPEndpointLatency.WithLabelValues(endpoint, chain).Observe(time.Since(start).Seconds())
+		metrics.HTTPResponseSize.WithLabelValues(endpoint, chain).Observe(float64(sw.size))
+		span.SetAttribute("http.status_code", sw.code)
+		span.SetAttribute("drand.chain", chain)
+		var err error
+		if sw.code >= http.StatusInternalServerError {
+			err = fmt.Errorf("%d %s", sw.code, http.StatusText(sw.code))
+		}
+		span.End(err)
+	})
+}
+
+// sizeWriter counts the bytes of a response.
+type sizeWriter struct {
+	http.ResponseWriter
+	code int
+	size int
+}
+
+func (w *sizeWriter) WriteHeader(code int) {
+	w.code = code
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *sizeWriter) Write(b []byte) (int, error) {
+	n, err := w.ResponseWriter.Write(b)
+	w.size += n
+	return n, err
+}
+
+func (w *sizeWriter) Flush() {
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// streaming counts a client streaming the rounds, until the returned function
+// is called.
+func (h *handler) streaming(kind string) func() {
+	g := metrics.HTTPStreams.WithLabelValues(kind, h.chainLabel())
+	g.Inc()
+	return g.Dec
+}
+
+// observeUpstream records a request of the relay to its client, whose latency
+// includes the rounds served by the cache of the client.
+func observeUpstream(chain, operation string, start time.Time, err error) {
+	outcome := "ok"
+	if err != nil {
+		outcome = "error"
+	}
+	metrics.HTTPUpstreamLatency.WithLabelValues(chain, operation, outcome).Observe(time.Since(start).Seconds())
+}
+
+// get fetches a round from the client of the relay.
+func (h *handler) get(ctx context.Context, round uint64) (client.Result, error) {
+	ctx, span := h.tracer.StartSpan(ctx, "upstream get", otlp.KindClient)
+	span.SetAttribute("drand.round", round)
+	start := time.Now()
+	r, err := h.client.Get(ctx, round)
+	observeUpstream(h.chainLabel(), "get", start, err)
+	span.End(err)
+	return r, err
+}
+
+// updateCacheRatio reports the ratio of the rounds served from the cache of
+// the client.
+func (h *handler) updateCacheRatio() {
+	if s, ok := client.StatsOf(h.client); ok {
+		metrics.HTTPCacheHitRatio.WithLabelValues(h.chainLabel()).Set(s.CacheHitRate())
+	}
+}
@@ -0,0 +1,19 @@
+package http
+
+import "testing"
+
+func TestEndpointOf(t *testing.T) {
+	for path, endpoint := range map[string]string{
+		"/public/latest":           "/public/latest",
+		"/public/1234":             "/public/{round}",
+		"/proof/1234":              "/proof/{round}",
+		"/info":                    "/info",
+		"/drand.Public/PublicRand": "/drand.Public/PublicRand",
+		"/drand.Public/Home":       "other",
+		"/favicon.ico":             "other",
+	} {
+		if got := endpointOf(path); got != endpoint {
+			t.Fatalf("expected %s to be the endpoint %s, got %s", path, endpoint, got)
+		}
+	}
+}
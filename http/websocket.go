@@ -0,0 +1,63 @@
+package http
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/drand/drand/client"
+	"github.com/gorilla/websocket"
+
+	json "github.com/nikkolasg/hexjson"
+)
+
+const wsWriteTimeout = 5 * time.Second
+
+var upgrader = websocket.Upgrader{
+	// the relay serves public data to any origin, as the other endpoints do.
+	CheckOrigin: func(r *http.Request) bool { return true },
+}
+
+// WebSocket streams new rounds of randomness to the client over a websocket,
+// as JSON encoded messages. The optional `from` query parameter resumes the
+// stream at the given round.
+func (h *handler) WebSocket(w http.ResponseWriter, r *http.Request) {
+	from, err := streamStart(r)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		h.log.Warn("http_server", "failed to parse stream round", "client", r.RemoteAddr, "req", url.PathEscape(r.URL.RawQuery), "err", err)
+		return
+	}
+
+	conn, err := upgrader.Upgrade(w, r, http.Header{"Server": []string{h.version}})
+	if err != nil {
+		h.log.Warn("http_server", "failed to upgrade websocket", "client", r.RemoteAddr, "err", err)
+		return
+	}
+	defer conn.Close()
+	defer h.streaming("websocket")()
+
+	ctx, cancel := context.WithCancel(r.Context())
+	defer cancel()
+	// the client is not expected to send anything: reading only serves to
+	// notice when the connection goes away.
+	go func() {
+		defer cancel()
+		for {
+			if _, _, err := conn.NextReader(); err != nil {
+				return
+			}
+		}
+	}()
+
+	err = h.streamRounds(ctx, from, func(res client.Result) error {
+		b, err := json.Marshal(res)
+		if err != nil {
+			return err
+		}
+		_ = conn.SetWriteDeadline(time.Now().Add(wsWriteTimeout))
+		return conn.WriteMessage(websocket.TextMessage, b)
+	})
+	h.log.Debug("http_server", "websocket stream ended", "client", r.RemoteAddr, "err", err)
+}
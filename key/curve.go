@@ -2,40 +2,99 @@ package key
 
 import (
 	"crypto/cipher"
+	"fmt"
+	"sync"
 
 	kyber "github.com/drand/kyber"
 	bls "github.com/drand/kyber-bls12381"
+	"github.com/drand/kyber/pairing"
+	"github.com/drand/kyber/pairing/bn256"
 
-	sign "github.com/drand/kyber/sign/bls"
+	"github.com/drand/kyber/sign"
+	signbls "github.com/drand/kyber/sign/bls"
 	"github.com/drand/kyber/sign/schnorr"
 	"github.com/drand/kyber/sign/tbls"
 	"github.com/drand/kyber/util/random"
 )
 
+// Suite holds the groups and the signature schemes of the keys, the shares and
+// the signatures of the nodes of a group. The keys and the signatures are
+// always on different groups of the pairing.
+type Suite struct {
+	// Pairing is the pairing of the curve.
+	Pairing pairing.Suite
+	// KeyGroup is the group of the keys of the nodes and of the distributed
+	// key.
+	KeyGroup kyber.Group
+	// SigGroup is the group of the signatures of the beacons.
+	SigGroup kyber.Group
+	// ThresholdScheme signs the beacons with the shares of the distributed
+	// key.
+	ThresholdScheme sign.ThresholdScheme
+	// AuthScheme signs the identities of the nodes.
+	AuthScheme sign.Scheme
+	// DKGAuthScheme authenticates the packets of the DKG.
+	DKGAuthScheme sign.Scheme
+}
+
+// newSuite returns the suite of a pairing, with the keys on G1 or on G2.
+func newSuite(p pairing.Suite, keysOnG1 bool) *Suite {
+	s := &Suite{Pairing: p}
+	if keysOnG1 {
+		s.KeyGroup, s.SigGroup = p.G1(), p.G2()
+		s.ThresholdScheme = tbls.NewThresholdSchemeOnG2(p)
+		s.AuthScheme = signbls.NewSchemeOnG2(p)
+	} else {
+		s.KeyGroup, s.SigGroup = p.G2(), p.G1()
+		s.ThresholdScheme = tbls.NewThresholdSchemeOnG1(p)
+		s.AuthScheme = signbls.NewSchemeOnG1(p)
+	}
+	s.DKGAuthScheme = schnorr.NewScheme(&schnorrSuite{s.KeyGroup})
+	return s
+}
+
+var (
+	// DefaultSuite is the suite of the default scheme: BLS12-381 with the keys
+	// on G1 and the signatures on G2.
+	DefaultSuite = newSuite(bls.NewBLS12381Suite(), true)
+	// G1SigSuite is BLS12-381 with the keys on G2 and the shorter signatures
+	// on G1. Its messages are hashed to G1 with the tag of the G2
+	// ciphersuite, "BLS_SIG_BLS12381G2_XMD:SHA-256_SSWU_RO_NUL_", rather than
+	// the G1 one, so its signatures are not interoperable with other BLS
+	// implementations.
+	G1SigSuite = newSuite(bls.NewBLS12381Suite(), false)
+	// BN254Suite is BN254 with the keys on G2 and the signatures on G1, the
+	// only group of the curve messages can be hashed to. Its messages are
+	// hashed by try-and-increment, without domain separation tag, instead of
+	// a standard hash to curve, so its signatures are not interoperable with
+	// other BN254 implementations.
+	BN254Suite = newSuite(bn256.NewSuite(), false)
+)
+
 // TODO: global variables are evil, make that a config
 
 // Pairing is the main pairing suite used by drand. New interesting curves
 // should be allowed by drand, such as BLS12-381.
-var Pairing = bls.NewBLS12381Suite()
+var Pairing = DefaultSuite.Pairing
 
 // KeyGroup is the group used to create the keys
-var KeyGroup = Pairing.G1()
+var KeyGroup = DefaultSuite.KeyGroup
 
 // SigGroup is the group used to create the signatures; it must always be
 // different than KeyGroup: G1 key group and G2 sig group or G1 sig group and G2
 // keygroup.
-var SigGroup = Pairing.G2()
+var SigGroup = DefaultSuite.SigGroup
 
 // Scheme is the signature scheme used, defining over which curve the signature
 // and keys respectively are.
-var Scheme = tbls.NewThresholdSchemeOnG2(Pairing)
+var Scheme = DefaultSuite.ThresholdScheme
 
 // AuthScheme is the signature scheme used to identify public identities
-var AuthScheme = sign.NewSchemeOnG2(Pairing)
+var AuthScheme = DefaultSuite.AuthScheme
 
 // DKGAuthScheme is the signature scheme used to authentify packets during
 // a broadcast during a DKG
-var DKGAuthScheme = schnorr.NewScheme(&schnorrSuite{KeyGroup})
+var DKGAuthScheme = DefaultSuite.DKGAuthScheme
 
 type schnorrSuite struct {
 	kyber.Group
@@ -44,3 +103,31 @@ type schnorrSuite struct {
 func (s *schnorrSuite) RandomStream() cipher.Stream {
 	return random.New()
 }
+
+var (
+	suitesLk sync.RWMutex
+	suites   = map[string]*Suite{}
+)
+
+// RegisterSuite sets the suite of the keys of the scheme with the given ID.
+// The chain package registers the suites of the schemes it knows.
+func RegisterSuite(schemeID string, s *Suite) {
+	suitesLk.Lock()
+	defer suitesLk.Unlock()
+	suites[schemeID] = s
+}
+
+// SchemeSuite returns the suite of the keys of the scheme with the given ID,
+// the empty ID designating the default scheme.
+func SchemeSuite(schemeID string) (*Suite, error) {
+	if schemeID == "" {
+		return DefaultSuite, nil
+	}
+	suitesLk.RLock()
+	defer suitesLk.RUnlock()
+	s, ok := suites[schemeID]
+	if !ok {
+		return nil, fmt.Errorf("unknown scheme %q", schemeID)
+	}
+	return s, nil
+}
@@ -0,0 +1,129 @@
+package key
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Epoch is a span of rounds of a chain produced at the same period, from its
+// first round until the first round of the next epoch. A chain starts with a
+// single epoch at its genesis, and a resharing changing the period starts a
+// new epoch at its transition round.
+type Epoch struct {
+	// Round is the first round of the epoch.
+	Round uint64
+	// Time is the time of the first round of the epoch.
+	Time int64
+	// Period is the period of the rounds of the epoch.
+	Period time.Duration
+}
+
+// Schedule returns the epochs of the chain of the group: the ones recorded in
+// the group if the period changed, a single epoch starting at the genesis
+// otherwise.
+func (g *Group) Schedule() []*Epoch {
+	if len(g.Epochs) > 0 {
+		return g.Epochs
+	}
+	return []*Epoch{{Round: 1, Time: g.GenesisTime, Period: g.Period}}
+}
+
+// GenesisPeriod returns the period of the chain of the group at its genesis,
+// which is the period part of the chain information.
+func (g *Group) GenesisPeriod() time.Duration {
+	return g.Schedule()[0].Period
+}
+
+// CheckEpochs returns an error if the epochs are not a valid schedule: the
+// first one starts at round 1, and each one starts after the previous one
+// with a positive period.
+func CheckEpochs(epochs []*Epoch) error {
+	for i, e := range epochs {
+		if e.Period < time.Second {
+			return fmt.Errorf("epoch %d: invalid period %s", i, e.Period)
+		}
+		if i == 0 {
+			if e.Round != 1 {
+				return errors.New("first epoch does not start at round 1")
+			}
+			continue
+		}
+		prev := epochs[i-1]
+		if e.Round <= prev.Round || e.Time <= prev.Time {
+			return fmt.Errorf("epoch %d does not start after the previous one", i)
+		}
+	}
+	return nil
+}
+
+// EqualEpochs returns true if both schedules are the same.
+func EqualEpochs(e1, e2 []*Epoch) bool {
+	if len(e1) != len(e2) {
+		return false
+	}
+	for i := range e1 {
+		if *e1[i] != *e2[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// EpochTOML is the TOML representation of an epoch.
+type EpochTOML struct {
+	Round  uint64
+	Time   int64
+	Period string
+}
+
+// TOML returns the TOML representation of the epoch.
+func (e *Epoch) TOML() interface{} {
+	return &EpochTOML{Round: e.Round, Time: e.Time, Period: e.Period.String()}
+}
+
+// FromTOML decodes the epoch from its TOML representation.
+func (e *Epoch) FromTOML(i interface{}) error {
+	et, ok := i.(*EpochTOML)
+	if !ok {
+		return errors.New("invalid struct received for epoch")
+	}
+	period, err := time.ParseDuration(et.Period)
+	if err != nil {
+		return err
+	}
+	e.Round = et.Round
+	e.Time = et.Time
+	e.Period = period
+	return nil
+}
+
+// TOMLValue returns an empty TOML-compatible value of the epoch.
+func (e *Epoch) TOMLValue() interface{} {
+	return &EpochTOML{}
+}
+
+type epochJSON struct {
+	Round uint64 `json:"round"`
+	Time  int64  `json:"time"`
+	// Period is in seconds, as the period of the chain information.
+	Period uint32 `json:"period"`
+}
+
+// MarshalJSON encodes the epoch with its period in seconds.
+func (e *Epoch) MarshalJSON() ([]byte, error) {
+	return json.Marshal(&epochJSON{Round: e.Round, Time: e.Time, Period: uint32(e.Period.Seconds())})
+}
+
+// UnmarshalJSON decodes an epoch encoded by MarshalJSON.
+func (e *Epoch) UnmarshalJSON(data []byte) error {
+	ej := new(epochJSON)
+	if err := json.Unmarshal(data, ej); err != nil {
+		return err
+	}
+	e.Round = ej.Round
+	e.Time = ej.Time
+	e.Period = time.Duration(ej.Period) * time.Second
+	return nil
+}
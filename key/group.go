@@ -27,7 +27,8 @@ var hashFunc = func() hash.Hash { h, _ := blake2b.New256(nil); return h }
 type Group struct {
 	// Threshold to setup during the DKG or resharing protocol.
 	Threshold int
-	// Period to use for the beacon randomness generation
+	// Period to use for the beacon randomness generation. When a resharing
+	// changed the period, it is the period from the transition time on.
 	Period time.Duration
 	// CatchupPeriod is a delay to insert while in a catchup mode
 	// also can be thought of as the minimum period allowed between
@@ -48,6 +49,39 @@ type Group struct {
 	// The distributed public key of this group. It is nil if the group has not
 	// ran a DKG protocol yet.
 	PublicKey *DistPublic
+	// Epochs are the periods of the chain since its genesis, set once a
+	// resharing changed the period, the last one being the current period.
+	// See Schedule.
+	Epochs []*Epoch
+	// Scheme is the ID of the scheme the keys of the nodes are generated for,
+	// empty for the default scheme. A new group is of the scheme of the key of
+	// its leader, and a resharing keeps the scheme of the old group.
+	Scheme string
+	// Derivation is the ID of the randomness derivation of the chain, empty
+	// for the default derivation. The leader of a new group chooses it, and a
+	// resharing keeps the derivation of the old group.
+	Derivation string
+}
+
+// Suite returns the suite of the keys of the nodes and of the distributed key,
+// given by the scheme of the group.
+func (g *Group) Suite() (*Suite, error) {
+	return SchemeSuite(g.Scheme)
+}
+
+// checkNodeSuites returns an error unless the keys of all nodes are on the key
+// group of the suite of the group.
+func (g *Group) checkNodeSuites(suite *Suite) error {
+	for _, n := range g.Nodes {
+		s, err := n.Suite()
+		if err != nil {
+			return fmt.Errorf("node %s: %v", n.Address(), err)
+		}
+		if s != suite {
+			return fmt.Errorf("node %s has a key of scheme %q, not of the scheme %q of the group", n.Address(), n.Scheme, g.Scheme)
+		}
+	}
+	return nil
 }
 
 // Find returns the Node that is equal to the given identity (without the
@@ -137,12 +171,21 @@ func (g *Group) Equal(g2 *Group) bool {
 	if g.Len() != g2.Len() {
 		return false
 	}
-	if !bytes.Equal(g.GetGenesisSeed(), g2.GetGenesisSeed()) {
+	if !ConstantTimeEqual(g.GetGenesisSeed(), g2.GetGenesisSeed()) {
 		return false
 	}
 	if g.TransitionTime != g2.TransitionTime {
 		return false
 	}
+	if g.Scheme != g2.Scheme {
+		return false
+	}
+	if g.Derivation != g2.Derivation {
+		return false
+	}
+	if !EqualEpochs(g.Epochs, g2.Epochs) {
+		return false
+	}
 	for i := 0; i < g.Len(); i++ {
 		if !g.Nodes[i].Equal(g2.Nodes[i]) {
 			return false
@@ -172,6 +215,9 @@ type GroupTOML struct {
 	TransitionTime int64           `toml:",omitempty"`
 	GenesisSeed    string          `toml:",omitempty"`
 	PublicKey      *DistPublicTOML `toml:",omitempty"`
+	Epochs         []*EpochTOML    `toml:",omitempty"`
+	Scheme         string          `toml:",omitempty"`
+	Derivation     string          `toml:",omitempty"`
 }
 
 // FromTOML decodes the group from the toml struct
@@ -180,14 +226,28 @@ func (g *Group) FromTOML(i interface{}) (err error) {
 	if !ok {
 		return fmt.Errorf("grouptoml unknown")
 	}
+	suite, err := SchemeSuite(gt.Scheme)
+	if err != nil {
+		return fmt.Errorf("group: %v", err)
+	}
+	g.Scheme = gt.Scheme
+	g.Derivation = gt.Derivation
 	g.Threshold = gt.Threshold
 	g.Nodes = make([]*Node, len(gt.Nodes))
 	for i, ptoml := range gt.Nodes {
+		// the nodes of groups written before the nodes recorded their
+		// scheme are of the scheme of the group
+		if ptoml.PublicTOML != nil && ptoml.Scheme == "" {
+			ptoml.Scheme = gt.Scheme
+		}
 		g.Nodes[i] = new(Node)
 		if err := g.Nodes[i].FromTOML(ptoml); err != nil {
 			return fmt.Errorf("group: unwrapping node[%d]: %v", i, err)
 		}
 	}
+	if err := g.checkNodeSuites(suite); err != nil {
+		return fmt.Errorf("group: %v", err)
+	}
 
 	if g.Threshold < dkg.MinimumT(len(gt.Nodes)) {
 		return errors.New("group file have threshold 0")
@@ -197,7 +257,7 @@ func (g *Group) FromTOML(i interface{}) (err error) {
 
 	if gt.PublicKey != nil {
 		// dist key only if dkg ran
-		g.PublicKey = &DistPublic{}
+		g.PublicKey = &DistPublic{Scheme: g.Scheme}
 		if err = g.PublicKey.FromTOML(gt.PublicKey); err != nil {
 			return fmt.Errorf("group: unwrapping distributed public key: %v", err)
 		}
@@ -223,6 +283,17 @@ func (g *Group) FromTOML(i interface{}) (err error) {
 			return fmt.Errorf("group: decoding genesis seed %v", err)
 		}
 	}
+	g.Epochs = nil
+	for i, etoml := range gt.Epochs {
+		e := new(Epoch)
+		if err := e.FromTOML(etoml); err != nil {
+			return fmt.Errorf("group: unwrapping epoch[%d]: %v", i, err)
+		}
+		g.Epochs = append(g.Epochs, e)
+	}
+	if err := CheckEpochs(g.Epochs); err != nil {
+		return fmt.Errorf("group: %v", err)
+	}
 	return nil
 }
 
@@ -246,6 +317,11 @@ func (g *Group) TOML() interface{} {
 		gtoml.TransitionTime = g.TransitionTime
 	}
 	gtoml.GenesisSeed = hex.EncodeToString(g.GetGenesisSeed())
+	for _, e := range g.Epochs {
+		gtoml.Epochs = append(gtoml.Epochs, e.TOML().(*EpochTOML))
+	}
+	gtoml.Scheme = g.Scheme
+	gtoml.Derivation = g.Derivation
 	return gtoml
 }
 
@@ -315,6 +391,10 @@ func MinimumT(n int) int {
 
 // GroupFromProto convertes a protobuf group into a local Group object
 func GroupFromProto(g *proto.GroupPacket) (*Group, error) {
+	suite, err := SchemeSuite(g.GetScheme())
+	if err != nil {
+		return nil, err
+	}
 	var nodes = make([]*Node, 0, len(g.GetNodes()))
 	for _, id := range g.GetNodes() {
 		kid, err := NodeFromProto(id)
@@ -337,9 +417,9 @@ func GroupFromProto(g *proto.GroupPacket) (*Group, error) {
 		return nil, fmt.Errorf("period time is zero")
 	}
 	catchupPeriod := time.Duration(g.GetCatchupPeriod()) * time.Second
-	var dist = new(DistPublic)
+	var dist = &DistPublic{Scheme: g.GetScheme()}
 	for _, coeff := range g.DistKey {
-		c := KeyGroup.Point()
+		c := suite.KeyGroup.Point()
 		if err := c.UnmarshalBinary(coeff); err != nil {
 			return nil, fmt.Errorf("invalid distributed key coefficients:%v", err)
 		}
@@ -352,6 +432,11 @@ func GroupFromProto(g *proto.GroupPacket) (*Group, error) {
 		Nodes:          nodes,
 		GenesisTime:    genesisTime,
 		TransitionTime: int64(g.GetTransitionTime()),
+		Scheme:         g.GetScheme(),
+		Derivation:     g.GetDerivation(),
+	}
+	if err := group.checkNodeSuites(suite); err != nil {
+		return nil, err
 	}
 	if g.GetGenesisSeed() != nil {
 		group.GenesisSeed = g.GetGenesisSeed()
@@ -377,6 +462,7 @@ func (g *Group) ToProto() *proto.GroupPacket {
 				Tls:       id.IsTLS(),
 				Key:       key,
 				Signature: id.Signature,
+				Scheme:    id.Scheme,
 			},
 			Index: id.Index,
 		}
@@ -388,6 +474,8 @@ func (g *Group) ToProto() *proto.GroupPacket {
 	out.GenesisTime = uint64(g.GenesisTime)
 	out.TransitionTime = uint64(g.TransitionTime)
 	out.GenesisSeed = g.GetGenesisSeed()
+	out.Scheme = g.Scheme
+	out.Derivation = g.Derivation
 	if g.PublicKey != nil {
 		var coeffs = make([][]byte, len(g.PublicKey.Coefficients))
 		for i, c := range g.PublicKey.Coefficients {
@@ -36,7 +36,7 @@ func TestGroupProtobuf(t *testing.T) {
 	ids := newIds(n)
 
 	dpub := []kyber.Point{KeyGroup.Point().Pick(random.New())}
-	group := LoadGroup(ids, 1, &DistPublic{dpub}, 30*time.Second, 61)
+	group := LoadGroup(ids, 1, &DistPublic{Coefficients: dpub}, 30*time.Second, 61)
 	group.Threshold = thr
 	group.Period = time.Second * 4
 	group.GenesisTime = time.Now().Add(10 * time.Second).Unix()
@@ -55,7 +55,7 @@ func TestGroupProtobuf(t *testing.T) {
 		dpub2 = append(dpub2, KeyGroup.Point().Pick(random.New()))
 	}
 	group2 := *group
-	group2.PublicKey = &DistPublic{dpub2}
+	group2.PublicKey = &DistPublic{Coefficients: dpub2}
 	vectors = append(vectors, testVector{
 		group:  &group2,
 		change: nil,
@@ -99,7 +99,7 @@ func TestGroupProtobuf(t *testing.T) {
 
 func TestGroupUnsignedIdentities(t *testing.T) {
 	ids := newIds(5)
-	group := LoadGroup(ids, 1, &DistPublic{[]kyber.Point{KeyGroup.Point()}}, 30*time.Second, 61)
+	group := LoadGroup(ids, 1, &DistPublic{Coefficients: []kyber.Point{KeyGroup.Point()}}, 30*time.Second, 61)
 	require.Nil(t, group.UnsignedIdentities())
 
 	ids[0].Signature = nil
@@ -112,11 +112,12 @@ func TestGroupSaveLoad(t *testing.T) {
 	n := 3
 	ids := newIds(n)
 	dpub := []kyber.Point{KeyGroup.Point().Pick(random.New())}
-	group := LoadGroup(ids, 1, &DistPublic{dpub}, 30*time.Second, 61)
+	group := LoadGroup(ids, 1, &DistPublic{Coefficients: dpub}, 30*time.Second, 61)
 	group.Threshold = 3
 	group.Period = time.Second * 4
 	group.GenesisTime = time.Now().Add(10 * time.Second).Unix()
 	group.TransitionTime = time.Now().Add(10 * time.Second).Unix()
+	group.Derivation = "blake3-256"
 
 	genesis := group.GenesisTime
 	transition := group.TransitionTime
@@ -145,6 +146,7 @@ func TestGroupSaveLoad(t *testing.T) {
 	require.Equal(t, seed, loaded.GetGenesisSeed())
 	require.Equal(t, genesis, loaded.GenesisTime)
 	require.Equal(t, transition, loaded.TransitionTime)
+	require.Equal(t, group.Derivation, loaded.Derivation)
 
 	require.Equal(t, group.Hash(), loaded.Hash())
 }
@@ -164,9 +166,40 @@ func TestConvertGroup(t *testing.T) {
 	group.Period = 5 * time.Second
 	group.TransitionTime = time.Now().Unix()
 	group.GenesisTime = time.Now().Unix()
+	group.Derivation = "sha3-256"
 
 	proto := group.ToProto()
 	received, err := GroupFromProto(proto)
 	require.NoError(t, err)
 	require.True(t, received.Equal(group))
+	require.Equal(t, group.Derivation, received.Derivation)
+}
+
+func TestGroupEpochs(t *testing.T) {
+	group := LoadGroup(newIds(3), 1, &DistPublic{Coefficients: []kyber.Point{KeyGroup.Point().Pick(random.New())}}, 30*time.Second, 0)
+	group.Threshold = 2
+	group.GenesisTime = 1000
+	require.Equal(t, []*Epoch{{Round: 1, Time: 1000, Period: 30 * time.Second}}, group.Schedule())
+
+	group.Period = 10 * time.Second
+	group.TransitionTime = 1300
+	group.Epochs = []*Epoch{
+		{Round: 1, Time: 1000, Period: 30 * time.Second},
+		{Round: 11, Time: 1300, Period: 10 * time.Second},
+	}
+	require.Equal(t, 30*time.Second, group.GenesisPeriod())
+
+	groupFile, err := ioutil.TempFile("", "group.toml")
+	require.NoError(t, err)
+	groupPath := groupFile.Name()
+	groupFile.Close()
+	defer os.RemoveAll(groupPath)
+	require.NoError(t, Save(groupPath, group, false))
+	loaded := &Group{}
+	require.NoError(t, Load(groupPath, loaded))
+	require.Equal(t, group.Epochs, loaded.Epochs)
+	require.True(t, group.Equal(loaded))
+
+	require.Error(t, CheckEpochs([]*Epoch{{Round: 2, Time: 1000, Period: time.Second}}))
+	require.Error(t, CheckEpochs([]*Epoch{group.Epochs[1], group.Epochs[0]}))
 }
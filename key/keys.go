@@ -30,6 +30,15 @@ type Identity struct {
 	Addr      string
 	TLS       bool
 	Signature []byte
+	// Scheme is the ID of the scheme of the chains the key is generated for,
+	// empty for the default scheme. It decides the groups of the key and of
+	// the signatures of the node, see Suite.
+	Scheme string
+}
+
+// Suite returns the suite of the key, given by its scheme.
+func (i *Identity) Suite() (*Suite, error) {
+	return SchemeSuite(i.Scheme)
 }
 
 // Address implements the net.Peer interface
@@ -58,8 +67,12 @@ func (i *Identity) Hash() []byte {
 // ValidSignature returns true if the signature included in this identity is
 // correct or not
 func (i *Identity) ValidSignature() error {
+	suite, err := i.Suite()
+	if err != nil {
+		return err
+	}
 	msg := i.Hash()
-	return AuthScheme.Verify(i.Key, msg, i.Signature)
+	return suite.AuthScheme.Verify(i.Key, msg, i.Signature)
 }
 
 // Equal indicates if two identities are equal
@@ -76,21 +89,42 @@ func (i *Identity) Equal(i2 *Identity) bool {
 	return true
 }
 
-// SelfSign signs the public key with the key pair
+// SelfSign signs the public key with the key pair. The signature is left
+// empty if the scheme of the key is unknown.
 func (p *Pair) SelfSign() {
+	suite, err := p.Public.Suite()
+	if err != nil {
+		p.Public.Signature = nil
+		return
+	}
 	msg := p.Public.Hash()
-	signature, _ := AuthScheme.Sign(p.Key, msg)
+	signature, _ := suite.AuthScheme.Sign(p.Key, msg)
 	p.Public.Signature = signature
 }
 
 // NewKeyPair returns a freshly created private / public key pair. The group is
 // decided by the group variable by default.
 func NewKeyPair(address string) *Pair {
-	key := KeyGroup.Scalar().Pick(random.New())
-	pubKey := KeyGroup.Point().Mul(key, nil)
+	return newKeyPair(address, "", DefaultSuite)
+}
+
+// NewSchemeKeyPair returns a freshly created private / public key pair for the
+// scheme with the given ID, on the key group of its suite.
+func NewSchemeKeyPair(address, schemeID string) (*Pair, error) {
+	suite, err := SchemeSuite(schemeID)
+	if err != nil {
+		return nil, err
+	}
+	return newKeyPair(address, schemeID, suite), nil
+}
+
+func newKeyPair(address, schemeID string, suite *Suite) *Pair {
+	key := suite.KeyGroup.Scalar().Pick(random.New())
+	pubKey := suite.KeyGroup.Point().Mul(key, nil)
 	pub := &Identity{
-		Key:  pubKey,
-		Addr: address,
+		Key:    pubKey,
+		Addr:   address,
+		Scheme: schemeID,
 	}
 	p := &Pair{
 		Key:    key,
@@ -111,7 +145,8 @@ func NewTLSKeyPair(address string) *Pair {
 
 // PairTOML is the TOML-able version of a private key
 type PairTOML struct {
-	Key string
+	Key    string
+	Scheme string `toml:",omitempty"`
 }
 
 // PublicTOML is the TOML-able version of a public key
@@ -120,12 +155,17 @@ type PublicTOML struct {
 	Key       string
 	TLS       bool
 	Signature string
+	Scheme    string `toml:",omitempty"`
 }
 
 // TOML returns a struct that can be marshaled using a TOML-encoding library
 func (p *Pair) TOML() interface{} {
 	hexKey := ScalarToString(p.Key)
-	return &PairTOML{hexKey}
+	ptoml := &PairTOML{Key: hexKey}
+	if p.Public != nil {
+		ptoml.Scheme = p.Public.Scheme
+	}
+	return ptoml
 }
 
 // FromTOML constructs the private key from an unmarshalled structure from TOML
@@ -135,9 +175,12 @@ func (p *Pair) FromTOML(i interface{}) error {
 		return errors.New("private can't decode toml from non PairTOML struct")
 	}
 
-	var err error
-	p.Key, err = StringToScalar(KeyGroup, ptoml.Key)
-	p.Public = new(Identity)
+	suite, err := SchemeSuite(ptoml.Scheme)
+	if err != nil {
+		return fmt.Errorf("private key: %s", err)
+	}
+	p.Key, err = StringToScalar(suite.KeyGroup, ptoml.Key)
+	p.Public = &Identity{Scheme: ptoml.Scheme}
 	return err
 }
 
@@ -152,13 +195,17 @@ func (i *Identity) FromTOML(t interface{}) error {
 	if !ok {
 		return errors.New("public can't decode from non PublicTOML struct")
 	}
-	var err error
-	i.Key, err = StringToPoint(KeyGroup, ptoml.Key)
+	suite, err := SchemeSuite(ptoml.Scheme)
+	if err != nil {
+		return fmt.Errorf("public key: %s", err)
+	}
+	i.Key, err = StringToPoint(suite.KeyGroup, ptoml.Key)
 	if err != nil {
 		return fmt.Errorf("decoding public key: %s", err)
 	}
 	i.Addr = ptoml.Address
 	i.TLS = ptoml.TLS
+	i.Scheme = ptoml.Scheme
 	if ptoml.Signature != "" {
 		i.Signature, err = hex.DecodeString(ptoml.Signature)
 	}
@@ -173,6 +220,7 @@ func (i *Identity) TOML() interface{} {
 		Key:       hexKey,
 		TLS:       i.TLS,
 		Signature: hex.EncodeToString(i.Signature),
+		Scheme:    i.Scheme,
 	}
 }
 
@@ -205,7 +253,11 @@ func IdentityFromProto(n *proto.Identity) (*Identity, error) {
 	if err != nil {
 		return nil, err
 	}
-	public := KeyGroup.Point()
+	suite, err := SchemeSuite(n.GetScheme())
+	if err != nil {
+		return nil, err
+	}
+	public := suite.KeyGroup.Point()
 	if err := public.UnmarshalBinary(n.GetKey()); err != nil {
 		return nil, err
 	}
@@ -215,6 +267,7 @@ func IdentityFromProto(n *proto.Identity) (*Identity, error) {
 		TLS:       n.Tls,
 		Key:       public,
 		Signature: n.GetSignature(),
+		Scheme:    n.GetScheme(),
 	}
 	return id, nil
 }
@@ -227,17 +280,28 @@ func (i *Identity) ToProto() *proto.Identity {
 		Key:       buff,
 		Tls:       i.TLS,
 		Signature: i.Signature,
+		Scheme:    i.Scheme,
 	}
 }
 
 // Share represents the private information that a node holds after a successful
 // DKG. This information MUST stay private !
-type Share dkg.DistKeyShare
+type Share struct {
+	dkg.DistKeyShare
+	// Scheme is the ID of the scheme of the group of the share, empty for the
+	// default scheme.
+	Scheme string
+}
+
+// Suite returns the suite of the share, given by its scheme.
+func (s *Share) Suite() (*Suite, error) {
+	return SchemeSuite(s.Scheme)
+}
 
 // PubPoly returns the public polynomial that can be used to verify any
 // individual patial signature
 func (s *Share) PubPoly() *share.PubPoly {
-	return share.NewPubPoly(KeyGroup, KeyGroup.Point().Base(), s.Commits)
+	return s.Public().PubPoly()
 }
 
 // PrivateShare returns the private share used to produce a partial signature
@@ -248,7 +312,7 @@ func (s *Share) PrivateShare() *share.PriShare {
 // Public returns the distributed public key associated with the distributed key
 // share
 func (s *Share) Public() *DistPublic {
-	return &DistPublic{s.Commits}
+	return &DistPublic{Coefficients: s.Commits, Scheme: s.Scheme}
 }
 
 // TOML returns a TOML-compatible version of this share
@@ -260,6 +324,7 @@ func (s *Share) TOML() interface{} {
 	}
 	dtoml.Share = ScalarToString(s.Share.V)
 	dtoml.Index = s.Share.I
+	dtoml.Scheme = s.Scheme
 	return dtoml
 }
 
@@ -269,16 +334,21 @@ func (s *Share) FromTOML(i interface{}) error {
 	if !ok {
 		return errors.New("invalid struct received for share")
 	}
+	suite, err := SchemeSuite(t.Scheme)
+	if err != nil {
+		return fmt.Errorf("share: %s", err)
+	}
+	s.Scheme = t.Scheme
 	s.Commits = make([]kyber.Point, len(t.Commits))
 	for i, c := range t.Commits {
-		p, err := StringToPoint(KeyGroup, c)
+		p, err := StringToPoint(suite.KeyGroup, c)
 		if err != nil {
 			return fmt.Errorf("share.Commit[%d] corruputed: %s", i, err)
 		}
 		s.Commits[i] = p
 	}
 
-	sshare, err := StringToScalar(KeyGroup, t.Share)
+	sshare, err := StringToScalar(suite.KeyGroup, t.Share)
 	if err != nil {
 		return fmt.Errorf("share.Share corrupted: %s", err)
 	}
@@ -302,6 +372,8 @@ type ShareTOML struct {
 	// coefficients of the individual private polynomial generated by the node
 	// at the given index.
 	PrivatePoly []string
+	// scheme of the group of the share, empty for the default scheme.
+	Scheme string `toml:",omitempty"`
 }
 
 // DistPublic represents the distributed public key generated during a DKG. This
@@ -310,11 +382,20 @@ type ShareTOML struct {
 // private distributed polynomial.
 type DistPublic struct {
 	Coefficients []kyber.Point
+	// Scheme is the ID of the scheme of the group of the key, empty for the
+	// default scheme. It is not part of the TOML description, which is read
+	// with the scheme of the group.
+	Scheme string
 }
 
-// PubPoly provides the public polynomial commitment
+// PubPoly provides the public polynomial commitment. It is nil if the scheme
+// of the key is unknown.
 func (d *DistPublic) PubPoly() *share.PubPoly {
-	return share.NewPubPoly(KeyGroup, KeyGroup.Point().Base(), d.Coefficients)
+	suite, err := SchemeSuite(d.Scheme)
+	if err != nil {
+		return nil
+	}
+	return share.NewPubPoly(suite.KeyGroup, suite.KeyGroup.Point().Base(), d.Coefficients)
 }
 
 // Key returns the first coefficient as representing the public key to be used
@@ -353,10 +434,13 @@ func (d *DistPublic) FromTOML(i interface{}) error {
 	if !ok {
 		return errors.New("wrong interface: expected DistPublicTOML")
 	}
+	suite, err := SchemeSuite(d.Scheme)
+	if err != nil {
+		return err
+	}
 	points := make([]kyber.Point, len(dtoml.Coefficients))
-	var err error
 	for i, s := range dtoml.Coefficients {
-		points[i], err = StringToPoint(KeyGroup, s)
+		points[i], err = StringToPoint(suite.KeyGroup, s)
 		if err != nil {
 			return err
 		}
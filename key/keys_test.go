@@ -130,6 +130,46 @@ func TestShare(t *testing.T) {
 	}
 }
 
+func TestKeyScheme(t *testing.T) {
+	RegisterSuite("test-bn254", BN254Suite)
+	kp, err := NewSchemeKeyPair(testAddr, "test-bn254")
+	require.NoError(t, err)
+	require.Equal(t, "test-bn254", kp.Public.Scheme)
+	require.Equal(t, BN254Suite.KeyGroup.PointLen(), kp.Public.Key.MarshalSize())
+	require.NoError(t, kp.Public.ValidSignature())
+
+	kp2 := new(Pair)
+	require.NoError(t, kp2.FromTOML(kp.TOML()))
+	require.Equal(t, "test-bn254", kp2.Public.Scheme)
+	require.NoError(t, kp2.Public.FromTOML(kp.Public.TOML()))
+	require.True(t, kp.Public.Key.Equal(kp2.Public.Key))
+	require.True(t, kp.Key.Equal(kp2.Key))
+
+	id, err := IdentityFromProto(kp.Public.ToProto())
+	require.NoError(t, err)
+	require.True(t, kp.Public.Equal(id))
+	require.NoError(t, id.ValidSignature())
+
+	// the key of a scheme does not pass for a key of the default scheme
+	unnamed := kp.Public.ToProto()
+	unnamed.Scheme = ""
+	_, err = IdentityFromProto(unnamed)
+	require.Error(t, err)
+
+	s := &Share{Scheme: "test-bn254"}
+	s.Commits = []kyber.Point{BN254Suite.KeyGroup.Point().Pick(random.New())}
+	s.Share = &share.PriShare{V: BN254Suite.KeyGroup.Scalar().Pick(random.New()), I: 0}
+	s2 := new(Share)
+	require.NoError(t, s2.FromTOML(s.TOML()))
+	require.Equal(t, "test-bn254", s2.Scheme)
+	require.True(t, s.Commits[0].Equal(s2.Commits[0]))
+	require.Equal(t, "test-bn254", s2.Public().Scheme)
+	require.NotNil(t, s2.PubPoly())
+
+	_, err = NewSchemeKeyPair(testAddr, "unknown")
+	require.Error(t, err)
+}
+
 func BatchIdentities(n int) ([]*Pair, *Group) {
 	startPort := 8000
 	startAddr := "127.0.0.1:"
@@ -145,7 +185,7 @@ func BatchIdentities(n int) ([]*Pair, *Group) {
 		}
 	}
 	fakeDistKey := KeyGroup.Point().Pick(random.New())
-	distKey := &DistPublic{[]kyber.Point{fakeDistKey}}
+	distKey := &DistPublic{Coefficients: []kyber.Point{fakeDistKey}}
 	group := &Group{
 		Threshold: DefaultThreshold(n),
 		Nodes:     pubs,
@@ -0,0 +1,73 @@
+package key
+
+import (
+	"crypto/subtle"
+
+	kyber "github.com/drand/kyber"
+	"github.com/drand/kyber/group/mod"
+)
+
+// SecureBytes holds secret material, such as an encoded private key or share.
+// It is never printed and must be wiped once used, so that the secret does not
+// linger in memory. Copies of the secret made from it, such as strings, are
+// not covered by Wipe.
+type SecureBytes []byte
+
+// Wipe overwrites the bytes with zeros.
+func (s SecureBytes) Wipe() {
+	for i := range s {
+		s[i] = 0
+	}
+}
+
+// Equal compares the bytes with b in constant time.
+func (s SecureBytes) Equal(b []byte) bool {
+	return ConstantTimeEqual(s, b)
+}
+
+// String does not reveal the bytes.
+func (s SecureBytes) String() string {
+	return "SecureBytes(redacted)"
+}
+
+// GoString does not reveal the bytes.
+func (s SecureBytes) GoString() string {
+	return s.String()
+}
+
+// ConstantTimeEqual compares signatures or hashes in a time that does not
+// depend on their content, so that comparing a secret value does not leak
+// how much of it an attacker guessed.
+func ConstantTimeEqual(a, b []byte) bool {
+	return subtle.ConstantTimeCompare(a, b) == 1
+}
+
+// WipeScalar sets a scalar to zero. When the scalar is a modular integer, as
+// the scalars of the key group, the memory holding its value is overwritten
+// rather than only released.
+func WipeScalar(s kyber.Scalar) {
+	if s == nil {
+		return
+	}
+	if i, ok := s.(*mod.Int); ok {
+		words := i.V.Bits()
+		for j := range words {
+			words[j] = 0
+		}
+	}
+	s.Zero()
+}
+
+// Wipe zeroes the private key of the pair. The pair must not be used to sign
+// afterwards.
+func (p *Pair) Wipe() {
+	WipeScalar(p.Key)
+}
+
+// Wipe zeroes the private share. The share must not be used to sign
+// afterwards.
+func (s *Share) Wipe() {
+	if s.Share != nil {
+		WipeScalar(s.Share.V)
+	}
+}
@@ -0,0 +1,31 @@
+package key
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/drand/kyber/share"
+	dkg "github.com/drand/kyber/share/dkg"
+	"github.com/drand/kyber/util/random"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSecureBytes(t *testing.T) {
+	s := SecureBytes("secret")
+	require.True(t, s.Equal([]byte("secret")))
+	require.False(t, s.Equal([]byte("secreT")))
+	require.NotContains(t, fmt.Sprintf("%v %s %#v", s, s, s), "secret")
+
+	s.Wipe()
+	require.Equal(t, SecureBytes(make([]byte, 6)), s)
+}
+
+func TestWipe(t *testing.T) {
+	pair := NewKeyPair("127.0.0.1:8080")
+	pair.Wipe()
+	require.True(t, pair.Key.Equal(KeyGroup.Scalar().Zero()))
+
+	sh := &Share{DistKeyShare: dkg.DistKeyShare{Share: &share.PriShare{I: 1, V: KeyGroup.Scalar().Pick(random.New())}}}
+	sh.Wipe()
+	require.True(t, sh.Share.V.Equal(KeyGroup.Scalar().Zero()))
+}
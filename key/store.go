@@ -1,7 +1,9 @@
 package key
 
 import (
+	"bytes"
 	"fmt"
+	"io/ioutil"
 	"os"
 	"path"
 	"reflect"
@@ -130,7 +132,10 @@ func (f *fileStore) Reset(...ResetOption) error {
 }
 
 // Save the given Tomler interface to the given path. If secure is true, the
-// file will have a 0700 security.
+// file will have a 0700 security and the encoded file content is wiped from
+// memory once written. The hexadecimal strings of the TOML value are
+// immutable and can not be wiped: they are only released to the garbage
+// collector.
 // TODO: move that to fs/
 func Save(filePath string, t Tomler, secure bool) error {
 	var fd *os.File
@@ -144,14 +149,31 @@ func Save(filePath string, t Tomler, secure bool) error {
 		return fmt.Errorf("config: can't save %s to %s: %s", reflect.TypeOf(t).String(), filePath, err)
 	}
 	defer fd.Close()
-	return toml.NewEncoder(fd).Encode(t.TOML())
+	if !secure {
+		return toml.NewEncoder(fd).Encode(t.TOML())
+	}
+	var buff bytes.Buffer
+	if err := toml.NewEncoder(&buff).Encode(t.TOML()); err != nil {
+		return err
+	}
+	content := SecureBytes(buff.Bytes())
+	defer content.Wipe()
+	_, err = fd.Write(content)
+	return err
 }
 
-// Load the given Tomler from the given file path.
+// Load the given Tomler from the given file path. The content of the file is
+// wiped from memory once decoded. As in Save, the strings of the decoded TOML
+// value are not wiped.
 func Load(filePath string, t Tomler) error {
+	buff, err := ioutil.ReadFile(filePath)
+	if err != nil {
+		return err
+	}
+	content := SecureBytes(buff)
+	defer content.Wipe()
 	tomlValue := t.TOMLValue()
-	var err error
-	if _, err = toml.DecodeFile(filePath, tomlValue); err != nil {
+	if _, err = toml.DecodeReader(bytes.NewReader(content), tomlValue); err != nil {
 		return err
 	}
 	return t.FromTOML(tomlValue)
@@ -7,6 +7,7 @@ import (
 
 	kyber "github.com/drand/kyber"
 	"github.com/drand/kyber/share"
+	dkg "github.com/drand/kyber/share/dkg"
 	"github.com/stretchr/testify/require"
 )
 
@@ -55,10 +56,10 @@ func TestKeysSaveLoad(t *testing.T) {
 	}
 
 	// test share / dist key
-	testShare := &Share{
+	testShare := &Share{DistKeyShare: dkg.DistKeyShare{
 		Commits: []kyber.Point{ps[0].Public.Key, ps[1].Public.Key},
 		Share:   &share.PriShare{V: ps[0].Key, I: 0},
-	}
+	}}
 	require.Nil(t, store.SaveShare(testShare))
 	loadedShare, err := store.LoadShare()
 	require.NoError(t, err)
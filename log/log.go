@@ -1,9 +1,11 @@
 package log
 
 import (
+	"fmt"
 	"io"
 	"os"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/go-kit/kit/log"
@@ -99,6 +101,19 @@ func NewKitLogger(logger log.Logger, opts ...lvl.Option) Logger {
 	for _, opt := range opts {
 		logger = lvl.NewFilter(logger, opt)
 	}
+	return newKitLogger(logger)
+}
+
+// NewLeveledLogger returns a Logger like NewKitLogger, which prints the
+// statements at the given level, even when the level changes afterwards.
+func NewLeveledLogger(logger log.Logger, level *Level) Logger {
+	if logger == nil {
+		logger = LoggerTo(os.Stdout)
+	}
+	return newKitLogger(&levelFilter{next: logger, level: level})
+}
+
+func newKitLogger(logger log.Logger) Logger {
 	timestamp := log.TimestampFormat(time.Now, time.RFC1123)
 	logger = log.With(logger, "ts", timestamp)
 	logger = log.With(logger, "call", log.Caller(logStackDepth))
@@ -130,3 +145,71 @@ func (k *kitLogger) With(kv ...interface{}) Logger {
 	newLogger := log.With(k.Logger, kv...)
 	return NewKitLoggerFrom(newLogger)
 }
+
+// ParseLevel returns the level of the given name: "none", "info" or "debug".
+func ParseLevel(name string) (int, error) {
+	switch name {
+	case "none":
+		return LogNone, nil
+	case "info":
+		return LogInfo, nil
+	case "debug":
+		return LogDebug, nil
+	default:
+		return 0, fmt.Errorf("unknown log level %q", name)
+	}
+}
+
+// Level is a logging level which can be changed while the loggers created with
+// it are in use.
+type Level struct {
+	level int32
+}
+
+// NewLevel returns a Level set to the given level.
+func NewLevel(level int) *Level {
+	l := new(Level)
+	if err := l.Set(level); err != nil {
+		panic(err)
+	}
+	return l
+}
+
+// Set changes the level.
+func (l *Level) Set(level int) error {
+	if level < LogNone || level > LogDebug {
+		return fmt.Errorf("unknown log level %d", level)
+	}
+	atomic.StoreInt32(&l.level, int32(level))
+	return nil
+}
+
+// Get returns the current level.
+func (l *Level) Get() int {
+	return int(atomic.LoadInt32(&l.level))
+}
+
+// levelFilter drops the statements above the current level, as the filters of
+// the level package do for a fixed level.
+type levelFilter struct {
+	next  log.Logger
+	level *Level
+}
+
+func (f *levelFilter) Log(kv ...interface{}) error {
+	for i := 0; i+1 < len(kv); i += 2 {
+		if kv[i] != lvl.Key() {
+			continue
+		}
+		switch f.level.Get() {
+		case LogNone:
+			return nil
+		case LogInfo:
+			if kv[i+1] == lvl.DebugValue() {
+				return nil
+			}
+		}
+		break
+	}
+	return f.next.Log(kv...)
+}
@@ -85,3 +85,29 @@ func requireContains(t *testing.T, r io.Reader, outs []string, present bool) {
 		require.Contains(t, string(out), o)
 	}
 }
+
+func TestLoggerLevel(t *testing.T) {
+	var b bytes.Buffer
+	level := NewLevel(LogInfo)
+	logger := NewLeveledLogger(log.NewLogfmtLogger(&b), level).With("yard", "bird")
+
+	logger.Debug("msg", "hidden")
+	requireContains(t, &b, nil, false)
+	logger.Info("msg", "shown")
+	requireContains(t, &b, []string{"yard", "shown"}, true)
+
+	require.NoError(t, level.Set(LogDebug))
+	logger.Debug("msg", "debug")
+	requireContains(t, &b, []string{"debug"}, true)
+
+	require.NoError(t, level.Set(LogNone))
+	logger.Error("msg", "error")
+	requireContains(t, &b, nil, false)
+	require.Error(t, level.Set(42))
+
+	l, err := ParseLevel("debug")
+	require.NoError(t, err)
+	require.Equal(t, LogDebug, l)
+	_, err = ParseLevel("loud")
+	require.Error(t, err)
+}
@@ -0,0 +1,179 @@
+package lp2p
+
+import (
+	"context"
+	"encoding/json"
+	mrand "math/rand"
+	"time"
+
+	dlog "github.com/drand/drand/log"
+
+	"github.com/ipfs/go-datastore"
+	"github.com/libp2p/go-libp2p-core/host"
+	"github.com/libp2p/go-libp2p-core/network"
+	"github.com/libp2p/go-libp2p-core/peer"
+	"github.com/libp2p/go-libp2p-core/peerstore"
+	ma "github.com/multiformats/go-multiaddr"
+	"golang.org/x/xerrors"
+)
+
+const (
+	// bootstrapInterval is how often a host resolves its bootstrap addresses
+	// again and reconnects to the peers it lost.
+	bootstrapInterval = 5 * time.Minute
+	// minPeers is the number of peers under which a host also dials the peers
+	// it knew before.
+	minPeers = 4
+	// maxKnownPeers bounds the number of known peers kept in the datastore.
+	maxKnownPeers = 64
+)
+
+// knownPeersKey is the datastore key of the peers a host reached lately.
+var knownPeersKey = datastore.NewKey("/drand/known-peers")
+
+// bootstrapper keeps a host connected to the mesh: it resolves the bootstrap
+// addresses again at each interval, so that the DNS multiaddrs follow the
+// peers they point to, and records the peers it reaches in the datastore, so
+// that the host reconnects to the mesh after an outage even when the
+// bootstrap peers are gone.
+type bootstrapper struct {
+	h         host.Host
+	ds        datastore.Datastore
+	bootstrap []ma.Multiaddr
+	// direct are the direct peers of gossipsub, which it redials at the
+	// addresses of the peerstore.
+	direct map[peer.ID]struct{}
+	log    dlog.Logger
+}
+
+func (b *bootstrapper) run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		b.refresh(ctx)
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// refresh connects to the bootstrap peers, and to the known peers when the
+// host has few peers, then records the peers reached.
+func (b *bootstrapper) refresh(ctx context.Context) {
+	var infos []peer.AddrInfo
+	for _, addr := range b.bootstrap {
+		resolved, err := resolveAddresses(ctx, []ma.Multiaddr{addr}, nil)
+		if err != nil {
+			b.log.Warn("bootstrap", "could not resolve", "addr", addr, "err", err)
+			continue
+		}
+		b.updateDirectPeers(resolved)
+		infos = append(infos, resolved...)
+	}
+	if len(b.h.Network().Peers()) < minPeers {
+		known, err := loadKnownPeers(b.ds)
+		if err != nil {
+			b.log.Warn("bootstrap", "could not load known peers", "err", err)
+		}
+		infos = append(infos, known...)
+	}
+
+	mrand.Shuffle(len(infos), func(i, j int) {
+		infos[i], infos[j] = infos[j], infos[i]
+	})
+	for _, ai := range infos {
+		if ai.ID == b.h.ID() || b.h.Network().Connectedness(ai.ID) == network.Connected {
+			continue
+		}
+		cctx, cancel := context.WithTimeout(ctx, bootstrapTimeout)
+		err := b.h.Connect(cctx, ai)
+		cancel()
+		if err != nil {
+			b.log.Warn("bootstrap", "could not bootstrap", "addr", ai)
+		}
+	}
+
+	if err := saveKnownPeers(b.ds, reachedPeers(b.h)); err != nil {
+		b.log.Warn("bootstrap", "could not save known peers", "err", err)
+	}
+}
+
+// updateDirectPeers replaces the addresses of the direct peers by the ones
+// they resolve to now, so that gossipsub redials a direct peer whose DNS
+// record moved at its new address. The direct peers themselves are fixed when
+// gossipsub starts: a bootstrap address resolving to another peer is only
+// dialed as a bootstrap peer.
+func (b *bootstrapper) updateDirectPeers(infos []peer.AddrInfo) {
+	ps := b.h.Peerstore()
+	for _, ai := range infos {
+		if _, ok := b.direct[ai.ID]; !ok || len(ai.Addrs) == 0 {
+			continue
+		}
+		for _, old := range ps.Addrs(ai.ID) {
+			if !containsAddr(ai.Addrs, old) {
+				ps.SetAddr(ai.ID, old, 0)
+			}
+		}
+		ps.AddAddrs(ai.ID, ai.Addrs, peerstore.PermanentAddrTTL)
+	}
+}
+
+func containsAddr(addrs []ma.Multiaddr, a ma.Multiaddr) bool {
+	for _, addr := range addrs {
+		if addr.Equal(a) {
+			return true
+		}
+	}
+	return false
+}
+
+// reachedPeers returns the peers the host dialed, at the addresses it
+// reached them, which are the ones worth dialing again.
+func reachedPeers(h host.Host) []peer.AddrInfo {
+	var infos []peer.AddrInfo
+	for _, p := range h.Network().Peers() {
+		ai := peer.AddrInfo{ID: p}
+		for _, c := range h.Network().ConnsToPeer(p) {
+			if c.Stat().Direction == network.DirOutbound {
+				ai.Addrs = append(ai.Addrs, c.RemoteMultiaddr())
+			}
+		}
+		if len(ai.Addrs) > 0 {
+			infos = append(infos, ai)
+		}
+		if len(infos) == maxKnownPeers {
+			break
+		}
+	}
+	return infos
+}
+
+func loadKnownPeers(ds datastore.Datastore) ([]peer.AddrInfo, error) {
+	b, err := ds.Get(knownPeersKey)
+	if xerrors.Is(err, datastore.ErrNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var infos []peer.AddrInfo
+	if err := json.Unmarshal(b, &infos); err != nil {
+		return nil, xerrors.Errorf("decoding known peers: %w", err)
+	}
+	return infos, nil
+}
+
+// saveKnownPeers records the peers reached, keeping the previous ones when
+// none is reached so that they survive an outage.
+func saveKnownPeers(ds datastore.Datastore, infos []peer.AddrInfo) error {
+	if len(infos) == 0 {
+		return nil
+	}
+	b, err := json.Marshal(infos)
+	if err != nil {
+		return err
+	}
+	return ds.Put(knownPeersKey, b)
+}
@@ -0,0 +1,83 @@
+package lp2p
+
+import (
+	"crypto/rand"
+	"testing"
+	"time"
+
+	"github.com/drand/drand/log"
+	"github.com/ipfs/go-datastore"
+	"github.com/libp2p/go-libp2p-core/crypto"
+	"github.com/libp2p/go-libp2p-core/peer"
+	ma "github.com/multiformats/go-multiaddr"
+)
+
+func TestKnownPeers(t *testing.T) {
+	ds := datastore.NewMapDatastore()
+	known, err := loadKnownPeers(ds)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(known) != 0 {
+		t.Fatal("expected no known peers", known)
+	}
+
+	_, pub, err := crypto.GenerateEd25519Key(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	id, err := peer.IDFromPublicKey(pub)
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr, err := ma.NewMultiaddr("/ip4/192.0.2.1/tcp/44544")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := saveKnownPeers(ds, []peer.AddrInfo{{ID: id, Addrs: []ma.Multiaddr{addr}}}); err != nil {
+		t.Fatal(err)
+	}
+	// the known peers survive an outage
+	if err := saveKnownPeers(ds, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	known, err = loadKnownPeers(ds)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(known) != 1 || known[0].ID != id || !known[0].Addrs[0].Equal(addr) {
+		t.Fatal("unexpected known peers", known)
+	}
+}
+
+func TestUpdateDirectPeers(t *testing.T) {
+	priv, _, err := crypto.GenerateEd25519Key(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	h, _, err := ConstructHost(datastore.NewMapDatastore(), priv, "", nil, nil, log.DefaultLogger())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer h.Close()
+
+	_, pub, err := crypto.GenerateEd25519Key(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	id, err := peer.IDFromPublicKey(pub)
+	if err != nil {
+		t.Fatal(err)
+	}
+	before, _ := ma.NewMultiaddr("/ip4/192.0.2.1/tcp/44544")
+	after, _ := ma.NewMultiaddr("/ip4/192.0.2.2/tcp/44544")
+	h.Peerstore().AddAddr(id, before, time.Hour)
+
+	b := &bootstrapper{h: h, direct: map[peer.ID]struct{}{id: {}}, log: log.DefaultLogger()}
+	b.updateDirectPeers([]peer.AddrInfo{{ID: id, Addrs: []ma.Multiaddr{after}}})
+	addrs := h.Peerstore().Addrs(id)
+	if len(addrs) != 1 || !addrs[0].Equal(after) {
+		t.Fatal("expected the direct peer at its new address only", addrs)
+	}
+}
@@ -22,6 +22,8 @@ type Client struct {
 	latest uint64
 	cache  client.Cache
 	log    log.Logger
+	peers  peerTracker
+	scores *PeerScores
 
 	subs struct {
 		sync.Mutex
@@ -34,11 +36,22 @@ func (c *Client) SetLog(l log.Logger) {
 	c.log = l
 }
 
+// Option configures a gossip client.
+type Option func(c *Client)
+
+// WithPeerScores makes the client record the messages of its peers in the
+// given peer scores too.
+func WithPeerScores(s *PeerScores) Option {
+	return func(c *Client) {
+		c.scores = s
+	}
+}
+
 // WithPubsub provides an option for integrating pubsub notification
 // into a drand client.
-func WithPubsub(ps *pubsub.PubSub) client.Option {
+func WithPubsub(ps *pubsub.PubSub, opts ...Option) client.Option {
 	return client.WithWatcher(func(info *chain.Info, cache client.Cache) (client.Watcher, error) {
-		c, err := NewWithPubsub(ps, info, cache)
+		c, err := NewWithPubsub(ps, info, cache, opts...)
 		if err != nil {
 			return nil, err
 		}
@@ -47,7 +60,7 @@ func WithPubsub(ps *pubsub.PubSub) client.Option {
 }
 
 // NewWithPubsub creates a gossip randomness client.
-func NewWithPubsub(ps *pubsub.PubSub, info *chain.Info, cache client.Cache) (*Client, error) {
+func NewWithPubsub(ps *pubsub.PubSub, info *chain.Info, cache client.Cache, opts ...Option) (*Client, error) {
 	if info == nil {
 		return nil, xerrors.Errorf("No chain supplied for joining")
 	}
@@ -58,6 +71,9 @@ func NewWithPubsub(ps *pubsub.PubSub, info *chain.Info, cache client.Cache) (*Cl
 		cache:  cache,
 		log:    log.DefaultLogger(),
 	}
+	for _, opt := range opts {
+		opt(c)
+	}
 
 	chainHash := hex.EncodeToString(info.Hash())
 	topic := lp2p.PubSubTopic(chainHash)
@@ -62,6 +62,7 @@ func TestGRPCClient(t *testing.T) {
 		DataDir:      dataDir,
 		IdentityPath: path.Join(identityDir, "identity.key"),
 		Client:       grpcClient,
+		ChainInfo:    info,
 	}
 	g, err := lp2p.NewGossipRelayNode(log.DefaultLogger(), cfg)
 	if err != nil {
@@ -139,6 +140,7 @@ func TestHTTPClient(t *testing.T) {
 		DataDir:      dataDir,
 		IdentityPath: path.Join(identityDir, "identity.key"),
 		Client:       httpClient,
+		ChainInfo:    chainInfo,
 	}
 	g, err := lp2p.NewGossipRelayNode(log.DefaultLogger(), cfg)
 	if err != nil {
@@ -191,12 +193,15 @@ func newTestClient(name string, relayMultiaddr []ma.Multiaddr, info *chain.Info)
 	if err != nil {
 		return nil, err
 	}
+	scores := NewPeerScores()
 	h, ps, err := lp2p.ConstructHost(
 		ds,
 		priv,
 		"/ip4/0.0.0.0/tcp/"+test.FreePort(),
 		relayMultiaddr,
+		nil,
 		log.DefaultLogger(),
+		scores.PubsubOptions()...,
 	)
 	if err != nil {
 		return nil, err
@@ -209,7 +214,7 @@ func newTestClient(name string, relayMultiaddr []ma.Multiaddr, info *chain.Info)
 	if err != nil {
 		return nil, err
 	}
-	c, err := NewWithPubsub(ps, info, nil)
+	c, err := NewWithPubsub(ps, info, nil, WithPeerScores(scores))
 	if err != nil {
 		return nil, err
 	}
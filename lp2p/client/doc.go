@@ -78,5 +78,19 @@ Example using "WithChainHash()" and combining it with a different client:
 	func newPubSub() *pubsub.Pubsub {
 		// ...
 	}
+
+Gossip peers may go quiet. "WithPubsubFallback()" can be used in place of
+"WithPubsub()" to poll another client, such as an HTTP one, for new rounds
+whenever no round is gossiped for 1.5 times the round period, and to switch
+back to gossip once it recovers. The gossip client also keeps per-peer
+statistics, available with "PeerStats()", and the time of the last valid round
+received, available with "LastMessage()".
+
+The statistics can score the peers in gossipsub, which stops gossiping with
+the peers sending repeated invalid rounds, then graylists them. The penalty of
+an invalid round halves every 10 minutes, so peers recover once they behave
+again. Create the scores with
+"NewPeerScores()", pass their "PubsubOptions()" when creating the pubsub, and
+"WithPeerScores()" to "WithPubsub()".
 */
 package client
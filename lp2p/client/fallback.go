@@ -0,0 +1,145 @@
+package client
+
+import (
+	"context"
+	"io"
+	"time"
+
+	"github.com/drand/drand/chain"
+	"github.com/drand/drand/client"
+	"github.com/drand/drand/log"
+
+	pubsub "github.com/libp2p/go-libp2p-pubsub"
+)
+
+// fallbackPeriodFactor is how many round periods without gossip it takes to
+// switch to the fallback client.
+const fallbackPeriodFactor = 1.5
+
+// WithPubsubFallback provides an option for integrating pubsub notification
+// into a drand client, polling the fallback client for new rounds whenever
+// gossip goes quiet for longer than 1.5 times the round period. The fallback
+// is dropped again once gossip recovers.
+func WithPubsubFallback(ps *pubsub.PubSub, fallback client.Client, opts ...Option) client.Option {
+	return client.WithWatcher(func(info *chain.Info, cache client.Cache) (client.Watcher, error) {
+		c, err := NewWithPubsub(ps, info, cache, opts...)
+		if err != nil {
+			return nil, err
+		}
+		return NewFallbackWatcher(c, fallback, info, c.log), nil
+	})
+}
+
+// NewFallbackWatcher returns a watcher following the gossip watcher, and
+// switching to the fallback watcher while gossip delivers no round for longer
+// than 1.5 times the round period. Rounds are delivered in increasing order
+// and only once, whichever watcher they come from.
+func NewFallbackWatcher(gossip, fallback client.Watcher, info *chain.Info, l log.Logger) client.Watcher {
+	return &fallbackWatcher{
+		gossip:   gossip,
+		fallback: fallback,
+		timeout:  time.Duration(float64(info.Period) * fallbackPeriodFactor),
+		log:      l,
+	}
+}
+
+type fallbackWatcher struct {
+	gossip   client.Watcher
+	fallback client.Watcher
+	timeout  time.Duration
+	log      log.Logger
+}
+
+// Watch implements the client.Watcher interface
+func (f *fallbackWatcher) Watch(ctx context.Context) <-chan client.Result {
+	out := make(chan client.Result)
+	go func() {
+		defer close(out)
+		gossip := f.gossip.Watch(ctx)
+		timer := time.NewTimer(f.timeout)
+		defer timer.Stop()
+
+		var last uint64
+		var polled <-chan client.Result
+		stopPolling := func() {}
+		// drain leftovers so the fallback can notice its context is done.
+		drain := func(ch <-chan client.Result) {
+			go func() {
+				for range ch {
+				}
+			}()
+		}
+		defer func() {
+			stopPolling()
+			if polled != nil {
+				drain(polled)
+			}
+		}()
+
+		forward := func(r client.Result) bool {
+			if r.Round() <= last {
+				return true
+			}
+			select {
+			case out <- r:
+				last = r.Round()
+				return true
+			case <-ctx.Done():
+				return false
+			}
+		}
+
+		for {
+			select {
+			case r, ok := <-gossip:
+				if !ok {
+					return
+				}
+				if polled != nil {
+					f.log.Info("gossip client", "gossip recovered, stopping fallback", "round", r.Round())
+					stopPolling()
+					drain(polled)
+					polled = nil
+				}
+				if !timer.Stop() {
+					select {
+					case <-timer.C:
+					default:
+					}
+				}
+				timer.Reset(f.timeout)
+				if !forward(r) {
+					return
+				}
+			case r, ok := <-polled:
+				if !ok {
+					// restarted on the next timeout if gossip is still quiet.
+					polled = nil
+					continue
+				}
+				if !forward(r) {
+					return
+				}
+			case <-timer.C:
+				if polled == nil {
+					f.log.Warn("gossip client", "no gossip received, using fallback", "timeout", f.timeout, "last", last)
+					pctx, cancel := context.WithCancel(ctx)
+					stopPolling = cancel
+					polled = f.fallback.Watch(pctx)
+				}
+				timer.Reset(f.timeout)
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return out
+}
+
+// Close closes the gossip watcher. The fallback is left to its owner.
+func (f *fallbackWatcher) Close() error {
+	if c, ok := f.gossip.(io.Closer); ok {
+		return c.Close()
+	}
+	return nil
+}
@@ -0,0 +1,153 @@
+package client
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/drand/drand/chain"
+	"github.com/drand/drand/client"
+	"github.com/drand/drand/log"
+	"github.com/drand/drand/protobuf/drand"
+	pubsub "github.com/libp2p/go-libp2p-pubsub"
+	pb "github.com/libp2p/go-libp2p-pubsub/pb"
+	"google.golang.org/protobuf/proto"
+)
+
+type chanWatcher chan client.Result
+
+func (c chanWatcher) Watch(ctx context.Context) <-chan client.Result {
+	return c
+}
+
+func nextRound(t *testing.T, ch <-chan client.Result) uint64 {
+	t.Helper()
+	select {
+	case r, ok := <-ch:
+		if !ok {
+			t.Fatal("watch closed early")
+		}
+		return r.Round()
+	case <-time.After(2 * time.Second):
+		t.Fatal("timed out waiting for round")
+		return 0
+	}
+}
+
+func TestFallbackWatcher(t *testing.T) {
+	gossip := make(chanWatcher, 1)
+	fallback := make(chanWatcher, 2)
+	info := &chain.Info{Period: 100 * time.Millisecond}
+	w := NewFallbackWatcher(gossip, fallback, info, log.DefaultLogger())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	out := w.Watch(ctx)
+
+	// gossip is quiet: rounds come from the fallback.
+	fallback <- &client.RandomData{Rnd: 1}
+	if r := nextRound(t, out); r != 1 {
+		t.Fatal("expected round from fallback", r)
+	}
+
+	// gossip recovers: rounds are not delivered twice.
+	gossip <- &client.RandomData{Rnd: 1}
+	gossip <- &client.RandomData{Rnd: 2}
+	if r := nextRound(t, out); r != 2 {
+		t.Fatal("expected next round from gossip", r)
+	}
+}
+
+func TestPeerStats(t *testing.T) {
+	c := Client{log: log.DefaultLogger()}
+	p := randomPeerID(t)
+	c.peers.record(p, pubsub.ValidationAccept)
+	c.peers.record(p, pubsub.ValidationIgnore)
+	c.peers.record(p, pubsub.ValidationReject)
+
+	s, ok := c.PeerStats()[p]
+	if !ok {
+		t.Fatal("expected peer to be tracked")
+	}
+	if s.Valid != 1 || s.Duplicate != 1 || s.Invalid != 1 {
+		t.Fatal("unexpected peer stats", s)
+	}
+	if s.Score() >= 0 {
+		t.Fatal("invalid messages should outweigh valid ones", s.Score())
+	}
+	if c.LastMessage().IsZero() {
+		t.Fatal("expected last message time to be set")
+	}
+}
+
+func TestPeerScores(t *testing.T) {
+	scores := NewPeerScores()
+	now := time.Now()
+	scores.tracker.clock = func() time.Time { return now }
+	c := Client{log: log.DefaultLogger()}
+	WithPeerScores(scores)(&c)
+	validate := randomnessValidator(nil, nil, &c)
+	good, bad := randomPeerID(t), randomPeerID(t)
+	for i := 0; i < 2*maxGossipScore; i++ {
+		validate(context.Background(), good, randomnessMessage(t, uint64(i+1)))
+	}
+	validate(context.Background(), bad, randomnessMessage(t, 1))
+	validate(context.Background(), bad, &pubsub.Message{Message: &pb.Message{Data: []byte("invalid")}})
+
+	// the scores given to gossipsub are capped for well-behaved peers, and
+	// fall under the gossip threshold with invalid rounds
+	if s := scores.tracker.score(good); s != maxGossipScore {
+		t.Fatal("unexpected score of a good peer", s)
+	}
+	if s := scores.tracker.score(bad); s != 1-invalidPenalty {
+		t.Fatal("unexpected score of a bad peer", s)
+	}
+	if s := scores.tracker.score(randomPeerID(t)); s != 0 {
+		t.Fatal("unknown peers should have no score", s)
+	}
+	if _, ok := c.PeerStats()[bad]; !ok {
+		t.Fatal("the client should keep its own statistics")
+	}
+}
+
+func TestPeerScoresRecover(t *testing.T) {
+	scores := NewPeerScores()
+	now := time.Now()
+	scores.tracker.clock = func() time.Time { return now }
+	c := Client{log: log.DefaultLogger()}
+	WithPeerScores(scores)(&c)
+	validate := randomnessValidator(nil, nil, &c)
+	thresholds := scores.thresholds()
+	invalid := &pubsub.Message{Message: &pb.Message{Data: []byte("invalid")}}
+
+	// a single invalid round doesn't stop the gossip with a new peer
+	p := randomPeerID(t)
+	validate(context.Background(), p, invalid)
+	if s := scores.tracker.score(p); s < thresholds.GossipThreshold {
+		t.Fatal("a single invalid round should not stop the gossip", s)
+	}
+	validate(context.Background(), p, invalid)
+	validate(context.Background(), p, invalid)
+	if s := scores.tracker.score(p); s >= thresholds.GossipThreshold {
+		t.Fatal("repeated invalid rounds should stop the gossip", s)
+	}
+
+	// the penalty decays, and the peer recovers
+	now = now.Add(penaltyHalfLife)
+	if s := scores.tracker.score(p); s != -1.5*invalidPenalty {
+		t.Fatal("the penalty should halve after its half-life", s)
+	}
+	now = now.Add(4 * penaltyHalfLife)
+	validate(context.Background(), p, randomnessMessage(t, 1))
+	if s := scores.tracker.score(p); s <= 0 {
+		t.Fatal("the peer should recover", s)
+	}
+}
+
+func randomnessMessage(t *testing.T, round uint64) *pubsub.Message {
+	data, err := proto.Marshal(&drand.PublicRandResponse{Round: round})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &pubsub.Message{Message: &pb.Message{Data: data}}
+}
@@ -0,0 +1,181 @@
+package client
+
+import (
+	"math"
+	"sync"
+	"time"
+
+	"github.com/libp2p/go-libp2p-core/peer"
+	pubsub "github.com/libp2p/go-libp2p-pubsub"
+)
+
+const (
+	// invalidPenalty is how many valid messages an invalid one cancels out in
+	// a peer score.
+	invalidPenalty = 10
+	// maxGossipScore caps the score given to gossipsub for a peer, so that a
+	// long history of valid rounds doesn't shield a peer turning bad.
+	maxGossipScore = 10
+	// penaltyHalfLife is how long it takes for the penalty of the invalid
+	// rounds of a peer to halve in the score given to gossipsub, so that a
+	// peer which sent an invalid round recovers by behaving.
+	penaltyHalfLife = 10 * time.Minute
+)
+
+// PeerStat summarizes the randomness messages a gossip peer sent us.
+type PeerStat struct {
+	// Valid is the number of new, valid rounds received from the peer.
+	Valid uint64
+	// Duplicate is the number of rounds received from the peer that were
+	// already known.
+	Duplicate uint64
+	// Invalid is the number of rounds received from the peer that failed
+	// validation.
+	Invalid uint64
+	// LastSeen is when the peer last sent a message.
+	LastSeen time.Time
+}
+
+// Score rates how useful a peer has been: valid rounds count for it while
+// invalid ones heavily count against it.
+func (s PeerStat) Score() float64 {
+	return float64(s.Valid) - invalidPenalty*float64(s.Invalid)
+}
+
+// penalty is the decaying penalty of the invalid rounds of a peer.
+type penalty struct {
+	value float64
+	at    time.Time
+}
+
+// valueAt returns the penalty decayed until now.
+func (p *penalty) valueAt(now time.Time) float64 {
+	return p.value * math.Exp2(-float64(now.Sub(p.at))/float64(penaltyHalfLife))
+}
+
+// peerTracker records the messages received from each gossip peer.
+type peerTracker struct {
+	sync.Mutex
+	stats       map[peer.ID]*PeerStat
+	penalties   map[peer.ID]*penalty
+	lastMessage time.Time
+	// clock returns the current time, time.Now when nil.
+	clock func() time.Time
+}
+
+func (t *peerTracker) now() time.Time {
+	if t.clock != nil {
+		return t.clock()
+	}
+	return time.Now()
+}
+
+func (t *peerTracker) record(p peer.ID, res pubsub.ValidationResult) {
+	t.Lock()
+	defer t.Unlock()
+	if t.stats == nil {
+		t.stats = make(map[peer.ID]*PeerStat)
+		t.penalties = make(map[peer.ID]*penalty)
+	}
+	s, ok := t.stats[p]
+	if !ok {
+		s = new(PeerStat)
+		t.stats[p] = s
+	}
+	now := t.now()
+	s.LastSeen = now
+	switch res {
+	case pubsub.ValidationAccept:
+		s.Valid++
+		t.lastMessage = now
+	case pubsub.ValidationIgnore:
+		s.Duplicate++
+	case pubsub.ValidationReject:
+		s.Invalid++
+		pen, ok := t.penalties[p]
+		if !ok {
+			pen = new(penalty)
+			t.penalties[p] = pen
+		}
+		pen.value = pen.valueAt(now) + invalidPenalty
+		pen.at = now
+	}
+}
+
+// score returns the score of the peer given to gossipsub: its valid rounds,
+// capped at maxGossipScore, minus the penalty of its invalid rounds, which
+// decays over time.
+func (t *peerTracker) score(p peer.ID) float64 {
+	t.Lock()
+	defer t.Unlock()
+	s, ok := t.stats[p]
+	if !ok {
+		return 0
+	}
+	score := math.Min(float64(s.Valid), maxGossipScore)
+	if pen, ok := t.penalties[p]; ok {
+		score -= pen.valueAt(t.now())
+	}
+	return score
+}
+
+// PeerScores feeds the scores of the peers sending randomness to the gossip
+// clients into gossipsub, so that the peers sending invalid rounds stop being
+// gossiped with, then get graylisted. The penalty of the invalid rounds
+// decays over time, so that peers recover once they behave again. The pubsub must be created with its
+// PubsubOptions, and the gossip clients using it with WithPeerScores, so that
+// they record the messages of their peers in the scores.
+type PeerScores struct {
+	tracker peerTracker
+}
+
+// NewPeerScores returns peer scores without any peer.
+func NewPeerScores() *PeerScores {
+	return new(PeerScores)
+}
+
+// PubsubOptions returns the options enabling the peer scores in gossipsub,
+// to pass to lp2p.ConstructHost or pubsub.NewGossipSub.
+func (s *PeerScores) PubsubOptions() []pubsub.Option {
+	return []pubsub.Option{pubsub.WithPeerScore(
+		&pubsub.PeerScoreParams{
+			AppSpecificScore:  s.tracker.score,
+			AppSpecificWeight: 1,
+			DecayInterval:     pubsub.DefaultDecayInterval,
+			DecayToZero:       pubsub.DefaultDecayToZero,
+		},
+		s.thresholds(),
+	)}
+}
+
+func (s *PeerScores) thresholds() *pubsub.PeerScoreThresholds {
+	return &pubsub.PeerScoreThresholds{
+		// the gossip with a peer survives two recent invalid rounds
+		// outweighing its valid ones, more stop it, a few more graylist it
+		GossipThreshold:             -2 * invalidPenalty,
+		PublishThreshold:            -5 * invalidPenalty,
+		GraylistThreshold:           -8 * invalidPenalty,
+		AcceptPXThreshold:           maxGossipScore / 2,
+		OpportunisticGraftThreshold: 1,
+	}
+}
+
+// PeerStats returns the statistics of every gossip peer that sent us a
+// message.
+func (c *Client) PeerStats() map[peer.ID]PeerStat {
+	c.peers.Lock()
+	defer c.peers.Unlock()
+	stats := make(map[peer.ID]PeerStat, len(c.peers.stats))
+	for p, s := range c.peers.stats {
+		stats[p] = *s
+	}
+	return stats
+}
+
+// LastMessage returns when a new valid round was last received through
+// gossip, or the zero time if none was received yet.
+func (c *Client) LastMessage() time.Time {
+	c.peers.Lock()
+	defer c.peers.Unlock()
+	return c.peers.lastMessage
+}
@@ -14,6 +14,18 @@ import (
 )
 
 func randomnessValidator(info *chain.Info, cache client.Cache, c *Client) pubsub.ValidatorEx {
+	validate := validateRandomness(info, cache, c)
+	return func(ctx context.Context, p peer.ID, m *pubsub.Message) pubsub.ValidationResult {
+		res := validate(ctx, p, m)
+		c.peers.record(p, res)
+		if c.scores != nil {
+			c.scores.tracker.record(p, res)
+		}
+		return res
+	}
+}
+
+func validateRandomness(info *chain.Info, cache client.Cache, c *Client) pubsub.ValidatorEx {
 	return func(ctx context.Context, p peer.ID, m *pubsub.Message) pubsub.ValidationResult {
 		var rand drand.PublicRandResponse
 		err := proto.Unmarshal(m.Data, &rand)
@@ -33,7 +45,7 @@ func randomnessValidator(info *chain.Info, cache client.Cache, c *Client) pubsub
 		}
 
 		// Unwilling to relay beacons in the future.
-		if time.Unix(chain.TimeOfRound(info.Period, info.GenesisTime, b.Round), 0).After(time.Now()) {
+		if info.TimeOfRound(b.Round).After(time.Now()) {
 			return pubsub.ValidationReject
 		}
 
@@ -61,7 +73,7 @@ func randomnessValidator(info *chain.Info, cache client.Cache, c *Client) pubsub
 			}
 		}
 
-		if err := chain.VerifyBeacon(info.PublicKey, &b); err != nil {
+		if err := info.VerifyBeacon(&b); err != nil {
 			return pubsub.ValidationReject
 		}
 		return pubsub.ValidationAccept
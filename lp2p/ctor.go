@@ -6,7 +6,6 @@ import (
 	"encoding/base64"
 	"fmt"
 	"io/ioutil"
-	mrand "math/rand"
 	"os"
 	"path"
 	"time"
@@ -47,9 +46,34 @@ func PubSubTopic(h string) string {
 }
 
 // ConstructHost build a libp2p host configured for relaying drand randomness over pubsub.
+// The host keeps reconnecting to its bootstrap peers, and to the peers it reached
+// before, which it records in the datastore. A nil NAT configuration disables
+// the circuit relays. The pubsub options are added to the ones of drand, for
+// instance to score the peers. Closing the host stops the routines it runs.
 func ConstructHost(ds datastore.Datastore, priv crypto.PrivKey, listenAddr string,
-	bootstrap []ma.Multiaddr, log dlog.Logger) (host.Host, *pubsub.PubSub, error) {
-	ctx := context.Background()
+	bootstrap []ma.Multiaddr, nat *NATConfig, log dlog.Logger, psOpts ...pubsub.Option) (host.Host, *pubsub.PubSub, error) {
+	ctx, cancel := context.WithCancel(context.Background())
+	h, p, err := constructHost(ctx, ds, priv, listenAddr, bootstrap, nat, log, psOpts)
+	if err != nil {
+		cancel()
+		return nil, nil, err
+	}
+	return &closingHost{Host: h, cancel: cancel}, p, nil
+}
+
+// closingHost cancels the context of the routines of a host when it closes.
+type closingHost struct {
+	host.Host
+	cancel context.CancelFunc
+}
+
+func (h *closingHost) Close() error {
+	h.cancel()
+	return h.Host.Close()
+}
+
+func constructHost(ctx context.Context, ds datastore.Datastore, priv crypto.PrivKey, listenAddr string,
+	bootstrap []ma.Multiaddr, nat *NATConfig, log dlog.Logger, psOpts []pubsub.Option) (host.Host, *pubsub.PubSub, error) {
 
 	pstoreDs := namespace.Wrap(ds, datastore.NewKey("/peerstore"))
 	pstore, err := pstoreds.NewPeerstore(ctx, pstoreDs, pstoreds.DefaultOpts())
@@ -77,12 +101,17 @@ func ConstructHost(ds datastore.Datastore, priv crypto.PrivKey, listenAddr strin
 		libp2p.ChainOptions(
 			libp2p.Security(libp2ptls.ID, libp2ptls.New),
 			libp2p.Security(noise.ID, noise.New)),
-		libp2p.DisableRelay(),
 		// libp2p.Peerstore(pstore), depends on https://github.com/libp2p/go-libp2p-peerstore/issues/153
 		libp2p.UserAgent(userAgent),
 		libp2p.ConnectionManager(cmgr),
 	}
 
+	natOpts, err := nat.options()
+	if err != nil {
+		return nil, nil, err
+	}
+	opts = append(opts, natOpts...)
+
 	if listenAddr != "" {
 		opts = append(opts, libp2p.ListenAddrStrings(listenAddr))
 	} else {
@@ -93,8 +122,9 @@ func ConstructHost(ds datastore.Datastore, priv crypto.PrivKey, listenAddr strin
 	if err != nil {
 		return nil, nil, xerrors.Errorf("constructing host: %w", err)
 	}
+	observeConnectivity(ctx, h, log)
 
-	p, err := pubsub.NewGossipSub(ctx, h,
+	p, err := pubsub.NewGossipSub(ctx, h, append([]pubsub.Option{
 		pubsub.WithPeerExchange(true),
 		pubsub.WithMessageIdFn(func(pmsg *pubsubpb.Message) string {
 			hash := blake2b.Sum256(pmsg.Data)
@@ -103,24 +133,21 @@ func ConstructHost(ds datastore.Datastore, priv crypto.PrivKey, listenAddr strin
 		pubsub.WithDirectPeers(addrInfos),
 		pubsub.WithFloodPublish(true),
 		pubsub.WithDirectConnectTicks(directConnectTicks),
-	)
+		// the messages are signed by the peers publishing them, and the
+		// unsigned ones are dropped
+		pubsub.WithMessageSigning(true),
+		pubsub.WithStrictSignatureVerification(true),
+	}, psOpts...)...)
 	if err != nil {
 		return nil, nil, xerrors.Errorf("constructing pubsub: %d", err)
 	}
 
-	go func() {
-		mrand.Shuffle(len(addrInfos), func(i, j int) {
-			addrInfos[i], addrInfos[j] = addrInfos[j], addrInfos[i]
-		})
-		for _, ai := range addrInfos {
-			ctx, cancel := context.WithTimeout(ctx, bootstrapTimeout)
-			err := h.Connect(ctx, ai)
-			cancel()
-			if err != nil {
-				log.Warn("construct_host", "could not bootstrap", "addr", ai)
-			}
-		}
-	}()
+	direct := make(map[peer.ID]struct{}, len(addrInfos))
+	for _, ai := range addrInfos {
+		direct[ai.ID] = struct{}{}
+	}
+	b := &bootstrapper{h: h, ds: ds, bootstrap: bootstrap, direct: direct, log: log}
+	go b.run(ctx, bootstrapInterval)
 	return h, p, nil
 }
 
@@ -0,0 +1,114 @@
+package lp2p
+
+import (
+	"context"
+
+	dlog "github.com/drand/drand/log"
+	"github.com/drand/drand/metrics"
+
+	"github.com/libp2p/go-libp2p"
+	circuit "github.com/libp2p/go-libp2p-circuit"
+	"github.com/libp2p/go-libp2p-core/event"
+	"github.com/libp2p/go-libp2p-core/host"
+	"github.com/libp2p/go-libp2p-core/network"
+	"github.com/libp2p/go-libp2p-core/peer"
+	ma "github.com/multiformats/go-multiaddr"
+	"golang.org/x/xerrors"
+)
+
+const (
+	pathDirect  = "direct"
+	pathRelayed = "relayed"
+)
+
+// NATConfig configures how a host behind a NAT joins the mesh, and how a
+// public host helps the others to.
+//
+// TODO: hole punching (DCUtR) and circuit relay v2 are left to a follow-up:
+// they need go-libp2p v0.16 or later, and the circuit relays used until then
+// speak the v1 protocol.
+type NATConfig struct {
+	// PortMap maps a port of the router to the host with UPnP or NAT-PMP.
+	PortMap bool
+	// Service dials back the peers asking whether they are reachable, for
+	// their AutoNAT to find out whether they are behind a NAT.
+	Service bool
+	// RelayHop relays the connections to the peers behind a NAT.
+	RelayHop bool
+	// Relays are the circuit relays a host behind a NAT announces, so that
+	// the peers reach it through them.
+	Relays []ma.Multiaddr
+}
+
+// options returns the libp2p options of the configuration. The AutoNAT client
+// always runs, to find out whether the host is behind a NAT.
+func (c *NATConfig) options() ([]libp2p.Option, error) {
+	if c == nil {
+		return []libp2p.Option{libp2p.DisableRelay()}, nil
+	}
+	var opts []libp2p.Option
+	if c.PortMap {
+		opts = append(opts, libp2p.NATPortMap())
+	}
+	if c.Service {
+		opts = append(opts, libp2p.EnableNATService())
+	}
+	switch {
+	case c.RelayHop:
+		opts = append(opts, libp2p.EnableRelay(circuit.OptHop))
+	case len(c.Relays) > 0:
+		relays, err := peer.AddrInfosFromP2pAddrs(c.Relays...)
+		if err != nil {
+			return nil, xerrors.Errorf("parsing circuit relays: %w", err)
+		}
+		opts = append(opts, libp2p.EnableRelay(), libp2p.EnableAutoRelay(), libp2p.StaticRelays(relays))
+	default:
+		opts = append(opts, libp2p.DisableRelay())
+	}
+	return opts, nil
+}
+
+// connectionPath tells whether a connection is relayed through a circuit
+// relay.
+func connectionPath(c network.Conn) string {
+	if _, err := c.RemoteMultiaddr().ValueForProtocol(ma.P_CIRCUIT); err == nil {
+		return pathRelayed
+	}
+	return pathDirect
+}
+
+// observeConnectivity reports the connections of the host by path, and its
+// reachability, in the metrics.
+func observeConnectivity(ctx context.Context, h host.Host, log dlog.Logger) {
+	h.Network().Notify(&network.NotifyBundle{
+		ConnectedF: func(_ network.Network, c network.Conn) {
+			metrics.GossipConnections.WithLabelValues(connectionPath(c)).Inc()
+			log.Debug("connectivity", "connected", "peer", c.RemotePeer(), "path", connectionPath(c))
+		},
+		DisconnectedF: func(_ network.Network, c network.Conn) {
+			metrics.GossipConnections.WithLabelValues(connectionPath(c)).Dec()
+		},
+	})
+
+	sub, err := h.EventBus().Subscribe(new(event.EvtLocalReachabilityChanged))
+	if err != nil {
+		log.Warn("connectivity", "could not follow the reachability", "err", err)
+		return
+	}
+	go func() {
+		defer sub.Close()
+		for {
+			select {
+			case e, ok := <-sub.Out():
+				if !ok {
+					return
+				}
+				r := e.(event.EvtLocalReachabilityChanged).Reachability
+				metrics.GossipReachability.Set(float64(r))
+				log.Info("connectivity", "reachability changed", "reachability", r.String())
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+}
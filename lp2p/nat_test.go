@@ -0,0 +1,35 @@
+package lp2p
+
+import (
+	"testing"
+
+	ma "github.com/multiformats/go-multiaddr"
+)
+
+func TestNATConfigOptions(t *testing.T) {
+	var disabled *NATConfig
+	opts, err := disabled.options()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(opts) != 1 {
+		t.Fatal("expected the relays to be disabled only", len(opts))
+	}
+
+	opts, err = (&NATConfig{PortMap: true, Service: true, RelayHop: true}).options()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(opts) != 3 {
+		t.Fatal("expected an option for the port map, the service and the hop", len(opts))
+	}
+
+	// the circuit relays must be given with their peer ID
+	relay, err := ma.NewMultiaddr("/ip4/192.0.2.1/tcp/44544")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := (&NATConfig{Relays: []ma.Multiaddr{relay}}).options(); err == nil {
+		t.Fatal("expected an error for a relay without peer ID")
+	}
+}
@@ -2,11 +2,15 @@ package lp2p
 
 import (
 	"context"
+	"encoding/hex"
 	"fmt"
+	"sync"
 	"time"
 
+	"github.com/drand/drand/chain"
 	"github.com/drand/drand/client"
 	"github.com/drand/drand/log"
+	"github.com/drand/drand/metrics"
 	"github.com/drand/drand/protobuf/drand"
 
 	bds "github.com/ipfs/go-ds-badger2"
@@ -18,6 +22,15 @@ import (
 	"google.golang.org/protobuf/proto"
 )
 
+const (
+	// infoTimeout bounds the time to get the chain info of the client of a
+	// relay.
+	infoTimeout = 10 * time.Second
+	// chainRetryInterval is how often a relay tries again to join a chain it
+	// could not get the info of.
+	chainRetryInterval = 30 * time.Second
+)
+
 // GossipRelayConfig configures a gossip relay node.
 type GossipRelayConfig struct {
 	// ChainHash is a hash that uniquely identifies the drand chain.
@@ -29,6 +42,24 @@ type GossipRelayConfig struct {
 	CertPath     string
 	Insecure     bool
 	Client       client.Client
+	// NAT configures the traversal of the NATs, disabled when nil.
+	NAT *NATConfig
+	// ChainInfo pins the chain info the rounds are verified against, the
+	// chain info of the client by default.
+	ChainInfo *chain.Info
+	// Chains are the other chains the node relays, each on the topic of its
+	// chain hash.
+	Chains []GossipRelayChain
+}
+
+// GossipRelayChain is a chain relayed by a gossip relay node besides the
+// chain of its configuration.
+type GossipRelayChain struct {
+	ChainHash string
+	Client    client.Client
+	// ChainInfo pins the chain info the rounds are verified against, the
+	// chain info of the client by default.
+	ChainInfo *chain.Info
 }
 
 // GossipRelayNode is a gossip relay runtime.
@@ -39,13 +70,51 @@ type GossipRelayNode struct {
 	priv      crypto.PrivKey
 	h         host.Host
 	ps        *pubsub.PubSub
-	t         *pubsub.Topic
 	addrs     []ma.Multiaddr
 	done      chan struct{}
+
+	mu sync.Mutex
+	// hashes are the hashes of the chains the node relays, and chains the
+	// ones it joined so far.
+	hashes map[string]bool
+	chains map[string]*relayedChain
+}
+
+// relayedChain is a chain joined by a relay node.
+type relayedChain struct {
+	hash  string
+	t     *pubsub.Topic
+	seen  *seenStore
+	state *chainState
+	l     log.Logger
 }
 
-// NewGossipRelayNode starts a new gossip relay node.
+// NewGossipRelayNode starts a new gossip relay node. The node only publishes
+// and forwards the rounds verifying against the pinned chain info of their
+// chain, which must match its chain hash. The chain of the configuration is
+// joined before the node is returned, while the other ones are joined in the
+// background, each on its own, so that a chain whose client fails does not
+// affect the others.
 func NewGossipRelayNode(l log.Logger, cfg *GossipRelayConfig) (*GossipRelayNode, error) {
+	if cfg.Client == nil {
+		return nil, xerrors.Errorf("No client supplying randomness supplied.")
+	}
+	hashes := map[string]bool{cfg.ChainHash: true}
+	for _, c := range cfg.Chains {
+		if c.Client == nil {
+			return nil, xerrors.Errorf("no client supplying randomness for chain %s", c.ChainHash)
+		}
+		if hashes[c.ChainHash] {
+			return nil, xerrors.Errorf("chain %s is relayed twice", c.ChainHash)
+		}
+		hashes[c.ChainHash] = true
+	}
+	primary := GossipRelayChain{ChainHash: cfg.ChainHash, Client: cfg.Client, ChainInfo: cfg.ChainInfo}
+	info, err := primary.pinChainInfo()
+	if err != nil {
+		return nil, err
+	}
+
 	bootstrap, err := ParseMultiaddrSlice(cfg.PeerWith)
 	if err != nil {
 		return nil, xerrors.Errorf("parsing peer-with: %w", err)
@@ -61,7 +130,7 @@ func NewGossipRelayNode(l log.Logger, cfg *GossipRelayConfig) (*GossipRelayNode,
 		return nil, xerrors.Errorf("loading p2p key: %w", err)
 	}
 
-	h, ps, err := ConstructHost(ds, priv, cfg.Addr, bootstrap, l)
+	h, ps, err := ConstructHost(ds, priv, cfg.Addr, bootstrap, cfg.NAT, l)
 	if err != nil {
 		return nil, xerrors.Errorf("constructing host: %w", err)
 	}
@@ -75,11 +144,6 @@ func NewGossipRelayNode(l log.Logger, cfg *GossipRelayConfig) (*GossipRelayNode,
 		l.Info("relay_node", "has addr", "addr", fmt.Sprintf("%s/p2p/%s", a, h.ID()))
 	}
 
-	t, err := ps.Join(PubSubTopic(cfg.ChainHash))
-	if err != nil {
-		return nil, xerrors.Errorf("joining topic: %w", err)
-	}
-
 	g := &GossipRelayNode{
 		l:         l,
 		bootstrap: bootstrap,
@@ -87,19 +151,86 @@ func NewGossipRelayNode(l log.Logger, cfg *GossipRelayConfig) (*GossipRelayNode,
 		priv:      priv,
 		h:         h,
 		ps:        ps,
-		t:         t,
 		addrs:     addrs,
 		done:      make(chan struct{}),
+		hashes:    hashes,
+		chains:    make(map[string]*relayedChain),
 	}
 
-	if cfg.Client == nil {
-		return nil, xerrors.Errorf("No client supplying randomness supplied.")
+	rc, err := g.join(primary.ChainHash, info)
+	if err != nil {
+		return nil, err
+	}
+	go g.background(rc, primary.Client)
+	for _, c := range cfg.Chains {
+		go g.relay(c)
 	}
-	go g.background(cfg.Client)
+	go g.report()
 
 	return g, nil
 }
 
+// pinChainInfo returns the chain info the rounds of the chain are verified
+// against, checking it matches the chain hash.
+func (c *GossipRelayChain) pinChainInfo() (*chain.Info, error) {
+	info := c.ChainInfo
+	if info == nil {
+		ctx, cancel := context.WithTimeout(context.Background(), infoTimeout)
+		var err error
+		info, err = c.Client.Info(ctx)
+		cancel()
+		if err != nil {
+			return nil, xerrors.Errorf("getting chain info: %w", err)
+		}
+	}
+	if hash := hex.EncodeToString(info.Hash()); hash != c.ChainHash {
+		return nil, xerrors.Errorf("chain info of hash %s does not match chain hash %s", hash, c.ChainHash)
+	}
+	return info, nil
+}
+
+// join registers the validator of the topic of a chain, then joins it.
+func (g *GossipRelayNode) join(hash string, info *chain.Info) (*relayedChain, error) {
+	l := g.l.With("chain", hash)
+	topic := PubSubTopic(hash)
+	seen := newSeenStore(g.ds, seenPrefix.ChildString(hash), seenTTL)
+	if err := g.ps.RegisterTopicValidator(topic, randomnessValidator(hash, info, seen, l)); err != nil {
+		return nil, xerrors.Errorf("registering topic validator: %w", err)
+	}
+	t, err := g.ps.Join(topic)
+	if err != nil {
+		return nil, xerrors.Errorf("joining topic: %w", err)
+	}
+	rc := &relayedChain{hash: hash, t: t, seen: seen, state: &chainState{info: info}, l: l}
+	g.mu.Lock()
+	g.chains[hash] = rc
+	g.mu.Unlock()
+	return rc, nil
+}
+
+// relay joins another chain once its chain info is known, then relays its
+// rounds.
+func (g *GossipRelayNode) relay(c GossipRelayChain) {
+	for {
+		info, err := c.pinChainInfo()
+		if err == nil {
+			rc, err := g.join(c.ChainHash, info)
+			if err != nil {
+				g.l.Error("relay_node", "could not join chain", "chain", c.ChainHash, "err", err)
+				return
+			}
+			g.background(rc, c.Client)
+			return
+		}
+		g.l.Warn("relay_node", "could not pin chain info", "chain", c.ChainHash, "err", err)
+		select {
+		case <-time.After(chainRetryInterval):
+		case <-g.done:
+			return
+		}
+	}
+}
+
 // Multiaddrs returns the gossipsub multiaddresses of this relay node.
 func (g *GossipRelayNode) Multiaddrs() []ma.Multiaddr {
 	base := g.h.Addrs()
@@ -132,7 +263,7 @@ func ParseMultiaddrSlice(peers []string) ([]ma.Multiaddr, error) {
 	return out, nil
 }
 
-func (g *GossipRelayNode) background(w client.Watcher) {
+func (g *GossipRelayNode) background(rc *relayedChain, w client.Watcher) {
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 	for {
@@ -142,13 +273,22 @@ func (g *GossipRelayNode) background(w client.Watcher) {
 			select {
 			case res, ok := <-results:
 				if !ok {
-					g.l.Warn("relay_node", "watch channel closed")
+					rc.l.Warn("relay_node", "watch channel closed")
 					break LOOP
 				}
 
 				rd, ok := res.(*client.RandomData)
 				if !ok {
-					g.l.Error("relay_node", "unexpected client result type")
+					rc.l.Error("relay_node", "unexpected client result type")
+					continue
+				}
+				rc.state.received(res.Round())
+
+				// the rounds published or relayed before a restart are
+				// not published again
+				if seen, err := rc.seen.seen(res.Round(), res.Signature()); err == nil && seen {
+					metrics.GossipDeduplicated.WithLabelValues(rc.hash, "local").Inc()
+					rc.l.Debug("relay_node", "round already published", "round", res.Round())
 					continue
 				}
 
@@ -157,19 +297,21 @@ func (g *GossipRelayNode) background(w client.Watcher) {
 					Signature:         res.Signature(),
 					PreviousSignature: rd.PreviousSignature,
 					Randomness:        res.Randomness(),
+					SignatureV2:       rd.SigV2,
 				})
 				if err != nil {
-					g.l.Error("relay_node", "err marshaling", "err", err)
+					rc.l.Error("relay_node", "err marshaling", "err", err)
 					continue
 				}
 
-				err = g.t.Publish(ctx, randB)
+				err = rc.t.Publish(ctx, randB)
 				if err != nil {
-					g.l.Error("relay_node", "err publishing on pubsub", "err", err)
+					rc.l.Error("relay_node", "err publishing on pubsub", "err", err)
 					continue
 				}
+				rc.state.published(rc.hash, res.Round(), time.Now())
 
-				g.l.Info("relay_node", "Published randomness on pubsub", "round", res.Round())
+				rc.l.Info("relay_node", "Published randomness on pubsub", "round", res.Round())
 			case <-g.done:
 				return
 			}
@@ -123,3 +123,59 @@ func TestWatchRetryOnClose(t *testing.T) {
 		t.Fatal("random data items waiting to be consumed", len(results))
 	}
 }
+
+func TestRelayMultipleChains(t *testing.T) {
+	infos := []*chain.Info{{
+		Period:      time.Second,
+		GenesisTime: time.Now().Unix(),
+		PublicKey:   test.GenerateIDs(1)[0].Public.Key,
+	}, {
+		Period:      3 * time.Second,
+		GenesisTime: time.Now().Unix(),
+		PublicKey:   test.GenerateIDs(1)[0].Public.Key,
+	}}
+
+	// each chain is watched on its own
+	var wg sync.WaitGroup
+	wg.Add(len(infos))
+	clients := make([]*mockClient, len(infos))
+	for i, info := range infos {
+		var once sync.Once
+		clients[i] = &mockClient{info, func(ctx context.Context) <-chan client.Result {
+			once.Do(wg.Done)
+			ch := make(chan client.Result)
+			go func() {
+				<-ctx.Done()
+				close(ch)
+			}()
+			return ch
+		}}
+	}
+
+	td := tmpDir(t)
+	defer func() {
+		_ = os.RemoveAll(td)
+	}()
+	cfg := &GossipRelayConfig{
+		ChainHash:    hex.EncodeToString(infos[0].Hash()),
+		Addr:         "/ip4/0.0.0.0/tcp/0",
+		DataDir:      td,
+		IdentityPath: path.Join(td, "identity.key"),
+		Client:       clients[0],
+		Chains: []GossipRelayChain{{
+			ChainHash: hex.EncodeToString(infos[0].Hash()),
+			Client:    clients[1],
+		}},
+	}
+	if _, err := NewGossipRelayNode(log.DefaultLogger(), cfg); err == nil {
+		t.Fatal("expected an error for a chain relayed twice")
+	}
+
+	cfg.Chains[0].ChainHash = hex.EncodeToString(infos[1].Hash())
+	gr, err := NewGossipRelayNode(log.DefaultLogger(), cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer gr.Shutdown()
+	wg.Wait()
+}
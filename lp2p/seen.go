@@ -0,0 +1,73 @@
+package lp2p
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"strconv"
+	"time"
+
+	"github.com/ipfs/go-datastore"
+	"golang.org/x/xerrors"
+)
+
+// seenTTL is how long a relay remembers the rounds it has seen, which covers
+// its restarts and the replays of the rounds of the last day.
+const seenTTL = 24 * time.Hour
+
+// seenPrefix is the datastore prefix of the rounds seen, under which each
+// chain has its own prefix.
+var seenPrefix = datastore.NewKey("/drand/seen")
+
+// seenStore remembers the rounds a relay has seen, by the digest of their
+// signature, across its restarts. The pubsub router only remembers the
+// messages of the last minutes, in memory.
+type seenStore struct {
+	ds     datastore.Datastore
+	prefix datastore.Key
+	ttl    time.Duration
+	now    func() time.Time
+}
+
+func newSeenStore(ds datastore.Datastore, prefix datastore.Key, ttl time.Duration) *seenStore {
+	return &seenStore{ds: ds, prefix: prefix, ttl: ttl, now: time.Now}
+}
+
+func (s *seenStore) key(round uint64) datastore.Key {
+	return s.prefix.ChildString(strconv.FormatUint(round, 10))
+}
+
+// seen tells whether the round was seen with the same signature, and not
+// forgotten since.
+func (s *seenStore) seen(round uint64, sig []byte) (bool, error) {
+	v, err := s.ds.Get(s.key(round))
+	if xerrors.Is(err, datastore.ErrNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	// the value holds the expiry then the digest, as the datastores without
+	// TTL keep the entries forever
+	if len(v) != 8+sha256.Size {
+		return false, nil
+	}
+	expiry := time.Unix(int64(binary.BigEndian.Uint64(v[:8])), 0)
+	if s.now().After(expiry) {
+		return false, nil
+	}
+	digest := sha256.Sum256(sig)
+	return bytes.Equal(v[8:], digest[:]), nil
+}
+
+// add records that the round was seen with the signature.
+func (s *seenStore) add(round uint64, sig []byte) error {
+	digest := sha256.Sum256(sig)
+	v := make([]byte, 8, 8+sha256.Size)
+	binary.BigEndian.PutUint64(v, uint64(s.now().Add(s.ttl).Unix()))
+	v = append(v, digest[:]...)
+	if ttl, ok := s.ds.(datastore.TTLDatastore); ok {
+		return ttl.PutWithTTL(s.key(round), v, s.ttl)
+	}
+	return s.ds.Put(s.key(round), v)
+}
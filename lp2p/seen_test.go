@@ -0,0 +1,41 @@
+package lp2p
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ipfs/go-datastore"
+)
+
+func TestSeenStore(t *testing.T) {
+	ds := datastore.NewMapDatastore()
+	now := time.Now()
+	s := newSeenStore(ds, seenPrefix.ChildString("chain"), time.Hour)
+	s.now = func() time.Time { return now }
+
+	sig := []byte{0x01, 0x02}
+	if seen, err := s.seen(10, sig); err != nil || seen {
+		t.Fatal("expected round 10 not to be seen", seen, err)
+	}
+	if err := s.add(10, sig); err != nil {
+		t.Fatal(err)
+	}
+
+	// the store outlives the relay
+	s = newSeenStore(ds, seenPrefix.ChildString("chain"), time.Hour)
+	s.now = func() time.Time { return now }
+	if seen, err := s.seen(10, sig); err != nil || !seen {
+		t.Fatal("expected round 10 to be seen", seen, err)
+	}
+	if seen, _ := s.seen(10, []byte{0x03}); seen {
+		t.Fatal("expected another signature of round 10 not to be seen")
+	}
+	if seen, _ := s.seen(11, sig); seen {
+		t.Fatal("expected round 11 not to be seen")
+	}
+
+	now = now.Add(2 * time.Hour)
+	if seen, _ := s.seen(10, sig); seen {
+		t.Fatal("expected round 10 to be forgotten")
+	}
+}
@@ -0,0 +1,161 @@
+package lp2p
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/drand/drand/chain"
+	"github.com/drand/drand/metrics"
+)
+
+const (
+	// statusInterval is how often a relay samples the health of the mesh of
+	// its chains into the metrics.
+	statusInterval = 10 * time.Second
+	// healthyLag is the number of rounds a chain may lag behind before its
+	// relay is unhealthy, as the upstream delivers each round a little after
+	// its time.
+	healthyLag = 1
+)
+
+// ChainStatus summarizes the health of a chain relayed by a gossip relay node.
+type ChainStatus struct {
+	ChainHash string `json:"chain_hash"`
+	// Joined tells whether the relay joined the topic of the chain, which it
+	// does once it knows the chain info.
+	Joined        bool       `json:"joined"`
+	MeshPeers     int        `json:"mesh_peers"`
+	CurrentRound  uint64     `json:"current_round"`
+	LastRound     uint64     `json:"last_round"`
+	LastPublished *time.Time `json:"last_published,omitempty"`
+	// Lag is the number of rounds the last round received from the upstream
+	// is behind the current round.
+	Lag     uint64 `json:"lag"`
+	Healthy bool   `json:"healthy"`
+}
+
+// RelayStatus summarizes the health of the mesh of a gossip relay node.
+type RelayStatus struct {
+	PeerID  string        `json:"peer_id"`
+	Peers   int           `json:"peers"`
+	Healthy bool          `json:"healthy"`
+	Chains  []ChainStatus `json:"chains"`
+}
+
+// chainState is what a relay tracks of the rounds of a chain.
+type chainState struct {
+	sync.Mutex
+	info          *chain.Info
+	lastRound     uint64
+	lastPublished time.Time
+}
+
+// received records the last round received from the upstream.
+func (s *chainState) received(round uint64) {
+	s.Lock()
+	defer s.Unlock()
+	if round > s.lastRound {
+		s.lastRound = round
+	}
+}
+
+// published records the publication of a round, and its latency since the
+// time of the round.
+func (s *chainState) published(hash string, round uint64, now time.Time) {
+	s.Lock()
+	s.lastPublished = now
+	s.Unlock()
+	latency := now.Sub(s.info.TimeOfRound(round))
+	metrics.GossipPublishLatency.WithLabelValues(hash).Observe(latency.Seconds())
+}
+
+// status returns the status of the chain at the given time, but the mesh
+// peers.
+func (s *chainState) status(hash string, now time.Time) ChainStatus {
+	s.Lock()
+	defer s.Unlock()
+	st := ChainStatus{
+		ChainHash:    hash,
+		Joined:       true,
+		CurrentRound: s.info.RoundAt(now),
+		LastRound:    s.lastRound,
+	}
+	if !s.lastPublished.IsZero() {
+		published := s.lastPublished
+		st.LastPublished = &published
+	}
+	if st.CurrentRound > st.LastRound {
+		st.Lag = st.CurrentRound - st.LastRound
+	}
+	return st
+}
+
+// Status summarizes the health of the mesh of the relay: a chain is healthy
+// when the relay has peers in its topic and its upstream is not lagging, and
+// the relay is healthy when all its chains are.
+func (g *GossipRelayNode) Status() RelayStatus {
+	now := time.Now()
+	st := RelayStatus{
+		PeerID:  g.h.ID().Pretty(),
+		Peers:   len(g.h.Network().Peers()),
+		Healthy: true,
+	}
+
+	g.mu.Lock()
+	for hash := range g.hashes {
+		cs := ChainStatus{ChainHash: hash}
+		if rc, ok := g.chains[hash]; ok {
+			cs = rc.state.status(hash, now)
+			cs.MeshPeers = len(rc.t.ListPeers())
+			cs.Healthy = cs.MeshPeers > 0 && cs.Lag <= healthyLag
+		}
+		st.Healthy = st.Healthy && cs.Healthy
+		st.Chains = append(st.Chains, cs)
+	}
+	g.mu.Unlock()
+
+	sort.Slice(st.Chains, func(i, j int) bool { return st.Chains[i].ChainHash < st.Chains[j].ChainHash })
+	return st
+}
+
+// StatusHandler serves the status of the relay as JSON, with a 503 status
+// code when the relay is unhealthy.
+func (g *GossipRelayNode) StatusHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		st := g.Status()
+		b, err := json.Marshal(st)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		if !st.Healthy {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+		_, _ = w.Write(b)
+	})
+}
+
+// report samples the mesh peers and the lag of the chains into the metrics
+// until the relay is shut down.
+func (g *GossipRelayNode) report() {
+	ticker := time.NewTicker(statusInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			for _, cs := range g.Status().Chains {
+				if !cs.Joined {
+					continue
+				}
+				metrics.GossipMeshPeers.WithLabelValues(cs.ChainHash).Set(float64(cs.MeshPeers))
+				metrics.GossipUpstreamLag.WithLabelValues(cs.ChainHash).Set(float64(cs.Lag))
+			}
+		case <-g.done:
+			return
+		}
+	}
+}
@@ -0,0 +1,33 @@
+package lp2p
+
+import (
+	"testing"
+	"time"
+
+	"github.com/drand/drand/chain"
+)
+
+func TestChainStateStatus(t *testing.T) {
+	now := time.Now()
+	info := &chain.Info{Period: time.Second, GenesisTime: now.Unix() - 9}
+	s := &chainState{info: info}
+
+	st := s.status("chain", now)
+	if !st.Joined || st.LastPublished != nil {
+		t.Fatal("expected a joined chain not published yet", st)
+	}
+	if st.CurrentRound != 10 || st.Lag != 10 {
+		t.Fatal("expected the chain to lag behind round 10", st.CurrentRound, st.Lag)
+	}
+
+	s.received(9)
+	s.published("chain", 9, now)
+	s.received(8)
+	st = s.status("chain", now)
+	if st.LastRound != 9 || st.Lag != 1 {
+		t.Fatal("expected the chain to lag one round behind", st.LastRound, st.Lag)
+	}
+	if st.LastPublished == nil || !st.LastPublished.Equal(now) {
+		t.Fatal("expected the time of the last publication", st.LastPublished)
+	}
+}
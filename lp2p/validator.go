@@ -0,0 +1,81 @@
+package lp2p
+
+import (
+	"bytes"
+	"context"
+	"time"
+
+	"github.com/drand/drand/chain"
+	"github.com/drand/drand/log"
+	"github.com/drand/drand/metrics"
+	"github.com/drand/drand/protobuf/drand"
+
+	"github.com/libp2p/go-libp2p-core/peer"
+	pubsub "github.com/libp2p/go-libp2p-pubsub"
+	"google.golang.org/protobuf/proto"
+)
+
+// randomnessValidator accepts the messages holding a round of the chain
+// only: the round must be produced by now, its signature must verify against
+// the pinned chain info and its randomness must derive from its signature.
+// The relay neither forwards nor publishes the other messages. The rounds
+// recorded in the seen store, if any, are ignored as replays. The rejections
+// are counted by reason under the chain hash.
+func randomnessValidator(hash string, info *chain.Info, seen *seenStore, l log.Logger) pubsub.ValidatorEx {
+	reject := func(reason string) pubsub.ValidationResult {
+		metrics.GossipValidationFailures.WithLabelValues(hash, reason).Inc()
+		return pubsub.ValidationReject
+	}
+	return func(ctx context.Context, p peer.ID, m *pubsub.Message) pubsub.ValidationResult {
+		var rand drand.PublicRandResponse
+		if err := proto.Unmarshal(m.Data, &rand); err != nil {
+			l.Debug("relay_node", "rejecting malformed message", "peer", p, "err", err)
+			return reject("malformed")
+		}
+
+		if seen != nil {
+			ok, err := seen.seen(rand.GetRound(), rand.GetSignature())
+			if err != nil {
+				l.Warn("relay_node", "could not read seen rounds", "err", err)
+			}
+			if ok {
+				metrics.GossipDeduplicated.WithLabelValues(hash, "peer").Inc()
+				return pubsub.ValidationIgnore
+			}
+		}
+
+		// Unwilling to relay beacons in the future.
+		if info.TimeOfRound(rand.GetRound()).After(time.Now()) {
+			l.Debug("relay_node", "rejecting future round", "peer", p, "round", rand.GetRound())
+			return reject("future")
+		}
+
+		b := chain.Beacon{
+			Round:       rand.GetRound(),
+			Signature:   rand.GetSignature(),
+			SignatureV2: rand.GetSignatureV2(),
+			PreviousSig: rand.GetPreviousSignature(),
+		}
+		if err := info.VerifyBeacon(&b); err != nil {
+			l.Debug("relay_node", "rejecting invalid round", "peer", p, "round", b.Round, "err", err)
+			return reject("invalid")
+		}
+
+		sig := b.Signature
+		if info.V2From != 0 && b.Round >= info.V2From {
+			sig = b.SignatureV2
+		}
+		randomness, err := info.Randomness(sig)
+		if err != nil || !bytes.Equal(randomness, rand.GetRandomness()) {
+			l.Debug("relay_node", "rejecting mismatched randomness", "peer", p, "round", b.Round)
+			return reject("randomness")
+		}
+
+		if seen != nil {
+			if err := seen.add(b.Round, b.Signature); err != nil {
+				l.Warn("relay_node", "could not record seen round", "round", b.Round, "err", err)
+			}
+		}
+		return pubsub.ValidationAccept
+	}
+}
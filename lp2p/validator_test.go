@@ -0,0 +1,57 @@
+package lp2p
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/drand/drand/chain"
+	"github.com/drand/drand/log"
+	"github.com/drand/drand/metrics"
+	"github.com/drand/drand/protobuf/drand"
+	"github.com/drand/drand/test"
+	pubsub "github.com/libp2p/go-libp2p-pubsub"
+	pb "github.com/libp2p/go-libp2p-pubsub/pb"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestRandomnessValidatorRejects(t *testing.T) {
+	info := &chain.Info{
+		Period:      time.Second,
+		GenesisTime: time.Now().Unix() - 10,
+		PublicKey:   test.GenerateIDs(1)[0].Public.Key,
+	}
+	validate := randomnessValidator("chain", info, nil, log.DefaultLogger())
+
+	sig := []byte{0x01, 0x02, 0x03}
+	messages := map[string][]byte{
+		"malformed": []byte("not a round"),
+	}
+	for name, round := range map[string]uint64{"spoofed": 5, "future": 1000} {
+		data, err := proto.Marshal(&drand.PublicRandResponse{
+			Round:             round,
+			Signature:         sig,
+			PreviousSignature: sig,
+			Randomness:        chain.RandomnessFromSignature(sig),
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		messages[name] = data
+	}
+
+	for name, data := range messages {
+		m := &pubsub.Message{Message: &pb.Message{Data: data}}
+		if res := validate(context.Background(), "peer", m); res != pubsub.ValidationReject {
+			t.Errorf("%s message: expected rejection, got %v", name, res)
+		}
+	}
+
+	// the rejections are counted by reason
+	for reason, count := range map[string]float64{"malformed": 1, "future": 1, "invalid": 1} {
+		if n := testutil.ToFloat64(metrics.GossipValidationFailures.WithLabelValues("chain", reason)); n != count {
+			t.Errorf("expected %v %s rejections, got %v", count, reason, n)
+		}
+	}
+}
@@ -49,6 +49,73 @@ var (
 		Name: "last_beacon_round",
 		Help: "Last locally stored beacon",
 	})
+	// PartialDuplicates (Group) how many partial signatures were dropped
+	// without verification as they were already received
+	PartialDuplicates = prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "partial_duplicates",
+		Help: "Number of partial signatures received again and not verified",
+	})
+	// SyncTargetRound (Group) is the last round of the catch-up sync in
+	// progress, zero when the node is not catching up.
+	SyncTargetRound = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "sync_target_round",
+		Help: "Last round of the catch-up sync in progress",
+	})
+	// BeaconPaused (Group) is 1 while the node does not sign the beacons of a
+	// beacon, as paused by its operator. The beacon ID is empty for the default
+	// beacon.
+	BeaconPaused = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "beacon_paused",
+		Help: "Whether the node is paused and does not sign the beacons",
+	}, []string{"beacon_id"})
+	// TLSCertExpiry (Group) is the unix time at which the certificate the
+	// node presents to its peers expires.
+	TLSCertExpiry = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "tls_cert_expiry",
+		Help: "Unix time at which the certificate of the node expires",
+	})
+	// BackupTimestamp (Group) is the unix time of the last successful
+	// backup of the node.
+	BackupTimestamp = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "backup_timestamp",
+		Help: "Unix time of the last successful backup",
+	})
+	// BackupFailures (Group) how many backups failed
+	BackupFailures = prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "backup_failures",
+		Help: "Number of backups that failed",
+	})
+	// SyncPeerRounds (Group) how many rounds were fetched from each peer
+	// while catching up
+	SyncPeerRounds = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "sync_peer_rounds",
+		Help: "Number of rounds fetched from a peer while catching up",
+	}, []string{"peer_address"})
+	// SyncPeerFailures (Group) how many ranges of rounds failed to be fetched
+	// or verified from each peer while catching up
+	SyncPeerFailures = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "sync_peer_failures",
+		Help: "Number of ranges of rounds a peer failed to serve while catching up",
+	}, []string{"peer_address"})
+	// PartialLatency (Group) how long after the start of their round the
+	// partial signatures of each member of the group arrive
+	PartialLatency = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "partial_latency",
+		Help:    "Seconds between the start of a round and the arrival of a partial signature of a member",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"peer_address"})
+	// PartialInvalid (Group) how many partial signatures of each member of
+	// the group did not verify
+	PartialInvalid = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "partial_invalid",
+		Help: "Number of partial signatures of a member which did not verify",
+	}, []string{"peer_address"})
+	// PartialMissedRounds (Group) how many rounds were stored without a
+	// partial signature of each member of the group
+	PartialMissedRounds = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "partial_missed_rounds",
+		Help: "Number of rounds stored without a partial signature of a member",
+	}, []string{"peer_address"})
 
 	// HTTPCallCounter (HTTP) how many http requests
 	HTTPCallCounter = prometheus.NewCounterVec(prometheus.CounterOpts{
@@ -67,6 +134,97 @@ var (
 		Name: "http_in_flight",
 		Help: "A gauge of requests currently being served.",
 	})
+	// HTTPThrottled (HTTP) how many http requests were refused by the rate
+	// limiting, by the kind of the limit (ip or token)
+	HTTPThrottled = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "http_throttled",
+		Help: "Number of HTTP calls refused by the rate limiting",
+	}, []string{"kind"})
+	// HTTPEndpointLatency (HTTP) how long the relay takes to answer the
+	// requests of each endpoint, by chain
+	HTTPEndpointLatency = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "http_endpoint_duration_seconds",
+		Help:    "Seconds taken to answer the requests of an endpoint",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"endpoint", "chain"})
+	// HTTPResponseSize (HTTP) how many bytes the relay sends in the responses
+	// of each endpoint, by chain
+	HTTPResponseSize = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "http_response_size_bytes",
+		Help:    "Size of the responses of an endpoint",
+		Buckets: prometheus.ExponentialBuckets(64, 4, 8),
+	}, []string{"endpoint", "chain"})
+	// HTTPUpstreamLatency (HTTP) how long the upstream of the relay takes to
+	// serve its requests, by chain, operation and outcome
+	HTTPUpstreamLatency = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "http_upstream_duration_seconds",
+		Help:    "Seconds taken by the upstream of the relay to serve a request",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"chain", "operation", "outcome"})
+	// HTTPCacheHitRatio (HTTP) the ratio of the rounds the relay serves from
+	// its cache, by chain
+	HTTPCacheHitRatio = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "http_cache_hit_ratio",
+		Help: "Ratio of the rounds served from the cache of the relay",
+	}, []string{"chain"})
+	// HTTPStreams (HTTP) how many clients follow the chain through each kind
+	// of stream, by chain
+	HTTPStreams = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "http_streams",
+		Help: "Number of clients streaming the rounds",
+	}, []string{"kind", "chain"})
+
+	// Gossip metrics
+
+	// GossipConnections counts the connections of the libp2p host, by path:
+	// direct or relayed through a circuit relay
+	GossipConnections = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "gossip_connections",
+		Help: "Number of connections of the libp2p host, by path",
+	}, []string{"path"})
+
+	// GossipReachability is the reachability of the libp2p host found by
+	// AutoNAT: 0 unknown, 1 public, 2 private
+	GossipReachability = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "gossip_reachability",
+		Help: "Reachability of the libp2p host: 0 unknown, 1 public, 2 private (behind a NAT)",
+	})
+
+	// GossipDeduplicated counts the rounds the gossip relay dropped as seen
+	// before, by chain and source: received from a peer or to publish
+	GossipDeduplicated = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "gossip_deduplicated",
+		Help: "Number of rounds dropped by the gossip relay as seen before, by source",
+	}, []string{"chain", "source"})
+
+	// GossipMeshPeers is the number of peers of the gossip relay in the topic
+	// of each chain
+	GossipMeshPeers = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "gossip_mesh_peers",
+		Help: "Number of peers of the gossip relay in the topic of the chain",
+	}, []string{"chain"})
+
+	// GossipPublishLatency measures the time between the time of a round and
+	// the time the gossip relay publishes it, by chain
+	GossipPublishLatency = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "gossip_publish_latency_seconds",
+		Help:    "Seconds between the time of a round and its publication by the gossip relay",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"chain"})
+
+	// GossipValidationFailures counts the messages rejected by the gossip
+	// relay, by chain and reason: malformed, future, invalid or randomness
+	GossipValidationFailures = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "gossip_validation_failures",
+		Help: "Number of messages rejected by the gossip relay, by reason",
+	}, []string{"chain", "reason"})
+
+	// GossipUpstreamLag is the number of rounds the last round the gossip
+	// relay got from its upstream is behind the current round, by chain
+	GossipUpstreamLag = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "gossip_upstream_lag",
+		Help: "Number of rounds the last round of the upstream of the gossip relay is behind",
+	}, []string{"chain"})
 
 	// Client observation metrics
 
@@ -165,6 +323,17 @@ func bindMetrics() error {
 		GroupConnections,
 		BeaconDiscrepancyLatency,
 		LastBeaconRound,
+		PartialDuplicates,
+		SyncTargetRound,
+		BeaconPaused,
+		TLSCertExpiry,
+		SyncPeerRounds,
+		SyncPeerFailures,
+		BackupTimestamp,
+		BackupFailures,
+		PartialLatency,
+		PartialInvalid,
+		PartialMissedRounds,
 	}
 	for _, c := range group {
 		if err := GroupMetrics.Register(c); err != nil {
@@ -180,6 +349,12 @@ func bindMetrics() error {
 		HTTPCallCounter,
 		HTTPLatency,
 		HTTPInFlight,
+		HTTPThrottled,
+		HTTPEndpointLatency,
+		HTTPResponseSize,
+		HTTPUpstreamLatency,
+		HTTPCacheHitRatio,
+		HTTPStreams,
 	}
 	for _, c := range httpMetrics {
 		if err := HTTPMetrics.Register(c); err != nil {
@@ -190,6 +365,22 @@ func bindMetrics() error {
 		}
 	}
 
+	// Gossip metrics
+	gossipMetrics := []prometheus.Collector{
+		GossipConnections,
+		GossipReachability,
+		GossipDeduplicated,
+		GossipMeshPeers,
+		GossipPublishLatency,
+		GossipValidationFailures,
+		GossipUpstreamLag,
+	}
+	for _, c := range gossipMetrics {
+		if err := PrivateMetrics.Register(c); err != nil {
+			return err
+		}
+	}
+
 	// Client metrics
 	if err := RegisterClientMetrics(ClientMetrics); err != nil {
 		return err
@@ -200,6 +391,12 @@ func bindMetrics() error {
 	return nil
 }
 
+// Bind registers the metrics of drand in their registries, for the processes
+// exporting them without running the metrics server.
+func Bind() error {
+	return bindMetrics()
+}
+
 // RegisterClientMetrics registers drand client metrics with the given registry
 func RegisterClientMetrics(r prometheus.Registerer) error {
 	// Client metrics
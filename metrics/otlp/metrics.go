@@ -0,0 +1,163 @@
+package otlp
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	dto "github.com/prometheus/client_model/go"
+)
+
+type metricsRequest struct {
+	ResourceMetrics []resourceMetrics `json:"resourceMetrics"`
+}
+
+type resourceMetrics struct {
+	Resource     resource       `json:"resource"`
+	ScopeMetrics []scopeMetrics `json:"scopeMetrics"`
+}
+
+type scopeMetrics struct {
+	Scope   scope    `json:"scope"`
+	Metrics []metric `json:"metrics"`
+}
+
+type metric struct {
+	Name        string     `json:"name"`
+	Description string     `json:"description,omitempty"`
+	Sum         *sum       `json:"sum,omitempty"`
+	Gauge       *gauge     `json:"gauge,omitempty"`
+	Histogram   *histogram `json:"histogram,omitempty"`
+}
+
+// aggregationCumulative tells the collector the points of the sums and the
+// histograms accumulate since the start of the process, as the ones of
+// prometheus do.
+const aggregationCumulative = 2
+
+type sum struct {
+	DataPoints             []numberPoint `json:"dataPoints"`
+	AggregationTemporality int           `json:"aggregationTemporality"`
+	IsMonotonic            bool          `json:"isMonotonic"`
+}
+
+type gauge struct {
+	DataPoints []numberPoint `json:"dataPoints"`
+}
+
+type histogram struct {
+	DataPoints             []histogramPoint `json:"dataPoints"`
+	AggregationTemporality int              `json:"aggregationTemporality"`
+}
+
+type numberPoint struct {
+	Attributes        []keyValue `json:"attributes,omitempty"`
+	StartTimeUnixNano string     `json:"startTimeUnixNano,omitempty"`
+	TimeUnixNano      string     `json:"timeUnixNano"`
+	AsDouble          float64    `json:"asDouble"`
+}
+
+type histogramPoint struct {
+	Attributes        []keyValue `json:"attributes,omitempty"`
+	StartTimeUnixNano string     `json:"startTimeUnixNano"`
+	TimeUnixNano      string     `json:"timeUnixNano"`
+	Count             string     `json:"count"`
+	Sum               float64    `json:"sum"`
+	BucketCounts      []string   `json:"bucketCounts"`
+	ExplicitBounds    []float64  `json:"explicitBounds"`
+}
+
+func (e *Exporter) pushMetrics(ctx context.Context) error {
+	families, err := e.gatherer.Gather()
+	if err != nil && len(families) == 0 {
+		return err
+	}
+	metrics := convertFamilies(families, e.start, time.Now())
+	if len(metrics) == 0 {
+		return nil
+	}
+	return e.post(ctx, "/v1/metrics", metricsRequest{ResourceMetrics: []resourceMetrics{{
+		Resource:     e.resource(),
+		ScopeMetrics: []scopeMetrics{{Scope: scope{Name: scopeName}, Metrics: metrics}},
+	}}})
+}
+
+// convertFamilies converts the metrics gathered from prometheus: the counters
+// to monotonic sums, the gauges and the untyped metrics to gauges, and the
+// histograms to histograms. The summaries are skipped.
+func convertFamilies(families []*dto.MetricFamily, start, now time.Time) []metric {
+	startNano, nowNano := unixNano(start), unixNano(now)
+	var metrics []metric
+	for _, f := range families {
+		m := metric{Name: f.GetName(), Description: f.GetHelp()}
+		switch f.GetType() {
+		case dto.MetricType_COUNTER:
+			m.Sum = &sum{AggregationTemporality: aggregationCumulative, IsMonotonic: true}
+			for _, pm := range f.GetMetric() {
+				m.Sum.DataPoints = append(m.Sum.DataPoints, numberPoint{
+					Attributes:        labels(pm),
+					StartTimeUnixNano: startNano,
+					TimeUnixNano:      nowNano,
+					AsDouble:          pm.GetCounter().GetValue(),
+				})
+			}
+		case dto.MetricType_GAUGE, dto.MetricType_UNTYPED:
+			m.Gauge = &gauge{}
+			for _, pm := range f.GetMetric() {
+				value := pm.GetGauge().GetValue()
+				if f.GetType() == dto.MetricType_UNTYPED {
+					value = pm.GetUntyped().GetValue()
+				}
+				m.Gauge.DataPoints = append(m.Gauge.DataPoints, numberPoint{
+					Attributes:   labels(pm),
+					TimeUnixNano: nowNano,
+					AsDouble:     value,
+				})
+			}
+		case dto.MetricType_HISTOGRAM:
+			m.Histogram = &histogram{AggregationTemporality: aggregationCumulative}
+			for _, pm := range f.GetMetric() {
+				m.Histogram.DataPoints = append(m.Histogram.DataPoints, histogramPointOf(pm, startNano, nowNano))
+			}
+		default:
+			continue
+		}
+		metrics = append(metrics, m)
+	}
+	return metrics
+}
+
+// histogramPointOf converts a prometheus histogram, whose buckets count the
+// observations up to their bound, to the buckets of the protocol which count
+// the observations between their bounds, the last one counting the ones over
+// the last bound.
+func histogramPointOf(pm *dto.Metric, startNano, nowNano string) histogramPoint {
+	h := pm.GetHistogram()
+	p := histogramPoint{
+		Attributes:        labels(pm),
+		StartTimeUnixNano: startNano,
+		TimeUnixNano:      nowNano,
+		Count:             uintString(h.GetSampleCount()),
+		Sum:               h.GetSampleSum(),
+	}
+	var below uint64
+	for _, b := range h.GetBucket() {
+		p.ExplicitBounds = append(p.ExplicitBounds, b.GetUpperBound())
+		p.BucketCounts = append(p.BucketCounts, uintString(b.GetCumulativeCount()-below))
+		below = b.GetCumulativeCount()
+	}
+	p.BucketCounts = append(p.BucketCounts, uintString(h.GetSampleCount()-below))
+	return p
+}
+
+func labels(pm *dto.Metric) []keyValue {
+	var attrs []keyValue
+	for _, l := range pm.GetLabel() {
+		attrs = append(attrs, attribute(l.GetName(), l.GetValue()))
+	}
+	return attrs
+}
+
+func uintString(v uint64) string {
+	return strconv.FormatUint(v, 10)
+}
@@ -0,0 +1,187 @@
+// Package otlp exports the metrics and the traces of a drand process to an
+// OpenTelemetry collector, with the OTLP/HTTP protocol in its JSON encoding.
+// The metrics are the ones of a prometheus registry, pushed periodically, so
+// that the collector receives the same series the metrics server serves.
+//
+// The package is a stop-gap covering the few OTLP messages drand needs: it
+// is to be replaced by the exporters of the OpenTelemetry SDK once the module
+// can depend on a stable release of it.
+package otlp
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/drand/drand/log"
+	"github.com/prometheus/client_golang/prometheus"
+
+	json "github.com/nikkolasg/hexjson"
+)
+
+const (
+	// DefaultInterval is how often the metrics and the spans are pushed by
+	// default.
+	DefaultInterval = 15 * time.Second
+	// maxSpans bounds the spans kept between two pushes, the next ones being
+	// dropped while the collector does not keep up.
+	maxSpans = 4096
+	// pushTimeout bounds a push to the collector.
+	pushTimeout = 10 * time.Second
+	scopeName   = "github.com/drand/drand"
+)
+
+// Exporter pushes the metrics of a registry and the spans started with it to
+// a collector. A nil Exporter starts no span.
+type Exporter struct {
+	endpoint string
+	service  string
+	gatherer prometheus.Gatherer
+	interval time.Duration
+	client   *http.Client
+	log      log.Logger
+	// start is the start time of the cumulative series.
+	start time.Time
+
+	lk      sync.Mutex
+	spans   []*Span
+	dropped uint64
+}
+
+// New returns an exporter to the collector at the endpoint, such as
+// http://localhost:4318, pushing the metrics of the gatherer, if not nil, and
+// the spans under the name of the service.
+func New(endpoint, service string, gatherer prometheus.Gatherer, interval time.Duration) *Exporter {
+	if interval <= 0 {
+		interval = DefaultInterval
+	}
+	return &Exporter{
+		endpoint: strings.TrimSuffix(endpoint, "/"),
+		service:  service,
+		gatherer: gatherer,
+		interval: interval,
+		client:   &http.Client{Timeout: pushTimeout},
+		log:      log.DefaultLogger(),
+		start:    time.Now(),
+	}
+}
+
+// Start pushes the metrics and the spans every interval until the context is
+// done, and a last time then.
+func (e *Exporter) Start(ctx context.Context) {
+	ticker := time.NewTicker(e.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			e.Push(ctx)
+		case <-ctx.Done():
+			pctx, cancel := context.WithTimeout(context.Background(), pushTimeout)
+			e.Push(pctx)
+			cancel()
+			return
+		}
+	}
+}
+
+// Push sends the current metrics and the spans ended since the last push.
+func (e *Exporter) Push(ctx context.Context) {
+	if e.gatherer != nil {
+		if err := e.pushMetrics(ctx); err != nil {
+			e.log.Warn("otlp", "failed to push metrics", "err", err)
+		}
+	}
+	if err := e.pushSpans(ctx); err != nil {
+		e.log.Warn("otlp", "failed to push spans", "err", err)
+	}
+}
+
+func (e *Exporter) resource() resource {
+	return resource{Attributes: []keyValue{attribute("service.name", e.service)}}
+}
+
+// post sends a request of the OTLP/HTTP protocol to the path of the
+// collector.
+func (e *Exporter) post(ctx context.Context, path string, body interface{}) error {
+	b, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, e.endpoint+path, bytes.NewReader(b))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(ioutil.Discard, resp.Body)
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("collector answered %s", resp.Status)
+	}
+	return nil
+}
+
+// The messages of the OTLP protocol, in their JSON encoding.
+
+type resource struct {
+	Attributes []keyValue `json:"attributes"`
+}
+
+type scope struct {
+	Name string `json:"name"`
+}
+
+type keyValue struct {
+	Key   string   `json:"key"`
+	Value anyValue `json:"value"`
+}
+
+type anyValue struct {
+	StringValue *string  `json:"stringValue,omitempty"`
+	IntValue    *string  `json:"intValue,omitempty"`
+	DoubleValue *float64 `json:"doubleValue,omitempty"`
+	BoolValue   *bool    `json:"boolValue,omitempty"`
+}
+
+// attribute returns the attribute of a value, which is a string, a bool, an
+// integer or a float, and otherwise formatted as a string.
+func attribute(key string, value interface{}) keyValue {
+	var v anyValue
+	switch value := value.(type) {
+	case string:
+		v.StringValue = &value
+	case bool:
+		v.BoolValue = &value
+	case int:
+		s := strconv.FormatInt(int64(value), 10)
+		v.IntValue = &s
+	case int64:
+		s := strconv.FormatInt(value, 10)
+		v.IntValue = &s
+	case uint64:
+		s := strconv.FormatUint(value, 10)
+		v.IntValue = &s
+	case float64:
+		v.DoubleValue = &value
+	default:
+		s := fmt.Sprint(value)
+		v.StringValue = &s
+	}
+	return keyValue{Key: key, Value: v}
+}
+
+// unixNano encodes a time as the protocol does its 64-bit integers, as a
+// string.
+func unixNano(t time.Time) string {
+	return strconv.FormatInt(t.UnixNano(), 10)
+}
@@ -0,0 +1,116 @@
+package otlp
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/require"
+
+	json "github.com/nikkolasg/hexjson"
+)
+
+// collector records the requests pushed to it, by path.
+type collector struct {
+	sync.Mutex
+	received map[string][]byte
+}
+
+func (c *collector) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	b, _ := ioutil.ReadAll(r.Body)
+	c.Lock()
+	c.received[r.URL.Path] = b
+	c.Unlock()
+	w.WriteHeader(http.StatusOK)
+}
+
+func TestExporter(t *testing.T) {
+	c := &collector{received: make(map[string][]byte)}
+	server := httptest.NewServer(c)
+	defer server.Close()
+
+	registry := prometheus.NewRegistry()
+	counter := prometheus.NewCounterVec(prometheus.CounterOpts{Name: "requests", Help: "requests"}, []string{"endpoint"})
+	histogram := prometheus.NewHistogram(prometheus.HistogramOpts{Name: "latency", Help: "latency", Buckets: []float64{1, 2}})
+	registry.MustRegister(counter, histogram)
+	counter.WithLabelValues("/info").Add(3)
+	histogram.Observe(0.5)
+	histogram.Observe(1.5)
+	histogram.Observe(5)
+
+	e := New(server.URL, "relay", registry, time.Minute)
+	ctx := WithTraceParent(context.Background(), "00-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-01")
+	ctx, root := e.StartSpan(ctx, "GET /info", KindServer)
+	_, child := e.StartSpan(ctx, "upstream info", KindClient)
+	child.SetAttribute("drand.round", uint64(12))
+	child.End(errors.New("unreachable"))
+	root.End(nil)
+	e.Push(context.Background())
+
+	var traces struct {
+		ResourceSpans []struct {
+			ScopeSpans []struct {
+				Spans []spanJSON
+			}
+		}
+	}
+	c.Lock()
+	require.NoError(t, json.Unmarshal(c.received["/v1/traces"], &traces))
+	c.Unlock()
+	spans := traces.ResourceSpans[0].ScopeSpans[0].Spans
+	require.Len(t, spans, 2)
+	// the spans continue the trace of the header
+	require.Equal(t, "0af7651916cd43dd8448eb211c80319c", spans[0].TraceID)
+	require.Equal(t, spans[0].TraceID, spans[1].TraceID)
+	require.Equal(t, spans[1].SpanID, spans[0].ParentSpanID)
+	require.Equal(t, "b7ad6b7169203331", spans[1].ParentSpanID)
+	require.Equal(t, statusError, spans[0].Status.Code)
+	require.Equal(t, "12", *spans[0].Attributes[0].Value.IntValue)
+
+	var metrics struct {
+		ResourceMetrics []struct {
+			ScopeMetrics []struct {
+				Metrics []metric
+			}
+		}
+	}
+	c.Lock()
+	require.NoError(t, json.Unmarshal(c.received["/v1/metrics"], &metrics))
+	c.Unlock()
+	byName := make(map[string]metric)
+	for _, m := range metrics.ResourceMetrics[0].ScopeMetrics[0].Metrics {
+		byName[m.Name] = m
+	}
+	require.NotNil(t, byName["requests"].Sum)
+	require.Equal(t, 3.0, byName["requests"].Sum.DataPoints[0].AsDouble)
+	require.Equal(t, "/info", *byName["requests"].Sum.DataPoints[0].Attributes[0].Value.StringValue)
+	// the cumulative buckets are split between their bounds
+	h := byName["latency"].Histogram
+	require.NotNil(t, h)
+	require.Equal(t, "3", h.DataPoints[0].Count)
+	require.Equal(t, []float64{1, 2}, h.DataPoints[0].ExplicitBounds)
+	require.Equal(t, []string{"1", "1", "1"}, h.DataPoints[0].BucketCounts)
+
+	// the spans are only pushed once
+	c.Lock()
+	delete(c.received, "/v1/traces")
+	c.Unlock()
+	e.Push(context.Background())
+	c.Lock()
+	require.NotContains(t, c.received, "/v1/traces")
+	c.Unlock()
+}
+
+func TestNilExporter(t *testing.T) {
+	var e *Exporter
+	ctx, span := e.StartSpan(context.Background(), "nothing", KindInternal)
+	require.NotNil(t, ctx)
+	span.SetAttribute("key", "value")
+	span.End(nil)
+}
@@ -0,0 +1,185 @@
+package otlp
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// SpanKind tells what a span measures.
+type SpanKind int
+
+// The kinds of the spans, numbered as in the protocol.
+const (
+	KindInternal SpanKind = 1
+	KindServer   SpanKind = 2
+	KindClient   SpanKind = 3
+)
+
+type spanContextKey struct{}
+
+// spanContext identifies a span, possibly of another process.
+type spanContext struct {
+	traceID [16]byte
+	spanID  [8]byte
+}
+
+// Span measures an operation. The methods of a nil Span do nothing.
+type Span struct {
+	e      *Exporter
+	name   string
+	kind   SpanKind
+	ids    spanContext
+	parent [8]byte
+	start  time.Time
+	end    time.Time
+	attrs  []keyValue
+	err    error
+	ended  int32
+}
+
+// StartSpan starts a span, child of the span of the context if any, and
+// returns the context of the new span.
+func (e *Exporter) StartSpan(ctx context.Context, name string, kind SpanKind) (context.Context, *Span) {
+	if e == nil {
+		return ctx, nil
+	}
+	s := &Span{e: e, name: name, kind: kind, start: time.Now()}
+	if parent, ok := ctx.Value(spanContextKey{}).(spanContext); ok {
+		s.ids.traceID = parent.traceID
+		s.parent = parent.spanID
+	} else {
+		_, _ = rand.Read(s.ids.traceID[:])
+	}
+	_, _ = rand.Read(s.ids.spanID[:])
+	return context.WithValue(ctx, spanContextKey{}, s.ids), s
+}
+
+// WithTraceParent returns a context continuing the trace of a W3C traceparent
+// header, such as the one set by a proxy in front of the relay. The context
+// is returned as is for an invalid header.
+func WithTraceParent(ctx context.Context, header string) context.Context {
+	// version-traceid-parentid-flags
+	parts := strings.Split(strings.TrimSpace(header), "-")
+	if len(parts) != 4 || len(parts[1]) != 32 || len(parts[2]) != 16 {
+		return ctx
+	}
+	var sc spanContext
+	if _, err := hex.Decode(sc.traceID[:], []byte(parts[1])); err != nil {
+		return ctx
+	}
+	if _, err := hex.Decode(sc.spanID[:], []byte(parts[2])); err != nil {
+		return ctx
+	}
+	if sc.traceID == [16]byte{} || sc.spanID == [8]byte{} {
+		return ctx
+	}
+	return context.WithValue(ctx, spanContextKey{}, sc)
+}
+
+// SetAttribute sets an attribute of the span, a string, a bool, an integer
+// or a float.
+func (s *Span) SetAttribute(key string, value interface{}) {
+	if s == nil {
+		return
+	}
+	s.attrs = append(s.attrs, attribute(key, value))
+}
+
+// End ends the span, failed if err is not nil, and queues it for the next
+// push.
+func (s *Span) End(err error) {
+	if s == nil || !atomic.CompareAndSwapInt32(&s.ended, 0, 1) {
+		return
+	}
+	s.end = time.Now()
+	s.err = err
+	e := s.e
+	e.lk.Lock()
+	defer e.lk.Unlock()
+	if len(e.spans) >= maxSpans {
+		e.dropped++
+		return
+	}
+	e.spans = append(e.spans, s)
+}
+
+type spanJSON struct {
+	TraceID           string     `json:"traceId"`
+	SpanID            string     `json:"spanId"`
+	ParentSpanID      string     `json:"parentSpanId,omitempty"`
+	Name              string     `json:"name"`
+	Kind              SpanKind   `json:"kind"`
+	StartTimeUnixNano string     `json:"startTimeUnixNano"`
+	EndTimeUnixNano   string     `json:"endTimeUnixNano"`
+	Attributes        []keyValue `json:"attributes,omitempty"`
+	Status            status     `json:"status"`
+}
+
+type status struct {
+	Code    int    `json:"code"`
+	Message string `json:"message,omitempty"`
+}
+
+const (
+	statusOK    = 1
+	statusError = 2
+)
+
+func (s *Span) toJSON() spanJSON {
+	js := spanJSON{
+		TraceID:           hex.EncodeToString(s.ids.traceID[:]),
+		SpanID:            hex.EncodeToString(s.ids.spanID[:]),
+		Name:              s.name,
+		Kind:              s.kind,
+		StartTimeUnixNano: unixNano(s.start),
+		EndTimeUnixNano:   unixNano(s.end),
+		Attributes:        s.attrs,
+		Status:            status{Code: statusOK},
+	}
+	if s.parent != [8]byte{} {
+		js.ParentSpanID = hex.EncodeToString(s.parent[:])
+	}
+	if s.err != nil {
+		js.Status = status{Code: statusError, Message: s.err.Error()}
+	}
+	return js
+}
+
+type tracesRequest struct {
+	ResourceSpans []resourceSpans `json:"resourceSpans"`
+}
+
+type resourceSpans struct {
+	Resource   resource     `json:"resource"`
+	ScopeSpans []scopeSpans `json:"scopeSpans"`
+}
+
+type scopeSpans struct {
+	Scope scope      `json:"scope"`
+	Spans []spanJSON `json:"spans"`
+}
+
+func (e *Exporter) pushSpans(ctx context.Context) error {
+	e.lk.Lock()
+	spans, dropped := e.spans, e.dropped
+	e.spans, e.dropped = nil, 0
+	e.lk.Unlock()
+	if dropped > 0 {
+		e.log.Warn("otlp", "dropped spans", "count", dropped)
+	}
+	if len(spans) == 0 {
+		return nil
+	}
+	js := make([]spanJSON, len(spans))
+	for i, s := range spans {
+		js[i] = s.toJSON()
+	}
+	return e.post(ctx, "/v1/traces", tracesRequest{ResourceSpans: []resourceSpans{{
+		Resource:   e.resource(),
+		ScopeSpans: []scopeSpans{{Scope: scope{Name: scopeName}, Spans: js}},
+	}}})
+}
@@ -0,0 +1,224 @@
+package net
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/drand/drand/log"
+
+	"golang.org/x/crypto/acme"
+	"golang.org/x/crypto/acme/autocert"
+	"golang.org/x/crypto/ocsp"
+)
+
+const (
+	// ocspTimeout is how long fetching an OCSP response may take.
+	ocspTimeout = 10 * time.Second
+	// ocspRetry is how long to wait before fetching again an OCSP response
+	// that could not be fetched.
+	ocspRetry = 5 * time.Minute
+)
+
+// ACME configures the automatic issuance and renewal of the certificates of a
+// public endpoint with an ACME certificate authority, such as Let's Encrypt.
+// The TLS-ALPN-01 challenges are answered on the endpoint itself, and the
+// HTTP-01 challenges on HTTPAddr when it is set.
+type ACME struct {
+	// Domains are the names the certificates are issued for.
+	Domains []string
+	// CacheDir is the folder keeping the account key and the certificates
+	// across restarts, so that they are not issued again.
+	CacheDir string
+	// Email is the contact of the operator, notified of the problems with
+	// the certificates.
+	Email string
+	// DirectoryURL is the directory of the certificate authority, Let's
+	// Encrypt if empty.
+	DirectoryURL string
+	// HTTPAddr is the address answering the HTTP-01 challenges, such as
+	// ":80", and redirecting the other requests to HTTPS.
+	HTTPAddr string
+
+	once    sync.Once
+	manager *autocert.Manager
+	stapler *ocspStapler
+}
+
+func (a *ACME) init() {
+	a.once.Do(func() {
+		a.manager = &autocert.Manager{
+			Prompt:     autocert.AcceptTOS,
+			HostPolicy: autocert.HostWhitelist(a.Domains...),
+			Email:      a.Email,
+		}
+		if a.CacheDir != "" {
+			a.manager.Cache = autocert.DirCache(a.CacheDir)
+		}
+		if a.DirectoryURL != "" {
+			a.manager.Client = &acme.Client{DirectoryURL: a.DirectoryURL}
+		}
+		a.stapler = &ocspStapler{staples: make(map[string]*staple), l: log.DefaultLogger()}
+	})
+}
+
+// TLSConfig completes the TLS configuration of an endpoint with the
+// certificates of the ACME certificate authority, stapled with their OCSP
+// responses.
+func (a *ACME) TLSConfig(config *tls.Config) (*tls.Config, error) {
+	if len(a.Domains) == 0 {
+		return nil, errors.New("acme: no domain given")
+	}
+	a.init()
+	config.Certificates = nil
+	config.GetCertificate = func(hello *tls.ClientHelloInfo) (*tls.Certificate, error) {
+		cert, err := a.manager.GetCertificate(hello)
+		if err != nil || cert == nil {
+			return cert, err
+		}
+		// the certificates answering the challenges are not stapled
+		for _, proto := range hello.SupportedProtos {
+			if proto == acme.ALPNProto {
+				return cert, nil
+			}
+		}
+		return a.stapler.staple(cert), nil
+	}
+	config.NextProtos = append(config.NextProtos, acme.ALPNProto)
+	return config, nil
+}
+
+// ServeHTTPChallenges answers the HTTP-01 challenges on HTTPAddr, if set,
+// until the returned function is called.
+func (a *ACME) ServeHTTPChallenges() (stop func(), err error) {
+	if a.HTTPAddr == "" {
+		return func() {}, nil
+	}
+	a.init()
+	lis, err := net.Listen("tcp", a.HTTPAddr)
+	if err != nil {
+		return nil, fmt.Errorf("acme: %s", err)
+	}
+	srv := &http.Server{Handler: a.manager.HTTPHandler(nil), ReadHeaderTimeout: 10 * time.Second}
+	go func() { _ = srv.Serve(lis) }()
+	return func() { _ = srv.Close() }, nil
+}
+
+// staple is the OCSP response of a certificate.
+type staple struct {
+	raw []byte
+	// refresh is the time at which to fetch a new response.
+	refresh time.Time
+}
+
+// ocspStapler keeps the OCSP responses of the certificates served, and
+// refreshes them in the background halfway through their validity.
+type ocspStapler struct {
+	sync.Mutex
+	staples  map[string]*staple
+	fetching map[string]bool
+	l        log.Logger
+	// fetch is replaced in the tests.
+	fetch func(leaf, issuer *x509.Certificate) (*staple, error)
+}
+
+// staple returns the certificate with its OCSP response, if one was fetched.
+// A certificate without one is served as is while it is fetched.
+func (s *ocspStapler) staple(cert *tls.Certificate) *tls.Certificate {
+	if len(cert.Certificate) < 2 {
+		return cert
+	}
+	key := string(cert.Certificate[0])
+	s.Lock()
+	st := s.staples[key]
+	if (st == nil || time.Now().After(st.refresh)) && !s.fetching[key] {
+		if s.fetching == nil {
+			s.fetching = make(map[string]bool)
+		}
+		s.fetching[key] = true
+		go s.update(key, cert)
+	}
+	s.Unlock()
+	if st == nil || st.raw == nil {
+		return cert
+	}
+	stapled := *cert
+	stapled.OCSPStaple = st.raw
+	return &stapled
+}
+
+func (s *ocspStapler) update(key string, cert *tls.Certificate) {
+	var st *staple
+	leaf, err := x509.ParseCertificate(cert.Certificate[0])
+	if err == nil {
+		var issuer *x509.Certificate
+		if issuer, err = x509.ParseCertificate(cert.Certificate[1]); err == nil {
+			fetch := s.fetch
+			if fetch == nil {
+				fetch = fetchOCSP
+			}
+			st, err = fetch(leaf, issuer)
+		}
+	}
+	s.Lock()
+	defer s.Unlock()
+	delete(s.fetching, key)
+	if err != nil {
+		s.l.Warn("acme", "ocsp", "err", err)
+		// keeps the previous response, which may still be valid
+		if prev, ok := s.staples[key]; ok {
+			prev.refresh = time.Now().Add(ocspRetry)
+		} else {
+			s.staples[key] = &staple{refresh: time.Now().Add(ocspRetry)}
+		}
+		return
+	}
+	s.staples[key] = st
+}
+
+// fetchOCSP fetches the OCSP response of a certificate from its issuer.
+func fetchOCSP(leaf, issuer *x509.Certificate) (*staple, error) {
+	if len(leaf.OCSPServer) == 0 {
+		return nil, errors.New("no OCSP server in the certificate")
+	}
+	req, err := ocsp.CreateRequest(leaf, issuer, nil)
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), ocspTimeout)
+	defer cancel()
+	httpReq, err := http.NewRequestWithContext(ctx, "POST", leaf.OCSPServer[0], bytes.NewReader(req))
+	if err != nil {
+		return nil, err
+	}
+	httpReq.Header.Set("Content-Type", "application/ocsp-request")
+	resp, err := http.DefaultClient.Do(httpReq)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	raw, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	parsed, err := ocsp.ParseResponseForCert(raw, leaf, issuer)
+	if err != nil {
+		return nil, err
+	}
+	if parsed.Status != ocsp.Good {
+		return nil, fmt.Errorf("certificate status %d", parsed.Status)
+	}
+	refresh := parsed.ThisUpdate.Add(time.Hour)
+	if !parsed.NextUpdate.IsZero() {
+		refresh = parsed.ThisUpdate.Add(parsed.NextUpdate.Sub(parsed.ThisUpdate) / 2)
+	}
+	return &staple{raw: raw, refresh: refresh}, nil
+}
@@ -0,0 +1,65 @@
+package net
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/drand/drand/log"
+	"github.com/stretchr/testify/require"
+)
+
+func selfSigned(t *testing.T, serial int64) []byte {
+	priv, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(serial),
+		Subject:      pkix.Name{CommonName: "drand.test"},
+		NotBefore:    time.Now(),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &priv.PublicKey, priv)
+	require.NoError(t, err)
+	return der
+}
+
+func TestACMEConfig(t *testing.T) {
+	_, err := (&ACME{}).TLSConfig(&tls.Config{})
+	require.Error(t, err)
+
+	config, err := (&ACME{Domains: []string{"drand.test"}}).TLSConfig(&tls.Config{NextProtos: []string{"h2"}})
+	require.NoError(t, err)
+	require.NotNil(t, config.GetCertificate)
+	require.Equal(t, []string{"h2", "acme-tls/1"}, config.NextProtos)
+}
+
+func TestOCSPStapler(t *testing.T) {
+	fetched := make(chan struct{}, 1)
+	s := &ocspStapler{
+		staples: make(map[string]*staple),
+		l:       log.DefaultLogger(),
+		fetch: func(leaf, issuer *x509.Certificate) (*staple, error) {
+			defer func() { fetched <- struct{}{} }()
+			return &staple{raw: []byte("ocsp"), refresh: time.Now().Add(time.Hour)}, nil
+		},
+	}
+	cert := &tls.Certificate{Certificate: [][]byte{selfSigned(t, 1), selfSigned(t, 2)}}
+
+	// the response is fetched in the background, the first handshakes are
+	// served without it
+	require.Nil(t, s.staple(cert).OCSPStaple)
+	<-fetched
+	stapled := s.staple(cert)
+	require.Equal(t, []byte("ocsp"), stapled.OCSPStaple)
+	require.Nil(t, cert.OCSPStaple)
+
+	// a certificate without its issuer can not be stapled
+	lone := &tls.Certificate{Certificate: [][]byte{selfSigned(t, 3)}}
+	require.Equal(t, lone, s.staple(lone))
+}
@@ -0,0 +1,198 @@
+package net
+
+import (
+	"context"
+	"encoding/json"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/encoding"
+	"google.golang.org/grpc/status"
+)
+
+// The admin service of the control port serves the administration requests
+// added after the control protobuf service, such as the status of a DKG. Its
+// messages are JSON encoded: a request names a method and holds its
+// parameters, the response is the JSON encoding of the method's result.
+const (
+	adminServiceName = "drand.Admin"
+	adminCallMethod  = "/" + adminServiceName + "/Call"
+	adminWatchMethod = "/" + adminServiceName + "/Watch"
+	adminCodecName   = "json"
+)
+
+func init() {
+	encoding.RegisterCodec(jsonCodec{})
+}
+
+// jsonCodec encodes the messages of the admin service in JSON.
+type jsonCodec struct{}
+
+func (jsonCodec) Marshal(v interface{}) ([]byte, error) {
+	return json.Marshal(v)
+}
+
+func (jsonCodec) Unmarshal(data []byte, v interface{}) error {
+	return json.Unmarshal(data, v)
+}
+
+func (jsonCodec) Name() string {
+	return adminCodecName
+}
+
+// AdminRequest is a request of the admin service.
+type AdminRequest struct {
+	Method string          `json:"method"`
+	Params json.RawMessage `json:"params,omitempty"`
+}
+
+// Decode decodes the parameters of the request into v. A request without
+// parameters leaves v untouched.
+func (r *AdminRequest) Decode(v interface{}) error {
+	if len(r.Params) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(r.Params, v); err != nil {
+		return status.Errorf(codes.InvalidArgument, "invalid parameters of %s: %v", r.Method, err)
+	}
+	return nil
+}
+
+// AdminSender sends the responses of a streaming admin request.
+type AdminSender interface {
+	Context() context.Context
+	Send(v interface{}) error
+}
+
+// AdminServer answers the requests of the admin service.
+type AdminServer interface {
+	// AdminCall answers a request with a single response.
+	AdminCall(ctx context.Context, req *AdminRequest) (interface{}, error)
+	// AdminWatch answers a request with a stream of responses, until it
+	// returns.
+	AdminWatch(req *AdminRequest, stream AdminSender) error
+}
+
+// ErrUnknownAdminMethod returns the error of a request for a method the
+// server does not know.
+func ErrUnknownAdminMethod(method string) error {
+	return status.Errorf(codes.Unimplemented, "unknown admin method %q", method)
+}
+
+var adminServiceDesc = grpc.ServiceDesc{
+	ServiceName: adminServiceName,
+	HandlerType: (*AdminServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Call",
+			Handler:    adminCallHandler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "Watch",
+			Handler:       adminWatchHandler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "admin",
+}
+
+func adminCallHandler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AdminRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdminServer).AdminCall(ctx, req.(*AdminRequest))
+	}
+	if interceptor == nil {
+		return handler(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: adminCallMethod,
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func adminWatchHandler(srv interface{}, stream grpc.ServerStream) error {
+	in := new(AdminRequest)
+	if err := stream.RecvMsg(in); err != nil {
+		return err
+	}
+	return srv.(AdminServer).AdminWatch(in, &adminSender{stream})
+}
+
+type adminSender struct {
+	grpc.ServerStream
+}
+
+func (a *adminSender) Send(v interface{}) error {
+	return a.ServerStream.SendMsg(v)
+}
+
+// registerAdmin registers the admin service on a control server, when it
+// implements it.
+func registerAdmin(s *grpc.Server, srv interface{}) {
+	if a, ok := srv.(AdminServer); ok {
+		s.RegisterService(&adminServiceDesc, a)
+	}
+}
+
+func newAdminRequest(method string, params interface{}) (*AdminRequest, error) {
+	req := &AdminRequest{Method: method}
+	if params != nil {
+		buff, err := json.Marshal(params)
+		if err != nil {
+			return nil, err
+		}
+		req.Params = buff
+	}
+	return req, nil
+}
+
+// AdminCall sends a request to the admin service of the daemon and decodes
+// its response into resp.
+func (c *ControlClient) AdminCall(method string, params, resp interface{}) error {
+	req, err := newAdminRequest(method, params)
+	if err != nil {
+		return err
+	}
+	return c.conn.Invoke(c.context(), adminCallMethod, req, resp, grpc.CallContentSubtype(adminCodecName))
+}
+
+// AdminStream receives the responses of a streaming admin request.
+type AdminStream interface {
+	// Recv decodes the next response into v.
+	Recv(v interface{}) error
+}
+
+type adminStream struct {
+	grpc.ClientStream
+}
+
+func (a *adminStream) Recv(v interface{}) error {
+	return a.ClientStream.RecvMsg(v)
+}
+
+// AdminWatch sends a streaming request to the admin service of the daemon.
+// The stream ends when the context is canceled.
+func (c *ControlClient) AdminWatch(cc context.Context, method string, params interface{}) (AdminStream, error) {
+	req, err := newAdminRequest(method, params)
+	if err != nil {
+		return nil, err
+	}
+	desc := &grpc.StreamDesc{StreamName: "Watch", ServerStreams: true}
+	stream, err := c.conn.NewStream(c.withContext(cc), desc, adminWatchMethod, grpc.CallContentSubtype(adminCodecName))
+	if err != nil {
+		return nil, err
+	}
+	if err := stream.SendMsg(req); err != nil {
+		return nil, err
+	}
+	if err := stream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return &adminStream{stream}, nil
+}
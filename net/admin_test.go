@@ -0,0 +1,163 @@
+package net
+
+import (
+	"context"
+	"errors"
+	"io"
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+
+	control "github.com/drand/drand/protobuf/drand"
+	testnet "github.com/drand/drand/test/net"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+type adminParams struct {
+	N int `json:"n"`
+}
+
+type adminResult struct {
+	Beacon string `json:"beacon"`
+	N      int    `json:"n"`
+}
+
+type adminTestServer struct {
+	testnet.EmptyServer
+	setup *control.SetupInfoPacket
+}
+
+func (a *adminTestServer) AdminCall(ctx context.Context, req *AdminRequest) (interface{}, error) {
+	if req.Method != "double" {
+		return nil, ErrUnknownAdminMethod(req.Method)
+	}
+	p := new(adminParams)
+	if err := req.Decode(p); err != nil {
+		return nil, err
+	}
+	return &adminResult{Beacon: BeaconIDFromContext(ctx), N: 2 * p.N}, nil
+}
+
+func (a *adminTestServer) InitDKG(ctx context.Context, in *control.InitDKGPacket) (*control.GroupPacket, error) {
+	a.setup = in.GetInfo()
+	return new(control.GroupPacket), nil
+}
+
+func (a *adminTestServer) AdminWatch(req *AdminRequest, stream AdminSender) error {
+	p := new(adminParams)
+	if err := req.Decode(p); err != nil {
+		return err
+	}
+	for i := 0; i < p.N; i++ {
+		if err := stream.Send(&adminResult{N: i}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func TestAdminService(t *testing.T) {
+	if !testable() {
+		t.Skip("Platform does not support unix.")
+	}
+	name, err := ioutil.TempDir("", "unixadmin")
+	require.NoError(t, err)
+	defer os.RemoveAll(name)
+	addr := "unix://" + name + "/sock"
+	service := NewTCPGrpcControlListener(&adminTestServer{}, addr)
+	go service.Start()
+	defer service.Stop()
+
+	client, err := NewControlClientForBeacon(addr, "fastnet")
+	require.NoError(t, err)
+	defer client.conn.Close()
+
+	res := new(adminResult)
+	require.NoError(t, client.AdminCall("double", &adminParams{N: 21}, res))
+	require.Equal(t, 42, res.N)
+	require.Equal(t, "fastnet", res.Beacon)
+
+	err = client.AdminCall("unknown", nil, res)
+	require.Error(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	stream, err := client.AdminWatch(ctx, "count", &adminParams{N: 3})
+	require.NoError(t, err)
+	for i := 0; i < 3; i++ {
+		res := new(adminResult)
+		require.NoError(t, stream.Recv(res))
+		require.Equal(t, i, res.N)
+	}
+	require.True(t, errors.Is(stream.Recv(new(adminResult)), io.EOF))
+}
+
+func TestControlAuth(t *testing.T) {
+	if !testable() {
+		t.Skip("Platform does not support unix.")
+	}
+	name, err := ioutil.TempDir("", "unixauth")
+	require.NoError(t, err)
+	defer os.RemoveAll(name)
+	addr := "unix://" + name + "/sock"
+	auth, err := NewControlAuth([]ControlToken{
+		{Name: "monitoring", Role: ControlRoleRead, Token: "read-token-0123456789"},
+		{Name: "operator", Role: ControlRoleAdmin, Token: "admin-token-0123456789"},
+	})
+	require.NoError(t, err)
+	service := NewTCPGrpcControlListener(&adminTestServer{}, addr, auth.ServerOptions()...)
+	go service.Start()
+	defer service.Stop()
+
+	client, err := NewControlClient(addr)
+	require.NoError(t, err)
+	defer client.conn.Close()
+	res := new(adminResult)
+	err = client.AdminCall("double", &adminParams{N: 1}, res)
+	require.Equal(t, codes.Unauthenticated, status.Code(err))
+
+	client.SetToken("read-token-0123456789")
+	err = client.AdminCall("double", &adminParams{N: 1}, res)
+	require.Equal(t, codes.PermissionDenied, status.Code(err))
+	// the status methods are allowed, and reach the server
+	err = client.AdminCall("sync.status", nil, res)
+	require.Equal(t, codes.Unimplemented, status.Code(err))
+
+	client.SetToken("admin-token-0123456789")
+	require.NoError(t, client.AdminCall("double", &adminParams{N: 1}, res))
+	require.Equal(t, 2, res.N)
+
+	_, err = NewControlAuth([]ControlToken{{Name: "short", Role: ControlRoleAdmin, Token: "short"}})
+	require.Error(t, err)
+	_, err = NewControlAuth([]ControlToken{{Name: "root", Role: "root", Token: "root-token-0123456789"}})
+	require.Error(t, err)
+}
+
+func TestControlClientDKGTimeouts(t *testing.T) {
+	if !testable() {
+		t.Skip("Platform does not support unix.")
+	}
+	name, err := ioutil.TempDir("", "unixdkg")
+	require.NoError(t, err)
+	defer os.RemoveAll(name)
+	addr := "unix://" + name + "/sock"
+	server := &adminTestServer{}
+	service := NewTCPGrpcControlListener(server, addr)
+	go service.Start()
+	defer service.Stop()
+
+	client, err := NewControlClient(addr)
+	require.NoError(t, err)
+	defer client.conn.Close()
+	client.SetDKGTimeouts(time.Second, time.Minute, time.Hour)
+	client.SetDKGPhaseRetries(2)
+
+	_, err = client.InitDKGLeader(3, 2, time.Second, time.Second, 10*time.Second, nil, "secret", 0)
+	require.NoError(t, err)
+	require.Equal(t, []uint32{1, 60, 3600}, server.setup.GetPhaseTimeouts())
+	require.Equal(t, uint32(2), server.setup.GetPhaseRetries())
+	require.Equal(t, uint32(10), server.setup.GetTimeout())
+}
@@ -0,0 +1,119 @@
+package net
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/drand/drand/protobuf/drand"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// BeaconIDKey is the gRPC metadata key carrying the ID of the beacon a request
+// is meant for, when a daemon hosts several beacons behind the same
+// listeners. Requests without it are meant for the default beacon.
+const BeaconIDKey = "drand-beacon-id"
+
+// WithBeaconID returns a context whose outgoing requests are meant for the
+// given beacon. The default beacon, with the empty ID, is left implicit so
+// that nodes hosting a single beacon keep understanding the requests.
+func WithBeaconID(ctx context.Context, id string) context.Context {
+	if id == "" {
+		return ctx
+	}
+	return metadata.AppendToOutgoingContext(ctx, BeaconIDKey, id)
+}
+
+// BeaconIDFromContext returns the ID of the beacon an incoming request is
+// meant for, empty for the default beacon.
+func BeaconIDFromContext(ctx context.Context) string {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return ""
+	}
+	if vals := md.Get(BeaconIDKey); len(vals) > 0 {
+		return vals[0]
+	}
+	return ""
+}
+
+// ForBeacon returns a gateway sharing the listener and connections of g whose
+// clients address the requests to the given beacon of the remote nodes. The
+// returned gateway must not be stopped, g owning the connections.
+func (g *PrivateGateway) ForBeacon(id string) *PrivateGateway {
+	if id == "" {
+		return g
+	}
+	c := &beaconClient{
+		ProtocolClient: g.ProtocolClient,
+		PublicClient:   g.PublicClient,
+		id:             id,
+	}
+	return &PrivateGateway{Listener: g.Listener, ProtocolClient: c, PublicClient: c}
+}
+
+// beaconClient tags the requests of a client with a beacon ID.
+type beaconClient struct {
+	ProtocolClient
+	PublicClient
+	id string
+}
+
+func (b *beaconClient) GetIdentity(ctx context.Context, p Peer, in *drand.IdentityRequest, opts ...CallOption) (*drand.Identity, error) {
+	return b.ProtocolClient.GetIdentity(WithBeaconID(ctx, b.id), p, in, opts...)
+}
+
+func (b *beaconClient) SyncChain(ctx context.Context, p Peer, in *drand.SyncRequest, opts ...CallOption) (chan *drand.BeaconPacket, error) {
+	return b.ProtocolClient.SyncChain(WithBeaconID(ctx, b.id), p, in, opts...)
+}
+
+func (b *beaconClient) PartialBeacon(ctx context.Context, p Peer, in *drand.PartialBeaconPacket, opts ...CallOption) error {
+	return b.ProtocolClient.PartialBeacon(WithBeaconID(ctx, b.id), p, in, opts...)
+}
+
+func (b *beaconClient) Departure(ctx context.Context, p Peer, in *drand.DeparturePacket, opts ...CallOption) error {
+	return b.ProtocolClient.Departure(WithBeaconID(ctx, b.id), p, in, opts...)
+}
+
+func (b *beaconClient) BroadcastDKG(ctx context.Context, p Peer, in *drand.DKGPacket, opts ...CallOption) error {
+	return b.ProtocolClient.BroadcastDKG(WithBeaconID(ctx, b.id), p, in, opts...)
+}
+
+func (b *beaconClient) SignalDKGParticipant(ctx context.Context, p Peer, in *drand.SignalDKGPacket, opts ...CallOption) error {
+	return b.ProtocolClient.SignalDKGParticipant(WithBeaconID(ctx, b.id), p, in, opts...)
+}
+
+func (b *beaconClient) PushDKGInfo(ctx context.Context, p Peer, in *drand.DKGInfoPacket, opts ...grpc.CallOption) error {
+	return b.ProtocolClient.PushDKGInfo(WithBeaconID(ctx, b.id), p, in, opts...)
+}
+
+func (b *beaconClient) PublicRandStream(ctx context.Context, p Peer, in *drand.PublicRandRequest, opts ...CallOption) (chan *drand.PublicRandResponse, error) {
+	return b.PublicClient.PublicRandStream(WithBeaconID(ctx, b.id), p, in, opts...)
+}
+
+func (b *beaconClient) PublicRand(ctx context.Context, p Peer, in *drand.PublicRandRequest) (*drand.PublicRandResponse, error) {
+	return b.PublicClient.PublicRand(WithBeaconID(ctx, b.id), p, in)
+}
+
+func (b *beaconClient) PrivateRand(ctx context.Context, p Peer, in *drand.PrivateRandRequest) (*drand.PrivateRandResponse, error) {
+	return b.PublicClient.PrivateRand(WithBeaconID(ctx, b.id), p, in)
+}
+
+func (b *beaconClient) ChainInfo(ctx context.Context, p Peer, in *drand.ChainInfoRequest) (*drand.ChainInfoPacket, error) {
+	return b.PublicClient.ChainInfo(WithBeaconID(ctx, b.id), p, in)
+}
+
+func (b *beaconClient) Home(ctx context.Context, p Peer, in *drand.HomeRequest) (*drand.HomeResponse, error) {
+	return b.PublicClient.Home(WithBeaconID(ctx, b.id), p, in)
+}
+
+// HandleHTTP relays HTTP requests, such as metrics, which are not specific to
+// a beacon.
+func (b *beaconClient) HandleHTTP(p Peer) (http.Handler, error) {
+	hc, ok := b.ProtocolClient.(HTTPClient)
+	if !ok {
+		return nil, errors.New("client does not relay HTTP")
+	}
+	return hc.HandleHTTP(p)
+}
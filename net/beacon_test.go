@@ -0,0 +1,21 @@
+package net
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/metadata"
+)
+
+func TestBeaconID(t *testing.T) {
+	ctx := context.Background()
+	require.Equal(t, ctx, WithBeaconID(ctx, ""))
+	require.Equal(t, "", BeaconIDFromContext(ctx))
+
+	out := WithBeaconID(ctx, "fastnet")
+	md, ok := metadata.FromOutgoingContext(out)
+	require.True(t, ok)
+	in := metadata.NewIncomingContext(ctx, md)
+	require.Equal(t, "fastnet", BeaconIDFromContext(in))
+}
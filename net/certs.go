@@ -13,6 +13,7 @@ import (
 // of certificates coming with the OS (Go's implementation).
 type CertManager struct {
 	pool *x509.CertPool
+	mtls *MTLS
 }
 
 // NewCertManager returns a cert manager filled with the trusted certificates of
@@ -22,7 +23,7 @@ func NewCertManager() *CertManager {
 	if err != nil {
 		panic(err)
 	}
-	return &CertManager{pool}
+	return &CertManager{pool: pool}
 }
 
 // Pool returns the pool of trusted certificates
@@ -43,3 +44,19 @@ func (p *CertManager) Add(certPath string) error {
 	log.DefaultLogger().Debug("cert_manager", "add", "server cert path", certPath)
 	return nil
 }
+
+// EnableMTLS makes the node present the certificate of the given MTLS to its
+// peers, and trust the certificates of its CA.
+func (p *CertManager) EnableMTLS(m *MTLS) {
+	p.mtls = m
+	p.pool.AppendCertsFromPEM(m.caPEM)
+}
+
+// MTLS returns the material of the mutual TLS between the nodes, nil when it
+// is not enabled.
+func (p *CertManager) MTLS() *MTLS {
+	if p == nil {
+		return nil
+	}
+	return p.mtls
+}
@@ -213,6 +213,17 @@ func (g *grpcClient) PartialBeacon(ctx context.Context, p Peer, in *drand.Partia
 	return err
 }
 
+func (g *grpcClient) Departure(ctx context.Context, p Peer, in *drand.DeparturePacket, opts ...CallOption) error {
+	c, err := g.conn(p)
+	if err != nil {
+		return err
+	}
+	client := drand.NewProtocolClient(c)
+	ctx, _ = g.getTimeoutContext(ctx)
+	_, err = client.Departure(ctx, in, opts...)
+	return err
+}
+
 // MaxSyncBuffer is the maximum number of queued rounds when syncing
 const MaxSyncBuffer = 100
 
@@ -283,7 +294,10 @@ func (g *grpcClient) conn(p Peer) (*grpc.ClientConn, error) {
 		} else {
 			var opts []grpc.DialOption
 			opts = append(opts, g.opts...)
-			if g.manager != nil {
+			if m := g.manager.MTLS(); m != nil {
+				creds := credentials.NewTLS(m.ClientConfig(g.manager.Pool()))
+				opts = append(opts, grpc.WithTransportCredentials(creds))
+			} else if g.manager != nil {
 				pool := g.manager.Pool()
 				creds := credentials.NewClientTLSFromCert(pool, "")
 				opts = append(opts, grpc.WithTransportCredentials(creds))
@@ -9,8 +9,10 @@ import (
 
 	control "github.com/drand/drand/protobuf/drand"
 
+	"github.com/drand/drand/audit"
 	"github.com/drand/drand/log"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
 )
 
 // ControlListener is used to keep state of the connections of our drand instance
@@ -19,15 +21,26 @@ type ControlListener struct {
 	lis   net.Listener
 }
 
+// ControlActor is the actor of the audit log of the commands of the control
+// port, when it does not require tokens.
+const ControlActor = "control"
+
 // NewTCPGrpcControlListener registers the pairing between a ControlServer and a grpc server
-func NewTCPGrpcControlListener(s control.ControlServer, controlAddr string) ControlListener {
+func NewTCPGrpcControlListener(s control.ControlServer, controlAddr string, opts ...grpc.ServerOption) ControlListener {
 	lis, err := net.Listen(controlListenAddr(controlAddr))
 	if err != nil {
 		log.DefaultLogger().Error("grpc listener", "failure", "err", err)
 		return ControlListener{}
 	}
-	grpcServer := grpc.NewServer()
+	// the commands run as the control port, or as the token authenticating
+	// them, see ControlAuth
+	actor := grpc.ChainUnaryInterceptor(func(c ctx.Context, req interface{}, info *grpc.UnaryServerInfo,
+		handler grpc.UnaryHandler) (interface{}, error) {
+		return handler(audit.WithActor(c, ControlActor), req)
+	})
+	grpcServer := grpc.NewServer(append([]grpc.ServerOption{actor}, opts...)...)
 	control.RegisterControlServer(grpcServer, s)
+	registerAdmin(grpcServer, s)
 	return ControlListener{conns: grpcServer, lis: lis}
 }
 
@@ -48,6 +61,20 @@ func (g *ControlListener) Stop() {
 type ControlClient struct {
 	conn   *grpc.ClientConn
 	client control.ControlClient
+	// beaconID is the beacon the commands are meant for, empty for the
+	// default beacon of the daemon.
+	beaconID string
+	// dkgTimeouts are the timeouts of each DKG phase in seconds the DKG
+	// commands set, dkgRetries the number of times a phase is extended.
+	dkgTimeouts []uint32
+	dkgRetries  uint32
+	// resharePeriod is the new period of the resharings the client starts.
+	resharePeriod time.Duration
+	// derivation is the randomness derivation of the chains the client
+	// starts.
+	derivation string
+	// token authenticates the commands, see ControlAuth.
+	token string
 }
 
 const grpcDefaultIPNetwork = "tcp"
@@ -69,9 +96,63 @@ func NewControlClient(addr string) (*ControlClient, error) {
 	return &ControlClient{conn: conn, client: c}, nil
 }
 
+// NewControlClientForBeacon creates a client issuing control commands to one of
+// the beacons hosted by a localhost running drand daemon.
+func NewControlClientForBeacon(addr, beaconID string) (*ControlClient, error) {
+	c, err := NewControlClient(addr)
+	if err != nil {
+		return nil, err
+	}
+	c.beaconID = beaconID
+	return c, nil
+}
+
+// SetDKGTimeouts sets the timeouts of the deal, response and justification
+// phases of the DKGs and resharings this client starts as a leader. By
+// default, all phases last the timeout of the command.
+func (c *ControlClient) SetDKGTimeouts(deal, response, justification time.Duration) {
+	c.dkgTimeouts = []uint32{uint32(deal.Seconds()), uint32(response.Seconds()), uint32(justification.Seconds())}
+}
+
+// SetDKGPhaseRetries sets the number of times the deal and response phases of
+// the DKGs and resharings this client starts as a leader are extended while
+// some participants did not complete them. By default, phases are never
+// extended.
+func (c *ControlClient) SetDKGPhaseRetries(retries int) {
+	c.dkgRetries = uint32(retries)
+}
+
+// SetResharePeriod sets the period the chain switches to at the transition of
+// the resharings this client starts as a leader. By default, a resharing keeps
+// the period of the chain.
+func (c *ControlClient) SetResharePeriod(period time.Duration) {
+	c.resharePeriod = period
+}
+
+// SetDerivation sets the ID of the randomness derivation of the chains of the
+// DKGs this client starts as a leader. By default, chains use the default
+// derivation.
+func (c *ControlClient) SetDerivation(id string) {
+	c.derivation = id
+}
+
+// context returns the context of the commands, carrying the beacon they are
+// meant for.
+func (c *ControlClient) context() ctx.Context {
+	return c.withContext(ctx.Background())
+}
+
+func (c *ControlClient) withContext(cc ctx.Context) ctx.Context {
+	cc = WithBeaconID(cc, c.beaconID)
+	if c.token != "" {
+		cc = metadata.AppendToOutgoingContext(cc, controlTokenKey, "Bearer "+c.token)
+	}
+	return cc
+}
+
 // Ping the drand daemon to check if it's up and running
 func (c *ControlClient) Ping() error {
-	_, err := c.client.PingPong(ctx.Background(), &control.Ping{})
+	_, err := c.client.PingPong(c.context(), &control.Ping{})
 	return err
 }
 
@@ -88,17 +169,20 @@ func (c *ControlClient) InitReshareLeader(
 			Location: &control.GroupInfo_Path{Path: oldPath},
 		},
 		Info: &control.SetupInfoPacket{
-			Nodes:        uint32(nodes),
-			Threshold:    uint32(threshold),
-			Leader:       true,
-			Timeout:      uint32(timeout.Seconds()),
-			Secret:       []byte(secret),
-			BeaconOffset: uint32(offset),
+			Nodes:         uint32(nodes),
+			Threshold:     uint32(threshold),
+			Leader:        true,
+			Timeout:       uint32(timeout.Seconds()),
+			Secret:        []byte(secret),
+			BeaconOffset:  uint32(offset),
+			PhaseTimeouts: c.dkgTimeouts,
+			PhaseRetries:  c.dkgRetries,
 		},
 		CatchupPeriodChanged: catchupPeriod >= 0,
 		CatchupPeriod:        uint32(catchupPeriod.Seconds()),
+		BeaconPeriod:         uint32(c.resharePeriod.Seconds()),
 	}
-	return c.client.InitReshare(ctx.Background(), request)
+	return c.client.InitReshare(c.context(), request)
 }
 
 // InitReshare sets up the node to be ready for a resharing protocol.
@@ -115,7 +199,7 @@ func (c *ControlClient) InitReshare(leader Peer, secret, oldPath string, force b
 			Force:         force,
 		},
 	}
-	return c.client.InitReshare(ctx.Background(), request)
+	return c.client.InitReshare(c.context(), request)
 }
 
 // InitDKGLeader sets up the node to be ready for a first DKG protocol.
@@ -129,18 +213,21 @@ func (c *ControlClient) InitDKGLeader(nodes, threshold int,
 	offset int) (*control.GroupPacket, error) {
 	request := &control.InitDKGPacket{
 		Info: &control.SetupInfoPacket{
-			Nodes:        uint32(nodes),
-			Threshold:    uint32(threshold),
-			Leader:       true,
-			Timeout:      uint32(timeout.Seconds()),
-			Secret:       []byte(secret),
-			BeaconOffset: uint32(offset),
+			Nodes:         uint32(nodes),
+			Threshold:     uint32(threshold),
+			Leader:        true,
+			Timeout:       uint32(timeout.Seconds()),
+			Secret:        []byte(secret),
+			BeaconOffset:  uint32(offset),
+			PhaseTimeouts: c.dkgTimeouts,
+			PhaseRetries:  c.dkgRetries,
 		},
 		Entropy:       entropy,
 		BeaconPeriod:  uint32(beaconPeriod.Seconds()),
 		CatchupPeriod: uint32(catchupPeriod.Seconds()),
+		Derivation:    c.derivation,
 	}
-	return c.client.InitDKG(ctx.Background(), request)
+	return c.client.InitDKG(c.context(), request)
 }
 
 // InitDKG sets up the node to be ready for a first DKG protocol.
@@ -154,38 +241,38 @@ func (c *ControlClient) InitDKG(leader Peer, entropy *control.EntropyInfo, secre
 		},
 		Entropy: entropy,
 	}
-	return c.client.InitDKG(ctx.Background(), request)
+	return c.client.InitDKG(c.context(), request)
 }
 
 // Share returns the share of the remote node
 func (c *ControlClient) Share() (*control.ShareResponse, error) {
-	return c.client.Share(ctx.Background(), &control.ShareRequest{})
+	return c.client.Share(c.context(), &control.ShareRequest{})
 }
 
 // PublicKey returns the public key of the remote node
 func (c *ControlClient) PublicKey() (*control.PublicKeyResponse, error) {
-	return c.client.PublicKey(ctx.Background(), &control.PublicKeyRequest{})
+	return c.client.PublicKey(c.context(), &control.PublicKeyRequest{})
 }
 
 // PrivateKey returns the private key of the remote node
 func (c *ControlClient) PrivateKey() (*control.PrivateKeyResponse, error) {
-	return c.client.PrivateKey(ctx.Background(), &control.PrivateKeyRequest{})
+	return c.client.PrivateKey(c.context(), &control.PrivateKeyRequest{})
 }
 
 // ChainInfo returns the collective key of the remote node
 func (c *ControlClient) ChainInfo() (*control.ChainInfoPacket, error) {
-	return c.client.ChainInfo(ctx.Background(), &control.ChainInfoRequest{})
+	return c.client.ChainInfo(c.context(), &control.ChainInfoRequest{})
 }
 
 // GroupFile returns the group file that the drand instance uses at the current
 // time
 func (c *ControlClient) GroupFile() (*control.GroupPacket, error) {
-	return c.client.GroupFile(ctx.Background(), &control.GroupRequest{})
+	return c.client.GroupFile(c.context(), &control.GroupRequest{})
 }
 
 // Shutdown stops the daemon
 func (c *ControlClient) Shutdown() (*control.ShutdownResponse, error) {
-	return c.client.Shutdown(ctx.Background(), &control.ShutdownRequest{})
+	return c.client.Shutdown(c.context(), &control.ShutdownRequest{})
 }
 
 const progressFollowQueue = 100
@@ -197,7 +284,7 @@ func (c *ControlClient) StartFollowChain(cc ctx.Context,
 	tls bool,
 	upTo uint64) (outCh chan *control.FollowProgress,
 	errCh chan error, e error) {
-	stream, err := c.client.StartFollowChain(cc, &control.StartFollowRequest{
+	stream, err := c.client.StartFollowChain(c.withContext(cc), &control.StartFollowRequest{
 		InfoHash: hash,
 		Nodes:    nodes,
 		IsTls:    tls,
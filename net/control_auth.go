@@ -0,0 +1,190 @@
+package net
+
+import (
+	"context"
+	"crypto/subtle"
+	"fmt"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"github.com/drand/drand/audit"
+	"github.com/drand/drand/log"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// The roles of the tokens of the control port. A read token only runs the
+// commands which do not change the node, such as the status commands, while
+// an admin token runs all of them, including the DKG, the resharing and the
+// commands revealing the key material.
+const (
+	ControlRoleRead  = "read"
+	ControlRoleAdmin = "admin"
+)
+
+// controlTokenKey is the metadata key carrying the token of a control command.
+const controlTokenKey = "authorization"
+
+// readOnlyControlMethods are the methods of the control service a read token
+// may call. The admin methods a read token may call are the ones named after
+// a status or a watch, see readOnlyAdminMethod.
+var readOnlyControlMethods = map[string]bool{
+	"/drand.Control/PingPong":  true,
+	"/drand.Control/PublicKey": true,
+	"/drand.Control/ChainInfo": true,
+	"/drand.Control/GroupFile": true,
+	adminWatchMethod:           true,
+}
+
+func readOnlyAdminMethod(method string) bool {
+	return strings.HasSuffix(method, "status") || strings.HasSuffix(method, ".watch")
+}
+
+// ControlToken is a token of the control port and its role.
+type ControlToken struct {
+	// Name identifies the token in the logs, without revealing it.
+	Name  string `toml:"name"`
+	Role  string `toml:"role"`
+	Token string `toml:"token"`
+}
+
+// ControlAuth authenticates the commands of the control port with tokens, and
+// authorizes them according to the role of the token. The denials are logged,
+// and recorded in the audit log if any.
+type ControlAuth struct {
+	tokens []ControlToken
+	l      log.Logger
+	audit  *audit.Log
+}
+
+// NewControlAuth returns the authentication of the control port with the
+// given tokens.
+func NewControlAuth(tokens []ControlToken) (*ControlAuth, error) {
+	if len(tokens) == 0 {
+		return nil, fmt.Errorf("control auth: no token given")
+	}
+	for _, t := range tokens {
+		if t.Role != ControlRoleRead && t.Role != ControlRoleAdmin {
+			return nil, fmt.Errorf("control auth: unknown role %q of token %q", t.Role, t.Name)
+		}
+		if len(t.Token) < 16 {
+			return nil, fmt.Errorf("control auth: token %q shorter than 16 characters", t.Name)
+		}
+	}
+	return &ControlAuth{tokens: tokens, l: log.DefaultLogger().With("control", "auth")}, nil
+}
+
+// LoadControlAuth reads the tokens of the control port from a TOML file
+// holding a [[tokens]] table per token.
+func LoadControlAuth(path string) (*ControlAuth, error) {
+	var file struct {
+		Tokens []ControlToken `toml:"tokens"`
+	}
+	if _, err := toml.DecodeFile(path, &file); err != nil {
+		return nil, fmt.Errorf("control auth: reading %s: %s", path, err)
+	}
+	return NewControlAuth(file.Tokens)
+}
+
+// SetAuditLog records the denied commands in the given audit log.
+func (a *ControlAuth) SetAuditLog(l *audit.Log) {
+	a.audit = l
+}
+
+// tokenActor is the actor of the audit log of the commands authenticated
+// with the token of the given name.
+func tokenActor(name string) string {
+	return "token:" + name
+}
+
+// token returns the token of the control port presented in the context.
+func (a *ControlAuth) token(ctx context.Context) *ControlToken {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return nil
+	}
+	for _, v := range md.Get(controlTokenKey) {
+		given := []byte(strings.TrimPrefix(v, "Bearer "))
+		for i := range a.tokens {
+			if subtle.ConstantTimeCompare(given, []byte(a.tokens[i].Token)) == 1 {
+				return &a.tokens[i]
+			}
+		}
+	}
+	return nil
+}
+
+// authorize returns an error if the token of the context may not call the
+// method, and the command, which is the admin method for the admin calls.
+func (a *ControlAuth) authorize(ctx context.Context, method, command string) error {
+	t := a.token(ctx)
+	if t == nil {
+		a.l.Warn("denied", command, "reason", "no valid token", "from", RemoteAddress(ctx))
+		a.recordDenial(ctx, "unknown", command, "no valid token")
+		return status.Error(codes.Unauthenticated, "control: missing or invalid token")
+	}
+	if t.Role == ControlRoleAdmin {
+		return nil
+	}
+	if readOnlyControlMethods[method] || (method == adminCallMethod && readOnlyAdminMethod(command)) {
+		return nil
+	}
+	a.l.Warn("denied", command, "reason", "read only token", "token", t.Name, "from", RemoteAddress(ctx))
+	a.recordDenial(ctx, tokenActor(t.Name), command, "read only token")
+	return status.Errorf(codes.PermissionDenied, "control: token %q may not run %s", t.Name, command)
+}
+
+func (a *ControlAuth) recordDenial(ctx context.Context, actor, command, reason string) {
+	a.audit.Record(audit.Event{
+		Action:  audit.ActionDenied,
+		Outcome: audit.OutcomeDenied,
+		Actor:   actor,
+		Remote:  RemoteAddress(ctx),
+		Details: map[string]string{"command": command, "reason": reason},
+	})
+}
+
+// ServerOptions returns the interceptors authorizing the commands of the
+// control port, none when a is nil.
+func (a *ControlAuth) ServerOptions() []grpc.ServerOption {
+	if a == nil {
+		return nil
+	}
+	return []grpc.ServerOption{
+		grpc.ChainUnaryInterceptor(func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo,
+			handler grpc.UnaryHandler) (interface{}, error) {
+			command := info.FullMethod
+			if r, ok := req.(*AdminRequest); ok {
+				command = r.Method
+			}
+			if err := a.authorize(ctx, info.FullMethod, command); err != nil {
+				return nil, err
+			}
+			return handler(ctx, req)
+		}),
+		grpc.ChainStreamInterceptor(func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo,
+			handler grpc.StreamHandler) error {
+			if err := a.authorize(ss.Context(), info.FullMethod, info.FullMethod); err != nil {
+				return err
+			}
+			return handler(srv, ss)
+		}),
+	}
+}
+
+// actorStream is a stream running its commands as the actor of its context.
+type actorStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *actorStream) Context() context.Context {
+	return s.ctx
+}
+
+// SetToken sets the token the client presents with its commands.
+func (c *ControlClient) SetToken(token string) {
+	c.token = token
+}
@@ -0,0 +1,115 @@
+package net
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"encoding/base64"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"time"
+
+	"golang.org/x/net/proxy"
+	"google.golang.org/grpc"
+)
+
+// Dialer opens the connections of the node to its peers, for all the traffic
+// between the nodes, including the DKG and the sync of the chain. By default,
+// the connections go through the SOCKS5 proxy set in the ALL_PROXY environment
+// variable, if any, and directly otherwise.
+type Dialer func(ctx context.Context, addr string) (net.Conn, error)
+
+// DialOption returns the option making a gRPC client dial with d.
+func (d Dialer) DialOption() grpc.DialOption {
+	return grpc.WithContextDialer(d)
+}
+
+// NewProxyDialer returns a dialer connecting to the peers through the proxy of
+// the given URL, a SOCKS5 proxy with the socks5 scheme, or a HTTP proxy
+// supporting the CONNECT method with the http or https scheme. The URL may
+// hold the credentials of the proxy.
+func NewProxyDialer(proxyURL string) (Dialer, error) {
+	u, err := url.Parse(proxyURL)
+	if err != nil {
+		return nil, fmt.Errorf("proxy: %s", err)
+	}
+	if u.Host == "" {
+		return nil, fmt.Errorf("proxy: no host in %q", proxyURL)
+	}
+	switch u.Scheme {
+	case "socks5", "socks5h":
+		var auth *proxy.Auth
+		if u.User != nil {
+			password, _ := u.User.Password()
+			auth = &proxy.Auth{User: u.User.Username(), Password: password}
+		}
+		d, err := proxy.SOCKS5("tcp", u.Host, auth, proxy.Direct)
+		if err != nil {
+			return nil, fmt.Errorf("proxy: %s", err)
+		}
+		cd, ok := d.(proxy.ContextDialer)
+		if !ok {
+			return nil, fmt.Errorf("proxy: the SOCKS5 dialer does not support contexts")
+		}
+		return func(ctx context.Context, addr string) (net.Conn, error) {
+			return cd.DialContext(ctx, "tcp", addr)
+		}, nil
+	case "http", "https":
+		return func(ctx context.Context, addr string) (net.Conn, error) {
+			return dialHTTPProxy(ctx, u, addr)
+		}, nil
+	default:
+		return nil, fmt.Errorf("proxy: unsupported scheme %q, expected socks5, http or https", u.Scheme)
+	}
+}
+
+// dialHTTPProxy opens a tunnel to the address through the HTTP proxy.
+func dialHTTPProxy(ctx context.Context, proxyURL *url.URL, addr string) (net.Conn, error) {
+	var d net.Dialer
+	conn, err := d.DialContext(ctx, "tcp", proxyURL.Host)
+	if err != nil {
+		return nil, fmt.Errorf("proxy: %s", err)
+	}
+	if proxyURL.Scheme == "https" {
+		conn = tls.Client(conn, &tls.Config{ServerName: proxyURL.Hostname()})
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+		defer conn.SetDeadline(time.Time{})
+	}
+	req := &http.Request{
+		Method: http.MethodConnect,
+		URL:    &url.URL{Opaque: addr},
+		Host:   addr,
+		Header: make(http.Header),
+	}
+	if proxyURL.User != nil {
+		password, _ := proxyURL.User.Password()
+		credentials := base64.StdEncoding.EncodeToString([]byte(proxyURL.User.Username() + ":" + password))
+		req.Header.Set("Proxy-Authorization", "Basic "+credentials)
+	}
+	if err := req.Write(conn); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("proxy: %s", err)
+	}
+	br := bufio.NewReader(conn)
+	resp, err := http.ReadResponse(br, req)
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("proxy: %s", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		conn.Close()
+		return nil, fmt.Errorf("proxy: connecting to %s: %s", addr, resp.Status)
+	}
+	if br.Buffered() > 0 {
+		// the client speaks first on the tunnel, so the peer may not have sent
+		// anything yet
+		conn.Close()
+		return nil, fmt.Errorf("proxy: unexpected data from %s before the request", addr)
+	}
+	return conn, nil
+}
@@ -0,0 +1,88 @@
+package net
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// connectProxy serves a single CONNECT request, requiring the given
+// authorization, and relays the connection to the requested address.
+func connectProxy(t *testing.T, authorization string) net.Listener {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	go func() {
+		conn, err := l.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		req, err := http.ReadRequest(bufio.NewReader(conn))
+		if err != nil {
+			return
+		}
+		if req.Method != http.MethodConnect || req.Header.Get("Proxy-Authorization") != authorization {
+			_, _ = io.WriteString(conn, "HTTP/1.1 407 Proxy Authentication Required\r\n\r\n")
+			return
+		}
+		target, err := net.Dial("tcp", req.Host)
+		if err != nil {
+			_, _ = io.WriteString(conn, "HTTP/1.1 502 Bad Gateway\r\n\r\n")
+			return
+		}
+		defer target.Close()
+		_, _ = io.WriteString(conn, "HTTP/1.1 200 Connection established\r\n\r\n")
+		go func() { _, _ = io.Copy(target, conn) }()
+		_, _ = io.Copy(conn, target)
+	}()
+	return l
+}
+
+func TestProxyDialer(t *testing.T) {
+	echo, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer echo.Close()
+	go func() {
+		conn, err := echo.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		_, _ = io.Copy(conn, conn)
+	}()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	proxy := connectProxy(t, "Basic dXNlcjpwYXNz")
+	defer proxy.Close()
+	dial, err := NewProxyDialer("http://user:pass@" + proxy.Addr().String())
+	require.NoError(t, err)
+	conn, err := dial(ctx, echo.Addr().String())
+	require.NoError(t, err)
+	defer conn.Close()
+	_, err = conn.Write([]byte("ping"))
+	require.NoError(t, err)
+	reply := make([]byte, 4)
+	_, err = io.ReadFull(conn, reply)
+	require.NoError(t, err)
+	require.Equal(t, "ping", string(reply))
+
+	// the proxy refuses the connections without the credentials
+	denying := connectProxy(t, "Basic dXNlcjpwYXNz")
+	defer denying.Close()
+	dial, err = NewProxyDialer("http://" + denying.Addr().String())
+	require.NoError(t, err)
+	_, err = dial(ctx, echo.Addr().String())
+	require.Error(t, err)
+
+	_, err = NewProxyDialer("ftp://127.0.0.1:21")
+	require.Error(t, err)
+	_, err = NewProxyDialer("socks5://127.0.0.1:1080")
+	require.NoError(t, err)
+}
@@ -56,7 +56,7 @@ func NewGRPCPrivateGateway(ctx context.Context,
 	s Service,
 	insecure bool,
 	opts ...grpc.DialOption) (*PrivateGateway, error) {
-	l, err := NewGRPCListenerForPrivate(ctx, listen, certPath, keyPath, s, insecure, grpc.ConnectionTimeout(time.Second))
+	l, err := newGRPCListenerForPrivate(ctx, listen, certPath, keyPath, certs.MTLS(), s, insecure, grpc.ConnectionTimeout(time.Second))
 	if err != nil {
 		return nil, err
 	}
@@ -105,3 +105,13 @@ func NewRESTPublicGateway(
 	}
 	return &PublicGateway{Listener: l}, nil
 }
+
+// NewACMEPublicGateway returns a gateway listening on "listen" for the public
+// methods over TLS, with the certificates of an ACME certificate authority.
+func NewACMEPublicGateway(ctx context.Context, listen string, a *ACME, handler http.Handler) (*PublicGateway, error) {
+	l, err := NewRESTListenerForPublicACME(ctx, listen, a, handler)
+	if err != nil {
+		return nil, err
+	}
+	return &PublicGateway{Listener: l}, nil
+}
@@ -3,6 +3,7 @@ package net
 import (
 	"context"
 	"crypto/tls"
+	"errors"
 	"net"
 	"net/http"
 
@@ -30,18 +31,37 @@ func NewGRPCListenerForPrivate(
 	s Service,
 	insecure bool,
 	opts ...grpc.ServerOption) (Listener, error) {
+	return newGRPCListenerForPrivate(ctx, bindingAddr, certPath, keyPath, nil, s, insecure, opts...)
+}
+
+// newGRPCListenerForPrivate creates the listener, which presents the
+// certificate of mtls and requires one from the callers of the Protocol API
+// when it is not nil.
+func newGRPCListenerForPrivate(
+	ctx context.Context,
+	bindingAddr, certPath, keyPath string,
+	mtls *MTLS,
+	s Service,
+	insecure bool,
+	opts ...grpc.ServerOption) (Listener, error) {
 	lis, err := net.Listen("tcp", bindingAddr)
 	if err != nil {
 		return nil, err
 	}
+	if mtls != nil && insecure {
+		return nil, errors.New("mutual TLS requires TLS")
+	}
 
-	if !insecure {
+	if !insecure && mtls == nil {
 		grpcCreds, err := credentials.NewServerTLSFromFile(certPath, keyPath)
 		if err != nil {
 			return nil, err
 		}
 		opts = append(opts, grpc.Creds(grpcCreds))
 	}
+	if mtls != nil {
+		opts = append(opts, mtls.ServerOptions()...)
+	}
 	opts = append(opts,
 		grpc.StreamInterceptor(grpc_prometheus.StreamServerInterceptor),
 		grpc.UnaryInterceptor(grpc_prometheus.UnaryServerInterceptor))
@@ -57,13 +77,16 @@ func NewGRPCListenerForPrivate(
 			lis:        lis,
 		}
 	} else {
-		x509KeyPair, err := tls.LoadX509KeyPair(certPath, keyPath)
-		if err != nil {
-			return nil, err
-		}
-
-		gr := &restListener{
-			restServer: buildTLSServer(grpcServer, &x509KeyPair),
+		gr := &restListener{}
+		if mtls != nil {
+			gr.restServer = buildTLSServer(grpcServer, nil)
+			mtls.ServerConfig(gr.restServer.TLSConfig)
+		} else {
+			x509KeyPair, err := tls.LoadX509KeyPair(certPath, keyPath)
+			if err != nil {
+				return nil, err
+			}
+			gr.restServer = buildTLSServer(grpcServer, &x509KeyPair)
 		}
 		gr.lis = tls.NewListener(lis, gr.restServer.TLSConfig)
 		g = gr
@@ -105,7 +128,36 @@ func NewRESTListenerForPublic(
 	return g, nil
 }
 
+// NewRESTListenerForPublicACME creates a new listener for the Public API over
+// REST with TLS, whose certificates are issued and renewed by an ACME
+// certificate authority.
+func NewRESTListenerForPublicACME(
+	ctx context.Context,
+	bindingAddr string,
+	a *ACME,
+	handler http.Handler) (Listener, error) {
+	server := buildTLSServer(handler, nil)
+	if _, err := a.TLSConfig(server.TLSConfig); err != nil {
+		return nil, err
+	}
+	stop, err := a.ServeHTTPChallenges()
+	if err != nil {
+		return nil, err
+	}
+	lis, err := net.Listen("tcp", bindingAddr)
+	if err != nil {
+		stop()
+		return nil, err
+	}
+	server.RegisterOnShutdown(stop)
+	return &restListener{restServer: server, lis: tls.NewListener(lis, server.TLSConfig)}, nil
+}
+
 func buildTLSServer(httpHandler http.Handler, x509KeyPair *tls.Certificate) *http.Server {
+	var certs []tls.Certificate
+	if x509KeyPair != nil {
+		certs = []tls.Certificate{*x509KeyPair}
+	}
 	return &http.Server{
 		Handler: httpHandler,
 		TLSConfig: &tls.Config{
@@ -134,7 +186,7 @@ func buildTLSServer(httpHandler http.Handler, x509KeyPair *tls.Certificate) *htt
 			},
 			// End Cloudflare recommendations.
 
-			Certificates: []tls.Certificate{*x509KeyPair},
+			Certificates: certs,
 			NextProtos:   []string{"h2"},
 		},
 	}
@@ -178,7 +230,22 @@ func (g *grpcListener) Start() {
 	}()
 }
 
+// Stop closes the listener. When the context has a deadline, the calls in
+// progress are given until then to finish.
 func (g *grpcListener) Stop(ctx context.Context) {
-	_ = g.lis.Close()
-	g.grpcServer.Stop()
+	if _, ok := ctx.Deadline(); !ok {
+		_ = g.lis.Close()
+		g.grpcServer.Stop()
+		return
+	}
+	done := make(chan bool)
+	go func() {
+		g.grpcServer.GracefulStop()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-ctx.Done():
+		g.grpcServer.Stop()
+	}
 }
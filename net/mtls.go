@@ -0,0 +1,248 @@
+package net
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/drand/drand/log"
+	"github.com/drand/drand/metrics"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// DefaultMTLSReloadInterval is how often the files of the certificate of a
+// node are checked for a rotated certificate.
+const DefaultMTLSReloadInterval = time.Minute
+
+// MTLSExpiryWarning is how long before the expiry of the certificate of the
+// node a warning is logged, if it was not rotated.
+var MTLSExpiryWarning = 7 * 24 * time.Hour
+
+// protocolService is the prefix of the methods of the protocol API, which only
+// the nodes presenting a certificate of the CA may call when mutual TLS is
+// enabled.
+const protocolService = "/drand.Protocol/"
+
+// MTLS is the material of the mutual TLS between the nodes: the certificate of
+// the node, issued by the CA of the operators, and the CA which must have
+// issued the certificates of the peers. The certificate is reloaded when its
+// files change, so that a certificate rotated by an external issuer is used
+// for the new connections, while the established ones keep running.
+type MTLS struct {
+	sync.RWMutex
+	certPath string
+	keyPath  string
+	ca       *x509.CertPool
+	caPEM    []byte
+	cert     *tls.Certificate
+	notAfter time.Time
+	modTime  time.Time
+	warned   bool
+	l        log.Logger
+}
+
+// NewMTLS loads the certificate and key of the node, and the CA bundle of the
+// operators.
+func NewMTLS(certPath, keyPath, caPath string) (*MTLS, error) {
+	pem, err := ioutil.ReadFile(caPath)
+	if err != nil {
+		return nil, err
+	}
+	ca := x509.NewCertPool()
+	if !ca.AppendCertsFromPEM(pem) {
+		return nil, fmt.Errorf("mtls: no certificate in %s", caPath)
+	}
+	m := &MTLS{
+		certPath: certPath,
+		keyPath:  keyPath,
+		ca:       ca,
+		caPEM:    pem,
+		l:        log.DefaultLogger().With("mtls", certPath),
+	}
+	if _, err := m.Reload(); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// CA returns the pool of the certificates of the CA of the operators.
+func (m *MTLS) CA() *x509.CertPool {
+	return m.ca
+}
+
+// NotAfter returns the expiry time of the certificate in use.
+func (m *MTLS) NotAfter() time.Time {
+	m.RLock()
+	defer m.RUnlock()
+	return m.notAfter
+}
+
+// Reload loads the certificate again if its files changed since it was
+// loaded, and returns true if it did. A certificate which is not issued by the
+// CA is refused, and the previous one stays in use.
+func (m *MTLS) Reload() (bool, error) {
+	modTime, err := lastModified(m.certPath, m.keyPath)
+	if err != nil {
+		return false, err
+	}
+	m.RLock()
+	unchanged := m.cert != nil && modTime.Equal(m.modTime)
+	m.RUnlock()
+	if unchanged {
+		return false, nil
+	}
+	cert, err := tls.LoadX509KeyPair(m.certPath, m.keyPath)
+	if err != nil {
+		return false, fmt.Errorf("mtls: %s", err)
+	}
+	leaf, err := x509.ParseCertificate(cert.Certificate[0])
+	if err != nil {
+		return false, fmt.Errorf("mtls: %s", err)
+	}
+	intermediates := x509.NewCertPool()
+	for _, c := range cert.Certificate[1:] {
+		if ic, err := x509.ParseCertificate(c); err == nil {
+			intermediates.AddCert(ic)
+		}
+	}
+	_, err = leaf.Verify(x509.VerifyOptions{
+		Roots:         m.ca,
+		Intermediates: intermediates,
+		KeyUsages:     []x509.ExtKeyUsage{x509.ExtKeyUsageAny},
+	})
+	if err != nil {
+		return false, fmt.Errorf("mtls: certificate not issued by the CA: %s", err)
+	}
+	cert.Leaf = leaf
+	m.Lock()
+	m.cert = &cert
+	m.notAfter = leaf.NotAfter
+	m.modTime = modTime
+	m.warned = false
+	m.Unlock()
+	metrics.TLSCertExpiry.Set(float64(leaf.NotAfter.Unix()))
+	m.l.Info("mtls", "loaded", "not_after", leaf.NotAfter)
+	return true, nil
+}
+
+func lastModified(paths ...string) (time.Time, error) {
+	var last time.Time
+	for _, p := range paths {
+		info, err := os.Stat(p)
+		if err != nil {
+			return last, err
+		}
+		if info.ModTime().After(last) {
+			last = info.ModTime()
+		}
+	}
+	return last, nil
+}
+
+// Watch reloads the certificate at the given interval, until the context is
+// done, and warns when it nears its expiry without being rotated.
+func (m *MTLS) Watch(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return
+		}
+		if _, err := m.Reload(); err != nil {
+			m.l.Error("mtls", "reload", "err", err)
+		}
+		m.Lock()
+		expiring := !m.warned && time.Until(m.notAfter) < MTLSExpiryWarning
+		if expiring {
+			m.warned = true
+		}
+		m.Unlock()
+		if expiring {
+			m.l.Warn("mtls", "certificate expires soon", "not_after", m.NotAfter())
+		}
+	}
+}
+
+func (m *MTLS) certificate() *tls.Certificate {
+	m.RLock()
+	defer m.RUnlock()
+	return m.cert
+}
+
+// ServerConfig sets the given configuration of a server to present the
+// certificate in use, and to verify the certificates the clients present. The
+// clients without a certificate are accepted, as the public API is served on
+// the same port, but only the peers presenting a certificate of the CA may
+// call the protocol API, see the interceptors.
+func (m *MTLS) ServerConfig(config *tls.Config) {
+	config.Certificates = nil
+	config.GetCertificate = func(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+		return m.certificate(), nil
+	}
+	config.ClientAuth = tls.VerifyClientCertIfGiven
+	config.ClientCAs = m.ca
+}
+
+// ClientConfig returns the configuration of a client presenting the
+// certificate in use, and trusting the given pool.
+func (m *MTLS) ClientConfig(roots *x509.CertPool) *tls.Config {
+	return &tls.Config{
+		RootCAs: roots,
+		GetClientCertificate: func(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+			return m.certificate(), nil
+		},
+	}
+}
+
+var errNoPeerCert = errors.New("mtls: the protocol API requires a certificate of the CA")
+
+// checkPeer returns an error if the method is part of the protocol API and the
+// caller did not present a certificate of the CA.
+func checkPeer(ctx context.Context, method string) error {
+	if !strings.HasPrefix(method, protocolService) {
+		return nil
+	}
+	p, ok := peer.FromContext(ctx)
+	if !ok {
+		return status.Error(codes.Unauthenticated, errNoPeerCert.Error())
+	}
+	info, ok := p.AuthInfo.(credentials.TLSInfo)
+	if !ok || len(info.State.VerifiedChains) == 0 {
+		return status.Error(codes.Unauthenticated, errNoPeerCert.Error())
+	}
+	return nil
+}
+
+// ServerOptions returns the interceptors refusing the calls to the protocol
+// API from the peers without a certificate of the CA.
+func (m *MTLS) ServerOptions() []grpc.ServerOption {
+	return []grpc.ServerOption{
+		grpc.ChainUnaryInterceptor(func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo,
+			handler grpc.UnaryHandler) (interface{}, error) {
+			if err := checkPeer(ctx, info.FullMethod); err != nil {
+				return nil, err
+			}
+			return handler(ctx, req)
+		}),
+		grpc.ChainStreamInterceptor(func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo,
+			handler grpc.StreamHandler) error {
+			if err := checkPeer(ss.Context(), info.FullMethod); err != nil {
+				return err
+			}
+			return handler(srv, ss)
+		}),
+	}
+}
@@ -0,0 +1,115 @@
+package net
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"path"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/peer"
+)
+
+type testCA struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+	pem  []byte
+}
+
+func newTestCA(t *testing.T) *testCA {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "operators"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(24 * time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	require.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+	return &testCA{cert, key, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})}
+}
+
+// issue writes a certificate of the CA valid for the given duration, and its
+// key, at the given paths.
+func (ca *testCA) issue(t *testing.T, certPath, keyPath string, validity time.Duration) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: "node"},
+		DNSNames:     []string{"localhost"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(validity),
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, ca.cert, &key.PublicKey, ca.key)
+	require.NoError(t, err)
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+	require.NoError(t, ioutil.WriteFile(certPath, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0600))
+	require.NoError(t, ioutil.WriteFile(keyPath, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0600))
+}
+
+func TestMTLSReload(t *testing.T) {
+	tmp, err := ioutil.TempDir("", "mtls")
+	require.NoError(t, err)
+	defer os.RemoveAll(tmp)
+	certPath, keyPath, caPath := path.Join(tmp, "node.crt"), path.Join(tmp, "node.key"), path.Join(tmp, "ca.crt")
+
+	ca := newTestCA(t)
+	require.NoError(t, ioutil.WriteFile(caPath, ca.pem, 0600))
+	ca.issue(t, certPath, keyPath, time.Hour)
+	m, err := NewMTLS(certPath, keyPath, caPath)
+	require.NoError(t, err)
+	first := m.NotAfter()
+
+	reloaded, err := m.Reload()
+	require.NoError(t, err)
+	require.False(t, reloaded)
+
+	// a rotated certificate is used for the new connections
+	ca.issue(t, certPath, keyPath, 2*time.Hour)
+	later := time.Now().Add(time.Minute)
+	require.NoError(t, os.Chtimes(certPath, later, later))
+	reloaded, err = m.Reload()
+	require.NoError(t, err)
+	require.True(t, reloaded)
+	require.True(t, m.NotAfter().After(first))
+	config := &tls.Config{}
+	m.ServerConfig(config)
+	cert, err := config.GetCertificate(nil)
+	require.NoError(t, err)
+	require.Equal(t, m.NotAfter(), cert.Leaf.NotAfter)
+
+	// a certificate of another CA is refused, and the current one kept
+	newTestCA(t).issue(t, certPath, keyPath, 3*time.Hour)
+	later = later.Add(time.Minute)
+	require.NoError(t, os.Chtimes(certPath, later, later))
+	_, err = m.Reload()
+	require.Error(t, err)
+	require.Equal(t, cert, m.certificate())
+}
+
+func TestMTLSCheckPeer(t *testing.T) {
+	ctx := context.Background()
+	require.NoError(t, checkPeer(ctx, "/drand.Public/PublicRand"))
+	require.Error(t, checkPeer(ctx, "/drand.Protocol/PartialBeacon"))
+	anonymous := peer.NewContext(ctx, &peer.Peer{})
+	require.Error(t, checkPeer(anonymous, "/drand.Protocol/PartialBeacon"))
+}
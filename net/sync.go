@@ -0,0 +1,41 @@
+package net
+
+import (
+	"context"
+	"strconv"
+
+	"google.golang.org/grpc/metadata"
+)
+
+// SyncUpToKey is the gRPC metadata key carrying the last round a sync request
+// asks for, as the sync requests only hold the first round. A node streaming
+// its chain stops after that round instead of following the chain, so that a
+// syncing node can fetch ranges of rounds from several peers.
+const SyncUpToKey = "drand-sync-up-to"
+
+// WithSyncUpTo returns a context whose sync requests ask for the rounds up to
+// the given one. A zero round asks to follow the chain.
+func WithSyncUpTo(ctx context.Context, upTo uint64) context.Context {
+	if upTo == 0 {
+		return ctx
+	}
+	return metadata.AppendToOutgoingContext(ctx, SyncUpToKey, strconv.FormatUint(upTo, 10))
+}
+
+// SyncUpToFromContext returns the last round an incoming sync request asks
+// for, zero when it asks to follow the chain.
+func SyncUpToFromContext(ctx context.Context) uint64 {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return 0
+	}
+	vals := md.Get(SyncUpToKey)
+	if len(vals) == 0 {
+		return 0
+	}
+	upTo, err := strconv.ParseUint(vals[0], 10, 64)
+	if err != nil {
+		return 0
+	}
+	return upTo
+}
@@ -151,8 +151,15 @@ type DKGInfoPacket struct {
 	// timeout in seconds
 	DkgTimeout uint32 `protobuf:"varint,3,opt,name=dkg_timeout,json=dkgTimeout,proto3" json:"dkg_timeout,omitempty"`
 	// signature from the coordinator to prove he is the one sending that group
-	// file.
+	// file. It also covers the timeout and the phase timeouts and retries when
+	// these are set.
 	Signature []byte `protobuf:"bytes,4,opt,name=signature,proto3" json:"signature,omitempty"`
+	// timeouts of the deal, response and justification phases in seconds,
+	// overriding dkg_timeout when set.
+	PhaseTimeouts []uint32 `protobuf:"varint,5,rep,packed,name=phase_timeouts,json=phaseTimeouts,proto3" json:"phase_timeouts,omitempty"`
+	// number of times a deal or response phase is extended while some
+	// participants did not complete it.
+	PhaseRetries uint32 `protobuf:"varint,6,opt,name=phase_retries,json=phaseRetries,proto3" json:"phase_retries,omitempty"`
 }
 
 func (x *DKGInfoPacket) Reset() {
@@ -215,6 +222,20 @@ func (x *DKGInfoPacket) GetSignature() []byte {
 	return nil
 }
 
+func (x *DKGInfoPacket) GetPhaseTimeouts() []uint32 {
+	if x != nil {
+		return x.PhaseTimeouts
+	}
+	return nil
+}
+
+func (x *DKGInfoPacket) GetPhaseRetries() uint32 {
+	if x != nil {
+		return x.PhaseRetries
+	}
+	return 0
+}
+
 type PartialBeaconPacket struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
@@ -293,6 +314,74 @@ func (x *PartialBeaconPacket) GetPartialSigV2() []byte {
 	return nil
 }
 
+// DeparturePacket is the packet a node stopping gracefully sends to the other
+// nodes of its group, so that they stop sending it partial signatures.
+type DeparturePacket struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// address of the node in the group
+	Address string `protobuf:"bytes,1,opt,name=address,proto3" json:"address,omitempty"`
+	// last round the node signed before stopping
+	Round uint64 `protobuf:"varint,2,opt,name=round,proto3" json:"round,omitempty"`
+	// signature of the node with its key over the address and the round
+	Signature []byte `protobuf:"bytes,3,opt,name=signature,proto3" json:"signature,omitempty"`
+}
+
+func (x *DeparturePacket) Reset() {
+	*x = DeparturePacket{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_drand_protocol_proto_msgTypes[4]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *DeparturePacket) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeparturePacket) ProtoMessage() {}
+
+func (x *DeparturePacket) ProtoReflect() protoreflect.Message {
+	mi := &file_drand_protocol_proto_msgTypes[4]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeparturePacket.ProtoReflect.Descriptor instead.
+func (*DeparturePacket) Descriptor() ([]byte, []int) {
+	return file_drand_protocol_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *DeparturePacket) GetAddress() string {
+	if x != nil {
+		return x.Address
+	}
+	return ""
+}
+
+func (x *DeparturePacket) GetRound() uint64 {
+	if x != nil {
+		return x.Round
+	}
+	return 0
+}
+
+func (x *DeparturePacket) GetSignature() []byte {
+	if x != nil {
+		return x.Signature
+	}
+	return nil
+}
+
 // DKGPacket is the packet that nodes send to others nodes as part of the
 // broadcasting protocol.
 type DKGPacket struct {
@@ -306,7 +395,7 @@ type DKGPacket struct {
 func (x *DKGPacket) Reset() {
 	*x = DKGPacket{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_drand_protocol_proto_msgTypes[4]
+		mi := &file_drand_protocol_proto_msgTypes[5]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -319,7 +408,7 @@ func (x *DKGPacket) String() string {
 func (*DKGPacket) ProtoMessage() {}
 
 func (x *DKGPacket) ProtoReflect() protoreflect.Message {
-	mi := &file_drand_protocol_proto_msgTypes[4]
+	mi := &file_drand_protocol_proto_msgTypes[5]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -332,7 +421,7 @@ func (x *DKGPacket) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use DKGPacket.ProtoReflect.Descriptor instead.
 func (*DKGPacket) Descriptor() ([]byte, []int) {
-	return file_drand_protocol_proto_rawDescGZIP(), []int{4}
+	return file_drand_protocol_proto_rawDescGZIP(), []int{5}
 }
 
 func (x *DKGPacket) GetDkg() *dkg.Packet {
@@ -355,7 +444,7 @@ type SyncRequest struct {
 func (x *SyncRequest) Reset() {
 	*x = SyncRequest{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_drand_protocol_proto_msgTypes[5]
+		mi := &file_drand_protocol_proto_msgTypes[6]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -368,7 +457,7 @@ func (x *SyncRequest) String() string {
 func (*SyncRequest) ProtoMessage() {}
 
 func (x *SyncRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_drand_protocol_proto_msgTypes[5]
+	mi := &file_drand_protocol_proto_msgTypes[6]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -381,7 +470,7 @@ func (x *SyncRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use SyncRequest.ProtoReflect.Descriptor instead.
 func (*SyncRequest) Descriptor() ([]byte, []int) {
-	return file_drand_protocol_proto_rawDescGZIP(), []int{5}
+	return file_drand_protocol_proto_rawDescGZIP(), []int{6}
 }
 
 func (x *SyncRequest) GetFromRound() uint64 {
@@ -404,7 +493,7 @@ type BeaconPacket struct {
 func (x *BeaconPacket) Reset() {
 	*x = BeaconPacket{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_drand_protocol_proto_msgTypes[6]
+		mi := &file_drand_protocol_proto_msgTypes[7]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -417,7 +506,7 @@ func (x *BeaconPacket) String() string {
 func (*BeaconPacket) ProtoMessage() {}
 
 func (x *BeaconPacket) ProtoReflect() protoreflect.Message {
-	mi := &file_drand_protocol_proto_msgTypes[6]
+	mi := &file_drand_protocol_proto_msgTypes[7]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -430,7 +519,7 @@ func (x *BeaconPacket) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use BeaconPacket.ProtoReflect.Descriptor instead.
 func (*BeaconPacket) Descriptor() ([]byte, []int) {
-	return file_drand_protocol_proto_rawDescGZIP(), []int{6}
+	return file_drand_protocol_proto_rawDescGZIP(), []int{7}
 }
 
 func (x *BeaconPacket) GetPreviousSig() []byte {
@@ -471,7 +560,7 @@ var file_drand_protocol_proto_rawDesc = []byte{
 	0x74, 0x50, 0x72, 0x6f, 0x6f, 0x66, 0x12, 0x2e, 0x0a, 0x13, 0x70, 0x72, 0x65, 0x76, 0x69, 0x6f,
 	0x75, 0x73, 0x5f, 0x67, 0x72, 0x6f, 0x75, 0x70, 0x5f, 0x68, 0x61, 0x73, 0x68, 0x18, 0x03, 0x20,
 	0x01, 0x28, 0x0c, 0x52, 0x11, 0x70, 0x72, 0x65, 0x76, 0x69, 0x6f, 0x75, 0x73, 0x47, 0x72, 0x6f,
-	0x75, 0x70, 0x48, 0x61, 0x73, 0x68, 0x22, 0xa2, 0x01, 0x0a, 0x0d, 0x44, 0x4b, 0x47, 0x49, 0x6e,
+	0x75, 0x70, 0x48, 0x61, 0x73, 0x68, 0x22, 0xee, 0x01, 0x0a, 0x0d, 0x44, 0x4b, 0x47, 0x49, 0x6e,
 	0x66, 0x6f, 0x50, 0x61, 0x63, 0x6b, 0x65, 0x74, 0x12, 0x2f, 0x0a, 0x09, 0x6e, 0x65, 0x77, 0x5f,
 	0x67, 0x72, 0x6f, 0x75, 0x70, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x12, 0x2e, 0x64, 0x72,
 	0x61, 0x6e, 0x64, 0x2e, 0x47, 0x72, 0x6f, 0x75, 0x70, 0x50, 0x61, 0x63, 0x6b, 0x65, 0x74, 0x52,
@@ -481,50 +570,64 @@ var file_drand_protocol_proto_rawDesc = []byte{
 	0x64, 0x6b, 0x67, 0x5f, 0x74, 0x69, 0x6d, 0x65, 0x6f, 0x75, 0x74, 0x18, 0x03, 0x20, 0x01, 0x28,
 	0x0d, 0x52, 0x0a, 0x64, 0x6b, 0x67, 0x54, 0x69, 0x6d, 0x65, 0x6f, 0x75, 0x74, 0x12, 0x1c, 0x0a,
 	0x09, 0x73, 0x69, 0x67, 0x6e, 0x61, 0x74, 0x75, 0x72, 0x65, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0c,
-	0x52, 0x09, 0x73, 0x69, 0x67, 0x6e, 0x61, 0x74, 0x75, 0x72, 0x65, 0x22, 0x95, 0x01, 0x0a, 0x13,
-	0x50, 0x61, 0x72, 0x74, 0x69, 0x61, 0x6c, 0x42, 0x65, 0x61, 0x63, 0x6f, 0x6e, 0x50, 0x61, 0x63,
-	0x6b, 0x65, 0x74, 0x12, 0x14, 0x0a, 0x05, 0x72, 0x6f, 0x75, 0x6e, 0x64, 0x18, 0x01, 0x20, 0x01,
-	0x28, 0x04, 0x52, 0x05, 0x72, 0x6f, 0x75, 0x6e, 0x64, 0x12, 0x21, 0x0a, 0x0c, 0x70, 0x72, 0x65,
-	0x76, 0x69, 0x6f, 0x75, 0x73, 0x5f, 0x73, 0x69, 0x67, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0c, 0x52,
-	0x0b, 0x70, 0x72, 0x65, 0x76, 0x69, 0x6f, 0x75, 0x73, 0x53, 0x69, 0x67, 0x12, 0x1f, 0x0a, 0x0b,
-	0x70, 0x61, 0x72, 0x74, 0x69, 0x61, 0x6c, 0x5f, 0x73, 0x69, 0x67, 0x18, 0x03, 0x20, 0x01, 0x28,
-	0x0c, 0x52, 0x0a, 0x70, 0x61, 0x72, 0x74, 0x69, 0x61, 0x6c, 0x53, 0x69, 0x67, 0x12, 0x24, 0x0a,
-	0x0e, 0x70, 0x61, 0x72, 0x74, 0x69, 0x61, 0x6c, 0x5f, 0x73, 0x69, 0x67, 0x5f, 0x76, 0x32, 0x18,
-	0x04, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x0c, 0x70, 0x61, 0x72, 0x74, 0x69, 0x61, 0x6c, 0x53, 0x69,
-	0x67, 0x56, 0x32, 0x22, 0x2a, 0x0a, 0x09, 0x44, 0x4b, 0x47, 0x50, 0x61, 0x63, 0x6b, 0x65, 0x74,
-	0x12, 0x1d, 0x0a, 0x03, 0x64, 0x6b, 0x67, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0b, 0x2e,
-	0x64, 0x6b, 0x67, 0x2e, 0x50, 0x61, 0x63, 0x6b, 0x65, 0x74, 0x52, 0x03, 0x64, 0x6b, 0x67, 0x22,
-	0x2c, 0x0a, 0x0b, 0x53, 0x79, 0x6e, 0x63, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x1d,
-	0x0a, 0x0a, 0x66, 0x72, 0x6f, 0x6d, 0x5f, 0x72, 0x6f, 0x75, 0x6e, 0x64, 0x18, 0x01, 0x20, 0x01,
-	0x28, 0x04, 0x52, 0x09, 0x66, 0x72, 0x6f, 0x6d, 0x52, 0x6f, 0x75, 0x6e, 0x64, 0x22, 0x65, 0x0a,
-	0x0c, 0x42, 0x65, 0x61, 0x63, 0x6f, 0x6e, 0x50, 0x61, 0x63, 0x6b, 0x65, 0x74, 0x12, 0x21, 0x0a,
-	0x0c, 0x70, 0x72, 0x65, 0x76, 0x69, 0x6f, 0x75, 0x73, 0x5f, 0x73, 0x69, 0x67, 0x18, 0x01, 0x20,
-	0x01, 0x28, 0x0c, 0x52, 0x0b, 0x70, 0x72, 0x65, 0x76, 0x69, 0x6f, 0x75, 0x73, 0x53, 0x69, 0x67,
-	0x12, 0x14, 0x0a, 0x05, 0x72, 0x6f, 0x75, 0x6e, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x04, 0x52,
-	0x05, 0x72, 0x6f, 0x75, 0x6e, 0x64, 0x12, 0x1c, 0x0a, 0x09, 0x73, 0x69, 0x67, 0x6e, 0x61, 0x74,
-	0x75, 0x72, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x09, 0x73, 0x69, 0x67, 0x6e, 0x61,
-	0x74, 0x75, 0x72, 0x65, 0x32, 0xd6, 0x02, 0x0a, 0x08, 0x50, 0x72, 0x6f, 0x74, 0x6f, 0x63, 0x6f,
-	0x6c, 0x12, 0x36, 0x0a, 0x0b, 0x47, 0x65, 0x74, 0x49, 0x64, 0x65, 0x6e, 0x74, 0x69, 0x74, 0x79,
-	0x12, 0x16, 0x2e, 0x64, 0x72, 0x61, 0x6e, 0x64, 0x2e, 0x49, 0x64, 0x65, 0x6e, 0x74, 0x69, 0x74,
-	0x79, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x0f, 0x2e, 0x64, 0x72, 0x61, 0x6e, 0x64,
-	0x2e, 0x49, 0x64, 0x65, 0x6e, 0x74, 0x69, 0x74, 0x79, 0x12, 0x3c, 0x0a, 0x14, 0x53, 0x69, 0x67,
-	0x6e, 0x61, 0x6c, 0x44, 0x4b, 0x47, 0x50, 0x61, 0x72, 0x74, 0x69, 0x63, 0x69, 0x70, 0x61, 0x6e,
-	0x74, 0x12, 0x16, 0x2e, 0x64, 0x72, 0x61, 0x6e, 0x64, 0x2e, 0x53, 0x69, 0x67, 0x6e, 0x61, 0x6c,
+	0x52, 0x09, 0x73, 0x69, 0x67, 0x6e, 0x61, 0x74, 0x75, 0x72, 0x65, 0x12, 0x25, 0x0a, 0x0e, 0x70,
+	0x68, 0x61, 0x73, 0x65, 0x5f, 0x74, 0x69, 0x6d, 0x65, 0x6f, 0x75, 0x74, 0x73, 0x18, 0x05, 0x20,
+	0x03, 0x28, 0x0d, 0x52, 0x0d, 0x70, 0x68, 0x61, 0x73, 0x65, 0x54, 0x69, 0x6d, 0x65, 0x6f, 0x75,
+	0x74, 0x73, 0x12, 0x23, 0x0a, 0x0d, 0x70, 0x68, 0x61, 0x73, 0x65, 0x5f, 0x72, 0x65, 0x74, 0x72,
+	0x69, 0x65, 0x73, 0x18, 0x06, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x0c, 0x70, 0x68, 0x61, 0x73, 0x65,
+	0x52, 0x65, 0x74, 0x72, 0x69, 0x65, 0x73, 0x22, 0x95, 0x01, 0x0a, 0x13, 0x50, 0x61, 0x72, 0x74,
+	0x69, 0x61, 0x6c, 0x42, 0x65, 0x61, 0x63, 0x6f, 0x6e, 0x50, 0x61, 0x63, 0x6b, 0x65, 0x74, 0x12,
+	0x14, 0x0a, 0x05, 0x72, 0x6f, 0x75, 0x6e, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x04, 0x52, 0x05,
+	0x72, 0x6f, 0x75, 0x6e, 0x64, 0x12, 0x21, 0x0a, 0x0c, 0x70, 0x72, 0x65, 0x76, 0x69, 0x6f, 0x75,
+	0x73, 0x5f, 0x73, 0x69, 0x67, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x0b, 0x70, 0x72, 0x65,
+	0x76, 0x69, 0x6f, 0x75, 0x73, 0x53, 0x69, 0x67, 0x12, 0x1f, 0x0a, 0x0b, 0x70, 0x61, 0x72, 0x74,
+	0x69, 0x61, 0x6c, 0x5f, 0x73, 0x69, 0x67, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x0a, 0x70,
+	0x61, 0x72, 0x74, 0x69, 0x61, 0x6c, 0x53, 0x69, 0x67, 0x12, 0x24, 0x0a, 0x0e, 0x70, 0x61, 0x72,
+	0x74, 0x69, 0x61, 0x6c, 0x5f, 0x73, 0x69, 0x67, 0x5f, 0x76, 0x32, 0x18, 0x04, 0x20, 0x01, 0x28,
+	0x0c, 0x52, 0x0c, 0x70, 0x61, 0x72, 0x74, 0x69, 0x61, 0x6c, 0x53, 0x69, 0x67, 0x56, 0x32, 0x22,
+	0x5f, 0x0a, 0x0f, 0x44, 0x65, 0x70, 0x61, 0x72, 0x74, 0x75, 0x72, 0x65, 0x50, 0x61, 0x63, 0x6b,
+	0x65, 0x74, 0x12, 0x18, 0x0a, 0x07, 0x61, 0x64, 0x64, 0x72, 0x65, 0x73, 0x73, 0x18, 0x01, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x07, 0x61, 0x64, 0x64, 0x72, 0x65, 0x73, 0x73, 0x12, 0x14, 0x0a, 0x05,
+	0x72, 0x6f, 0x75, 0x6e, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x04, 0x52, 0x05, 0x72, 0x6f, 0x75,
+	0x6e, 0x64, 0x12, 0x1c, 0x0a, 0x09, 0x73, 0x69, 0x67, 0x6e, 0x61, 0x74, 0x75, 0x72, 0x65, 0x18,
+	0x03, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x09, 0x73, 0x69, 0x67, 0x6e, 0x61, 0x74, 0x75, 0x72, 0x65,
+	0x22, 0x2a, 0x0a, 0x09, 0x44, 0x4b, 0x47, 0x50, 0x61, 0x63, 0x6b, 0x65, 0x74, 0x12, 0x1d, 0x0a,
+	0x03, 0x64, 0x6b, 0x67, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0b, 0x2e, 0x64, 0x6b, 0x67,
+	0x2e, 0x50, 0x61, 0x63, 0x6b, 0x65, 0x74, 0x52, 0x03, 0x64, 0x6b, 0x67, 0x22, 0x2c, 0x0a, 0x0b,
+	0x53, 0x79, 0x6e, 0x63, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x1d, 0x0a, 0x0a, 0x66,
+	0x72, 0x6f, 0x6d, 0x5f, 0x72, 0x6f, 0x75, 0x6e, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x04, 0x52,
+	0x09, 0x66, 0x72, 0x6f, 0x6d, 0x52, 0x6f, 0x75, 0x6e, 0x64, 0x22, 0x65, 0x0a, 0x0c, 0x42, 0x65,
+	0x61, 0x63, 0x6f, 0x6e, 0x50, 0x61, 0x63, 0x6b, 0x65, 0x74, 0x12, 0x21, 0x0a, 0x0c, 0x70, 0x72,
+	0x65, 0x76, 0x69, 0x6f, 0x75, 0x73, 0x5f, 0x73, 0x69, 0x67, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0c,
+	0x52, 0x0b, 0x70, 0x72, 0x65, 0x76, 0x69, 0x6f, 0x75, 0x73, 0x53, 0x69, 0x67, 0x12, 0x14, 0x0a,
+	0x05, 0x72, 0x6f, 0x75, 0x6e, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x04, 0x52, 0x05, 0x72, 0x6f,
+	0x75, 0x6e, 0x64, 0x12, 0x1c, 0x0a, 0x09, 0x73, 0x69, 0x67, 0x6e, 0x61, 0x74, 0x75, 0x72, 0x65,
+	0x18, 0x03, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x09, 0x73, 0x69, 0x67, 0x6e, 0x61, 0x74, 0x75, 0x72,
+	0x65, 0x32, 0x89, 0x03, 0x0a, 0x08, 0x50, 0x72, 0x6f, 0x74, 0x6f, 0x63, 0x6f, 0x6c, 0x12, 0x36,
+	0x0a, 0x0b, 0x47, 0x65, 0x74, 0x49, 0x64, 0x65, 0x6e, 0x74, 0x69, 0x74, 0x79, 0x12, 0x16, 0x2e,
+	0x64, 0x72, 0x61, 0x6e, 0x64, 0x2e, 0x49, 0x64, 0x65, 0x6e, 0x74, 0x69, 0x74, 0x79, 0x52, 0x65,
+	0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x0f, 0x2e, 0x64, 0x72, 0x61, 0x6e, 0x64, 0x2e, 0x49, 0x64,
+	0x65, 0x6e, 0x74, 0x69, 0x74, 0x79, 0x12, 0x3c, 0x0a, 0x14, 0x53, 0x69, 0x67, 0x6e, 0x61, 0x6c,
+	0x44, 0x4b, 0x47, 0x50, 0x61, 0x72, 0x74, 0x69, 0x63, 0x69, 0x70, 0x61, 0x6e, 0x74, 0x12, 0x16,
+	0x2e, 0x64, 0x72, 0x61, 0x6e, 0x64, 0x2e, 0x53, 0x69, 0x67, 0x6e, 0x61, 0x6c, 0x44, 0x4b, 0x47,
+	0x50, 0x61, 0x63, 0x6b, 0x65, 0x74, 0x1a, 0x0c, 0x2e, 0x64, 0x72, 0x61, 0x6e, 0x64, 0x2e, 0x45,
+	0x6d, 0x70, 0x74, 0x79, 0x12, 0x31, 0x0a, 0x0b, 0x50, 0x75, 0x73, 0x68, 0x44, 0x4b, 0x47, 0x49,
+	0x6e, 0x66, 0x6f, 0x12, 0x14, 0x2e, 0x64, 0x72, 0x61, 0x6e, 0x64, 0x2e, 0x44, 0x4b, 0x47, 0x49,
+	0x6e, 0x66, 0x6f, 0x50, 0x61, 0x63, 0x6b, 0x65, 0x74, 0x1a, 0x0c, 0x2e, 0x64, 0x72, 0x61, 0x6e,
+	0x64, 0x2e, 0x45, 0x6d, 0x70, 0x74, 0x79, 0x12, 0x2e, 0x0a, 0x0c, 0x42, 0x72, 0x6f, 0x61, 0x64,
+	0x63, 0x61, 0x73, 0x74, 0x44, 0x4b, 0x47, 0x12, 0x10, 0x2e, 0x64, 0x72, 0x61, 0x6e, 0x64, 0x2e,
 	0x44, 0x4b, 0x47, 0x50, 0x61, 0x63, 0x6b, 0x65, 0x74, 0x1a, 0x0c, 0x2e, 0x64, 0x72, 0x61, 0x6e,
-	0x64, 0x2e, 0x45, 0x6d, 0x70, 0x74, 0x79, 0x12, 0x31, 0x0a, 0x0b, 0x50, 0x75, 0x73, 0x68, 0x44,
-	0x4b, 0x47, 0x49, 0x6e, 0x66, 0x6f, 0x12, 0x14, 0x2e, 0x64, 0x72, 0x61, 0x6e, 0x64, 0x2e, 0x44,
-	0x4b, 0x47, 0x49, 0x6e, 0x66, 0x6f, 0x50, 0x61, 0x63, 0x6b, 0x65, 0x74, 0x1a, 0x0c, 0x2e, 0x64,
-	0x72, 0x61, 0x6e, 0x64, 0x2e, 0x45, 0x6d, 0x70, 0x74, 0x79, 0x12, 0x2e, 0x0a, 0x0c, 0x42, 0x72,
-	0x6f, 0x61, 0x64, 0x63, 0x61, 0x73, 0x74, 0x44, 0x4b, 0x47, 0x12, 0x10, 0x2e, 0x64, 0x72, 0x61,
-	0x6e, 0x64, 0x2e, 0x44, 0x4b, 0x47, 0x50, 0x61, 0x63, 0x6b, 0x65, 0x74, 0x1a, 0x0c, 0x2e, 0x64,
-	0x72, 0x61, 0x6e, 0x64, 0x2e, 0x45, 0x6d, 0x70, 0x74, 0x79, 0x12, 0x39, 0x0a, 0x0d, 0x50, 0x61,
-	0x72, 0x74, 0x69, 0x61, 0x6c, 0x42, 0x65, 0x61, 0x63, 0x6f, 0x6e, 0x12, 0x1a, 0x2e, 0x64, 0x72,
-	0x61, 0x6e, 0x64, 0x2e, 0x50, 0x61, 0x72, 0x74, 0x69, 0x61, 0x6c, 0x42, 0x65, 0x61, 0x63, 0x6f,
-	0x6e, 0x50, 0x61, 0x63, 0x6b, 0x65, 0x74, 0x1a, 0x0c, 0x2e, 0x64, 0x72, 0x61, 0x6e, 0x64, 0x2e,
-	0x45, 0x6d, 0x70, 0x74, 0x79, 0x12, 0x36, 0x0a, 0x09, 0x53, 0x79, 0x6e, 0x63, 0x43, 0x68, 0x61,
-	0x69, 0x6e, 0x12, 0x12, 0x2e, 0x64, 0x72, 0x61, 0x6e, 0x64, 0x2e, 0x53, 0x79, 0x6e, 0x63, 0x52,
-	0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x13, 0x2e, 0x64, 0x72, 0x61, 0x6e, 0x64, 0x2e, 0x42,
-	0x65, 0x61, 0x63, 0x6f, 0x6e, 0x50, 0x61, 0x63, 0x6b, 0x65, 0x74, 0x30, 0x01, 0x42, 0x27, 0x5a,
+	0x64, 0x2e, 0x45, 0x6d, 0x70, 0x74, 0x79, 0x12, 0x39, 0x0a, 0x0d, 0x50, 0x61, 0x72, 0x74, 0x69,
+	0x61, 0x6c, 0x42, 0x65, 0x61, 0x63, 0x6f, 0x6e, 0x12, 0x1a, 0x2e, 0x64, 0x72, 0x61, 0x6e, 0x64,
+	0x2e, 0x50, 0x61, 0x72, 0x74, 0x69, 0x61, 0x6c, 0x42, 0x65, 0x61, 0x63, 0x6f, 0x6e, 0x50, 0x61,
+	0x63, 0x6b, 0x65, 0x74, 0x1a, 0x0c, 0x2e, 0x64, 0x72, 0x61, 0x6e, 0x64, 0x2e, 0x45, 0x6d, 0x70,
+	0x74, 0x79, 0x12, 0x36, 0x0a, 0x09, 0x53, 0x79, 0x6e, 0x63, 0x43, 0x68, 0x61, 0x69, 0x6e, 0x12,
+	0x12, 0x2e, 0x64, 0x72, 0x61, 0x6e, 0x64, 0x2e, 0x53, 0x79, 0x6e, 0x63, 0x52, 0x65, 0x71, 0x75,
+	0x65, 0x73, 0x74, 0x1a, 0x13, 0x2e, 0x64, 0x72, 0x61, 0x6e, 0x64, 0x2e, 0x42, 0x65, 0x61, 0x63,
+	0x6f, 0x6e, 0x50, 0x61, 0x63, 0x6b, 0x65, 0x74, 0x30, 0x01, 0x12, 0x31, 0x0a, 0x09, 0x44, 0x65,
+	0x70, 0x61, 0x72, 0x74, 0x75, 0x72, 0x65, 0x12, 0x16, 0x2e, 0x64, 0x72, 0x61, 0x6e, 0x64, 0x2e,
+	0x44, 0x65, 0x70, 0x61, 0x72, 0x74, 0x75, 0x72, 0x65, 0x50, 0x61, 0x63, 0x6b, 0x65, 0x74, 0x1a,
+	0x0c, 0x2e, 0x64, 0x72, 0x61, 0x6e, 0x64, 0x2e, 0x45, 0x6d, 0x70, 0x74, 0x79, 0x42, 0x27, 0x5a,
 	0x25, 0x67, 0x69, 0x74, 0x68, 0x75, 0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x64, 0x72, 0x61, 0x6e,
 	0x64, 0x2f, 0x64, 0x72, 0x61, 0x6e, 0x64, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66,
 	0x2f, 0x64, 0x72, 0x61, 0x6e, 0x64, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
@@ -542,38 +645,41 @@ func file_drand_protocol_proto_rawDescGZIP() []byte {
 	return file_drand_protocol_proto_rawDescData
 }
 
-var file_drand_protocol_proto_msgTypes = make([]protoimpl.MessageInfo, 7)
+var file_drand_protocol_proto_msgTypes = make([]protoimpl.MessageInfo, 8)
 var file_drand_protocol_proto_goTypes = []interface{}{
 	(*IdentityRequest)(nil),     // 0: drand.IdentityRequest
 	(*SignalDKGPacket)(nil),     // 1: drand.SignalDKGPacket
 	(*DKGInfoPacket)(nil),       // 2: drand.DKGInfoPacket
 	(*PartialBeaconPacket)(nil), // 3: drand.PartialBeaconPacket
-	(*DKGPacket)(nil),           // 4: drand.DKGPacket
-	(*SyncRequest)(nil),         // 5: drand.SyncRequest
-	(*BeaconPacket)(nil),        // 6: drand.BeaconPacket
-	(*Identity)(nil),            // 7: drand.Identity
-	(*GroupPacket)(nil),         // 8: drand.GroupPacket
-	(*dkg.Packet)(nil),          // 9: dkg.Packet
-	(*Empty)(nil),               // 10: drand.Empty
+	(*DeparturePacket)(nil),     // 4: drand.DeparturePacket
+	(*DKGPacket)(nil),           // 5: drand.DKGPacket
+	(*SyncRequest)(nil),         // 6: drand.SyncRequest
+	(*BeaconPacket)(nil),        // 7: drand.BeaconPacket
+	(*Identity)(nil),            // 8: drand.Identity
+	(*GroupPacket)(nil),         // 9: drand.GroupPacket
+	(*dkg.Packet)(nil),          // 10: dkg.Packet
+	(*Empty)(nil),               // 11: drand.Empty
 }
 var file_drand_protocol_proto_depIdxs = []int32{
-	7,  // 0: drand.SignalDKGPacket.node:type_name -> drand.Identity
-	8,  // 1: drand.DKGInfoPacket.new_group:type_name -> drand.GroupPacket
-	9,  // 2: drand.DKGPacket.dkg:type_name -> dkg.Packet
+	8,  // 0: drand.SignalDKGPacket.node:type_name -> drand.Identity
+	9,  // 1: drand.DKGInfoPacket.new_group:type_name -> drand.GroupPacket
+	10, // 2: drand.DKGPacket.dkg:type_name -> dkg.Packet
 	0,  // 3: drand.Protocol.GetIdentity:input_type -> drand.IdentityRequest
 	1,  // 4: drand.Protocol.SignalDKGParticipant:input_type -> drand.SignalDKGPacket
 	2,  // 5: drand.Protocol.PushDKGInfo:input_type -> drand.DKGInfoPacket
-	4,  // 6: drand.Protocol.BroadcastDKG:input_type -> drand.DKGPacket
+	5,  // 6: drand.Protocol.BroadcastDKG:input_type -> drand.DKGPacket
 	3,  // 7: drand.Protocol.PartialBeacon:input_type -> drand.PartialBeaconPacket
-	5,  // 8: drand.Protocol.SyncChain:input_type -> drand.SyncRequest
-	7,  // 9: drand.Protocol.GetIdentity:output_type -> drand.Identity
-	10, // 10: drand.Protocol.SignalDKGParticipant:output_type -> drand.Empty
-	10, // 11: drand.Protocol.PushDKGInfo:output_type -> drand.Empty
-	10, // 12: drand.Protocol.BroadcastDKG:output_type -> drand.Empty
-	10, // 13: drand.Protocol.PartialBeacon:output_type -> drand.Empty
-	6,  // 14: drand.Protocol.SyncChain:output_type -> drand.BeaconPacket
-	9,  // [9:15] is the sub-list for method output_type
-	3,  // [3:9] is the sub-list for method input_type
+	6,  // 8: drand.Protocol.SyncChain:input_type -> drand.SyncRequest
+	4,  // 9: drand.Protocol.Departure:input_type -> drand.DeparturePacket
+	8,  // 10: drand.Protocol.GetIdentity:output_type -> drand.Identity
+	11, // 11: drand.Protocol.SignalDKGParticipant:output_type -> drand.Empty
+	11, // 12: drand.Protocol.PushDKGInfo:output_type -> drand.Empty
+	11, // 13: drand.Protocol.BroadcastDKG:output_type -> drand.Empty
+	11, // 14: drand.Protocol.PartialBeacon:output_type -> drand.Empty
+	7,  // 15: drand.Protocol.SyncChain:output_type -> drand.BeaconPacket
+	11, // 16: drand.Protocol.Departure:output_type -> drand.Empty
+	10, // [10:17] is the sub-list for method output_type
+	3,  // [3:10] is the sub-list for method input_type
 	3,  // [3:3] is the sub-list for extension type_name
 	3,  // [3:3] is the sub-list for extension extendee
 	0,  // [0:3] is the sub-list for field type_name
@@ -635,7 +741,7 @@ func file_drand_protocol_proto_init() {
 			}
 		}
 		file_drand_protocol_proto_msgTypes[4].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*DKGPacket); i {
+			switch v := v.(*DeparturePacket); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -647,7 +753,7 @@ func file_drand_protocol_proto_init() {
 			}
 		}
 		file_drand_protocol_proto_msgTypes[5].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*SyncRequest); i {
+			switch v := v.(*DKGPacket); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -659,6 +765,18 @@ func file_drand_protocol_proto_init() {
 			}
 		}
 		file_drand_protocol_proto_msgTypes[6].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*SyncRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_drand_protocol_proto_msgTypes[7].Exporter = func(v interface{}, i int) interface{} {
 			switch v := v.(*BeaconPacket); i {
 			case 0:
 				return &v.state
@@ -677,7 +795,7 @@ func file_drand_protocol_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: file_drand_protocol_proto_rawDesc,
 			NumEnums:      0,
-			NumMessages:   7,
+			NumMessages:   8,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
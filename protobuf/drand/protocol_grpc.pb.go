@@ -33,6 +33,9 @@ type ProtocolClient interface {
 	PartialBeacon(ctx context.Context, in *PartialBeaconPacket, opts ...grpc.CallOption) (*Empty, error)
 	// SyncRequest forces a daemon to sync up its chain with other nodes
 	SyncChain(ctx context.Context, in *SyncRequest, opts ...grpc.CallOption) (Protocol_SyncChainClient, error)
+	// Departure tells the other nodes of the group that the node stops after
+	// the round it last signed, until it starts again.
+	Departure(ctx context.Context, in *DeparturePacket, opts ...grpc.CallOption) (*Empty, error)
 }
 
 type protocolClient struct {
@@ -103,6 +106,15 @@ func (c *protocolClient) SyncChain(ctx context.Context, in *SyncRequest, opts ..
 	return x, nil
 }
 
+func (c *protocolClient) Departure(ctx context.Context, in *DeparturePacket, opts ...grpc.CallOption) (*Empty, error) {
+	out := new(Empty)
+	err := c.cc.Invoke(ctx, "/drand.Protocol/Departure", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 type Protocol_SyncChainClient interface {
 	Recv() (*BeaconPacket, error)
 	grpc.ClientStream
@@ -140,6 +152,9 @@ type ProtocolServer interface {
 	PartialBeacon(context.Context, *PartialBeaconPacket) (*Empty, error)
 	// SyncRequest forces a daemon to sync up its chain with other nodes
 	SyncChain(*SyncRequest, Protocol_SyncChainServer) error
+	// Departure tells the other nodes of the group that the node stops after
+	// the round it last signed, until it starts again.
+	Departure(context.Context, *DeparturePacket) (*Empty, error)
 }
 
 // UnimplementedProtocolServer should be embedded to have forward compatible implementations.
@@ -164,6 +179,9 @@ func (*UnimplementedProtocolServer) PartialBeacon(context.Context, *PartialBeaco
 func (*UnimplementedProtocolServer) SyncChain(*SyncRequest, Protocol_SyncChainServer) error {
 	return status.Errorf(codes.Unimplemented, "method SyncChain not implemented")
 }
+func (*UnimplementedProtocolServer) Departure(context.Context, *DeparturePacket) (*Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Departure not implemented")
+}
 
 func RegisterProtocolServer(s *grpc.Server, srv ProtocolServer) {
 	s.RegisterService(&_Protocol_serviceDesc, srv)
@@ -280,6 +298,24 @@ func (x *protocolSyncChainServer) Send(m *BeaconPacket) error {
 	return x.ServerStream.SendMsg(m)
 }
 
+func _Protocol_Departure_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeparturePacket)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ProtocolServer).Departure(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/drand.Protocol/Departure",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ProtocolServer).Departure(ctx, req.(*DeparturePacket))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 var _Protocol_serviceDesc = grpc.ServiceDesc{
 	ServiceName: "drand.Protocol",
 	HandlerType: (*ProtocolServer)(nil),
@@ -304,6 +340,10 @@ var _Protocol_serviceDesc = grpc.ServiceDesc{
 			MethodName: "PartialBeacon",
 			Handler:    _Protocol_PartialBeacon_Handler,
 		},
+		{
+			MethodName: "Departure",
+			Handler:    _Protocol_Departure_Handler,
+		},
 	},
 	Streams: []grpc.StreamDesc{
 		{
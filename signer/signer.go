@@ -0,0 +1,207 @@
+// Package signer implements a remote signing daemon producing the partial
+// signatures of a drand node, so that the node never holds its private share.
+// The daemon holds the share and answers the signing requests of the node
+// over gRPC authenticated by mutual TLS.
+//
+// The daemon only signs beacon messages: a request carries a round and the
+// previous signature, from which the daemon computes the messages itself. It
+// follows the schedule of the chain and refuses the rounds whose time has not
+// come yet, so that a compromised node can't have future rounds signed.
+package signer
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"time"
+
+	"github.com/drand/drand/chain"
+	"github.com/drand/drand/chain/beacon"
+	"github.com/drand/drand/protobuf/drand"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+)
+
+// DefaultTimeout is how long the node waits for a partial signature.
+const DefaultTimeout = 5 * time.Second
+
+// MaxClockSkew is how early the daemon signs a round before its time, to
+// absorb the clock skew between the node and the daemon.
+const MaxClockSkew = 2 * time.Second
+
+const signPartialMethod = "/drand.Signer/SignPartial"
+
+// serviceDesc describes the signing service. The requests and responses are
+// partial beacon packets: a request sets the round and previous signature,
+// the response the partial signatures.
+var serviceDesc = grpc.ServiceDesc{
+	ServiceName: "drand.Signer",
+	HandlerType: (*beacon.Signer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "SignPartial",
+			Handler:    signPartialHandler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "signer",
+}
+
+func signPartialHandler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(drand.PartialBeaconPacket)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		p := req.(*drand.PartialBeaconPacket)
+		sig, sigV2, err := srv.(beacon.Signer).SignPartial(p.GetRound(), p.GetPreviousSig())
+		if err != nil {
+			return nil, err
+		}
+		return &drand.PartialBeaconPacket{
+			Round:        p.GetRound(),
+			PreviousSig:  p.GetPreviousSig(),
+			PartialSig:   sig,
+			PartialSigV2: sigV2,
+		}, nil
+	}
+	if interceptor == nil {
+		return handler(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: signPartialMethod,
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// ScheduledSigner is a beacon.Signer refusing to sign the rounds whose time,
+// following the schedule of the chain, has not come yet.
+type ScheduledSigner struct {
+	beacon.Signer
+	info  *chain.Info
+	clock func() time.Time
+}
+
+// NewScheduledSigner returns a signer signing with s the rounds of the chain
+// described by info whose time has come, up to MaxClockSkew in advance.
+func NewScheduledSigner(s beacon.Signer, info *chain.Info) *ScheduledSigner {
+	return &ScheduledSigner{Signer: s, info: info, clock: time.Now}
+}
+
+// SignPartial implements the beacon.Signer interface.
+func (s *ScheduledSigner) SignPartial(round uint64, previousSig []byte) ([]byte, []byte, error) {
+	if at := s.info.TimeOfRound(round); at.After(s.clock().Add(MaxClockSkew)) {
+		return nil, nil, fmt.Errorf("signer: round %d is only due at %s", round, at.UTC().Format(time.RFC3339))
+	}
+	return s.Signer.SignPartial(round, previousSig)
+}
+
+// NewServer returns a gRPC server answering the signing requests with s. The
+// TLS config should require client certificates, see ServerTLSConfig. A nil
+// config serves in plaintext, which is only meant for tests.
+func NewServer(s beacon.Signer, tlsConf *tls.Config) *grpc.Server {
+	var opts []grpc.ServerOption
+	if tlsConf != nil {
+		opts = append(opts, grpc.Creds(credentials.NewTLS(tlsConf)))
+	}
+	srv := grpc.NewServer(opts...)
+	srv.RegisterService(&serviceDesc, s)
+	return srv
+}
+
+// Client is a beacon.Signer requesting the partial signatures of a remote
+// signing daemon.
+type Client struct {
+	conn    *grpc.ClientConn
+	timeout time.Duration
+}
+
+// Dial returns a client of the signing daemon listening at addr. The TLS
+// config should hold the client certificate, see ClientTLSConfig. A nil
+// config connects in plaintext, which is only meant for tests.
+func Dial(addr string, tlsConf *tls.Config) (*Client, error) {
+	opt := grpc.WithInsecure()
+	if tlsConf != nil {
+		opt = grpc.WithTransportCredentials(credentials.NewTLS(tlsConf))
+	}
+	conn, err := grpc.Dial(addr, opt)
+	if err != nil {
+		return nil, err
+	}
+	return &Client{conn: conn, timeout: DefaultTimeout}, nil
+}
+
+// SignPartial implements the beacon.Signer interface.
+func (c *Client) SignPartial(round uint64, previousSig []byte) ([]byte, []byte, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), c.timeout)
+	defer cancel()
+	in := &drand.PartialBeaconPacket{Round: round, PreviousSig: previousSig}
+	out := new(drand.PartialBeaconPacket)
+	if err := c.conn.Invoke(ctx, signPartialMethod, in, out); err != nil {
+		return nil, nil, fmt.Errorf("signer: round %d: %w", round, err)
+	}
+	if out.GetRound() != round {
+		return nil, nil, fmt.Errorf("signer: asked round %d, signed round %d", round, out.GetRound())
+	}
+	return out.GetPartialSig(), out.GetPartialSigV2(), nil
+}
+
+// Close closes the connection to the signing daemon.
+func (c *Client) Close() error {
+	return c.conn.Close()
+}
+
+// ServerTLSConfig returns the TLS config of a signing daemon presenting the
+// given certificate and only accepting the clients presenting a certificate
+// signed by the given CA.
+func ServerTLSConfig(certPath, keyPath, clientCAPath string) (*tls.Config, error) {
+	cert, err := tls.LoadX509KeyPair(certPath, keyPath)
+	if err != nil {
+		return nil, err
+	}
+	pool, err := certPool(clientCAPath)
+	if err != nil {
+		return nil, err
+	}
+	return &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		ClientAuth:   tls.RequireAndVerifyClientCert,
+		ClientCAs:    pool,
+		MinVersion:   tls.VersionTLS12,
+	}, nil
+}
+
+// ClientTLSConfig returns the TLS config of a node presenting the given
+// certificate to the signing daemon, whose certificate must be signed by the
+// given CA.
+func ClientTLSConfig(certPath, keyPath, caPath string) (*tls.Config, error) {
+	cert, err := tls.LoadX509KeyPair(certPath, keyPath)
+	if err != nil {
+		return nil, err
+	}
+	pool, err := certPool(caPath)
+	if err != nil {
+		return nil, err
+	}
+	return &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		RootCAs:      pool,
+		MinVersion:   tls.VersionTLS12,
+	}, nil
+}
+
+func certPool(caPath string) (*x509.CertPool, error) {
+	pem, err := ioutil.ReadFile(caPath)
+	if err != nil {
+		return nil, err
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(pem) {
+		return nil, errors.New("signer: no certificate in " + caPath)
+	}
+	return pool, nil
+}
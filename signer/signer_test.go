@@ -0,0 +1,75 @@
+package signer
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/drand/drand/chain"
+	"github.com/drand/drand/chain/beacon"
+	"github.com/drand/drand/key"
+	"github.com/drand/kyber/share"
+	dkg "github.com/drand/kyber/share/dkg"
+	"github.com/drand/kyber/util/random"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRemoteSigner(t *testing.T) {
+	sh := &key.Share{DistKeyShare: dkg.DistKeyShare{Share: &share.PriShare{I: 2, V: key.KeyGroup.Scalar().Pick(random.New())}}}
+	local := beacon.NewShareSigner(sh)
+
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	srv := NewServer(local, nil)
+	go func() { _ = srv.Serve(lis) }()
+	defer srv.Stop()
+
+	c, err := Dial(lis.Addr().String(), nil)
+	require.NoError(t, err)
+	defer c.Close()
+
+	prev := []byte("previous signature")
+	sig, sigV2, err := c.SignPartial(10, prev)
+	require.NoError(t, err)
+	expSig, expSigV2, err := local.SignPartial(10, prev)
+	require.NoError(t, err)
+	require.Equal(t, expSig, sig)
+	require.Equal(t, expSigV2, sigV2)
+	idx, err := key.Scheme.IndexOf(sig)
+	require.NoError(t, err)
+	require.Equal(t, 2, idx)
+}
+
+func TestRemoteSignerError(t *testing.T) {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	srv := NewServer(beacon.NewShareSigner(nil), nil)
+	go func() { _ = srv.Serve(lis) }()
+	defer srv.Stop()
+
+	c, err := Dial(lis.Addr().String(), nil)
+	require.NoError(t, err)
+	defer c.Close()
+	_, _, err = c.SignPartial(10, nil)
+	require.Error(t, err)
+}
+
+func TestScheduledSigner(t *testing.T) {
+	sh := &key.Share{DistKeyShare: dkg.DistKeyShare{Share: &share.PriShare{I: 1, V: key.KeyGroup.Scalar().Pick(random.New())}}}
+	info := &chain.Info{Period: 30 * time.Second, GenesisTime: 1000}
+	s := NewScheduledSigner(beacon.NewShareSigner(sh), info)
+	now := time.Unix(1000+30*9, 0)
+	s.clock = func() time.Time { return now }
+
+	// the current round and the next one within the clock skew are signed
+	_, _, err := s.SignPartial(10, nil)
+	require.NoError(t, err)
+	now = now.Add(30*time.Second - MaxClockSkew)
+	_, _, err = s.SignPartial(11, nil)
+	require.NoError(t, err)
+	// the rounds to come are refused
+	_, _, err = s.SignPartial(12, nil)
+	require.Error(t, err)
+	_, _, err = s.SignPartial(1000, nil)
+	require.Error(t, err)
+}
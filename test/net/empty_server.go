@@ -70,6 +70,11 @@ func (s *EmptyServer) PartialBeacon(context.Context, *drand.PartialBeaconPacket)
 	return nil, nil
 }
 
+// Departure is an empty implementation
+func (s *EmptyServer) Departure(context.Context, *drand.DeparturePacket) (*drand.Empty, error) {
+	return nil, nil
+}
+
 // PingPong is an empty implementation
 func (s *EmptyServer) PingPong(context.Context, *drand.Ping) (*drand.Pong, error) {
 	return nil, nil